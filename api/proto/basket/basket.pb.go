@@ -23,15 +23,18 @@ const (
 
 // Basket item message
 type BasketItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
-	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	Subtotal      float64                `protobuf:"fixed64,5,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
-	Category      string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ProductId      int32                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Price          float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity       int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Subtotal       float64                `protobuf:"fixed64,5,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Category       string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	TaxRate        float64                `protobuf:"fixed64,7,opt,name=tax_rate,json=taxRate,proto3" json:"tax_rate,omitempty"`
+	TaxAmount      float64                `protobuf:"fixed64,8,opt,name=tax_amount,json=taxAmount,proto3" json:"tax_amount,omitempty"`
+	DiscountAmount float64                `protobuf:"fixed64,9,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *BasketItem) Reset() {
@@ -106,6 +109,27 @@ func (x *BasketItem) GetCategory() string {
 	return ""
 }
 
+func (x *BasketItem) GetTaxRate() float64 {
+	if x != nil {
+		return x.TaxRate
+	}
+	return 0
+}
+
+func (x *BasketItem) GetTaxAmount() float64 {
+	if x != nil {
+		return x.TaxAmount
+	}
+	return 0
+}
+
+func (x *BasketItem) GetDiscountAmount() float64 {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return 0
+}
+
 // Basket message
 type Basket struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1101,7 +1125,7 @@ var File_api_proto_basket_basket_proto protoreflect.FileDescriptor
 
 const file_api_proto_basket_basket_proto_rawDesc = "" +
 	"\n" +
-	"\x1dapi/proto/basket/basket.proto\x12\x06basket\"\xa9\x01\n" +
+	"\x1dapi/proto/basket/basket.proto\x12\x06basket\"\x8c\x02\n" +
 	"\n" +
 	"BasketItem\x12\x1d\n" +
 	"\n" +
@@ -1110,7 +1134,11 @@ const file_api_proto_basket_basket_proto_rawDesc = "" +
 	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
 	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x1a\n" +
 	"\bsubtotal\x18\x05 \x01(\x01R\bsubtotal\x12\x1a\n" +
-	"\bcategory\x18\x06 \x01(\tR\bcategory\"\xed\x01\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12\x19\n" +
+	"\btax_rate\x18\a \x01(\x01R\ataxRate\x12\x1d\n" +
+	"\n" +
+	"tax_amount\x18\b \x01(\x01R\ttaxAmount\x12'\n" +
+	"\x0fdiscount_amount\x18\t \x01(\x01R\x0ediscountAmount\"\xed\x01\n" +
 	"\x06Basket\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12(\n" +
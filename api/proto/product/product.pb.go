@@ -21,6 +21,61 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ProductEventType int32
+
+const (
+	ProductEventType_PRODUCT_EVENT_TYPE_UNSPECIFIED ProductEventType = 0
+	ProductEventType_PRODUCT_EVENT_TYPE_SNAPSHOT    ProductEventType = 1
+	ProductEventType_PRODUCT_EVENT_TYPE_CREATED     ProductEventType = 2
+	ProductEventType_PRODUCT_EVENT_TYPE_UPDATED     ProductEventType = 3
+	ProductEventType_PRODUCT_EVENT_TYPE_DELETED     ProductEventType = 4
+)
+
+// Enum value maps for ProductEventType.
+var (
+	ProductEventType_name = map[int32]string{
+		0: "PRODUCT_EVENT_TYPE_UNSPECIFIED",
+		1: "PRODUCT_EVENT_TYPE_SNAPSHOT",
+		2: "PRODUCT_EVENT_TYPE_CREATED",
+		3: "PRODUCT_EVENT_TYPE_UPDATED",
+		4: "PRODUCT_EVENT_TYPE_DELETED",
+	}
+	ProductEventType_value = map[string]int32{
+		"PRODUCT_EVENT_TYPE_UNSPECIFIED": 0,
+		"PRODUCT_EVENT_TYPE_SNAPSHOT":    1,
+		"PRODUCT_EVENT_TYPE_CREATED":     2,
+		"PRODUCT_EVENT_TYPE_UPDATED":     3,
+		"PRODUCT_EVENT_TYPE_DELETED":     4,
+	}
+)
+
+func (x ProductEventType) Enum() *ProductEventType {
+	p := new(ProductEventType)
+	*p = x
+	return p
+}
+
+func (x ProductEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProductEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_product_product_proto_enumTypes[0].Descriptor()
+}
+
+func (ProductEventType) Type() protoreflect.EnumType {
+	return &file_api_proto_product_product_proto_enumTypes[0]
+}
+
+func (x ProductEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProductEventType.Descriptor instead.
+func (ProductEventType) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_product_product_proto_rawDescGZIP(), []int{0}
+}
+
 type Product struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -669,6 +724,118 @@ func (x *ProductResponse) GetProduct() *Product {
 	return nil
 }
 
+type WatchProductsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Category        string                 `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`                                       // Only stream changes to products of this category; empty streams every category
+	IncludeSnapshot bool                   `protobuf:"varint,2,opt,name=include_snapshot,json=includeSnapshot,proto3" json:"include_snapshot,omitempty"` // Send every current product as a SNAPSHOT event before streaming changes
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchProductsRequest) Reset() {
+	*x = WatchProductsRequest{}
+	mi := &file_api_proto_product_product_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchProductsRequest) ProtoMessage() {}
+
+func (x *WatchProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_product_product_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchProductsRequest.ProtoReflect.Descriptor instead.
+func (*WatchProductsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_product_product_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchProductsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *WatchProductsRequest) GetIncludeSnapshot() bool {
+	if x != nil {
+		return x.IncludeSnapshot
+	}
+	return false
+}
+
+type ProductEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          ProductEventType       `protobuf:"varint,1,opt,name=type,proto3,enum=product.ProductEventType" json:"type,omitempty"`
+	Product       *Product               `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	OccurredAt    string                 `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"` // RFC 3339 timestamp of the change
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductEvent) Reset() {
+	*x = ProductEvent{}
+	mi := &file_api_proto_product_product_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductEvent) ProtoMessage() {}
+
+func (x *ProductEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_product_product_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductEvent.ProtoReflect.Descriptor instead.
+func (*ProductEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_product_product_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ProductEvent) GetType() ProductEventType {
+	if x != nil {
+		return x.Type
+	}
+	return ProductEventType_PRODUCT_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *ProductEvent) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
 var File_api_proto_product_product_proto protoreflect.FileDescriptor
 
 const file_api_proto_product_product_proto_rawDesc = "" +
@@ -714,7 +881,21 @@ const file_api_proto_product_product_proto_rawDesc = "" +
 	"\x1cGetProductsByCategoryRequest\x12\x1a\n" +
 	"\bcategory\x18\x01 \x01(\tR\bcategory\"=\n" +
 	"\x0fProductResponse\x12*\n" +
-	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct2\xaa\x05\n" +
+	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"]\n" +
+	"\x14WatchProductsRequest\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\tR\bcategory\x12)\n" +
+	"\x10include_snapshot\x18\x02 \x01(\bR\x0fincludeSnapshot\"\x8a\x01\n" +
+	"\fProductEvent\x12-\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x19.product.ProductEventTypeR\x04type\x12*\n" +
+	"\aproduct\x18\x02 \x01(\v2\x10.product.ProductR\aproduct\x12\x1f\n" +
+	"\voccurred_at\x18\x03 \x01(\tR\n" +
+	"occurredAt*\xb7\x01\n" +
+	"\x10ProductEventType\x12\"\n" +
+	"\x1ePRODUCT_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1f\n" +
+	"\x1bPRODUCT_EVENT_TYPE_SNAPSHOT\x10\x01\x12\x1e\n" +
+	"\x1aPRODUCT_EVENT_TYPE_CREATED\x10\x02\x12\x1e\n" +
+	"\x1aPRODUCT_EVENT_TYPE_UPDATED\x10\x03\x12\x1e\n" +
+	"\x1aPRODUCT_EVENT_TYPE_DELETED\x10\x042\xf3\x05\n" +
 	"\x0eProductService\x12B\n" +
 	"\n" +
 	"GetProduct\x12\x1a.product.GetProductRequest\x1a\x18.product.ProductResponse\x12H\n" +
@@ -724,7 +905,8 @@ const file_api_proto_product_product_proto_rawDesc = "" +
 	"\fListProducts\x12\x1c.product.ListProductsRequest\x1a\x1d.product.ListProductsResponse\x12i\n" +
 	"\x1bGetTopMostExpensiveProducts\x12+.product.GetTopMostExpensiveProductsRequest\x1a\x1d.product.ListProductsResponse\x12Y\n" +
 	"\x13GetLowStockProducts\x12#.product.GetLowStockProductsRequest\x1a\x1d.product.ListProductsResponse\x12]\n" +
-	"\x15GetProductsByCategory\x12%.product.GetProductsByCategoryRequest\x1a\x1d.product.ListProductsResponseB#Z!obs-tools-usage/api/proto/productb\x06proto3"
+	"\x15GetProductsByCategory\x12%.product.GetProductsByCategoryRequest\x1a\x1d.product.ListProductsResponse\x12G\n" +
+	"\rWatchProducts\x12\x1d.product.WatchProductsRequest\x1a\x15.product.ProductEvent0\x01B#Z!obs-tools-usage/api/proto/productb\x06proto3"
 
 var (
 	file_api_proto_product_product_proto_rawDescOnce sync.Once
@@ -738,45 +920,53 @@ func file_api_proto_product_product_proto_rawDescGZIP() []byte {
 	return file_api_proto_product_product_proto_rawDescData
 }
 
-var file_api_proto_product_product_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_api_proto_product_product_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_product_product_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_api_proto_product_product_proto_goTypes = []any{
-	(*Product)(nil),                            // 0: product.Product
-	(*GetProductRequest)(nil),                  // 1: product.GetProductRequest
-	(*CreateProductRequest)(nil),               // 2: product.CreateProductRequest
-	(*UpdateProductRequest)(nil),               // 3: product.UpdateProductRequest
-	(*DeleteProductRequest)(nil),               // 4: product.DeleteProductRequest
-	(*DeleteProductResponse)(nil),              // 5: product.DeleteProductResponse
-	(*ListProductsRequest)(nil),                // 6: product.ListProductsRequest
-	(*ListProductsResponse)(nil),               // 7: product.ListProductsResponse
-	(*GetTopMostExpensiveProductsRequest)(nil), // 8: product.GetTopMostExpensiveProductsRequest
-	(*GetLowStockProductsRequest)(nil),         // 9: product.GetLowStockProductsRequest
-	(*GetProductsByCategoryRequest)(nil),       // 10: product.GetProductsByCategoryRequest
-	(*ProductResponse)(nil),                    // 11: product.ProductResponse
+	(ProductEventType)(0),                      // 0: product.ProductEventType
+	(*Product)(nil),                            // 1: product.Product
+	(*GetProductRequest)(nil),                  // 2: product.GetProductRequest
+	(*CreateProductRequest)(nil),               // 3: product.CreateProductRequest
+	(*UpdateProductRequest)(nil),               // 4: product.UpdateProductRequest
+	(*DeleteProductRequest)(nil),               // 5: product.DeleteProductRequest
+	(*DeleteProductResponse)(nil),              // 6: product.DeleteProductResponse
+	(*ListProductsRequest)(nil),                // 7: product.ListProductsRequest
+	(*ListProductsResponse)(nil),               // 8: product.ListProductsResponse
+	(*GetTopMostExpensiveProductsRequest)(nil), // 9: product.GetTopMostExpensiveProductsRequest
+	(*GetLowStockProductsRequest)(nil),         // 10: product.GetLowStockProductsRequest
+	(*GetProductsByCategoryRequest)(nil),       // 11: product.GetProductsByCategoryRequest
+	(*ProductResponse)(nil),                    // 12: product.ProductResponse
+	(*WatchProductsRequest)(nil),               // 13: product.WatchProductsRequest
+	(*ProductEvent)(nil),                       // 14: product.ProductEvent
 }
 var file_api_proto_product_product_proto_depIdxs = []int32{
-	0,  // 0: product.ListProductsResponse.products:type_name -> product.Product
-	0,  // 1: product.ProductResponse.product:type_name -> product.Product
-	1,  // 2: product.ProductService.GetProduct:input_type -> product.GetProductRequest
-	2,  // 3: product.ProductService.CreateProduct:input_type -> product.CreateProductRequest
-	3,  // 4: product.ProductService.UpdateProduct:input_type -> product.UpdateProductRequest
-	4,  // 5: product.ProductService.DeleteProduct:input_type -> product.DeleteProductRequest
-	6,  // 6: product.ProductService.ListProducts:input_type -> product.ListProductsRequest
-	8,  // 7: product.ProductService.GetTopMostExpensiveProducts:input_type -> product.GetTopMostExpensiveProductsRequest
-	9,  // 8: product.ProductService.GetLowStockProducts:input_type -> product.GetLowStockProductsRequest
-	10, // 9: product.ProductService.GetProductsByCategory:input_type -> product.GetProductsByCategoryRequest
-	11, // 10: product.ProductService.GetProduct:output_type -> product.ProductResponse
-	11, // 11: product.ProductService.CreateProduct:output_type -> product.ProductResponse
-	11, // 12: product.ProductService.UpdateProduct:output_type -> product.ProductResponse
-	5,  // 13: product.ProductService.DeleteProduct:output_type -> product.DeleteProductResponse
-	7,  // 14: product.ProductService.ListProducts:output_type -> product.ListProductsResponse
-	7,  // 15: product.ProductService.GetTopMostExpensiveProducts:output_type -> product.ListProductsResponse
-	7,  // 16: product.ProductService.GetLowStockProducts:output_type -> product.ListProductsResponse
-	7,  // 17: product.ProductService.GetProductsByCategory:output_type -> product.ListProductsResponse
-	10, // [10:18] is the sub-list for method output_type
-	2,  // [2:10] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	1,  // 0: product.ListProductsResponse.products:type_name -> product.Product
+	1,  // 1: product.ProductResponse.product:type_name -> product.Product
+	0,  // 2: product.ProductEvent.type:type_name -> product.ProductEventType
+	1,  // 3: product.ProductEvent.product:type_name -> product.Product
+	2,  // 4: product.ProductService.GetProduct:input_type -> product.GetProductRequest
+	3,  // 5: product.ProductService.CreateProduct:input_type -> product.CreateProductRequest
+	4,  // 6: product.ProductService.UpdateProduct:input_type -> product.UpdateProductRequest
+	5,  // 7: product.ProductService.DeleteProduct:input_type -> product.DeleteProductRequest
+	7,  // 8: product.ProductService.ListProducts:input_type -> product.ListProductsRequest
+	9,  // 9: product.ProductService.GetTopMostExpensiveProducts:input_type -> product.GetTopMostExpensiveProductsRequest
+	10, // 10: product.ProductService.GetLowStockProducts:input_type -> product.GetLowStockProductsRequest
+	11, // 11: product.ProductService.GetProductsByCategory:input_type -> product.GetProductsByCategoryRequest
+	13, // 12: product.ProductService.WatchProducts:input_type -> product.WatchProductsRequest
+	12, // 13: product.ProductService.GetProduct:output_type -> product.ProductResponse
+	12, // 14: product.ProductService.CreateProduct:output_type -> product.ProductResponse
+	12, // 15: product.ProductService.UpdateProduct:output_type -> product.ProductResponse
+	6,  // 16: product.ProductService.DeleteProduct:output_type -> product.DeleteProductResponse
+	8,  // 17: product.ProductService.ListProducts:output_type -> product.ListProductsResponse
+	8,  // 18: product.ProductService.GetTopMostExpensiveProducts:output_type -> product.ListProductsResponse
+	8,  // 19: product.ProductService.GetLowStockProducts:output_type -> product.ListProductsResponse
+	8,  // 20: product.ProductService.GetProductsByCategory:output_type -> product.ListProductsResponse
+	14, // 21: product.ProductService.WatchProducts:output_type -> product.ProductEvent
+	13, // [13:22] is the sub-list for method output_type
+	4,  // [4:13] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_product_product_proto_init() }
@@ -789,13 +979,14 @@ func file_api_proto_product_product_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_product_product_proto_rawDesc), len(file_api_proto_product_product_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   12,
+			NumEnums:      1,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_product_product_proto_goTypes,
 		DependencyIndexes: file_api_proto_product_product_proto_depIdxs,
+		EnumInfos:         file_api_proto_product_product_proto_enumTypes,
 		MessageInfos:      file_api_proto_product_product_proto_msgTypes,
 	}.Build()
 	File_api_proto_product_product_proto = out.File
@@ -6,23 +6,37 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 
 	"obs-tools-usage/internal/basket/application/handler"
 	"obs-tools-usage/internal/basket/application/usecase"
+	"obs-tools-usage/internal/basket/domain/repository"
 	"obs-tools-usage/internal/basket/infrastructure/client"
 	"obs-tools-usage/internal/basket/infrastructure/config"
+	"obs-tools-usage/internal/basket/infrastructure/messaging"
 	"obs-tools-usage/internal/basket/infrastructure/metrics"
 	"obs-tools-usage/internal/basket/infrastructure/persistence"
 	httpInterface "obs-tools-usage/internal/basket/interfaces/http"
 	grpcInterface "obs-tools-usage/internal/basket/interfaces/grpc"
+	"obs-tools-usage/pkg/grpclimits"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/httpserver"
+	"obs-tools-usage/pkg/interceptor"
+	"obs-tools-usage/pkg/lock"
+	"obs-tools-usage/pkg/logging"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/kafka/consumer"
+	"obs-tools-usage/kafka/publisher"
+	"obs-tools-usage/pkg/tracing"
 )
 
 //go:generate wire
@@ -33,27 +47,63 @@ func main() {
 	logger := logrus.New()
 	logger.SetLevel(getLogLevel(cfg.LogLevel))
 	logger.SetFormatter(getLogFormatter(cfg.LogFormat))
+	logging.ApplySampling(logger, logging.SamplingConfig{
+		Rate:   cfg.LogSampling.Rate,
+		Demote: cfg.LogSampling.Demote,
+	})
 	
 	logger.Info("Basket service starting...")
+
+	// TLS that is enabled without certificates fails startup instead of serving plaintext
+	if err := cfg.GRPCTLS.Validate(); err != nil {
+		logger.WithError(err).Fatal("Invalid gRPC TLS configuration")
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "basket-service",
+		Environment: cfg.Environment,
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to initialize tracing, spans will not be exported")
+	}
 	
 	// Initialize Redis client
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-		PoolSize: cfg.Redis.PoolSize,
-	})
+	redisClient := persistence.NewRedisClient(cfg.Redis)
 	defer redisClient.Close()
+	redisClient.AddHook(tracing.NewRedisHook())
 	
-	// Test Redis connection
-	ctx := context.Background()
-	if err := redisClient.Ping(ctx).Err(); err != nil {
+	// Wait for Redis, retrying transient failures within the startup window
+	if err := persistence.WaitForRedis(context.Background(), redisClient, cfg.Redis.StartupTimeout, logger); err != nil {
 		logger.WithError(err).Fatal("Failed to connect to Redis")
 	}
 	logger.Info("Connected to Redis")
 	
+	// Start Redis health monitor
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	defer stopHealth()
+	redisHealth := persistence.NewRedisHealthMonitor(redisClient, cfg.Redis.HealthCheckInterval, logger)
+	go redisHealth.Start(healthCtx)
+	
+	// Start system metrics collector
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+	go metrics.StartSystemMetricsCollector(metricsCtx, cfg.Metrics.ScrapeInterval)
+	
+	// Mutual TLS between services; credentials are loaded up front so bad certificates fail startup
+	grpcServerCreds, err := cfg.GRPCTLS.ServerCredentials()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load gRPC TLS credentials")
+	}
+	if !cfg.GRPCTLS.Enabled {
+		logger.Warn("gRPC TLS disabled, inter-service connections are insecure")
+	}
+	
 	// Initialize product client
-	productClient, err := client.NewProductClientImpl(cfg.Product.ServiceURL, logger)
+	productClient, err := client.NewProductClientImpl(cfg.Product.ServiceURL, logger, cfg.GRPCTLS)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize product client")
 	}
@@ -61,34 +111,110 @@ func main() {
 	logger.Info("Connected to product service")
 	
 	// Initialize repository
-	basketRepo := persistence.NewBasketRepositoryImpl(redisClient, logger)
+	basketRepo := persistence.NewBasketRepositoryImpl(redisClient, logger, cfg.Basket.KeyPrefix)
+	
+	// Initialize Kafka publisher
+	var kafkaPublisher publisher.BasketEventPublisher
+	if cfg.Kafka.Enabled {
+		basketPublisher, err := publisher.NewBasketPublisher(cfg.Kafka.Brokers, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Kafka publisher")
+		}
+		kafkaPublisher = basketPublisher
+		logger.Info("Connected to Kafka")
+	} else {
+		kafkaPublisher = publisher.NewNoopPublisher(logger)
+		logger.Warn("Kafka publishing disabled, basket events will be discarded")
+	}
+	defer kafkaPublisher.Close()
+	
+	// Operation events can be turned off while abandonment events keep being published
+	operationPublisher := kafkaPublisher
+	if !cfg.Kafka.OperationEvents {
+		operationPublisher = publisher.NewNoopPublisher(logger)
+		logger.Info("Basket operation events disabled")
+	}
 	
 	// Initialize use case
-	basketUseCase := usecase.NewBasketUseCase(basketRepo, productClient, logger)
+	basketUseCase := usecase.NewBasketUseCase(basketRepo, productClient, logger, cfg.Basket, operationPublisher)
 	
 	// Initialize handlers
 	commandHandler := handler.NewCommandHandler(basketUseCase)
 	queryHandler := handler.NewQueryHandler(basketUseCase)
 	
 	// Initialize Gin router
-	r := gin.New()
-	r.Use(gin.Logger())
+	r, err := httpserver.NewEngine(httpserver.EngineConfig{
+		Mode:           cfg.HTTP.Mode,
+		TrustedProxies: cfg.HTTP.TrustedProxies,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize HTTP router")
+	}
+	r.Use(middleware.AccessLog(logger, func(method, route string, status int, latency time.Duration, _, _ int) {
+		metrics.RecordHTTPRequest(method, route, status, latency)
+	}, "/metrics"))
 	r.Use(gin.Recovery())
 	
+	// Start a trace span per request
+	r.Use(middleware.Tracing("basket-service"))
+	
+	// Limit request body size
+	r.Use(middleware.BodySizeLimit(cfg.MaxBodySize))
+	
 	// Add CORS middleware
 	r.Use(corsMiddleware())
 	
-	// Add metrics middleware
-	r.Use(metrics.HTTPLoggingMiddleware())
-	
 	// Add Prometheus metrics endpoint
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	
+	// Register health checks
+	checker := health.NewChecker("basket-service", "1.0.0")
+	checker.AddReadinessCheck("redis", redisHealth.Check)
+	checker.AddDependencyCheck("product-service", productClient.Ping)
+	
 	// Setup HTTP routes
-	httpInterface.SetupRoutes(r, commandHandler, queryHandler)
+	if cfg.Basket.AdminToken == "" {
+		logger.Warn("ADMIN_TOKEN is not set, admin routes will reject every request")
+	}
+	httpInterface.SetupRoutes(r, commandHandler, queryHandler, checker, cfg.Basket.AdminToken)
+	
+	// Periodic jobs run on one replica at a time, holding a lease in Redis
+	jobLocker := lock.NewLocker(lock.NewRedisBackend(redisClient, strings.TrimSuffix(cfg.Basket.KeyPrefix, ":")+"-lock:"), lock.Config{
+		Enabled: cfg.JobLock.Enabled,
+		TTL:     cfg.JobLock.TTL,
+	}, logger)
+	var jobs sync.WaitGroup
+	runJob := func(ctx context.Context, name string, job func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			jobLocker.Run(ctx, name, job)
+		}()
+	}
+	
+	// Start expiry sweep for expired baskets
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+	runJob(sweepCtx, "basket-expiry-sweep", func(ctx context.Context) {
+		startCleanupRoutine(ctx, basketRepo, cfg.Basket.ExpirySweepInterval, logger)
+	})
 	
-	// Start cleanup goroutine for expired baskets
-	go startCleanupRoutine(basketRepo, logger)
+	// Start abandonment detection for idle baskets
+	abandonmentDetector := messaging.NewAbandonmentDetector(basketRepo, kafkaPublisher, cfg.Basket, logger)
+	runJob(sweepCtx, "basket-abandonment-detector", abandonmentDetector.Start)
+	
+	// Consume checkout events so paid items are taken out of baskets and orders feed recommendations
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	defer stopConsumer()
+	if cfg.Kafka.Enabled {
+		eventHandler := consumer.NewBasketServiceEventHandler(logger, basketUseCase, basketUseCase)
+		basketConsumer, err := consumer.NewPaymentConsumer(cfg.Kafka.Brokers, cfg.Kafka.ConsumerGroup, eventHandler, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Kafka consumer")
+		}
+		defer basketConsumer.Stop()
+		go basketConsumer.Start(consumerCtx)
+	}
 	
 	// Create HTTP server
 	srv := &http.Server{
@@ -105,14 +231,35 @@ func main() {
 	}()
 
 	// Create gRPC server
-	grpcPort := "50051" // Basket service gRPC port
+	grpcPort := cfg.GRPCPort
 	lis, err := net.Listen("tcp", ":"+grpcPort)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to listen on gRPC port")
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcLimits := grpclimits.Config{
+		MaxConcurrentStreams: uint32(cfg.GRPCLimits.MaxConcurrentStreams),
+		MaxRecvMsgSize:       cfg.GRPCLimits.MaxRecvMsgSize,
+		KeepaliveTime:        cfg.GRPCLimits.KeepaliveTime,
+		KeepaliveTimeout:     cfg.GRPCLimits.KeepaliveTimeout,
+		MaxConnectionIdle:    cfg.GRPCLimits.MaxConnectionIdle,
+		MinPingInterval:      cfg.GRPCLimits.MinPingInterval,
+		PermitWithoutStream:  cfg.GRPCLimits.PermitWithoutStream,
+	}
+	grpcOptions := append([]grpc.ServerOption{
+		grpc.Creds(grpcServerCreds),
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor()),
+		grpc.ChainUnaryInterceptor(grpcInterface.UnaryMetricsInterceptor()),
+		grpc.ChainUnaryInterceptor(interceptor.UnaryServerInterceptors(logger)...),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor()),
+	}, grpcLimits.ServerOptions()...)
+	grpcServer := grpc.NewServer(grpcOptions...)
+	logger.WithFields(grpcLimits.LogFields()).Info("gRPC server limits applied")
 	grpcInterface.RegisterServer(grpcServer, commandHandler, queryHandler, logger)
+	if cfg.EnableGRPCReflection {
+		reflection.Register(grpcServer) // Enable reflection for grpcurl
+		logger.Info("gRPC reflection enabled")
+	}
 
 	// Start gRPC server in a goroutine
 	go func() {
@@ -141,18 +288,41 @@ func main() {
 	logger.Info("Shutting down gRPC server...")
 	grpcServer.GracefulStop()
 	
+	// Stop periodic jobs and release their leases so another replica can take over right away
+	stopSweep()
+	jobs.Wait()
+	
+	// Stop system metrics collector
+	stopMetrics()
+	
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to flush traces")
+	}
+	
 	logger.Info("Server exited")
 }
 
-// startCleanupRoutine starts a background routine to clean up expired baskets
-func startCleanupRoutine(repo interface{}, logger *logrus.Logger) {
-	ticker := time.NewTicker(1 * time.Hour) // Run every hour
+// startCleanupRoutine periodically sweeps the basket expiry index until the context is cancelled.
+// Redis TTL evicts the baskets; the sweep only prunes index entries and records baskets_expired_total.
+func startCleanupRoutine(ctx context.Context, repo repository.BasketRepository, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			logger.Info("Cleanup routine tick - Redis TTL handles expiration automatically")
+			expired, err := repo.ClearExpiredBaskets()
+			if err != nil {
+				logger.WithError(err).Warn("Failed to sweep expired baskets")
+				continue
+			}
+			metrics.RecordBasketsExpired(expired)
+			if expired > 0 {
+				logger.WithField("expired_count", expired).Info("Expired baskets swept")
+			}
 		}
 	}
 }
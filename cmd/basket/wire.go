@@ -4,6 +4,8 @@
 package main
 
 import (
+	"context"
+
 	"obs-tools-usage/internal/basket/application/handler"
 	"obs-tools-usage/internal/basket/application/usecase"
 	"obs-tools-usage/internal/basket/domain/repository"
@@ -12,9 +14,12 @@ import (
 	"obs-tools-usage/internal/basket/infrastructure/config"
 	"obs-tools-usage/internal/basket/infrastructure/persistence"
 	httpInterface "obs-tools-usage/internal/basket/interfaces/http"
+	"obs-tools-usage/kafka/publisher"
+	"obs-tools-usage/pkg/health"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/wire"
+	"github.com/sirupsen/logrus"
 )
 
 // ProviderSet is the provider set for dependency injection
@@ -31,7 +36,11 @@ var ProviderSet = wire.NewSet(
 	// Repository
 	NewBasketRepository,
 
+	// Kafka
+	NewBasketEventPublisher,
+
 	// Use Case
+	NewBasketConfig,
 	usecase.NewBasketUseCase,
 
 	// Handlers
@@ -39,29 +48,49 @@ var ProviderSet = wire.NewSet(
 	handler.NewQueryHandler,
 
 	// HTTP
+	NewHealthChecker,
 	httpInterface.NewHandler,
 	httpInterface.SetupRoutes,
 )
 
 // NewRedisClient provides Redis client
 func NewRedisClient(cfg *config.Config) *redis.Client {
-	return redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-		PoolSize: cfg.Redis.PoolSize,
-	})
+	return persistence.NewRedisClient(cfg.Redis)
 }
 
 // NewProductClient provides product client
 func NewProductClient(cfg *config.Config, redisClient *redis.Client) (service.ProductClient, error) {
 	// Note: We need a logger here, but for simplicity we'll use a basic one
 	// In a real implementation, you'd inject the logger properly
-	return client.NewProductClientImpl(cfg.Product.ServiceURL, nil)
+	return client.NewProductClientImpl(cfg.Product.ServiceURL, nil, cfg.GRPCTLS)
 }
 
 // NewBasketRepository provides basket repository
-func NewBasketRepository(redisClient *redis.Client) repository.BasketRepository {
+func NewBasketRepository(cfg *config.Config, redisClient *redis.Client) repository.BasketRepository {
 	// Note: We need a logger here, but for simplicity we'll use a basic one
-	return persistence.NewBasketRepositoryImpl(redisClient, nil)
+	return persistence.NewBasketRepositoryImpl(redisClient, nil, cfg.Basket.KeyPrefix)
+}
+
+// NewBasketEventPublisher provides the publisher of basket operation events, which discards
+// them when Kafka or operation events are disabled
+func NewBasketEventPublisher(cfg *config.Config) (publisher.BasketEventPublisher, error) {
+	if !cfg.Kafka.Enabled || !cfg.Kafka.OperationEvents {
+		return publisher.NewNoopPublisher(nil), nil
+	}
+	return publisher.NewBasketPublisher(cfg.Kafka.Brokers, logrus.StandardLogger())
+}
+
+// NewBasketConfig provides basket behaviour configuration
+func NewBasketConfig(cfg *config.Config) config.BasketConfig {
+	return cfg.Basket
+}
+
+// NewHealthChecker provides the health checker backing /health and /ready
+func NewHealthChecker(basketRepo repository.BasketRepository, productClient service.ProductClient) *health.Checker {
+	checker := health.NewChecker("basket-service", "1.0.0")
+	checker.AddReadinessCheck("redis", func(ctx context.Context) error {
+		return basketRepo.Ping()
+	})
+	checker.AddDependencyCheck("product-service", productClient.Ping)
+	return checker
 }
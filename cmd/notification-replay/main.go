@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/notification/application/handler"
+	"obs-tools-usage/internal/notification/application/usecase"
+	"obs-tools-usage/internal/notification/infrastructure/channel"
+	"obs-tools-usage/internal/notification/infrastructure/config"
+	"obs-tools-usage/internal/notification/infrastructure/persistence"
+	"obs-tools-usage/kafka/consumer"
+)
+
+// notification-replay re-dispatches a range of past Kafka events through the notification
+// event handlers, e.g. after a handler fix. Events already applied are skipped.
+//
+//	notification-replay -topic payment-events -from 1200 -to 1500
+func main() {
+	topic := flag.String("topic", "", "Kafka topic to replay")
+	fromOffset := flag.Int64("from", 0, "First offset to replay on each partition")
+	toOffset := flag.Int64("to", -1, "Last offset to replay on each partition (inclusive)")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	if *topic == "" || *toOffset < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	database, err := persistence.NewDatabase(cfg, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to connect to database")
+	}
+	defer database.Close()
+
+	processedEvents, err := consumer.NewGormProcessedEventStore(database.DB)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize processed event store")
+	}
+
+	// Replayed events create their notifications right away: no digests, fallbacks or rate limits
+	notificationUseCase := usecase.NewNotificationUseCase(
+		persistence.NewNotificationRepositoryImpl(database.DB, logger),
+		persistence.NewPreferenceRepository(database.DB, logger),
+		channel.NewChannelSenders(cfg, logger),
+		usecase.DigestConfig{},
+		usecase.FallbackConfig{},
+		usecase.NewSlidingWindowRateLimiter(nil),
+		usecase.RetryConfig{
+			MaxAttempts: cfg.DefaultRetryAttempts,
+			BaseBackoff: cfg.RetryBackoff,
+		},
+		logger,
+	)
+	eventHandler := consumer.NewNotificationServiceEventHandler(logger, handler.NewEventNotifier(handler.NewCommandHandler(notificationUseCase)))
+
+	retryPolicy := consumer.RetryPolicy{
+		MaxRetries:     cfg.KafkaMaxRetries,
+		InitialBackoff: cfg.KafkaRetryBackoff,
+		MaxBackoff:     cfg.KafkaMaxRetryBackoff,
+	}
+	brokers := strings.Split(cfg.KafkaBrokers, ",")
+	replayer, err := consumer.NewNotificationConsumer(brokers, "notification-replay", eventHandler, retryPolicy, processedEvents, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize Kafka consumer")
+	}
+	defer replayer.Stop()
+
+	// Stop cleanly between messages on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	result, err := replayer.ReplayEvents(ctx, *topic, *fromOffset, *toOffset)
+	if result != nil {
+		json.NewEncoder(os.Stdout).Encode(result)
+	}
+	if err != nil {
+		logger.WithError(err).Fatal("Event replay failed")
+	}
+}
@@ -14,11 +14,20 @@ import (
 
 	"obs-tools-usage/internal/notification/application/handler"
 	"obs-tools-usage/internal/notification/application/usecase"
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/infrastructure/channel"
 	"obs-tools-usage/internal/notification/infrastructure/config"
 	"obs-tools-usage/internal/notification/infrastructure/metrics"
 	"obs-tools-usage/internal/notification/infrastructure/persistence"
 	httpInterface "obs-tools-usage/internal/notification/interfaces/http"
 	"obs-tools-usage/kafka/consumer"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/httpserver"
+	"obs-tools-usage/pkg/lock"
+	"obs-tools-usage/pkg/logging"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/seed"
+	"obs-tools-usage/pkg/tracing"
 )
 
 func main() {
@@ -27,8 +36,24 @@ func main() {
 	logger := logrus.New()
 	logger.SetLevel(getLogLevel(cfg.LogLevel))
 	logger.SetFormatter(getLogFormatter(cfg.LogFormat))
+	logging.ApplySampling(logger, logging.SamplingConfig{
+		Rate:   cfg.LogSampleRate,
+		Demote: cfg.LogSampleDemote,
+	})
 	
 	logger.Info("Notification service starting...")
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "notification-service",
+		Environment: cfg.Environment,
+		Enabled:     cfg.TracingEnabled,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to initialize tracing, spans will not be exported")
+	}
 	
 	// Initialize database
 	database, err := persistence.NewDatabase(cfg, logger)
@@ -37,30 +62,108 @@ func main() {
 	}
 	defer database.Close()
 	
+	// Trace database operations
+	if err := database.DB.Use(tracing.NewGormPlugin("postgresql")); err != nil {
+		logger.WithError(err).Warn("Failed to register database tracing")
+	}
+	
 	// Run migrations
 	if err := database.Migrate(); err != nil {
 		logger.WithError(err).Fatal("Failed to run migrations")
 	}
 	
-	// Seed data (only in development)
-	if cfg.IsDevelopment() {
-		if err := database.SeedData(); err != nil {
+	// Seed sample data when enabled
+	if cfg.DBSeed {
+		if _, err := database.SeedData(); err != nil {
 			logger.WithError(err).Warn("Failed to seed data")
 		}
 	}
 	
 	logger.Info("Connected to database")
 	
+	// Start system metrics collector
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to get underlying sql.DB")
+	}
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+	go metrics.StartSystemMetricsCollector(metricsCtx, sqlDB, cfg.MetricsScrapeInterval)
+	
 	// Initialize repository
 	notificationRepo := persistence.NewNotificationRepositoryImpl(database.DB, logger)
+	preferenceRepo := persistence.NewPreferenceRepository(database.DB, logger)
 	
-	// Initialize Kafka consumer for events
+	// Initialize use case
+	channelSenders := channel.NewChannelSenders(cfg, logger)
+	digestTypes := make([]entity.NotificationType, 0, len(cfg.DigestTypes))
+	for _, notificationType := range cfg.DigestTypes {
+		digestTypes = append(digestTypes, entity.NotificationType(notificationType))
+	}
+	fallbackChains := make(map[entity.NotificationType][]entity.NotificationChannel, len(cfg.FallbackChains))
+	for notificationType, chain := range cfg.FallbackChains {
+		for _, channel := range chain {
+			fallbackChains[entity.NotificationType(notificationType)] = append(fallbackChains[entity.NotificationType(notificationType)], entity.NotificationChannel(channel))
+		}
+	}
+	rateLimits := make(map[entity.NotificationType]usecase.RateLimit, len(cfg.NotificationLimits))
+	for notificationType, limit := range cfg.NotificationLimits {
+		rateLimits[entity.NotificationType(notificationType)] = usecase.RateLimit{Max: limit.Max, Window: limit.Window}
+	}
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, preferenceRepo, channelSenders, usecase.DigestConfig{
+		Window: cfg.DigestWindow,
+		Types:  digestTypes,
+	}, usecase.FallbackConfig{
+		Chains:      fallbackChains,
+		MaxAttempts: cfg.FallbackMaxAttempts,
+	}, usecase.NewSlidingWindowRateLimiter(rateLimits), usecase.RetryConfig{
+		MaxAttempts: cfg.DefaultRetryAttempts,
+		BaseBackoff: cfg.RetryBackoff,
+	}, logger)
+	
+	// Background jobs run on one replica at a time, holding a lease in the database
+	leaseBackend, err := lock.NewGormBackend(database.DB)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize job lease table")
+	}
+	jobLocker := lock.NewLocker(leaseBackend, lock.Config{
+		Enabled: cfg.JobLockEnabled,
+		TTL:     cfg.JobLockTTL,
+	}, logger)
+	
+	// Retry failed notifications in background until shutdown
+	retryCtx, stopRetries := context.WithCancel(context.Background())
+	defer stopRetries()
+	if cfg.DefaultRetryAttempts > 0 {
+		retryWorker := handler.NewRetryWorker(notificationUseCase, cfg.RetryInterval, cfg.RetryBatchSize, logger)
+		go jobLocker.Run(retryCtx, "notification-retry-worker", retryWorker.Start)
+	}
+	
+	// Deliver digests whose window elapsed, including those left pending by a previous run
+	digestWorker := handler.NewDigestWorker(notificationUseCase, cfg.DigestFlushInterval, logger)
+	go jobLocker.Run(retryCtx, "notification-digest-worker", digestWorker.Start)
+	
+	// Initialize handlers
+	commandHandler := handler.NewCommandHandler(notificationUseCase)
+	queryHandler := handler.NewQueryHandler(notificationUseCase)
+	
+	// Initialize Kafka consumer for events, creating their notifications through the command handler
 	kafkaBrokers := []string{"localhost:9092"} // In production, this should come from config
-	eventHandler := consumer.NewNotificationEventHandler(logger)
+	eventHandler := consumer.NewNotificationServiceEventHandler(logger, handler.NewEventNotifier(commandHandler))
+	processedEvents, err := consumer.NewGormProcessedEventStore(database.DB)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize processed event store")
+	}
 	
 	// Start Kafka consumer in background
 	go func() {
-		consumer, err := consumer.NewNotificationConsumer(kafkaBrokers, "notification-service", eventHandler, logger)
+		retryPolicy := consumer.RetryPolicy{
+			MaxRetries:      cfg.KafkaMaxRetries,
+			InitialBackoff:  cfg.KafkaRetryBackoff,
+			MaxBackoff:      cfg.KafkaMaxRetryBackoff,
+			DeadLetterTopic: cfg.KafkaDeadLetterTopic,
+		}
+		consumer, err := consumer.NewNotificationConsumer(kafkaBrokers, "notification-service", eventHandler, retryPolicy, processedEvents, logger)
 		if err != nil {
 			logger.WithError(err).Fatal("Failed to initialize Kafka consumer")
 		}
@@ -72,26 +175,47 @@ func main() {
 	}()
 	logger.Info("Connected to Kafka")
 	
-	// Initialize use case
-	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, logger)
-	
-	// Initialize handlers
-	commandHandler := handler.NewCommandHandler(notificationUseCase)
-	queryHandler := handler.NewQueryHandler(notificationUseCase)
-	
 	// Initialize Gin router
-	r := gin.New()
-	r.Use(gin.Logger())
+	r, err := httpserver.NewEngine(httpserver.EngineConfig{
+		Mode:           cfg.GinMode,
+		TrustedProxies: cfg.TrustedProxies,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize HTTP router")
+	}
+	r.Use(middleware.AccessLog(logger, func(method, route string, status int, latency time.Duration, _, _ int) {
+		metrics.RecordHTTPRequest(method, route, status, latency)
+	}, "/metrics"))
 	r.Use(gin.Recovery())
 	
+	// Start a trace span per request
+	r.Use(middleware.Tracing("notification-service"))
+	
+	// Limit request body size
+	r.Use(middleware.BodySizeLimit(cfg.MaxBodySize))
+	r.Use(middleware.Gzip(cfg.GzipMinSize, "/metrics"))
+	
 	// Add CORS middleware
 	r.Use(corsMiddleware())
 	
 	// Add Prometheus metrics endpoint
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	
+	// Register health checks
+	checker := health.NewChecker("notification-service", "1.0.0")
+	checker.AddReadinessCheck("database", notificationRepo.Ping)
+	
 	// Setup HTTP routes
-	httpInterface.SetupRoutes(r, commandHandler, queryHandler)
+	if cfg.AdminToken == "" {
+		logger.Warn("ADMIN_TOKEN is not set, admin routes will reject every request")
+	}
+	httpInterface.SetupRoutes(r, commandHandler, queryHandler, checker, cfg.MaxPageSize, cfg.AdminToken, cfg.APIPrefix)
+
+	// Re-seeding on demand is a development convenience: it needs SEED_ENDPOINT_ENABLED,
+	// a development environment and the admin token
+	if cfg.IsDevelopment() && cfg.SeedEndpoint {
+		r.POST("/admin/seed", middleware.AdminAuth(cfg.AdminToken), seed.Handler(database.SeedData))
+	}
 	
 	// Create HTTP server
 	srv := &http.Server{
@@ -122,6 +246,17 @@ func main() {
 		logger.WithError(err).Fatal("HTTP server forced to shutdown")
 	}
 	
+	// Stop retrying and flushing digests before tracing is flushed
+	stopRetries()
+	
+	// Stop system metrics collector
+	stopMetrics()
+	
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to flush traces")
+	}
+	
 	logger.Info("Server exited")
 }
 
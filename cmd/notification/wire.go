@@ -7,6 +7,7 @@ import (
 	"github.com/google/wire"
 	"obs-tools-usage/internal/notification/application/handler"
 	"obs-tools-usage/internal/notification/application/usecase"
+	"obs-tools-usage/internal/notification/infrastructure/channel"
 	"obs-tools-usage/internal/notification/infrastructure/config"
 	"obs-tools-usage/internal/notification/infrastructure/metrics"
 	"obs-tools-usage/internal/notification/infrastructure/persistence"
@@ -20,6 +21,10 @@ var WireSet = wire.NewSet(
 	
 	// Repository
 	persistence.NewNotificationRepository,
+	persistence.NewPreferenceRepository,
+	
+	// Channel senders
+	channel.NewChannelSenders,
 	
 	// Use case
 	usecase.NewNotificationUseCase,
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -13,15 +14,28 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 
 	"obs-tools-usage/internal/payment/application/handler"
 	"obs-tools-usage/internal/payment/application/usecase"
+	"obs-tools-usage/internal/payment/domain/service"
 	"obs-tools-usage/internal/payment/infrastructure/client"
 	"obs-tools-usage/internal/payment/infrastructure/config"
+	"obs-tools-usage/internal/payment/infrastructure/messaging"
+	"obs-tools-usage/internal/payment/infrastructure/metrics"
 	"obs-tools-usage/internal/payment/infrastructure/persistence"
 	httpInterface "obs-tools-usage/internal/payment/interfaces/http"
 	grpcInterface "obs-tools-usage/internal/payment/interfaces/grpc"
 	"obs-tools-usage/kafka/publisher"
+	"obs-tools-usage/pkg/grpclimits"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/httpserver"
+	"obs-tools-usage/pkg/interceptor"
+	"obs-tools-usage/pkg/lock"
+	"obs-tools-usage/pkg/logging"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/seed"
+	"obs-tools-usage/pkg/tracing"
 )
 
 func main() {
@@ -30,8 +44,29 @@ func main() {
 	logger := logrus.New()
 	logger.SetLevel(getLogLevel(cfg.LogLevel))
 	logger.SetFormatter(getLogFormatter(cfg.LogFormat))
+	logging.ApplySampling(logger, logging.SamplingConfig{
+		Rate:   cfg.LogSampling.Rate,
+		Demote: cfg.LogSampling.Demote,
+	})
 	
 	logger.Info("Payment service starting...")
+
+	// TLS that is enabled without certificates fails startup instead of serving plaintext
+	if err := cfg.GRPCTLS.Validate(); err != nil {
+		logger.WithError(err).Fatal("Invalid gRPC TLS configuration")
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "payment-service",
+		Environment: cfg.Environment,
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to initialize tracing, spans will not be exported")
+	}
 	
 	// Initialize database
 	database, err := persistence.NewDatabase(cfg, logger)
@@ -40,29 +75,59 @@ func main() {
 	}
 	defer database.Close()
 	
+	// Trace database operations
+	if err := database.DB.Use(tracing.NewGormPlugin("mysql")); err != nil {
+		logger.WithError(err).Warn("Failed to register database tracing")
+	}
+	
 	// Run migrations
 	if err := database.Migrate(); err != nil {
 		logger.WithError(err).Fatal("Failed to run migrations")
 	}
 	
-	// Seed data (only in development)
-	if cfg.IsDevelopment() {
-		if err := database.SeedData(); err != nil {
+	// Index the metadata keys payments are commonly searched by; without the index searches still work
+	indexedMetadataKeys := cfg.Database.IndexedMetadataKeys
+	if err := database.IndexMetadataKeys(indexedMetadataKeys); err != nil {
+		logger.WithError(err).Warn("Failed to index payment metadata keys, searches by them scan the table")
+		indexedMetadataKeys = nil
+	}
+	
+	// Seed sample data when enabled
+	if cfg.Database.Seed {
+		if _, err := database.SeedData(); err != nil {
 			logger.WithError(err).Warn("Failed to seed data")
 		}
 	}
 	
 	logger.Info("Connected to MariaDB database")
 	
+	// Start system metrics collector
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to get underlying sql.DB")
+	}
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+	go metrics.StartSystemMetricsCollector(metricsCtx, sqlDB, cfg.Metrics.ScrapeInterval)
+	
+	// Mutual TLS between services; credentials are loaded up front so bad certificates fail startup
+	grpcServerCreds, err := cfg.GRPCTLS.ServerCredentials()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load gRPC TLS credentials")
+	}
+	if !cfg.GRPCTLS.Enabled {
+		logger.Warn("gRPC TLS disabled, inter-service connections are insecure")
+	}
+	
 	// Initialize gRPC clients
-	basketClient, err := client.NewBasketClientImpl(cfg.Basket.ServiceURL, logger)
+	basketClient, err := client.NewBasketClientImpl(cfg.Basket.ServiceURL, logger, cfg.GRPCTLS)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize basket client")
 	}
 	defer basketClient.Close()
 	logger.Info("Connected to basket service")
 	
-	productClient, err := client.NewProductClientImpl(cfg.Product.ServiceURL, logger)
+	productClient, err := client.NewProductClientImpl(cfg.Product.ServiceURL, logger, cfg.GRPCTLS)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize product client")
 	}
@@ -70,37 +135,130 @@ func main() {
 	logger.Info("Connected to product service")
 	
 	// Initialize repository
-	paymentRepo := persistence.NewPaymentRepositoryImpl(database.DB, logger)
+	paymentRepo := persistence.NewPaymentRepositoryImpl(database.DB, logger, indexedMetadataKeys)
 	
 	// Initialize Kafka publisher
-	kafkaBrokers := []string{"localhost:9092"} // In production, this should come from config
-	kafkaPublisher, err := publisher.NewPaymentPublisher(kafkaBrokers, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize Kafka publisher")
+	var kafkaPublisher publisher.EventPublisher
+	if cfg.Kafka.Enabled {
+		paymentPublisher, err := publisher.NewPaymentPublisher(cfg.Kafka.Brokers, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Kafka publisher")
+		}
+		kafkaPublisher = paymentPublisher
+		logger.Info("Connected to Kafka")
+	} else {
+		kafkaPublisher = publisher.NewNoopPublisher(logger)
+		logger.Warn("Kafka publishing disabled, payment events are kept in the outbox until Kafka is enabled")
 	}
 	defer kafkaPublisher.Close()
-	logger.Info("Connected to Kafka")
+	
+	// Periodic jobs run on one replica at a time, holding a lease in the database
+	leaseBackend, err := lock.NewGormBackend(database.DB)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize job lease table")
+	}
+	jobLocker := lock.NewLocker(leaseBackend, lock.Config{
+		Enabled: cfg.JobLock.Enabled,
+		TTL:     cfg.JobLock.TTL,
+	}, logger)
+	var jobs sync.WaitGroup
+	runJob := func(ctx context.Context, name string, job func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			jobLocker.Run(ctx, name, job)
+		}()
+	}
+	
+	// Start outbox relay
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	if cfg.Kafka.Enabled {
+		outboxRelay := messaging.NewOutboxRelay(paymentRepo, kafkaPublisher, cfg.Outbox, logger)
+		runJob(relayCtx, "payment-outbox-relay", outboxRelay.Start)
+	} else {
+		logger.Warn("Kafka disabled, outbox events stay pending until it is enabled")
+	}
+	outboxJanitor := messaging.NewOutboxJanitor(paymentRepo, cfg.Outbox, logger)
+	runJob(relayCtx, "payment-outbox-janitor", outboxJanitor.Start)
+	
+	// Initialize exchange rates for converted payment totals
+	exchangeRates := client.NewStaticExchangeRateProvider(cfg.Exchange.BaseCurrency, cfg.Exchange.Rates)
+	
+	// Initialize payment provider (simulated outside of the real mode)
+	var paymentProvider service.PaymentProvider
+	switch cfg.Provider.Mode {
+	case client.ProviderModeReal:
+		if cfg.Provider.URL == "" {
+			logger.Fatal("PAYMENT_PROVIDER_URL is required when PAYMENT_PROVIDER_MODE is real")
+		}
+		paymentProvider = client.NewHTTPPaymentProvider(cfg.Provider.URL, cfg.Provider.Timeout)
+	case client.ProviderModeAlwaysSucceed, client.ProviderModeAlwaysFail, client.ProviderModeRandom:
+		paymentProvider = client.NewSimulatedPaymentProvider(cfg.Provider.Mode, cfg.Provider.FailureRate, cfg.Provider.Latency)
+	default:
+		logger.WithField("mode", cfg.Provider.Mode).Fatal("Unknown payment provider mode")
+	}
+	logger.WithField("mode", cfg.Provider.Mode).Info("Payment provider configured")
 	
 	// Initialize use case
-	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, basketClient, productClient, kafkaPublisher, logger)
+	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, basketClient, productClient, kafkaPublisher, logger, cfg.Expiry, cfg.Retry, exchangeRates, paymentProvider, cfg.Fees, cfg.Processing, cfg.Currency, cfg.Checkout)
 	
 	// Initialize handlers
 	commandHandler := handler.NewCommandHandler(paymentUseCase)
 	queryHandler := handler.NewQueryHandler(paymentUseCase)
 	
+	// Reconcile payments left in processing with the provider
+	if cfg.Reconcile.Enabled {
+		reconcileJob := handler.NewReconcileJob(paymentUseCase, cfg.Reconcile, logger)
+		runJob(relayCtx, "payment-reconcile", reconcileJob.Start)
+	}
+	
 	// Initialize Gin router
-	r := gin.New()
-	r.Use(gin.Logger())
+	r, err := httpserver.NewEngine(httpserver.EngineConfig{
+		Mode:           cfg.HTTP.Mode,
+		TrustedProxies: cfg.HTTP.TrustedProxies,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize HTTP router")
+	}
+	r.Use(middleware.AccessLog(logger, func(method, route string, status int, latency time.Duration, _, _ int) {
+		metrics.RecordHTTPRequest(method, route, status, latency)
+	}, "/metrics"))
 	r.Use(gin.Recovery())
 	
+	// Start a trace span per request
+	r.Use(middleware.Tracing("payment-service"))
+	
+	// Limit request body size
+	r.Use(middleware.BodySizeLimit(cfg.MaxBodySize))
+	r.Use(middleware.Gzip(cfg.GzipMinSize, "/metrics"))
+	
 	// Add CORS middleware
 	r.Use(corsMiddleware())
 	
 	// Add Prometheus metrics endpoint
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	
+	// Register health checks
+	checker := health.NewChecker("payment-service", "1.0.0")
+	checker.AddReadinessCheck("database", func(ctx context.Context) error {
+		return paymentRepo.Ping()
+	})
+	checker.AddInfo("database_pool", health.DBPoolInfo(sqlDB))
+	checker.AddDependencyCheck("basket-service", basketClient.Ping)
+	checker.AddDependencyCheck("product-service", productClient.Ping)
+	
 	// Setup HTTP routes
-	httpInterface.SetupRoutes(r, commandHandler, queryHandler)
+	if cfg.AdminToken == "" {
+		logger.Warn("ADMIN_TOKEN is not set, admin routes will reject every request")
+	}
+	httpInterface.SetupRoutes(r, commandHandler, queryHandler, checker, cfg.MaxPageSize, cfg.AdminToken, cfg.APIPrefix)
+
+	// Re-seeding on demand is a development convenience: it needs SEED_ENDPOINT_ENABLED,
+	// a development environment and the admin token
+	if cfg.IsDevelopment() && cfg.SeedEndpointEnabled {
+		r.POST("/admin/seed", middleware.AdminAuth(cfg.AdminToken), seed.Handler(database.SeedData))
+	}
 	
 	// Create HTTP server
 	srv := &http.Server{
@@ -117,14 +275,34 @@ func main() {
 	}()
 
 	// Create gRPC server
-	grpcPort := "50052" // Payment service gRPC port
+	grpcPort := cfg.GRPCPort
 	lis, err := net.Listen("tcp", ":"+grpcPort)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to listen on gRPC port")
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcLimits := grpclimits.Config{
+		MaxConcurrentStreams: uint32(cfg.GRPCLimits.MaxConcurrentStreams),
+		MaxRecvMsgSize:       cfg.GRPCLimits.MaxRecvMsgSize,
+		KeepaliveTime:        cfg.GRPCLimits.KeepaliveTime,
+		KeepaliveTimeout:     cfg.GRPCLimits.KeepaliveTimeout,
+		MaxConnectionIdle:    cfg.GRPCLimits.MaxConnectionIdle,
+		MinPingInterval:      cfg.GRPCLimits.MinPingInterval,
+		PermitWithoutStream:  cfg.GRPCLimits.PermitWithoutStream,
+	}
+	grpcOptions := append([]grpc.ServerOption{
+		grpc.Creds(grpcServerCreds),
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor()),
+		grpc.ChainUnaryInterceptor(interceptor.UnaryServerInterceptors(logger)...),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor()),
+	}, grpcLimits.ServerOptions()...)
+	grpcServer := grpc.NewServer(grpcOptions...)
+	logger.WithFields(grpcLimits.LogFields()).Info("gRPC server limits applied")
 	grpcInterface.RegisterServer(grpcServer, commandHandler, queryHandler, logger)
+	if cfg.EnableGRPCReflection {
+		reflection.Register(grpcServer) // Enable reflection for grpcurl
+		logger.Info("gRPC reflection enabled")
+	}
 
 	// Start gRPC server in a goroutine
 	go func() {
@@ -153,6 +331,18 @@ func main() {
 	logger.Info("Shutting down gRPC server...")
 	grpcServer.GracefulStop()
 	
+	// Stop outbox relay and the other periodic jobs, releasing their leases
+	stopRelay()
+	jobs.Wait()
+	
+	// Stop system metrics collector
+	stopMetrics()
+	
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to flush traces")
+	}
+	
 	logger.Info("Server exited")
 }
 
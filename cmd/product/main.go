@@ -13,10 +13,22 @@ import (
 
 	"obs-tools-usage/internal/product/application/handler"
 	"obs-tools-usage/internal/product/application/usecase"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/internal/product/infrastructure/cache"
 	"obs-tools-usage/internal/product/infrastructure/config"
+	"obs-tools-usage/internal/product/infrastructure/external"
 	"obs-tools-usage/internal/product/infrastructure/persistence"
 	"obs-tools-usage/internal/product/interfaces/grpc"
 	httpInterface "obs-tools-usage/internal/product/interfaces/http"
+	"obs-tools-usage/pkg/grpclimits"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/httpserver"
+	"obs-tools-usage/pkg/logging"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/kafka/consumer"
+	"obs-tools-usage/kafka/publisher"
+	"obs-tools-usage/pkg/seed"
+	"obs-tools-usage/pkg/tracing"
 )
 
 //go:generate wire
@@ -25,8 +37,29 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 	logger := config.GetLogger()
+	logging.ApplySampling(logger, logging.SamplingConfig{
+		Rate:   cfg.LogSampling.Rate,
+		Demote: cfg.LogSampling.Demote,
+	})
 	
 	logger.Info("Product service starting...")
+
+	// TLS that is enabled without certificates fails startup instead of serving plaintext
+	if err := cfg.GRPCTLS.Validate(); err != nil {
+		logger.WithError(err).Fatal("Invalid gRPC TLS configuration")
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: "product-service",
+		Environment: cfg.Environment,
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to initialize tracing, spans will not be exported")
+	}
 	
 	// Initialize database
 	db, err := persistence.NewDatabase(&cfg.Database)
@@ -35,42 +68,145 @@ func main() {
 	}
 	defer db.Close()
 	
+	// Trace database operations
+	if err := db.DB.Use(tracing.NewGormPlugin("postgresql")); err != nil {
+		logger.WithError(err).Warn("Failed to register database tracing")
+	}
+	
 	// Run database migrations
 	if err := db.Migrate(); err != nil {
 		logger.WithError(err).Fatal("Failed to run database migrations")
 	}
 	
 	// Seed database with initial data
-	if err := db.SeedData(); err != nil {
-		logger.WithError(err).Warn("Failed to seed database")
+	if cfg.Database.Seed {
+		if _, err := db.SeedData(); err != nil {
+			logger.WithError(err).Warn("Failed to seed database")
+		}
 	}
 	
+	// Register service metrics once, with the registry served on /metrics
+	metrics := external.NewMetrics(external.GetPrometheusMetrics())
+
+	// Start system metrics collector
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to get underlying sql.DB")
+	}
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+	go metrics.StartSystemMetricsCollector(metricsCtx, sqlDB, cfg.Metrics.ScrapeInterval)
+	
 	// Initialize repository
-	productRepo := persistence.NewProductRepositoryImpl(db.DB)
+	productRepo := persistence.NewProductRepositoryImpl(db.DB, cfg.Stock.LowStockThreshold, metrics)
+	
+	// Cache category listings, in memory unless a shared backend is configured
+	categoryCache, err := cache.NewCategoryCache(cfg.Cache, metrics, logger)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to initialize category cache, caching category listings in memory")
+		categoryCache = cache.NewTTLCache[string, []entity.Product]("category", cfg.Cache.CategoryTTL, metrics)
+	}
 	
 	// Initialize use case
-	productUseCase := usecase.NewProductUseCase(productRepo)
+	productUseCase := usecase.NewProductUseCase(productRepo, cfg.Cache, cfg.Validation, metrics, categoryCache)
+	
+	// Keep the product stats snapshot fresh in the background
+	go productUseCase.StartStatsRefresher(metricsCtx, cfg.Cache.StatsRefreshInterval)
 	
 	// Initialize handlers
 	commandHandler := handler.NewCommandHandler(productUseCase)
 	queryHandler := handler.NewQueryHandler(productUseCase)
 	
+	// Apply stock updates from Kafka and publish stock alerts
+	if cfg.Kafka.Enabled {
+		stockPublisher, err := publisher.NewStockPublisher(cfg.Kafka.Brokers, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Kafka publisher")
+		}
+		defer stockPublisher.Close()
+		
+		stockHandler := handler.NewStockEventHandler(productUseCase, stockPublisher, cfg.Stock.LowStockThreshold, logger)
+		retryPolicy := consumer.RetryPolicy{
+			MaxRetries:      cfg.Kafka.MaxRetries,
+			InitialBackoff:  cfg.Kafka.RetryBackoff,
+			MaxBackoff:      cfg.Kafka.MaxRetryBackoff,
+			DeadLetterTopic: cfg.Kafka.DeadLetterTopic,
+		}
+		stockConsumer, err := consumer.NewStockConsumer(cfg.Kafka.Brokers, cfg.Kafka.ConsumerGroup, stockHandler, retryPolicy, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Kafka consumer")
+		}
+		defer stockConsumer.Stop()
+		
+		go stockConsumer.Start(metricsCtx)
+		
+		// Announce promotions as they start
+		promotionPublisher, err := publisher.NewPromotionPublisher(cfg.Kafka.Brokers, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize Kafka publisher")
+		}
+		defer promotionPublisher.Close()
+		
+		promotionScheduler := handler.NewPromotionScheduler(productUseCase, promotionPublisher, cfg.Promotion.PollInterval, cfg.Promotion.BatchSize, logger)
+		go promotionScheduler.Start(metricsCtx)
+		logger.Info("Connected to Kafka")
+	} else {
+		logger.Warn("Kafka disabled, stock updates from payments will not be applied and promotions will not be announced")
+	}
+	
 	// Initialize gRPC server
-	grpcServer := grpc.NewGRPCServer(commandHandler, queryHandler, productRepo)
+	grpcServer := grpc.NewGRPCServer(commandHandler, queryHandler, productRepo, cfg.EnableGRPCReflection, cfg.GRPCTLS, grpclimits.Config{
+		MaxConcurrentStreams: uint32(cfg.GRPCLimits.MaxConcurrentStreams),
+		MaxRecvMsgSize:       cfg.GRPCLimits.MaxRecvMsgSize,
+		KeepaliveTime:        cfg.GRPCLimits.KeepaliveTime,
+		KeepaliveTimeout:     cfg.GRPCLimits.KeepaliveTimeout,
+		MaxConnectionIdle:    cfg.GRPCLimits.MaxConnectionIdle,
+		MinPingInterval:      cfg.GRPCLimits.MinPingInterval,
+		PermitWithoutStream:  cfg.GRPCLimits.PermitWithoutStream,
+	})
 	
 	// Initialize Gin router
-	r := gin.New()
-	r.Use(gin.Logger())
+	r, err := httpserver.NewEngine(httpserver.EngineConfig{
+		Mode:           cfg.HTTP.Mode,
+		TrustedProxies: cfg.HTTP.TrustedProxies,
+	})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize HTTP router")
+	}
+	r.Use(middleware.AccessLog(logger, metrics.RecordHTTPRequest, "/metrics"))
 	r.Use(gin.Recovery())
 	
+	// Start a trace span per request
+	r.Use(middleware.Tracing("product-service"))
+	
+	// Limit request body size
+	r.Use(middleware.BodySizeLimit(cfg.MaxBodySize))
+	r.Use(middleware.Gzip(cfg.GzipMinSize, "/metrics"))
+	
 	// Add CORS middleware
 	r.Use(corsMiddleware())
 	
 	// Add Prometheus metrics endpoint
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	
+	// Register health checks
+	checker := health.NewChecker("product-service", "1.0.0")
+	checker.AddReadinessCheck("database", func(ctx context.Context) error {
+		return productRepo.Ping()
+	})
+	checker.AddInfo("database_pool", health.DBPoolInfo(sqlDB))
+	
 	// Setup HTTP routes
-	httpInterface.SetupRoutes(r, commandHandler, queryHandler)
+	if cfg.AdminToken == "" {
+		logger.Warn("ADMIN_TOKEN is not set, admin routes will reject every request")
+	}
+	httpInterface.SetupRoutes(r, commandHandler, queryHandler, checker, cfg.MaxPageSize, cfg.AdminToken, cfg.APIPrefix)
+
+	// Re-seeding on demand is a development convenience: it needs SEED_ENDPOINT_ENABLED,
+	// a development environment and the admin token
+	if cfg.IsDevelopment() && cfg.SeedEndpointEnabled {
+		r.POST("/admin/seed", middleware.AdminAuth(cfg.AdminToken), seed.Handler(db.SeedData))
+	}
 	
 	// Create HTTP server
 	srv := &http.Server{
@@ -88,8 +224,8 @@ func main() {
 	
 	// Start gRPC server in a goroutine
 	go func() {
-		logger.WithField("port", 50050).Info("Starting gRPC server")
-		if err := grpcServer.Start(50050); err != nil {
+		logger.WithField("port", cfg.GetGRPCPort()).Info("Starting gRPC server")
+		if err := grpcServer.Start(cfg.GetGRPCPort()); err != nil {
 			logger.WithError(err).Fatal("Failed to start gRPC server")
 		}
 	}()
@@ -104,6 +240,9 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	
+	// Stop system metrics collector
+	stopMetrics()
+	
 	// Shutdown gRPC server
 	grpcServer.Stop()
 	
@@ -112,6 +251,11 @@ func main() {
 		logger.WithError(err).Fatal("HTTP server forced to shutdown")
 	}
 	
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		logger.WithError(err).Warn("Failed to flush traces")
+	}
+	
 	logger.Info("Server exited")
 }
 
@@ -120,7 +264,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Cache-Control")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -11,7 +11,6 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/sirupsen/logrus"
 
@@ -23,6 +22,7 @@ import (
 	"fiberv2-gateway/internal/ratelimiter"
 	"fiberv2-gateway/internal/redis"
 	"fiberv2-gateway/internal/middleware"
+	"fiberv2-gateway/internal/respond"
 )
 
 func main() {
@@ -62,32 +62,36 @@ func main() {
 		ServerHeader: "FiberV2-Gateway",
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			logger.WithError(err).Error("Request error")
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Internal Server Error",
-			})
+			return respond.Error(c, 500, "Internal Server Error")
 		},
 	})
 
+	// Rejects new requests while the gateway drains
+	drainer := middleware.NewDrainer(cfg.Admin.DrainRetryAfter)
+
 	// Setup middleware
-	setupMiddleware(app, logger, rateLimiter, cfg)
+	setupMiddleware(app, logger, rateLimiter, cfg, drainer)
 
 	// Setup metrics
 	metrics.SetupMetrics(app)
 
 	// Setup health checks
-	health.SetupHealthRoutes(app)
+	health.SetupHealthRoutes(app, drainer.Draining)
 
 	// Setup gateway routes
-	gateway.SetupRoutes(app, cfg, logger)
+	gw := gateway.SetupRoutes(app, cfg, logger, drainer)
 
 	// Start server
-	startServer(app, cfg, logger)
+	startServer(app, gw, cfg, logger)
 }
 
-func setupMiddleware(app *fiber.App, logger *logrus.Logger, rateLimiter *ratelimiter.SlidingWindowRateLimiter, cfg *config.Config) {
+func setupMiddleware(app *fiber.App, logger *logrus.Logger, rateLimiter *ratelimiter.SlidingWindowRateLimiter, cfg *config.Config, drainer *middleware.Drainer) {
 	// Recovery middleware
 	app.Use(recover.New())
 
+	// Reject new requests first while draining; admin and health routes stay reachable
+	app.Use(drainer.Middleware("/admin", "/health", cfg.Metrics.Path))
+
 	// CORS middleware
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
@@ -95,11 +99,6 @@ func setupMiddleware(app *fiber.App, logger *logrus.Logger, rateLimiter *ratelim
 		AllowHeaders: "Origin,Content-Type,Accept,Authorization,X-Request-ID,X-User-ID",
 	}))
 
-	// Logger middleware
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${method} ${path} - ${latency}\n",
-	}))
-
 	// Custom request ID middleware
 	app.Use(func(c *fiber.Ctx) error {
 		requestID := c.Get("X-Request-ID")
@@ -111,8 +110,18 @@ func setupMiddleware(app *fiber.App, logger *logrus.Logger, rateLimiter *ratelim
 		return c.Next()
 	})
 
+	// Structured access log
+	app.Use(middleware.RequestLoggerMiddleware(logger))
+
 	// Rate limiting middleware
 	if cfg.RateLimit.Enabled {
+		// Internal service traffic is exempt from rate limits
+		bypass, err := middleware.RateLimitBypassMiddleware(cfg.RateLimit, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid rate limit bypass configuration")
+		}
+		app.Use(bypass)
+
 		rateLimitConfig := ratelimiter.RateLimitConfig{
 			WindowSize:  cfg.RateLimit.Window,
 			MaxRequests: cfg.RateLimit.Requests,
@@ -150,11 +159,14 @@ func setupMiddleware(app *fiber.App, logger *logrus.Logger, rateLimiter *ratelim
 	app.Use(middleware.TimeoutMiddleware(30 * time.Second))
 }
 
-func startServer(app *fiber.App, cfg *config.Config, logger *logrus.Logger) {
+func startServer(app *fiber.App, gw *gateway.Gateway, cfg *config.Config, logger *logrus.Logger) {
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Actively probe backends so dead ones are skipped before requests fail
+	gw.StartHealthProbes(ctx)
+
 	// Start server in goroutine
 	go func() {
 		logger.WithFields(logrus.Fields{
@@ -178,9 +190,20 @@ func startServer(app *fiber.App, cfg *config.Config, logger *logrus.Logger) {
 	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer shutdownCancel()
 
+	// Stop proxying new requests and let in-flight ones finish
+	gw.BeginDrain()
+	logger.WithField("active_requests", gw.ActiveProxies()).Info("Draining in-flight requests")
+	remaining := gw.WaitForProxies(shutdownCtx)
+
 	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
 		logger.WithError(err).Error("Server forced to shutdown")
 	} else {
 		logger.Info("Server shutdown completed")
 	}
+
+	if remaining > 0 {
+		logger.WithField("terminated_requests", remaining).Warn("Shutdown timeout reached, in-flight requests were terminated")
+	} else {
+		logger.Info("All in-flight requests drained")
+	}
 }
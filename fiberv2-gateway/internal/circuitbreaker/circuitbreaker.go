@@ -1,19 +1,39 @@
 package circuitbreaker
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/sony/gobreaker"
 	"github.com/sirupsen/logrus"
+
+	"fiberv2-gateway/internal/metrics"
 )
 
+// alertWebhookTimeout bounds a single state-change alert POST
+const alertWebhookTimeout = 5 * time.Second
+
 // CircuitBreakerManager manages circuit breakers for different services
 type CircuitBreakerManager struct {
 	breakers map[string]*gobreaker.CircuitBreaker
 	mutex    sync.RWMutex
 	logger   *logrus.Logger
+
+	// alertWebhookURL receives a JSON POST on every state transition when set
+	alertWebhookURL string
+	httpClient      *http.Client
+}
+
+// StateChangeAlert is the payload posted to the alert webhook on a state transition
+type StateChangeAlert struct {
+	Service   string `json:"service"`
+	FromState string `json:"from_state"`
+	ToState   string `json:"to_state"`
+	Timestamp string `json:"timestamp"`
 }
 
 // CircuitBreakerConfig holds configuration for circuit breaker
@@ -26,11 +46,14 @@ type CircuitBreakerConfig struct {
 	OnStateChange func(name string, from gobreaker.State, to gobreaker.State)
 }
 
-// NewCircuitBreakerManager creates a new circuit breaker manager
-func NewCircuitBreakerManager(logger *logrus.Logger) *CircuitBreakerManager {
+// NewCircuitBreakerManager creates a new circuit breaker manager.
+// An empty alertWebhookURL disables webhook alerts; transitions are still logged and counted.
+func NewCircuitBreakerManager(logger *logrus.Logger, alertWebhookURL string) *CircuitBreakerManager {
 	return &CircuitBreakerManager{
-		breakers: make(map[string]*gobreaker.CircuitBreaker),
-		logger:   logger,
+		breakers:        make(map[string]*gobreaker.CircuitBreaker),
+		logger:          logger,
+		alertWebhookURL: alertWebhookURL,
+		httpClient:      &http.Client{Timeout: alertWebhookTimeout},
 	}
 }
 
@@ -47,24 +70,20 @@ func (cbm *CircuitBreakerManager) CreateCircuitBreaker(config CircuitBreakerConf
 		}
 	}
 
-	// Default OnStateChange function
-	if config.OnStateChange == nil {
-		config.OnStateChange = func(name string, from gobreaker.State, to gobreaker.State) {
-			cbm.logger.WithFields(logrus.Fields{
-				"circuit_breaker": name,
-				"from_state":      from,
-				"to_state":        to,
-			}).Info("Circuit breaker state changed")
-		}
-	}
-
+	// The manager's transition hook always runs, followed by a custom OnStateChange if one is set
+	onStateChange := config.OnStateChange
 	settings := gobreaker.Settings{
 		Name:        config.Name,
 		MaxRequests: config.MaxRequests,
 		Interval:    config.Interval,
 		Timeout:     config.Timeout,
 		ReadyToTrip: config.ReadyToTrip,
-		OnStateChange: config.OnStateChange,
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			cbm.handleStateChange(name, from, to)
+			if onStateChange != nil {
+				onStateChange(name, from, to)
+			}
+		},
 	}
 
 	breaker := gobreaker.NewCircuitBreaker(settings)
@@ -180,3 +199,65 @@ func (cbm *CircuitBreakerManager) Reset(name string) error {
 
 	return nil
 }
+
+// handleStateChange logs a state transition, records it in the metrics and sends the webhook alert
+func (cbm *CircuitBreakerManager) handleStateChange(name string, from gobreaker.State, to gobreaker.State) {
+	entry := cbm.logger.WithFields(logrus.Fields{
+		"event":           "circuit_breaker_state_change",
+		"circuit_breaker": name,
+		"from_state":      from.String(),
+		"to_state":        to.String(),
+	})
+	if to == gobreaker.StateOpen {
+		entry.Warn("Circuit breaker opened")
+	} else {
+		entry.Info("Circuit breaker state changed")
+	}
+
+	metrics.RecordCircuitBreakerStateChange(name, to.String())
+	metrics.UpdateCircuitBreakerState(name, stateMetricValue(to))
+
+	if cbm.alertWebhookURL != "" {
+		// gobreaker calls this hook while holding the breaker's lock, so never block on the webhook
+		go cbm.sendAlert(StateChangeAlert{
+			Service:   name,
+			FromState: from.String(),
+			ToState:   to.String(),
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// sendAlert posts a state-change alert to the configured webhook
+func (cbm *CircuitBreakerManager) sendAlert(alert StateChangeAlert) {
+	logger := cbm.logger.WithField("circuit_breaker", alert.Service)
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		logger.WithError(err).Error("Failed to encode circuit breaker alert")
+		return
+	}
+
+	resp, err := cbm.httpClient.Post(cbm.alertWebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to send circuit breaker alert")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		logger.WithField("status", resp.StatusCode).Warn("Circuit breaker alert webhook rejected the alert")
+	}
+}
+
+// stateMetricValue maps a breaker state to the gateway_circuit_breaker_state gauge value
+func stateMetricValue(state gobreaker.State) int {
+	switch state {
+	case gobreaker.StateOpen:
+		return 1
+	case gobreaker.StateHalfOpen:
+		return 2
+	default:
+		return 0
+	}
+}
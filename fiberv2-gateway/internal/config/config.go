@@ -15,6 +15,9 @@ type Config struct {
 	Environment string
 	LogLevel    string
 	LogFormat   string
+
+	// Path prefix of the versioned backend APIs, forwarded unchanged to the services
+	APIVersionPrefix string
 	
 	// Redis configuration
 	Redis RedisConfig
@@ -36,6 +39,9 @@ type Config struct {
 	
 	// Metrics configuration
 	Metrics MetricsConfig
+	
+	// Admin routes configuration
+	Admin AdminConfig
 }
 
 // ServicesConfig holds configuration for backend services
@@ -53,6 +59,7 @@ type ProductServiceConfig struct {
 	Timeout  int
 	Retries  int
 	Enabled  bool
+	Probe    ProbeConfig
 }
 
 // BasketServiceConfig holds basket service configuration
@@ -62,6 +69,7 @@ type BasketServiceConfig struct {
 	Timeout  int
 	Retries  int
 	Enabled  bool
+	Probe    ProbeConfig
 }
 
 // PaymentServiceConfig holds payment service configuration
@@ -71,6 +79,7 @@ type PaymentServiceConfig struct {
 	Timeout  int
 	Retries  int
 	Enabled  bool
+	Probe    ProbeConfig
 }
 
 // NotificationServiceConfig holds notification service configuration
@@ -80,6 +89,16 @@ type NotificationServiceConfig struct {
 	Timeout  int
 	Retries  int
 	Enabled  bool
+	Probe    ProbeConfig
+}
+
+// ProbeConfig holds active health probing configuration for a service's backends
+type ProbeConfig struct {
+	Path             string
+	Interval         time.Duration
+	Timeout          time.Duration
+	FailureThreshold int // consecutive failed probes before a backend is marked down
+	SuccessThreshold int // consecutive successful probes before a backend is marked up again
 }
 
 // CircuitBreakerConfig holds circuit breaker configuration
@@ -90,6 +109,9 @@ type CircuitBreakerConfig struct {
 	Timeout           int
 	ReadyToTrip       func(counts gobreaker.Counts) bool
 	OnStateChange     func(name string, from gobreaker.State, to gobreaker.State)
+
+	// AlertWebhookURL receives a JSON POST on every breaker state transition; empty disables it
+	AlertWebhookURL string
 }
 
 // LoadBalancerConfig holds load balancer configuration
@@ -104,6 +126,12 @@ type RateLimitConfig struct {
 	Requests   int
 	Window     time.Duration
 	Burst      int
+
+	// Internal service traffic skips rate limiting when it carries InternalAPIKey in
+	// InternalAPIKeyHeader or comes from one of BypassCIDRs; empty values disable each check
+	InternalAPIKeyHeader string
+	InternalAPIKey       string
+	BypassCIDRs          []string
 }
 
 // HealthConfig holds health check configuration
@@ -119,6 +147,17 @@ type MetricsConfig struct {
 	Path    string
 }
 
+// AdminConfig holds configuration for the /admin routes.
+// Requests must carry either the static bearer Token or an HMAC signature made with HMACSecret.
+type AdminConfig struct {
+	Enabled      bool
+	Token        string
+	HMACSecret   string
+	MaxClockSkew time.Duration
+	// Retry-After sent with the 503 of requests rejected while the gateway drains
+	DrainRetryAfter time.Duration
+}
+
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
 	Host         string
@@ -140,6 +179,8 @@ func LoadConfig() *Config {
 		Environment: getEnv("ENVIRONMENT", "development"),
 		LogLevel:    getEnv("LOG_LEVEL", "info"),
 		LogFormat:   getEnv("LOG_FORMAT", "json"),
+
+		APIVersionPrefix: getEnv("API_VERSION_PREFIX", "/v1"),
 		
 		Redis: RedisConfig{
 			Host:         getEnv("REDIS_HOST", "localhost"),
@@ -161,6 +202,7 @@ func LoadConfig() *Config {
 				Timeout:  getEnvAsInt("PRODUCT_SERVICE_TIMEOUT", 30),
 				Retries:  getEnvAsInt("PRODUCT_SERVICE_RETRIES", 3),
 				Enabled:  getEnvAsBool("PRODUCT_SERVICE_ENABLED", true),
+				Probe:    loadProbeConfig("PRODUCT_SERVICE"),
 			},
 			Basket: BasketServiceConfig{
 				Name:     getEnv("BASKET_SERVICE_NAME", "basket-service"),
@@ -168,6 +210,7 @@ func LoadConfig() *Config {
 				Timeout:  getEnvAsInt("BASKET_SERVICE_TIMEOUT", 30),
 				Retries:  getEnvAsInt("BASKET_SERVICE_RETRIES", 3),
 				Enabled:  getEnvAsBool("BASKET_SERVICE_ENABLED", true),
+				Probe:    loadProbeConfig("BASKET_SERVICE"),
 			},
 			Payment: PaymentServiceConfig{
 				Name:     getEnv("PAYMENT_SERVICE_NAME", "payment-service"),
@@ -175,6 +218,7 @@ func LoadConfig() *Config {
 				Timeout:  getEnvAsInt("PAYMENT_SERVICE_TIMEOUT", 30),
 				Retries:  getEnvAsInt("PAYMENT_SERVICE_RETRIES", 3),
 				Enabled:  getEnvAsBool("PAYMENT_SERVICE_ENABLED", true),
+				Probe:    loadProbeConfig("PAYMENT_SERVICE"),
 			},
 			Notification: NotificationServiceConfig{
 				Name:     getEnv("NOTIFICATION_SERVICE_NAME", "notification-service"),
@@ -182,6 +226,7 @@ func LoadConfig() *Config {
 				Timeout:  getEnvAsInt("NOTIFICATION_SERVICE_TIMEOUT", 30),
 				Retries:  getEnvAsInt("NOTIFICATION_SERVICE_RETRIES", 3),
 				Enabled:  getEnvAsBool("NOTIFICATION_SERVICE_ENABLED", true),
+				Probe:    loadProbeConfig("NOTIFICATION_SERVICE"),
 			},
 		},
 		
@@ -190,6 +235,8 @@ func LoadConfig() *Config {
 			MaxRequests: uint32(getEnvAsInt("CIRCUIT_BREAKER_MAX_REQUESTS", 10)),
 			Interval:    getEnvAsInt("CIRCUIT_BREAKER_INTERVAL", 60),
 			Timeout:     getEnvAsInt("CIRCUIT_BREAKER_TIMEOUT", 30),
+
+			AlertWebhookURL: getEnv("CIRCUIT_BREAKER_ALERT_WEBHOOK_URL", ""),
 		},
 		
 		LoadBalancer: LoadBalancerConfig{
@@ -202,6 +249,10 @@ func LoadConfig() *Config {
 			Requests: getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
 			Window:   getEnvAsDuration("RATE_LIMIT_WINDOW", "1m"),
 			Burst:    getEnvAsInt("RATE_LIMIT_BURST", 10),
+
+			InternalAPIKeyHeader: getEnv("RATE_LIMIT_INTERNAL_API_KEY_HEADER", "X-Internal-API-Key"),
+			InternalAPIKey:       getEnv("RATE_LIMIT_INTERNAL_API_KEY", ""),
+			BypassCIDRs:          getEnvSlice("RATE_LIMIT_BYPASS_CIDRS", nil),
 		},
 		
 		Health: HealthConfig{
@@ -214,6 +265,26 @@ func LoadConfig() *Config {
 			Enabled: getEnvAsBool("METRICS_ENABLED", true),
 			Path:    getEnv("METRICS_PATH", "/metrics"),
 		},
+		
+		Admin: AdminConfig{
+			Enabled:      getEnvAsBool("ADMIN_ROUTES_ENABLED", true),
+			Token:        getEnv("ADMIN_TOKEN", ""),
+			HMACSecret:   getEnv("ADMIN_HMAC_SECRET", ""),
+			MaxClockSkew: getEnvAsDuration("ADMIN_HMAC_MAX_SKEW", "5m"),
+
+			DrainRetryAfter: getEnvAsDuration("DRAIN_RETRY_AFTER", "30s"),
+		},
+	}
+}
+
+// loadProbeConfig reads the health probe settings for a service from <prefix>_PROBE_* variables
+func loadProbeConfig(prefix string) ProbeConfig {
+	return ProbeConfig{
+		Path:             getEnv(prefix+"_PROBE_PATH", "/health"),
+		Interval:         getEnvAsDuration(prefix+"_PROBE_INTERVAL", "10s"),
+		Timeout:          getEnvAsDuration(prefix+"_PROBE_TIMEOUT", "2s"),
+		FailureThreshold: getEnvAsInt(prefix+"_PROBE_FAILURE_THRESHOLD", 3),
+		SuccessThreshold: getEnvAsInt(prefix+"_PROBE_SUCCESS_THRESHOLD", 2),
 	}
 }
 
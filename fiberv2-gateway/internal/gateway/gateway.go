@@ -1,8 +1,11 @@
 package gateway
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,7 +14,9 @@ import (
 	"fiberv2-gateway/internal/circuitbreaker"
 	"fiberv2-gateway/internal/config"
 	"fiberv2-gateway/internal/loadbalancer"
+	"fiberv2-gateway/internal/middleware"
 	"fiberv2-gateway/internal/proxy"
+	"fiberv2-gateway/internal/respond"
 )
 
 // Gateway manages the API Gateway functionality
@@ -20,17 +25,25 @@ type Gateway struct {
 	logger           *logrus.Logger
 	circuitBreaker   *circuitbreaker.CircuitBreakerManager
 	loadBalancers    map[string]*loadbalancer.LoadBalancer
+	healthProbers    map[string]*loadbalancer.HealthProber
 	reverseProxy     *proxy.ReverseProxy
 	mutex            sync.RWMutex
+
+	// activeProxies counts requests currently being proxied to a backend
+	activeProxies    atomic.Int64
+	// drainer rejects new requests while the gateway drains, on shutdown or on an admin request
+	drainer          *middleware.Drainer
 }
 
 // NewGateway creates a new API Gateway
-func NewGateway(cfg *config.Config, logger *logrus.Logger) *Gateway {
+func NewGateway(cfg *config.Config, logger *logrus.Logger, drainer *middleware.Drainer) *Gateway {
 	return &Gateway{
 		config:         cfg,
 		logger:         logger,
-		circuitBreaker: circuitbreaker.NewCircuitBreakerManager(logger),
+		drainer:        drainer,
+		circuitBreaker: circuitbreaker.NewCircuitBreakerManager(logger, cfg.CircuitBreaker.AlertWebhookURL),
 		loadBalancers:  make(map[string]*loadbalancer.LoadBalancer),
+		healthProbers:  make(map[string]*loadbalancer.HealthProber),
 		reverseProxy:   proxy.NewReverseProxy(proxy.ProxyConfig{
 			Timeout:   30 * time.Second,
 			Retries:   3,
@@ -43,9 +56,9 @@ func NewGateway(cfg *config.Config, logger *logrus.Logger) *Gateway {
 	}
 }
 
-// SetupRoutes sets up all the gateway routes
-func SetupRoutes(app *fiber.App, cfg *config.Config, logger *logrus.Logger) {
-	gateway := NewGateway(cfg, logger)
+// SetupRoutes sets up all the gateway routes and returns the gateway so it can be drained on shutdown
+func SetupRoutes(app *fiber.App, cfg *config.Config, logger *logrus.Logger, drainer *middleware.Drainer) *Gateway {
+	gateway := NewGateway(cfg, logger, drainer)
 	
 	// Initialize services
 	gateway.initializeServices()
@@ -55,33 +68,75 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, logger *logrus.Logger) {
 	
 	// Setup admin routes
 	gateway.setupAdminRoutes(app)
+
+	return gateway
+}
+
+// StartHealthProbes starts actively probing every service's backends until ctx is cancelled
+func (g *Gateway) StartHealthProbes(ctx context.Context) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	for _, prober := range g.healthProbers {
+		go prober.Start(ctx)
+	}
+}
+
+// BeginDrain stops the gateway from proxying new requests; they are answered with 503
+func (g *Gateway) BeginDrain() {
+	g.drainer.Drain()
+}
+
+// ActiveProxies returns the number of requests currently being proxied to backends
+func (g *Gateway) ActiveProxies() int64 {
+	return g.activeProxies.Load()
+}
+
+// WaitForProxies blocks until no proxied requests are in flight or ctx is done.
+// It returns how many requests were still in flight when it gave up.
+func (g *Gateway) WaitForProxies(ctx context.Context) int64 {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		active := g.ActiveProxies()
+		if active == 0 {
+			return 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return g.ActiveProxies()
+		case <-ticker.C:
+		}
+	}
 }
 
 // initializeServices initializes all backend services
 func (g *Gateway) initializeServices() {
 	// Initialize Product Service
 	if g.config.Services.Product.Enabled {
-		g.initializeService("product", g.config.Services.Product.URLs, g.config.Services.Product.Timeout)
+		g.initializeService("product", g.config.Services.Product.URLs, g.config.Services.Product.Timeout, g.config.Services.Product.Probe)
 	}
 
 	// Initialize Basket Service
 	if g.config.Services.Basket.Enabled {
-		g.initializeService("basket", g.config.Services.Basket.URLs, g.config.Services.Basket.Timeout)
+		g.initializeService("basket", g.config.Services.Basket.URLs, g.config.Services.Basket.Timeout, g.config.Services.Basket.Probe)
 	}
 
 	// Initialize Payment Service
 	if g.config.Services.Payment.Enabled {
-		g.initializeService("payment", g.config.Services.Payment.URLs, g.config.Services.Payment.Timeout)
+		g.initializeService("payment", g.config.Services.Payment.URLs, g.config.Services.Payment.Timeout, g.config.Services.Payment.Probe)
 	}
 
 	// Initialize Notification Service
 	if g.config.Services.Notification.Enabled {
-		g.initializeService("notification", g.config.Services.Notification.URLs, g.config.Services.Notification.Timeout)
+		g.initializeService("notification", g.config.Services.Notification.URLs, g.config.Services.Notification.Timeout, g.config.Services.Notification.Probe)
 	}
 }
 
 // initializeService initializes a single service with load balancer and circuit breaker
-func (g *Gateway) initializeService(serviceName string, urls []string, timeout int) {
+func (g *Gateway) initializeService(serviceName string, urls []string, timeout int, probe config.ProbeConfig) {
 	// Create load balancer for the service
 	lb := loadbalancer.NewLoadBalancer(
 		loadbalancer.Strategy(g.config.LoadBalancer.Strategy),
@@ -105,6 +160,15 @@ func (g *Gateway) initializeService(serviceName string, urls []string, timeout i
 	// Store load balancer
 	g.mutex.Lock()
 	g.loadBalancers[serviceName] = lb
+	if g.config.Health.Enabled {
+		g.healthProbers[serviceName] = loadbalancer.NewHealthProber(serviceName, lb, loadbalancer.ProberConfig{
+			Path:             probe.Path,
+			Interval:         probe.Interval,
+			Timeout:          probe.Timeout,
+			FailureThreshold: probe.FailureThreshold,
+			SuccessThreshold: probe.SuccessThreshold,
+		}, g.logger)
+	}
 	g.mutex.Unlock()
 
 	// Create circuit breaker for the service
@@ -147,6 +211,27 @@ func (g *Gateway) setupServiceRoutes(app *fiber.App) {
 		notificationGroup := app.Group("/api/notifications")
 		g.setupServiceGroup(notificationGroup, "notification")
 	}
+
+	g.setupVersionedRoutes(app)
+}
+
+// setupVersionedRoutes forwards the versioned service APIs with their prefix kept in the path,
+// so clients can move off the deprecated unversioned routes of the services
+func (g *Gateway) setupVersionedRoutes(app *fiber.App) {
+	prefix := g.config.APIVersionPrefix
+	if prefix == "" {
+		return
+	}
+
+	if g.config.Services.Product.Enabled {
+		g.setupServiceGroup(app.Group(prefix+"/products"), "product")
+	}
+	if g.config.Services.Payment.Enabled {
+		g.setupServiceGroup(app.Group(prefix+"/payments"), "payment")
+	}
+	if g.config.Services.Notification.Enabled {
+		g.setupServiceGroup(app.Group(prefix+"/notifications"), "notification")
+	}
 }
 
 // setupServiceGroup sets up routes for a service group
@@ -158,13 +243,17 @@ func (g *Gateway) setupServiceGroup(group *fiber.Router, serviceName string) {
 // createServiceHandler creates a handler for a service
 func (g *Gateway) createServiceHandler(serviceName string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		c.Locals("service", serviceName)
+
+		// Track the request so shutdown can wait for it
+		g.activeProxies.Add(1)
+		defer g.activeProxies.Add(-1)
+
 		// Get load balancer for the service
 		lb, exists := g.loadBalancers[serviceName]
 		if !exists {
 			g.logger.WithField("service", serviceName).Error("Load balancer not found")
-			return c.Status(503).JSON(fiber.Map{
-				"error": "Service not available",
-			})
+			return respond.Error(c, 503, "Service not available")
 		}
 
 		// Get backend from load balancer
@@ -174,9 +263,7 @@ func (g *Gateway) createServiceHandler(serviceName string) fiber.Handler {
 				"service": serviceName,
 				"error":   err.Error(),
 			}).Error("No healthy backends available")
-			return c.Status(503).JSON(fiber.Map{
-				"error": "No healthy backends available",
-			})
+			return respond.Error(c, 503, "No healthy backends available")
 		}
 
 		// Increment connection count
@@ -222,9 +309,7 @@ func (g *Gateway) executeWithCircuitBreaker(c *fiber.Ctx, serviceName string, ba
 		// Increment failed request count
 		g.loadBalancers[serviceName].IncrementFailedRequest(backend)
 
-		return c.Status(503).JSON(fiber.Map{
-			"error": "Service temporarily unavailable",
-		})
+		return respond.Error(c, 503, "Service temporarily unavailable")
 	}
 
 	_ = result // Result is not used in this context
@@ -246,9 +331,7 @@ func (g *Gateway) executeRequest(c *fiber.Ctx, backend *loadbalancer.Backend) er
 			"error":   err.Error(),
 		}).Error("Request execution failed")
 
-		return c.Status(502).JSON(fiber.Map{
-			"error": "Backend service error",
-		})
+		return respond.Error(c, 502, "Backend service error")
 	}
 
 	return nil
@@ -275,28 +358,48 @@ func (g *Gateway) findServiceNameByBackend(backendURL string) string {
 func (g *Gateway) setupAdminRoutes(app *fiber.App) {
 	admin := app.Group("/admin")
 
+	// Health check stays unauthenticated so probes keep working
+	admin.Get("/health", g.getHealthCheck)
+
+	if !g.config.Admin.Enabled {
+		g.logger.Info("Admin routes disabled, only /admin/health is exposed")
+		return
+	}
+
+	// Everything registered below requires admin credentials
+	admin.Use(middleware.AdminAuthMiddleware(g.config.Admin, g.logger))
+
 	// Gateway status
 	admin.Get("/status", g.getGatewayStatus)
 
+	// Connection draining, e.g. ahead of a deploy so readiness probes take the gateway out of rotation
+	admin.Post("/drain", g.drain)
+	admin.Post("/undrain", g.undrain)
+
 	// Service status
 	admin.Get("/services", g.getServicesStatus)
 
 	// Load balancer stats
 	admin.Get("/loadbalancer/:service", g.getLoadBalancerStats)
 
+	// Load balancer backend management
+	admin.Post("/loadbalancer/:service/backends", g.addBackend)
+	admin.Delete("/loadbalancer/:service/backends/:backend", g.removeBackend)
+	admin.Post("/loadbalancer/:service/backends/:backend/disable", g.disableBackend)
+	admin.Post("/loadbalancer/:service/backends/:backend/enable", g.enableBackend)
+
 	// Circuit breaker stats
 	admin.Get("/circuitbreaker/:service", g.getCircuitBreakerStats)
-
-	// Health check
-	admin.Get("/health", g.getHealthCheck)
 }
 
 // getGatewayStatus returns the overall gateway status
 func (g *Gateway) getGatewayStatus(c *fiber.Ctx) error {
 	status := fiber.Map{
-		"status":    "healthy",
-		"timestamp": time.Now(),
-		"services":  make(map[string]interface{}),
+		"status":          "healthy",
+		"timestamp":       time.Now(),
+		"services":        make(map[string]interface{}),
+		"active_requests": g.ActiveProxies(),
+		"draining":        g.drainer.Draining(),
 	}
 
 	g.mutex.RLock()
@@ -320,7 +423,28 @@ func (g *Gateway) getGatewayStatus(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(status)
+	return respond.OK(c, fiber.StatusOK, status)
+}
+
+// drain makes the gateway reject new requests while in-flight ones complete
+func (g *Gateway) drain(c *fiber.Ctx) error {
+	g.drainer.Drain()
+	g.logger.WithField("active_requests", g.ActiveProxies()).Warn("Gateway draining on admin request")
+
+	return respond.OK(c, fiber.StatusOK, fiber.Map{
+		"draining":        true,
+		"active_requests": g.ActiveProxies(),
+	})
+}
+
+// undrain makes the gateway accept new requests again
+func (g *Gateway) undrain(c *fiber.Ctx) error {
+	g.drainer.Undrain()
+	g.logger.Info("Gateway accepting requests again on admin request")
+
+	return respond.OK(c, fiber.StatusOK, fiber.Map{
+		"draining": false,
+	})
 }
 
 // getServicesStatus returns the status of all services
@@ -334,7 +458,7 @@ func (g *Gateway) getServicesStatus(c *fiber.Ctx) error {
 		services[serviceName] = lb.GetStats()
 	}
 
-	return c.JSON(services)
+	return respond.OK(c, fiber.StatusOK, services)
 }
 
 // getLoadBalancerStats returns load balancer statistics for a service
@@ -343,12 +467,92 @@ func (g *Gateway) getLoadBalancerStats(c *fiber.Ctx) error {
 
 	lb, exists := g.loadBalancers[serviceName]
 	if !exists {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "Service not found",
-		})
+		return respond.Error(c, 404, "Service not found")
+	}
+
+	return respond.OK(c, fiber.StatusOK, lb.GetStats())
+}
+
+// addBackend registers a new backend URL for a service at runtime
+func (g *Gateway) addBackend(c *fiber.Ctx) error {
+	lb, ok := g.getLoadBalancer(c.Params("service"))
+	if !ok {
+		return respond.Error(c, 404, "Service not found")
+	}
+
+	var req struct {
+		URL    string `json:"url"`
+		Weight int    `json:"weight"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.URL == "" {
+		return respond.Error(c, 400, "Request body must contain a backend url")
+	}
+	if req.Weight <= 0 {
+		req.Weight = 1
+	}
+
+	if err := lb.AddBackend(req.URL, req.Weight); err != nil {
+		return respond.Error(c, 400, err.Error())
+	}
+
+	return respond.OK(c, fiber.StatusCreated, lb.GetStats())
+}
+
+// removeBackend removes a backend from a service at runtime
+func (g *Gateway) removeBackend(c *fiber.Ctx) error {
+	lb, ok := g.getLoadBalancer(c.Params("service"))
+	if !ok {
+		return respond.Error(c, 404, "Service not found")
+	}
+
+	backendID, err := url.PathUnescape(c.Params("backend"))
+	if err != nil {
+		return respond.Error(c, 400, "Invalid backend identifier")
+	}
+
+	if err := lb.RemoveBackend(backendID); err != nil {
+		return respond.Error(c, 404, err.Error())
+	}
+
+	return respond.OK(c, fiber.StatusOK, lb.GetStats())
+}
+
+// disableBackend stops routing traffic to a backend without removing it
+func (g *Gateway) disableBackend(c *fiber.Ctx) error {
+	return g.setBackendEnabled(c, false)
+}
+
+// enableBackend resumes routing traffic to a previously disabled backend
+func (g *Gateway) enableBackend(c *fiber.Ctx) error {
+	return g.setBackendEnabled(c, true)
+}
+
+// setBackendEnabled toggles whether the load balancer may select a backend
+func (g *Gateway) setBackendEnabled(c *fiber.Ctx, enabled bool) error {
+	lb, ok := g.getLoadBalancer(c.Params("service"))
+	if !ok {
+		return respond.Error(c, 404, "Service not found")
+	}
+
+	backendID, err := url.PathUnescape(c.Params("backend"))
+	if err != nil {
+		return respond.Error(c, 400, "Invalid backend identifier")
+	}
+
+	if err := lb.SetBackendEnabled(backendID, enabled); err != nil {
+		return respond.Error(c, 404, err.Error())
 	}
 
-	return c.JSON(lb.GetStats())
+	return respond.OK(c, fiber.StatusOK, lb.GetStats())
+}
+
+// getLoadBalancer returns the load balancer registered for a service
+func (g *Gateway) getLoadBalancer(serviceName string) (*loadbalancer.LoadBalancer, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	lb, ok := g.loadBalancers[serviceName]
+	return lb, ok
 }
 
 // getCircuitBreakerStats returns circuit breaker statistics for a service
@@ -357,19 +561,15 @@ func (g *Gateway) getCircuitBreakerStats(c *fiber.Ctx) error {
 
 	state, err := g.circuitBreaker.GetState(serviceName)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "Circuit breaker not found",
-		})
+		return respond.Error(c, 404, "Circuit breaker not found")
 	}
 
 	stats, err := g.circuitBreaker.GetStats(serviceName)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{
-			"error": "Circuit breaker not found",
-		})
+		return respond.Error(c, 404, "Circuit breaker not found")
 	}
 
-	return c.JSON(fiber.Map{
+	return respond.OK(c, fiber.StatusOK, fiber.Map{
 		"state": state.String(),
 		"stats": stats,
 	})
@@ -72,8 +72,9 @@ func (hm *HealthManager) CheckHealth(ctx context.Context) map[string]interface{}
 	}
 }
 
-// SetupHealthRoutes sets up health check routes
-func SetupHealthRoutes(app *fiber.App) {
+// SetupHealthRoutes sets up health check routes. The readiness check fails while draining
+// reports true, so orchestrators take the gateway out of rotation.
+func SetupHealthRoutes(app *fiber.App, draining func() bool) {
 	health := app.Group("/health")
 
 	// Basic health check
@@ -112,6 +113,12 @@ func SetupHealthRoutes(app *fiber.App) {
 	// Readiness check
 	health.Get("/ready", func(c *fiber.Ctx) error {
 		// Check if the gateway is ready to serve requests
+		if draining() {
+			return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{
+				"status":    "draining",
+				"timestamp": time.Now(),
+			})
+		}
 		return c.JSON(fiber.Map{
 			"status":    "ready",
 			"timestamp": time.Now(),
@@ -31,7 +31,25 @@ type Backend struct {
 	FailedRequests int64
 	LastHealthCheck time.Time
 	Healthy        bool
+	Disabled       bool // Set by operators to drain the backend regardless of its health
 	mutex          sync.RWMutex
+
+	// Active probe state, maintained by HealthProber
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	lastProbeError       string
+}
+
+// IsAvailable reports whether the backend is healthy and not disabled
+func (b *Backend) IsAvailable() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.Healthy && !b.Disabled
+}
+
+// matches reports whether the backend is identified by the given URL or host
+func (b *Backend) matches(backendID string) bool {
+	return b.URL.String() == backendID || b.URL.Host == backendID
 }
 
 // LoadBalancer manages backend servers and load balancing
@@ -61,6 +79,10 @@ func (lb *LoadBalancer) AddBackend(backendURL string, weight int) error {
 		return fmt.Errorf("invalid backend URL: %w", err)
 	}
 
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return fmt.Errorf("invalid backend URL: %s", backendURL)
+	}
+
 	backend := &Backend{
 		URL:     parsedURL,
 		Weight:  weight,
@@ -68,6 +90,10 @@ func (lb *LoadBalancer) AddBackend(backendURL string, weight int) error {
 	}
 
 	lb.mutex.Lock()
+	if lb.findBackend(parsedURL.String()) != nil {
+		lb.mutex.Unlock()
+		return fmt.Errorf("backend already exists: %s", backendURL)
+	}
 	lb.backends = append(lb.backends, backend)
 	lb.mutex.Unlock()
 
@@ -79,15 +105,18 @@ func (lb *LoadBalancer) AddBackend(backendURL string, weight int) error {
 	return nil
 }
 
-// RemoveBackend removes a backend server from the load balancer
+// RemoveBackend removes a backend server, identified by URL or host, from the load balancer
 func (lb *LoadBalancer) RemoveBackend(backendURL string) error {
 	lb.mutex.Lock()
 	defer lb.mutex.Unlock()
 
 	for i, backend := range lb.backends {
-		if backend.URL.String() == backendURL {
-			lb.backends = append(lb.backends[:i], lb.backends[i+1:]...)
-			lb.logger.WithField("backend", backendURL).Info("Backend removed from load balancer")
+		if backend.matches(backendURL) {
+			// Copy so in-flight GetBackend snapshots never observe a shifted slice
+			backends := make([]*Backend, 0, len(lb.backends)-1)
+			backends = append(backends, lb.backends[:i]...)
+			lb.backends = append(backends, lb.backends[i+1:]...)
+			lb.logger.WithField("backend", backend.URL.String()).Info("Backend removed from load balancer")
 			return nil
 		}
 	}
@@ -95,6 +124,39 @@ func (lb *LoadBalancer) RemoveBackend(backendURL string) error {
 	return fmt.Errorf("backend not found: %s", backendURL)
 }
 
+// SetBackendEnabled enables or disables a backend, identified by URL or host.
+// Disabled backends are skipped by GetBackend until they are enabled again.
+func (lb *LoadBalancer) SetBackendEnabled(backendURL string, enabled bool) error {
+	lb.mutex.RLock()
+	defer lb.mutex.RUnlock()
+
+	backend := lb.findBackend(backendURL)
+	if backend == nil {
+		return fmt.Errorf("backend not found: %s", backendURL)
+	}
+
+	backend.mutex.Lock()
+	backend.Disabled = !enabled
+	backend.mutex.Unlock()
+
+	lb.logger.WithFields(logrus.Fields{
+		"backend": backend.URL.String(),
+		"enabled": enabled,
+	}).Info("Backend availability updated")
+
+	return nil
+}
+
+// findBackend returns the backend identified by URL or host; callers must hold lb.mutex
+func (lb *LoadBalancer) findBackend(backendID string) *Backend {
+	for _, backend := range lb.backends {
+		if backend.matches(backendID) {
+			return backend
+		}
+	}
+	return nil
+}
+
 // GetBackend returns the next backend server based on the strategy
 func (lb *LoadBalancer) GetBackend() (*Backend, error) {
 	lb.mutex.RLock()
@@ -104,10 +166,10 @@ func (lb *LoadBalancer) GetBackend() (*Backend, error) {
 		return nil, fmt.Errorf("no backends available")
 	}
 
-	// Filter healthy backends
+	// Filter healthy backends that have not been disabled
 	healthyBackends := make([]*Backend, 0)
 	for _, backend := range lb.backends {
-		if backend.Healthy {
+		if backend.IsAvailable() {
 			healthyBackends = append(healthyBackends, backend)
 		}
 	}
@@ -255,6 +317,60 @@ func (lb *LoadBalancer) SetBackendHealth(backendURL string, healthy bool) error
 	return fmt.Errorf("backend not found: %s", backendURL)
 }
 
+// RecordProbeResult applies the outcome of an active health probe to a backend.
+// The backend is marked down after failureThreshold consecutive failures and
+// back up after successThreshold consecutive successes.
+func (lb *LoadBalancer) RecordProbeResult(backend *Backend, probeErr error, failureThreshold, successThreshold int) {
+	backend.mutex.Lock()
+	wasHealthy := backend.Healthy
+	backend.LastHealthCheck = time.Now()
+
+	if probeErr != nil {
+		backend.consecutiveSuccesses = 0
+		backend.consecutiveFailures++
+		backend.lastProbeError = probeErr.Error()
+		if backend.Healthy && backend.consecutiveFailures >= failureThreshold {
+			backend.Healthy = false
+		}
+	} else {
+		backend.consecutiveFailures = 0
+		backend.consecutiveSuccesses++
+		backend.lastProbeError = ""
+		if !backend.Healthy && backend.consecutiveSuccesses >= successThreshold {
+			backend.Healthy = true
+		}
+	}
+
+	healthy := backend.Healthy
+	failures := backend.consecutiveFailures
+	backend.mutex.Unlock()
+
+	if healthy == wasHealthy {
+		return
+	}
+
+	fields := logrus.Fields{
+		"backend": backend.URL.String(),
+		"healthy": healthy,
+	}
+	if healthy {
+		lb.logger.WithFields(fields).Info("Backend marked up by health probe")
+	} else {
+		lb.logger.WithFields(fields).WithField("consecutive_failures", failures).
+			WithError(probeErr).Warn("Backend marked down by health probe")
+	}
+}
+
+// Backends returns a snapshot of the registered backends
+func (lb *LoadBalancer) Backends() []*Backend {
+	lb.mutex.RLock()
+	defer lb.mutex.RUnlock()
+
+	backends := make([]*Backend, len(lb.backends))
+	copy(backends, lb.backends)
+	return backends
+}
+
 // GetStats returns statistics for all backends
 func (lb *LoadBalancer) GetStats() []map[string]interface{} {
 	lb.mutex.RLock()
@@ -271,7 +387,13 @@ func (lb *LoadBalancer) GetStats() []map[string]interface{} {
 			"total_requests":    atomic.LoadInt64(&backend.TotalRequests),
 			"failed_requests":   atomic.LoadInt64(&backend.FailedRequests),
 			"healthy":           backend.Healthy,
+			"disabled":          backend.Disabled,
 			"last_health_check": backend.LastHealthCheck,
+			"probe": map[string]interface{}{
+				"consecutive_failures":  backend.consecutiveFailures,
+				"consecutive_successes": backend.consecutiveSuccesses,
+				"last_error":            backend.lastProbeError,
+			},
 		}
 		backend.mutex.RUnlock()
 	}
@@ -279,14 +401,14 @@ func (lb *LoadBalancer) GetStats() []map[string]interface{} {
 	return stats
 }
 
-// GetHealthyBackends returns the count of healthy backends
+// GetHealthyBackends returns the count of healthy backends that are not disabled
 func (lb *LoadBalancer) GetHealthyBackends() int {
 	lb.mutex.RLock()
 	defer lb.mutex.RUnlock()
 
 	healthy := 0
 	for _, backend := range lb.backends {
-		if backend.Healthy {
+		if backend.IsAvailable() {
 			healthy++
 		}
 	}
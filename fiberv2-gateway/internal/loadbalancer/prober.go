@@ -0,0 +1,124 @@
+package loadbalancer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProberConfig holds the active health probe settings for one service
+type ProberConfig struct {
+	Path             string
+	Interval         time.Duration
+	Timeout          time.Duration
+	FailureThreshold int
+	SuccessThreshold int
+}
+
+// HealthProber periodically probes every backend of a load balancer and
+// marks backends down or up so GetBackend can skip dead ones proactively
+type HealthProber struct {
+	service string
+	lb      *LoadBalancer
+	config  ProberConfig
+	client  *http.Client
+	logger  *logrus.Logger
+}
+
+// NewHealthProber creates a new health prober for a service's load balancer
+func NewHealthProber(service string, lb *LoadBalancer, config ProberConfig, logger *logrus.Logger) *HealthProber {
+	if config.Path == "" {
+		config.Path = "/health"
+	}
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Second
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 1
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 1
+	}
+
+	return &HealthProber{
+		service: service,
+		lb:      lb,
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		logger:  logger,
+	}
+}
+
+// Config returns the effective probe configuration
+func (p *HealthProber) Config() ProberConfig {
+	return p.config
+}
+
+// Start probes all backends every interval until ctx is cancelled
+func (p *HealthProber) Start(ctx context.Context) {
+	p.logger.WithFields(logrus.Fields{
+		"service":  p.service,
+		"path":     p.config.Path,
+		"interval": p.config.Interval.String(),
+	}).Info("Backend health prober started")
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	p.probeAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.WithField("service", p.service).Info("Backend health prober stopped")
+			return
+		case <-ticker.C:
+			p.probeAll(ctx)
+		}
+	}
+}
+
+// probeAll probes every backend concurrently and waits for the round to finish
+func (p *HealthProber) probeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, backend := range p.lb.Backends() {
+		wg.Add(1)
+		go func(backend *Backend) {
+			defer wg.Done()
+			err := p.probe(ctx, backend)
+			if ctx.Err() != nil {
+				return
+			}
+			p.lb.RecordProbeResult(backend, err, p.config.FailureThreshold, p.config.SuccessThreshold)
+		}(backend)
+	}
+	wg.Wait()
+}
+
+// probe issues a GET against the backend's probe path; any non-2xx response is a failure
+func (p *HealthProber) probe(ctx context.Context, backend *Backend) error {
+	probeURL := *backend.URL
+	probeURL.Path = p.config.Path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
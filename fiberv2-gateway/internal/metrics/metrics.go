@@ -13,6 +13,8 @@ type Metrics struct {
 	ActiveRequests  prometheus.Gauge
 	BackendHealth   *prometheus.GaugeVec
 	CircuitBreaker  *prometheus.GaugeVec
+	StateChanges    *prometheus.CounterVec
+	RateLimitBypass *prometheus.CounterVec
 }
 
 // GatewayMetrics holds the global metrics instance
@@ -57,6 +59,20 @@ func SetupMetrics(app *fiber.App) {
 			},
 			[]string{"service"},
 		),
+		StateChanges: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "circuit_breaker_state_changes_total",
+				Help: "Total number of circuit breaker state transitions by new state",
+			},
+			[]string{"service", "state"},
+		),
+		RateLimitBypass: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rate_limit_bypass_total",
+				Help: "Total number of requests that skipped rate limiting as internal traffic, by reason",
+			},
+			[]string{"reason"},
+		),
 	}
 
 	// Custom metrics middleware
@@ -95,3 +111,13 @@ func UpdateBackendHealth(service, backend string, healthy bool) {
 func UpdateCircuitBreakerState(service string, state int) {
 	GatewayMetrics.CircuitBreaker.WithLabelValues(service).Set(float64(state))
 }
+
+// RecordCircuitBreakerStateChange records a circuit breaker transition into state
+func RecordCircuitBreakerStateChange(service, state string) {
+	GatewayMetrics.StateChanges.WithLabelValues(service, state).Inc()
+}
+
+// RecordRateLimitBypass records a request that skipped rate limiting, by reason (api_key or cidr)
+func RecordRateLimitBypass(reason string) {
+	GatewayMetrics.RateLimitBypass.WithLabelValues(reason).Inc()
+}
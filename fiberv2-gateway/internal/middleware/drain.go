@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"fiberv2-gateway/internal/respond"
+)
+
+// Drainer tracks whether the gateway is draining. While it drains, new requests are rejected
+// with 503 so load balancers and orchestrators move traffic elsewhere, and requests already
+// in flight are left to complete.
+type Drainer struct {
+	draining   atomic.Bool
+	retryAfter time.Duration
+}
+
+// NewDrainer creates a drainer whose rejections ask clients to retry after retryAfter
+func NewDrainer(retryAfter time.Duration) *Drainer {
+	return &Drainer{retryAfter: retryAfter}
+}
+
+// Drain starts rejecting new requests
+func (d *Drainer) Drain() {
+	d.draining.Store(true)
+}
+
+// Undrain accepts new requests again
+func (d *Drainer) Undrain() {
+	d.draining.Store(false)
+}
+
+// Draining reports whether new requests are being rejected
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Middleware rejects new requests with 503 and a Retry-After header while draining.
+// Requests under the exempt path prefixes, such as admin and health routes, are always let
+// through so the drain can be inspected and undone. It should be registered before any other
+// middleware that does work for the request.
+func (d *Drainer) Middleware(exemptPrefixes ...string) fiber.Handler {
+	retryAfter := strconv.Itoa(int(d.retryAfter.Round(time.Second).Seconds()))
+
+	return func(c *fiber.Ctx) error {
+		if !d.Draining() {
+			return c.Next()
+		}
+
+		path := c.Path()
+		for _, prefix := range exemptPrefixes {
+			if prefix != "" && strings.HasPrefix(path, prefix) {
+				return c.Next()
+			}
+		}
+
+		c.Set(fiber.HeaderRetryAfter, retryAfter)
+		c.Set(fiber.HeaderConnection, "close")
+		return respond.Error(c, fiber.StatusServiceUnavailable, "Gateway is draining")
+	}
+}
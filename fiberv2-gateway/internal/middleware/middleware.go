@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 	"go.uber.org/ratelimit"
+
+	"fiberv2-gateway/internal/metrics"
 )
 
 // RateLimiter holds rate limiting configuration
@@ -34,7 +37,9 @@ func (rl *RateLimiter) RateLimitMiddleware() fiber.Handler {
 	}
 }
 
-// RequestLoggerMiddleware creates a request logging middleware
+// RequestLoggerMiddleware logs every request as one structured entry and records its
+// latency in the gateway request duration histogram. The backend service handling the
+// request is read from the "service" local set by the proxy handlers.
 func RequestLoggerMiddleware(logger *logrus.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
@@ -42,16 +47,44 @@ func RequestLoggerMiddleware(logger *logrus.Logger) fiber.Handler {
 		// Continue to next middleware
 		err := c.Next()
 
-		// Log the request
 		duration := time.Since(start)
-		logger.WithFields(logrus.Fields{
-			"method":   c.Method(),
-			"path":     c.Path(),
-			"status":   c.Response().StatusCode(),
-			"duration": duration,
-			"ip":       c.IP(),
-			"user_agent": c.Get("User-Agent"),
-		}).Info("Request processed")
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		}
+
+		service, _ := c.Locals("service").(string)
+		if service == "" {
+			service = "gateway"
+		}
+		route := c.Route().Path
+		statusLabel := strconv.Itoa(status)
+		metrics.RecordRequestDuration(c.Method(), route, statusLabel, service, duration.Seconds())
+		metrics.RecordRequestTotal(c.Method(), route, statusLabel, service)
+
+		requestID, _ := c.Locals("requestID").(string)
+		entry := logger.WithFields(logrus.Fields{
+			"method":     c.Method(),
+			"path":       c.Path(),
+			"route":      route,
+			"service":    service,
+			"status":     status,
+			"latency_ms": float64(duration.Microseconds()) / 1000,
+			"request_id": requestID,
+			"user_id":    c.Get("X-User-ID"),
+			"bytes_in":   len(c.Body()),
+			"bytes_out":  len(c.Response().Body()),
+			"client_ip":  c.IP(),
+		})
+
+		switch {
+		case status >= 500:
+			entry.Error("HTTP request completed with error")
+		case status >= 400:
+			entry.Warn("HTTP request completed with client error")
+		default:
+			entry.Info("HTTP request completed")
+		}
 
 		return err
 	}
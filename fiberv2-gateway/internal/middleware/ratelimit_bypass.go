@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"fiberv2-gateway/internal/config"
+	"fiberv2-gateway/internal/metrics"
+)
+
+// rateLimitBypassLocal marks a request as internal traffic that skips rate limiting
+const rateLimitBypassLocal = "rateLimitBypass"
+
+// RateLimitBypassMiddleware marks internal service traffic so the rate limit middlewares
+// let it through. A request is internal when it carries the configured internal API key
+// or comes from one of the configured CIDRs. The key header is stripped before proxying.
+// It must be registered before any rate limit middleware.
+func RateLimitBypassMiddleware(cfg config.RateLimitConfig, logger *logrus.Logger) (fiber.Handler, error) {
+	networks := make([]*net.IPNet, 0, len(cfg.BypassCIDRs))
+	for _, cidr := range cfg.BypassCIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit bypass CIDR %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return func(c *fiber.Ctx) error {
+		reason := ""
+		if cfg.InternalAPIKey != "" && cfg.InternalAPIKeyHeader != "" {
+			key := c.Get(cfg.InternalAPIKeyHeader)
+			if key != "" {
+				c.Request().Header.Del(cfg.InternalAPIKeyHeader)
+				if subtle.ConstantTimeCompare([]byte(key), []byte(cfg.InternalAPIKey)) == 1 {
+					reason = "api_key"
+				}
+			}
+		}
+		if reason == "" && len(networks) > 0 {
+			if ip := net.ParseIP(c.IP()); ip != nil {
+				for _, network := range networks {
+					if network.Contains(ip) {
+						reason = "cidr"
+						break
+					}
+				}
+			}
+		}
+
+		if reason != "" {
+			c.Locals(rateLimitBypassLocal, true)
+			metrics.RecordRateLimitBypass(reason)
+			logger.WithFields(logrus.Fields{
+				"method": c.Method(),
+				"path":   c.Path(),
+				"ip":     c.IP(),
+				"reason": reason,
+			}).Debug("Rate limit bypassed for internal traffic")
+		}
+
+		return c.Next()
+	}, nil
+}
+
+// isRateLimitBypassed reports whether RateLimitBypassMiddleware marked the request as internal traffic
+func isRateLimitBypassed(c *fiber.Ctx) bool {
+	bypassed, _ := c.Locals(rateLimitBypassLocal).(bool)
+	return bypassed
+}
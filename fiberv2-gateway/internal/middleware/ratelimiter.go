@@ -9,11 +9,16 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"fiberv2-gateway/internal/ratelimiter"
+	"fiberv2-gateway/internal/respond"
 )
 
 // RateLimitMiddleware creates a rate limiting middleware using Redis sliding window
 func RateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter, config ratelimiter.RateLimitConfig, logger *logrus.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if isRateLimitBypassed(c) {
+			return c.Next()
+		}
+
 		// Get client identifier (IP address or user ID)
 		identifier := getClientIdentifier(c)
 		
@@ -37,8 +42,7 @@ func RateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter, conf
 				"reset_time":   result.ResetTime,
 			}).Warn("Rate limit exceeded")
 			
-			c.Status(429).JSON(fiber.Map{
-				"error":       "Rate limit exceeded",
+			respond.ErrorWithDetails(c, 429, respond.CodeRateLimited, "Rate limit exceeded", fiber.Map{
 				"retry_after": result.RetryAfter.Seconds(),
 				"reset_time":  result.ResetTime,
 			})
@@ -58,6 +62,10 @@ func RateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter, conf
 // AdaptiveRateLimitMiddleware creates an adaptive rate limiting middleware
 func AdaptiveRateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter, configs map[string]ratelimiter.RateLimitConfig, logger *logrus.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if isRateLimitBypassed(c) {
+			return c.Next()
+		}
+
 		// Get client identifier
 		identifier := getClientIdentifier(c)
 		
@@ -85,8 +93,7 @@ func AdaptiveRateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimit
 				"reset_time":   result.ResetTime,
 			}).Warn("Adaptive rate limit exceeded")
 			
-			c.Status(429).JSON(fiber.Map{
-				"error":       "Rate limit exceeded",
+			respond.ErrorWithDetails(c, 429, respond.CodeRateLimited, "Rate limit exceeded", fiber.Map{
 				"retry_after": result.RetryAfter.Seconds(),
 				"reset_time":  result.ResetTime,
 			})
@@ -100,6 +107,10 @@ func AdaptiveRateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimit
 // PerServiceRateLimitMiddleware creates rate limiting middleware per service
 func PerServiceRateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter, serviceConfigs map[string]ratelimiter.RateLimitConfig, logger *logrus.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if isRateLimitBypassed(c) {
+			return c.Next()
+		}
+
 		// Get client identifier
 		identifier := getClientIdentifier(c)
 		
@@ -140,8 +151,7 @@ func PerServiceRateLimitMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLim
 				"reset_time":   result.ResetTime,
 			}).Warn("Per-service rate limit exceeded")
 			
-			c.Status(429).JSON(fiber.Map{
-				"error":       "Rate limit exceeded for service: " + service,
+			respond.ErrorWithDetails(c, 429, respond.CodeRateLimited, "Rate limit exceeded for service: "+service, fiber.Map{
 				"retry_after": result.RetryAfter.Seconds(),
 				"reset_time":  result.ResetTime,
 			})
@@ -248,9 +258,7 @@ func RateLimitStatusMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter
 		result, err := rateLimiter.GetRateLimitStatus(c.Context(), config, identifier)
 		if err != nil {
 			logger.WithError(err).Error("Failed to get rate limit status")
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to get rate limit status",
-			})
+			return respond.Error(c, 500, "Failed to get rate limit status")
 		}
 		
 		// Get detailed stats
@@ -259,7 +267,7 @@ func RateLimitStatusMiddleware(rateLimiter *ratelimiter.SlidingWindowRateLimiter
 			logger.WithError(err).Error("Failed to get rate limit stats")
 		}
 		
-		return c.JSON(fiber.Map{
+		return respond.OK(c, fiber.StatusOK, fiber.Map{
 			"identifier":    identifier,
 			"allowed":       result.Allowed,
 			"remaining":     result.Remaining,
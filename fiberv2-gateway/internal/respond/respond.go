@@ -0,0 +1,79 @@
+package respond
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Code is a stable, machine-readable error code clients can branch on.
+// The values match the codes of the backend services so clients handle both alike.
+type Code string
+
+// Error codes returned by the gateway itself
+const (
+	CodeValidation         Code = "VALIDATION_ERROR"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeRateLimited        Code = "RATE_LIMITED"
+	CodeInternal           Code = "INTERNAL_ERROR"
+	CodeBadGateway         Code = "BAD_GATEWAY"
+	CodeServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+)
+
+// Envelope is the body of every gateway response: data on success, error otherwise
+type Envelope struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   *ErrorBody  `json:"error,omitempty"`
+}
+
+// ErrorBody describes why a request failed
+type ErrorBody struct {
+	Code    Code        `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// CodeForStatus returns the error code matching an HTTP status
+func CodeForStatus(status int) Code {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeValidation
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusBadGateway:
+		return CodeBadGateway
+	case fiber.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
+// OK writes data in a successful envelope
+func OK(c *fiber.Ctx, status int, data interface{}) error {
+	return c.Status(status).JSON(Envelope{Success: true, Data: data})
+}
+
+// Error writes a failed envelope whose code is derived from the status
+func Error(c *fiber.Ctx, status int, message string) error {
+	return ErrorWithDetails(c, status, CodeForStatus(status), message, nil)
+}
+
+// ErrorWithDetails writes a failed envelope with an explicit code and optional details
+func ErrorWithDetails(c *fiber.Ctx, status int, code Code, message string, details interface{}) error {
+	return c.Status(status).JSON(Envelope{
+		Success: false,
+		Error: &ErrorBody{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	})
+}
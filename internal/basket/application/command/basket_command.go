@@ -19,6 +19,8 @@ type AddItemCommand struct {
 	UserID    string `json:"user_id" binding:"required"`
 	ProductID int    `json:"product_id" binding:"required"`
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
+	// OperationID makes retries safe: a repeated ID returns the first result instead of applying again
+	OperationID string `json:"operation_id,omitempty" binding:"omitempty,max=128"`
 }
 
 // ToDTO converts command to DTO
@@ -34,6 +36,8 @@ type UpdateItemCommand struct {
 	UserID    string `json:"user_id" binding:"required"`
 	ProductID int    `json:"product_id" binding:"required"`
 	Quantity  int    `json:"quantity" binding:"required,min=0"`
+	// OperationID makes retries safe: a repeated ID returns the first result instead of applying again
+	OperationID string `json:"operation_id,omitempty" binding:"omitempty,max=128"`
 }
 
 // ToDTO converts command to DTO
@@ -50,6 +54,11 @@ type RemoveItemCommand struct {
 	ProductID int    `json:"product_id" binding:"required"`
 }
 
+// RefreshBasketCommand represents a command to refresh basket items from the product service
+type RefreshBasketCommand struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
 // ClearBasketCommand represents a command to clear the basket
 type ClearBasketCommand struct {
 	UserID string `json:"user_id" binding:"required"`
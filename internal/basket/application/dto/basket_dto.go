@@ -21,12 +21,21 @@ type UpdateItemRequest struct {
 
 // BasketItemResponse represents a basket item in response
 type BasketItemResponse struct {
-	ProductID int     `json:"product_id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Subtotal  float64 `json:"subtotal"`
-	Category  string  `json:"category"`
+	ProductID      int     `json:"product_id"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	Quantity       int     `json:"quantity"`
+	Subtotal       float64 `json:"subtotal"`
+	Category       string  `json:"category"`
+	NeedsRefresh   bool    `json:"needs_refresh,omitempty"`
+	TaxRate        float64 `json:"tax_rate"`
+	TaxAmount      float64 `json:"tax_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+
+	// Set only when the basket is fetched with ?enrich=true and the product could be looked up
+	CurrentPrice *float64 `json:"current_price,omitempty"`
+	InStock      *bool    `json:"in_stock,omitempty"`
+	PriceChanged *bool    `json:"price_changed,omitempty"`
 }
 
 // BasketResponse represents the response payload for basket operations
@@ -41,18 +50,35 @@ type BasketResponse struct {
 	ExpiresAt time.Time           `json:"expires_at"`
 }
 
+// BatchBasketsResponse maps each requested user ID to its basket.
+// Users without a basket map to null instead of failing the whole batch.
+type BatchBasketsResponse struct {
+	Baskets map[string]*BasketResponse `json:"baskets"`
+	Found   int                        `json:"found"`
+	Missing int                        `json:"missing"`
+}
+
+// RefreshFailure describes a basket item whose product details could not be refreshed
+type RefreshFailure struct {
+	ProductID int    `json:"product_id"`
+	Reason    string `json:"reason"`
+}
+
+// BasketRefreshResponse represents the outcome of refreshing a basket against the product service.
+// Items that could not be refreshed keep their cached details and are flagged needs_refresh.
+type BasketRefreshResponse struct {
+	Basket    *BasketResponse  `json:"basket"`
+	Refreshed []int            `json:"refreshed"`
+	Failed    []RefreshFailure `json:"failed,omitempty"`
+	Partial   bool             `json:"partial"`
+}
+
 // SuccessResponse represents a success response
 type SuccessResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
 // BasketTotalResponse represents basket total response
 type BasketTotalResponse struct {
 	UserID    string  `json:"user_id"`
@@ -102,10 +128,3 @@ type BasketRecommendationsResponse struct {
 	Reason         string              `json:"reason"`
 }
 
-// HealthResponse represents a health check response
-type HealthResponse struct {
-	Service   string `json:"service"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Version   string `json:"version"`
-}
@@ -25,12 +25,12 @@ func (h *CommandHandler) HandleCreateBasket(cmd command.CreateBasketCommand) (*d
 
 // HandleAddItem handles AddItemCommand
 func (h *CommandHandler) HandleAddItem(cmd command.AddItemCommand) (*dto.BasketResponse, error) {
-	return h.basketUseCase.AddItem(cmd.UserID, cmd.ProductID, cmd.Quantity)
+	return h.basketUseCase.AddItem(cmd.UserID, cmd.ProductID, cmd.Quantity, cmd.OperationID)
 }
 
 // HandleUpdateItem handles UpdateItemCommand
 func (h *CommandHandler) HandleUpdateItem(cmd command.UpdateItemCommand) (*dto.BasketResponse, error) {
-	return h.basketUseCase.UpdateItem(cmd.UserID, cmd.ProductID, cmd.Quantity)
+	return h.basketUseCase.UpdateItem(cmd.UserID, cmd.ProductID, cmd.Quantity, cmd.OperationID)
 }
 
 // HandleRemoveItem handles RemoveItemCommand
@@ -38,6 +38,11 @@ func (h *CommandHandler) HandleRemoveItem(cmd command.RemoveItemCommand) (*dto.B
 	return h.basketUseCase.RemoveItem(cmd.UserID, cmd.ProductID)
 }
 
+// HandleRefreshBasket handles RefreshBasketCommand
+func (h *CommandHandler) HandleRefreshBasket(cmd command.RefreshBasketCommand) (*dto.BasketRefreshResponse, error) {
+	return h.basketUseCase.RefreshBasket(cmd.UserID)
+}
+
 // HandleClearBasket handles ClearBasketCommand
 func (h *CommandHandler) HandleClearBasket(cmd command.ClearBasketCommand) (*dto.BasketResponse, error) {
 	return h.basketUseCase.ClearBasket(cmd.UserID)
@@ -20,9 +20,17 @@ func NewQueryHandler(basketUseCase *usecase.BasketUseCase) *QueryHandler {
 
 // HandleGetBasket handles GetBasketQuery
 func (h *QueryHandler) HandleGetBasket(q query.GetBasketQuery) (*dto.BasketResponse, error) {
+	if q.Enrich {
+		return h.basketUseCase.GetEnrichedBasket(q.UserID)
+	}
 	return h.basketUseCase.GetBasket(q.UserID)
 }
 
+// HandleGetBaskets handles GetBasketsQuery
+func (h *QueryHandler) HandleGetBaskets(q query.GetBasketsQuery) (map[string]*dto.BasketResponse, error) {
+	return h.basketUseCase.GetBaskets(q.UserIDs)
+}
+
 // HandleGetBasketItems handles GetBasketItemsQuery
 func (h *QueryHandler) HandleGetBasketItems(q query.GetBasketItemsQuery) ([]dto.BasketItemResponse, error) {
 	return h.basketUseCase.GetBasketItems(q.UserID)
@@ -3,6 +3,12 @@ package query
 // GetBasketQuery represents a query to get a basket
 type GetBasketQuery struct {
 	UserID string `json:"user_id" binding:"required"`
+	Enrich bool   `json:"enrich"` // Add current price and stock flags to each item
+}
+
+// GetBasketsQuery represents a query to get the baskets of several users at once
+type GetBasketsQuery struct {
+	UserIDs []string `json:"user_ids" binding:"required,min=1,dive,required"`
 }
 
 // GetBasketItemsQuery represents a query to get basket items
@@ -3,30 +3,58 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
 	"obs-tools-usage/internal/basket/application/dto"
 	"obs-tools-usage/internal/basket/domain/entity"
 	"obs-tools-usage/internal/basket/domain/repository"
 	"obs-tools-usage/internal/basket/domain/service"
+	"obs-tools-usage/internal/basket/infrastructure/config"
 	"obs-tools-usage/internal/basket/infrastructure/metrics"
+	"obs-tools-usage/kafka/events"
+	"obs-tools-usage/kafka/publisher"
 )
 
 // BasketUseCase handles basket business logic
 type BasketUseCase struct {
-	basketRepo    repository.BasketRepository
-	productClient service.ProductClient
-	logger        *logrus.Logger
+	basketRepo     repository.BasketRepository
+	productClient  service.ProductClient
+	kafkaPublisher publisher.BasketEventPublisher
+	logger         *logrus.Logger
+	allowStaleAdd  bool
+	operationTTL   time.Duration
+	batchMaxUsers  int
+	maxItemQty     int
+	currency       string
+	pricing        service.PricingPolicy
 }
 
-// NewBasketUseCase creates a new basket use case
-func NewBasketUseCase(basketRepo repository.BasketRepository, productClient service.ProductClient, logger *logrus.Logger) *BasketUseCase {
+// NewBasketUseCase creates a new basket use case. Item added and basket cleared events are
+// published through kafkaPublisher; a nil publisher discards them.
+func NewBasketUseCase(basketRepo repository.BasketRepository, productClient service.ProductClient, logger *logrus.Logger, basketConfig config.BasketConfig, kafkaPublisher publisher.BasketEventPublisher) *BasketUseCase {
+	if kafkaPublisher == nil {
+		kafkaPublisher = publisher.NewNoopPublisher(logger)
+	}
 	return &BasketUseCase{
-		basketRepo:    basketRepo,
-		productClient: productClient,
-		logger:        logger,
+		basketRepo:     basketRepo,
+		productClient:  productClient,
+		kafkaPublisher: kafkaPublisher,
+		logger:         logger,
+		allowStaleAdd:  basketConfig.AllowStaleAdd,
+		operationTTL:   basketConfig.OperationTTL,
+		batchMaxUsers:  basketConfig.BatchMaxUsers,
+		maxItemQty:     basketConfig.MaxItemQuantity,
+		currency:       strings.ToUpper(basketConfig.Currency),
+		pricing: service.PricingPolicy{
+			DefaultTaxRate:    basketConfig.DefaultTaxRate,
+			CategoryTaxRates:  basketConfig.CategoryTaxRates,
+			CategoryDiscounts: basketConfig.CategoryDiscounts,
+		},
 	}
 }
 
@@ -45,6 +73,91 @@ func (uc *BasketUseCase) GetBasket(userID string) (*dto.BasketResponse, error) {
 	return response, nil
 }
 
+// GetBaskets retrieves the baskets of several users with one Redis round trip.
+// Users without a basket map to nil; duplicate user IDs are looked up once.
+func (uc *BasketUseCase) GetBaskets(userIDs []string) (map[string]*dto.BasketResponse, error) {
+	start := time.Now()
+
+	unique := make([]string, 0, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			unique = append(unique, userID)
+		}
+	}
+
+	if uc.batchMaxUsers > 0 && len(unique) > uc.batchMaxUsers {
+		return nil, fmt.Errorf("invalid batch: at most %d user IDs are allowed, got %d", uc.batchMaxUsers, len(unique))
+	}
+
+	baskets, err := uc.basketRepo.GetBaskets(unique)
+	if err != nil {
+		metrics.RecordRedisOperation("GetBaskets", "error", time.Since(start))
+		return nil, fmt.Errorf("failed to get baskets: %w", err)
+	}
+	metrics.RecordRedisOperation("GetBaskets", "success", time.Since(start))
+
+	responses := make(map[string]*dto.BasketResponse, len(baskets))
+	for userID, basket := range baskets {
+		if basket == nil {
+			responses[userID] = nil
+			continue
+		}
+		responses[userID] = uc.basketToResponse(basket)
+	}
+	return responses, nil
+}
+
+// GetEnrichedBasket retrieves a basket and flags, per item, the current product price and
+// whether it is still in stock. Items whose product cannot be looked up are left unflagged,
+// and if the product service is unreachable the basket is returned as stored.
+func (uc *BasketUseCase) GetEnrichedBasket(userID string) (*dto.BasketResponse, error) {
+	response, err := uc.GetBasket(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return response, nil
+	}
+
+	productIDs := make([]int, 0, len(response.Items))
+	for _, item := range response.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	products, err := uc.productClient.GetProducts(ctx, productIDs)
+	if err != nil {
+		uc.logger.WithError(err).WithField("user_id", userID).Warn("Failed to enrich basket, returning stored items")
+		return response, nil
+	}
+
+	byID := make(map[int]*service.ProductInfo, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	for i := range response.Items {
+		item := &response.Items[i]
+		product, ok := byID[item.ProductID]
+		if !ok {
+			continue
+		}
+
+		currentPrice := product.Price
+		inStock := product.Available && product.Stock >= item.Quantity
+		priceChanged := math.Abs(currentPrice-item.Price) >= 0.005
+		item.CurrentPrice = &currentPrice
+		item.InStock = &inStock
+		item.PriceChanged = &priceChanged
+	}
+
+	return response, nil
+}
+
 // CreateBasket creates a new basket for a user
 func (uc *BasketUseCase) CreateBasket(userID string) (*dto.BasketResponse, error) {
 	start := time.Now()
@@ -80,8 +193,21 @@ func (uc *BasketUseCase) CreateBasket(userID string) (*dto.BasketResponse, error
 	return response, nil
 }
 
-// AddItem adds an item to the basket
-func (uc *BasketUseCase) AddItem(userID string, productID int, quantity int) (*dto.BasketResponse, error) {
+// AddItem adds an item to the basket. A non-empty operationID makes the call safe to retry.
+func (uc *BasketUseCase) AddItem(userID string, productID int, quantity int, operationID string) (*dto.BasketResponse, error) {
+	return uc.applyOnce(userID, operationID, func() (*entity.Basket, error) {
+		basket, err := uc.addItem(userID, productID, quantity)
+		if err != nil {
+			return nil, err
+		}
+		// Only published when the item is actually added, not for a repeated operation ID
+		uc.publishItemAdded(basket, productID, quantity, operationID)
+		return basket, nil
+	})
+}
+
+// addItem adds an item to the basket and returns the saved basket
+func (uc *BasketUseCase) addItem(userID string, productID int, quantity int) (*entity.Basket, error) {
 	start := time.Now()
 	defer metrics.RecordBasketOperation("add_item")
 
@@ -90,13 +216,18 @@ func (uc *BasketUseCase) AddItem(userID string, productID int, quantity int) (*d
 	productInfo, err := uc.productClient.GetProduct(ctx, productID)
 	if err != nil {
 		metrics.RecordProductServiceRequest("GetProduct", "error", time.Since(start))
+		if uc.allowStaleAdd {
+			if basket, staleErr := uc.addStaleItem(userID, productID, quantity, err); staleErr == nil {
+				return basket, nil
+			}
+		}
 		return nil, fmt.Errorf("failed to get product information: %w", err)
 	}
 	metrics.RecordProductServiceRequest("GetProduct", "success", time.Since(start))
 
 	// Check if product is available
-	if !productInfo.Available || productInfo.Stock < quantity {
-		return nil, fmt.Errorf("product is not available or insufficient stock")
+	if !productInfo.Available {
+		return nil, fmt.Errorf("product %d is not available", productID)
 	}
 
 	// Get or create basket
@@ -105,8 +236,22 @@ func (uc *BasketUseCase) AddItem(userID string, productID int, quantity int) (*d
 		return nil, fmt.Errorf("failed to get or create basket: %w", err)
 	}
 
-	// Add item to basket
-	basket.AddItem(productID, productInfo.Name, productInfo.Price, quantity, productInfo.Category)
+	// The quantity already in the basket counts against the stock and the per-product cap
+	existing := basket.ItemQuantity(productID)
+	if uc.maxItemQty > 0 && existing+quantity > uc.maxItemQty {
+		return nil, fmt.Errorf("invalid quantity: at most %d of product %d may be in the basket, %d already are", uc.maxItemQty, productID, existing)
+	}
+	if existing+quantity > productInfo.Stock {
+		available := productInfo.Stock - existing
+		if available < 0 {
+			available = 0
+		}
+		return nil, fmt.Errorf("insufficient stock for product %d: %d more can be added, %d already in the basket", productID, available, existing)
+	}
+
+	// Add item to basket, priced by the server's pricing policy
+	basket.AddItem(productID, productInfo.Name, productInfo.Price, quantity, productInfo.Category, 0, 0)
+	uc.priceItem(basket, productID)
 
 	// Save basket
 	err = uc.basketRepo.UpdateBasket(basket)
@@ -116,8 +261,6 @@ func (uc *BasketUseCase) AddItem(userID string, productID int, quantity int) (*d
 	}
 	metrics.RecordRedisOperation("UpdateBasket", "success", time.Since(start))
 
-	response := uc.basketToResponse(basket)
-	
 	uc.logger.WithFields(logrus.Fields{
 		"user_id":    userID,
 		"product_id": productID,
@@ -125,11 +268,50 @@ func (uc *BasketUseCase) AddItem(userID string, productID int, quantity int) (*d
 		"item_count": basket.GetItemCount(),
 	}).Info("Added item to basket")
 
-	return response, nil
+	return basket, nil
+}
+
+// addStaleItem increments an item already in the basket using its cached details
+// when the product service cannot be reached
+func (uc *BasketUseCase) addStaleItem(userID string, productID int, quantity int, productErr error) (*entity.Basket, error) {
+	start := time.Now()
+
+	basket, err := uc.basketRepo.GetBasket(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !basket.AddStaleQuantity(productID, quantity) {
+		return nil, fmt.Errorf("product %d is not in the basket", productID)
+	}
+	uc.priceItem(basket, productID)
+
+	err = uc.basketRepo.UpdateBasket(basket)
+	if err != nil {
+		metrics.RecordRedisOperation("UpdateBasket", "error", time.Since(start))
+		return nil, fmt.Errorf("failed to update basket: %w", err)
+	}
+	metrics.RecordRedisOperation("UpdateBasket", "success", time.Since(start))
+	metrics.RecordBasketOperation("add_item_stale")
+
+	uc.logger.WithError(productErr).WithFields(logrus.Fields{
+		"user_id":    userID,
+		"product_id": productID,
+		"quantity":   quantity,
+	}).Warn("Product service unavailable, added item using cached details")
+
+	return basket, nil
+}
+
+// UpdateItem updates the quantity of an item in the basket. A non-empty operationID makes the call safe to retry.
+func (uc *BasketUseCase) UpdateItem(userID string, productID int, quantity int, operationID string) (*dto.BasketResponse, error) {
+	return uc.applyOnce(userID, operationID, func() (*entity.Basket, error) {
+		return uc.updateItem(userID, productID, quantity)
+	})
 }
 
-// UpdateItem updates the quantity of an item in the basket
-func (uc *BasketUseCase) UpdateItem(userID string, productID int, quantity int) (*dto.BasketResponse, error) {
+// updateItem updates the quantity of an item in the basket and returns the saved basket
+func (uc *BasketUseCase) updateItem(userID string, productID int, quantity int) (*entity.Basket, error) {
 	start := time.Now()
 	defer metrics.RecordBasketOperation("update_item")
 
@@ -141,6 +323,7 @@ func (uc *BasketUseCase) UpdateItem(userID string, productID int, quantity int)
 
 	// Update item quantity
 	basket.UpdateItemQuantity(productID, quantity)
+	uc.priceItem(basket, productID)
 
 	// Save basket
 	err = uc.basketRepo.UpdateBasket(basket)
@@ -150,15 +333,69 @@ func (uc *BasketUseCase) UpdateItem(userID string, productID int, quantity int)
 	}
 	metrics.RecordRedisOperation("UpdateBasket", "success", time.Since(start))
 
-	response := uc.basketToResponse(basket)
-	
 	uc.logger.WithFields(logrus.Fields{
 		"user_id":    userID,
 		"product_id": productID,
 		"quantity":   quantity,
 	}).Info("Updated item quantity in basket")
 
-	return response, nil
+	return basket, nil
+}
+
+// priceItem sets the tax rate and discount of a basket line from the pricing policy.
+// The discount covers the whole line, so it follows quantity changes.
+func (uc *BasketUseCase) priceItem(basket *entity.Basket, productID int) {
+	for _, item := range basket.Items {
+		if item.ProductID == productID {
+			subtotal := item.Price * float64(item.Quantity)
+			basket.SetItemPricing(productID, uc.pricing.TaxRate(item.Category), uc.pricing.Discount(item.Category, subtotal))
+			return
+		}
+	}
+}
+
+// applyOnce runs a basket mutation at most once per operation ID. A repeated ID returns the
+// basket stored for the first request; a repeat that arrives while the first is still being
+// applied is rejected as a conflict. Without an operation ID the mutation always runs.
+func (uc *BasketUseCase) applyOnce(userID, operationID string, mutate func() (*entity.Basket, error)) (*dto.BasketResponse, error) {
+	if operationID == "" {
+		basket, err := mutate()
+		if err != nil {
+			return nil, err
+		}
+		return uc.basketToResponse(basket), nil
+	}
+
+	claimed, previous, err := uc.basketRepo.ClaimOperation(userID, operationID, uc.operationTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		if previous == nil {
+			return nil, fmt.Errorf("operation conflict: %s is still being applied", operationID)
+		}
+		metrics.RecordBasketOperation("duplicate_operation")
+		uc.logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"operation_id": operationID,
+		}).Info("Duplicate basket operation, returning previous result")
+		return uc.basketToResponse(previous), nil
+	}
+
+	basket, err := mutate()
+	if err != nil {
+		if releaseErr := uc.basketRepo.ReleaseOperation(userID, operationID); releaseErr != nil {
+			uc.logger.WithError(releaseErr).WithField("operation_id", operationID).Warn("Failed to release basket operation")
+		}
+		return nil, err
+	}
+
+	if err := uc.basketRepo.SaveOperationResult(userID, operationID, basket, uc.operationTTL); err != nil {
+		// The mutation is applied; retries see the claim as in flight until it expires
+		uc.logger.WithError(err).WithField("operation_id", operationID).Warn("Failed to store basket operation result")
+	}
+
+	return uc.basketToResponse(basket), nil
 }
 
 // RemoveItem removes an item from the basket
@@ -193,6 +430,82 @@ func (uc *BasketUseCase) RemoveItem(userID string, productID int) (*dto.BasketRe
 	return response, nil
 }
 
+// RefreshBasket re-reads every item's name, price and category from the product service.
+// Items that resolve are updated; the others keep their cached details, are flagged
+// needs_refresh and are listed in the response with the reason, so one failing product
+// never fails the whole refresh.
+func (uc *BasketUseCase) RefreshBasket(userID string) (*dto.BasketRefreshResponse, error) {
+	start := time.Now()
+	defer metrics.RecordBasketOperation("refresh_basket")
+
+	basket, err := uc.basketRepo.GetBasket(userID)
+	if err != nil {
+		metrics.RecordRedisOperation("GetBasket", "error", time.Since(start))
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	metrics.RecordRedisOperation("GetBasket", "success", time.Since(start))
+
+	result := &dto.BasketRefreshResponse{Refreshed: []int{}}
+	if len(basket.Items) == 0 {
+		result.Basket = uc.basketToResponse(basket)
+		return result, nil
+	}
+
+	productIDs := make([]int, 0, len(basket.Items))
+	for _, item := range basket.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lookupStart := time.Now()
+	products, failures := uc.productClient.GetProductsPartial(ctx, productIDs)
+	if len(failures) > 0 {
+		metrics.RecordProductServiceRequest("GetProducts", "error", time.Since(lookupStart))
+	} else {
+		metrics.RecordProductServiceRequest("GetProducts", "success", time.Since(lookupStart))
+	}
+
+	for _, productID := range productIDs {
+		if product, ok := products[productID]; ok {
+			basket.RefreshItem(productID, product.Name, product.Price, product.Category)
+			result.Refreshed = append(result.Refreshed, productID)
+			continue
+		}
+
+		reason := "product was not returned by the product service"
+		if lookupErr, ok := failures[productID]; ok {
+			reason = lookupErr.Error()
+		}
+		basket.MarkItemStale(productID)
+		result.Failed = append(result.Failed, dto.RefreshFailure{ProductID: productID, Reason: reason})
+	}
+	result.Partial = len(result.Failed) > 0
+
+	saveStart := time.Now()
+	if err := uc.basketRepo.UpdateBasket(basket); err != nil {
+		metrics.RecordRedisOperation("UpdateBasket", "error", time.Since(saveStart))
+		return nil, fmt.Errorf("failed to update basket: %w", err)
+	}
+	metrics.RecordRedisOperation("UpdateBasket", "success", time.Since(saveStart))
+
+	result.Basket = uc.basketToResponse(basket)
+
+	entry := uc.logger.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"refreshed": len(result.Refreshed),
+		"failed":    len(result.Failed),
+	})
+	if result.Partial {
+		entry.Warn("Basket partially refreshed, some items kept cached details")
+	} else {
+		entry.Info("Refreshed basket")
+	}
+
+	return result, nil
+}
+
 // ClearBasket clears all items from the basket
 func (uc *BasketUseCase) ClearBasket(userID string) (*dto.BasketResponse, error) {
 	start := time.Now()
@@ -218,6 +531,7 @@ func (uc *BasketUseCase) ClearBasket(userID string) (*dto.BasketResponse, error)
 	response := uc.basketToResponse(basket)
 	
 	uc.logger.WithField("user_id", userID).Info("Cleared basket")
+	uc.publishBasketCleared(userID, basket.ID, "Basket cleared")
 
 	return response, nil
 }
@@ -227,6 +541,9 @@ func (uc *BasketUseCase) DeleteBasket(userID string) error {
 	start := time.Now()
 	defer metrics.RecordBasketOperation("delete_basket")
 
+	// Looked up first so the cleared event carries the ID of the deleted basket
+	basket, lookupErr := uc.basketRepo.GetBasket(userID)
+
 	err := uc.basketRepo.DeleteBasket(userID)
 	if err != nil {
 		metrics.RecordRedisOperation("DeleteBasket", "error", time.Since(start))
@@ -235,9 +552,119 @@ func (uc *BasketUseCase) DeleteBasket(userID string) error {
 	metrics.RecordRedisOperation("DeleteBasket", "success", time.Since(start))
 
 	uc.logger.WithField("user_id", userID).Info("Deleted basket")
+	if lookupErr == nil {
+		uc.publishBasketCleared(userID, basket.ID, "Basket deleted")
+	}
+	return nil
+}
+
+// RemoveCheckedOutItems takes the products of a completed checkout out of the user's basket,
+// or empties it when productIDs is empty. A missing basket, or one replaced since the checkout
+// started, is left alone so the call is safe to repeat.
+func (uc *BasketUseCase) RemoveCheckedOutItems(userID, basketID string, productIDs []int) error {
+	start := time.Now()
+	defer metrics.RecordBasketOperation("remove_checked_out_items")
+
+	exists, err := uc.basketRepo.BasketExists(userID)
+	if err != nil {
+		metrics.RecordRedisOperation("BasketExists", "error", time.Since(start))
+		return fmt.Errorf("failed to check basket: %w", err)
+	}
+	if !exists {
+		uc.logger.WithField("user_id", userID).Debug("No basket to remove checked out items from")
+		return nil
+	}
+
+	basket, err := uc.basketRepo.GetBasket(userID)
+	if err != nil {
+		metrics.RecordRedisOperation("GetBasket", "error", time.Since(start))
+		return fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basketID != "" && basket.ID != basketID {
+		uc.logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"basket_id": basketID,
+		}).Info("Checked out basket was replaced, keeping the current one")
+		return nil
+	}
+
+	if len(productIDs) == 0 {
+		basket.Clear()
+	}
+	for _, productID := range productIDs {
+		basket.RemoveItem(productID)
+	}
+
+	if err := uc.basketRepo.UpdateBasket(basket); err != nil {
+		metrics.RecordRedisOperation("UpdateBasket", "error", time.Since(start))
+		return fmt.Errorf("failed to update basket: %w", err)
+	}
+	metrics.RecordRedisOperation("UpdateBasket", "success", time.Since(start))
+
+	uc.logger.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"basket_id":   basket.ID,
+		"product_ids": productIDs,
+		"items_left":  len(basket.Items),
+	}).Info("Removed checked out items from basket")
+
 	return nil
 }
 
+// publishItemAdded publishes a BasketItemAddedEvent for an item just added to the basket.
+// The basket change is already saved, so a failure is only logged.
+func (uc *BasketUseCase) publishItemAdded(basket *entity.Basket, productID, quantity int, operationID string) {
+	event := &events.BasketItemAddedEvent{
+		EventID:     uuid.New().String(),
+		OperationID: operationID,
+		UserID:      basket.UserID,
+		BasketID:    basket.ID,
+		ProductID:   productID,
+		Quantity:    quantity,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+	for _, item := range basket.Items {
+		if item.ProductID == productID {
+			event.ProductName = item.Name
+			event.Price = item.Price
+			break
+		}
+	}
+
+	if err := uc.kafkaPublisher.PublishBasketItemAdded(context.Background(), event); err != nil {
+		uc.logger.WithError(err).WithFields(logrus.Fields{
+			"event_id":     event.EventID,
+			"operation_id": operationID,
+			"user_id":      basket.UserID,
+			"product_id":   productID,
+		}).Error("Failed to publish basket item added event")
+	}
+}
+
+// publishBasketCleared publishes a BasketClearedEvent for a basket just emptied or deleted.
+// The basket change is already saved, so a failure is only logged.
+func (uc *BasketUseCase) publishBasketCleared(userID, basketID, reason string) {
+	event := &events.BasketClearedEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.BasketClearedEventType,
+		Timestamp: time.Now(),
+		UserID:    userID,
+		BasketID:  basketID,
+		Reason:    reason,
+		Metadata: map[string]interface{}{
+			"source": events.BasketServiceSource,
+		},
+	}
+
+	if err := uc.kafkaPublisher.PublishBasketCleared(context.Background(), event); err != nil {
+		uc.logger.WithError(err).WithFields(logrus.Fields{
+			"event_id":  event.EventID,
+			"user_id":   userID,
+			"basket_id": basketID,
+		}).Error("Failed to publish basket cleared event")
+	}
+}
+
 // getOrCreateBasket gets an existing basket or creates a new one
 func (uc *BasketUseCase) getOrCreateBasket(userID string) (*entity.Basket, error) {
 	// Try to get existing basket
@@ -257,12 +684,16 @@ func (uc *BasketUseCase) basketToResponse(basket *entity.Basket) *dto.BasketResp
 	var items []dto.BasketItemResponse
 	for _, item := range basket.Items {
 		items = append(items, dto.BasketItemResponse{
-			ProductID: item.ProductID,
-			Name:      item.Name,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-			Subtotal:  item.Subtotal,
-			Category:  item.Category,
+			ProductID:      item.ProductID,
+			Name:           item.Name,
+			Price:          item.Price,
+			Quantity:       item.Quantity,
+			Subtotal:       item.Subtotal,
+			Category:       item.Category,
+			NeedsRefresh:   item.NeedsRefresh,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			DiscountAmount: item.DiscountAmount,
 		})
 	}
 
@@ -292,12 +723,16 @@ func (uc *BasketUseCase) GetBasketItems(userID string) ([]dto.BasketItemResponse
 	var items []dto.BasketItemResponse
 	for _, item := range basket.Items {
 		items = append(items, dto.BasketItemResponse{
-			ProductID: item.ProductID,
-			Name:      item.Name,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-			Subtotal:  item.Subtotal,
-			Category:  item.Category,
+			ProductID:      item.ProductID,
+			Name:           item.Name,
+			Price:          item.Price,
+			Quantity:       item.Quantity,
+			Subtotal:       item.Subtotal,
+			Category:       item.Category,
+			NeedsRefresh:   item.NeedsRefresh,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			DiscountAmount: item.DiscountAmount,
 		})
 	}
 
@@ -319,7 +754,7 @@ func (uc *BasketUseCase) GetBasketTotal(userID string) (*dto.BasketTotalResponse
 		UserID:    userID,
 		Total:     basket.Total,
 		ItemCount: basket.GetItemCount(),
-		Currency:  "USD",
+		Currency:  uc.currency,
 	}, nil
 }
 
@@ -356,12 +791,13 @@ func (uc *BasketUseCase) GetBasketByCategory(userID, category string) ([]dto.Bas
 	for _, item := range basket.Items {
 		if item.Category == category {
 			items = append(items, dto.BasketItemResponse{
-				ProductID: item.ProductID,
-				Name:      item.Name,
-				Price:     item.Price,
-				Quantity:  item.Quantity,
-				Subtotal:  item.Subtotal,
-				Category:  item.Category,
+				ProductID:    item.ProductID,
+				Name:         item.Name,
+				Price:        item.Price,
+				Quantity:     item.Quantity,
+				Subtotal:     item.Subtotal,
+				Category:     item.Category,
+				NeedsRefresh: item.NeedsRefresh,
 			})
 		}
 	}
@@ -458,12 +894,16 @@ func (uc *BasketUseCase) GetBasketHistory(userID string) (*dto.BasketHistoryResp
 	var history []dto.BasketItemResponse
 	for _, item := range basket.Items {
 		history = append(history, dto.BasketItemResponse{
-			ProductID: item.ProductID,
-			Name:      item.Name,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-			Subtotal:  item.Subtotal,
-			Category:  item.Category,
+			ProductID:      item.ProductID,
+			Name:           item.Name,
+			Price:          item.Price,
+			Quantity:       item.Quantity,
+			Subtotal:       item.Subtotal,
+			Category:       item.Category,
+			NeedsRefresh:   item.NeedsRefresh,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			DiscountAmount: item.DiscountAmount,
 		})
 	}
 
@@ -474,34 +914,128 @@ func (uc *BasketUseCase) GetBasketHistory(userID string) (*dto.BasketHistoryResp
 	}, nil
 }
 
-// GetBasketRecommendations retrieves basket recommendations (simplified)
+// maxRecommendations is the number of products returned by GetBasketRecommendations
+const maxRecommendations = 5
+
+// GetBasketRecommendations recommends products frequently bought together with the items in the basket.
+// Items already in the basket are excluded; top-selling products fill in when co-purchase data is insufficient.
 func (uc *BasketUseCase) GetBasketRecommendations(userID string) (*dto.BasketRecommendationsResponse, error) {
 	start := time.Now()
-	defer metrics.RecordRedisOperation("GetBasketRecommendations", "success", time.Since(start))
-
-	// Simplified recommendations - in real implementation, this would use ML or business logic
-	recommendations := []dto.BasketItemResponse{
-		{
-			ProductID: 999,
-			Name:      "Recommended Product 1",
-			Price:     29.99,
-			Quantity:  1,
-			Subtotal:  29.99,
-			Category:  "Electronics",
-		},
-		{
-			ProductID: 998,
-			Name:      "Recommended Product 2",
-			Price:     19.99,
-			Quantity:  1,
-			Subtotal:  19.99,
-			Category:  "Accessories",
-		},
+
+	inBasket := make(map[int]bool)
+	var basketProductIDs []int
+	if basket, err := uc.basketRepo.GetBasket(userID); err == nil {
+		for _, item := range basket.Items {
+			inBasket[item.ProductID] = true
+			basketProductIDs = append(basketProductIDs, item.ProductID)
+		}
+	}
+
+	candidates, err := uc.basketRepo.GetCoPurchasedProducts(basketProductIDs, maxRecommendations)
+	if err != nil {
+		metrics.RecordRedisOperation("GetBasketRecommendations", "error", time.Since(start))
+		return nil, fmt.Errorf("failed to get co-purchased products: %w", err)
+	}
+	coPurchased := len(candidates)
+
+	if len(candidates) < maxRecommendations {
+		// Over-fetch so basket items and duplicates can be skipped
+		topSelling, err := uc.basketRepo.GetTopSellingProducts(maxRecommendations + len(inBasket) + len(candidates))
+		if err != nil {
+			metrics.RecordRedisOperation("GetBasketRecommendations", "error", time.Since(start))
+			return nil, fmt.Errorf("failed to get top selling products: %w", err)
+		}
+
+		chosen := make(map[int]bool, len(candidates))
+		for _, candidate := range candidates {
+			chosen[candidate.ProductID] = true
+		}
+		for _, product := range topSelling {
+			if len(candidates) >= maxRecommendations {
+				break
+			}
+			if inBasket[product.ProductID] || chosen[product.ProductID] {
+				continue
+			}
+			chosen[product.ProductID] = true
+			candidates = append(candidates, product)
+		}
+	}
+
+	productIDs := make([]int, 0, len(candidates))
+	for _, candidate := range candidates {
+		productIDs = append(productIDs, candidate.ProductID)
+	}
+
+	recommendations := make([]dto.BasketItemResponse, 0, len(productIDs))
+	if len(productIDs) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		products, err := uc.productClient.GetProducts(ctx, productIDs)
+		if err != nil {
+			metrics.RecordRedisOperation("GetBasketRecommendations", "error", time.Since(start))
+			return nil, fmt.Errorf("failed to get recommended products: %w", err)
+		}
+
+		byID := make(map[int]*service.ProductInfo, len(products))
+		for _, product := range products {
+			byID[product.ID] = product
+		}
+
+		// Keep the ranking order and drop products that are gone or unavailable
+		for _, productID := range productIDs {
+			product, ok := byID[productID]
+			if !ok || !product.Available {
+				continue
+			}
+			recommendations = append(recommendations, dto.BasketItemResponse{
+				ProductID: product.ID,
+				Name:      product.Name,
+				Price:     product.Price,
+				Quantity:  1,
+				Subtotal:  product.Price,
+				Category:  product.Category,
+			})
+		}
 	}
 
+	reason := "Frequently bought together with items in your basket"
+	switch {
+	case len(recommendations) == 0:
+		reason = "Not enough purchase data for recommendations yet"
+	case coPurchased == 0:
+		reason = "Popular products"
+	case coPurchased < len(candidates):
+		reason = "Frequently bought together with items in your basket, and popular products"
+	}
+
+	metrics.RecordRedisOperation("GetBasketRecommendations", "success", time.Since(start))
+
 	return &dto.BasketRecommendationsResponse{
-		UserID:         userID,
+		UserID:          userID,
 		Recommendations: recommendations,
-		Reason:         "Based on your current basket items",
+		Reason:          reason,
 	}, nil
 }
+
+// RecordCoPurchase records the products of a completed order for recommendations.
+// Redelivered orders are ignored so counts stay accurate under at-least-once delivery.
+func (uc *BasketUseCase) RecordCoPurchase(orderID string, productIDs []int) error {
+	start := time.Now()
+
+	recorded, err := uc.basketRepo.RecordCoPurchase(orderID, productIDs)
+	if err != nil {
+		metrics.RecordRedisOperation("RecordCoPurchase", "error", time.Since(start))
+		return fmt.Errorf("failed to record co-purchase: %w", err)
+	}
+	metrics.RecordRedisOperation("RecordCoPurchase", "success", time.Since(start))
+
+	uc.logger.WithFields(logrus.Fields{
+		"order_id":      orderID,
+		"product_count": len(productIDs),
+		"recorded":      recorded,
+	}).Info("Co-purchase processed")
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/basket/domain/entity"
+	"obs-tools-usage/internal/basket/domain/repository"
+	"obs-tools-usage/internal/basket/domain/service"
+	"obs-tools-usage/internal/basket/infrastructure/config"
+)
+
+// fakeBasketRepository keeps baskets and operation IDs in memory. Baskets are copied on the
+// way in and out, as Redis would, so the use case cannot mutate stored state by accident.
+// Methods the tests do not exercise panic through the nil embedded interface.
+type fakeBasketRepository struct {
+	repository.BasketRepository
+	baskets    map[string]*entity.Basket
+	operations map[string]*entity.Basket // nil value: claimed, result not stored yet
+	updates    int
+}
+
+func newFakeBasketRepository() *fakeBasketRepository {
+	return &fakeBasketRepository{
+		baskets:    make(map[string]*entity.Basket),
+		operations: make(map[string]*entity.Basket),
+	}
+}
+
+func copyBasket(basket *entity.Basket) *entity.Basket {
+	data, err := json.Marshal(basket)
+	if err != nil {
+		panic(err)
+	}
+	var copied entity.Basket
+	if err := json.Unmarshal(data, &copied); err != nil {
+		panic(err)
+	}
+	return &copied
+}
+
+func (r *fakeBasketRepository) GetBasket(userID string) (*entity.Basket, error) {
+	basket, ok := r.baskets[userID]
+	if !ok {
+		return nil, fmt.Errorf("basket not found for user %s", userID)
+	}
+	return copyBasket(basket), nil
+}
+
+func (r *fakeBasketRepository) CreateBasket(userID string) (*entity.Basket, error) {
+	now := time.Now()
+	basket := &entity.Basket{
+		ID:        "basket-" + userID,
+		UserID:    userID,
+		Items:     []entity.BasketItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(24 * time.Hour),
+	}
+	r.baskets[userID] = copyBasket(basket)
+	return basket, nil
+}
+
+func (r *fakeBasketRepository) UpdateBasket(basket *entity.Basket) error {
+	r.updates++
+	r.baskets[basket.UserID] = copyBasket(basket)
+	return nil
+}
+
+func (r *fakeBasketRepository) ClaimOperation(userID, operationID string, ttl time.Duration) (bool, *entity.Basket, error) {
+	key := userID + ":" + operationID
+	if previous, ok := r.operations[key]; ok {
+		if previous == nil {
+			return false, nil, nil
+		}
+		return false, copyBasket(previous), nil
+	}
+	r.operations[key] = nil
+	return true, nil, nil
+}
+
+func (r *fakeBasketRepository) SaveOperationResult(userID, operationID string, basket *entity.Basket, ttl time.Duration) error {
+	r.operations[userID+":"+operationID] = copyBasket(basket)
+	return nil
+}
+
+func (r *fakeBasketRepository) ReleaseOperation(userID, operationID string) error {
+	delete(r.operations, userID+":"+operationID)
+	return nil
+}
+
+// fakeProductClient serves the products it holds; any other ID fails to resolve.
+// IDs in omitted are left out of batch lookups without an error.
+type fakeProductClient struct {
+	service.ProductClient
+	products map[int]*service.ProductInfo
+	omitted  map[int]bool
+}
+
+func (c *fakeProductClient) GetProduct(ctx context.Context, productID int) (*service.ProductInfo, error) {
+	product, ok := c.products[productID]
+	if !ok {
+		return nil, fmt.Errorf("product %d not found", productID)
+	}
+	copied := *product
+	return &copied, nil
+}
+
+func (c *fakeProductClient) GetProductsPartial(ctx context.Context, productIDs []int) (map[int]*service.ProductInfo, map[int]error) {
+	products := make(map[int]*service.ProductInfo)
+	failures := make(map[int]error)
+	for _, productID := range productIDs {
+		if c.omitted[productID] {
+			continue
+		}
+		product, err := c.GetProduct(ctx, productID)
+		if err != nil {
+			failures[productID] = err
+			continue
+		}
+		products[productID] = product
+	}
+	return products, failures
+}
+
+func newTestBasketUseCase(repo repository.BasketRepository, products map[int]*service.ProductInfo, basketConfig config.BasketConfig) *BasketUseCase {
+	return newTestBasketUseCaseWithClient(repo, &fakeProductClient{products: products}, basketConfig)
+}
+
+func newTestBasketUseCaseWithClient(repo repository.BasketRepository, productClient service.ProductClient, basketConfig config.BasketConfig) *BasketUseCase {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	if basketConfig.OperationTTL == 0 {
+		basketConfig.OperationTTL = time.Minute
+	}
+	return NewBasketUseCase(repo, productClient, logger, basketConfig, nil)
+}
+
+func TestAddItemAppliesOperationIDOnce(t *testing.T) {
+	repo := newFakeBasketRepository()
+	uc := newTestBasketUseCase(repo, map[int]*service.ProductInfo{
+		1: {ID: 1, Name: "Keyboard", Price: 50, Stock: 10, Category: "electronics", Available: true},
+	}, config.BasketConfig{})
+
+	first, err := uc.AddItem("user-1", 1, 2, "op-1")
+	if err != nil {
+		t.Fatalf("first AddItem: %v", err)
+	}
+	retried, err := uc.AddItem("user-1", 1, 2, "op-1")
+	if err != nil {
+		t.Fatalf("retried AddItem: %v", err)
+	}
+
+	if repo.updates != 1 {
+		t.Errorf("basket saved %d times, want 1", repo.updates)
+	}
+	stored, _ := repo.GetBasket("user-1")
+	if got := stored.ItemQuantity(1); got != 2 {
+		t.Errorf("stored quantity = %d, want 2", got)
+	}
+	if retried.ItemCount != first.ItemCount || retried.Total != first.Total {
+		t.Errorf("retry returned item count %d total %.2f, want %d and %.2f", retried.ItemCount, retried.Total, first.ItemCount, first.Total)
+	}
+
+	if _, err := uc.AddItem("user-1", 1, 2, "op-2"); err != nil {
+		t.Fatalf("AddItem with a new operation ID: %v", err)
+	}
+	stored, _ = repo.GetBasket("user-1")
+	if got := stored.ItemQuantity(1); got != 4 {
+		t.Errorf("quantity after a new operation ID = %d, want 4", got)
+	}
+}
+
+func TestAddItemRetryAfterFailureApplies(t *testing.T) {
+	repo := newFakeBasketRepository()
+	products := map[int]*service.ProductInfo{
+		1: {ID: 1, Name: "Keyboard", Price: 50, Stock: 1, Category: "electronics", Available: true},
+	}
+	uc := newTestBasketUseCase(repo, products, config.BasketConfig{})
+
+	if _, err := uc.AddItem("user-1", 1, 2, "op-1"); err == nil {
+		t.Fatal("expected an insufficient stock error")
+	}
+
+	products[1].Stock = 5
+	if _, err := uc.AddItem("user-1", 1, 2, "op-1"); err != nil {
+		t.Fatalf("retry after a failed attempt: %v", err)
+	}
+	stored, _ := repo.GetBasket("user-1")
+	if got := stored.ItemQuantity(1); got != 2 {
+		t.Errorf("stored quantity = %d, want 2", got)
+	}
+}
+
+func TestRefreshBasketWithPartialProductLookup(t *testing.T) {
+	repo := newFakeBasketRepository()
+	basket, _ := repo.CreateBasket("user-1")
+	basket.AddItem(1, "Keyboard", 50, 1, "electronics", 0, 0)
+	basket.AddItem(2, "Mouse", 20, 2, "electronics", 0, 0)
+	basket.AddItem(3, "Desk", 200, 1, "furniture", 0, 0)
+	if err := repo.UpdateBasket(basket); err != nil {
+		t.Fatal(err)
+	}
+
+	// Product 1 resolves with a new price, product 2 is silently left out of the
+	// response and product 3 fails to resolve
+	uc := newTestBasketUseCaseWithClient(repo, &fakeProductClient{
+		products: map[int]*service.ProductInfo{
+			1: {ID: 1, Name: "Mechanical Keyboard", Price: 60, Stock: 10, Category: "electronics", Available: true},
+			2: {ID: 2, Name: "Mouse", Price: 25, Stock: 10, Category: "electronics", Available: true},
+		},
+		omitted: map[int]bool{2: true},
+	}, config.BasketConfig{})
+
+	result, err := uc.RefreshBasket("user-1")
+	if err != nil {
+		t.Fatalf("RefreshBasket: %v", err)
+	}
+
+	if !result.Partial {
+		t.Error("Partial = false, want true")
+	}
+	if len(result.Refreshed) != 1 || result.Refreshed[0] != 1 {
+		t.Errorf("Refreshed = %v, want [1]", result.Refreshed)
+	}
+	if len(result.Failed) != 2 {
+		t.Fatalf("Failed = %+v, want products 2 and 3", result.Failed)
+	}
+	if result.Failed[0].ProductID != 2 || result.Failed[0].Reason != "product was not returned by the product service" {
+		t.Errorf("Failed[0] = %+v, want product 2 not returned", result.Failed[0])
+	}
+	if result.Failed[1].ProductID != 3 || result.Failed[1].Reason != "product 3 not found" {
+		t.Errorf("Failed[1] = %+v, want product 3 with the lookup error", result.Failed[1])
+	}
+
+	stored, _ := repo.GetBasket("user-1")
+	want := map[int]struct {
+		name         string
+		price        float64
+		needsRefresh bool
+	}{
+		1: {"Mechanical Keyboard", 60, false},
+		2: {"Mouse", 20, true},
+		3: {"Desk", 200, true},
+	}
+	for _, item := range stored.Items {
+		w := want[item.ProductID]
+		if item.Name != w.name || item.Price != w.price || item.NeedsRefresh != w.needsRefresh {
+			t.Errorf("item %d = {%s %.2f needs_refresh=%v}, want {%s %.2f needs_refresh=%v}",
+				item.ProductID, item.Name, item.Price, item.NeedsRefresh, w.name, w.price, w.needsRefresh)
+		}
+	}
+	if stored.Total != 60+2*20+200 {
+		t.Errorf("stored total = %.2f, want %.2f", stored.Total, 60.0+2*20+200)
+	}
+}
+
+func TestAddItemRejectsIncrementalOvershoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		stock   int
+		maxQty  int
+		message string
+	}{
+		{name: "stock", stock: 5, message: "insufficient stock for product 1: 1 more can be added, 4 already in the basket"},
+		{name: "per-product cap", stock: 100, maxQty: 5, message: "invalid quantity: at most 5 of product 1 may be in the basket, 4 already are"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeBasketRepository()
+			uc := newTestBasketUseCase(repo, map[int]*service.ProductInfo{
+				1: {ID: 1, Name: "Keyboard", Price: 50, Stock: tt.stock, Category: "electronics", Available: true},
+			}, config.BasketConfig{MaxItemQuantity: tt.maxQty})
+
+			// Each add fits on its own; together the first two leave room for one more
+			for i, operationID := range []string{"op-1", "op-2"} {
+				if _, err := uc.AddItem("user-1", 1, 2, operationID); err != nil {
+					t.Fatalf("add %d: %v", i+1, err)
+				}
+			}
+
+			_, err := uc.AddItem("user-1", 1, 2, "op-3")
+			if err == nil {
+				t.Fatal("expected the third add to be rejected")
+			}
+			if err.Error() != tt.message {
+				t.Errorf("error = %q, want %q", err, tt.message)
+			}
+
+			stored, _ := repo.GetBasket("user-1")
+			if got := stored.ItemQuantity(1); got != 4 {
+				t.Errorf("stored quantity = %d, want 4", got)
+			}
+
+			if _, err := uc.AddItem("user-1", 1, 1, "op-4"); err != nil {
+				t.Fatalf("add up to the limit: %v", err)
+			}
+			stored, _ = repo.GetBasket("user-1")
+			if got := stored.ItemQuantity(1); got != 5 {
+				t.Errorf("quantity at the limit = %d, want 5", got)
+			}
+		})
+	}
+}
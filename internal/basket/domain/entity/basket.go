@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"math"
 	"time"
 )
 
@@ -24,24 +25,56 @@ type BasketItem struct {
 	Quantity  int     `json:"quantity" redis:"quantity"`
 	Subtotal  float64 `json:"subtotal" redis:"subtotal"`
 	Category  string  `json:"category,omitempty" redis:"category"`
+	// NeedsRefresh marks items whose name/price were not confirmed by the product service
+	NeedsRefresh bool `json:"needs_refresh,omitempty" redis:"needs_refresh"`
+	// TaxRate is the fraction of the discounted subtotal charged as tax, e.g. 0.18
+	TaxRate        float64 `json:"tax_rate" redis:"tax_rate"`
+	TaxAmount      float64 `json:"tax_amount" redis:"tax_amount"`
+	DiscountAmount float64 `json:"discount_amount" redis:"discount_amount"`
 }
 
-// CalculateTotal calculates the total price of the basket
+// LineTotal returns what the item costs after its discount and tax
+func (i *BasketItem) LineTotal() float64 {
+	return i.Subtotal + i.TaxAmount - i.DiscountAmount
+}
+
+// calculate recomputes the subtotal and tax of the item, capping the discount at the subtotal
+func (i *BasketItem) calculate() {
+	i.Subtotal = i.Price * float64(i.Quantity)
+	if i.DiscountAmount > i.Subtotal {
+		i.DiscountAmount = i.Subtotal
+	}
+	i.TaxAmount = math.Round((i.Subtotal-i.DiscountAmount)*i.TaxRate*100) / 100
+}
+
+// CalculateTotal calculates the total price of the basket as the sum of its line totals
 func (b *Basket) CalculateTotal() {
 	total := 0.0
 	for i := range b.Items {
-		b.Items[i].Subtotal = b.Items[i].Price * float64(b.Items[i].Quantity)
-		total += b.Items[i].Subtotal
+		b.Items[i].calculate()
+		total += b.Items[i].LineTotal()
 	}
 	b.Total = total
 	b.UpdatedAt = time.Now()
 }
 
-// AddItem adds an item to the basket
-func (b *Basket) AddItem(productID int, name string, price float64, quantity int, category string) {
+// AddItem adds an item to the basket. For an item already in the basket a non-zero
+// taxRate replaces its rate and discountAmount is added to its line discount.
+func (b *Basket) AddItem(productID int, name string, price float64, quantity int, category string, taxRate, discountAmount float64) {
 	// Check if item already exists
 	for i := range b.Items {
 		if b.Items[i].ProductID == productID {
+			// Reconcile items added while the product service was unavailable
+			if b.Items[i].NeedsRefresh {
+				b.Items[i].Name = name
+				b.Items[i].Price = price
+				b.Items[i].Category = category
+				b.Items[i].NeedsRefresh = false
+			}
+			if taxRate > 0 {
+				b.Items[i].TaxRate = taxRate
+			}
+			b.Items[i].DiscountAmount += discountAmount
 			b.Items[i].Quantity += quantity
 			b.Items[i].Subtotal = b.Items[i].Price * float64(b.Items[i].Quantity)
 			b.CalculateTotal()
@@ -51,11 +84,13 @@ func (b *Basket) AddItem(productID int, name string, price float64, quantity int
 
 	// Add new item
 	item := BasketItem{
-		ProductID: productID,
-		Name:      name,
-		Price:     price,
-		Quantity:  quantity,
-		Category:  category,
+		ProductID:      productID,
+		Name:           name,
+		Price:          price,
+		Quantity:       quantity,
+		Category:       category,
+		TaxRate:        taxRate,
+		DiscountAmount: discountAmount,
 	}
 	item.Subtotal = item.Price * float64(item.Quantity)
 	
@@ -63,6 +98,70 @@ func (b *Basket) AddItem(productID int, name string, price float64, quantity int
 	b.CalculateTotal()
 }
 
+// ItemQuantity returns the quantity of a product in the basket, 0 when it is not in the basket
+func (b *Basket) ItemQuantity(productID int) int {
+	for _, item := range b.Items {
+		if item.ProductID == productID {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+// AddStaleQuantity increments an existing item using its cached details and flags it for refresh.
+// It returns false when the product is not already in the basket.
+func (b *Basket) AddStaleQuantity(productID int, quantity int) bool {
+	for i := range b.Items {
+		if b.Items[i].ProductID == productID {
+			b.Items[i].Quantity += quantity
+			b.Items[i].NeedsRefresh = true
+			b.CalculateTotal()
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshItem replaces an item's cached product details with confirmed ones and clears its refresh flag.
+// It returns false when the product is not in the basket.
+func (b *Basket) RefreshItem(productID int, name string, price float64, category string) bool {
+	for i := range b.Items {
+		if b.Items[i].ProductID == productID {
+			b.Items[i].Name = name
+			b.Items[i].Price = price
+			b.Items[i].Category = category
+			b.Items[i].NeedsRefresh = false
+			b.CalculateTotal()
+			return true
+		}
+	}
+	return false
+}
+
+// MarkItemStale flags an item whose details could not be confirmed by the product service
+func (b *Basket) MarkItemStale(productID int) {
+	for i := range b.Items {
+		if b.Items[i].ProductID == productID {
+			b.Items[i].NeedsRefresh = true
+			return
+		}
+	}
+}
+
+// SetItemPricing replaces the tax rate and line discount of an item.
+// It returns false when the product is not in the basket.
+func (b *Basket) SetItemPricing(productID int, taxRate, discountAmount float64) bool {
+	for i := range b.Items {
+		if b.Items[i].ProductID == productID {
+			b.Items[i].TaxRate = taxRate
+			b.Items[i].DiscountAmount = discountAmount
+			b.CalculateTotal()
+			return true
+		}
+	}
+	return false
+}
+
 // RemoveItem removes an item from the basket
 func (b *Basket) RemoveItem(productID int) {
 	for i := range b.Items {
@@ -0,0 +1,7 @@
+package entity
+
+// ProductScore represents a product ranked by how often it was purchased
+type ProductScore struct {
+	ProductID int     `json:"product_id"`
+	Score     float64 `json:"score"`
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"obs-tools-usage/internal/basket/domain/entity"
 )
 
@@ -8,6 +10,7 @@ import (
 type BasketRepository interface {
 	// Basic CRUD operations
 	GetBasket(userID string) (*entity.Basket, error)
+	GetBaskets(userIDs []string) (map[string]*entity.Basket, error)
 	SaveBasket(basket *entity.Basket) error
 	DeleteBasket(userID string) error
 	
@@ -18,7 +21,22 @@ type BasketRepository interface {
 	// Utility operations
 	BasketExists(userID string) (bool, error)
 	GetAllBaskets() ([]*entity.Basket, error)
-	ClearExpiredBaskets() error
+	ClearExpiredBaskets() (int64, error)
+	
+	// Co-purchase operations
+	RecordCoPurchase(orderID string, productIDs []int) (bool, error)
+	GetCoPurchasedProducts(productIDs []int, limit int) ([]entity.ProductScore, error)
+	GetTopSellingProducts(limit int) ([]entity.ProductScore, error)
+	
+	// Idempotent operations
+	ClaimOperation(userID, operationID string, ttl time.Duration) (bool, *entity.Basket, error)
+	SaveOperationResult(userID, operationID string, basket *entity.Basket, ttl time.Duration) error
+	ReleaseOperation(userID, operationID string) error
+	
+	// Abandonment operations
+	GetIdleBaskets(idleSince time.Time) ([]*entity.Basket, error)
+	MarkBasketAbandoned(basketID string, cooldown time.Duration) (bool, error)
+	UnmarkBasketAbandoned(basketID string) error
 	
 	// Health check
 	Ping() error
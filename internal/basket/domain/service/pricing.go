@@ -0,0 +1,31 @@
+package service
+
+import "math"
+
+// PricingPolicy prices basket lines on the server, so clients cannot pick their own tax or discount.
+// Both default to zero for categories it does not configure.
+type PricingPolicy struct {
+	DefaultTaxRate    float64
+	CategoryTaxRates  map[string]float64 // Tax rate by category, e.g. 0.18, overriding DefaultTaxRate
+	CategoryDiscounts map[string]float64 // Percentage off by category, e.g. 10 for 10% off
+}
+
+// TaxRate returns the fraction of the discounted subtotal charged as tax for a category
+func (p PricingPolicy) TaxRate(category string) float64 {
+	if rate, ok := p.CategoryTaxRates[category]; ok && rate >= 0 && rate <= 1 {
+		return rate
+	}
+	return p.DefaultTaxRate
+}
+
+// Discount returns the discount on a line of the category with the given subtotal, rounded to cents
+func (p PricingPolicy) Discount(category string, subtotal float64) float64 {
+	percent := p.CategoryDiscounts[category]
+	if percent <= 0 || subtotal <= 0 {
+		return 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	return math.Round(subtotal*percent) / 100
+}
@@ -9,6 +9,8 @@ type ProductClient interface {
 	// Get product information
 	GetProduct(ctx context.Context, productID int) (*ProductInfo, error)
 	GetProducts(ctx context.Context, productIDs []int) ([]*ProductInfo, error)
+	// GetProductsPartial looks up every product and reports why each failed lookup failed
+	GetProductsPartial(ctx context.Context, productIDs []int) (map[int]*ProductInfo, map[int]error)
 	
 	// Health check
 	Ping(ctx context.Context) error
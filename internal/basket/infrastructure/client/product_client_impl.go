@@ -6,10 +6,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"obs-tools-usage/internal/basket/domain/service"
 	pb "obs-tools-usage/api/proto/product"
+	"obs-tools-usage/pkg/grpctls"
+	"obs-tools-usage/pkg/tracing"
 )
 
 // ProductClientImpl implements ProductClient interface using gRPC
@@ -20,9 +21,17 @@ type ProductClientImpl struct {
 }
 
 // NewProductClientImpl creates a new product client implementation
-func NewProductClientImpl(productServiceURL string, logger *logrus.Logger) (*ProductClientImpl, error) {
+func NewProductClientImpl(productServiceURL string, logger *logrus.Logger, tlsConfig grpctls.Config) (*ProductClientImpl, error) {
+	creds, err := tlsConfig.ClientCredentials()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create gRPC connection
-	conn, err := grpc.Dial(productServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(productServiceURL,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to product service: %w", err)
 	}
@@ -95,6 +104,32 @@ func (c *ProductClientImpl) GetProducts(ctx context.Context, productIDs []int) (
 	return products, nil
 }
 
+// GetProductsPartial retrieves multiple products by IDs. Products that resolved are
+// returned keyed by ID; every product that could not be retrieved maps to its error.
+func (c *ProductClientImpl) GetProductsPartial(ctx context.Context, productIDs []int) (map[int]*service.ProductInfo, map[int]error) {
+	c.logger.WithField("product_ids", productIDs).Debug("Getting products from product service")
+
+	products := make(map[int]*service.ProductInfo, len(productIDs))
+	failures := make(map[int]error)
+
+	for _, productID := range productIDs {
+		product, err := c.GetProduct(ctx, productID)
+		if err != nil {
+			failures[productID] = err
+			continue
+		}
+		products[productID] = product
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"requested_count": len(productIDs),
+		"retrieved_count": len(products),
+		"failed_count":    len(failures),
+	}).Debug("Retrieved products")
+
+	return products, failures
+}
+
 // Ping checks the health of the product service
 func (c *ProductClientImpl) Ping(ctx context.Context) error {
 	// Try to get a product to check if service is responsive
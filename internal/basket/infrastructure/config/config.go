@@ -3,28 +3,60 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"obs-tools-usage/pkg/grpctls"
 )
 
 // Config holds the configuration for the basket service
 type Config struct {
 	Port        string
+	GRPCPort    string
 	Environment string
+	MaxBodySize int64 // Maximum accepted HTTP request body size in bytes
 	LogLevel    string
 	LogFormat   string
 	LogOutput   string
 	LogDir      string
 	LogFile     string
+	LogSampling LogSamplingConfig
+	JobLock     JobLockConfig
 	Redis       RedisConfig
 	Product     ProductConfig
+	Basket      BasketConfig
+	Kafka       KafkaConfig
+	Tracing     TracingConfig
+	Metrics     MetricsConfig
+	GRPCTLS     grpctls.Config
+	GRPCLimits  GRPCLimitsConfig
+	HTTP        HTTPConfig
+
+	// EnableGRPCReflection registers the gRPC reflection service, which lets developers
+	// introspect and call the gRPC API with tools like grpcurl without the .proto files
+	EnableGRPCReflection bool
+}
+
+// HTTPConfig holds gin engine configuration
+type HTTPConfig struct {
+	Mode           string   // gin mode: debug or release
+	TrustedProxies []string // IPs or CIDRs of proxies, such as the gateway, whose X-Forwarded-For is trusted
 }
 
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-	PoolSize int
+	Host                string
+	Port                string
+	Password            string
+	DB                  int
+	PoolSize            int
+	MinIdleConns        int
+	MaxRetries          int
+	DialTimeout         time.Duration
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	StartupTimeout      time.Duration
+	HealthCheckInterval time.Duration
 }
 
 // ProductConfig holds product service configuration
@@ -32,28 +64,175 @@ type ProductConfig struct {
 	ServiceURL string
 }
 
+// BasketConfig holds basket behaviour configuration
+type BasketConfig struct {
+	// AllowStaleAdd lets AddItem increment an item already in the basket
+	// using its cached name and price when the product service is unavailable
+	AllowStaleAdd bool
+	// ExpirySweepInterval is how often expired baskets are counted and pruned from the expiry index.
+	// Baskets themselves are evicted by their Redis TTL.
+	ExpirySweepInterval time.Duration
+	// OperationTTL is how long client-supplied operation IDs are remembered for AddItem/UpdateItem retries
+	OperationTTL time.Duration
+	// BatchMaxUsers caps the number of user IDs accepted by POST /baskets/batch
+	BatchMaxUsers int
+	// MaxItemQuantity caps the quantity of a single product a basket may hold; 0 leaves only the stock limit
+	MaxItemQuantity int
+	// AdminToken is the bearer token required by admin routes; empty disables them
+	AdminToken string
+	// Currency is the ISO 4217 code basket prices and totals are expressed in
+	Currency string
+	// AbandonmentThreshold is how long a basket with items must go without updates to count as abandoned
+	AbandonmentThreshold time.Duration
+	// AbandonmentCooldown is how long an abandoned basket is not reported again while it stays idle
+	AbandonmentCooldown time.Duration
+	// AbandonmentScanInterval is how often idle baskets are checked for abandonment
+	AbandonmentScanInterval time.Duration
+	// KeyPrefix namespaces basket keys in Redis, e.g. "basket:prod:", so environments can share an instance
+	KeyPrefix string
+	// DefaultTaxRate is the tax rate of items whose category has none in CategoryTaxRates
+	DefaultTaxRate float64
+	// CategoryTaxRates is the tax rate of items by category, e.g. 0.18
+	CategoryTaxRates map[string]float64
+	// CategoryDiscounts is the percentage off items by category, e.g. 10 for 10% off
+	CategoryDiscounts map[string]float64
+}
+
+// KafkaConfig holds Kafka publisher and consumer configuration
+type KafkaConfig struct {
+	Enabled       bool
+	Brokers       []string
+	ConsumerGroup string
+	// OperationEvents publishes item added and basket cleared events for basket operations
+	OperationEvents bool
+}
+
+// JobLockConfig holds the lease that keeps periodic jobs to one replica at a time
+type JobLockConfig struct {
+	Enabled bool
+	TTL     time.Duration // How long a lease outlives a replica that stopped renewing it
+}
+
+// MetricsConfig holds metrics collection configuration
+type MetricsConfig struct {
+	ScrapeInterval time.Duration // How often runtime gauges are refreshed
+}
+
+// LogSamplingConfig holds sampling of high-volume Info logs
+type LogSamplingConfig struct {
+	Rate   int  // Keep 1 in Rate routine "operation completed" logs; 1 keeps all
+	Demote bool // Log them at Debug instead of sampling
+}
+
+// GRPCLimitsConfig holds the limits the gRPC server enforces on clients; zero keeps gRPC's default
+type GRPCLimitsConfig struct {
+	MaxConcurrentStreams int           // Streams a single connection may have open at once
+	MaxRecvMsgSize       int           // Largest message in bytes the server accepts
+	KeepaliveTime        time.Duration // Idle time after which the server pings the client
+	KeepaliveTimeout     time.Duration // How long a ping may go unanswered before the connection is closed
+	MaxConnectionIdle    time.Duration // Idle time after which a connection without streams is closed
+	MinPingInterval      time.Duration // Clients pinging more often than this are disconnected
+	PermitWithoutStream  bool          // Allow client pings on connections without active streams
+}
+
+// TracingConfig holds OpenTelemetry trace export configuration
+type TracingConfig struct {
+	Enabled  bool
+	Endpoint string // OTLP gRPC collector address
+	Insecure bool
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	environment := getEnv("ENVIRONMENT", "development")
 	
 	return &Config{
 		Port:        getEnv("PORT", "8081"),
+		GRPCPort:    getEnv("GRPC_PORT", "50051"),
 		Environment: environment,
+		MaxBodySize: int64(getEnvAsInt("MAX_REQUEST_BODY_BYTES", 1<<20)),
 		LogLevel:    getLogLevelFromEnv(environment),
 		LogFormat:   getLogFormatFromEnv(environment),
 		LogOutput:   getLogOutputFromEnv(environment),
 		LogDir:      getEnv("LOG_DIR", "./logs"),
 		LogFile:     getEnv("LOG_FILE", "basket-service.log"),
+		LogSampling: LogSamplingConfig{
+			Rate:   getEnvAsInt("LOG_SAMPLE_RATE", 1),
+			Demote: getEnvAsBool("LOG_SAMPLE_DEMOTE", false),
+		},
+		JobLock: JobLockConfig{
+			Enabled: getEnvAsBool("JOB_LOCK_ENABLED", true),
+			TTL:     getEnvAsPositiveDuration("JOB_LOCK_TTL", 30*time.Second),
+		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvAsInt("REDIS_DB", 0),
-			PoolSize: getEnvAsInt("REDIS_POOL_SIZE", 10),
+			Host:                getEnv("REDIS_HOST", "localhost"),
+			Port:                getEnv("REDIS_PORT", "6379"),
+			Password:            getEnv("REDIS_PASSWORD", ""),
+			DB:                  getEnvAsInt("REDIS_DB", 0),
+			PoolSize:            getEnvAsInt("REDIS_POOL_SIZE", 10),
+			MinIdleConns:        getEnvAsInt("REDIS_MIN_IDLE_CONNS", 5),
+			MaxRetries:          getEnvAsInt("REDIS_MAX_RETRIES", 3),
+			DialTimeout:         getEnvAsDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+			ReadTimeout:         getEnvAsDuration("REDIS_READ_TIMEOUT", 3*time.Second),
+			WriteTimeout:        getEnvAsDuration("REDIS_WRITE_TIMEOUT", 3*time.Second),
+			StartupTimeout:      getEnvAsDuration("REDIS_STARTUP_TIMEOUT", 30*time.Second),
+			HealthCheckInterval: getEnvAsPositiveDuration("REDIS_HEALTH_CHECK_INTERVAL", 10*time.Second),
 		},
 		Product: ProductConfig{
 			ServiceURL: getEnv("PRODUCT_SERVICE_URL", "localhost:50050"),
 		},
+		Basket: BasketConfig{
+			AllowStaleAdd:           getEnvAsBool("BASKET_ALLOW_STALE_ADD", false),
+			ExpirySweepInterval:     getEnvAsPositiveDuration("BASKET_EXPIRY_SWEEP_INTERVAL", time.Minute),
+			OperationTTL:            getEnvAsDuration("BASKET_OPERATION_TTL", 10*time.Minute),
+			BatchMaxUsers:           getEnvAsInt("BASKET_BATCH_MAX_USERS", 100),
+			MaxItemQuantity:         getEnvAsInt("BASKET_MAX_ITEM_QUANTITY", 0),
+			AdminToken:              getEnv("ADMIN_TOKEN", ""),
+			Currency:                getEnv("DEFAULT_CURRENCY", "USD"),
+			AbandonmentThreshold:    getEnvAsDuration("BASKET_ABANDONMENT_THRESHOLD", time.Hour),
+			AbandonmentCooldown:     getEnvAsDuration("BASKET_ABANDONMENT_COOLDOWN", 24*time.Hour),
+			AbandonmentScanInterval: getEnvAsPositiveDuration("BASKET_ABANDONMENT_SCAN_INTERVAL", 5*time.Minute),
+			KeyPrefix:               getEnv("BASKET_KEY_PREFIX", "basket:"),
+			DefaultTaxRate:          getEnvAsFloat("BASKET_DEFAULT_TAX_RATE", 0),
+			CategoryTaxRates:        getEnvAsFloatMap("BASKET_CATEGORY_TAX_RATES", map[string]float64{}),
+			CategoryDiscounts:       getEnvAsFloatMap("BASKET_CATEGORY_DISCOUNTS", map[string]float64{}),
+		},
+		Kafka: KafkaConfig{
+			Enabled:         getEnvAsBool("KAFKA_ENABLED", false),
+			Brokers:         getEnvAsSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
+			ConsumerGroup:   getEnv("KAFKA_CONSUMER_GROUP", "basket-service"),
+			OperationEvents: getEnvAsBool("BASKET_OPERATION_EVENTS_ENABLED", true),
+		},
+		Tracing: TracingConfig{
+			Enabled:  getEnvAsBool("TRACING_ENABLED", false),
+			Endpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			Insecure: getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		},
+		Metrics: MetricsConfig{
+			ScrapeInterval: getEnvAsPositiveDuration("METRICS_SCRAPE_INTERVAL", 5*time.Second),
+		},
+		GRPCTLS: grpctls.Config{
+			// Only development falls back to insecure connections by default
+			Enabled:    getEnvAsBool("GRPC_TLS_ENABLED", environment != "development"),
+			CertFile:   getEnv("GRPC_TLS_CERT_FILE", ""),
+			KeyFile:    getEnv("GRPC_TLS_KEY_FILE", ""),
+			CAFile:     getEnv("GRPC_TLS_CA_FILE", ""),
+			ServerName: getEnv("GRPC_TLS_SERVER_NAME", ""),
+		},
+		GRPCLimits: GRPCLimitsConfig{
+			MaxConcurrentStreams: getEnvAsInt("GRPC_MAX_CONCURRENT_STREAMS", 100),
+			MaxRecvMsgSize:       getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", 4<<20),
+			KeepaliveTime:        getEnvAsDuration("GRPC_KEEPALIVE_TIME", 2*time.Minute),
+			KeepaliveTimeout:     getEnvAsDuration("GRPC_KEEPALIVE_TIMEOUT", 20*time.Second),
+			MaxConnectionIdle:    getEnvAsDuration("GRPC_MAX_CONNECTION_IDLE", 15*time.Minute),
+			MinPingInterval:      getEnvAsDuration("GRPC_KEEPALIVE_MIN_PING_INTERVAL", 30*time.Second),
+			PermitWithoutStream:  getEnvAsBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", true),
+		},
+		HTTP: HTTPConfig{
+			Mode:           getGinModeFromEnv(environment),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}),
+		},
+		EnableGRPCReflection: getEnvAsBool("ENABLE_GRPC_REFLECTION", environment != "production"),
 	}
 }
 
@@ -94,6 +273,96 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as boolean with a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration gets an environment variable as duration with a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsPositiveDuration gets an environment variable as a positive duration.
+// Values that are not positive fall back to the default.
+func getEnvAsPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := getEnvAsDuration(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice gets a comma-separated environment variable as a string slice with a default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsFloatMap parses "key=number" pairs separated by commas, skipping malformed or negative entries
+func getEnvAsFloatMap(key string, defaultValue map[string]float64) map[string]float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || number < 0 {
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = number
+	}
+	return result
+}
+
+// getGinModeFromEnv determines the gin mode, defaulting to release mode in production
+func getGinModeFromEnv(environment string) string {
+	if mode := os.Getenv("GIN_MODE"); mode != "" {
+		return mode
+	}
+	if environment == "production" {
+		return "release"
+	}
+	return "debug"
+}
+
 // getLogLevelFromEnv determines log level from environment
 func getLogLevelFromEnv(environment string) string {
 	// First check LOG_LEVEL environment variable
@@ -0,0 +1,116 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/basket/domain/repository"
+	"obs-tools-usage/internal/basket/infrastructure/config"
+	"obs-tools-usage/internal/basket/infrastructure/metrics"
+	"obs-tools-usage/kafka/events"
+	"obs-tools-usage/kafka/publisher"
+)
+
+// AbandonmentDetector periodically reports baskets that still hold items but have not been
+// updated for the abandonment threshold. Baskets that are checked out are cleared and never
+// reported. A reported basket is flagged for the cooldown so it is not reported on every scan.
+type AbandonmentDetector struct {
+	basketRepo     repository.BasketRepository
+	kafkaPublisher publisher.BasketEventPublisher
+	config         config.BasketConfig
+	logger         *logrus.Logger
+}
+
+// NewAbandonmentDetector creates a new abandonment detector.
+// A nil kafkaPublisher is replaced by a NoopPublisher, which flags baskets without publishing.
+func NewAbandonmentDetector(basketRepo repository.BasketRepository, kafkaPublisher publisher.BasketEventPublisher, cfg config.BasketConfig, logger *logrus.Logger) *AbandonmentDetector {
+	if kafkaPublisher == nil {
+		logger.Warn("No Kafka publisher configured for abandonment detector, events will be discarded")
+		kafkaPublisher = publisher.NewNoopPublisher(logger)
+	}
+	return &AbandonmentDetector{
+		basketRepo:     basketRepo,
+		kafkaPublisher: kafkaPublisher,
+		config:         cfg,
+		logger:         logger,
+	}
+}
+
+// Start scans for abandoned baskets until the context is cancelled
+func (d *AbandonmentDetector) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.config.AbandonmentScanInterval)
+	defer ticker.Stop()
+
+	d.logger.WithFields(logrus.Fields{
+		"scan_interval": d.config.AbandonmentScanInterval.String(),
+		"threshold":     d.config.AbandonmentThreshold.String(),
+		"cooldown":      d.config.AbandonmentCooldown.String(),
+	}).Info("Basket abandonment detector started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Basket abandonment detector stopped")
+			return
+		case <-ticker.C:
+			d.scan(ctx)
+		}
+	}
+}
+
+// scan reports every idle basket that is not flagged yet
+func (d *AbandonmentDetector) scan(ctx context.Context) {
+	now := time.Now()
+	baskets, err := d.basketRepo.GetIdleBaskets(now.Add(-d.config.AbandonmentThreshold))
+	if err != nil {
+		d.logger.WithError(err).Error("Failed to load idle baskets")
+		return
+	}
+
+	reported := 0
+	for _, basket := range baskets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Flag first so concurrent replicas never report the same basket twice
+		marked, err := d.basketRepo.MarkBasketAbandoned(basket.ID, d.config.AbandonmentCooldown)
+		if err != nil || !marked {
+			continue
+		}
+
+		event := &events.BasketAbandonedEvent{
+			EventID:     uuid.New().String(),
+			UserID:      basket.UserID,
+			BasketID:    basket.ID,
+			ItemCount:   basket.GetItemCount(),
+			TotalValue:  basket.Total,
+			AbandonedAt: basket.UpdatedAt.Format(time.RFC3339),
+			Timestamp:   now.Format(time.RFC3339),
+		}
+		if err := d.kafkaPublisher.PublishBasketAbandoned(ctx, event); err != nil {
+			d.logger.WithError(err).WithFields(logrus.Fields{
+				"user_id":   basket.UserID,
+				"basket_id": basket.ID,
+			}).Warn("Failed to publish basket abandoned event, will retry on next scan")
+
+			if err := d.basketRepo.UnmarkBasketAbandoned(basket.ID); err != nil {
+				d.logger.WithError(err).WithField("basket_id", basket.ID).Error("Failed to unmark abandoned basket")
+			}
+			continue
+		}
+
+		metrics.RecordBasketAbandoned()
+		reported++
+	}
+
+	if reported > 0 {
+		d.logger.WithFields(logrus.Fields{
+			"idle_count":     len(baskets),
+			"reported_count": reported,
+		}).Info("Abandoned baskets reported")
+	}
+}
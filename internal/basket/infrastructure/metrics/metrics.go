@@ -1,12 +1,13 @@
 package metrics
 
 import (
+	"context"
+	"runtime"
+	"strconv"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/sirupsen/logrus"
 )
 
 // Prometheus metrics for basket service
@@ -44,6 +45,20 @@ var (
 		},
 	)
 
+	basketsExpiredTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "baskets_expired_total",
+			Help: "Total number of baskets evicted by their Redis TTL",
+		},
+	)
+
+	basketsAbandonedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "baskets_abandoned_total",
+			Help: "Total number of baskets reported as abandoned",
+		},
+	)
+
 	basketOperationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "basket_operations_total",
@@ -52,6 +67,24 @@ var (
 		[]string{"operation"},
 	)
 
+	// gRPC server metrics
+	grpcRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "basket_grpc_requests_total",
+			Help: "Total number of gRPC requests handled by the basket service",
+		},
+		[]string{"method", "status"},
+	)
+
+	grpcRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "basket_grpc_request_duration_seconds",
+			Help:    "gRPC request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method"},
+	)
+
 	// Redis metrics
 	redisOperationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -87,20 +120,57 @@ var (
 		},
 		[]string{"operation"},
 	)
+
+	// Runtime metrics
+	memoryAllocBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "basket_memory_alloc_bytes",
+			Help: "Current memory allocation in bytes",
+		},
+	)
+
+	memoryHeapBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "basket_memory_heap_bytes",
+			Help: "Heap memory size in bytes",
+		},
+	)
+
+	goroutinesTotal = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "basket_goroutines_total",
+			Help: "Current number of goroutines",
+		},
+	)
 )
 
 // RecordHTTPRequest records HTTP request metrics
 func RecordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration) {
-	statusCodeStr := string(rune(statusCode))
-	httpRequestsTotal.WithLabelValues(method, endpoint, statusCodeStr).Inc()
+	httpRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(statusCode)).Inc()
 	httpRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
 }
 
+// RecordGRPCRequest records the outcome and duration of a gRPC request
+func RecordGRPCRequest(method, status string, duration time.Duration) {
+	grpcRequestsTotal.WithLabelValues(method, status).Inc()
+	grpcRequestDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
 // RecordBasketOperation records basket operation metrics
 func RecordBasketOperation(operation string) {
 	basketOperationsTotal.WithLabelValues(operation).Inc()
 }
 
+// RecordBasketsExpired records baskets that expired through their TTL
+func RecordBasketsExpired(count int64) {
+	basketsExpiredTotal.Add(float64(count))
+}
+
+// RecordBasketAbandoned records a basket reported as abandoned
+func RecordBasketAbandoned() {
+	basketsAbandonedTotal.Inc()
+}
+
 // RecordRedisOperation records Redis operation metrics
 func RecordRedisOperation(operation, status string, duration time.Duration) {
 	redisOperationsTotal.WithLabelValues(operation, status).Inc()
@@ -119,33 +189,30 @@ func UpdateBasketMetrics(basketCount, itemCount int) {
 	basketItemsTotal.Set(float64(itemCount))
 }
 
-// HTTPLoggingMiddleware logs HTTP requests and responses
-func HTTPLoggingMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		
-		// Process request
-		c.Next()
-		
-		// Calculate duration
-		duration := time.Since(start)
-		
-		// Record metrics
-		RecordHTTPRequest(c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
-		
-		// Log request
-		logger := logrus.WithFields(logrus.Fields{
-			"method":      c.Request.Method,
-			"path":        c.Request.URL.Path,
-			"status_code": c.Writer.Status(),
-			"duration_ms": duration.Milliseconds(),
-			"ip":          c.ClientIP(),
-		})
-		
-		if c.Writer.Status() >= 400 {
-			logger.Error("HTTP request completed with error")
-		} else {
-			logger.Info("HTTP request completed")
+// UpdateSystemMetrics updates runtime metrics. The basket service keeps its state in Redis,
+// so unlike the database-backed services it has no connection pool to report.
+func UpdateSystemMetrics() {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	memoryAllocBytes.Set(float64(memStats.Alloc))
+	memoryHeapBytes.Set(float64(memStats.HeapAlloc))
+	goroutinesTotal.Set(float64(runtime.NumGoroutine()))
+}
+
+// StartSystemMetricsCollector calls UpdateSystemMetrics on every interval until the context is cancelled
+func StartSystemMetricsCollector(ctx context.Context, interval time.Duration) {
+	UpdateSystemMetrics()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			UpdateSystemMetrics()
 		}
 	}
 }
@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/basket/domain/entity"
+)
+
+// idleBasketBatchSize is how many activity index entries are loaded per MGET
+const idleBasketBatchSize = 100
+
+// GetIdleBaskets returns the baskets with items that have not been updated since idleSince.
+// Index entries left behind by baskets Redis has already evicted are pruned along the way.
+func (r *BasketRepositoryImpl) GetIdleBaskets(idleSince time.Time) ([]*entity.Basket, error) {
+	ctx := context.Background()
+
+	r.logger.WithField("idle_since", idleSince).Debug("Getting idle baskets from Redis")
+
+	var idle []*entity.Basket
+	var evicted []interface{}
+	idleBefore := strconv.FormatInt(idleSince.Unix(), 10)
+	for offset := int64(0); ; offset += idleBasketBatchSize {
+		userIDs, err := r.client.ZRangeByScore(ctx, r.getActivityIndexKey(), &redis.ZRangeBy{
+			Min:    "-inf",
+			Max:    idleBefore,
+			Offset: offset,
+			Count:  idleBasketBatchSize,
+		}).Result()
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to read basket activity index")
+			return nil, fmt.Errorf("failed to read basket activity index: %w", err)
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		baskets, err := r.GetBaskets(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range userIDs {
+			basket := baskets[userID]
+			if basket == nil {
+				evicted = append(evicted, userID)
+				continue
+			}
+			if len(basket.Items) > 0 {
+				idle = append(idle, basket)
+			}
+		}
+
+		if len(userIDs) < idleBasketBatchSize {
+			break
+		}
+	}
+
+	// Pruned after the walk so removals don't shift the offsets being paged through
+	if len(evicted) > 0 {
+		if err := r.client.ZRem(ctx, r.getActivityIndexKey(), evicted...).Err(); err != nil {
+			r.logger.WithError(err).Warn("Failed to prune basket activity index")
+		}
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"idle_count":    len(idle),
+		"evicted_count": len(evicted),
+	}).Debug("Successfully retrieved idle baskets")
+	return idle, nil
+}
+
+// MarkBasketAbandoned flags a basket as abandoned for the cooldown. It returns false when
+// the basket is already flagged, so the same abandonment is only reported once per cooldown.
+func (r *BasketRepositoryImpl) MarkBasketAbandoned(basketID string, cooldown time.Duration) (bool, error) {
+	ctx := context.Background()
+
+	marked, err := r.client.SetNX(ctx, r.getAbandonedKey(basketID), time.Now().Unix(), cooldown).Result()
+	if err != nil {
+		r.logger.WithError(err).WithField("basket_id", basketID).Error("Failed to mark basket as abandoned")
+		return false, fmt.Errorf("failed to mark basket as abandoned: %w", err)
+	}
+	return marked, nil
+}
+
+// UnmarkBasketAbandoned clears the abandonment flag, e.g. when reporting the abandonment failed
+func (r *BasketRepositoryImpl) UnmarkBasketAbandoned(basketID string) error {
+	ctx := context.Background()
+
+	if err := r.client.Del(ctx, r.getAbandonedKey(basketID)).Err(); err != nil {
+		r.logger.WithError(err).WithField("basket_id", basketID).Error("Failed to unmark abandoned basket")
+		return fmt.Errorf("failed to unmark abandoned basket: %w", err)
+	}
+	return nil
+}
+
+// getAbandonedKey generates the Redis key flagging an abandoned basket.
+// It does not share the basket key prefix so basket scans never see it.
+func (r *BasketRepositoryImpl) getAbandonedKey(basketID string) string {
+	return fmt.Sprintf("%sabandoned:%s", r.auxPrefix, basketID)
+}
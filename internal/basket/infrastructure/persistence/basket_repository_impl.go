@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -13,17 +15,33 @@ import (
 	"obs-tools-usage/internal/basket/domain/repository"
 )
 
+// DefaultKeyPrefix is the basket key prefix used when none is configured
+const DefaultKeyPrefix = "basket:"
+
 // BasketRepositoryImpl implements BasketRepository interface using Redis
 type BasketRepositoryImpl struct {
 	client *redis.Client
 	logger *logrus.Logger
+
+	// keyPrefix namespaces basket keys, e.g. "basket:prod:"
+	keyPrefix string
+	// auxPrefix namespaces indexes and bookkeeping keys. It is derived from keyPrefix
+	// but never starts with it, so basket scans only ever see basket keys.
+	auxPrefix string
 }
 
-// NewBasketRepositoryImpl creates a new basket repository implementation
-func NewBasketRepositoryImpl(client *redis.Client, logger *logrus.Logger) repository.BasketRepository {
+// NewBasketRepositoryImpl creates a new basket repository implementation.
+// keyPrefix is prepended to every basket key; empty uses DefaultKeyPrefix.
+func NewBasketRepositoryImpl(client *redis.Client, logger *logrus.Logger, keyPrefix string) repository.BasketRepository {
+	if keyPrefix == "" {
+		keyPrefix = DefaultKeyPrefix
+	}
+
 	return &BasketRepositoryImpl{
-		client: client,
-		logger: logger,
+		client:    client,
+		logger:    logger,
+		keyPrefix: keyPrefix,
+		auxPrefix: strings.TrimSuffix(keyPrefix, ":") + "-",
 	}
 }
 
@@ -64,6 +82,51 @@ func (r *BasketRepositoryImpl) GetBasket(userID string) (*entity.Basket, error)
 	return &basket, nil
 }
 
+// GetBaskets retrieves several baskets with a single MGET. Every requested user ID is
+// present in the result; missing, expired or unreadable baskets map to nil.
+func (r *BasketRepositoryImpl) GetBaskets(userIDs []string) (map[string]*entity.Basket, error) {
+	ctx := context.Background()
+
+	r.logger.WithField("count", len(userIDs)).Debug("Getting baskets from Redis")
+
+	baskets := make(map[string]*entity.Basket, len(userIDs))
+	if len(userIDs) == 0 {
+		return baskets, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = r.getBasketKey(userID)
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get baskets from Redis")
+		return nil, fmt.Errorf("failed to get baskets: %w", err)
+	}
+
+	for i, userID := range userIDs {
+		baskets[userID] = nil
+
+		data, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+
+		var basket entity.Basket
+		if err := json.Unmarshal([]byte(data), &basket); err != nil {
+			r.logger.WithError(err).WithField("user_id", userID).Warn("Failed to unmarshal basket data, skipping")
+			continue
+		}
+		if basket.IsExpired() {
+			continue
+		}
+		baskets[userID] = &basket
+	}
+
+	return baskets, nil
+}
+
 // SaveBasket saves a basket to Redis
 func (r *BasketRepositoryImpl) SaveBasket(basket *entity.Basket) error {
 	ctx := context.Background()
@@ -82,7 +145,12 @@ func (r *BasketRepositoryImpl) SaveBasket(basket *entity.Basket) error {
 		return fmt.Errorf("basket is already expired")
 	}
 
-	err = r.client.Set(ctx, r.getBasketKey(basket.UserID), data, ttl).Err()
+	// The key TTL evicts the basket; the expiry index only lets the sweep count expirations
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.getBasketKey(basket.UserID), data, ttl)
+	pipe.ZAdd(ctx, r.getExpiryIndexKey(), &redis.Z{Score: float64(basket.ExpiresAt.Unix()), Member: basket.UserID})
+	pipe.ZAdd(ctx, r.getActivityIndexKey(), &redis.Z{Score: float64(basket.UpdatedAt.Unix()), Member: basket.UserID})
+	_, err = pipe.Exec(ctx)
 	if err != nil {
 		r.logger.WithError(err).WithField("user_id", basket.UserID).Error("Failed to save basket to Redis")
 		return fmt.Errorf("failed to save basket: %w", err)
@@ -104,7 +172,11 @@ func (r *BasketRepositoryImpl) DeleteBasket(userID string) error {
 	
 	r.logger.WithField("user_id", userID).Debug("Deleting basket from Redis")
 
-	err := r.client.Del(ctx, r.getBasketKey(userID)).Err()
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, r.getBasketKey(userID))
+	pipe.ZRem(ctx, r.getExpiryIndexKey(), userID)
+	pipe.ZRem(ctx, r.getActivityIndexKey(), userID)
+	_, err := pipe.Exec(ctx)
 	if err != nil {
 		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to delete basket from Redis")
 		return fmt.Errorf("failed to delete basket: %w", err)
@@ -161,14 +233,12 @@ func (r *BasketRepositoryImpl) GetAllBaskets() ([]*entity.Basket, error) {
 	
 	r.logger.Debug("Getting all baskets from Redis")
 
-	keys, err := r.client.Keys(ctx, "basket:*").Result()
-	if err != nil {
-		r.logger.WithError(err).Error("Failed to get basket keys")
-		return nil, fmt.Errorf("failed to get basket keys: %w", err)
-	}
-
 	var baskets []*entity.Basket
-	for _, key := range keys {
+
+	// SCAN walks the keyspace incrementally instead of blocking Redis like KEYS
+	iter := r.client.Scan(ctx, 0, r.keyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
 		data, err := r.client.Get(ctx, key).Result()
 		if err != nil {
 			r.logger.WithError(err).WithField("key", key).Warn("Failed to get basket data, skipping")
@@ -188,52 +258,31 @@ func (r *BasketRepositoryImpl) GetAllBaskets() ([]*entity.Basket, error) {
 
 		baskets = append(baskets, &basket)
 	}
+	if err := iter.Err(); err != nil {
+		r.logger.WithError(err).Error("Failed to scan basket keys")
+		return nil, fmt.Errorf("failed to scan basket keys: %w", err)
+	}
 
 	r.logger.WithField("count", len(baskets)).Debug("Successfully retrieved all baskets")
 	return baskets, nil
 }
 
-// ClearExpiredBaskets removes all expired baskets
-func (r *BasketRepositoryImpl) ClearExpiredBaskets() error {
+// ClearExpiredBaskets sweeps the expiry index and returns how many baskets expired since the last sweep.
+// Basket expiry itself is TTL-driven: Redis evicts basket keys when their TTL elapses, so this never
+// touches basket keys and never scans the keyspace. It only drops index entries whose expiry has passed.
+func (r *BasketRepositoryImpl) ClearExpiredBaskets() (int64, error) {
 	ctx := context.Background()
 	
-	r.logger.Debug("Clearing expired baskets from Redis")
+	r.logger.Debug("Sweeping basket expiry index")
 
-	keys, err := r.client.Keys(ctx, "basket:*").Result()
+	removed, err := r.client.ZRemRangeByScore(ctx, r.getExpiryIndexKey(), "-inf", strconv.FormatInt(time.Now().Unix(), 10)).Result()
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get basket keys")
-		return fmt.Errorf("failed to get basket keys: %w", err)
+		r.logger.WithError(err).Error("Failed to sweep basket expiry index")
+		return 0, fmt.Errorf("failed to sweep basket expiry index: %w", err)
 	}
 
-	var expiredKeys []string
-	now := time.Now()
-
-	for _, key := range keys {
-		data, err := r.client.Get(ctx, key).Result()
-		if err != nil {
-			continue
-		}
-
-		var basket entity.Basket
-		if err := json.Unmarshal([]byte(data), &basket); err != nil {
-			continue
-		}
-
-		if now.After(basket.ExpiresAt) {
-			expiredKeys = append(expiredKeys, key)
-		}
-	}
-
-	if len(expiredKeys) > 0 {
-		err = r.client.Del(ctx, expiredKeys...).Err()
-		if err != nil {
-			r.logger.WithError(err).Error("Failed to delete expired baskets")
-			return fmt.Errorf("failed to delete expired baskets: %w", err)
-		}
-	}
-
-	r.logger.WithField("deleted_count", len(expiredKeys)).Info("Successfully cleared expired baskets")
-	return nil
+	r.logger.WithField("expired_count", removed).Debug("Swept basket expiry index")
+	return removed, nil
 }
 
 // Ping checks the Redis connection
@@ -251,5 +300,15 @@ func (r *BasketRepositoryImpl) Ping() error {
 
 // getBasketKey generates the Redis key for a basket
 func (r *BasketRepositoryImpl) getBasketKey(userID string) string {
-	return fmt.Sprintf("basket:%s", userID)
+	return r.keyPrefix + userID
+}
+
+// getExpiryIndexKey returns the sorted set of user IDs scored by basket expiry time
+func (r *BasketRepositoryImpl) getExpiryIndexKey() string {
+	return r.auxPrefix + "index:expiry"
+}
+
+// getActivityIndexKey returns the sorted set of user IDs scored by the basket's last update time
+func (r *BasketRepositoryImpl) getActivityIndexKey() string {
+	return r.auxPrefix + "index:activity"
 }
@@ -0,0 +1,194 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/basket/domain/entity"
+)
+
+const (
+	// coPurchaseMaxRelated bounds the related products kept per product
+	coPurchaseMaxRelated = 200
+	// coPurchaseOrderTTL is how long a recorded order is remembered for deduplication
+	coPurchaseOrderTTL = 7 * 24 * time.Hour
+)
+
+// RecordCoPurchase records that the given products were bought together in one order.
+// Each order is counted once; it returns false when the order was already recorded.
+func (r *BasketRepositoryImpl) RecordCoPurchase(orderID string, productIDs []int) (bool, error) {
+	ctx := context.Background()
+
+	ids := uniqueProductIDs(productIDs)
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	recorded, err := r.client.SetNX(ctx, r.getCoPurchaseOrderKey(orderID), 1, coPurchaseOrderTTL).Result()
+	if err != nil {
+		r.logger.WithError(err).WithField("order_id", orderID).Error("Failed to check co-purchase order")
+		return false, fmt.Errorf("failed to record co-purchase: %w", err)
+	}
+	if !recorded {
+		r.logger.WithField("order_id", orderID).Debug("Co-purchase already recorded for order")
+		return false, nil
+	}
+
+	pipe := r.client.TxPipeline()
+	for _, productID := range ids {
+		pipe.ZIncrBy(ctx, r.getTopSellingKey(), 1, strconv.Itoa(productID))
+
+		key := r.getCoPurchaseKey(productID)
+		for _, relatedID := range ids {
+			if relatedID == productID {
+				continue
+			}
+			pipe.ZIncrBy(ctx, key, 1, strconv.Itoa(relatedID))
+		}
+		pipe.ZRemRangeByRank(ctx, key, 0, -coPurchaseMaxRelated-1)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		// Forget the order so a redelivered event can record it again
+		r.client.Del(ctx, r.getCoPurchaseOrderKey(orderID))
+		r.logger.WithError(err).WithField("order_id", orderID).Error("Failed to record co-purchase")
+		return false, fmt.Errorf("failed to record co-purchase: %w", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"order_id":      orderID,
+		"product_count": len(ids),
+	}).Debug("Recorded co-purchase")
+
+	return true, nil
+}
+
+// GetCoPurchasedProducts returns products frequently bought with any of the given products,
+// ranked by the summed co-occurrence count. The given products themselves are excluded.
+func (r *BasketRepositoryImpl) GetCoPurchasedProducts(productIDs []int, limit int) ([]entity.ProductScore, error) {
+	ctx := context.Background()
+
+	ids := uniqueProductIDs(productIDs)
+	if len(ids) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.ZSliceCmd, 0, len(ids))
+	for _, productID := range ids {
+		cmds = append(cmds, pipe.ZRevRangeWithScores(ctx, r.getCoPurchaseKey(productID), 0, coPurchaseMaxRelated-1))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		r.logger.WithError(err).Error("Failed to get co-purchased products")
+		return nil, fmt.Errorf("failed to get co-purchased products: %w", err)
+	}
+
+	exclude := make(map[int]bool, len(ids))
+	for _, productID := range ids {
+		exclude[productID] = true
+	}
+
+	scores := make(map[int]float64)
+	for _, cmd := range cmds {
+		for _, z := range cmd.Val() {
+			productID, ok := parseProductMember(z.Member)
+			if !ok || exclude[productID] {
+				continue
+			}
+			scores[productID] += z.Score
+		}
+	}
+
+	return rankProductScores(scores, limit), nil
+}
+
+// GetTopSellingProducts returns the products bought in the most orders
+func (r *BasketRepositoryImpl) GetTopSellingProducts(limit int) ([]entity.ProductScore, error) {
+	ctx := context.Background()
+
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	members, err := r.client.ZRevRangeWithScores(ctx, r.getTopSellingKey(), 0, int64(limit-1)).Result()
+	if err != nil && err != redis.Nil {
+		r.logger.WithError(err).Error("Failed to get top selling products")
+		return nil, fmt.Errorf("failed to get top selling products: %w", err)
+	}
+
+	result := make([]entity.ProductScore, 0, len(members))
+	for _, z := range members {
+		productID, ok := parseProductMember(z.Member)
+		if !ok {
+			continue
+		}
+		result = append(result, entity.ProductScore{ProductID: productID, Score: z.Score})
+	}
+	return result, nil
+}
+
+// getCoPurchaseKey generates the Redis key holding products bought together with a product
+func (r *BasketRepositoryImpl) getCoPurchaseKey(productID int) string {
+	return fmt.Sprintf("%scopurchase:%d", r.auxPrefix, productID)
+}
+
+// getTopSellingKey generates the Redis key holding per-product order counts
+func (r *BasketRepositoryImpl) getTopSellingKey() string {
+	return r.auxPrefix + "copurchase:top"
+}
+
+// getCoPurchaseOrderKey generates the Redis key marking an order as recorded
+func (r *BasketRepositoryImpl) getCoPurchaseOrderKey(orderID string) string {
+	return fmt.Sprintf("%scopurchase:order:%s", r.auxPrefix, orderID)
+}
+
+// uniqueProductIDs removes duplicate and invalid product IDs, keeping the first occurrence
+func uniqueProductIDs(productIDs []int) []int {
+	seen := make(map[int]bool, len(productIDs))
+	ids := make([]int, 0, len(productIDs))
+	for _, productID := range productIDs {
+		if productID <= 0 || seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		ids = append(ids, productID)
+	}
+	return ids
+}
+
+// parseProductMember converts a sorted set member back into a product ID
+func parseProductMember(member interface{}) (int, bool) {
+	value, ok := member.(string)
+	if !ok {
+		return 0, false
+	}
+	productID, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return productID, true
+}
+
+// rankProductScores orders scores descending, breaking ties by product ID, and keeps the top limit
+func rankProductScores(scores map[int]float64, limit int) []entity.ProductScore {
+	ranked := make([]entity.ProductScore, 0, len(scores))
+	for productID, score := range scores {
+		ranked = append(ranked, entity.ProductScore{ProductID: productID, Score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ProductID < ranked[j].ProductID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/basket/domain/entity"
+)
+
+// operationPending marks an operation that was claimed but has no stored result yet
+const operationPending = ""
+
+// ClaimOperation reserves an operation ID for a basket mutation. When the ID was already
+// claimed it returns false together with the basket stored for it, or a nil basket while
+// the first request is still being applied.
+func (r *BasketRepositoryImpl) ClaimOperation(userID, operationID string, ttl time.Duration) (bool, *entity.Basket, error) {
+	ctx := context.Background()
+	key := r.getOperationKey(userID, operationID)
+
+	claimed, err := r.client.SetNX(ctx, key, operationPending, ttl).Result()
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"user_id":      userID,
+			"operation_id": operationID,
+		}).Error("Failed to claim basket operation")
+		return false, nil, fmt.Errorf("failed to claim operation: %w", err)
+	}
+	if claimed {
+		return true, nil, nil
+	}
+
+	data, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		// The claim expired between SETNX and GET; treat it as still in flight
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get operation result: %w", err)
+	}
+	if data == operationPending {
+		return false, nil, nil
+	}
+
+	var basket entity.Basket
+	if err := json.Unmarshal([]byte(data), &basket); err != nil {
+		return false, nil, fmt.Errorf("failed to unmarshal operation result: %w", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"operation_id": operationID,
+	}).Debug("Duplicate basket operation")
+	return false, &basket, nil
+}
+
+// SaveOperationResult stores the basket produced by a claimed operation so duplicates can replay it
+func (r *BasketRepositoryImpl) SaveOperationResult(userID, operationID string, basket *entity.Basket, ttl time.Duration) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(basket)
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation result: %w", err)
+	}
+
+	if err := r.client.Set(ctx, r.getOperationKey(userID, operationID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save operation result: %w", err)
+	}
+	return nil
+}
+
+// ReleaseOperation forgets a claimed operation whose mutation failed, so a retry can apply it
+func (r *BasketRepositoryImpl) ReleaseOperation(userID, operationID string) error {
+	ctx := context.Background()
+
+	if err := r.client.Del(ctx, r.getOperationKey(userID, operationID)).Err(); err != nil {
+		return fmt.Errorf("failed to release operation: %w", err)
+	}
+	return nil
+}
+
+// getOperationKey generates the Redis key for a basket operation ID.
+// It does not share the basket key prefix so basket scans never see it.
+func (r *BasketRepositoryImpl) getOperationKey(userID, operationID string) string {
+	return fmt.Sprintf("%sop:%s:%s", r.auxPrefix, userID, operationID)
+}
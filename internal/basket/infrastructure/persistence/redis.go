@@ -0,0 +1,151 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/basket/infrastructure/config"
+)
+
+// NewRedisClient creates a Redis client from the basket service configuration
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:         cfg.Host + ":" + cfg.Port,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+		MaxRetries:   cfg.MaxRetries,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	})
+}
+
+// WaitForRedis pings Redis with exponential backoff until it responds or the window elapses
+func WaitForRedis(ctx context.Context, client *redis.Client, window time.Duration, logger *logrus.Logger) error {
+	deadline := time.Now().Add(window)
+	backoff := 500 * time.Millisecond
+	attempt := 0
+
+	for {
+		attempt++
+		err := client.Ping(ctx).Err()
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("redis not reachable after %d attempts: %w", attempt, err)
+		}
+
+		logger.WithError(err).WithFields(logrus.Fields{
+			"attempt": attempt,
+			"backoff": backoff.String(),
+		}).Warn("Redis not reachable, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > 5*time.Second {
+			backoff = 5 * time.Second
+		}
+	}
+}
+
+// RedisHealthMonitor periodically pings Redis and tracks its readiness
+type RedisHealthMonitor struct {
+	client   *redis.Client
+	interval time.Duration
+	logger   *logrus.Logger
+
+	mu        sync.RWMutex
+	healthy   bool
+	lastError error
+	lastCheck time.Time
+}
+
+// NewRedisHealthMonitor creates a new Redis health monitor
+func NewRedisHealthMonitor(client *redis.Client, interval time.Duration, logger *logrus.Logger) *RedisHealthMonitor {
+	return &RedisHealthMonitor{
+		client:   client,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start runs the health check loop until the context is cancelled
+func (m *RedisHealthMonitor) Start(ctx context.Context) {
+	m.check(ctx)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// check pings Redis once and flips the readiness flag on state changes
+func (m *RedisHealthMonitor) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+
+	err := m.client.Ping(pingCtx).Err()
+
+	m.mu.Lock()
+	wasHealthy := m.healthy
+	m.healthy = err == nil
+	m.lastError = err
+	m.lastCheck = time.Now()
+	m.mu.Unlock()
+
+	switch {
+	case err != nil && wasHealthy:
+		m.logger.WithError(err).Error("Redis became unreachable")
+	case err == nil && !wasHealthy:
+		m.logger.Info("Redis connection is healthy")
+	}
+}
+
+// IsHealthy reports whether the last Redis ping succeeded
+func (m *RedisHealthMonitor) IsHealthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.healthy
+}
+
+// Check reports the last observed Redis state as a health check result without pinging again
+func (m *RedisHealthMonitor) Check(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.healthy {
+		return nil
+	}
+	if m.lastError != nil {
+		return m.lastError
+	}
+	return fmt.Errorf("redis has not been checked yet")
+}
+
+// LastError returns the error of the last failed ping, if any
+func (m *RedisHealthMonitor) LastError() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastError
+}
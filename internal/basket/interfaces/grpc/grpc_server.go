@@ -9,9 +9,9 @@ import (
 
 	"obs-tools-usage/api/proto/basket"
 	"obs-tools-usage/internal/basket/application/command"
+	"obs-tools-usage/internal/basket/application/dto"
 	"obs-tools-usage/internal/basket/application/handler"
 	"obs-tools-usage/internal/basket/application/query"
-	"obs-tools-usage/internal/basket/infrastructure/metrics"
 )
 
 // BasketGRPCServer implements the BasketService gRPC server
@@ -33,9 +33,6 @@ func NewBasketGRPCServer(commandHandler *handler.CommandHandler, queryHandler *h
 
 // GetBasket retrieves a basket by user ID
 func (s *BasketGRPCServer) GetBasket(ctx context.Context, req *basket.GetBasketRequest) (*basket.GetBasketResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("GetBasket", "success", time.Since(start))
-
 	s.logger.WithFields(logrus.Fields{
 		"user_id": req.UserId,
 	}).Debug("gRPC GetBasket request received")
@@ -68,9 +65,6 @@ func (s *BasketGRPCServer) GetBasket(ctx context.Context, req *basket.GetBasketR
 
 // CreateBasket creates a new basket for a user
 func (s *BasketGRPCServer) CreateBasket(ctx context.Context, req *basket.CreateBasketRequest) (*basket.CreateBasketResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("CreateBasket", "success", time.Since(start))
-
 	s.logger.WithField("user_id", req.UserId).Debug("gRPC CreateBasket request received")
 
 	// Handle command
@@ -97,9 +91,6 @@ func (s *BasketGRPCServer) CreateBasket(ctx context.Context, req *basket.CreateB
 
 // DeleteBasket deletes a basket
 func (s *BasketGRPCServer) DeleteBasket(ctx context.Context, req *basket.DeleteBasketRequest) (*basket.DeleteBasketResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("DeleteBasket", "success", time.Since(start))
-
 	s.logger.WithField("user_id", req.UserId).Debug("gRPC DeleteBasket request received")
 
 	// Handle command
@@ -122,9 +113,6 @@ func (s *BasketGRPCServer) DeleteBasket(ctx context.Context, req *basket.DeleteB
 
 // AddItem adds an item to the basket
 func (s *BasketGRPCServer) AddItem(ctx context.Context, req *basket.AddItemRequest) (*basket.AddItemResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("AddItem", "success", time.Since(start))
-
 	s.logger.WithFields(logrus.Fields{
 		"user_id":    req.UserId,
 		"product_id": req.ProductId,
@@ -166,9 +154,6 @@ func (s *BasketGRPCServer) AddItem(ctx context.Context, req *basket.AddItemReque
 
 // UpdateItem updates the quantity of an item in the basket
 func (s *BasketGRPCServer) UpdateItem(ctx context.Context, req *basket.UpdateItemRequest) (*basket.UpdateItemResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("UpdateItem", "success", time.Since(start))
-
 	s.logger.WithFields(logrus.Fields{
 		"user_id":    req.UserId,
 		"product_id": req.ProductId,
@@ -210,9 +195,6 @@ func (s *BasketGRPCServer) UpdateItem(ctx context.Context, req *basket.UpdateIte
 
 // RemoveItem removes an item from the basket
 func (s *BasketGRPCServer) RemoveItem(ctx context.Context, req *basket.RemoveItemRequest) (*basket.RemoveItemResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("RemoveItem", "success", time.Since(start))
-
 	s.logger.WithFields(logrus.Fields{
 		"user_id":    req.UserId,
 		"product_id": req.ProductId,
@@ -251,9 +233,6 @@ func (s *BasketGRPCServer) RemoveItem(ctx context.Context, req *basket.RemoveIte
 
 // ClearBasket clears all items from the basket
 func (s *BasketGRPCServer) ClearBasket(ctx context.Context, req *basket.ClearBasketRequest) (*basket.ClearBasketResponse, error) {
-	start := time.Now()
-	defer metrics.RecordProductServiceRequest("ClearBasket", "success", time.Since(start))
-
 	s.logger.WithField("user_id", req.UserId).Debug("gRPC ClearBasket request received")
 
 	// Handle command
@@ -293,18 +272,35 @@ func (s *BasketGRPCServer) HealthCheck(ctx context.Context, req *basket.HealthCh
 }
 
 // convertToGRPCBasket converts internal basket response to gRPC basket message
-func (s *BasketGRPCServer) convertToGRPCBasket(basketResponse interface{}) *basket.Basket {
-	// This is a simplified conversion - in a real implementation you'd properly map the fields
-	// For now, return an empty basket as placeholder
+func (s *BasketGRPCServer) convertToGRPCBasket(basketResponse *dto.BasketResponse) *basket.Basket {
+	if basketResponse == nil {
+		return &basket.Basket{}
+	}
+
+	items := make([]*basket.BasketItem, 0, len(basketResponse.Items))
+	for _, item := range basketResponse.Items {
+		items = append(items, &basket.BasketItem{
+			ProductId:      int32(item.ProductID),
+			Name:           item.Name,
+			Price:          item.Price,
+			Quantity:       int32(item.Quantity),
+			Subtotal:       item.Subtotal,
+			Category:       item.Category,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			DiscountAmount: item.DiscountAmount,
+		})
+	}
+
 	return &basket.Basket{
-		Id:        "converted-id",
-		UserId:    "converted-user-id",
-		Items:     []*basket.BasketItem{},
-		Total:     0.0,
-		ItemCount: 0,
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
-		ExpiresAt: time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		Id:        basketResponse.ID,
+		UserId:    basketResponse.UserID,
+		Items:     items,
+		Total:     basketResponse.Total,
+		ItemCount: int32(basketResponse.ItemCount),
+		CreatedAt: basketResponse.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: basketResponse.UpdatedAt.Format(time.RFC3339),
+		ExpiresAt: basketResponse.ExpiresAt.Format(time.RFC3339),
 	}
 }
 
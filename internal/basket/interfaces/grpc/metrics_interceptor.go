@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"obs-tools-usage/internal/basket/infrastructure/metrics"
+)
+
+// successReporter is implemented by basket responses, which report failures in-band
+// through their Success field instead of returning an error
+type successReporter interface {
+	GetSuccess() bool
+}
+
+// UnaryMetricsInterceptor records every basket gRPC call as "success" or "error".
+// A call is an error when the handler returns one or when the response reports Success=false.
+func UnaryMetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		status := "success"
+		if err != nil {
+			status = "error"
+		} else if reporter, ok := resp.(successReporter); ok && !reporter.GetSuccess() {
+			status = "error"
+		}
+
+		metrics.RecordGRPCRequest(path.Base(info.FullMethod), status, time.Since(start))
+		return resp, err
+	}
+}
@@ -5,14 +5,9 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"obs-tools-usage/pkg/respond"
 )
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
 // HandleError handles errors and returns appropriate HTTP responses
 func HandleError(c *gin.Context, err error) {
 	if err == nil {
@@ -40,8 +35,5 @@ func HandleError(c *gin.Context, err error) {
 		statusCode = http.StatusGone
 	}
 
-	c.JSON(statusCode, ErrorResponse{
-		Error:   http.StatusText(statusCode),
-		Message: errorMsg,
-	})
+	respond.Error(c, statusCode, errorMsg)
 }
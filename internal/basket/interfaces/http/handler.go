@@ -2,26 +2,31 @@ package http
 
 import (
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"obs-tools-usage/internal/basket/application/command"
 	"obs-tools-usage/internal/basket/application/dto"
 	"obs-tools-usage/internal/basket/application/handler"
 	"obs-tools-usage/internal/basket/application/query"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/respond"
 )
 
 // Handler handles HTTP requests using CQRS pattern
 type Handler struct {
 	commandHandler *handler.CommandHandler
 	queryHandler   *handler.QueryHandler
+	health         *health.Checker
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler) *Handler {
+func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler, checker *health.Checker) *Handler {
 	return &Handler{
 		commandHandler: commandHandler,
 		queryHandler:   queryHandler,
+		health:         checker,
 	}
 }
 
@@ -29,30 +34,30 @@ func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.Qu
 func (h *Handler) GetBasket(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
-	basket, err := h.queryHandler.HandleGetBasket(query.GetBasketQuery{UserID: userID})
+	enrich, err := parseBoolQuery(c, "enrich")
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, "enrich must be true or false")
+		return
+	}
+
+	basket, err := h.queryHandler.HandleGetBasket(query.GetBasketQuery{UserID: userID, Enrich: enrich})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, basket)
+	respond.OK(c, http.StatusOK, basket)
 }
 
 // CreateBasket handles POST /baskets
 func (h *Handler) CreateBasket(c *gin.Context) {
 	var cmd command.CreateBasketCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
@@ -62,26 +67,20 @@ func (h *Handler) CreateBasket(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, basket)
+	respond.OK(c, http.StatusCreated, basket)
 }
 
 // AddItem handles POST /baskets/:user_id/items
 func (h *Handler) AddItem(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	var cmd command.AddItemCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
@@ -93,7 +92,7 @@ func (h *Handler) AddItem(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, basket)
+	respond.OK(c, http.StatusOK, basket)
 }
 
 // UpdateItem handles PUT /baskets/:user_id/items/:product_id
@@ -102,27 +101,18 @@ func (h *Handler) UpdateItem(c *gin.Context) {
 	productIDStr := c.Param("product_id")
 	
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	if productIDStr == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid product ID",
-			Message: "Product ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Product ID is required")
 		return
 	}
 
 	var cmd command.UpdateItemCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
@@ -135,7 +125,7 @@ func (h *Handler) UpdateItem(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, basket)
+	respond.OK(c, http.StatusOK, basket)
 }
 
 // RemoveItem handles DELETE /baskets/:user_id/items/:product_id
@@ -144,18 +134,12 @@ func (h *Handler) RemoveItem(c *gin.Context) {
 	productIDStr := c.Param("product_id")
 	
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	if productIDStr == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid product ID",
-			Message: "Product ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Product ID is required")
 		return
 	}
 
@@ -171,17 +155,33 @@ func (h *Handler) RemoveItem(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, basket)
+	respond.OK(c, http.StatusOK, basket)
+}
+
+// RefreshBasket handles POST /baskets/:user_id/refresh
+func (h *Handler) RefreshBasket(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	cmd := command.RefreshBasketCommand{UserID: userID}
+
+	result, err := h.commandHandler.HandleRefreshBasket(cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, result)
 }
 
 // ClearBasket handles DELETE /baskets/:user_id/items
 func (h *Handler) ClearBasket(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -193,17 +193,14 @@ func (h *Handler) ClearBasket(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, basket)
+	respond.OK(c, http.StatusOK, basket)
 }
 
 // DeleteBasket handles DELETE /baskets/:user_id
 func (h *Handler) DeleteBasket(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -215,19 +212,43 @@ func (h *Handler) DeleteBasket(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse{
+	respond.OK(c, http.StatusOK, dto.SuccessResponse{
 		Message: "Basket deleted successfully",
 	})
 }
 
+// GetBasketsBatch handles POST /baskets/batch, returning the baskets of up to the configured
+// number of users keyed by user ID. Users without a basket are returned as null.
+func (h *Handler) GetBasketsBatch(c *gin.Context) {
+	var q query.GetBasketsQuery
+	if err := c.ShouldBindJSON(&q); err != nil {
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
+		return
+	}
+
+	baskets, err := h.queryHandler.HandleGetBaskets(q)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	response := dto.BatchBasketsResponse{Baskets: baskets}
+	for _, basket := range baskets {
+		if basket != nil {
+			response.Found++
+		} else {
+			response.Missing++
+		}
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
 // GetBasketItems handles GET /baskets/:user_id/items
 func (h *Handler) GetBasketItems(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -237,17 +258,14 @@ func (h *Handler) GetBasketItems(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	respond.OK(c, http.StatusOK, items)
 }
 
 // GetBasketTotal handles GET /baskets/:user_id/total
 func (h *Handler) GetBasketTotal(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -257,17 +275,14 @@ func (h *Handler) GetBasketTotal(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, total)
+	respond.OK(c, http.StatusOK, total)
 }
 
 // GetBasketItemCount handles GET /baskets/:user_id/count
 func (h *Handler) GetBasketItemCount(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -277,7 +292,7 @@ func (h *Handler) GetBasketItemCount(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, count)
+	respond.OK(c, http.StatusOK, count)
 }
 
 // GetBasketByCategory handles GET /baskets/:user_id/category/:category
@@ -286,18 +301,12 @@ func (h *Handler) GetBasketByCategory(c *gin.Context) {
 	category := c.Param("category")
 	
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	if category == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid category",
-			Message: "Category is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Category is required")
 		return
 	}
 
@@ -310,17 +319,14 @@ func (h *Handler) GetBasketByCategory(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	respond.OK(c, http.StatusOK, items)
 }
 
 // GetBasketStats handles GET /baskets/:user_id/stats
 func (h *Handler) GetBasketStats(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -330,17 +336,14 @@ func (h *Handler) GetBasketStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	respond.OK(c, http.StatusOK, stats)
 }
 
 // GetBasketExpiry handles GET /baskets/:user_id/expiry
 func (h *Handler) GetBasketExpiry(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -350,17 +353,14 @@ func (h *Handler) GetBasketExpiry(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, expiry)
+	respond.OK(c, http.StatusOK, expiry)
 }
 
 // GetBasketHistory handles GET /baskets/:user_id/history
 func (h *Handler) GetBasketHistory(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -370,17 +370,14 @@ func (h *Handler) GetBasketHistory(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, history)
+	respond.OK(c, http.StatusOK, history)
 }
 
 // GetBasketRecommendations handles GET /baskets/:user_id/recommendations
 func (h *Handler) GetBasketRecommendations(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -390,22 +387,27 @@ func (h *Handler) GetBasketRecommendations(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, recommendations)
+	respond.OK(c, http.StatusOK, recommendations)
 }
 
-// HealthCheck handles GET /health
+// HealthCheck handles GET /health, reporting Redis and the product service
 func (h *Handler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, dto.HealthResponse{
-		Service:   "basket-service",
-		Status:    "healthy",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Version:   "1.0.0",
-	})
+	h.health.ServeHealth(c)
+}
+
+// ReadinessCheck handles GET /ready, reporting whether Redis is reachable
+func (h *Handler) ReadinessCheck(c *gin.Context) {
+	h.health.ServeReady(c)
+}
+
+// LivenessCheck handles GET /live, reporting only that the process is running
+func (h *Handler) LivenessCheck(c *gin.Context) {
+	h.health.ServeLive(c)
 }
 
 // SetupRoutes sets up all routes
-func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler) {
-	handler := NewHandler(commandHandler, queryHandler)
+func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler, checker *health.Checker, adminToken string) {
+	handler := NewHandler(commandHandler, queryHandler, checker)
 
 	// Basket routes
 	r.GET("/baskets/:user_id", handler.GetBasket)
@@ -414,6 +416,7 @@ func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHan
 	r.PUT("/baskets/:user_id/items/:product_id", handler.UpdateItem)
 	r.DELETE("/baskets/:user_id/items/:product_id", handler.RemoveItem)
 	r.DELETE("/baskets/:user_id/items", handler.ClearBasket)
+	r.POST("/baskets/:user_id/refresh", handler.RefreshBasket)
 	r.DELETE("/baskets/:user_id", handler.DeleteBasket)
 
 	// Query routes
@@ -426,6 +429,20 @@ func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHan
 	r.GET("/baskets/:user_id/history", handler.GetBasketHistory)
 	r.GET("/baskets/:user_id/recommendations", handler.GetBasketRecommendations)
 
-	// Health check
+	// Admin routes
+	r.POST("/baskets/batch", middleware.AdminAuth(adminToken), handler.GetBasketsBatch)
+
+	// Health checks
 	r.GET("/health", handler.HealthCheck)
+	r.GET("/ready", handler.ReadinessCheck)
+	r.GET("/live", handler.LivenessCheck)
+}
+
+// parseBoolQuery parses an optional boolean query parameter, defaulting to false
+func parseBoolQuery(c *gin.Context, key string) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
 }
@@ -7,15 +7,19 @@ import (
 
 // CreateNotificationCommand represents a command to create a notification
 type CreateNotificationCommand struct {
-	UserID     string            `json:"user_id" binding:"required"`
-	Title      string            `json:"title" binding:"required"`
-	Message    string            `json:"message" binding:"required"`
-	Type       entity.NotificationType `json:"type" binding:"required"`
-	Priority   entity.NotificationPriority `json:"priority"`
-	Channel    entity.NotificationChannel `json:"channel" binding:"required"`
-	TemplateID string            `json:"template_id"`
-	Data       map[string]string `json:"data"`
-	ExpiresAt  *time.Time        `json:"expires_at"`
+	UserID      string                      `json:"user_id" binding:"required"`
+	Title       string                      `json:"title" binding:"required"`
+	Message     string                      `json:"message" binding:"required"`
+	Type        entity.NotificationType     `json:"type" binding:"required"`
+	Priority    entity.NotificationPriority `json:"priority"`
+	Channel     entity.NotificationChannel  `json:"channel" binding:"required"`
+	TemplateID  string                      `json:"template_id"`
+	Data        map[string]string           `json:"data"`
+	ExpiresAt   *time.Time                  `json:"expires_at"`
+	Attachments []entity.Attachment         `json:"attachments"`
+
+	// SourceEventID makes creation idempotent per event, see NotificationUseCase.CreateNotification
+	SourceEventID string `json:"source_event_id"`
 }
 
 // ToDTO converts CreateNotificationCommand to CreateNotificationRequest
@@ -30,6 +34,7 @@ func (c CreateNotificationCommand) ToDTO() entity.CreateNotificationRequest {
 		TemplateID: c.TemplateID,
 		Data:       c.Data,
 		ExpiresAt:  c.ExpiresAt,
+		SourceEventID: c.SourceEventID,
 	}
 }
 
@@ -56,6 +61,13 @@ type MarkAllAsReadCommand struct {
 	UserID string `json:"user_id" binding:"required"`
 }
 
+// BatchUpdateCommand represents a command to apply an action to several notifications of a user
+type BatchUpdateCommand struct {
+	UserID string   `json:"user_id" binding:"required"`
+	IDs    []string `json:"ids" binding:"required"`
+	Action string   `json:"action" binding:"required"`
+}
+
 // DeleteNotificationCommand represents a command to delete a notification
 type DeleteNotificationCommand struct {
 	ID string `json:"id" binding:"required"`
@@ -97,3 +109,22 @@ type RetryFailedNotificationCommand struct {
 type CleanupExpiredNotificationsCommand struct {
 	// No fields needed - cleanup all expired notifications
 }
+
+// UpdatePreferenceCommand represents a command to update a user's notification preferences
+type UpdatePreferenceCommand struct {
+	UserID          string                    `json:"user_id" binding:"required"`
+	EmailEnabled    *bool                     `json:"email_enabled"`
+	SMSEnabled      *bool                     `json:"sms_enabled"`
+	PushEnabled     *bool                     `json:"push_enabled"`
+	WebhookEnabled  *bool                     `json:"webhook_enabled"`
+	MarketingOptIn  *bool                     `json:"marketing_opt_in"`
+	MutedTypes      []entity.NotificationType `json:"muted_types"`
+	QuietHoursStart *string                   `json:"quiet_hours_start"`
+	QuietHoursEnd   *string                   `json:"quiet_hours_end"`
+	Timezone        *string                   `json:"timezone"`
+}
+
+// DeletePreferenceCommand represents a command to reset a user's notification preferences
+type DeletePreferenceCommand struct {
+	UserID string `json:"user_id" binding:"required"`
+}
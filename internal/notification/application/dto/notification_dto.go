@@ -2,6 +2,7 @@ package dto
 
 import (
 	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/pkg/pagination"
 	"time"
 )
 
@@ -10,6 +11,7 @@ type NotificationResponse struct {
 	Success      bool                    `json:"success"`
 	Message      string                  `json:"message"`
 	Notification *entity.Notification    `json:"notification,omitempty"`
+	AffectedIDs  []string                `json:"affected_ids,omitempty"`
 }
 
 // NotificationListResponse represents the response for notification list operations
@@ -19,6 +21,17 @@ type NotificationListResponse struct {
 	Notifications []*entity.Notification  `json:"notifications"`
 	Total         int64                   `json:"total"`
 	UnreadCount   int64                   `json:"unread_count"`
+	Pagination    *pagination.Page        `json:"pagination,omitempty"`
+}
+
+// AdminNotificationListResponse represents a page of notifications across all users
+type AdminNotificationListResponse struct {
+	Success       bool                   `json:"success"`
+	Message       string                 `json:"message"`
+	Notifications []*entity.Notification `json:"notifications"`
+	Total         int64                  `json:"total"`
+	StatusCounts  map[string]int64       `json:"status_counts"` // Matching notifications per status, ignoring the status filter
+	Pagination    *pagination.Page       `json:"pagination,omitempty"`
 }
 
 // NotificationStatsResponse represents the response for notification statistics
@@ -30,15 +43,20 @@ type NotificationStatsResponse struct {
 
 // CreateNotificationRequest represents the request to create a notification
 type CreateNotificationRequest struct {
-	UserID     string                        `json:"user_id" binding:"required"`
-	Title      string                        `json:"title" binding:"required"`
-	Message    string                        `json:"message" binding:"required"`
-	Type       entity.NotificationType       `json:"type" binding:"required"`
-	Priority   entity.NotificationPriority   `json:"priority"`
-	Channel    entity.NotificationChannel    `json:"channel" binding:"required"`
-	TemplateID string                        `json:"template_id"`
-	Data       map[string]string             `json:"data"`
-	ExpiresAt  *time.Time                    `json:"expires_at"`
+	UserID      string                      `json:"user_id" binding:"required"`
+	Title       string                      `json:"title" binding:"required"`
+	Message     string                      `json:"message" binding:"required"`
+	Type        entity.NotificationType     `json:"type" binding:"required,notification_type"`
+	Priority    entity.NotificationPriority `json:"priority" binding:"omitempty,notification_priority"`
+	Channel     entity.NotificationChannel  `json:"channel" binding:"required,notification_channel"`
+	TemplateID  string                      `json:"template_id"`
+	Data        map[string]string           `json:"data"`
+	ExpiresAt   *time.Time                  `json:"expires_at" binding:"omitempty,future"`
+	Attachments []entity.Attachment         `json:"attachments"` // Validated against the domain's URL and size limits
+
+	// SourceEventID is the ID of the event the notification is created for; a notification
+	// already created for it is returned instead of creating another
+	SourceEventID string `json:"source_event_id" binding:"omitempty,max=64"`
 }
 
 // UpdateNotificationRequest represents the request to update a notification
@@ -63,6 +81,39 @@ type MarkAllAsReadRequest struct {
 	UserID string `json:"user_id" binding:"required"`
 }
 
+// Batch actions accepted by BatchUpdateRequest
+const (
+	BatchActionRead   = "read"
+	BatchActionDelete = "delete"
+)
+
+// MaxBatchSize is the maximum number of notification IDs accepted in one batch request
+const MaxBatchSize = 500
+
+// BatchUpdateRequest represents the request to apply an action to several notifications of a user
+type BatchUpdateRequest struct {
+	UserID string   `json:"user_id" binding:"required"`
+	IDs    []string `json:"ids" binding:"required,min=1,max=500,dive,required"`
+	Action string   `json:"action" binding:"required,oneof=read delete"`
+}
+
+// BatchItemResult represents the outcome of a batch action for a single notification
+type BatchItemResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BatchUpdateResponse represents the response for batch notification operations
+type BatchUpdateResponse struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message"`
+	Action    string            `json:"action"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
 // GetNotificationsRequest represents the request to get notifications
 type GetNotificationsRequest struct {
 	UserID string `json:"user_id" binding:"required"`
@@ -82,12 +133,12 @@ type BulkCreateNotificationRequest struct {
 	UserIDs    []string                      `json:"user_ids" binding:"required"`
 	Title      string                        `json:"title" binding:"required"`
 	Message    string                        `json:"message" binding:"required"`
-	Type       entity.NotificationType       `json:"type" binding:"required"`
-	Priority   entity.NotificationPriority   `json:"priority"`
-	Channel    entity.NotificationChannel    `json:"channel" binding:"required"`
+	Type       entity.NotificationType       `json:"type" binding:"required,notification_type"`
+	Priority   entity.NotificationPriority   `json:"priority" binding:"omitempty,notification_priority"`
+	Channel    entity.NotificationChannel    `json:"channel" binding:"required,notification_channel"`
 	TemplateID string                        `json:"template_id"`
 	Data       map[string]string             `json:"data"`
-	ExpiresAt  *time.Time                    `json:"expires_at"`
+	ExpiresAt  *time.Time                    `json:"expires_at" binding:"omitempty,future"`
 }
 
 // ScheduleNotificationRequest represents the request to schedule a notification
@@ -95,11 +146,32 @@ type ScheduleNotificationRequest struct {
 	UserID     string                        `json:"user_id" binding:"required"`
 	Title      string                        `json:"title" binding:"required"`
 	Message    string                        `json:"message" binding:"required"`
-	Type       entity.NotificationType       `json:"type" binding:"required"`
-	Priority   entity.NotificationPriority   `json:"priority"`
-	Channel    entity.NotificationChannel    `json:"channel" binding:"required"`
+	Type       entity.NotificationType       `json:"type" binding:"required,notification_type"`
+	Priority   entity.NotificationPriority   `json:"priority" binding:"omitempty,notification_priority"`
+	Channel    entity.NotificationChannel    `json:"channel" binding:"required,notification_channel"`
 	TemplateID string                        `json:"template_id"`
 	Data       map[string]string             `json:"data"`
 	SendAt     time.Time                     `json:"send_at" binding:"required"`
-	ExpiresAt  *time.Time                    `json:"expires_at"`
+	ExpiresAt  *time.Time                    `json:"expires_at" binding:"omitempty,future"`
+}
+
+// UpdatePreferenceRequest represents the request to update notification preferences.
+// Omitted fields keep their current value.
+type UpdatePreferenceRequest struct {
+	EmailEnabled    *bool                     `json:"email_enabled"`
+	SMSEnabled      *bool                     `json:"sms_enabled"`
+	PushEnabled     *bool                     `json:"push_enabled"`
+	WebhookEnabled  *bool                     `json:"webhook_enabled"`
+	MarketingOptIn  *bool                     `json:"marketing_opt_in"`
+	MutedTypes      []entity.NotificationType `json:"muted_types"`
+	QuietHoursStart *string                   `json:"quiet_hours_start"`
+	QuietHoursEnd   *string                   `json:"quiet_hours_end"`
+	Timezone        *string                   `json:"timezone"`
+}
+
+// PreferenceResponse represents the response for notification preference operations
+type PreferenceResponse struct {
+	Success    bool                           `json:"success"`
+	Message    string                         `json:"message"`
+	Preference *entity.NotificationPreference `json:"preference,omitempty"`
 }
@@ -30,6 +30,8 @@ func (h *CommandHandler) HandleCreateNotification(cmd command.CreateNotification
 		cmd.TemplateID,
 		cmd.Data,
 		cmd.ExpiresAt,
+		cmd.SourceEventID,
+		cmd.Attachments,
 	)
 }
 
@@ -53,6 +55,11 @@ func (h *CommandHandler) HandleMarkAsRead(cmd command.MarkAsReadCommand) (*dto.N
 	return h.notificationUseCase.MarkAsRead(cmd.ID)
 }
 
+// HandleBatchUpdate handles BatchUpdateCommand
+func (h *CommandHandler) HandleBatchUpdate(cmd command.BatchUpdateCommand) (*dto.BatchUpdateResponse, error) {
+	return h.notificationUseCase.BatchUpdate(cmd.UserID, cmd.IDs, cmd.Action)
+}
+
 // HandleMarkAllAsRead handles MarkAllAsReadCommand
 func (h *CommandHandler) HandleMarkAllAsRead(cmd command.MarkAllAsReadCommand) (*dto.NotificationResponse, error) {
 	return h.notificationUseCase.MarkAllAsRead(cmd.UserID)
@@ -103,3 +110,23 @@ func (h *CommandHandler) HandleRetryFailedNotification(cmd command.RetryFailedNo
 func (h *CommandHandler) HandleCleanupExpiredNotifications(cmd command.CleanupExpiredNotificationsCommand) (*dto.NotificationResponse, error) {
 	return h.notificationUseCase.CleanupExpiredNotifications()
 }
+
+// HandleUpdatePreference handles UpdatePreferenceCommand
+func (h *CommandHandler) HandleUpdatePreference(cmd command.UpdatePreferenceCommand) (*dto.PreferenceResponse, error) {
+	return h.notificationUseCase.UpdatePreferences(cmd.UserID, dto.UpdatePreferenceRequest{
+		EmailEnabled:    cmd.EmailEnabled,
+		SMSEnabled:      cmd.SMSEnabled,
+		PushEnabled:     cmd.PushEnabled,
+		WebhookEnabled:  cmd.WebhookEnabled,
+		MarketingOptIn:  cmd.MarketingOptIn,
+		MutedTypes:      cmd.MutedTypes,
+		QuietHoursStart: cmd.QuietHoursStart,
+		QuietHoursEnd:   cmd.QuietHoursEnd,
+		Timezone:        cmd.Timezone,
+	})
+}
+
+// HandleDeletePreference handles DeletePreferenceCommand
+func (h *CommandHandler) HandleDeletePreference(cmd command.DeletePreferenceCommand) (*dto.PreferenceResponse, error) {
+	return h.notificationUseCase.DeletePreferences(cmd.UserID)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/internal/notification/application/usecase"
+)
+
+// DigestWorker periodically delivers the notifications pending a digest once their digest window
+// has elapsed. Pending notifications are stored, so digests left pending by a restart are delivered too.
+type DigestWorker struct {
+	notificationUseCase *usecase.NotificationUseCase
+	interval            time.Duration
+	logger              *logrus.Logger
+}
+
+// NewDigestWorker creates a new notification digest worker
+func NewDigestWorker(notificationUseCase *usecase.NotificationUseCase, interval time.Duration, logger *logrus.Logger) *DigestWorker {
+	return &DigestWorker{
+		notificationUseCase: notificationUseCase,
+		interval:            interval,
+		logger:              logger,
+	}
+}
+
+// Start flushes due digests every interval until ctx is cancelled
+func (w *DigestWorker) Start(ctx context.Context) {
+	w.logger.WithField("interval", w.interval.String()).Info("Notification digest worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Notification digest worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := w.notificationUseCase.FlushDueDigests(ctx); err != nil {
+				w.logger.WithError(err).Warn("Failed to flush digests")
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"obs-tools-usage/internal/notification/application/command"
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/kafka/consumer"
+)
+
+// EventNotifier creates the notifications for consumed Kafka events through the command handler
+type EventNotifier struct {
+	commandHandler *CommandHandler
+}
+
+// NewEventNotifier creates a new event notifier
+func NewEventNotifier(commandHandler *CommandHandler) *EventNotifier {
+	return &EventNotifier{
+		commandHandler: commandHandler,
+	}
+}
+
+var _ consumer.NotificationCreator = (*EventNotifier)(nil)
+
+// CreateEventNotification creates the notification for an event. The event ID is passed on as the
+// source event ID, so a redelivered event returns the notification created the first time.
+func (n *EventNotifier) CreateEventNotification(ctx context.Context, notification consumer.EventNotification) error {
+	data := make(map[string]string, len(notification.Data))
+	for key, value := range notification.Data {
+		data[key] = fmt.Sprint(value)
+	}
+
+	_, err := n.commandHandler.HandleCreateNotification(command.CreateNotificationCommand{
+		UserID:        notification.UserID,
+		Title:         notification.Title,
+		Message:       notification.Message,
+		Type:          entity.NotificationType(notification.Type),
+		Priority:      entity.NotificationPriority(notification.Priority),
+		Channel:       entity.NotificationChannel(notification.Channel),
+		Data:          data,
+		SourceEventID: notification.SourceEventID,
+	})
+	return err
+}
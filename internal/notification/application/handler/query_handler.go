@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"obs-tools-usage/internal/notification/application/dto"
 	"obs-tools-usage/internal/notification/application/query"
 	"obs-tools-usage/internal/notification/application/usecase"
 )
@@ -22,14 +23,21 @@ func (h *QueryHandler) HandleGetNotification(q query.GetNotificationQuery) (*dto
 	return h.notificationUseCase.GetNotification(q.ID)
 }
 
+// HandleListNotifications handles ListNotificationsQuery
+func (h *QueryHandler) HandleListNotifications(q query.ListNotificationsQuery) (*dto.AdminNotificationListResponse, error) {
+	return h.notificationUseCase.ListNotifications(q.Status, q.Type, q.Channel, q.Limit, q.Offset, q.Sort)
+}
+
 // HandleGetNotificationsByUser handles GetNotificationsByUserQuery
 func (h *QueryHandler) HandleGetNotificationsByUser(q query.GetNotificationsByUserQuery) (*dto.NotificationListResponse, error) {
 	return h.notificationUseCase.GetNotificationsByUser(
 		q.UserID,
-		q.Limit,
-		q.Offset,
 		q.Status,
 		q.Type,
+		q.Read,
+		q.Limit,
+		q.Offset,
+		q.Sort,
 	)
 }
 
@@ -39,6 +47,7 @@ func (h *QueryHandler) HandleGetUnreadNotifications(q query.GetUnreadNotificatio
 		q.UserID,
 		q.Limit,
 		q.Offset,
+		q.Sort,
 	)
 }
 
@@ -111,3 +120,8 @@ func (h *QueryHandler) HandleGetRecentNotifications(q query.GetRecentNotificatio
 		q.Offset,
 	)
 }
+
+// HandleGetPreference handles GetPreferenceQuery
+func (h *QueryHandler) HandleGetPreference(q query.GetPreferenceQuery) (*dto.PreferenceResponse, error) {
+	return h.notificationUseCase.GetPreferences(q.UserID)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/internal/notification/application/usecase"
+)
+
+// RetryWorker periodically retries failed notifications whose scheduled retry is due.
+// Each notification is retried with an exponential backoff until its retries are used up,
+// after which it is marked permanently failed.
+type RetryWorker struct {
+	notificationUseCase *usecase.NotificationUseCase
+	interval            time.Duration
+	batchSize           int
+	logger              *logrus.Logger
+}
+
+// NewRetryWorker creates a new notification retry worker
+func NewRetryWorker(notificationUseCase *usecase.NotificationUseCase, interval time.Duration, batchSize int, logger *logrus.Logger) *RetryWorker {
+	return &RetryWorker{
+		notificationUseCase: notificationUseCase,
+		interval:            interval,
+		batchSize:           batchSize,
+		logger:              logger,
+	}
+}
+
+// Start retries due notifications every interval until ctx is cancelled
+func (w *RetryWorker) Start(ctx context.Context) {
+	w.logger.WithField("interval", w.interval.String()).Info("Notification retry worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Notification retry worker stopped")
+			return
+		case <-ticker.C:
+			w.retryDue(ctx)
+		}
+	}
+}
+
+// retryDue retries due notifications batch by batch until none are left
+func (w *RetryWorker) retryDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		retried, err := w.notificationUseCase.RetryDueNotifications(ctx, w.batchSize)
+		if err != nil {
+			w.logger.WithError(err).Warn("Failed to retry notifications")
+			return
+		}
+		if retried == 0 || retried < w.batchSize {
+			return
+		}
+	}
+}
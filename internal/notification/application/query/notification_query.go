@@ -16,6 +16,18 @@ type GetNotificationsByUserQuery struct {
 	Offset int    `json:"offset"`
 	Status string `json:"status"`
 	Type   string `json:"type"`
+	Read   *bool  `json:"read"` // nil lists both read and unread notifications
+	Sort   string `json:"sort"` // Field to order by, prefixed with "-" for descending; defaults to -created_at
+}
+
+// ListNotificationsQuery represents a query to list notifications across all users
+type ListNotificationsQuery struct {
+	Status  string `json:"status"`
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+	Sort    string `json:"sort"`
 }
 
 // GetUnreadNotificationsQuery represents a query to get unread notifications for a user
@@ -23,6 +35,7 @@ type GetUnreadNotificationsQuery struct {
 	UserID string `json:"user_id" binding:"required"`
 	Limit  int    `json:"limit"`
 	Offset int    `json:"offset"`
+	Sort   string `json:"sort"`
 }
 
 // GetNotificationStatsQuery represents a query to get notification statistics
@@ -82,3 +95,8 @@ type GetRecentNotificationsQuery struct {
 	Limit  int    `json:"limit"`
 	Offset int    `json:"offset"`
 }
+
+// GetPreferenceQuery represents a query to get a user's notification preferences
+type GetPreferenceQuery struct {
+	UserID string `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// maxDigestLines caps how many buffered titles are listed in a digest message
+const maxDigestLines = 10
+
+// DigestConfig controls how notifications are coalesced into digests
+type DigestConfig struct {
+	Window time.Duration             // How long notifications are buffered before one digest is sent; 0 disables digests
+	Types  []entity.NotificationType // Notification types that are coalesced; others are sent individually
+}
+
+// digestKey identifies the notifications coalesced into a single digest
+type digestKey struct {
+	userID           string
+	notificationType entity.NotificationType
+	channel          entity.NotificationChannel
+}
+
+// notificationDigester decides which notifications are held back for a digest and when the
+// notifications pending a digest are due. Pending notifications are stored, so digests survive
+// restarts and are coalesced the same whichever replica created them.
+type notificationDigester struct {
+	window time.Duration
+	types  map[entity.NotificationType]bool
+}
+
+// newNotificationDigester creates a digester for the given configuration
+func newNotificationDigester(cfg DigestConfig) *notificationDigester {
+	types := make(map[entity.NotificationType]bool, len(cfg.Types))
+	for _, notificationType := range cfg.Types {
+		types[notificationType] = true
+	}
+
+	return &notificationDigester{
+		window: cfg.Window,
+		types:  types,
+	}
+}
+
+// accepts reports whether a notification should be held back for a digest.
+// Urgent notifications are never delayed.
+func (d *notificationDigester) accepts(notification *entity.Notification) bool {
+	return d.window > 0 &&
+		d.types[notification.Type] &&
+		notification.Priority != entity.NotificationPriorityUrgent
+}
+
+// dueDigests groups notifications pending a digest, oldest first, per user, type and channel and
+// returns the groups whose window, started by their first notification, has elapsed at now
+func (d *notificationDigester) dueDigests(pending []*entity.Notification, now time.Time) [][]*entity.Notification {
+	var keys []digestKey
+	groups := make(map[digestKey][]*entity.Notification)
+	for _, notification := range pending {
+		key := digestKey{
+			userID:           notification.UserID,
+			notificationType: notification.Type,
+			channel:          notification.Channel,
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], notification)
+	}
+
+	var due [][]*entity.Notification
+	for _, key := range keys {
+		if !groups[key][0].CreatedAt.Add(d.window).After(now) {
+			due = append(due, groups[key])
+		}
+	}
+	return due
+}
+
+// buildDigest coalesces buffered notifications of one user, type and channel into a
+// single summary notification. A buffer holding one notification is returned as is.
+func buildDigest(notifications []*entity.Notification) *entity.Notification {
+	if len(notifications) == 1 {
+		return notifications[0]
+	}
+
+	first := notifications[0]
+	count := len(notifications)
+
+	priority := first.Priority
+	var expiresAt *time.Time
+	lines := make([]string, 0, maxDigestLines+1)
+	for i, notification := range notifications {
+		if priorityRank[notification.Priority] > priorityRank[priority] {
+			priority = notification.Priority
+		}
+		// The digest expires with the last of its notifications, or never if one never does
+		if i == 0 || (expiresAt != nil && (notification.ExpiresAt == nil || notification.ExpiresAt.After(*expiresAt))) {
+			expiresAt = notification.ExpiresAt
+		}
+		if i < maxDigestLines {
+			lines = append(lines, "- "+notification.Title)
+		}
+	}
+	if count > maxDigestLines {
+		lines = append(lines, fmt.Sprintf("...and %d more", count-maxDigestLines))
+	}
+
+	now := time.Now()
+	return &entity.Notification{
+		ID:       uuid.New().String(),
+		UserID:   first.UserID,
+		Title:    fmt.Sprintf("You have %d new %s notifications", count, first.Type),
+		Message:  strings.Join(lines, "\n"),
+		Type:     first.Type,
+		Priority: priority,
+		Channel:  first.Channel,
+		Data: map[string]string{
+			"digest":       "true",
+			"digest_count": strconv.Itoa(count),
+			"digest_from":  first.CreatedAt.Format(time.RFC3339),
+			"digest_until": notifications[count-1].CreatedAt.Format(time.RFC3339),
+		},
+		Status:    entity.NotificationStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// priorityRank orders priorities so a digest keeps the highest of its notifications
+var priorityRank = map[entity.NotificationPriority]int{
+	entity.NotificationPriorityLow:    0,
+	entity.NotificationPriorityNormal: 1,
+	entity.NotificationPriorityHigh:   2,
+	entity.NotificationPriorityUrgent: 3,
+}
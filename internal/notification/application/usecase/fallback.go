@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// defaultFallbackChain is the key of the chain used for notification types without their own
+const defaultFallbackChain entity.NotificationType = "*"
+
+// FallbackConfig controls which channels are tried when delivery through a notification's channel fails
+type FallbackConfig struct {
+	Chains      map[entity.NotificationType][]entity.NotificationChannel // Channels tried in order per type; "*" applies to other types
+	MaxAttempts int                                                      // Most channels tried per delivery, including the notification's own; 1 disables fallback
+}
+
+// deliveryChannels returns the channels to try for a notification, in order: its own channel, then
+// the channels that follow it in the fallback chain of its type, or the whole chain when its channel
+// is not part of it. Channels the user disabled are skipped and at most MaxAttempts are returned.
+func (u *NotificationUseCase) deliveryChannels(ctx context.Context, notification *entity.Notification) []entity.NotificationChannel {
+	channels := []entity.NotificationChannel{notification.Channel}
+	if u.fallback.MaxAttempts <= 1 {
+		return channels
+	}
+
+	chain, ok := u.fallback.Chains[notification.Type]
+	if !ok {
+		chain = u.fallback.Chains[defaultFallbackChain]
+	}
+	for i, channel := range chain {
+		if channel == notification.Channel {
+			chain = chain[i+1:]
+			break
+		}
+	}
+	if len(chain) == 0 {
+		return channels
+	}
+
+	pref, err := u.preferenceRepo.GetByUserID(ctx, notification.UserID)
+	if err != nil {
+		pref = entity.NewDefaultNotificationPreference(notification.UserID)
+	}
+
+	for _, channel := range chain {
+		if len(channels) >= u.fallback.MaxAttempts {
+			break
+		}
+		if channel == notification.Channel || !pref.IsChannelEnabled(channel) {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	return channels
+}
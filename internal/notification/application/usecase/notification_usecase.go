@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,28 +12,50 @@ import (
 	"obs-tools-usage/internal/notification/domain/entity"
 	"obs-tools-usage/internal/notification/domain/repository"
 	"obs-tools-usage/internal/notification/domain/service"
+	"obs-tools-usage/internal/notification/infrastructure/metrics"
 )
 
 // NotificationUseCase handles notification business logic
 type NotificationUseCase struct {
 	notificationRepo     repository.NotificationRepository
+	preferenceRepo       repository.PreferenceRepository
 	domainService        *service.NotificationDomainService
+	senders              service.ChannelSenders
+	digester             *notificationDigester
+	fallback             FallbackConfig
+	rateLimiter          NotificationRateLimiter
+	retry                RetryConfig
 	logger               *logrus.Logger
 }
 
 // NewNotificationUseCase creates a new notification use case
 func NewNotificationUseCase(
 	notificationRepo repository.NotificationRepository,
+	preferenceRepo repository.PreferenceRepository,
+	senders service.ChannelSenders,
+	digestConfig DigestConfig,
+	fallbackConfig FallbackConfig,
+	rateLimiter NotificationRateLimiter,
+	retryConfig RetryConfig,
 	logger *logrus.Logger,
 ) *NotificationUseCase {
-	return &NotificationUseCase{
+	u := &NotificationUseCase{
 		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
 		domainService:    service.NewNotificationDomainService(),
+		senders:          senders,
+		fallback:         fallbackConfig,
+		rateLimiter:      rateLimiter,
+		retry:            retryConfig,
 		logger:           logger,
 	}
+	u.digester = newNotificationDigester(digestConfig)
+	return u
 }
 
-// CreateNotification creates a new notification
+// CreateNotification creates a new notification. A non-empty sourceEventID, set when the notification
+// is created for an event, makes creation idempotent: when a notification already exists for that event
+// it is returned instead of creating another, so redelivered events are notified once.
 func (u *NotificationUseCase) CreateNotification(
 	userID, title, message string,
 	notificationType entity.NotificationType,
@@ -41,7 +64,16 @@ func (u *NotificationUseCase) CreateNotification(
 	templateID string,
 	data map[string]string,
 	expiresAt *time.Time,
+	sourceEventID string,
+	attachments []entity.Attachment,
 ) (*dto.NotificationResponse, error) {
+	ctx := context.Background()
+	if sourceEventID != "" {
+		if existing, ok := u.existingEventNotification(ctx, sourceEventID); ok {
+			return existing, nil
+		}
+	}
+
 	// Set default priority if not provided
 	if priority == "" {
 		priority = u.domainService.GetDefaultPriority(notificationType)
@@ -49,19 +81,21 @@ func (u *NotificationUseCase) CreateNotification(
 
 	// Create notification entity
 	notification := &entity.Notification{
-		ID:         uuid.New().String(),
-		UserID:     userID,
-		Title:      title,
-		Message:    message,
-		Type:       notificationType,
-		Priority:   priority,
-		Channel:    channel,
-		TemplateID: templateID,
-		Data:       data,
-		Status:     entity.NotificationStatusPending,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-		ExpiresAt:  expiresAt,
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		Title:         title,
+		Message:       message,
+		Type:          notificationType,
+		Priority:      priority,
+		Channel:       channel,
+		TemplateID:    templateID,
+		Data:          data,
+		Status:        entity.NotificationStatusPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		ExpiresAt:     expiresAt,
+		SourceEventID: sourceEventID,
+		Attachments:   attachments,
 	}
 
 	// Validate notification
@@ -72,9 +106,26 @@ func (u *NotificationUseCase) CreateNotification(
 		}, err
 	}
 
+	// Apply user preferences before storing so the persisted channel matches delivery,
+	// then the per-user rate limit; dropped notifications are stored as suppressed with the reason
+	suppressed := !u.applyPreferences(ctx, notification)
+	throttled := !suppressed && !u.allowByRateLimit(notification)
+
+	// Digestible notifications are stored pending a digest and delivered, in-app included,
+	// as a single summary once the digest window elapses, see FlushDueDigests
+	digested := !suppressed && !throttled && u.digester.accepts(notification)
+	if digested {
+		notification.Status = entity.NotificationStatusDigestPending
+	}
+
 	// Save to database
-	ctx := context.Background()
 	if err := u.notificationRepo.Create(ctx, notification); err != nil {
+		// A concurrent delivery of the same event may have stored it first
+		if sourceEventID != "" {
+			if existing, ok := u.existingEventNotification(ctx, sourceEventID); ok {
+				return existing, nil
+			}
+		}
 		u.logger.WithError(err).Error("Failed to create notification")
 		return &dto.NotificationResponse{
 			Success: false,
@@ -82,6 +133,35 @@ func (u *NotificationUseCase) CreateNotification(
 		}, err
 	}
 
+	if suppressed {
+		return &dto.NotificationResponse{
+			Success:      true,
+			Message:      "Notification suppressed by user preferences",
+			Notification: notification,
+		}, nil
+	}
+	if throttled {
+		return &dto.NotificationResponse{
+			Success:      true,
+			Message:      "Notification dropped by rate limit",
+			Notification: notification,
+		}, nil
+	}
+	if digested {
+		u.logger.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"user_id":         userID,
+			"type":            notificationType,
+			"channel":         notification.Channel,
+		}).Debug("Notification queued for digest")
+
+		return &dto.NotificationResponse{
+			Success:      true,
+			Message:      "Notification queued for digest",
+			Notification: notification,
+		}, nil
+	}
+
 	// Send notification if should be sent immediately
 	if u.domainService.ShouldSendImmediately(*notification) {
 		go u.sendNotification(notification)
@@ -114,7 +194,7 @@ func (u *NotificationUseCase) UpdateNotification(
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Notification not found",
+			Message: notificationErrorMessage(err, "Failed to get notification"),
 		}, err
 	}
 
@@ -146,6 +226,28 @@ func (u *NotificationUseCase) UpdateNotification(
 	}, nil
 }
 
+// existingEventNotification returns the response for a notification already created for an event, if any
+func (u *NotificationUseCase) existingEventNotification(ctx context.Context, sourceEventID string) (*dto.NotificationResponse, bool) {
+	notification, err := u.notificationRepo.GetBySourceEventID(ctx, sourceEventID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotificationNotFound) {
+			u.logger.WithError(err).WithField("source_event_id", sourceEventID).Warn("Failed to look up notification for event")
+		}
+		return nil, false
+	}
+
+	u.logger.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"source_event_id": sourceEventID,
+	}).Info("Notification already created for event, skipping duplicate")
+
+	return &dto.NotificationResponse{
+		Success:      true,
+		Message:      "Notification already created for event",
+		Notification: notification,
+	}, true
+}
+
 // SendNotification sends a notification
 func (u *NotificationUseCase) SendNotification(id string) (*dto.NotificationResponse, error) {
 	ctx := context.Background()
@@ -155,26 +257,19 @@ func (u *NotificationUseCase) SendNotification(id string) (*dto.NotificationResp
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Notification not found",
+			Message: notificationErrorMessage(err, "Failed to get notification"),
 		}, err
 	}
 
-	// Send notification
+	// Send notification; the delivery outcome is persisted by sendNotification
 	if err := u.sendNotification(notification); err != nil {
-		// Mark as failed
-		notification.MarkAsFailed()
-		u.notificationRepo.Update(ctx, notification)
-
 		return &dto.NotificationResponse{
-			Success: false,
-			Message: "Failed to send notification",
+			Success:      false,
+			Message:      "Failed to send notification",
+			Notification: notification,
 		}, err
 	}
 
-	// Mark as sent
-	notification.MarkAsSent()
-	u.notificationRepo.Update(ctx, notification)
-
 	return &dto.NotificationResponse{
 		Success:      true,
 		Message:      "Notification sent successfully",
@@ -189,7 +284,7 @@ func (u *NotificationUseCase) MarkAsRead(id string) (*dto.NotificationResponse,
 	if err := u.notificationRepo.MarkAsRead(ctx, id); err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Failed to mark notification as read",
+			Message: notificationErrorMessage(err, "Failed to mark notification as read"),
 		}, err
 	}
 
@@ -198,7 +293,7 @@ func (u *NotificationUseCase) MarkAsRead(id string) (*dto.NotificationResponse,
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Notification not found",
+			Message: notificationErrorMessage(err, "Failed to get notification"),
 		}, err
 	}
 
@@ -213,7 +308,7 @@ func (u *NotificationUseCase) MarkAsRead(id string) (*dto.NotificationResponse,
 func (u *NotificationUseCase) MarkAllAsRead(userID string) (*dto.NotificationResponse, error) {
 	ctx := context.Background()
 
-	count, err := u.notificationRepo.MarkAllAsRead(ctx, userID)
+	count, ids, err := u.notificationRepo.MarkAllAsRead(ctx, userID)
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
@@ -222,8 +317,88 @@ func (u *NotificationUseCase) MarkAllAsRead(userID string) (*dto.NotificationRes
 	}
 
 	return &dto.NotificationResponse{
-		Success: true,
-		Message: fmt.Sprintf("Marked %d notifications as read", count),
+		Success:     true,
+		Message:     fmt.Sprintf("Marked %d notifications as read", count),
+		AffectedIDs: ids,
+	}, nil
+}
+
+// BatchUpdate applies a read or delete action to several notifications of a user.
+// Only notifications owned by the user are modified; the others are reported as not found.
+func (u *NotificationUseCase) BatchUpdate(userID string, ids []string, action string) (*dto.BatchUpdateResponse, error) {
+	ctx := context.Background()
+
+	// Deduplicate while keeping the request order for the per-ID results
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	if len(unique) > dto.MaxBatchSize {
+		return &dto.BatchUpdateResponse{
+			Success: false,
+			Message: fmt.Sprintf("Batch size exceeds maximum of %d", dto.MaxBatchSize),
+			Action:  action,
+		}, fmt.Errorf("invalid batch: %d ids exceeds maximum of %d", len(unique), dto.MaxBatchSize)
+	}
+
+	var applied []string
+	var err error
+	switch action {
+	case dto.BatchActionRead:
+		applied, err = u.notificationRepo.MarkAsReadForUser(ctx, userID, unique)
+	case dto.BatchActionDelete:
+		applied, err = u.notificationRepo.DeleteForUser(ctx, userID, unique)
+	default:
+		return &dto.BatchUpdateResponse{
+			Success: false,
+			Message: "Unsupported batch action",
+			Action:  action,
+		}, fmt.Errorf("invalid batch action: %q", action)
+	}
+	if err != nil {
+		return &dto.BatchUpdateResponse{
+			Success: false,
+			Message: "Failed to apply batch action",
+			Action:  action,
+		}, err
+	}
+
+	appliedSet := make(map[string]bool, len(applied))
+	for _, id := range applied {
+		appliedSet[id] = true
+	}
+
+	results := make([]dto.BatchItemResult, 0, len(unique))
+	succeeded := 0
+	for _, id := range unique {
+		if appliedSet[id] {
+			succeeded++
+			results = append(results, dto.BatchItemResult{ID: id, Success: true})
+			continue
+		}
+		results = append(results, dto.BatchItemResult{ID: id, Success: false, Error: "notification not found"})
+	}
+
+	u.logger.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"action":    action,
+		"requested": len(unique),
+		"succeeded": succeeded,
+	}).Info("Batch notification update applied")
+
+	return &dto.BatchUpdateResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Applied %s to %d of %d notifications", action, succeeded, len(unique)),
+		Action:    action,
+		Succeeded: succeeded,
+		Failed:    len(unique) - succeeded,
+		Results:   results,
 	}, nil
 }
 
@@ -234,7 +409,7 @@ func (u *NotificationUseCase) DeleteNotification(id string) (*dto.NotificationRe
 	if err := u.notificationRepo.Delete(ctx, id); err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Failed to delete notification",
+			Message: notificationErrorMessage(err, "Failed to delete notification"),
 		}, err
 	}
 
@@ -252,7 +427,7 @@ func (u *NotificationUseCase) GetNotification(id string) (*dto.NotificationRespo
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Notification not found",
+			Message: notificationErrorMessage(err, "Failed to get notification"),
 		}, err
 	}
 
@@ -263,26 +438,77 @@ func (u *NotificationUseCase) GetNotification(id string) (*dto.NotificationRespo
 	}, nil
 }
 
+// notificationErrorMessage returns the response message for a failed operation on a single
+// notification, telling a missing notification apart from other failures
+func notificationErrorMessage(err error, fallback string) string {
+	if errors.Is(err, repository.ErrNotificationNotFound) {
+		return "Notification not found"
+	}
+	return fallback
+}
+
+// ListNotifications gets a page of notifications across all users, optionally filtered by status, type
+// and channel, with the number of matching notifications per status regardless of the status filter
+func (u *NotificationUseCase) ListNotifications(status, notificationType, channel string, limit, offset int, sort string) (*dto.AdminNotificationListResponse, error) {
+	ctx := context.Background()
+	filter := repository.NotificationFilter{
+		Status:  entity.NotificationStatus(status),
+		Type:    entity.NotificationType(notificationType),
+		Channel: entity.NotificationChannel(channel),
+	}
+
+	notifications, total, err := u.notificationRepo.List(ctx, filter, limit, offset, sort)
+	if err != nil {
+		return &dto.AdminNotificationListResponse{
+			Success: false,
+			Message: "Failed to list notifications",
+		}, err
+	}
+
+	// Counted without the status filter so the breakdown shows where matching notifications stand
+	countFilter := filter
+	countFilter.Status = ""
+	statusCounts, err := u.notificationRepo.GetCountsByStatus(ctx, countFilter)
+	if err != nil {
+		return &dto.AdminNotificationListResponse{
+			Success: false,
+			Message: "Failed to count notifications by status",
+		}, err
+	}
+
+	return &dto.AdminNotificationListResponse{
+		Success:       true,
+		Message:       "Notifications retrieved successfully",
+		Notifications: notifications,
+		Total:         total,
+		StatusCounts:  statusCounts,
+	}, nil
+}
+
 // GetNotificationsByUser gets notifications for a user
 func (u *NotificationUseCase) GetNotificationsByUser(
 	userID, status, notificationType string,
+	read *bool,
 	limit, offset int,
+	sort string,
 ) (*dto.NotificationListResponse, error) {
 	ctx := context.Background()
 
 	var notifications []*entity.Notification
 	var err error
 
-	if status != "" {
+	if read != nil {
+		notifications, err = u.notificationRepo.GetByUserIDAndReadState(ctx, userID, *read, limit, offset, sort)
+	} else if status != "" {
 		notifications, err = u.notificationRepo.GetByUserIDAndStatus(
-			ctx, userID, entity.NotificationStatus(status), limit, offset,
+			ctx, userID, entity.NotificationStatus(status), limit, offset, sort,
 		)
 	} else if notificationType != "" {
 		notifications, err = u.notificationRepo.GetByUserIDAndType(
-			ctx, userID, entity.NotificationType(notificationType), limit, offset,
+			ctx, userID, entity.NotificationType(notificationType), limit, offset, sort,
 		)
 	} else {
-		notifications, err = u.notificationRepo.GetByUserID(ctx, userID, limit, offset)
+		notifications, err = u.notificationRepo.GetByUserID(ctx, userID, limit, offset, sort)
 	}
 
 	if err != nil {
@@ -296,6 +522,15 @@ func (u *NotificationUseCase) GetNotificationsByUser(
 	total, _ := u.notificationRepo.GetCountByUserID(ctx, userID)
 	unreadCount, _ := u.notificationRepo.GetUnreadCountByUserID(ctx, userID)
 
+	// With a read filter the total covers only the matching notifications, so pagination stays correct
+	if read != nil {
+		if *read {
+			total -= unreadCount
+		} else {
+			total = unreadCount
+		}
+	}
+
 	return &dto.NotificationListResponse{
 		Success:       true,
 		Message:       "Notifications retrieved successfully",
@@ -309,10 +544,11 @@ func (u *NotificationUseCase) GetNotificationsByUser(
 func (u *NotificationUseCase) GetUnreadNotifications(
 	userID string,
 	limit, offset int,
+	sort string,
 ) (*dto.NotificationListResponse, error) {
 	ctx := context.Background()
 
-	notifications, err := u.notificationRepo.GetUnreadByUserID(ctx, userID)
+	notifications, err := u.notificationRepo.GetUnreadByUserID(ctx, userID, sort)
 	if err != nil {
 		return &dto.NotificationListResponse{
 			Success: false,
@@ -320,6 +556,9 @@ func (u *NotificationUseCase) GetUnreadNotifications(
 		}, err
 	}
 
+	// Count before paging so the total covers every unread notification
+	unreadCount := int64(len(notifications))
+
 	// Apply pagination
 	start := offset
 	end := offset + limit
@@ -333,8 +572,6 @@ func (u *NotificationUseCase) GetUnreadNotifications(
 		notifications = notifications[start:end]
 	}
 
-	unreadCount := int64(len(notifications))
-
 	return &dto.NotificationListResponse{
 		Success:       true,
 		Message:       "Unread notifications retrieved successfully",
@@ -380,7 +617,7 @@ func (u *NotificationUseCase) BulkCreateNotification(
 	for _, userID := range userIDs {
 		response, err := u.CreateNotification(
 			userID, title, message, notificationType,
-			priority, channel, templateID, data, expiresAt,
+			priority, channel, templateID, data, expiresAt, "", nil,
 		)
 		if err != nil {
 			errors = append(errors, err)
@@ -461,11 +698,12 @@ func (u *NotificationUseCase) RetryFailedNotification(id string) (*dto.Notificat
 	if err != nil {
 		return &dto.NotificationResponse{
 			Success: false,
-			Message: "Notification not found",
+			Message: notificationErrorMessage(err, "Failed to get notification"),
 		}, err
 	}
 
-	if notification.Status != entity.NotificationStatusFailed {
+	// Permanently failed notifications can still be retried by hand
+	if notification.Status != entity.NotificationStatusFailed && notification.Status != entity.NotificationStatusPermanentlyFailed {
 		return &dto.NotificationResponse{
 			Success: false,
 			Message: "Notification is not in failed status",
@@ -474,23 +712,19 @@ func (u *NotificationUseCase) RetryFailedNotification(id string) (*dto.Notificat
 
 	// Reset status and retry
 	notification.Status = entity.NotificationStatusPending
+	notification.NextRetryAt = nil
 	notification.UpdatedAt = time.Now()
 	u.notificationRepo.Update(ctx, notification)
 
 	// Retry sending
 	if err := u.sendNotification(notification); err != nil {
-		notification.MarkAsFailed()
-		u.notificationRepo.Update(ctx, notification)
-
 		return &dto.NotificationResponse{
-			Success: false,
-			Message: "Failed to retry notification",
+			Success:      false,
+			Message:      "Failed to retry notification",
+			Notification: notification,
 		}, err
 	}
 
-	notification.MarkAsSent()
-	u.notificationRepo.Update(ctx, notification)
-
 	return &dto.NotificationResponse{
 		Success:      true,
 		Message:      "Notification retried successfully",
@@ -516,66 +750,270 @@ func (u *NotificationUseCase) CleanupExpiredNotifications() (*dto.NotificationRe
 	}, nil
 }
 
-// sendNotification sends a notification through the appropriate channel
+// sendNotification dispatches a notification to the sender registered for its channel, falling back
+// to the next channel of its type's fallback chain when a channel fails, and persists the outcome:
+// delivered with the provider's delivery ID and the channel that delivered it, or failed with the last error
 func (u *NotificationUseCase) sendNotification(notification *entity.Notification) error {
-	u.logger.WithFields(logrus.Fields{
+	logger := u.logger.WithFields(logrus.Fields{
 		"notification_id": notification.ID,
 		"user_id":         notification.UserID,
 		"channel":         notification.Channel,
 		"type":            notification.Type,
-	}).Info("Sending notification")
-
-	// Simulate sending notification
-	// In production, implement actual sending logic for each channel
-	switch notification.Channel {
-	case entity.NotificationChannelEmail:
-		return u.sendEmailNotification(notification)
-	case entity.NotificationChannelSMS:
-		return u.sendSMSNotification(notification)
-	case entity.NotificationChannelPush:
-		return u.sendPushNotification(notification)
-	case entity.NotificationChannelInApp:
-		return u.sendInAppNotification(notification)
-	case entity.NotificationChannelWebhook:
-		return u.sendWebhookNotification(notification)
-	default:
-		return fmt.Errorf("unsupported notification channel: %s", notification.Channel)
+	})
+	logger.Info("Sending notification")
+
+	ctx := context.Background()
+
+	// Preferences may have changed since the notification was created
+	if !u.applyPreferences(ctx, notification) {
+		if updateErr := u.notificationRepo.Update(ctx, notification); updateErr != nil {
+			logger.WithError(updateErr).Error("Failed to persist notification delivery status")
+		}
+		return nil
 	}
+
+	var deliveryID string
+	var deliveredVia entity.NotificationChannel
+	var err error
+	channels := u.deliveryChannels(ctx, notification)
+	for i, channel := range channels {
+		if i > 0 {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"failed_channel":   channels[i-1],
+				"fallback_channel": channel,
+			}).Warn("Notification delivery failed, falling back to next channel")
+			metrics.RecordChannelFallback(string(channels[i-1]), string(channel))
+		}
+
+		if sender, ok := u.senders[channel]; ok {
+			deliveryID, err = sender.Send(ctx, notification)
+		} else {
+			err = fmt.Errorf("no sender registered for notification channel: %s", channel)
+		}
+		if err == nil {
+			deliveredVia = channel
+			break
+		}
+	}
+
+	if err != nil {
+		notification.MarkAsFailed(err)
+		u.scheduleRetry(notification)
+		logger.WithError(err).WithField("channels_tried", len(channels)).Error("Failed to deliver notification")
+	} else {
+		notification.MarkAsDelivered(deliveredVia, deliveryID)
+		logger.WithFields(logrus.Fields{
+			"delivery_id":   deliveryID,
+			"delivered_via": deliveredVia,
+		}).Info("Notification delivered")
+	}
+
+	if updateErr := u.notificationRepo.Update(ctx, notification); updateErr != nil {
+		logger.WithError(updateErr).Error("Failed to persist notification delivery status")
+	}
+
+	return err
 }
 
-// sendEmailNotification sends email notification
-func (u *NotificationUseCase) sendEmailNotification(notification *entity.Notification) error {
-	// Implement email sending logic
-	u.logger.WithField("notification_id", notification.ID).Info("Sending email notification")
-	return nil
+// FlushDueDigests delivers the notifications pending a digest whose window has elapsed, each group as a
+// single notification, and returns how many digests were delivered. Every replica may call it; a group
+// flushed concurrently by another is skipped.
+func (u *NotificationUseCase) FlushDueDigests(ctx context.Context) (int, error) {
+	pending, err := u.notificationRepo.GetDigestPending(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get notifications pending a digest: %w", err)
+	}
+
+	flushed := 0
+	for _, notifications := range u.digester.dueDigests(pending, time.Now()) {
+		if ctx.Err() != nil {
+			break
+		}
+		if u.flushDigest(ctx, notifications) {
+			flushed++
+		}
+	}
+	return flushed, nil
 }
 
-// sendSMSNotification sends SMS notification
-func (u *NotificationUseCase) sendSMSNotification(notification *entity.Notification) error {
-	// Implement SMS sending logic
-	u.logger.WithField("notification_id", notification.ID).Info("Sending SMS notification")
-	return nil
+// flushDigest stores and delivers the notifications pending one digest as a single notification.
+// A single notification is delivered as is. It reports whether the digest was delivered.
+func (u *NotificationUseCase) flushDigest(ctx context.Context, notifications []*entity.Notification) bool {
+	notification := buildDigest(notifications)
+
+	logger := u.logger.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"user_id":         notification.UserID,
+		"type":            notification.Type,
+		"channel":         notification.Channel,
+		"digest_count":    len(notifications),
+	})
+
+	if len(notifications) == 1 {
+		err := u.notificationRepo.ReleaseFromDigest(ctx, notification.ID)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotificationNotFound) {
+				logger.WithError(err).Error("Failed to release notification from digest")
+			}
+			return false
+		}
+		notification.Status = entity.NotificationStatusPending
+	} else {
+		itemIDs := make([]string, 0, len(notifications))
+		for _, item := range notifications {
+			itemIDs = append(itemIDs, item.ID)
+		}
+		if err := u.notificationRepo.CreateDigest(ctx, notification, itemIDs); err != nil {
+			if !errors.Is(err, repository.ErrDigestItemsChanged) {
+				logger.WithError(err).Error("Failed to create digest notification")
+			}
+			return false
+		}
+	}
+	logger.Info("Digest notification created")
+
+	if u.domainService.ShouldSendImmediately(*notification) {
+		u.sendNotification(notification)
+	}
+	return true
 }
 
-// sendPushNotification sends push notification
-func (u *NotificationUseCase) sendPushNotification(notification *entity.Notification) error {
-	// Implement push notification logic
-	u.logger.WithField("notification_id", notification.ID).Info("Sending push notification")
-	return nil
+// applyPreferences adjusts the notification according to the user's preferences.
+// It returns false when the notification was suppressed and must not be delivered.
+func (u *NotificationUseCase) applyPreferences(ctx context.Context, notification *entity.Notification) bool {
+	pref, err := u.preferenceRepo.GetByUserID(ctx, notification.UserID)
+	if err != nil {
+		// Fall back to the defaults when the user has none or the lookup fails
+		pref = nil
+	}
+
+	decision := u.domainService.ApplyPreferences(*notification, pref, time.Now())
+	if decision.Suppress {
+		notification.MarkAsSuppressed(decision.Reason)
+		u.logger.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"user_id":         notification.UserID,
+			"reason":          decision.Reason,
+		}).Info("Notification suppressed by user preferences")
+		return false
+	}
+
+	if decision.Channel != notification.Channel {
+		if notification.Data == nil {
+			notification.Data = make(map[string]string)
+		}
+		notification.Data["requested_channel"] = string(notification.Channel)
+		u.logger.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"from_channel":    notification.Channel,
+			"to_channel":      decision.Channel,
+			"reason":          decision.Reason,
+		}).Info("Notification channel downgraded by user preferences")
+		notification.Channel = decision.Channel
+	}
+	return true
 }
 
-// sendInAppNotification sends in-app notification
-func (u *NotificationUseCase) sendInAppNotification(notification *entity.Notification) error {
-	// In-app notifications are already stored in database
-	u.logger.WithField("notification_id", notification.ID).Info("In-app notification ready")
-	return nil
+// GetPreferences returns a user's notification preferences, or the defaults if none are stored
+func (u *NotificationUseCase) GetPreferences(userID string) (*dto.PreferenceResponse, error) {
+	ctx := context.Background()
+
+	pref, err := u.preferenceRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		pref = entity.NewDefaultNotificationPreference(userID)
+	}
+
+	return &dto.PreferenceResponse{
+		Success:    true,
+		Message:    "Notification preferences retrieved successfully",
+		Preference: pref,
+	}, nil
+}
+
+// UpdatePreferences applies the provided fields to a user's notification preferences
+func (u *NotificationUseCase) UpdatePreferences(userID string, req dto.UpdatePreferenceRequest) (*dto.PreferenceResponse, error) {
+	ctx := context.Background()
+
+	pref, err := u.preferenceRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		pref = entity.NewDefaultNotificationPreference(userID)
+		pref.CreatedAt = time.Now()
+	}
+
+	if req.EmailEnabled != nil {
+		pref.EmailEnabled = *req.EmailEnabled
+	}
+	if req.SMSEnabled != nil {
+		pref.SMSEnabled = *req.SMSEnabled
+	}
+	if req.PushEnabled != nil {
+		pref.PushEnabled = *req.PushEnabled
+	}
+	if req.WebhookEnabled != nil {
+		pref.WebhookEnabled = *req.WebhookEnabled
+	}
+	if req.MarketingOptIn != nil {
+		pref.MarketingOptIn = *req.MarketingOptIn
+	}
+	if req.MutedTypes != nil {
+		for _, t := range req.MutedTypes {
+			if !u.domainService.IsValidNotificationType(t) {
+				return &dto.PreferenceResponse{
+					Success: false,
+					Message: fmt.Sprintf("invalid notification type %q", t),
+				}, nil
+			}
+		}
+		pref.MutedTypes = req.MutedTypes
+	}
+	if req.QuietHoursStart != nil {
+		pref.QuietHoursStart = *req.QuietHoursStart
+	}
+	if req.QuietHoursEnd != nil {
+		pref.QuietHoursEnd = *req.QuietHoursEnd
+	}
+	if req.Timezone != nil {
+		pref.Timezone = *req.Timezone
+	}
+
+	if err := pref.Validate(); err != nil {
+		return &dto.PreferenceResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	pref.UpdatedAt = time.Now()
+	if err := u.preferenceRepo.Upsert(ctx, pref); err != nil {
+		u.logger.WithError(err).Error("Failed to update notification preferences")
+		return &dto.PreferenceResponse{
+			Success: false,
+			Message: "Failed to update notification preferences",
+		}, err
+	}
+
+	return &dto.PreferenceResponse{
+		Success:    true,
+		Message:    "Notification preferences updated successfully",
+		Preference: pref,
+	}, nil
 }
 
-// sendWebhookNotification sends webhook notification
-func (u *NotificationUseCase) sendWebhookNotification(notification *entity.Notification) error {
-	// Implement webhook sending logic
-	u.logger.WithField("notification_id", notification.ID).Info("Sending webhook notification")
-	return nil
+// DeletePreferences removes a user's stored preferences so the defaults apply again
+func (u *NotificationUseCase) DeletePreferences(userID string) (*dto.PreferenceResponse, error) {
+	ctx := context.Background()
+
+	if err := u.preferenceRepo.Delete(ctx, userID); err != nil {
+		return &dto.PreferenceResponse{
+			Success: false,
+			Message: "Notification preferences not found",
+		}, err
+	}
+
+	return &dto.PreferenceResponse{
+		Success: true,
+		Message: "Notification preferences reset to defaults",
+	}, nil
 }
 
 // scheduleNotification schedules a notification for later sending
@@ -592,7 +1030,7 @@ func (u *NotificationUseCase) GetNotificationsByType(
 ) (*dto.NotificationListResponse, error) {
 	ctx := context.Background()
 
-	notifications, err := u.notificationRepo.GetByUserIDAndType(ctx, userID, notificationType, limit, offset)
+	notifications, err := u.notificationRepo.GetByUserIDAndType(ctx, userID, notificationType, limit, offset, entity.DefaultNotificationSort)
 	if err != nil {
 		return &dto.NotificationListResponse{
 			Success: false,
@@ -623,7 +1061,7 @@ func (u *NotificationUseCase) GetNotificationsByChannel(
 
 	// This would need to be implemented in the repository
 	// For now, return all notifications and filter by channel
-	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset)
+	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset, entity.DefaultNotificationSort)
 	if err != nil {
 		return &dto.NotificationListResponse{
 			Success: false,
@@ -662,7 +1100,7 @@ func (u *NotificationUseCase) GetNotificationsByPriority(
 
 	// This would need to be implemented in the repository
 	// For now, return all notifications and filter by priority
-	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset)
+	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset, entity.DefaultNotificationSort)
 	if err != nil {
 		return &dto.NotificationListResponse{
 			Success: false,
@@ -700,7 +1138,7 @@ func (u *NotificationUseCase) SearchNotifications(
 
 	// This would need to be implemented in the repository with proper search logic
 	// For now, return all notifications for the user
-	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset)
+	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset, entity.DefaultNotificationSort)
 	if err != nil {
 		return &dto.NotificationListResponse{
 			Success: false,
@@ -765,7 +1203,7 @@ func (u *NotificationUseCase) GetRecentNotifications(
 
 	// This would need to be implemented in the repository with time filtering
 	// For now, return all notifications for the user
-	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset)
+	notifications, err := u.notificationRepo.GetByUserID(ctx, userID, limit, offset, entity.DefaultNotificationSort)
 	if err != nil {
 		return &dto.NotificationListResponse{
 			Success: false,
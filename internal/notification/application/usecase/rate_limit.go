@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/infrastructure/metrics"
+)
+
+// defaultRateLimit is the key of the limit applied to notification types without their own
+const defaultRateLimit entity.NotificationType = "*"
+
+// RateLimit caps how many notifications of a type a user may receive within a window
+type RateLimit struct {
+	Max    int
+	Window time.Duration
+}
+
+// NotificationRateLimiter decides whether a user may receive another notification of a type.
+// The in-memory SlidingWindowRateLimiter suits a single replica; an implementation backed by a
+// shared store can be plugged in to enforce the limits across replicas.
+type NotificationRateLimiter interface {
+	// Allow records a notification for the user and type if it is within the limit.
+	// When it is not, it returns false and how long until the next one would be allowed.
+	Allow(userID string, notificationType entity.NotificationType, now time.Time) (bool, time.Duration)
+}
+
+// rateLimitKey identifies the notifications counted against one limit
+type rateLimitKey struct {
+	userID           string
+	notificationType entity.NotificationType
+}
+
+// SlidingWindowRateLimiter limits notifications per user and type over a sliding window,
+// the same approach the gateway uses for requests, keeping the window's send times in memory
+type SlidingWindowRateLimiter struct {
+	limits    map[entity.NotificationType]RateLimit
+	maxWindow time.Duration
+
+	mu        sync.Mutex
+	sent      map[rateLimitKey][]time.Time
+	lastSweep time.Time
+}
+
+// NewSlidingWindowRateLimiter creates a limiter for the given limits per notification type.
+// The "*" limit applies to types without their own; types without any limit are never throttled.
+func NewSlidingWindowRateLimiter(limits map[entity.NotificationType]RateLimit) *SlidingWindowRateLimiter {
+	var maxWindow time.Duration
+	for _, limit := range limits {
+		if limit.Window > maxWindow {
+			maxWindow = limit.Window
+		}
+	}
+
+	return &SlidingWindowRateLimiter{
+		limits:    limits,
+		maxWindow: maxWindow,
+		sent:      make(map[rateLimitKey][]time.Time),
+	}
+}
+
+// Allow records a notification for the user and type if fewer than the limit were sent in the last window
+func (l *SlidingWindowRateLimiter) Allow(userID string, notificationType entity.NotificationType, now time.Time) (bool, time.Duration) {
+	limit, ok := l.limits[notificationType]
+	if !ok {
+		limit, ok = l.limits[defaultRateLimit]
+	}
+	if !ok || limit.Max <= 0 || limit.Window <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	key := rateLimitKey{userID: userID, notificationType: notificationType}
+	sent := pruneBefore(l.sent[key], now.Add(-limit.Window))
+	if len(sent) >= limit.Max {
+		l.sent[key] = sent
+		return false, sent[0].Add(limit.Window).Sub(now)
+	}
+
+	l.sent[key] = append(sent, now)
+	return true, 0
+}
+
+// sweep drops the entries of users who have sent nothing within the longest window,
+// at most once per window, so idle users do not accumulate in memory
+func (l *SlidingWindowRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.maxWindow {
+		return
+	}
+	l.lastSweep = now
+
+	cutoff := now.Add(-l.maxWindow)
+	for key, sent := range l.sent {
+		if len(sent) == 0 || !sent[len(sent)-1].After(cutoff) {
+			delete(l.sent, key)
+		}
+	}
+}
+
+// pruneBefore drops the send times at or before cutoff; times are kept in ascending order
+func pruneBefore(sent []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(sent) && !sent[i].After(cutoff) {
+		i++
+	}
+	return sent[i:]
+}
+
+// allowByRateLimit reports whether a notification is within its user's rate limit.
+// Urgent notifications are never throttled; throttled ones are marked suppressed with the reason.
+func (u *NotificationUseCase) allowByRateLimit(notification *entity.Notification) bool {
+	if u.rateLimiter == nil || notification.Priority == entity.NotificationPriorityUrgent {
+		return true
+	}
+
+	allowed, retryAfter := u.rateLimiter.Allow(notification.UserID, notification.Type, time.Now())
+	if allowed {
+		return true
+	}
+
+	notification.MarkAsSuppressed("rate limited: too many " + string(notification.Type) + " notifications")
+	metrics.RecordNotificationThrottled(string(notification.Type))
+	u.logger.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"user_id":         notification.UserID,
+		"type":            notification.Type,
+		"retry_after":     retryAfter.String(),
+	}).Warn("Notification dropped by per-user rate limit")
+	return false
+}
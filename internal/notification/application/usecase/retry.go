@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/infrastructure/metrics"
+)
+
+// maxRetryBackoff caps the delay between two automatic retries of a notification
+const maxRetryBackoff = 24 * time.Hour
+
+// RetryConfig controls the automatic retries of notifications whose delivery failed
+type RetryConfig struct {
+	MaxAttempts int           // Retries before a notification is marked permanently failed; 0 disables automatic retries
+	BaseBackoff time.Duration // Delay before the first retry, doubled on each further attempt
+}
+
+// scheduleRetry schedules the next automatic retry of a notification whose delivery failed,
+// or marks it permanently failed once its retries are used up
+func (u *NotificationUseCase) scheduleRetry(notification *entity.Notification) {
+	if u.retry.MaxAttempts <= 0 {
+		return
+	}
+	if notification.RetryCount >= u.retry.MaxAttempts {
+		notification.MarkAsPermanentlyFailed()
+		return
+	}
+	notification.ScheduleRetry(time.Now().Add(retryBackoff(u.retry.BaseBackoff, notification.RetryCount)))
+}
+
+// retryBackoff returns the delay before the retry following attempt retries: the base backoff
+// doubled attempt times, capped at maxRetryBackoff
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 0; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
+
+// RetryDueNotifications retries up to limit failed notifications whose scheduled retry is due and
+// returns how many were retried. Every retry counts as an attempt, whether or not it is delivered.
+func (u *NotificationUseCase) RetryDueNotifications(ctx context.Context, limit int) (int, error) {
+	notifications, err := u.notificationRepo.GetDueForRetry(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get notifications due for retry: %w", err)
+	}
+
+	for _, notification := range notifications {
+		notification.RetryCount++
+		notification.NextRetryAt = nil
+		notification.Status = entity.NotificationStatusPending
+
+		// sendNotification persists the outcome and schedules the next retry on failure
+		err := u.sendNotification(notification)
+		metrics.RecordNotificationRetried(string(notification.Status))
+
+		logger := u.logger.WithFields(logrus.Fields{
+			"notification_id": notification.ID,
+			"retry_count":     notification.RetryCount,
+			"status":          notification.Status,
+		})
+		if err != nil {
+			logger.WithError(err).Warn("Notification retry failed")
+		} else {
+			logger.Info("Notification retried")
+		}
+	}
+
+	return len(notifications), nil
+}
@@ -1,13 +1,14 @@
 package entity
 
 import (
+	"strings"
 	"time"
 )
 
 // Notification represents a notification in the system
 type Notification struct {
 	ID          string            `json:"id" gorm:"primaryKey"`
-	UserID      string            `json:"user_id" gorm:"not null;index"`
+	UserID      string            `json:"user_id" gorm:"not null;index;index:idx_notifications_user_read,priority:1"`
 	Title       string            `json:"title" gorm:"not null"`
 	Message     string            `json:"message" gorm:"not null"`
 	Type        NotificationType  `json:"type" gorm:"not null"`
@@ -19,8 +20,28 @@ type Notification struct {
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 	SentAt      *time.Time        `json:"sent_at"`
-	ReadAt      *time.Time        `json:"read_at"`
+	DeliveredAt *time.Time        `json:"delivered_at"`
+	ReadAt      *time.Time        `json:"read_at" gorm:"index:idx_notifications_user_read,priority:2"`
 	ExpiresAt   *time.Time        `json:"expires_at"`
+	DeliveryID  string            `json:"delivery_id,omitempty" gorm:"index"`
+	DeliveredVia NotificationChannel `json:"delivered_via,omitempty"` // Channel that delivered it, which differs from Channel after a fallback
+	LastError   string            `json:"last_error,omitempty"`
+	RetryCount  int               `json:"retry_count" gorm:"not null;default:0"` // Automatic retries attempted after the first failed delivery
+	NextRetryAt *time.Time        `json:"next_retry_at,omitempty" gorm:"index"`  // When a failed notification is retried next; nil when no retry is scheduled
+	// SourceEventID is the ID of the event the notification was created for, unique when set,
+	// so a redelivered event does not create the notification twice
+	SourceEventID string `json:"source_event_id,omitempty" gorm:"uniqueIndex:idx_notifications_source_event_id,where:source_event_id <> ''"`
+	// Attachments link files such as a receipt PDF or an image; senders deliver them as links
+	Attachments []Attachment `json:"attachments,omitempty" gorm:"serializer:json"`
+	// DigestID is the ID of the digest notification this one was coalesced into
+	DigestID string `json:"digest_id,omitempty" gorm:"index"`
+}
+
+// Attachment is a file linked from a notification
+type Attachment struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
 }
 
 // NotificationType represents the type of notification
@@ -47,6 +68,14 @@ const (
 	NotificationStatusRead      NotificationStatus = "read"
 	NotificationStatusFailed    NotificationStatus = "failed"
 	NotificationStatusExpired   NotificationStatus = "expired"
+	// NotificationStatusSuppressed marks notifications withheld because of user preferences
+	NotificationStatusSuppressed NotificationStatus = "suppressed"
+	// NotificationStatusPermanentlyFailed marks failed notifications that used up their automatic retries
+	NotificationStatusPermanentlyFailed NotificationStatus = "permanently_failed"
+	// NotificationStatusDigestPending marks notifications held back until their digest window elapses
+	NotificationStatusDigestPending NotificationStatus = "digest_pending"
+	// NotificationStatusDigested marks notifications delivered as part of the digest named by DigestID
+	NotificationStatusDigested NotificationStatus = "digested"
 )
 
 // NotificationPriority represents the priority of a notification
@@ -70,6 +99,33 @@ const (
 	NotificationChannelWebhook NotificationChannel = "webhook"
 )
 
+// DefaultNotificationSort orders notification lists latest first
+const DefaultNotificationSort = "-created_at"
+
+// notificationSortFields are the fields notification lists can be ordered by
+var notificationSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"sent_at":    true,
+	"read_at":    true,
+}
+
+// NotificationSortField returns the field a sort orders by and whether the order is descending.
+// Sorts name a field, prefixed with "-" for descending; an empty sort is DefaultNotificationSort.
+func NotificationSortField(sort string) (string, bool) {
+	if sort == "" {
+		sort = DefaultNotificationSort
+	}
+	field := strings.TrimPrefix(sort, "-")
+	return field, field != sort
+}
+
+// ValidNotificationSort reports whether a sort orders by a sortable field
+func ValidNotificationSort(sort string) bool {
+	field, _ := NotificationSortField(sort)
+	return notificationSortFields[field]
+}
+
 // CreateNotificationRequest represents the request payload for creating a notification
 type CreateNotificationRequest struct {
 	UserID     string            `json:"user_id" binding:"required"`
@@ -81,6 +137,7 @@ type CreateNotificationRequest struct {
 	TemplateID string            `json:"template_id"`
 	Data       map[string]string `json:"data"`
 	ExpiresAt  *time.Time        `json:"expires_at"`
+	SourceEventID string         `json:"source_event_id"`
 }
 
 // UpdateNotificationRequest represents the request payload for updating a notification
@@ -93,21 +150,23 @@ type UpdateNotificationRequest struct {
 // ToDTO converts a Notification entity to a DTO-compatible struct
 func (n *Notification) ToDTO() map[string]interface{} {
 	return map[string]interface{}{
-		"id":          n.ID,
-		"user_id":     n.UserID,
-		"title":       n.Title,
-		"message":     n.Message,
-		"type":        n.Type,
-		"status":      n.Status,
-		"priority":    n.Priority,
-		"channel":     n.Channel,
-		"template_id": n.TemplateID,
-		"data":        n.Data,
-		"created_at":  n.CreatedAt,
-		"updated_at":  n.UpdatedAt,
-		"sent_at":     n.SentAt,
-		"read_at":     n.ReadAt,
-		"expires_at":  n.ExpiresAt,
+		"id":           n.ID,
+		"user_id":      n.UserID,
+		"title":        n.Title,
+		"message":      n.Message,
+		"type":         n.Type,
+		"status":       n.Status,
+		"priority":     n.Priority,
+		"channel":      n.Channel,
+		"template_id":  n.TemplateID,
+		"data":         n.Data,
+		"created_at":   n.CreatedAt,
+		"updated_at":   n.UpdatedAt,
+		"sent_at":      n.SentAt,
+		"delivered_at": n.DeliveredAt,
+		"read_at":      n.ReadAt,
+		"expires_at":   n.ExpiresAt,
+		"attachments":  n.Attachments,
 	}
 }
 
@@ -127,6 +186,7 @@ func (n *Notification) FromCreateRequest(req CreateNotificationRequest) {
 	n.TemplateID = req.TemplateID
 	n.Data = req.Data
 	n.ExpiresAt = req.ExpiresAt
+	n.SourceEventID = req.SourceEventID
 	n.Status = NotificationStatusPending
 	n.CreatedAt = time.Now()
 	n.UpdatedAt = time.Now()
@@ -161,14 +221,57 @@ func (n *Notification) MarkAsSent() {
 	n.UpdatedAt = now
 }
 
-// MarkAsDelivered marks the notification as delivered
-func (n *Notification) MarkAsDelivered() {
+// MarkAsDelivered marks the notification as delivered by the provider of channel
+func (n *Notification) MarkAsDelivered(channel NotificationChannel, deliveryID string) {
+	now := time.Now()
+	if n.SentAt == nil {
+		n.SentAt = &now
+	}
+	n.DeliveredAt = &now
+	n.DeliveryID = deliveryID
+	n.DeliveredVia = channel
+	n.LastError = ""
 	n.Status = NotificationStatusDelivered
+	n.UpdatedAt = now
+}
+
+// MarkAsSuppressed marks the notification as withheld by user preferences
+func (n *Notification) MarkAsSuppressed(reason string) {
+	n.LastError = reason
+	n.Status = NotificationStatusSuppressed
 	n.UpdatedAt = time.Now()
 }
 
-// MarkAsFailed marks the notification as failed
-func (n *Notification) MarkAsFailed() {
+// MarkAsFailed marks the notification as failed and records the delivery error
+func (n *Notification) MarkAsFailed(err error) {
+	if err != nil {
+		n.LastError = err.Error()
+	}
 	n.Status = NotificationStatusFailed
 	n.UpdatedAt = time.Now()
 }
+
+// ScheduleRetry sets when the retry worker retries the failed notification
+func (n *Notification) ScheduleRetry(at time.Time) {
+	n.NextRetryAt = &at
+	n.UpdatedAt = time.Now()
+}
+
+// MarkAsPermanentlyFailed stops automatic retries of a notification that kept failing
+func (n *Notification) MarkAsPermanentlyFailed() {
+	n.NextRetryAt = nil
+	n.Status = NotificationStatusPermanentlyFailed
+	n.UpdatedAt = time.Now()
+}
+
+// NotificationStats represents notification statistics
+type NotificationStats struct {
+	TotalNotifications    int64                        `json:"total_notifications"`
+	UnreadNotifications   int64                        `json:"unread_notifications"`
+	SentNotifications     int64                        `json:"sent_notifications"`
+	FailedNotifications   int64                        `json:"failed_notifications"`
+	PendingNotifications  int64                        `json:"pending_notifications"`
+	ByType                map[string]int64             `json:"by_type"`
+	ByChannel             map[string]int64             `json:"by_channel"`
+	ByStatus              map[string]int64             `json:"by_status"`
+}
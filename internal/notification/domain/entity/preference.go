@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// quietHoursLayout is the clock format used for quiet-hours boundaries
+const quietHoursLayout = "15:04"
+
+// NotificationPreference holds a user's delivery preferences
+type NotificationPreference struct {
+	UserID          string             `json:"user_id" gorm:"primaryKey"`
+	EmailEnabled    bool               `json:"email_enabled" gorm:"not null"`
+	SMSEnabled      bool               `json:"sms_enabled" gorm:"not null"`
+	PushEnabled     bool               `json:"push_enabled" gorm:"not null"`
+	WebhookEnabled  bool               `json:"webhook_enabled" gorm:"not null"`
+	MarketingOptIn  bool               `json:"marketing_opt_in" gorm:"not null"`
+	MutedTypes      []NotificationType `json:"muted_types" gorm:"serializer:json"`
+	QuietHoursStart string             `json:"quiet_hours_start,omitempty"` // HH:MM, empty disables quiet hours
+	QuietHoursEnd   string             `json:"quiet_hours_end,omitempty"`   // HH:MM, may be earlier than start to span midnight
+	Timezone        string             `json:"timezone,omitempty"`          // IANA zone for quiet hours, defaults to UTC
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// NewDefaultNotificationPreference returns the preferences applied to users who have not set any:
+// every channel enabled, nothing muted, no quiet hours and no marketing opt-in
+func NewDefaultNotificationPreference(userID string) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:         userID,
+		EmailEnabled:   true,
+		SMSEnabled:     true,
+		PushEnabled:    true,
+		WebhookEnabled: true,
+		MutedTypes:     []NotificationType{},
+	}
+}
+
+// IsChannelEnabled reports whether the user accepts notifications on the channel.
+// In-app notifications cannot be disabled and serve as the fallback channel.
+func (p *NotificationPreference) IsChannelEnabled(channel NotificationChannel) bool {
+	switch channel {
+	case NotificationChannelEmail:
+		return p.EmailEnabled
+	case NotificationChannelSMS:
+		return p.SMSEnabled
+	case NotificationChannelPush:
+		return p.PushEnabled
+	case NotificationChannelWebhook:
+		return p.WebhookEnabled
+	case NotificationChannelInApp:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTypeMuted reports whether the user muted the notification type
+func (p *NotificationPreference) IsTypeMuted(notificationType NotificationType) bool {
+	for _, muted := range p.MutedTypes {
+		if muted == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// InQuietHours reports whether t falls inside the user's quiet-hours window
+func (p *NotificationPreference) InQuietHours(t time.Time) bool {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return false
+	}
+
+	start, err := time.Parse(quietHoursLayout, p.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(quietHoursLayout, p.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	loc := time.UTC
+	if p.Timezone != "" {
+		if l, err := time.LoadLocation(p.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	// Window spans midnight, e.g. 22:00-07:00
+	return minute >= startMinute || minute < endMinute
+}
+
+// Validate checks the quiet-hours window and timezone
+func (p *NotificationPreference) Validate() error {
+	if (p.QuietHoursStart == "") != (p.QuietHoursEnd == "") {
+		return fmt.Errorf("invalid quiet hours: both start and end must be set")
+	}
+	if p.QuietHoursStart != "" {
+		if _, err := time.Parse(quietHoursLayout, p.QuietHoursStart); err != nil {
+			return fmt.Errorf("invalid quiet hours start %q: expected HH:MM", p.QuietHoursStart)
+		}
+		if _, err := time.Parse(quietHoursLayout, p.QuietHoursEnd); err != nil {
+			return fmt.Errorf("invalid quiet hours end %q: expected HH:MM", p.QuietHoursEnd)
+		}
+	}
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q", p.Timezone)
+		}
+	}
+	return nil
+}
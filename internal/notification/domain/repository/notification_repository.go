@@ -2,34 +2,56 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"obs-tools-usage/internal/notification/domain/entity"
 )
 
-// NotificationRepository defines the interface for notification data operations
+// ErrNotificationNotFound is returned when no notification has the requested ID
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// ErrDigestItemsChanged is returned when notifications to coalesce into a digest are no longer pending,
+// e.g. because they were already flushed
+var ErrDigestItemsChanged = errors.New("digest notifications are no longer pending")
+
+// NotificationRepository defines the interface for notification data operations.
+// Operations on a single notification by ID or source event ID return ErrNotificationNotFound when it does not exist.
+// Notifications waiting for or coalesced into a digest are left out of a user's lists and counts, since the digest stands for them.
+// List operations take a sort as described by entity.NotificationSortField; ties are ordered by ID so pages are stable.
 type NotificationRepository interface {
 	// Create operations
 	Create(ctx context.Context, notification *entity.Notification) error
 	
 	// Read operations
 	GetByID(ctx context.Context, id string) (*entity.Notification, error)
-	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*entity.Notification, error)
-	GetByUserIDAndStatus(ctx context.Context, userID string, status entity.NotificationStatus, limit, offset int) ([]*entity.Notification, error)
-	GetByUserIDAndType(ctx context.Context, userID string, notificationType entity.NotificationType, limit, offset int) ([]*entity.Notification, error)
-	GetUnreadByUserID(ctx context.Context, userID string) ([]*entity.Notification, error)
+	GetBySourceEventID(ctx context.Context, sourceEventID string) (*entity.Notification, error)
+	GetByUserID(ctx context.Context, userID string, limit, offset int, sort string) ([]*entity.Notification, error)
+	GetByUserIDAndStatus(ctx context.Context, userID string, status entity.NotificationStatus, limit, offset int, sort string) ([]*entity.Notification, error)
+	GetByUserIDAndType(ctx context.Context, userID string, notificationType entity.NotificationType, limit, offset int, sort string) ([]*entity.Notification, error)
+	GetByUserIDAndReadState(ctx context.Context, userID string, read bool, limit, offset int, sort string) ([]*entity.Notification, error)
+	GetUnreadByUserID(ctx context.Context, userID string, sort string) ([]*entity.Notification, error)
 	GetExpired(ctx context.Context) ([]*entity.Notification, error)
+	List(ctx context.Context, filter NotificationFilter, limit, offset int, sort string) ([]*entity.Notification, int64, error)
+	GetDueForRetry(ctx context.Context, now time.Time, limit int) ([]*entity.Notification, error)
+	GetDigestPending(ctx context.Context) ([]*entity.Notification, error)
 	
 	// Update operations
 	Update(ctx context.Context, notification *entity.Notification) error
 	MarkAsRead(ctx context.Context, id string) error
-	MarkAllAsRead(ctx context.Context, userID string) (int64, error)
+	MarkAllAsRead(ctx context.Context, userID string) (int64, []string, error)
 	MarkAsSent(ctx context.Context, id string) error
 	MarkAsDelivered(ctx context.Context, id string) error
 	MarkAsFailed(ctx context.Context, id string) error
+	MarkAsReadForUser(ctx context.Context, userID string, ids []string) ([]string, error)
+	CreateDigest(ctx context.Context, digest *entity.Notification, itemIDs []string) error
+	ReleaseFromDigest(ctx context.Context, id string) error
 	
 	// Delete operations
 	Delete(ctx context.Context, id string) error
 	DeleteByUserID(ctx context.Context, userID string) error
 	DeleteExpired(ctx context.Context) (int64, error)
+	DeleteForUser(ctx context.Context, userID string, ids []string) ([]string, error)
 	
 	// Statistics
 	GetStatsByUserID(ctx context.Context, userID string) (*entity.NotificationStats, error)
@@ -38,19 +60,16 @@ type NotificationRepository interface {
 	GetCountByStatus(ctx context.Context, status entity.NotificationStatus) (int64, error)
 	GetCountByType(ctx context.Context, notificationType entity.NotificationType) (int64, error)
 	GetCountByChannel(ctx context.Context, channel entity.NotificationChannel) (int64, error)
+	GetCountsByStatus(ctx context.Context, filter NotificationFilter) (map[string]int64, error)
 	
 	// Health check
 	Ping(ctx context.Context) error
 }
 
-// NotificationStats represents notification statistics
-type NotificationStats struct {
-	TotalNotifications    int64                        `json:"total_notifications"`
-	UnreadNotifications   int64                        `json:"unread_notifications"`
-	SentNotifications     int64                        `json:"sent_notifications"`
-	FailedNotifications   int64                        `json:"failed_notifications"`
-	PendingNotifications  int64                        `json:"pending_notifications"`
-	ByType                map[string]int64             `json:"by_type"`
-	ByChannel             map[string]int64             `json:"by_channel"`
-	ByStatus              map[string]int64             `json:"by_status"`
+// NotificationFilter narrows notifications listed across all users; empty fields match everything
+type NotificationFilter struct {
+	Status  entity.NotificationStatus
+	Type    entity.NotificationType
+	Channel entity.NotificationChannel
 }
+
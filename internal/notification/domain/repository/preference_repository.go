@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// PreferenceRepository defines the interface for notification preference data operations
+type PreferenceRepository interface {
+	GetByUserID(ctx context.Context, userID string) (*entity.NotificationPreference, error)
+	Upsert(ctx context.Context, preference *entity.NotificationPreference) error
+	Delete(ctx context.Context, userID string) error
+}
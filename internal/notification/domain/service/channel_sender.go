@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// ChannelSender delivers a notification through a single delivery channel
+type ChannelSender interface {
+	// Send delivers the notification and returns the provider's delivery ID
+	Send(ctx context.Context, notification *entity.Notification) (deliveryID string, err error)
+}
+
+// ChannelSenders maps each delivery channel to the sender responsible for it
+type ChannelSenders map[entity.NotificationChannel]ChannelSender
@@ -2,10 +2,22 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"obs-tools-usage/internal/notification/domain/entity"
+	"strings"
 	"time"
 )
 
+// Limits on the attachment metadata a notification carries
+const (
+	MaxAttachments     = 10
+	MaxAttachmentBytes = 8 << 10 // Total length of every attachment's name, URL and content type
+)
+
+// ErrInvalidAttachments is returned when a notification's attachments are malformed or over the limits
+var ErrInvalidAttachments = errors.New("invalid attachments")
+
 // NotificationDomainService handles domain-specific business logic
 type NotificationDomainService struct{}
 
@@ -31,6 +43,33 @@ func (s *NotificationDomainService) ValidateNotification(notification entity.Not
 	if notification.Channel == "" {
 		return errors.New("notification channel cannot be empty")
 	}
+	return s.ValidateAttachments(notification.Attachments)
+}
+
+// ValidateAttachments checks that every attachment has a name and an absolute http(s) URL and that
+// the attachments stay within MaxAttachments and MaxAttachmentBytes
+func (s *NotificationDomainService) ValidateAttachments(attachments []entity.Attachment) error {
+	if len(attachments) > MaxAttachments {
+		return fmt.Errorf("%w: at most %d attachments are allowed", ErrInvalidAttachments, MaxAttachments)
+	}
+
+	size := 0
+	for i, attachment := range attachments {
+		if strings.TrimSpace(attachment.Name) == "" {
+			return fmt.Errorf("%w: attachment %d has no name", ErrInvalidAttachments, i)
+		}
+		if strings.ContainsAny(attachment.Name+attachment.ContentType, "\r\n") {
+			return fmt.Errorf("%w: attachment %d contains a line break", ErrInvalidAttachments, i)
+		}
+		u, err := url.Parse(attachment.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: attachment %d URL must be an absolute http or https URL", ErrInvalidAttachments, i)
+		}
+		size += len(attachment.Name) + len(attachment.URL) + len(attachment.ContentType)
+	}
+	if size > MaxAttachmentBytes {
+		return fmt.Errorf("%w: attachment metadata exceeds %d bytes", ErrInvalidAttachments, MaxAttachmentBytes)
+	}
 	return nil
 }
 
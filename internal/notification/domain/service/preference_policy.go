@@ -0,0 +1,46 @@
+package service
+
+import (
+	"time"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// DeliveryDecision is the outcome of applying user preferences to a notification
+type DeliveryDecision struct {
+	Channel  entity.NotificationChannel
+	Suppress bool
+	Reason   string
+}
+
+// ApplyPreferences decides how a notification should be delivered given the user's preferences.
+// Muted types and marketing without explicit opt-in are suppressed; disabled channels and
+// non-urgent notifications during quiet hours are downgraded to in-app delivery.
+func (s *NotificationDomainService) ApplyPreferences(
+	notification entity.Notification,
+	pref *entity.NotificationPreference,
+	now time.Time,
+) DeliveryDecision {
+	if pref == nil {
+		pref = entity.NewDefaultNotificationPreference(notification.UserID)
+	}
+
+	if notification.Type == entity.NotificationTypeMarketing && !pref.MarketingOptIn {
+		return DeliveryDecision{Channel: notification.Channel, Suppress: true, Reason: "user has not opted in to marketing notifications"}
+	}
+	if pref.IsTypeMuted(notification.Type) {
+		return DeliveryDecision{Channel: notification.Channel, Suppress: true, Reason: "notification type muted by user"}
+	}
+
+	if notification.Channel == entity.NotificationChannelInApp {
+		return DeliveryDecision{Channel: notification.Channel}
+	}
+	if !pref.IsChannelEnabled(notification.Channel) {
+		return DeliveryDecision{Channel: entity.NotificationChannelInApp, Reason: "channel disabled by user"}
+	}
+	if notification.Priority != entity.NotificationPriorityUrgent && pref.InQuietHours(now) {
+		return DeliveryDecision{Channel: entity.NotificationChannelInApp, Reason: "quiet hours"}
+	}
+
+	return DeliveryDecision{Channel: notification.Channel}
+}
@@ -0,0 +1,20 @@
+package channel
+
+import (
+	"context"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// InAppSender delivers in-app notifications, which are already available once stored
+type InAppSender struct{}
+
+// NewInAppSender creates a new in-app sender
+func NewInAppSender() *InAppSender {
+	return &InAppSender{}
+}
+
+// Send reports the stored notification as delivered, using its ID as the delivery ID
+func (s *InAppSender) Send(ctx context.Context, notification *entity.Notification) (string, error) {
+	return notification.ID, nil
+}
@@ -0,0 +1,31 @@
+package channel
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/domain/service"
+	"obs-tools-usage/internal/notification/infrastructure/config"
+)
+
+// NewChannelSenders registers a sender for every channel that is configured.
+// Channels without a sender fail delivery instead of silently succeeding.
+func NewChannelSenders(cfg *config.Config, logger *logrus.Logger) service.ChannelSenders {
+	senders := service.ChannelSenders{
+		entity.NotificationChannelInApp: NewInAppSender(),
+	}
+
+	if cfg.WebhookURL != "" {
+		senders[entity.NotificationChannelWebhook] = NewWebhookSender(cfg.WebhookURL, cfg.WebhookTimeout, logger)
+	} else {
+		logger.Warn("WEBHOOK_URL not set, webhook notifications will fail")
+	}
+
+	if cfg.SMTPHost != "" {
+		senders[entity.NotificationChannelEmail] = NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, logger)
+	} else {
+		logger.Warn("SMTP_HOST not set, email notifications will fail")
+	}
+
+	return senders
+}
@@ -0,0 +1,97 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// EmailRecipientKey is the notification data key holding the recipient email address
+const EmailRecipientKey = "email"
+
+// SMTPSender delivers email notifications through an SMTP relay
+type SMTPSender struct {
+	addr   string
+	host   string
+	auth   smtp.Auth
+	from   string
+	logger *logrus.Logger
+}
+
+// NewSMTPSender creates a new SMTP email sender. Authentication is only used when a username is set.
+func NewSMTPSender(host, port, username, password, from string, logger *logrus.Logger) *SMTPSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPSender{
+		addr:   net.JoinHostPort(host, port),
+		host:   host,
+		auth:   auth,
+		from:   from,
+		logger: logger,
+	}
+}
+
+// Send emails the notification to the address stored under EmailRecipientKey in its data.
+// The generated Message-ID is returned as the delivery ID.
+func (s *SMTPSender) Send(ctx context.Context, notification *entity.Notification) (string, error) {
+	to := notification.Data[EmailRecipientKey]
+	if to == "" {
+		return "", fmt.Errorf("notification %s has no %q recipient in its data", notification.ID, EmailRecipientKey)
+	}
+	if strings.ContainsAny(to, "\r\n") {
+		return "", fmt.Errorf("invalid email recipient for notification %s", notification.ID)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), s.host)
+	msg := buildEmailMessage(s.from, to, messageID, notification)
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{to}, msg); err != nil {
+		return "", fmt.Errorf("smtp send failed: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"delivery_id":     messageID,
+	}).Debug("Email notification delivered")
+
+	return messageID, nil
+}
+
+// buildEmailMessage renders a plain-text RFC 5322 message for the notification, listing its attachments as links
+func buildEmailMessage(from, to, messageID string, notification *entity.Notification) []byte {
+	// Strip line breaks so the title cannot inject additional headers
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(notification.Title)
+
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + subject + "\r\n")
+	b.WriteString("Message-ID: " + messageID + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(notification.Message)
+	b.WriteString("\r\n")
+	if len(notification.Attachments) > 0 {
+		b.WriteString("\r\nAttachments:\r\n")
+		for _, attachment := range notification.Attachments {
+			b.WriteString("- " + attachment.Name + ": " + attachment.URL + "\r\n")
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,74 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/notification/domain/entity"
+)
+
+// WebhookSender delivers notifications by POSTing them as JSON to a configured URL
+type WebhookSender struct {
+	url    string
+	client *http.Client
+	logger *logrus.Logger
+}
+
+// NewWebhookSender creates a new webhook sender
+func NewWebhookSender(url string, timeout time.Duration, logger *logrus.Logger) *WebhookSender {
+	return &WebhookSender{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		logger: logger,
+	}
+}
+
+// Send posts the notification, attachments included, to the webhook endpoint. The delivery ID is taken from the
+// X-Delivery-ID response header when the receiver provides one, otherwise it is generated.
+func (s *WebhookSender) Send(ctx context.Context, notification *entity.Notification) (string, error) {
+	deliveryID := uuid.New().String()
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notification-ID", notification.ID)
+	req.Header.Set("X-Delivery-ID", deliveryID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	if id := resp.Header.Get("X-Delivery-ID"); id != "" {
+		deliveryID = id
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"notification_id": notification.ID,
+		"delivery_id":     deliveryID,
+		"status_code":     resp.StatusCode,
+	}).Debug("Webhook notification delivered")
+
+	return deliveryID, nil
+}
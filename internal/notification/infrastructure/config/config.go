@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,12 @@ type Config struct {
 	// Server configuration
 	Port         string
 	Environment  string
+	MaxBodySize  int64  // Maximum accepted HTTP request body size in bytes
+	GzipMinSize  int    // Smallest response body in bytes compressed for clients that accept gzip
+	MaxPageSize  int    // Largest limit accepted by list endpoints
+	APIPrefix    string // Path the versioned API is mounted under
+	AdminToken   string // Bearer token required by admin routes; empty disables them
+	SeedEndpoint bool   // Register POST /admin/seed in development; off unless explicitly enabled
 	
 	// Database configuration
 	DBHost     string
@@ -19,19 +26,50 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+	DBSeed     bool // Insert sample data at startup; rows that already exist are skipped
 	
 	// Kafka configuration
-	KafkaBrokers string
+	KafkaBrokers         string
+	KafkaMaxRetries      int           // Retries per message before it is skipped or dead-lettered
+	KafkaRetryBackoff    time.Duration // Delay before the first retry, doubled on each attempt
+	KafkaMaxRetryBackoff time.Duration
+	KafkaDeadLetterTopic string // Empty disables dead-lettering
 	
 	// Logging configuration
 	LogLevel  string
 	LogFormat string
 	LogOutput string
+	LogSampleRate   int  // Keep 1 in N routine "operation completed" logs; 1 keeps all
+	LogSampleDemote bool // Log them at Debug instead of sampling
+
+	MetricsScrapeInterval time.Duration // How often runtime and DB pool gauges are refreshed
 	
 	// Notification configuration
-	DefaultRetryAttempts int
+	DefaultRetryAttempts int           // Automatic retries of a failed notification before it is marked permanently failed; 0 disables them
+	RetryBackoff         time.Duration // Delay before the first automatic retry, doubled on each further attempt
+	RetryInterval        time.Duration // How often the retry worker looks for notifications due for retry; must be positive
+	RetryBatchSize       int           // Most notifications retried per batch; must be positive
 	NotificationTTL      time.Duration
 	CleanupInterval      time.Duration
+	DigestWindow         time.Duration                // How long digestible notifications are held back per user before one summary is sent
+	DigestTypes          []string                     // Notification types coalesced into digests; empty disables digests
+	DigestFlushInterval  time.Duration                // How often the digest worker looks for digests whose window elapsed; must be positive
+	FallbackChains       map[string][]string          // Channels tried in order per notification type when delivery fails; "*" applies to other types
+	FallbackMaxAttempts  int                          // Most channels tried per delivery, including the notification's own
+	NotificationLimits   map[string]NotificationLimit // Most notifications per user and type within a window; "*" applies to other types
+	
+	// Job lock configuration, keeping background jobs to one replica at a time
+	JobLockEnabled bool
+	JobLockTTL     time.Duration // How long a lease outlives a replica that stopped renewing it
+	
+	// Channel delivery configuration
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUsername   string
+	SMTPPassword   string
+	SMTPFrom       string
 	
 	// Rate limiting
 	RateLimitEnabled bool
@@ -40,14 +78,35 @@ type Config struct {
 	// Metrics configuration
 	MetricsEnabled bool
 	MetricsPath    string
+	
+	// Tracing configuration
+	TracingEnabled  bool
+	TracingEndpoint string // OTLP gRPC collector address
+	TracingInsecure bool
+	
+	// HTTP engine configuration
+	GinMode        string   // gin mode: debug or release
+	TrustedProxies []string // IPs or CIDRs of proxies, such as the gateway, whose X-Forwarded-For is trusted
+}
+
+// NotificationLimit caps how many notifications of a type a user may receive within a window
+type NotificationLimit struct {
+	Max    int
+	Window time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
 		// Server configuration
-		Port:        getEnv("PORT", "8084"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Port:         getEnv("PORT", "8084"),
+		Environment:  getEnv("ENVIRONMENT", "development"),
+		MaxBodySize:  int64(getEnvAsInt("MAX_REQUEST_BODY_BYTES", 1<<20)),
+		GzipMinSize:  getEnvAsInt("GZIP_MIN_SIZE", 1024),
+		MaxPageSize:  getEnvAsInt("MAX_PAGE_SIZE", 100),
+		APIPrefix:    getEnv("API_PREFIX", "/v1"),
+		AdminToken:   getEnv("ADMIN_TOKEN", ""),
+		SeedEndpoint: getEnvAsBool("SEED_ENDPOINT_ENABLED", false),
 		
 		// Database configuration
 		DBHost:     getEnv("DB_HOST", "localhost"),
@@ -56,19 +115,50 @@ func LoadConfig() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "password"),
 		DBName:     getEnv("DB_NAME", "notification_service"),
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
+		DBSeed:     getEnvAsBool("DB_SEED", getEnv("ENVIRONMENT", "development") == "development"),
 		
 		// Kafka configuration
-		KafkaBrokers: getEnv("KAFKA_BROKERS", "localhost:9092"),
+		KafkaBrokers:         getEnv("KAFKA_BROKERS", "localhost:9092"),
+		KafkaMaxRetries:      getEnvAsInt("KAFKA_CONSUMER_MAX_RETRIES", 3),
+		KafkaRetryBackoff:    getEnvAsDuration("KAFKA_CONSUMER_RETRY_BACKOFF", 500*time.Millisecond),
+		KafkaMaxRetryBackoff: getEnvAsDuration("KAFKA_CONSUMER_MAX_RETRY_BACKOFF", 10*time.Second),
+		KafkaDeadLetterTopic: getEnv("KAFKA_CONSUMER_DEAD_LETTER_TOPIC", ""),
 		
 		// Logging configuration
 		LogLevel:  getEnv("LOG_LEVEL", "info"),
 		LogFormat: getEnv("LOG_FORMAT", "json"),
 		LogOutput: getEnv("LOG_OUTPUT", "console"),
+		LogSampleRate:   getEnvAsInt("LOG_SAMPLE_RATE", 1),
+		LogSampleDemote: getEnvAsBool("LOG_SAMPLE_DEMOTE", false),
+
+		MetricsScrapeInterval: getEnvAsPositiveDuration("METRICS_SCRAPE_INTERVAL", 5*time.Second),
 		
 		// Notification configuration
 		DefaultRetryAttempts: getEnvAsInt("DEFAULT_RETRY_ATTEMPTS", 3),
+		RetryBackoff:         getEnvAsDuration("NOTIFICATION_RETRY_BACKOFF", 30*time.Second),
+		RetryInterval:        getEnvAsPositiveDuration("NOTIFICATION_RETRY_INTERVAL", 15*time.Second),
+		RetryBatchSize:       getEnvAsPositiveInt("NOTIFICATION_RETRY_BATCH_SIZE", 100),
 		NotificationTTL:      getEnvAsDuration("NOTIFICATION_TTL", 24*time.Hour),
 		CleanupInterval:      getEnvAsDuration("CLEANUP_INTERVAL", 1*time.Hour),
+		DigestWindow:         getEnvAsDuration("NOTIFICATION_DIGEST_WINDOW", 1*time.Minute),
+		DigestTypes:          getEnvAsSlice("NOTIFICATION_DIGEST_TYPES", []string{}),
+		DigestFlushInterval:  getEnvAsPositiveDuration("NOTIFICATION_DIGEST_FLUSH_INTERVAL", 10*time.Second),
+		FallbackChains:       getEnvAsChains("NOTIFICATION_FALLBACK_CHAINS"),
+		FallbackMaxAttempts:  getEnvAsInt("NOTIFICATION_FALLBACK_MAX_ATTEMPTS", 3),
+		NotificationLimits:   getEnvAsLimits("NOTIFICATION_RATE_LIMITS"),
+		
+		// Job lock configuration
+		JobLockEnabled: getEnvAsBool("JOB_LOCK_ENABLED", true),
+		JobLockTTL:     getEnvAsPositiveDuration("JOB_LOCK_TTL", 30*time.Second),
+		
+		// Channel delivery configuration
+		WebhookURL:     getEnv("WEBHOOK_URL", ""),
+		WebhookTimeout: getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		SMTPHost:       getEnv("SMTP_HOST", ""),
+		SMTPPort:       getEnv("SMTP_PORT", "587"),
+		SMTPUsername:   getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:       getEnv("SMTP_FROM", "notifications@localhost"),
 		
 		// Rate limiting
 		RateLimitEnabled: getEnvAsBool("RATE_LIMIT_ENABLED", true),
@@ -77,9 +167,23 @@ func LoadConfig() *Config {
 		// Metrics configuration
 		MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
 		MetricsPath:    getEnv("METRICS_PATH", "/metrics"),
+		
+		// Tracing configuration
+		TracingEnabled:  getEnvAsBool("TRACING_ENABLED", false),
+		TracingEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+		TracingInsecure: getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		
+		// HTTP engine configuration
+		GinMode:        getGinModeFromEnv(getEnv("ENVIRONMENT", "development")),
+		TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}),
 	}
 }
 
+// IsDevelopment checks if the environment is development
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == "development"
+}
+
 // getEnv gets an environment variable with a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -98,6 +202,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsPositiveInt gets an environment variable as a positive integer.
+// Values that are not positive fall back to the default.
+func getEnvAsPositiveInt(key string, defaultValue int) int {
+	if value := getEnvAsInt(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 // getEnvAsBool gets an environment variable as boolean with a default value
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -117,3 +230,93 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsPositiveDuration gets an environment variable as a positive duration.
+// Values that are not positive fall back to the default.
+func getEnvAsPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := getEnvAsDuration(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice gets a comma-separated environment variable as a string slice with a default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// getEnvAsChains parses a comma-separated list of type:channel>channel entries, e.g.
+// "payment:push>email>in_app,*:email>in_app", into the channels listed per type.
+// Malformed entries are skipped.
+func getEnvAsChains(key string) map[string][]string {
+	chains := make(map[string][]string)
+	for _, entry := range getEnvAsSlice(key, nil) {
+		notificationType, chain, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(notificationType) == "" {
+			continue
+		}
+
+		var channels []string
+		for _, channel := range strings.Split(chain, ">") {
+			if channel = strings.TrimSpace(channel); channel != "" {
+				channels = append(channels, channel)
+			}
+		}
+		if len(channels) > 0 {
+			chains[strings.TrimSpace(notificationType)] = channels
+		}
+	}
+	return chains
+}
+
+// getEnvAsLimits parses a comma-separated list of type:max/window entries, e.g.
+// "marketing:3/24h,*:30/1m", into the limit per type. Malformed entries are skipped.
+func getEnvAsLimits(key string) map[string]NotificationLimit {
+	limits := make(map[string]NotificationLimit)
+	for _, entry := range getEnvAsSlice(key, nil) {
+		notificationType, limit, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(notificationType) == "" {
+			continue
+		}
+		rawMax, rawWindow, ok := strings.Cut(limit, "/")
+		if !ok {
+			continue
+		}
+
+		max, err := strconv.Atoi(strings.TrimSpace(rawMax))
+		if err != nil || max <= 0 {
+			continue
+		}
+		window, err := time.ParseDuration(strings.TrimSpace(rawWindow))
+		if err != nil || window <= 0 {
+			continue
+		}
+		limits[strings.TrimSpace(notificationType)] = NotificationLimit{Max: max, Window: window}
+	}
+	return limits
+}
+
+// getGinModeFromEnv determines the gin mode, defaulting to release mode in production
+func getGinModeFromEnv(environment string) string {
+	if mode := os.Getenv("GIN_MODE"); mode != "" {
+		return mode
+	}
+	if environment == "production" {
+		return "release"
+	}
+	return "debug"
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Delivery metrics for notification service
+var (
+	channelFallbackTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_channel_fallback_total",
+			Help: "Total number of deliveries retried on the next channel of a fallback chain after a channel failed",
+		},
+		[]string{"from_channel", "to_channel"},
+	)
+
+	notificationsThrottledTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notifications_throttled_total",
+			Help: "Total number of notifications dropped for exceeding the per-user rate limit of their type",
+		},
+		[]string{"type"},
+	)
+
+	notificationsRetriedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notifications_retried_total",
+			Help: "Total number of failed notifications retried automatically, by the status the retry left them in",
+		},
+		[]string{"status"},
+	)
+)
+
+// RecordChannelFallback records a delivery moving from a failed channel to the next one in its chain
+func RecordChannelFallback(fromChannel, toChannel string) {
+	channelFallbackTotal.WithLabelValues(fromChannel, toChannel).Inc()
+}
+
+// RecordNotificationThrottled records a notification dropped by the per-user rate limit
+func RecordNotificationThrottled(notificationType string) {
+	notificationsThrottledTotal.WithLabelValues(notificationType).Inc()
+}
+
+// RecordNotificationRetried records an automatic retry of a failed notification and its resulting status
+func RecordNotificationRetried(status string) {
+	notificationsRetriedTotal.WithLabelValues(status).Inc()
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HTTP metrics for notification service
+var (
+	httpRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_http_requests_total",
+			Help: "Total number of HTTP requests to notification service",
+		},
+		[]string{"method", "endpoint", "status_code"},
+	)
+
+	httpRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "notification_http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "endpoint"},
+	)
+)
+
+// RecordHTTPRequest records HTTP request metrics
+func RecordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(statusCode)).Inc()
+	httpRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Runtime and database pool metrics for notification service
+var (
+	memoryAllocBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_memory_alloc_bytes",
+			Help: "Current memory allocation in bytes",
+		},
+	)
+
+	memoryHeapBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_memory_heap_bytes",
+			Help: "Heap memory size in bytes",
+		},
+	)
+
+	goroutinesTotal = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_goroutines_total",
+			Help: "Current number of goroutines",
+		},
+	)
+
+	dbPoolOpenConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_db_pool_open_connections",
+			Help: "Number of established database connections, both in use and idle",
+		},
+	)
+
+	dbPoolInUseConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_db_pool_in_use_connections",
+			Help: "Number of database connections currently in use",
+		},
+	)
+
+	dbPoolIdleConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_db_pool_idle_connections",
+			Help: "Number of idle database connections",
+		},
+	)
+
+	dbPoolWaitCount = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "notification_db_pool_wait_count",
+			Help: "Total number of times a caller waited for a database connection",
+		},
+	)
+)
+
+// UpdateSystemMetrics updates runtime metrics and, when sqlDB is set, database pool metrics
+func UpdateSystemMetrics(sqlDB *sql.DB) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	memoryAllocBytes.Set(float64(memStats.Alloc))
+	memoryHeapBytes.Set(float64(memStats.HeapAlloc))
+	goroutinesTotal.Set(float64(runtime.NumGoroutine()))
+
+	if sqlDB != nil {
+		stats := sqlDB.Stats()
+		dbPoolOpenConnections.Set(float64(stats.OpenConnections))
+		dbPoolInUseConnections.Set(float64(stats.InUse))
+		dbPoolIdleConnections.Set(float64(stats.Idle))
+		dbPoolWaitCount.Set(float64(stats.WaitCount))
+	}
+}
+
+// StartSystemMetricsCollector calls UpdateSystemMetrics on every interval until the context is cancelled
+func StartSystemMetricsCollector(ctx context.Context, sqlDB *sql.DB, interval time.Duration) {
+	UpdateSystemMetrics(sqlDB)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			UpdateSystemMetrics(sqlDB)
+		}
+	}
+}
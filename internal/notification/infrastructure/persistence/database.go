@@ -7,9 +7,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
 	"obs-tools-usage/internal/notification/domain/entity"
 	"obs-tools-usage/internal/notification/infrastructure/config"
+	"obs-tools-usage/pkg/seed"
 )
 
 // Database wraps GORM database connection
@@ -26,11 +28,11 @@ func NewDatabase(cfg *config.Config, logger *logrus.Logger) (*Database, error) {
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode)
 
 	// Configure GORM logger
-	var gormLogger logger.Interface
+	var gormLogger gormlogger.Interface
 	if cfg.LogLevel == "debug" {
-		gormLogger = logger.Default.LogMode(logger.Info)
+		gormLogger = gormlogger.Default.LogMode(gormlogger.Info)
 	} else {
-		gormLogger = logger.Default.LogMode(logger.Silent)
+		gormLogger = gormlogger.Default.LogMode(gormlogger.Silent)
 	}
 
 	// Connect to database
@@ -77,25 +79,20 @@ func (d *Database) Migrate() error {
 		return fmt.Errorf("failed to migrate notification table: %w", err)
 	}
 
+	// Auto-migrate notification preference table
+	if err := d.DB.AutoMigrate(&entity.NotificationPreference{}); err != nil {
+		return fmt.Errorf("failed to migrate notification preference table: %w", err)
+	}
+
 	d.logger.Info("Database migrations completed successfully")
 	return nil
 }
 
-// SeedData seeds the database with initial data
-func (d *Database) SeedData() error {
+// SeedData seeds the database with sample notifications. Notifications that already exist
+// are skipped, so seeding can be re-run.
+func (d *Database) SeedData() (seed.Result, error) {
 	d.logger.Info("Seeding database with initial data...")
 
-	// Check if data already exists
-	var count int64
-	if err := d.DB.Model(&entity.Notification{}).Count(&count).Error; err != nil {
-		return fmt.Errorf("failed to count existing notifications: %w", err)
-	}
-
-	if count > 0 {
-		d.logger.Info("Database already has data, skipping seed")
-		return nil
-	}
-
 	// Create sample notifications
 	sampleNotifications := []*entity.Notification{
 		{
@@ -139,17 +136,22 @@ func (d *Database) SeedData() error {
 	}
 
 	// Insert sample data
+	var result seed.Result
 	for _, notification := range sampleNotifications {
-		if err := d.DB.Create(notification).Error; err != nil {
-			return fmt.Errorf("failed to create sample notification %s: %w", notification.ID, err)
+		created := d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+		if created.Error != nil {
+			return result, fmt.Errorf("failed to create sample notification %s: %w", notification.ID, created.Error)
+		}
+		if created.RowsAffected == 0 {
+			result.Skipped++
+			continue
 		}
+		result.Inserted++
 	}
 
-	d.logger.Info("Database seeded successfully")
-	return nil
-}
-
-// IsDevelopment checks if the environment is development
-func (c *config.Config) IsDevelopment() bool {
-	return c.Environment == "development"
+	d.logger.WithFields(logrus.Fields{
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+	}).Info("Database seeded successfully")
+	return result, nil
 }
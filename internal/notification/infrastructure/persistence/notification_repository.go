@@ -2,13 +2,11 @@ package persistence
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/gorm/clause"
 	"obs-tools-usage/internal/notification/domain/entity"
 	"obs-tools-usage/internal/notification/domain/repository"
 )
@@ -41,7 +39,7 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id string) (*entit
 	var notification entity.Notification
 	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&notification).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("notification not found")
+			return nil, repository.ErrNotificationNotFound
 		}
 		r.logger.WithError(err).Error("Failed to get notification by ID")
 		return nil, err
@@ -49,10 +47,45 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id string) (*entit
 	return &notification, nil
 }
 
+// GetBySourceEventID gets the notification created for an event
+func (r *NotificationRepository) GetBySourceEventID(ctx context.Context, sourceEventID string) (*entity.Notification, error) {
+	var notification entity.Notification
+	if err := r.db.WithContext(ctx).Where("source_event_id = ?", sourceEventID).First(&notification).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, repository.ErrNotificationNotFound
+		}
+		r.logger.WithError(err).Error("Failed to get notification by source event ID")
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// notificationOrder returns the ORDER BY clause of a notification list sort. Only whitelisted
+// fields reach the clause, anything else falls back to the default; ties are broken by id in
+// the same direction so rows sharing a timestamp, as bulk inserts do, keep their place across pages.
+func notificationOrder(sort string) string {
+	if !entity.ValidNotificationSort(sort) {
+		sort = entity.DefaultNotificationSort
+	}
+	field, desc := entity.NotificationSortField(sort)
+	if desc {
+		return field + " DESC, id DESC"
+	}
+	return field + ", id"
+}
+
+// digestItemStatuses are the statuses of notifications a digest stands for
+var digestItemStatuses = []entity.NotificationStatus{entity.NotificationStatusDigestPending, entity.NotificationStatusDigested}
+
+// inbox narrows a query to the notifications listed to their user, leaving out those a digest stands for
+func inbox(query *gorm.DB) *gorm.DB {
+	return query.Where("status NOT IN ?", digestItemStatuses)
+}
+
 // GetByUserID gets notifications by user ID
-func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*entity.Notification, error) {
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, limit, offset int, sort string) ([]*entity.Notification, error) {
 	var notifications []*entity.Notification
-	query := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC")
+	query := inbox(r.db.WithContext(ctx).Where("user_id = ?", userID)).Order(notificationOrder(sort))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -69,9 +102,9 @@ func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string,
 }
 
 // GetByUserIDAndStatus gets notifications by user ID and status
-func (r *NotificationRepository) GetByUserIDAndStatus(ctx context.Context, userID string, status entity.NotificationStatus, limit, offset int) ([]*entity.Notification, error) {
+func (r *NotificationRepository) GetByUserIDAndStatus(ctx context.Context, userID string, status entity.NotificationStatus, limit, offset int, sort string) ([]*entity.Notification, error) {
 	var notifications []*entity.Notification
-	query := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, status).Order("created_at DESC")
+	query := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, status).Order(notificationOrder(sort))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -88,9 +121,9 @@ func (r *NotificationRepository) GetByUserIDAndStatus(ctx context.Context, userI
 }
 
 // GetByUserIDAndType gets notifications by user ID and type
-func (r *NotificationRepository) GetByUserIDAndType(ctx context.Context, userID string, notificationType entity.NotificationType, limit, offset int) ([]*entity.Notification, error) {
+func (r *NotificationRepository) GetByUserIDAndType(ctx context.Context, userID string, notificationType entity.NotificationType, limit, offset int, sort string) ([]*entity.Notification, error) {
 	var notifications []*entity.Notification
-	query := r.db.WithContext(ctx).Where("user_id = ? AND type = ?", userID, notificationType).Order("created_at DESC")
+	query := inbox(r.db.WithContext(ctx).Where("user_id = ? AND type = ?", userID, notificationType)).Order(notificationOrder(sort))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -106,10 +139,36 @@ func (r *NotificationRepository) GetByUserIDAndType(ctx context.Context, userID
 	return notifications, nil
 }
 
+// GetByUserIDAndReadState gets notifications by user ID that are read or unread.
+// The filter is on read_at, which is covered by the (user_id, read_at) index.
+func (r *NotificationRepository) GetByUserIDAndReadState(ctx context.Context, userID string, read bool, limit, offset int, sort string) ([]*entity.Notification, error) {
+	var notifications []*entity.Notification
+	query := inbox(r.db.WithContext(ctx).Where("user_id = ?", userID))
+	if read {
+		query = query.Where("read_at IS NOT NULL")
+	} else {
+		query = query.Where("read_at IS NULL")
+	}
+	query = query.Order(notificationOrder(sort))
+	
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	
+	if err := query.Find(&notifications).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to get notifications by user ID and read state")
+		return nil, err
+	}
+	return notifications, nil
+}
+
 // GetUnreadByUserID gets unread notifications by user ID
-func (r *NotificationRepository) GetUnreadByUserID(ctx context.Context, userID string) ([]*entity.Notification, error) {
+func (r *NotificationRepository) GetUnreadByUserID(ctx context.Context, userID string, sort string) ([]*entity.Notification, error) {
 	var notifications []*entity.Notification
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND read_at IS NULL", userID).Order("created_at DESC").Find(&notifications).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Where("user_id = ? AND read_at IS NULL", userID)).Order(notificationOrder(sort)).Find(&notifications).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get unread notifications by user ID")
 		return nil, err
 	}
@@ -126,6 +185,71 @@ func (r *NotificationRepository) GetExpired(ctx context.Context) ([]*entity.Noti
 	return notifications, nil
 }
 
+// GetDueForRetry gets up to limit failed notifications whose scheduled retry is due at now, longest due first
+func (r *NotificationRepository) GetDueForRetry(ctx context.Context, now time.Time, limit int) ([]*entity.Notification, error) {
+	var notifications []*entity.Notification
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND next_retry_at IS NOT NULL AND next_retry_at <= ?", entity.NotificationStatusFailed, now).
+		Order("next_retry_at, id").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to get notifications due for retry")
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// GetDigestPending gets the notifications waiting for their digest, oldest first
+func (r *NotificationRepository) GetDigestPending(ctx context.Context) ([]*entity.Notification, error) {
+	var notifications []*entity.Notification
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", entity.NotificationStatusDigestPending).
+		Order("created_at, id").
+		Find(&notifications).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to get notifications pending a digest")
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// List gets a page of notifications of all users matching the filter in sort order, with the total number of matches
+func (r *NotificationRepository) List(ctx context.Context, filter repository.NotificationFilter, limit, offset int, sort string) ([]*entity.Notification, int64, error) {
+	var total int64
+	if err := r.filtered(ctx, filter).Count(&total).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to count notifications")
+		return nil, 0, err
+	}
+
+	var notifications []*entity.Notification
+	query := r.filtered(ctx, filter).Order(notificationOrder(sort))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&notifications).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to list notifications")
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// filtered returns a query over the notifications of all users matching the filter
+func (r *NotificationRepository) filtered(ctx context.Context, filter repository.NotificationFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&entity.Notification{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	return query
+}
+
 // Update updates a notification
 func (r *NotificationRepository) Update(ctx context.Context, notification *entity.Notification) error {
 	if err := r.db.WithContext(ctx).Save(notification).Error; err != nil {
@@ -138,32 +262,133 @@ func (r *NotificationRepository) Update(ctx context.Context, notification *entit
 // MarkAsRead marks a notification as read
 func (r *NotificationRepository) MarkAsRead(ctx context.Context, id string) error {
 	now := time.Now()
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("id = ?", id).Updates(map[string]interface{}{
+	result := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"read_at":   &now,
 		"status":    entity.NotificationStatusRead,
 		"updated_at": now,
-	}).Error; err != nil {
-		r.logger.WithError(err).Error("Failed to mark notification as read")
-		return err
+	})
+	if result.Error != nil {
+		r.logger.WithError(result.Error).Error("Failed to mark notification as read")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotificationNotFound
 	}
 	return nil
 }
 
-// MarkAllAsRead marks all notifications as read for a user
-func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) (int64, error) {
-	now := time.Now()
-	result := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Updates(map[string]interface{}{
-		"read_at":   &now,
-		"status":    entity.NotificationStatusRead,
-		"updated_at": now,
+// MarkAllAsRead marks all notifications as read for a user and returns the affected IDs
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID string) (int64, []string, error) {
+	var ids []string
+	var affected int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the unread rows so the selected IDs match what gets updated
+		if err := inbox(tx.Model(&entity.Notification{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND read_at IS NULL", userID)).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		result := tx.Model(&entity.Notification{}).Where("id IN ? AND read_at IS NULL", ids).Updates(map[string]interface{}{
+			"read_at":   &now,
+			"status":    entity.NotificationStatusRead,
+			"updated_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		return nil
 	})
-	
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to mark all notifications as read")
+		return 0, nil, err
+	}
+
+	return affected, ids, nil
+}
+
+// MarkAsReadForUser marks the given notifications as read in a single update, touching only rows owned by userID.
+// It returns the IDs owned by the user; already read notifications are included but left unchanged.
+func (r *NotificationRepository) MarkAsReadForUser(ctx context.Context, userID string, ids []string) ([]string, error) {
+	var owned []string
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Notification{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND user_id = ?", ids, userID).
+			Pluck("id", &owned).Error; err != nil {
+			return err
+		}
+
+		if len(owned) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		return tx.Model(&entity.Notification{}).Where("id IN ? AND user_id = ? AND read_at IS NULL", owned, userID).Updates(map[string]interface{}{
+			"read_at":    &now,
+			"status":     entity.NotificationStatusRead,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to batch mark notifications as read")
+		return nil, err
+	}
+
+	return owned, nil
+}
+
+// CreateDigest creates a digest notification and links the pending notifications it coalesces to it in one
+// transaction. It returns repository.ErrDigestItemsChanged, creating nothing, when any of them is no longer pending.
+func (r *NotificationRepository) CreateDigest(ctx context.Context, digest *entity.Notification, itemIDs []string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Notification{}).
+			Where("id IN ? AND status = ?", itemIDs, entity.NotificationStatusDigestPending).
+			Updates(map[string]interface{}{
+				"status":     entity.NotificationStatusDigested,
+				"digest_id":  digest.ID,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(itemIDs)) {
+			return repository.ErrDigestItemsChanged
+		}
+		return tx.Create(digest).Error
+	})
+	if err != nil && err != repository.ErrDigestItemsChanged {
+		r.logger.WithError(err).Error("Failed to create digest notification")
+	}
+	return err
+}
+
+// ReleaseFromDigest makes a notification pending a digest a regular pending notification again,
+// for a digest window that ended with only that notification
+func (r *NotificationRepository) ReleaseFromDigest(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Model(&entity.Notification{}).
+		Where("id = ? AND status = ?", id, entity.NotificationStatusDigestPending).
+		Updates(map[string]interface{}{
+			"status":     entity.NotificationStatusPending,
+			"updated_at": time.Now(),
+		})
 	if result.Error != nil {
-		r.logger.WithError(result.Error).Error("Failed to mark all notifications as read")
-		return 0, result.Error
+		r.logger.WithError(result.Error).Error("Failed to release notification from digest")
+		return result.Error
 	}
-	
-	return result.RowsAffected, nil
+	if result.RowsAffected == 0 {
+		return repository.ErrNotificationNotFound
+	}
+	return nil
 }
 
 // MarkAsSent marks a notification as sent
@@ -182,9 +407,11 @@ func (r *NotificationRepository) MarkAsSent(ctx context.Context, id string) erro
 
 // MarkAsDelivered marks a notification as delivered
 func (r *NotificationRepository) MarkAsDelivered(ctx context.Context, id string) error {
+	now := time.Now()
 	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":    entity.NotificationStatusDelivered,
-		"updated_at": time.Now(),
+		"delivered_at": &now,
+		"status":       entity.NotificationStatusDelivered,
+		"updated_at":   now,
 	}).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to mark notification as delivered")
 		return err
@@ -206,9 +433,13 @@ func (r *NotificationRepository) MarkAsFailed(ctx context.Context, id string) er
 
 // Delete deletes a notification
 func (r *NotificationRepository) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Delete(&entity.Notification{}, "id = ?", id).Error; err != nil {
-		r.logger.WithError(err).Error("Failed to delete notification")
-		return err
+	result := r.db.WithContext(ctx).Delete(&entity.Notification{}, "id = ?", id)
+	if result.Error != nil {
+		r.logger.WithError(result.Error).Error("Failed to delete notification")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotificationNotFound
 	}
 	return nil
 }
@@ -222,6 +453,33 @@ func (r *NotificationRepository) DeleteByUserID(ctx context.Context, userID stri
 	return nil
 }
 
+// DeleteForUser deletes the given notifications in a single statement, touching only rows owned by userID.
+// It returns the IDs that were deleted.
+func (r *NotificationRepository) DeleteForUser(ctx context.Context, userID string, ids []string) ([]string, error) {
+	var owned []string
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Notification{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND user_id = ?", ids, userID).
+			Pluck("id", &owned).Error; err != nil {
+			return err
+		}
+
+		if len(owned) == 0 {
+			return nil
+		}
+
+		return tx.Delete(&entity.Notification{}, "id IN ? AND user_id = ?", owned, userID).Error
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to batch delete notifications")
+		return nil, err
+	}
+
+	return owned, nil
+}
+
 // DeleteExpired deletes expired notifications
 func (r *NotificationRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	result := r.db.WithContext(ctx).Delete(&entity.Notification{}, "expires_at IS NOT NULL AND expires_at < ?", time.Now())
@@ -237,13 +495,13 @@ func (r *NotificationRepository) GetStatsByUserID(ctx context.Context, userID st
 	stats := &entity.NotificationStats{}
 	
 	// Get total notifications
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ?", userID).Count(&stats.TotalNotifications).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ?", userID)).Count(&stats.TotalNotifications).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get total notifications count")
 		return nil, err
 	}
 	
 	// Get unread notifications
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&stats.UnreadNotifications).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)).Count(&stats.UnreadNotifications).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get unread notifications count")
 		return nil, err
 	}
@@ -272,7 +530,7 @@ func (r *NotificationRepository) GetStatsByUserID(ctx context.Context, userID st
 		Type  string
 		Count int64
 	}
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Select("type, count(*) as count").Where("user_id = ?", userID).Group("type").Scan(&typeStats).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Select("type, count(*) as count").Where("user_id = ?", userID)).Group("type").Scan(&typeStats).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get notifications by type")
 		return nil, err
 	}
@@ -286,7 +544,7 @@ func (r *NotificationRepository) GetStatsByUserID(ctx context.Context, userID st
 		Channel string
 		Count   int64
 	}
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Select("channel, count(*) as count").Where("user_id = ?", userID).Group("channel").Scan(&channelStats).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Select("channel, count(*) as count").Where("user_id = ?", userID)).Group("channel").Scan(&channelStats).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get notifications by channel")
 		return nil, err
 	}
@@ -300,7 +558,7 @@ func (r *NotificationRepository) GetStatsByUserID(ctx context.Context, userID st
 		Status string
 		Count  int64
 	}
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Select("status, count(*) as count").Where("user_id = ?", userID).Group("status").Scan(&statusStats).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Select("status, count(*) as count").Where("user_id = ?", userID)).Group("status").Scan(&statusStats).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get notifications by status")
 		return nil, err
 	}
@@ -314,7 +572,7 @@ func (r *NotificationRepository) GetStatsByUserID(ctx context.Context, userID st
 // GetCountByUserID gets notification count by user ID
 func (r *NotificationRepository) GetCountByUserID(ctx context.Context, userID string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ?", userID)).Count(&count).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get notification count by user ID")
 		return 0, err
 	}
@@ -324,13 +582,31 @@ func (r *NotificationRepository) GetCountByUserID(ctx context.Context, userID st
 // GetUnreadCountByUserID gets unread notification count by user ID
 func (r *NotificationRepository) GetUnreadCountByUserID(ctx context.Context, userID string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error; err != nil {
+	if err := inbox(r.db.WithContext(ctx).Model(&entity.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)).Count(&count).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get unread notification count by user ID")
 		return 0, err
 	}
 	return count, nil
 }
 
+// GetCountsByStatus gets the number of notifications of all users per status, among those matching the filter
+func (r *NotificationRepository) GetCountsByStatus(ctx context.Context, filter repository.NotificationFilter) (map[string]int64, error) {
+	var statusStats []struct {
+		Status string
+		Count  int64
+	}
+	if err := r.filtered(ctx, filter).Select("status, count(*) as count").Group("status").Scan(&statusStats).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to get notification counts by status")
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(statusStats))
+	for _, stat := range statusStats {
+		counts[stat.Status] = stat.Count
+	}
+	return counts, nil
+}
+
 // GetCountByStatus gets notification count by status
 func (r *NotificationRepository) GetCountByStatus(ctx context.Context, status entity.NotificationStatus) (int64, error) {
 	var count int64
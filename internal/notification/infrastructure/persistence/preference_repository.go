@@ -0,0 +1,61 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/domain/repository"
+)
+
+// PreferenceRepository implements the notification preference repository interface
+type PreferenceRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewPreferenceRepository creates a new notification preference repository
+func NewPreferenceRepository(db *gorm.DB, logger *logrus.Logger) repository.PreferenceRepository {
+	return &PreferenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetByUserID gets the preferences of a user
+func (r *PreferenceRepository) GetByUserID(ctx context.Context, userID string) (*entity.NotificationPreference, error) {
+	var preference entity.NotificationPreference
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preference).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("notification preference not found")
+		}
+		r.logger.WithError(err).Error("Failed to get notification preference")
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// Upsert creates or replaces the preferences of a user
+func (r *PreferenceRepository) Upsert(ctx context.Context, preference *entity.NotificationPreference) error {
+	if err := r.db.WithContext(ctx).Save(preference).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to save notification preference")
+		return err
+	}
+	return nil
+}
+
+// Delete removes the preferences of a user, reverting them to the defaults
+func (r *PreferenceRepository) Delete(ctx context.Context, userID string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.NotificationPreference{})
+	if result.Error != nil {
+		r.logger.WithError(result.Error).Error("Failed to delete notification preference")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("notification preference not found")
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,15 +14,26 @@ import (
 	"obs-tools-usage/internal/notification/application/handler"
 	"obs-tools-usage/internal/notification/application/query"
 	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/domain/repository"
+	"obs-tools-usage/internal/notification/domain/service"
 	"obs-tools-usage/internal/notification/infrastructure/metrics"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/pagination"
+	"obs-tools-usage/pkg/respond"
 )
 
+// defaultNotificationsPageSize is used when a notification list is requested without a limit
+const defaultNotificationsPageSize = 10
+
 // NotificationHandler handles HTTP requests for notifications
 type NotificationHandler struct {
 	commandHandler *handler.CommandHandler
 	queryHandler   *handler.QueryHandler
 	metrics        *metrics.NotificationMetrics
 	logger         *logrus.Logger
+	health         *health.Checker
+	maxPageSize    int // Largest limit accepted by the list endpoints
 }
 
 // NewNotificationHandler creates a new notification handler
@@ -29,12 +42,18 @@ func NewNotificationHandler(
 	queryHandler *handler.QueryHandler,
 	metrics *metrics.NotificationMetrics,
 	logger *logrus.Logger,
+	checker *health.Checker,
+	maxPageSize int,
 ) *NotificationHandler {
+	registerValidators()
+
 	return &NotificationHandler{
 		commandHandler: commandHandler,
 		queryHandler:   queryHandler,
 		metrics:        metrics,
 		logger:         logger,
+		health:         checker,
+		maxPageSize:    maxPageSize,
 	}
 }
 
@@ -48,28 +67,34 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 	var req dto.CreateNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to bind create notification request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	// Convert to command
 	cmd := command.CreateNotificationCommand{
-		UserID:     req.UserID,
-		Title:      req.Title,
-		Message:    req.Message,
-		Type:       req.Type,
-		Priority:   req.Priority,
-		Channel:    req.Channel,
-		TemplateID: req.TemplateID,
-		Data:       req.Data,
-		ExpiresAt:  req.ExpiresAt,
+		UserID:        req.UserID,
+		Title:         req.Title,
+		Message:       req.Message,
+		Type:          req.Type,
+		Priority:      req.Priority,
+		Channel:       req.Channel,
+		TemplateID:    req.TemplateID,
+		Data:          req.Data,
+		ExpiresAt:     req.ExpiresAt,
+		Attachments:   req.Attachments,
+		SourceEventID: req.SourceEventID,
 	}
 
 	// Handle command
 	response, err := h.commandHandler.HandleCreateNotification(cmd)
+	if errors.Is(err, service.ErrInvalidAttachments) {
+		respond.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to create notification")
 		return
 	}
 
@@ -82,14 +107,14 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 		)
 	}
 
-	c.JSON(http.StatusCreated, response)
+	respond.OK(c, http.StatusCreated, response)
 }
 
 // GetNotification handles GET /notifications/:id
 func (h *NotificationHandler) GetNotification(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification ID is required"})
+		respond.Error(c, http.StatusBadRequest, "Notification ID is required")
 		return
 	}
 
@@ -100,30 +125,30 @@ func (h *NotificationHandler) GetNotification(c *gin.Context) {
 	response, err := h.queryHandler.HandleGetNotification(q)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification"})
+		respondNotificationError(c, err, response, "Failed to get notification")
 		return
 	}
 
 	if !response.Success {
-		c.JSON(http.StatusNotFound, response)
+		respond.Error(c, http.StatusNotFound, response.Message)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // UpdateNotification handles PUT /notifications/:id
 func (h *NotificationHandler) UpdateNotification(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification ID is required"})
+		respond.Error(c, http.StatusBadRequest, "Notification ID is required")
 		return
 	}
 
 	var req dto.UpdateNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to bind update notification request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
@@ -139,18 +164,18 @@ func (h *NotificationHandler) UpdateNotification(c *gin.Context) {
 	response, err := h.commandHandler.HandleUpdateNotification(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		respondNotificationError(c, err, response, "Failed to update notification")
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // SendNotification handles POST /notifications/:id/send
 func (h *NotificationHandler) SendNotification(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification ID is required"})
+		respond.Error(c, http.StatusBadRequest, "Notification ID is required")
 		return
 	}
 
@@ -161,7 +186,7 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 	response, err := h.commandHandler.HandleSendNotification(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to send notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notification"})
+		respondNotificationError(c, err, response, "Failed to send notification")
 		return
 	}
 
@@ -173,14 +198,14 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 		)
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // MarkAsRead handles POST /notifications/:id/read
 func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification ID is required"})
+		respond.Error(c, http.StatusBadRequest, "Notification ID is required")
 		return
 	}
 
@@ -191,7 +216,7 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 	response, err := h.commandHandler.HandleMarkAsRead(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to mark notification as read")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+		respondNotificationError(c, err, response, "Failed to mark notification as read")
 		return
 	}
 
@@ -200,7 +225,7 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 		h.metrics.IncrementNotificationRead(response.Notification.UserID)
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // MarkAllAsRead handles POST /notifications/read-all
@@ -208,7 +233,7 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 	var req dto.MarkAllAsReadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to bind mark all as read request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
@@ -219,18 +244,58 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 	response, err := h.commandHandler.HandleMarkAllAsRead(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to mark all notifications as read")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark all notifications as read"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to mark all notifications as read")
+		return
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// BatchUpdate handles POST /notifications/batch
+func (h *NotificationHandler) BatchUpdate(c *gin.Context) {
+	var req dto.BatchUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to bind batch update request")
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
+		return
+	}
+
+	// Convert to command
+	cmd := command.BatchUpdateCommand{
+		UserID: req.UserID,
+		IDs:    req.IDs,
+		Action: req.Action,
+	}
+
+	// Handle command
+	response, err := h.commandHandler.HandleBatchUpdate(cmd)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to apply batch update")
+		if strings.Contains(err.Error(), "invalid") {
+			respond.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respond.Error(c, http.StatusInternalServerError, "Failed to apply batch update")
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	// Update metrics
+	if req.Action == dto.BatchActionRead {
+		for _, result := range response.Results {
+			if result.Success {
+				h.metrics.IncrementNotificationRead(req.UserID)
+			}
+		}
+	}
+
+	respond.OK(c, http.StatusOK, response)
 }
 
 // DeleteNotification handles DELETE /notifications/:id
 func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification ID is required"})
+		respond.Error(c, http.StatusBadRequest, "Notification ID is required")
 		return
 	}
 
@@ -241,7 +306,7 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 	response, err := h.commandHandler.HandleDeleteNotification(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification"})
+		respondNotificationError(c, err, response, "Failed to delete notification")
 		return
 	}
 
@@ -250,22 +315,56 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 		h.metrics.IncrementNotificationDeleted("unknown") // We don't have type info here
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
+}
+
+// respondNotificationError responds 404 with the use case's message when the notification
+// does not exist and 500 for any other failure
+func respondNotificationError(c *gin.Context, err error, response *dto.NotificationResponse, message string) {
+	if errors.Is(err, repository.ErrNotificationNotFound) && response != nil {
+		respond.Error(c, http.StatusNotFound, response.Message)
+		return
+	}
+	respond.Error(c, http.StatusInternalServerError, message)
+}
+
+// parseNotificationSort reads the sort parameter of a notification list, responding 400 when it
+// names a field lists cannot be ordered by
+func parseNotificationSort(c *gin.Context) (string, bool) {
+	sort := c.Query("sort")
+	if !entity.ValidNotificationSort(sort) {
+		respond.Error(c, http.StatusBadRequest, "invalid sort field: "+sort)
+		return "", false
+	}
+	return sort, true
 }
 
 // GetNotifications handles GET /notifications
 func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	// Parse query parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset := pagination.Parse(c.Request.URL.Query(), defaultNotificationsPageSize, h.maxPageSize)
 	status := c.Query("status")
 	notificationType := c.Query("type")
+	sort, ok := parseNotificationSort(c)
+	if !ok {
+		return
+	}
+
+	var read *bool
+	if raw := c.Query("read"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, "read must be true or false")
+			return
+		}
+		read = &value
+	}
 
 	// Convert to query
 	q := query.GetNotificationsByUserQuery{
@@ -274,54 +373,93 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 		Offset: offset,
 		Status: status,
 		Type:   notificationType,
+		Read:   read,
+		Sort:   sort,
 	}
 
 	// Handle query
 	response, err := h.queryHandler.HandleGetNotificationsByUser(q)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get notifications")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to get notifications")
+		return
+	}
+	response.Pagination = pagination.NewPage(c.Request.URL, response.Total, limit, offset)
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// ListNotifications handles GET /notifications/admin, listing notifications of all users
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	// Parse query parameters
+	limit, offset := pagination.Parse(c.Request.URL.Query(), defaultNotificationsPageSize, h.maxPageSize)
+	sort, ok := parseNotificationSort(c)
+	if !ok {
+		return
+	}
+
+	// Convert to query
+	q := query.ListNotificationsQuery{
+		Status:  c.Query("status"),
+		Type:    c.Query("type"),
+		Channel: c.Query("channel"),
+		Limit:   limit,
+		Offset:  offset,
+		Sort:    sort,
+	}
+
+	// Handle query
+	response, err := h.queryHandler.HandleListNotifications(q)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list notifications")
+		respond.Error(c, http.StatusInternalServerError, "Failed to list notifications")
 		return
 	}
+	response.Pagination = pagination.NewPage(c.Request.URL, response.Total, limit, offset)
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetUnreadNotifications handles GET /notifications/unread
 func (h *NotificationHandler) GetUnreadNotifications(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
 	// Parse query parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, offset := pagination.Parse(c.Request.URL.Query(), defaultNotificationsPageSize, h.maxPageSize)
+	sort, ok := parseNotificationSort(c)
+	if !ok {
+		return
+	}
 
 	// Convert to query
 	q := query.GetUnreadNotificationsQuery{
 		UserID: userID,
 		Limit:  limit,
 		Offset: offset,
+		Sort:   sort,
 	}
 
 	// Handle query
 	response, err := h.queryHandler.HandleGetUnreadNotifications(q)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get unread notifications")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread notifications"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to get unread notifications")
 		return
 	}
+	response.Pagination = pagination.NewPage(c.Request.URL, response.Total, limit, offset)
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetNotificationStats handles GET /notifications/stats
 func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
@@ -332,11 +470,11 @@ func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
 	response, err := h.queryHandler.HandleGetNotificationStats(q)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get notification stats")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification stats"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to get notification stats")
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // BulkCreateNotification handles POST /notifications/bulk
@@ -344,7 +482,7 @@ func (h *NotificationHandler) BulkCreateNotification(c *gin.Context) {
 	var req dto.BulkCreateNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to bind bulk create notification request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -365,7 +503,7 @@ func (h *NotificationHandler) BulkCreateNotification(c *gin.Context) {
 	response, err := h.commandHandler.HandleBulkCreateNotification(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to bulk create notifications")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to bulk create notifications"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to bulk create notifications")
 		return
 	}
 
@@ -380,7 +518,7 @@ func (h *NotificationHandler) BulkCreateNotification(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusCreated, response)
+	respond.OK(c, http.StatusCreated, response)
 }
 
 // ScheduleNotification handles POST /notifications/schedule
@@ -388,7 +526,7 @@ func (h *NotificationHandler) ScheduleNotification(c *gin.Context) {
 	var req dto.ScheduleNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to bind schedule notification request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
@@ -410,7 +548,7 @@ func (h *NotificationHandler) ScheduleNotification(c *gin.Context) {
 	response, err := h.commandHandler.HandleScheduleNotification(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to schedule notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule notification"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to schedule notification")
 		return
 	}
 
@@ -423,14 +561,14 @@ func (h *NotificationHandler) ScheduleNotification(c *gin.Context) {
 		)
 	}
 
-	c.JSON(http.StatusCreated, response)
+	respond.OK(c, http.StatusCreated, response)
 }
 
 // RetryFailedNotification handles POST /notifications/:id/retry
 func (h *NotificationHandler) RetryFailedNotification(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Notification ID is required"})
+		respond.Error(c, http.StatusBadRequest, "Notification ID is required")
 		return
 	}
 
@@ -441,11 +579,11 @@ func (h *NotificationHandler) RetryFailedNotification(c *gin.Context) {
 	response, err := h.commandHandler.HandleRetryFailedNotification(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to retry notification")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry notification"})
+		respondNotificationError(c, err, response, "Failed to retry notification")
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // CleanupExpiredNotifications handles POST /notifications/cleanup
@@ -457,18 +595,112 @@ func (h *NotificationHandler) CleanupExpiredNotifications(c *gin.Context) {
 	response, err := h.commandHandler.HandleCleanupExpiredNotifications(cmd)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to cleanup expired notifications")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup expired notifications"})
+		respond.Error(c, http.StatusInternalServerError, "Failed to cleanup expired notifications")
+		return
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// GetPreferences handles GET /notifications/preferences/:user_id
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	// Convert to query
+	q := query.GetPreferenceQuery{UserID: userID}
+
+	// Handle query
+	response, err := h.queryHandler.HandleGetPreference(q)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get notification preferences")
+		respond.Error(c, http.StatusInternalServerError, "Failed to get notification preferences")
+		return
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// UpdatePreferences handles PUT /notifications/preferences/:user_id
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	var req dto.UpdatePreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to bind update preference request")
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
+		return
+	}
+
+	// Convert to command
+	cmd := command.UpdatePreferenceCommand{
+		UserID:          userID,
+		EmailEnabled:    req.EmailEnabled,
+		SMSEnabled:      req.SMSEnabled,
+		PushEnabled:     req.PushEnabled,
+		WebhookEnabled:  req.WebhookEnabled,
+		MarketingOptIn:  req.MarketingOptIn,
+		MutedTypes:      req.MutedTypes,
+		QuietHoursStart: req.QuietHoursStart,
+		QuietHoursEnd:   req.QuietHoursEnd,
+		Timezone:        req.Timezone,
+	}
+
+	// Handle command
+	response, err := h.commandHandler.HandleUpdatePreference(cmd)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update notification preferences")
+		respond.Error(c, http.StatusInternalServerError, "Failed to update notification preferences")
+		return
+	}
+
+	if !response.Success {
+		respond.Error(c, http.StatusBadRequest, response.Message)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// DeletePreferences handles DELETE /notifications/preferences/:user_id
+func (h *NotificationHandler) DeletePreferences(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	// Convert to command
+	cmd := command.DeletePreferenceCommand{UserID: userID}
+
+	// Handle command
+	response, err := h.commandHandler.HandleDeletePreference(cmd)
+	if err != nil {
+		respond.Error(c, http.StatusNotFound, response.Message)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
-// HealthCheck handles GET /health
+// HealthCheck handles GET /health, reporting the database status
 func (h *NotificationHandler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"timestamp": time.Now().UTC(),
-		"service":   "notification-service",
-	})
+	h.health.ServeHealth(c)
+}
+
+// ReadinessCheck handles GET /ready, reporting whether the database is reachable
+func (h *NotificationHandler) ReadinessCheck(c *gin.Context) {
+	h.health.ServeReady(c)
+}
+
+// LivenessCheck handles GET /live, reporting only that the process is running
+func (h *NotificationHandler) LivenessCheck(c *gin.Context) {
+	h.health.ServeLive(c)
 }
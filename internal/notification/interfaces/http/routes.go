@@ -3,13 +3,23 @@ package http
 import (
 	"github.com/gin-gonic/gin"
 	"obs-tools-usage/internal/notification/application/handler"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/middleware"
 )
 
-// SetupRoutes configures all notification routes
+// legacyAPIPrefix is where the notification API was mounted before it moved under the configured prefix
+const legacyAPIPrefix = "/api/v1"
+
+// SetupRoutes configures all notification routes. The API is mounted under apiPrefix, such as /v1,
+// and under the legacy /api/v1 prefix as a deprecated alias.
 func SetupRoutes(
 	r *gin.Engine,
 	commandHandler *handler.CommandHandler,
 	queryHandler *handler.QueryHandler,
+	checker *health.Checker,
+	maxPageSize int,
+	adminToken string,
+	apiPrefix string,
 ) {
 	// Create notification handler
 	notificationHandler := NewNotificationHandler(
@@ -17,41 +27,57 @@ func SetupRoutes(
 		queryHandler,
 		nil, // metrics will be injected later
 		nil, // logger will be injected later
+		checker,
+		maxPageSize,
 	)
 
-	// API v1 routes
-	v1 := r.Group("/api/v1")
+	registerNotificationRoutes(r.Group(apiPrefix), notificationHandler, adminToken)
+	if apiPrefix != legacyAPIPrefix {
+		// Routes used to live under /api/v1; keep them working during the deprecation window
+		registerNotificationRoutes(r.Group(legacyAPIPrefix, middleware.DeprecatedAlias(legacyAPIPrefix, apiPrefix)), notificationHandler, adminToken)
+	}
+	
+	// Root health checks
+	r.GET("/health", notificationHandler.HealthCheck)
+	r.GET("/ready", notificationHandler.ReadinessCheck)
+	r.GET("/live", notificationHandler.LivenessCheck)
+}
+
+// registerNotificationRoutes registers the notification API on routes
+func registerNotificationRoutes(routes *gin.RouterGroup, notificationHandler *NotificationHandler, adminToken string) {
+	// Notification routes
+	notifications := routes.Group("/notifications")
 	{
-		// Notification routes
-		notifications := v1.Group("/notifications")
-		{
-			// CRUD operations
-			notifications.POST("", notificationHandler.CreateNotification)
-			notifications.GET("/:id", notificationHandler.GetNotification)
-			notifications.PUT("/:id", notificationHandler.UpdateNotification)
-			notifications.DELETE("/:id", notificationHandler.DeleteNotification)
-			
-			// Notification actions
-			notifications.POST("/:id/send", notificationHandler.SendNotification)
-			notifications.POST("/:id/read", notificationHandler.MarkAsRead)
-			notifications.POST("/:id/retry", notificationHandler.RetryFailedNotification)
-			
-			// Bulk operations
-			notifications.POST("/read-all", notificationHandler.MarkAllAsRead)
-			notifications.POST("/bulk", notificationHandler.BulkCreateNotification)
-			notifications.POST("/schedule", notificationHandler.ScheduleNotification)
-			notifications.POST("/cleanup", notificationHandler.CleanupExpiredNotifications)
-			
-			// Query operations
-			notifications.GET("", notificationHandler.GetNotifications)
-			notifications.GET("/unread", notificationHandler.GetUnreadNotifications)
-			notifications.GET("/stats", notificationHandler.GetNotificationStats)
-		}
+		// CRUD operations
+		notifications.POST("", notificationHandler.CreateNotification)
+		notifications.GET("/:id", notificationHandler.GetNotification)
+		notifications.PUT("/:id", notificationHandler.UpdateNotification)
+		notifications.DELETE("/:id", notificationHandler.DeleteNotification)
+		
+		// Notification actions
+		notifications.POST("/:id/send", notificationHandler.SendNotification)
+		notifications.POST("/:id/read", notificationHandler.MarkAsRead)
+		notifications.POST("/:id/retry", notificationHandler.RetryFailedNotification)
+		
+		// Bulk operations
+		notifications.POST("/read-all", notificationHandler.MarkAllAsRead)
+		notifications.POST("/batch", notificationHandler.BatchUpdate)
+		notifications.POST("/bulk", notificationHandler.BulkCreateNotification)
+		notifications.POST("/schedule", notificationHandler.ScheduleNotification)
+		notifications.POST("/cleanup", notificationHandler.CleanupExpiredNotifications)
 		
-		// Health check
-		v1.GET("/health", notificationHandler.HealthCheck)
+		// Query operations
+		notifications.GET("", notificationHandler.GetNotifications)
+		notifications.GET("/unread", notificationHandler.GetUnreadNotifications)
+		notifications.GET("/stats", notificationHandler.GetNotificationStats)
+		notifications.GET("/admin", middleware.AdminAuth(adminToken), notificationHandler.ListNotifications)
+
+		// Preference operations
+		notifications.GET("/preferences/:user_id", notificationHandler.GetPreferences)
+		notifications.PUT("/preferences/:user_id", notificationHandler.UpdatePreferences)
+		notifications.DELETE("/preferences/:user_id", notificationHandler.DeletePreferences)
 	}
 	
-	// Root health check
-	r.GET("/health", notificationHandler.HealthCheck)
+	// Health check
+	routes.GET("/health", notificationHandler.HealthCheck)
 }
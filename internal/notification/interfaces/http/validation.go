@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"obs-tools-usage/internal/notification/domain/entity"
+	"obs-tools-usage/internal/notification/domain/service"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/respond"
+)
+
+var registerValidatorsOnce sync.Once
+
+// FieldError describes a single invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// registerValidators adds the notification enum and time validators to gin's binding engine.
+// It is safe to call from every handler constructor; registration happens once.
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		// Report fields by their JSON name so clients can map errors back to the payload
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" || name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		domain := service.NewNotificationDomainService()
+		_ = v.RegisterValidation("notification_type", func(fl validator.FieldLevel) bool {
+			return domain.IsValidNotificationType(entity.NotificationType(fl.Field().String()))
+		})
+		_ = v.RegisterValidation("notification_priority", func(fl validator.FieldLevel) bool {
+			return domain.IsValidNotificationPriority(entity.NotificationPriority(fl.Field().String()))
+		})
+		_ = v.RegisterValidation("notification_channel", func(fl validator.FieldLevel) bool {
+			return domain.IsValidNotificationChannel(entity.NotificationChannel(fl.Field().String()))
+		})
+		_ = v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+			t, ok := fl.Field().Interface().(time.Time)
+			return ok && t.After(time.Now())
+		})
+	})
+}
+
+// respondBindError writes a bind error; validation failures are answered with a 400 listing each invalid field
+func respondBindError(c *gin.Context, err error) {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
+		return
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldErr.Field(),
+			Message: validationMessage(fieldErr),
+		})
+	}
+
+	respond.ErrorWithDetails(c, http.StatusBadRequest, respond.CodeValidation, "Request validation failed", gin.H{"fields": fields})
+}
+
+// validationMessage returns a human readable message for a failed validation tag
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "notification_type":
+		return "must be one of info, warning, error, success, payment, order, system, marketing"
+	case "notification_priority":
+		return "must be one of low, normal, high, urgent"
+	case "notification_channel":
+		return "must be one of email, sms, push, in_app, webhook"
+	case "future":
+		return "must be in the future"
+	default:
+		return "failed " + fieldErr.Tag() + " validation"
+	}
+}
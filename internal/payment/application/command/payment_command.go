@@ -11,6 +11,7 @@ type CreatePaymentCommand struct {
 	Currency    string            `json:"currency"`
 	Description string            `json:"description"`
 	Metadata    map[string]string `json:"metadata"`
+	ProductIDs  []int             `json:"product_ids"` // Basket products to check out; empty checks out the whole basket
 }
 
 // ToDTO converts command to DTO
@@ -23,6 +24,7 @@ func (c *CreatePaymentCommand) ToDTO() dto.CreatePaymentRequest {
 		Currency:    c.Currency,
 		Description: c.Description,
 		Metadata:    c.Metadata,
+		ProductIDs:  c.ProductIDs,
 	}
 }
 
@@ -31,6 +33,7 @@ type UpdatePaymentCommand struct {
 	PaymentID string            `json:"payment_id" binding:"required"`
 	Status    string            `json:"status" binding:"required"`
 	Metadata  map[string]string `json:"metadata"`
+	Actor     string            `json:"-"` // Who requested the change, recorded in the audit log
 }
 
 // ToDTO converts command to DTO
@@ -45,6 +48,7 @@ func (c *UpdatePaymentCommand) ToDTO() dto.UpdatePaymentRequest {
 type ProcessPaymentCommand struct {
 	PaymentID  string `json:"payment_id" binding:"required"`
 	ProviderID string `json:"provider_id"`
+	Actor      string `json:"-"` // Who requested the change, recorded in the audit log
 }
 
 // ToDTO converts command to DTO
@@ -60,6 +64,7 @@ type RefundPaymentCommand struct {
 	PaymentID string  `json:"payment_id" binding:"required"`
 	Amount    float64 `json:"amount"`
 	Reason    string  `json:"reason"`
+	Actor     string  `json:"-"` // Who requested the change, recorded in the audit log
 }
 
 // ToDTO converts command to DTO
@@ -74,6 +79,7 @@ func (c *RefundPaymentCommand) ToDTO() dto.RefundPaymentRequest {
 // CancelPaymentCommand represents a command to cancel a payment
 type CancelPaymentCommand struct {
 	PaymentID string `json:"payment_id" binding:"required"`
+	Actor     string `json:"-"` // Who requested the change, recorded in the audit log
 }
 
 // ToDTO converts command to DTO
@@ -83,9 +89,16 @@ func (c *CancelPaymentCommand) ToDTO() dto.CancelPaymentRequest {
 	}
 }
 
+// CancelAllPaymentsCommand represents a command to cancel every pending payment of a user
+type CancelAllPaymentsCommand struct {
+	UserID string `json:"user_id" binding:"required"`
+	Actor  string `json:"-"` // Who requested the change, recorded in the audit log
+}
+
 // RetryPaymentCommand represents a command to retry a payment
 type RetryPaymentCommand struct {
 	PaymentID string `json:"payment_id" binding:"required"`
+	Actor     string `json:"-"` // Who requested the change, recorded in the audit log
 }
 
 // ToDTO converts command to DTO
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"obs-tools-usage/pkg/pagination"
+)
 
 // CreatePaymentRequest represents the request payload for creating a payment
 type CreatePaymentRequest struct {
@@ -11,6 +15,7 @@ type CreatePaymentRequest struct {
 	Currency    string            `json:"currency"`
 	Description string            `json:"description"`
 	Metadata    map[string]string `json:"metadata"`
+	ProductIDs  []int             `json:"product_ids"` // Basket products to check out; empty checks out the whole basket
 }
 
 // UpdatePaymentRequest represents the request payload for updating a payment
@@ -32,16 +37,65 @@ type RefundPaymentRequest struct {
 	Reason    string  `json:"reason"`
 }
 
+// PaymentAuditEntryResponse represents a single payment status change in the audit trail
+type PaymentAuditEntryResponse struct {
+	FromStatus string            `json:"from_status"`
+	ToStatus   string            `json:"to_status"`
+	Actor      string            `json:"actor"`
+	Reason     string            `json:"reason"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// PaymentAuditLogResponse represents the chronological audit trail of a payment
+type PaymentAuditLogResponse struct {
+	PaymentID string                      `json:"payment_id"`
+	Entries   []PaymentAuditEntryResponse `json:"entries"`
+	Count     int                         `json:"count"`
+}
+
+// Timeline event types. Status changes are reported as payment_<new status>,
+// except a failed payment moved back to pending, which is payment_retried.
+const (
+	TimelineEventPaymentCreated = "payment_created"
+	TimelineEventPaymentRetried = "payment_retried"
+)
+
+// TimelineEventResponse is one entry of a user's payment timeline
+type TimelineEventResponse struct {
+	Type       string            `json:"type"`
+	Timestamp  time.Time         `json:"timestamp"`
+	PaymentID  string            `json:"payment_id"`
+	Amount     *float64          `json:"amount,omitempty"` // Payment amount when created, refunded amount on refunds
+	Currency   string            `json:"currency,omitempty"`
+	FromStatus string            `json:"from_status,omitempty"`
+	ToStatus   string            `json:"to_status,omitempty"`
+	Actor      string            `json:"actor,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+// UserTimelineResponse is a page of a user's payment activity, oldest first
+type UserTimelineResponse struct {
+	UserID     string                  `json:"user_id"`
+	Events     []TimelineEventResponse `json:"events"`
+	Count      int                     `json:"count"`
+	NextCursor string                  `json:"next_cursor,omitempty"` // Pass as ?after= to get the next page; empty on the last page
+}
+
 // PaymentItemResponse represents a payment item in response
 type PaymentItemResponse struct {
-	ID        string  `json:"id"`
-	ProductID int     `json:"product_id"`
-	Name      string  `json:"name"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
-	Subtotal  float64 `json:"subtotal"`
-	Category  string  `json:"category"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             string    `json:"id"`
+	ProductID      int       `json:"product_id"`
+	Name           string    `json:"name"`
+	Quantity       int       `json:"quantity"`
+	Price          float64   `json:"price"`
+	Subtotal       float64   `json:"subtotal"`
+	TaxRate        float64   `json:"tax_rate"`
+	TaxAmount      float64   `json:"tax_amount"`
+	DiscountAmount float64   `json:"discount_amount"`
+	Category       string    `json:"category"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 // PaymentResponse represents the response payload for payment operations
@@ -50,6 +104,8 @@ type PaymentResponse struct {
 	UserID      string                `json:"user_id"`
 	BasketID    string                `json:"basket_id"`
 	Amount      float64               `json:"amount"`
+	FeeAmount   float64               `json:"fee_amount"`
+	NetAmount   float64               `json:"net_amount"`
 	Currency    string                `json:"currency"`
 	Status      string                `json:"status"`
 	Method      string                `json:"method"`
@@ -62,16 +118,43 @@ type PaymentResponse struct {
 	UpdatedAt   time.Time             `json:"updated_at"`
 	ProcessedAt *time.Time            `json:"processed_at"`
 	ExpiresAt   *time.Time            `json:"expires_at"`
+	Attempts    int                   `json:"attempts"`
+	Partial     bool                  `json:"partial"`
+	// ExpiresInSeconds is the time left before a pending payment expires
+	ExpiresInSeconds *int64 `json:"expires_in_seconds,omitempty"`
+}
+
+// PaginatedPaymentsResponse represents a page of payments
+type PaginatedPaymentsResponse struct {
+	Payments   []*PaymentResponse `json:"payments"`
+	Total      int64              `json:"total"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+	Pagination *pagination.Page   `json:"pagination,omitempty"`
+}
+
+// CurrencyTotalResponse represents payment totals for a single currency
+type CurrencyTotalResponse struct {
+	Currency      string  `json:"currency"`
+	Payments      int64   `json:"payments"`
+	TotalAmount   float64 `json:"total_amount"`
+	AverageAmount float64 `json:"average_amount"`
+	TotalFees     float64 `json:"total_fees"`
+	NetAmount     float64 `json:"net_amount"`
 }
 
-// PaymentStatsResponse represents payment statistics response
+// PaymentStatsResponse represents payment statistics response.
+// TotalAmount and AverageAmount are expressed in Currency; when payments span several
+// currencies and no conversion was requested, Currency is empty and only ByCurrency is filled.
 type PaymentStatsResponse struct {
-	TotalPayments     int64   `json:"total_payments"`
-	TotalAmount       float64 `json:"total_amount"`
-	CompletedPayments int64   `json:"completed_payments"`
-	FailedPayments    int64   `json:"failed_payments"`
-	PendingPayments   int64   `json:"pending_payments"`
-	AverageAmount     float64 `json:"average_amount"`
+	TotalPayments     int64                   `json:"total_payments"`
+	TotalAmount       float64                 `json:"total_amount"`
+	CompletedPayments int64                   `json:"completed_payments"`
+	FailedPayments    int64                   `json:"failed_payments"`
+	PendingPayments   int64                   `json:"pending_payments"`
+	AverageAmount     float64                 `json:"average_amount"`
+	Currency          string                  `json:"currency,omitempty"`
+	ByCurrency        []CurrencyTotalResponse `json:"by_currency"`
 }
 
 // SuccessResponse represents a success response
@@ -80,32 +163,44 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
 // CancelPaymentRequest represents the request payload for cancelling a payment
 type CancelPaymentRequest struct {
 	PaymentID string `json:"payment_id" binding:"required"`
 }
 
+// CancelAllPaymentsResponse reports the outcome of cancelling every pending payment of a user
+type CancelAllPaymentsResponse struct {
+	UserID       string                  `json:"user_id"`
+	Cancelled    int                     `json:"cancelled"`
+	CancelledIDs []string                `json:"cancelled_ids"`
+	Failed       []CancelFailureResponse `json:"failed"`
+}
+
+// CancelFailureResponse describes a payment that could not be cancelled
+type CancelFailureResponse struct {
+	PaymentID string `json:"payment_id"`
+	Error     string `json:"error"`
+}
+
 // RetryPaymentRequest represents the request payload for retrying a payment
 type RetryPaymentRequest struct {
 	PaymentID string `json:"payment_id" binding:"required"`
 }
 
-// PaymentAnalyticsResponse represents payment analytics response
+// PaymentAnalyticsResponse represents payment analytics response.
+// GrossRevenue and MonthlyGrossRevenue are before provider fees, the net figures after them.
 type PaymentAnalyticsResponse struct {
-	TotalPayments     int64   `json:"total_payments"`
-	TotalRevenue      float64 `json:"total_revenue"`
-	SuccessRate       float64 `json:"success_rate"`
-	AverageAmount     float64 `json:"average_amount"`
-	TopPaymentMethod  string  `json:"top_payment_method"`
-	TopProvider       string  `json:"top_provider"`
-	DailyTransactions int64   `json:"daily_transactions"`
-	MonthlyRevenue    float64 `json:"monthly_revenue"`
+	TotalPayments       int64   `json:"total_payments"`
+	GrossRevenue        float64 `json:"gross_revenue"`
+	TotalFees           float64 `json:"total_fees"`
+	NetRevenue          float64 `json:"net_revenue"`
+	SuccessRate         float64 `json:"success_rate"`
+	AverageAmount       float64 `json:"average_amount"`
+	TopPaymentMethod    string  `json:"top_payment_method"`
+	TopProvider         string  `json:"top_provider"`
+	DailyTransactions   int64   `json:"daily_transactions"`
+	MonthlyGrossRevenue float64 `json:"monthly_gross_revenue"`
+	MonthlyNetRevenue   float64 `json:"monthly_net_revenue"`
 }
 
 // PaymentMethodsResponse represents payment methods response
@@ -120,22 +215,54 @@ type PaymentProvidersResponse struct {
 	Count     int      `json:"count"`
 }
 
-// PaymentSummaryResponse represents payment summary response
+// PaymentSummaryResponse represents payment summary response.
+// GrossRevenue, TotalFees, NetRevenue and AverageAmount are expressed in Currency; when revenue
+// spans several currencies and no conversion was requested, Currency is empty and only ByCurrency is filled.
 type PaymentSummaryResponse struct {
-	TotalPayments     int64   `json:"total_payments"`
-	TotalRevenue      float64 `json:"total_revenue"`
-	PendingPayments   int64   `json:"pending_payments"`
-	CompletedPayments int64   `json:"completed_payments"`
-	FailedPayments    int64   `json:"failed_payments"`
-	RefundedPayments  int64   `json:"refunded_payments"`
-	SuccessRate       float64 `json:"success_rate"`
-	AverageAmount     float64 `json:"average_amount"`
-}
-
-// HealthResponse represents a health check response
-type HealthResponse struct {
-	Service   string `json:"service"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Version   string `json:"version"`
+	TotalPayments     int64                   `json:"total_payments"`
+	GrossRevenue      float64                 `json:"gross_revenue"`
+	TotalFees         float64                 `json:"total_fees"`
+	NetRevenue        float64                 `json:"net_revenue"`
+	PendingPayments   int64                   `json:"pending_payments"`
+	CompletedPayments int64                   `json:"completed_payments"`
+	FailedPayments    int64                   `json:"failed_payments"`
+	RefundedPayments  int64                   `json:"refunded_payments"`
+	SuccessRate       float64                 `json:"success_rate"`
+	AverageAmount     float64                 `json:"average_amount"`
+	Currency          string                  `json:"currency,omitempty"`
+	ByCurrency        []CurrencyTotalResponse `json:"by_currency"`
+}
+
+
+// ReconcileMismatchResponse describes a stale payment whose local status disagreed with the provider,
+// or that could not be checked
+type ReconcileMismatchResponse struct {
+	PaymentID      string    `json:"payment_id"`
+	LocalStatus    string    `json:"local_status"`
+	ProviderStatus string    `json:"provider_status,omitempty"`
+	NewStatus      string    `json:"new_status,omitempty"`
+	Fixed          bool      `json:"fixed"`
+	Error          string    `json:"error,omitempty"`
+	CheckedAt      time.Time `json:"checked_at"`
+}
+
+// ReconcileRunResponse summarizes one reconciliation run
+type ReconcileRunResponse struct {
+	StartedAt  time.Time                   `json:"started_at"`
+	FinishedAt time.Time                   `json:"finished_at"`
+	Checked    int                         `json:"checked"`
+	Mismatches int                         `json:"mismatches"`
+	Fixed      int                         `json:"fixed"`
+	Errors     int                         `json:"errors"`
+	Details    []ReconcileMismatchResponse `json:"details"`
+}
+
+// ReconcileReportResponse summarizes the reconciliation runs since the service started
+type ReconcileReportResponse struct {
+	Runs       int                   `json:"runs"`
+	Checked    int                   `json:"checked"`
+	Mismatches int                   `json:"mismatches"`
+	Fixed      int                   `json:"fixed"`
+	Errors     int                   `json:"errors"`
+	LastRun    *ReconcileRunResponse `json:"last_run,omitempty"`
 }
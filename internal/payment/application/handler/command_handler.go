@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+
 	"obs-tools-usage/internal/payment/application/command"
 	"obs-tools-usage/internal/payment/application/dto"
 	"obs-tools-usage/internal/payment/application/usecase"
@@ -19,8 +21,9 @@ func NewCommandHandler(paymentUseCase *usecase.PaymentUseCase) *CommandHandler {
 }
 
 // HandleCreatePayment handles CreatePaymentCommand
-func (h *CommandHandler) HandleCreatePayment(cmd command.CreatePaymentCommand) (*dto.PaymentResponse, error) {
+func (h *CommandHandler) HandleCreatePayment(ctx context.Context, cmd command.CreatePaymentCommand) (*dto.PaymentResponse, error) {
 	return h.paymentUseCase.CreatePayment(
+		ctx,
 		cmd.UserID,
 		cmd.BasketID,
 		cmd.Method,
@@ -28,41 +31,53 @@ func (h *CommandHandler) HandleCreatePayment(cmd command.CreatePaymentCommand) (
 		cmd.Currency,
 		cmd.Description,
 		cmd.Metadata,
+		cmd.ProductIDs,
 	)
 }
 
 // HandleUpdatePayment handles UpdatePaymentCommand
-func (h *CommandHandler) HandleUpdatePayment(cmd command.UpdatePaymentCommand) (*dto.PaymentResponse, error) {
+func (h *CommandHandler) HandleUpdatePayment(ctx context.Context, cmd command.UpdatePaymentCommand) (*dto.PaymentResponse, error) {
 	return h.paymentUseCase.UpdatePayment(
+		ctx,
 		cmd.PaymentID,
 		cmd.Status,
 		cmd.Metadata,
+		cmd.Actor,
 	)
 }
 
 // HandleProcessPayment handles ProcessPaymentCommand
-func (h *CommandHandler) HandleProcessPayment(cmd command.ProcessPaymentCommand) (*dto.PaymentResponse, error) {
+func (h *CommandHandler) HandleProcessPayment(ctx context.Context, cmd command.ProcessPaymentCommand) (*dto.PaymentResponse, error) {
 	return h.paymentUseCase.ProcessPayment(
+		ctx,
 		cmd.PaymentID,
 		cmd.ProviderID,
+		cmd.Actor,
 	)
 }
 
 // HandleRefundPayment handles RefundPaymentCommand
-func (h *CommandHandler) HandleRefundPayment(cmd command.RefundPaymentCommand) (*dto.PaymentResponse, error) {
+func (h *CommandHandler) HandleRefundPayment(ctx context.Context, cmd command.RefundPaymentCommand) (*dto.PaymentResponse, error) {
 	return h.paymentUseCase.RefundPayment(
+		ctx,
 		cmd.PaymentID,
 		cmd.Amount,
 		cmd.Reason,
+		cmd.Actor,
 	)
 }
 
 // HandleCancelPayment handles CancelPaymentCommand
-func (h *CommandHandler) HandleCancelPayment(cmd command.CancelPaymentCommand) (*dto.PaymentResponse, error) {
-	return h.paymentUseCase.CancelPayment(cmd.PaymentID)
+func (h *CommandHandler) HandleCancelPayment(ctx context.Context, cmd command.CancelPaymentCommand) (*dto.PaymentResponse, error) {
+	return h.paymentUseCase.CancelPayment(ctx, cmd.PaymentID, cmd.Actor)
+}
+
+// HandleCancelAllPayments handles CancelAllPaymentsCommand
+func (h *CommandHandler) HandleCancelAllPayments(ctx context.Context, cmd command.CancelAllPaymentsCommand) (*dto.CancelAllPaymentsResponse, error) {
+	return h.paymentUseCase.CancelAllPayments(ctx, cmd.UserID, cmd.Actor)
 }
 
 // HandleRetryPayment handles RetryPaymentCommand
-func (h *CommandHandler) HandleRetryPayment(cmd command.RetryPaymentCommand) (*dto.PaymentResponse, error) {
-	return h.paymentUseCase.RetryPayment(cmd.PaymentID)
+func (h *CommandHandler) HandleRetryPayment(ctx context.Context, cmd command.RetryPaymentCommand) (*dto.PaymentResponse, error) {
+	return h.paymentUseCase.RetryPayment(ctx, cmd.PaymentID, cmd.Actor)
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+
 	"obs-tools-usage/internal/payment/application/dto"
 	"obs-tools-usage/internal/payment/application/query"
 	"obs-tools-usage/internal/payment/application/usecase"
@@ -19,71 +21,91 @@ func NewQueryHandler(paymentUseCase *usecase.PaymentUseCase) *QueryHandler {
 }
 
 // HandleGetPayment handles GetPaymentQuery
-func (h *QueryHandler) HandleGetPayment(q query.GetPaymentQuery) (*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPayment(q.PaymentID)
+func (h *QueryHandler) HandleGetPayment(ctx context.Context, q query.GetPaymentQuery) (*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPayment(ctx, q.PaymentID)
 }
 
 // HandleGetPaymentsByUser handles GetPaymentsByUserQuery
-func (h *QueryHandler) HandleGetPaymentsByUser(q query.GetPaymentsByUserQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByUser(q.UserID)
+func (h *QueryHandler) HandleGetPaymentsByUser(ctx context.Context, q query.GetPaymentsByUserQuery) (*dto.PaginatedPaymentsResponse, error) {
+	return h.paymentUseCase.GetPaymentsByUser(ctx, q.UserID, q.Limit, q.Offset, q.Sort)
 }
 
 // HandleGetPaymentsByBasket handles GetPaymentsByBasketQuery
-func (h *QueryHandler) HandleGetPaymentsByBasket(q query.GetPaymentsByBasketQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByUser(q.BasketID) // Simplified for now
+func (h *QueryHandler) HandleGetPaymentsByBasket(ctx context.Context, q query.GetPaymentsByBasketQuery) ([]*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPaymentsByBasket(ctx, q.BasketID)
 }
 
 // HandleGetPaymentsByStatus handles GetPaymentsByStatusQuery
-func (h *QueryHandler) HandleGetPaymentsByStatus(q query.GetPaymentsByStatusQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByStatus(q.Status)
+func (h *QueryHandler) HandleGetPaymentsByStatus(ctx context.Context, q query.GetPaymentsByStatusQuery) ([]*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPaymentsByStatus(ctx, q.Status)
 }
 
 // HandleGetPaymentStats handles GetPaymentStatsQuery
-func (h *QueryHandler) HandleGetPaymentStats(q query.GetPaymentStatsQuery) (*dto.PaymentStatsResponse, error) {
-	return h.paymentUseCase.GetPaymentStats(q.UserID)
+func (h *QueryHandler) HandleGetPaymentStats(ctx context.Context, q query.GetPaymentStatsQuery) (*dto.PaymentStatsResponse, error) {
+	return h.paymentUseCase.GetPaymentStats(ctx, q.UserID, q.TargetCurrency)
 }
 
 // HandleGetPaymentsByDateRange handles GetPaymentsByDateRangeQuery
-func (h *QueryHandler) HandleGetPaymentsByDateRange(q query.GetPaymentsByDateRangeQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByDateRange(q.StartDate, q.EndDate)
+func (h *QueryHandler) HandleGetPaymentsByDateRange(ctx context.Context, q query.GetPaymentsByDateRangeQuery) ([]*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPaymentsByDateRange(ctx, q.StartDate, q.EndDate)
 }
 
 // HandleGetPaymentsByAmountRange handles GetPaymentsByAmountRangeQuery
-func (h *QueryHandler) HandleGetPaymentsByAmountRange(q query.GetPaymentsByAmountRangeQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByAmountRange(q.MinAmount, q.MaxAmount)
+func (h *QueryHandler) HandleGetPaymentsByAmountRange(ctx context.Context, q query.GetPaymentsByAmountRangeQuery) ([]*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPaymentsByAmountRange(ctx, q.MinAmount, q.MaxAmount, q.Sort)
 }
 
 // HandleGetPaymentsByMethod handles GetPaymentsByMethodQuery
-func (h *QueryHandler) HandleGetPaymentsByMethod(q query.GetPaymentsByMethodQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByMethod(q.Method)
+func (h *QueryHandler) HandleGetPaymentsByMethod(ctx context.Context, q query.GetPaymentsByMethodQuery) ([]*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPaymentsByMethod(ctx, q.Method, q.Sort)
 }
 
 // HandleGetPaymentsByProvider handles GetPaymentsByProviderQuery
-func (h *QueryHandler) HandleGetPaymentsByProvider(q query.GetPaymentsByProviderQuery) ([]*dto.PaymentResponse, error) {
-	return h.paymentUseCase.GetPaymentsByProvider(q.Provider)
+func (h *QueryHandler) HandleGetPaymentsByProvider(ctx context.Context, q query.GetPaymentsByProviderQuery) ([]*dto.PaymentResponse, error) {
+	return h.paymentUseCase.GetPaymentsByProvider(ctx, q.Provider, q.Sort)
+}
+
+// HandleGetPaymentsByMetadata handles GetPaymentsByMetadataQuery
+func (h *QueryHandler) HandleGetPaymentsByMetadata(ctx context.Context, q query.GetPaymentsByMetadataQuery) (*dto.PaginatedPaymentsResponse, error) {
+	return h.paymentUseCase.GetPaymentsByMetadata(ctx, q.Key, q.Value, q.Limit, q.Offset, q.Sort)
 }
 
 // HandleGetPaymentItems handles GetPaymentItemsQuery
-func (h *QueryHandler) HandleGetPaymentItems(q query.GetPaymentItemsQuery) ([]dto.PaymentItemResponse, error) {
-	return h.paymentUseCase.GetPaymentItems(q.PaymentID)
+func (h *QueryHandler) HandleGetPaymentItems(ctx context.Context, q query.GetPaymentItemsQuery) ([]dto.PaymentItemResponse, error) {
+	return h.paymentUseCase.GetPaymentItems(ctx, q.PaymentID)
+}
+
+// HandleGetPaymentAuditLog handles GetPaymentAuditLogQuery
+func (h *QueryHandler) HandleGetPaymentAuditLog(ctx context.Context, q query.GetPaymentAuditLogQuery) (*dto.PaymentAuditLogResponse, error) {
+	return h.paymentUseCase.GetPaymentAuditLog(ctx, q.PaymentID)
+}
+
+// HandleGetUserTimeline handles GetUserTimelineQuery
+func (h *QueryHandler) HandleGetUserTimeline(ctx context.Context, q query.GetUserTimelineQuery) (*dto.UserTimelineResponse, error) {
+	return h.paymentUseCase.GetUserTimeline(ctx, q.UserID, q.After, q.Limit)
+}
+
+// HandleGetReconcileReport handles GetReconcileReportQuery
+func (h *QueryHandler) HandleGetReconcileReport(q query.GetReconcileReportQuery) (*dto.ReconcileReportResponse, error) {
+	return h.paymentUseCase.GetReconcileReport(), nil
 }
 
 // HandleGetPaymentAnalytics handles GetPaymentAnalyticsQuery
-func (h *QueryHandler) HandleGetPaymentAnalytics(q query.GetPaymentAnalyticsQuery) (*dto.PaymentAnalyticsResponse, error) {
-	return h.paymentUseCase.GetPaymentAnalytics()
+func (h *QueryHandler) HandleGetPaymentAnalytics(ctx context.Context, q query.GetPaymentAnalyticsQuery) (*dto.PaymentAnalyticsResponse, error) {
+	return h.paymentUseCase.GetPaymentAnalytics(ctx)
 }
 
 // HandleGetPaymentMethods handles GetPaymentMethodsQuery
-func (h *QueryHandler) HandleGetPaymentMethods(q query.GetPaymentMethodsQuery) (*dto.PaymentMethodsResponse, error) {
-	return h.paymentUseCase.GetPaymentMethods()
+func (h *QueryHandler) HandleGetPaymentMethods(ctx context.Context, q query.GetPaymentMethodsQuery) (*dto.PaymentMethodsResponse, error) {
+	return h.paymentUseCase.GetPaymentMethods(ctx)
 }
 
 // HandleGetPaymentProviders handles GetPaymentProvidersQuery
-func (h *QueryHandler) HandleGetPaymentProviders(q query.GetPaymentProvidersQuery) (*dto.PaymentProvidersResponse, error) {
-	return h.paymentUseCase.GetPaymentProviders()
+func (h *QueryHandler) HandleGetPaymentProviders(ctx context.Context, q query.GetPaymentProvidersQuery) (*dto.PaymentProvidersResponse, error) {
+	return h.paymentUseCase.GetPaymentProviders(ctx)
 }
 
 // HandleGetPaymentSummary handles GetPaymentSummaryQuery
-func (h *QueryHandler) HandleGetPaymentSummary(q query.GetPaymentSummaryQuery) (*dto.PaymentSummaryResponse, error) {
-	return h.paymentUseCase.GetPaymentSummary()
+func (h *QueryHandler) HandleGetPaymentSummary(ctx context.Context, q query.GetPaymentSummaryQuery) (*dto.PaymentSummaryResponse, error) {
+	return h.paymentUseCase.GetPaymentSummary(ctx, q.TargetCurrency)
 }
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/payment/application/usecase"
+	"obs-tools-usage/internal/payment/infrastructure/config"
+)
+
+// ReconcileJob periodically reconciles payments stuck in processing with the payment provider
+type ReconcileJob struct {
+	paymentUseCase *usecase.PaymentUseCase
+	config         config.ReconcileConfig
+	logger         *logrus.Logger
+}
+
+// NewReconcileJob creates a new reconciliation job
+func NewReconcileJob(paymentUseCase *usecase.PaymentUseCase, cfg config.ReconcileConfig, logger *logrus.Logger) *ReconcileJob {
+	return &ReconcileJob{
+		paymentUseCase: paymentUseCase,
+		config:         cfg,
+		logger:         logger,
+	}
+}
+
+// Start reconciles stale payments every interval until the context is cancelled
+func (j *ReconcileJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	j.logger.WithFields(logrus.Fields{
+		"interval":    j.config.Interval.String(),
+		"stale_after": j.config.StaleAfter.String(),
+		"batch_size":  j.config.BatchSize,
+	}).Info("Payment reconciliation started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("Payment reconciliation stopped")
+			return
+		case <-ticker.C:
+			if _, err := j.paymentUseCase.ReconcilePayments(ctx, j.config.StaleAfter, j.config.BatchSize); err != nil {
+				j.logger.WithError(err).Error("Payment reconciliation run failed")
+			}
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package query
 
+import "time"
+
 // GetPaymentQuery represents a query to get a payment
 type GetPaymentQuery struct {
 	PaymentID string `json:"payment_id" binding:"required"`
@@ -8,6 +10,9 @@ type GetPaymentQuery struct {
 // GetPaymentsByUserQuery represents a query to get payments by user
 type GetPaymentsByUserQuery struct {
 	UserID string `json:"user_id" binding:"required"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Sort   string `json:"sort"` // Field to order by, prefixed with "-" for descending; defaults to -created_at
 }
 
 // GetPaymentsByBasketQuery represents a query to get payments by basket
@@ -22,29 +27,42 @@ type GetPaymentsByStatusQuery struct {
 
 // GetPaymentStatsQuery represents a query to get payment statistics
 type GetPaymentStatsQuery struct {
-	UserID string `json:"user_id"`
+	UserID         string `json:"user_id"`
+	TargetCurrency string `json:"target_currency"`
 }
 
 // GetPaymentsByDateRangeQuery represents a query to get payments by date range
 type GetPaymentsByDateRangeQuery struct {
-	StartDate string `json:"start_date" binding:"required"`
-	EndDate   string `json:"end_date" binding:"required"`
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"` // Inclusive
 }
 
 // GetPaymentsByAmountRangeQuery represents a query to get payments by amount range
 type GetPaymentsByAmountRangeQuery struct {
 	MinAmount float64 `json:"min_amount" binding:"required"`
 	MaxAmount float64 `json:"max_amount" binding:"required"`
+	Sort      string  `json:"sort"`
 }
 
 // GetPaymentsByMethodQuery represents a query to get payments by method
 type GetPaymentsByMethodQuery struct {
 	Method string `json:"method" binding:"required"`
+	Sort   string `json:"sort"`
 }
 
 // GetPaymentsByProviderQuery represents a query to get payments by provider
 type GetPaymentsByProviderQuery struct {
 	Provider string `json:"provider" binding:"required"`
+	Sort     string `json:"sort"`
+}
+
+// GetPaymentsByMetadataQuery represents a query to get payments by a metadata key/value pair
+type GetPaymentsByMetadataQuery struct {
+	Key    string `json:"key" binding:"required"`
+	Value  string `json:"value" binding:"required"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Sort   string `json:"sort"`
 }
 
 // GetPaymentItemsQuery represents a query to get payment items
@@ -52,6 +70,22 @@ type GetPaymentItemsQuery struct {
 	PaymentID string `json:"payment_id" binding:"required"`
 }
 
+// GetPaymentAuditLogQuery represents a query to get the audit trail of a payment
+type GetPaymentAuditLogQuery struct {
+	PaymentID string `json:"payment_id" binding:"required"`
+}
+
+// GetUserTimelineQuery represents a query to get a page of a user's payment timeline.
+// After is the cursor of the previous page; the zero time starts at the user's first event.
+type GetUserTimelineQuery struct {
+	UserID string    `json:"user_id" binding:"required"`
+	After  time.Time `json:"after"`
+	Limit  int       `json:"limit"`
+}
+
+// GetReconcileReportQuery represents a query to get the payment reconciliation report
+type GetReconcileReportQuery struct{}
+
 // GetPaymentAnalyticsQuery represents a query to get payment analytics
 type GetPaymentAnalyticsQuery struct{}
 
@@ -62,4 +96,6 @@ type GetPaymentMethodsQuery struct{}
 type GetPaymentProvidersQuery struct{}
 
 // GetPaymentSummaryQuery represents a query to get payment summary
-type GetPaymentSummaryQuery struct{}
+type GetPaymentSummaryQuery struct {
+	TargetCurrency string `json:"target_currency"`
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import "fmt"
+
+// CheckoutValueExceededCode is the error code clients receive when a basket total is above the maximum checkout value
+const CheckoutValueExceededCode = "CHECKOUT_VALUE_EXCEEDED"
+
+// CheckoutValueExceededError rejects a checkout whose basket total, computed at checkout time,
+// is above the configured maximum
+type CheckoutValueExceededError struct {
+	Total    float64
+	MaxValue float64
+}
+
+func (e *CheckoutValueExceededError) Error() string {
+	return fmt.Sprintf("checkout value exceeded: basket total %.2f is above the maximum of %.2f", e.Total, e.MaxValue)
+}
@@ -2,100 +2,206 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
 	"obs-tools-usage/internal/payment/application/dto"
 	"obs-tools-usage/internal/payment/domain/entity"
 	"obs-tools-usage/internal/payment/domain/repository"
 	"obs-tools-usage/internal/payment/domain/service"
+	"obs-tools-usage/internal/payment/infrastructure/config"
+	"obs-tools-usage/internal/payment/infrastructure/metrics"
 	"obs-tools-usage/kafka/events"
 	"obs-tools-usage/kafka/publisher"
 )
 
+const (
+	// providerChargeTimeout bounds a single charge request to the payment provider
+	providerChargeTimeout = 30 * time.Second
+	// providerUnavailableCode is recorded when the provider could not be reached
+	providerUnavailableCode = "provider_unavailable"
+)
+
 // PaymentUseCase handles payment business logic
 type PaymentUseCase struct {
 	paymentRepo   repository.PaymentRepository
 	basketClient  service.BasketClient
 	productClient service.ProductClient
-	kafkaPublisher *publisher.PaymentPublisher
+	kafkaPublisher publisher.EventPublisher
 	logger        *logrus.Logger
+	expiryConfig  config.ExpiryConfig
+	retryConfig   config.RetryConfig
+	exchangeRates service.ExchangeRateProvider
+	provider      service.PaymentProvider
+	fees          config.FeeConfig
+	currencies    config.CurrencyConfig
+	checkout      config.CheckoutConfig
+
+	// processingSlots holds one token per in-flight ProcessPayment; nil when unlimited
+	processingSlots chan struct{}
+
+	reconcileMu     sync.Mutex
+	reconcileReport dto.ReconcileReportResponse
 }
 
-// NewPaymentUseCase creates a new payment use case
-func NewPaymentUseCase(paymentRepo repository.PaymentRepository, basketClient service.BasketClient, productClient service.ProductClient, kafkaPublisher *publisher.PaymentPublisher, logger *logrus.Logger) *PaymentUseCase {
+// NewPaymentUseCase creates a new payment use case.
+// A nil kafkaPublisher is replaced by a NoopPublisher so publishing is skipped instead of panicking.
+func NewPaymentUseCase(paymentRepo repository.PaymentRepository, basketClient service.BasketClient, productClient service.ProductClient, kafkaPublisher publisher.EventPublisher, logger *logrus.Logger, expiryConfig config.ExpiryConfig, retryConfig config.RetryConfig, exchangeRates service.ExchangeRateProvider, provider service.PaymentProvider, fees config.FeeConfig, processing config.ProcessingConfig, currencies config.CurrencyConfig, checkout config.CheckoutConfig) *PaymentUseCase {
+	if kafkaPublisher == nil {
+		logger.Warn("No Kafka publisher configured for payment use case, events will not be published")
+		kafkaPublisher = publisher.NewNoopPublisher(logger)
+	}
+	var processingSlots chan struct{}
+	if processing.MaxInFlight > 0 {
+		processingSlots = make(chan struct{}, processing.MaxInFlight)
+	}
+	metrics.SetProcessingLimit(processing.MaxInFlight)
 	return &PaymentUseCase{
-		paymentRepo:    paymentRepo,
-		basketClient:   basketClient,
-		productClient:  productClient,
-		kafkaPublisher: kafkaPublisher,
-		logger:         logger,
+		paymentRepo:     paymentRepo,
+		basketClient:    basketClient,
+		productClient:   productClient,
+		kafkaPublisher:  kafkaPublisher,
+		logger:          logger,
+		expiryConfig:    expiryConfig,
+		retryConfig:     retryConfig,
+		exchangeRates:   exchangeRates,
+		provider:        provider,
+		fees:            fees,
+		currencies:      currencies,
+		checkout:        checkout,
+		processingSlots: processingSlots,
+	}
+}
+
+// resolveCurrency normalizes a requested currency code to uppercase, falling back to the
+// configured default when none is given, and rejects codes that are not allowed
+func (uc *PaymentUseCase) resolveCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = strings.ToUpper(uc.currencies.Default)
+	}
+	if !uc.currencies.IsAllowed(currency) {
+		return "", fmt.Errorf("invalid currency: %s is not supported", currency)
 	}
+	return currency, nil
 }
 
-// CreatePayment creates a new payment
-func (uc *PaymentUseCase) CreatePayment(userID, basketID, method, provider, currency, description string, metadata map[string]string) (*dto.PaymentResponse, error) {
-	ctx := context.Background()
+// checkoutError reports err, naming the checkout budget when it is what ran out
+func (uc *PaymentUseCase) checkoutError(ctx context.Context, step string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("checkout timed out after %s while trying to %s: %w", uc.checkout.Timeout, step, err)
+	}
+	return fmt.Errorf("failed to %s: %w", step, err)
+}
+
+// CreatePayment creates a new payment. The whole checkout, from reading the basket to
+// storing the payment, shares one deadline; when it runs out nothing is stored.
+// When productIDs is set only those basket items are charged, and only they leave the basket on completion.
+func (uc *PaymentUseCase) CreatePayment(ctx context.Context, userID, basketID, method, provider, currency, description string, metadata map[string]string, productIDs []int) (*dto.PaymentResponse, error) {
+	if uc.checkout.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, uc.checkout.Timeout)
+		defer cancel()
+	}
+
+	currency, err := uc.resolveCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get basket information
 	basketInfo, err := uc.basketClient.GetBasket(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get basket: %w", err)
+		return nil, uc.checkoutError(ctx, "get basket", err)
 	}
 
 	if basketInfo.Total <= 0 {
 		return nil, fmt.Errorf("basket is empty or invalid")
 	}
 
+	basketItems, err := selectBasketItems(basketInfo.Items, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	partial := len(basketItems) < len(basketInfo.Items)
+
 	// Generate payment ID
 	paymentID := fmt.Sprintf("pay_%s_%d", userID, time.Now().Unix())
 
+	// Build payment items from basket, carrying over line tax and discounts
+	paymentItems := make([]entity.PaymentItem, 0, len(basketItems))
+	for _, basketItem := range basketItems {
+		paymentItems = append(paymentItems, entity.PaymentItem{
+			ID:             fmt.Sprintf("item_%s_%d", paymentID, basketItem.ProductID),
+			PaymentID:      paymentID,
+			ProductID:      basketItem.ProductID,
+			Name:           basketItem.Name,
+			Quantity:       basketItem.Quantity,
+			Price:          basketItem.Price,
+			Subtotal:       basketItem.Subtotal,
+			TaxRate:        basketItem.TaxRate,
+			TaxAmount:      basketItem.TaxAmount,
+			DiscountAmount: basketItem.DiscountAmount,
+			Category:       basketItem.Category,
+			CreatedAt:      time.Now(),
+		})
+	}
+
 	// Create payment entity
 	payment := &entity.Payment{
 		ID:          paymentID,
 		UserID:      userID,
 		BasketID:    basketInfo.ID,
-		Amount:      basketInfo.Total,
 		Currency:    currency,
 		Status:      entity.PaymentStatusPending,
 		Method:      entity.PaymentMethod(method),
 		Provider:    provider,
 		Description: description,
 		Metadata:    metadata,
+		Partial:     partial,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	// Set expiration time (30 minutes from now)
-	expiresAt := time.Now().Add(30 * time.Minute)
-	payment.ExpiresAt = &expiresAt
+	// Charge the sum of the line totals rather than trusting the basket total
+	payment.CalculateTotal(paymentItems)
+	if payment.Amount <= 0 {
+		return nil, fmt.Errorf("basket is empty or invalid")
+	}
+	if !partial && math.Abs(payment.Amount-basketInfo.Total) >= 0.005 {
+		uc.logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"basket_total": basketInfo.Total,
+			"amount":       payment.Amount,
+		}).Warn("Basket total does not match its line totals, charging the line totals")
+	}
 
-	// Create payment in database
-	if err := uc.paymentRepo.CreatePayment(payment); err != nil {
-		return nil, fmt.Errorf("failed to create payment: %w", err)
-	}
-
-	// Create payment items from basket
-	for _, basketItem := range basketInfo.Items {
-		itemID := fmt.Sprintf("item_%s_%d", paymentID, basketItem.ProductID)
-		paymentItem := &entity.PaymentItem{
-			ID:        itemID,
-			PaymentID: paymentID,
-			ProductID: basketItem.ProductID,
-			Name:      basketItem.Name,
-			Quantity:  basketItem.Quantity,
-			Price:     basketItem.Price,
-			Subtotal:  basketItem.Subtotal,
-			Category:  basketItem.Category,
-			CreatedAt: time.Now(),
-		}
+	// Checked against the total computed now, since prices may have risen since items were added
+	if uc.checkout.MaxValue > 0 && payment.Amount > uc.checkout.MaxValue {
+		metrics.RecordCheckoutValueExceeded()
+		uc.logger.WithFields(logrus.Fields{
+			"user_id":            userID,
+			"amount":             payment.Amount,
+			"max_checkout_value": uc.checkout.MaxValue,
+		}).Warn("Basket total exceeds the maximum checkout value")
+		return nil, &CheckoutValueExceededError{Total: payment.Amount, MaxValue: uc.checkout.MaxValue}
+	}
 
-		if err := uc.paymentRepo.CreatePaymentItem(paymentItem); err != nil {
-			uc.logger.WithError(err).Error("Failed to create payment item")
-			// Continue with other items
-		}
+	// Set expiration time from the window configured for the payment method
+	expiresAt := time.Now().Add(uc.expiryConfig.For(method))
+	payment.ExpiresAt = &expiresAt
+
+	// Store the payment and its items together so a timed out checkout leaves nothing behind
+	if err := uc.paymentRepo.CreatePaymentWithItems(ctx, payment, paymentItems); err != nil {
+		return nil, uc.checkoutError(ctx, "create payment", err)
 	}
 
 	// Convert to response
@@ -106,20 +212,49 @@ func (uc *PaymentUseCase) CreatePayment(userID, basketID, method, provider, curr
 		"user_id":    userID,
 		"amount":     payment.Amount,
 		"method":     payment.Method,
+		"partial":    payment.Partial,
 	}).Info("Created new payment")
 
 	return response, nil
 }
 
+// selectBasketItems returns the basket items of the given products, or every item when none are given.
+// Each selected product must be in the basket.
+func selectBasketItems(items []service.BasketItem, productIDs []int) ([]service.BasketItem, error) {
+	if len(productIDs) == 0 {
+		return items, nil
+	}
+
+	byProduct := make(map[int]service.BasketItem, len(items))
+	for _, item := range items {
+		byProduct[item.ProductID] = item
+	}
+
+	selected := make([]service.BasketItem, 0, len(productIDs))
+	seen := make(map[int]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if seen[productID] {
+			continue
+		}
+		item, ok := byProduct[productID]
+		if !ok {
+			return nil, fmt.Errorf("invalid product selection: product %d is not in the basket", productID)
+		}
+		seen[productID] = true
+		selected = append(selected, item)
+	}
+	return selected, nil
+}
+
 // GetPayment retrieves a payment by ID
-func (uc *PaymentUseCase) GetPayment(paymentID string) (*dto.PaymentResponse, error) {
-	payment, err := uc.paymentRepo.GetPayment(paymentID)
+func (uc *PaymentUseCase) GetPayment(ctx context.Context, paymentID string) (*dto.PaymentResponse, error) {
+	payment, err := uc.paymentRepo.GetPayment(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 
 	// Get payment items
-	items, err := uc.paymentRepo.GetPaymentItems(paymentID)
+	items, err := uc.paymentRepo.GetPaymentItems(ctx, paymentID)
 	if err != nil {
 		uc.logger.WithError(err).Warn("Failed to get payment items")
 	}
@@ -130,28 +265,36 @@ func (uc *PaymentUseCase) GetPayment(paymentID string) (*dto.PaymentResponse, er
 	return response, nil
 }
 
-// UpdatePayment updates payment status
-func (uc *PaymentUseCase) UpdatePayment(paymentID, status string, metadata map[string]string) (*dto.PaymentResponse, error) {
-	payment, err := uc.paymentRepo.GetPayment(paymentID)
+// UpdatePayment updates payment status on behalf of actor
+func (uc *PaymentUseCase) UpdatePayment(ctx context.Context, paymentID, status string, metadata map[string]string, actor string) (*dto.PaymentResponse, error) {
+	payment, err := uc.paymentRepo.GetPayment(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
+	fromStatus := payment.Status
 
-	// Update status
+	// Update status, rejecting transitions the payment state machine does not allow
 	switch entity.PaymentStatus(status) {
+	case entity.PaymentStatusPending:
+		err = payment.MarkAsPending()
 	case entity.PaymentStatusProcessing:
-		payment.MarkAsProcessing()
+		err = payment.MarkAsProcessing()
 	case entity.PaymentStatusCompleted:
-		payment.MarkAsCompleted()
+		if err = payment.MarkAsCompleted(); err == nil {
+			uc.applyProviderFee(payment)
+		}
 	case entity.PaymentStatusFailed:
-		payment.MarkAsFailed()
+		err = payment.MarkAsFailed()
 	case entity.PaymentStatusCancelled:
-		payment.MarkAsCancelled()
+		err = payment.MarkAsCancelled()
 	case entity.PaymentStatusRefunded:
-		payment.MarkAsRefunded()
+		err = payment.MarkAsRefunded()
 	default:
 		return nil, fmt.Errorf("invalid payment status: %s", status)
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Update metadata if provided
 	if metadata != nil {
@@ -159,7 +302,8 @@ func (uc *PaymentUseCase) UpdatePayment(paymentID, status string, metadata map[s
 	}
 
 	// Save to database
-	if err := uc.paymentRepo.UpdatePayment(payment); err != nil {
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, "status updated", metadata)
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, nil); err != nil {
 		return nil, fmt.Errorf("failed to update payment: %w", err)
 	}
 
@@ -173,96 +317,103 @@ func (uc *PaymentUseCase) UpdatePayment(paymentID, status string, metadata map[s
 	return response, nil
 }
 
-// ProcessPayment processes a payment
-func (uc *PaymentUseCase) ProcessPayment(paymentID, providerID string) (*dto.PaymentResponse, error) {
-	ctx := context.Background()
+// acquireProcessingSlot reserves one of the in-flight processing slots without waiting.
+// When every slot is taken it fails with a "too busy" error, so callers can retry later
+// instead of piling more concurrent charges onto the provider and database.
+func (uc *PaymentUseCase) acquireProcessingSlot() (func(), error) {
+	if uc.processingSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case uc.processingSlots <- struct{}{}:
+		metrics.SetProcessingInFlight(len(uc.processingSlots))
+		return func() {
+			<-uc.processingSlots
+			metrics.SetProcessingInFlight(len(uc.processingSlots))
+		}, nil
+	default:
+		metrics.RecordProcessingRejected()
+		uc.logger.WithField("max_in_flight", cap(uc.processingSlots)).Warn("Payment processing limit reached, rejecting request")
+		return nil, fmt.Errorf("payment processing too busy, retry later")
+	}
+}
 
-	payment, err := uc.paymentRepo.GetPayment(paymentID)
+// ProcessPayment processes a payment on behalf of actor.
+// At most the configured number of payments are processed at once; see acquireProcessingSlot.
+func (uc *PaymentUseCase) ProcessPayment(ctx context.Context, paymentID, providerID, actor string) (*dto.PaymentResponse, error) {
+	release, err := uc.acquireProcessingSlot()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// A charge must be recorded even when the caller goes away mid-request
+	ctx = context.WithoutCancel(ctx)
+
+	payment, err := uc.paymentRepo.GetPayment(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 
-	if !payment.CanBeCancelled() {
+	if !payment.CanTransitionTo(entity.PaymentStatusProcessing) {
 		return nil, fmt.Errorf("payment cannot be processed, current status: %s", payment.Status)
 	}
 
 	if payment.IsExpired() {
-		payment.MarkAsFailed()
-		uc.paymentRepo.UpdatePayment(payment)
+		fromStatus := payment.Status
+		if err := payment.MarkAsFailed(); err == nil {
+			audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, "payment expired", nil)
+			if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, nil); err != nil {
+				uc.logger.WithError(err).WithField("payment_id", paymentID).Error("Failed to mark expired payment as failed")
+				return nil, fmt.Errorf("failed to update payment: %w", err)
+			}
+		}
 		return nil, fmt.Errorf("payment has expired")
 	}
 
-	// Mark as processing
-	payment.MarkAsProcessing()
+	// Mark as processing. The write only applies while the payment is still in the status read above,
+	// so a payment cancelled in the meantime is never charged.
+	fromStatus := payment.Status
+	if err := payment.MarkAsProcessing(); err != nil {
+		return nil, err
+	}
 	payment.ProviderID = providerID
-	if err := uc.paymentRepo.UpdatePayment(payment); err != nil {
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, "processing started", nil)
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, nil); err != nil {
 		return nil, fmt.Errorf("failed to update payment: %w", err)
 	}
 
 	// Get payment items for stock update
-	items, err := uc.paymentRepo.GetPaymentItems(paymentID)
+	items, err := uc.paymentRepo.GetPaymentItems(ctx, paymentID)
 	if err != nil {
 		uc.logger.WithError(err).Warn("Failed to get payment items for stock update")
 	}
 
-	// Simulate payment processing (in real implementation, call payment provider)
-	time.Sleep(1 * time.Second)
-
-	// For demo purposes, mark as completed
-	// In real implementation, this would depend on payment provider response
-	payment.MarkAsCompleted()
-	if err := uc.paymentRepo.UpdatePayment(payment); err != nil {
-		return nil, fmt.Errorf("failed to update payment: %w", err)
-	}
-
-	// Publish payment completed event
-	paymentCompletedEvent := &events.PaymentCompletedEvent{
-		PaymentID: payment.ID,
-		UserID:    payment.UserID,
-		BasketID:  payment.BasketID,
-		Amount:    payment.Amount,
-		Currency:  payment.Currency,
-		Items:     uc.convertToPaymentItemEvents(items),
-		Metadata:  uc.convertMetadata(payment.Metadata),
-	}
+	chargeCtx, cancel := context.WithTimeout(ctx, providerChargeTimeout)
+	defer cancel()
 
-	if err := uc.kafkaPublisher.PublishPaymentCompleted(ctx, paymentCompletedEvent); err != nil {
-		uc.logger.WithError(err).Error("Failed to publish payment completed event")
+	result, err := uc.provider.Charge(chargeCtx, payment)
+	if err != nil {
+		uc.logger.WithError(err).WithField("payment_id", paymentID).Error("Payment provider unavailable")
+		metrics.RecordProviderCharge(metrics.ChargeResultUnavailable, providerUnavailableCode)
+		result = &service.ChargeResult{ErrorCode: providerUnavailableCode, Reason: "The payment provider could not be reached"}
+	} else if result.Approved {
+		metrics.RecordProviderCharge(metrics.ChargeResultApproved, "")
+	} else {
+		metrics.RecordProviderCharge(metrics.ChargeResultDeclined, result.ErrorCode)
 	}
 
-	// Publish stock update events for each item
-	for _, item := range items {
-		stockUpdateEvent := &events.StockUpdateEvent{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			Operation: "decrease",
-			Reason:    "Payment completed",
-			Metadata: map[string]interface{}{
-				"payment_id": payment.ID,
-				"user_id":    payment.UserID,
-			},
-		}
-
-		if err := uc.kafkaPublisher.PublishStockUpdate(ctx, stockUpdateEvent); err != nil {
-			uc.logger.WithError(err).WithFields(logrus.Fields{
-				"product_id": item.ProductID,
-				"quantity":   item.Quantity,
-			}).Error("Failed to publish stock update event")
-		}
+	if result.Reference != "" && payment.ProviderID == "" {
+		payment.ProviderID = result.Reference
 	}
 
-	// Publish basket cleared event
-	basketClearedEvent := &events.BasketClearedEvent{
-		UserID:   payment.UserID,
-		BasketID: payment.BasketID,
-		Reason:   "Payment completed",
-		Metadata: map[string]interface{}{
-			"payment_id": payment.ID,
-		},
+	if !result.Approved {
+		return uc.failDeclinedPayment(ctx, payment, result, actor)
 	}
 
-	if err := uc.kafkaPublisher.PublishBasketCleared(ctx, basketClearedEvent); err != nil {
-		uc.logger.WithError(err).Error("Failed to publish basket cleared event")
+	if err := uc.completeChargedPayment(ctx, payment, items, actor, "charge approved"); err != nil {
+		return nil, err
 	}
 
 	response := uc.paymentToResponse(payment)
@@ -276,9 +427,82 @@ func (uc *PaymentUseCase) ProcessPayment(paymentID, providerID string) (*dto.Pay
 	return response, nil
 }
 
-// RefundPayment refunds a payment
-func (uc *PaymentUseCase) RefundPayment(paymentID string, amount float64, reason string) (*dto.PaymentResponse, error) {
-	payment, err := uc.paymentRepo.GetPayment(paymentID)
+// completeChargedPayment marks a payment the provider charged as completed and stores the
+// completion events in the outbox within the same transaction as the status change;
+// the outbox relay publishes them to Kafka and retries on broker failures
+func (uc *PaymentUseCase) completeChargedPayment(ctx context.Context, payment *entity.Payment, items []*entity.PaymentItem, actor, reason string) error {
+	fromStatus := payment.Status
+	if err := payment.MarkAsCompleted(); err != nil {
+		return err
+	}
+	uc.applyProviderFee(payment)
+
+	outboxEvents, err := uc.paymentCompletedOutboxEvents(payment, items)
+	if err != nil {
+		return err
+	}
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, reason, map[string]string{
+		"provider_id": payment.ProviderID,
+	})
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, outboxEvents); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	return nil
+}
+
+// failDeclinedPayment marks a payment the provider declined as failed, keeping the decline
+// code in its metadata, and stores a payment failed event in the outbox with the status change
+func (uc *PaymentUseCase) failDeclinedPayment(ctx context.Context, payment *entity.Payment, result *service.ChargeResult, actor string) (*dto.PaymentResponse, error) {
+	fromStatus := payment.Status
+	if err := payment.MarkAsFailed(); err != nil {
+		return nil, err
+	}
+	if payment.Metadata == nil {
+		payment.Metadata = make(map[string]string)
+	}
+	payment.Metadata["failure_code"] = result.ErrorCode
+	payment.Metadata["failure_reason"] = result.Reason
+
+	paymentFailedEvent := &events.PaymentFailedEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.PaymentFailedEventType,
+		Timestamp: time.Now(),
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		BasketID:  payment.BasketID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Reason:    result.Reason,
+		ErrorCode: result.ErrorCode,
+		Metadata: map[string]interface{}{
+			"attempts": payment.Attempts,
+		},
+	}
+	outboxEvent, err := entity.NewOutboxEvent(payment.ID, paymentFailedEvent.EventType, paymentFailedEvent)
+	if err != nil {
+		return nil, err
+	}
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, "charge declined", map[string]string{
+		"failure_code":   result.ErrorCode,
+		"failure_reason": result.Reason,
+	})
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, []*entity.OutboxEvent{outboxEvent}); err != nil {
+		return nil, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	uc.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"user_id":    payment.UserID,
+		"error_code": result.ErrorCode,
+		"reason":     result.Reason,
+	}).Warn("Payment declined by provider")
+
+	return uc.paymentToResponse(payment), nil
+}
+
+// RefundPayment refunds a payment on behalf of actor
+func (uc *PaymentUseCase) RefundPayment(ctx context.Context, paymentID string, amount float64, reason, actor string) (*dto.PaymentResponse, error) {
+	payment, err := uc.paymentRepo.GetPayment(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
@@ -296,8 +520,14 @@ func (uc *PaymentUseCase) RefundPayment(paymentID string, amount float64, reason
 	}
 
 	// Mark as refunded
-	payment.MarkAsRefunded()
-	if err := uc.paymentRepo.UpdatePayment(payment); err != nil {
+	fromStatus := payment.Status
+	if err := payment.MarkAsRefunded(); err != nil {
+		return nil, err
+	}
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, reason, map[string]string{
+		"refund_amount": strconv.FormatFloat(amount, 'f', 2, 64),
+	})
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, nil); err != nil {
 		return nil, fmt.Errorf("failed to update payment: %w", err)
 	}
 
@@ -312,48 +542,137 @@ func (uc *PaymentUseCase) RefundPayment(paymentID string, amount float64, reason
 	return response, nil
 }
 
-// GetPaymentsByUser retrieves payments by user
-func (uc *PaymentUseCase) GetPaymentsByUser(userID string) ([]*dto.PaymentResponse, error) {
-	payments, err := uc.paymentRepo.GetPaymentsByUser(userID)
+// GetPaymentsByUser retrieves a page of payments by user
+func (uc *PaymentUseCase) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int, sort string) (*dto.PaginatedPaymentsResponse, error) {
+	if !entity.ValidPaymentSort(sort) {
+		return nil, fmt.Errorf("invalid sort field: %s", sort)
+	}
+
+	payments, total, err := uc.paymentRepo.GetPaymentsByUser(ctx, userID, limit, offset, sort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payments by user: %w", err)
 	}
 
-	var responses []*dto.PaymentResponse
-	for _, payment := range payments {
-		items, _ := uc.paymentRepo.GetPaymentItems(payment.ID)
-		response := uc.paymentToResponse(payment)
-		response.Items = uc.itemsToResponse(items)
-		responses = append(responses, response)
+	return &dto.PaginatedPaymentsResponse{
+		Payments: uc.paymentsWithItems(ctx, payments),
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+// GetPaymentsByBasket retrieves payments by basket
+func (uc *PaymentUseCase) GetPaymentsByBasket(ctx context.Context, basketID string) ([]*dto.PaymentResponse, error) {
+	payments, err := uc.paymentRepo.GetPaymentsByBasket(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments by basket: %w", err)
 	}
 
-	return responses, nil
+	return uc.paymentsWithItems(ctx, payments), nil
 }
 
-// GetPaymentStats retrieves payment statistics
-func (uc *PaymentUseCase) GetPaymentStats(userID string) (*dto.PaymentStatsResponse, error) {
-	stats, err := uc.paymentRepo.GetPaymentStats(userID)
+// GetPaymentStats retrieves payment statistics.
+// When targetCurrency is set, the totals are converted into it; the per-currency breakdown is always returned.
+func (uc *PaymentUseCase) GetPaymentStats(ctx context.Context, userID, targetCurrency string) (*dto.PaymentStatsResponse, error) {
+	stats, err := uc.paymentRepo.GetPaymentStats(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment stats: %w", err)
 	}
 
-	return &dto.PaymentStatsResponse{
+	currency, combined, err := uc.combineCurrencyTotals(ctx, stats.ByCurrency, targetCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PaymentStatsResponse{
 		TotalPayments:     stats.TotalPayments,
-		TotalAmount:       stats.TotalAmount,
 		CompletedPayments: stats.CompletedPayments,
 		FailedPayments:    stats.FailedPayments,
 		PendingPayments:   stats.PendingPayments,
-		AverageAmount:     stats.AverageAmount,
-	}, nil
+		Currency:          currency,
+		ByCurrency:        currencyTotalsToResponse(stats.ByCurrency),
+	}
+	if currency != "" {
+		response.TotalAmount = combined.TotalAmount
+		if combined.Payments > 0 {
+			response.AverageAmount = combined.TotalAmount / float64(combined.Payments)
+		}
+	}
+	return response, nil
+}
+
+// combineCurrencyTotals sums per-currency totals, including fees and net amounts, into a single currency.
+// Without a target currency they are only combined when every payment shares one currency;
+// otherwise the returned currency is empty and only the payment count is set.
+func (uc *PaymentUseCase) combineCurrencyTotals(ctx context.Context, totals []repository.CurrencyTotal, targetCurrency string) (string, repository.CurrencyTotal, error) {
+	var combined repository.CurrencyTotal
+	for _, t := range totals {
+		combined.Payments += t.Payments
+	}
+
+	targetCurrency = strings.ToUpper(strings.TrimSpace(targetCurrency))
+	if targetCurrency == "" {
+		switch len(totals) {
+		case 0:
+			return "", repository.CurrencyTotal{}, nil
+		case 1:
+			return totals[0].Currency, totals[0], nil
+		default:
+			return "", combined, nil
+		}
+	}
+
+	if uc.exchangeRates == nil {
+		return "", repository.CurrencyTotal{}, fmt.Errorf("invalid currency conversion: no exchange rate provider configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	combined.Currency = targetCurrency
+	for _, t := range totals {
+		rate, err := uc.exchangeRates.GetRate(ctx, t.Currency, targetCurrency)
+		if err != nil {
+			return "", repository.CurrencyTotal{}, err
+		}
+		combined.TotalAmount += t.TotalAmount * rate
+		combined.TotalFees += t.TotalFees * rate
+		combined.NetAmount += t.NetAmount * rate
+	}
+	return targetCurrency, combined, nil
+}
+
+// applyProviderFee records the configured fee of the payment's provider on a completed payment
+func (uc *PaymentUseCase) applyProviderFee(payment *entity.Payment) {
+	percent, fixed := uc.fees.For(payment.Provider)
+	payment.ApplyFee(percent, fixed)
+}
+
+// currencyTotalsToResponse converts per-currency totals to their response form
+func currencyTotalsToResponse(totals []repository.CurrencyTotal) []dto.CurrencyTotalResponse {
+	response := make([]dto.CurrencyTotalResponse, 0, len(totals))
+	for _, t := range totals {
+		response = append(response, dto.CurrencyTotalResponse{
+			Currency:      t.Currency,
+			Payments:      t.Payments,
+			TotalAmount:   t.TotalAmount,
+			AverageAmount: t.AverageAmount,
+			TotalFees:     t.TotalFees,
+			NetAmount:     t.NetAmount,
+		})
+	}
+	return response
 }
 
 // paymentToResponse converts entity.Payment to dto.PaymentResponse
 func (uc *PaymentUseCase) paymentToResponse(payment *entity.Payment) *dto.PaymentResponse {
-	return &dto.PaymentResponse{
+	response := &dto.PaymentResponse{
 		ID:          payment.ID,
 		UserID:      payment.UserID,
 		BasketID:    payment.BasketID,
 		Amount:      payment.Amount,
+		FeeAmount:   payment.FeeAmount,
+		NetAmount:   payment.NetAmount,
 		Currency:    payment.Currency,
 		Status:      string(payment.Status),
 		Method:      string(payment.Method),
@@ -366,7 +685,42 @@ func (uc *PaymentUseCase) paymentToResponse(payment *entity.Payment) *dto.Paymen
 		UpdatedAt:   payment.UpdatedAt,
 		ProcessedAt: payment.ProcessedAt,
 		ExpiresAt:   payment.ExpiresAt,
+		Attempts:    payment.Attempts,
+		Partial:     payment.Partial,
+	}
+
+	// Pending payments report the seconds left so clients can show a countdown
+	if payment.Status == entity.PaymentStatusPending && payment.ExpiresAt != nil {
+		remaining := int64(time.Until(*payment.ExpiresAt).Seconds())
+		if remaining < 0 {
+			remaining = 0
+		}
+		response.ExpiresInSeconds = &remaining
+	}
+
+	return response
+}
+
+// paymentsWithItems converts payments to responses, loading all of their items with a single query
+func (uc *PaymentUseCase) paymentsWithItems(ctx context.Context, payments []*entity.Payment) []*dto.PaymentResponse {
+	paymentIDs := make([]string, len(payments))
+	for i, payment := range payments {
+		paymentIDs[i] = payment.ID
+	}
+
+	itemsByPayment, err := uc.paymentRepo.GetPaymentItemsForPayments(ctx, paymentIDs)
+	if err != nil {
+		uc.logger.WithError(err).Warn("Failed to get payment items")
 	}
+
+	responses := make([]*dto.PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		response := uc.paymentToResponse(payment)
+		response.Items = uc.itemsToResponse(itemsByPayment[payment.ID])
+		responses = append(responses, response)
+	}
+
+	return responses
 }
 
 // itemsToResponse converts entity.PaymentItem slice to dto.PaymentItemResponse slice
@@ -374,112 +728,109 @@ func (uc *PaymentUseCase) itemsToResponse(items []*entity.PaymentItem) []dto.Pay
 	var responses []dto.PaymentItemResponse
 	for _, item := range items {
 		responses = append(responses, dto.PaymentItemResponse{
-			ID:        item.ID,
-			ProductID: item.ProductID,
-			Name:      item.Name,
-			Quantity:  item.Quantity,
-			Price:     item.Price,
-			Subtotal:  item.Subtotal,
-			Category:  item.Category,
-			CreatedAt: item.CreatedAt,
+			ID:             item.ID,
+			ProductID:      item.ProductID,
+			Name:           item.Name,
+			Quantity:       item.Quantity,
+			Price:          item.Price,
+			Subtotal:       item.Subtotal,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			DiscountAmount: item.DiscountAmount,
+			Category:       item.Category,
+			CreatedAt:      item.CreatedAt,
 		})
 	}
 	return responses
 }
 
 // GetPaymentsByStatus retrieves payments by status
-func (uc *PaymentUseCase) GetPaymentsByStatus(status string) ([]*dto.PaymentResponse, error) {
-	payments, err := uc.paymentRepo.GetPaymentsByStatus(entity.PaymentStatus(status))
+func (uc *PaymentUseCase) GetPaymentsByStatus(ctx context.Context, status string) ([]*dto.PaymentResponse, error) {
+	payments, err := uc.paymentRepo.GetPaymentsByStatus(ctx, entity.PaymentStatus(status))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payments by status: %w", err)
 	}
 
-	var responses []*dto.PaymentResponse
-	for _, payment := range payments {
-		items, _ := uc.paymentRepo.GetPaymentItems(payment.ID)
-		response := uc.paymentToResponse(payment)
-		response.Items = uc.itemsToResponse(items)
-		responses = append(responses, response)
-	}
-
-	return responses, nil
+	return uc.paymentsWithItems(ctx, payments), nil
 }
 
 // GetPaymentsByDateRange retrieves payments by date range
-func (uc *PaymentUseCase) GetPaymentsByDateRange(startDate, endDate string) ([]*dto.PaymentResponse, error) {
-	payments, err := uc.paymentRepo.GetPaymentsByDateRange(startDate, endDate)
+func (uc *PaymentUseCase) GetPaymentsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*dto.PaymentResponse, error) {
+	payments, err := uc.paymentRepo.GetPaymentsByDateRange(ctx, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payments by date range: %w", err)
 	}
 
-	var responses []*dto.PaymentResponse
-	for _, payment := range payments {
-		items, _ := uc.paymentRepo.GetPaymentItems(payment.ID)
-		response := uc.paymentToResponse(payment)
-		response.Items = uc.itemsToResponse(items)
-		responses = append(responses, response)
-	}
-
-	return responses, nil
+	return uc.paymentsWithItems(ctx, payments), nil
 }
 
-// GetPaymentsByAmountRange retrieves payments by amount range
-func (uc *PaymentUseCase) GetPaymentsByAmountRange(minAmount, maxAmount float64) ([]*dto.PaymentResponse, error) {
-	payments, err := uc.paymentRepo.GetPaymentsByAmountRange(minAmount, maxAmount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get payments by amount range: %w", err)
+// GetPaymentsByAmountRange retrieves payments by amount range in sort order
+func (uc *PaymentUseCase) GetPaymentsByAmountRange(ctx context.Context, minAmount, maxAmount float64, sort string) ([]*dto.PaymentResponse, error) {
+	if !entity.ValidPaymentSort(sort) {
+		return nil, fmt.Errorf("invalid sort field: %s", sort)
 	}
 
-	var responses []*dto.PaymentResponse
-	for _, payment := range payments {
-		items, _ := uc.paymentRepo.GetPaymentItems(payment.ID)
-		response := uc.paymentToResponse(payment)
-		response.Items = uc.itemsToResponse(items)
-		responses = append(responses, response)
+	payments, err := uc.paymentRepo.GetPaymentsByAmountRange(ctx, minAmount, maxAmount, sort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments by amount range: %w", err)
 	}
 
-	return responses, nil
+	return uc.paymentsWithItems(ctx, payments), nil
 }
 
-// GetPaymentsByMethod retrieves payments by method
-func (uc *PaymentUseCase) GetPaymentsByMethod(method string) ([]*dto.PaymentResponse, error) {
-	payments, err := uc.paymentRepo.GetPaymentsByMethod(method)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get payments by method: %w", err)
+// GetPaymentsByMethod retrieves payments by method in sort order
+func (uc *PaymentUseCase) GetPaymentsByMethod(ctx context.Context, method, sort string) ([]*dto.PaymentResponse, error) {
+	if !entity.ValidPaymentSort(sort) {
+		return nil, fmt.Errorf("invalid sort field: %s", sort)
 	}
 
-	var responses []*dto.PaymentResponse
-	for _, payment := range payments {
-		items, _ := uc.paymentRepo.GetPaymentItems(payment.ID)
-		response := uc.paymentToResponse(payment)
-		response.Items = uc.itemsToResponse(items)
-		responses = append(responses, response)
+	payments, err := uc.paymentRepo.GetPaymentsByMethod(ctx, method, sort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments by method: %w", err)
 	}
 
-	return responses, nil
+	return uc.paymentsWithItems(ctx, payments), nil
 }
 
-// GetPaymentsByProvider retrieves payments by provider
-func (uc *PaymentUseCase) GetPaymentsByProvider(provider string) ([]*dto.PaymentResponse, error) {
-	payments, err := uc.paymentRepo.GetPaymentsByProvider(provider)
+// GetPaymentsByProvider retrieves payments by provider in sort order
+func (uc *PaymentUseCase) GetPaymentsByProvider(ctx context.Context, provider, sort string) ([]*dto.PaymentResponse, error) {
+	if !entity.ValidPaymentSort(sort) {
+		return nil, fmt.Errorf("invalid sort field: %s", sort)
+	}
+
+	payments, err := uc.paymentRepo.GetPaymentsByProvider(ctx, provider, sort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payments by provider: %w", err)
 	}
 
-	var responses []*dto.PaymentResponse
-	for _, payment := range payments {
-		items, _ := uc.paymentRepo.GetPaymentItems(payment.ID)
-		response := uc.paymentToResponse(payment)
-		response.Items = uc.itemsToResponse(items)
-		responses = append(responses, response)
+	return uc.paymentsWithItems(ctx, payments), nil
+}
+
+// GetPaymentsByMetadata retrieves a page of payments whose metadata maps key to value
+func (uc *PaymentUseCase) GetPaymentsByMetadata(ctx context.Context, key, value string, limit, offset int, sort string) (*dto.PaginatedPaymentsResponse, error) {
+	if !entity.ValidMetadataKey(key) {
+		return nil, fmt.Errorf("invalid metadata key %q: only letters, digits and underscores are allowed", key)
+	}
+	if !entity.ValidPaymentSort(sort) {
+		return nil, fmt.Errorf("invalid sort field: %s", sort)
 	}
 
-	return responses, nil
+	payments, total, err := uc.paymentRepo.GetPaymentsByMetadata(ctx, key, value, limit, offset, sort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments by metadata: %w", err)
+	}
+
+	return &dto.PaginatedPaymentsResponse{
+		Payments: uc.paymentsWithItems(ctx, payments),
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
 }
 
 // GetPaymentItems retrieves payment items
-func (uc *PaymentUseCase) GetPaymentItems(paymentID string) ([]dto.PaymentItemResponse, error) {
-	items, err := uc.paymentRepo.GetPaymentItems(paymentID)
+func (uc *PaymentUseCase) GetPaymentItems(ctx context.Context, paymentID string) ([]dto.PaymentItemResponse, error) {
+	items, err := uc.paymentRepo.GetPaymentItems(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment items: %w", err)
 	}
@@ -488,27 +839,30 @@ func (uc *PaymentUseCase) GetPaymentItems(paymentID string) ([]dto.PaymentItemRe
 }
 
 // GetPaymentAnalytics retrieves payment analytics
-func (uc *PaymentUseCase) GetPaymentAnalytics() (*dto.PaymentAnalyticsResponse, error) {
-	analytics, err := uc.paymentRepo.GetPaymentAnalytics()
+func (uc *PaymentUseCase) GetPaymentAnalytics(ctx context.Context) (*dto.PaymentAnalyticsResponse, error) {
+	analytics, err := uc.paymentRepo.GetPaymentAnalytics(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment analytics: %w", err)
 	}
 
 	return &dto.PaymentAnalyticsResponse{
-		TotalPayments:     analytics.TotalPayments,
-		TotalRevenue:      analytics.TotalRevenue,
-		SuccessRate:       analytics.SuccessRate,
-		AverageAmount:     analytics.AverageAmount,
-		TopPaymentMethod:  analytics.TopPaymentMethod,
-		TopProvider:       analytics.TopProvider,
-		DailyTransactions: analytics.DailyTransactions,
-		MonthlyRevenue:    analytics.MonthlyRevenue,
+		TotalPayments:       analytics.TotalPayments,
+		GrossRevenue:        analytics.TotalRevenue,
+		TotalFees:           analytics.TotalFees,
+		NetRevenue:          analytics.NetRevenue,
+		SuccessRate:         analytics.SuccessRate,
+		AverageAmount:       analytics.AverageAmount,
+		TopPaymentMethod:    analytics.TopPaymentMethod,
+		TopProvider:         analytics.TopProvider,
+		DailyTransactions:   analytics.DailyTransactions,
+		MonthlyGrossRevenue: analytics.MonthlyRevenue,
+		MonthlyNetRevenue:   analytics.MonthlyNetRevenue,
 	}, nil
 }
 
 // GetPaymentMethods retrieves available payment methods
-func (uc *PaymentUseCase) GetPaymentMethods() (*dto.PaymentMethodsResponse, error) {
-	methods, err := uc.paymentRepo.GetPaymentMethods()
+func (uc *PaymentUseCase) GetPaymentMethods(ctx context.Context) (*dto.PaymentMethodsResponse, error) {
+	methods, err := uc.paymentRepo.GetPaymentMethods(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment methods: %w", err)
 	}
@@ -520,8 +874,8 @@ func (uc *PaymentUseCase) GetPaymentMethods() (*dto.PaymentMethodsResponse, erro
 }
 
 // GetPaymentProviders retrieves available payment providers
-func (uc *PaymentUseCase) GetPaymentProviders() (*dto.PaymentProvidersResponse, error) {
-	providers, err := uc.paymentRepo.GetPaymentProviders()
+func (uc *PaymentUseCase) GetPaymentProviders(ctx context.Context) (*dto.PaymentProvidersResponse, error) {
+	providers, err := uc.paymentRepo.GetPaymentProviders(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment providers: %w", err)
 	}
@@ -532,39 +886,78 @@ func (uc *PaymentUseCase) GetPaymentProviders() (*dto.PaymentProvidersResponse,
 	}, nil
 }
 
-// GetPaymentSummary retrieves payment summary
-func (uc *PaymentUseCase) GetPaymentSummary() (*dto.PaymentSummaryResponse, error) {
-	summary, err := uc.paymentRepo.GetPaymentSummary()
+// GetPaymentSummary retrieves payment summary.
+// When targetCurrency is set, revenue is converted into it; the per-currency breakdown is always returned.
+func (uc *PaymentUseCase) GetPaymentSummary(ctx context.Context, targetCurrency string) (*dto.PaymentSummaryResponse, error) {
+	summary, err := uc.paymentRepo.GetPaymentSummary(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment summary: %w", err)
 	}
 
-	return &dto.PaymentSummaryResponse{
+	currency, combined, err := uc.combineCurrencyTotals(ctx, summary.ByCurrency, targetCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PaymentSummaryResponse{
 		TotalPayments:     summary.TotalPayments,
-		TotalRevenue:      summary.TotalRevenue,
 		PendingPayments:   summary.PendingPayments,
 		CompletedPayments: summary.CompletedPayments,
 		FailedPayments:    summary.FailedPayments,
 		RefundedPayments:  summary.RefundedPayments,
 		SuccessRate:       summary.SuccessRate,
-		AverageAmount:     summary.AverageAmount,
-	}, nil
+		Currency:          currency,
+		ByCurrency:        currencyTotalsToResponse(summary.ByCurrency),
+	}
+	if currency != "" {
+		response.GrossRevenue = combined.TotalAmount
+		response.TotalFees = combined.TotalFees
+		response.NetRevenue = combined.NetAmount
+		if combined.Payments > 0 {
+			response.AverageAmount = combined.TotalAmount / float64(combined.Payments)
+		}
+	}
+	return response, nil
 }
 
-// CancelPayment cancels a payment
-func (uc *PaymentUseCase) CancelPayment(paymentID string) (*dto.PaymentResponse, error) {
-	payment, err := uc.paymentRepo.GetPayment(paymentID)
-	if err != nil {
+// GetPaymentAuditLog returns the status change trail of a payment, oldest first
+func (uc *PaymentUseCase) GetPaymentAuditLog(ctx context.Context, paymentID string) (*dto.PaymentAuditLogResponse, error) {
+	if _, err := uc.paymentRepo.GetPayment(ctx, paymentID); err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 
-	if !payment.CanBeCancelled() {
-		return nil, fmt.Errorf("payment cannot be cancelled, current status: %s", payment.Status)
+	logs, err := uc.paymentRepo.GetPaymentAuditLogs(ctx, paymentID)
+	if err != nil {
+		return nil, err
 	}
 
-	payment.MarkAsCancelled()
-	if err := uc.paymentRepo.UpdatePayment(payment); err != nil {
-		return nil, fmt.Errorf("failed to update payment: %w", err)
+	response := &dto.PaymentAuditLogResponse{
+		PaymentID: paymentID,
+		Entries:   make([]dto.PaymentAuditEntryResponse, len(logs)),
+		Count:     len(logs),
+	}
+	for i, log := range logs {
+		response.Entries[i] = dto.PaymentAuditEntryResponse{
+			FromStatus: string(log.FromStatus),
+			ToStatus:   string(log.ToStatus),
+			Actor:      log.Actor,
+			Reason:     log.Reason,
+			Metadata:   log.Metadata,
+			CreatedAt:  log.CreatedAt,
+		}
+	}
+	return response, nil
+}
+
+// CancelPayment cancels a payment on behalf of actor
+func (uc *PaymentUseCase) CancelPayment(ctx context.Context, paymentID, actor string) (*dto.PaymentResponse, error) {
+	payment, err := uc.paymentRepo.GetPayment(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if err := uc.cancelPayment(ctx, payment, actor, "cancelled"); err != nil {
+		return nil, err
 	}
 
 	response := uc.paymentToResponse(payment)
@@ -577,9 +970,81 @@ func (uc *PaymentUseCase) CancelPayment(paymentID string) (*dto.PaymentResponse,
 	return response, nil
 }
 
-// RetryPayment retries a failed payment
-func (uc *PaymentUseCase) RetryPayment(paymentID string) (*dto.PaymentResponse, error) {
-	payment, err := uc.paymentRepo.GetPayment(paymentID)
+// CancelAllPayments cancels every pending payment of a user on behalf of actor, e.g. when the
+// account is suspended. Payments that a concurrent request moves on first are reported as failed.
+func (uc *PaymentUseCase) CancelAllPayments(ctx context.Context, userID, actor string) (*dto.CancelAllPaymentsResponse, error) {
+	payments, err := uc.paymentRepo.GetUserPaymentsByStatus(ctx, userID, entity.PaymentStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending payments: %w", err)
+	}
+
+	response := &dto.CancelAllPaymentsResponse{
+		UserID:       userID,
+		CancelledIDs: []string{},
+		Failed:       []dto.CancelFailureResponse{},
+	}
+	for _, payment := range payments {
+		if err := uc.cancelPayment(ctx, payment, actor, "cancelled with all pending payments of the user"); err != nil {
+			response.Failed = append(response.Failed, dto.CancelFailureResponse{PaymentID: payment.ID, Error: err.Error()})
+			continue
+		}
+		response.CancelledIDs = append(response.CancelledIDs, payment.ID)
+	}
+	response.Cancelled = len(response.CancelledIDs)
+
+	uc.logger.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"actor":     actor,
+		"cancelled": response.Cancelled,
+		"failed":    len(response.Failed),
+	}).Info("Cancelled pending payments of user")
+
+	return response, nil
+}
+
+// cancelPayment cancels a payment and stores a payment cancelled event in the outbox with the
+// status change. The update only applies if the stored status is unchanged since the payment was read,
+// so a cancellation never overwrites a concurrent processing result.
+func (uc *PaymentUseCase) cancelPayment(ctx context.Context, payment *entity.Payment, actor, reason string) error {
+	if !payment.CanBeCancelled() {
+		return fmt.Errorf("payment cannot be cancelled, current status: %s", payment.Status)
+	}
+
+	fromStatus := payment.Status
+	if err := payment.MarkAsCancelled(); err != nil {
+		return err
+	}
+
+	paymentCancelledEvent := &events.PaymentCancelledEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.PaymentCancelledEventType,
+		Timestamp: time.Now(),
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		BasketID:  payment.BasketID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Reason:    reason,
+		Metadata: map[string]interface{}{
+			"actor":       actor,
+			"from_status": string(fromStatus),
+		},
+	}
+	outboxEvent, err := entity.NewOutboxEvent(payment.ID, paymentCancelledEvent.EventType, paymentCancelledEvent)
+	if err != nil {
+		return err
+	}
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, reason, nil)
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, []*entity.OutboxEvent{outboxEvent}); err != nil {
+		payment.Status = fromStatus
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	return nil
+}
+
+// RetryPayment retries a failed payment on behalf of actor
+func (uc *PaymentUseCase) RetryPayment(ctx context.Context, paymentID, actor string) (*dto.PaymentResponse, error) {
+	payment, err := uc.paymentRepo.GetPayment(ctx, paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
@@ -588,14 +1053,113 @@ func (uc *PaymentUseCase) RetryPayment(paymentID string) (*dto.PaymentResponse,
 		return nil, fmt.Errorf("payment cannot be retried, current status: %s", payment.Status)
 	}
 
+	if uc.retryConfig.MaxAttempts > 0 && payment.Attempts >= uc.retryConfig.MaxAttempts {
+		metrics.RecordPaymentRetry(metrics.RetryResultLimitReached)
+		return nil, fmt.Errorf("payment retry limit reached: %d of %d attempts used", payment.Attempts, uc.retryConfig.MaxAttempts)
+	}
+
+	now := time.Now()
+	if wait := payment.RetryCooldownRemaining(uc.retryConfig.Cooldown, now); wait > 0 {
+		metrics.RecordPaymentRetry(metrics.RetryResultCoolingDown)
+		return nil, fmt.Errorf("payment retry cooldown: try again in %s", wait.Round(time.Second))
+	}
+
 	// Reset to pending status for retry
-	payment.MarkAsPending()
-	if err := uc.paymentRepo.UpdatePayment(payment); err != nil {
+	fromStatus := payment.Status
+	if err := payment.MarkAsPending(); err != nil {
+		return nil, err
+	}
+	payment.RecordRetryAttempt(now)
+	metrics.RecordPaymentRetry(metrics.RetryResultAccepted)
+
+	uc.logger.WithFields(logrus.Fields{
+		"payment_id": paymentID,
+		"attempt":    payment.Attempts,
+	}).Info("Retrying payment")
+
+	audit := entity.NewPaymentAuditLog(payment, fromStatus, actor, "retry requested", map[string]string{
+		"attempt": strconv.Itoa(payment.Attempts),
+	})
+	if err := uc.paymentRepo.UpdatePaymentFromStatus(ctx, payment, fromStatus, audit, nil); err != nil {
 		return nil, fmt.Errorf("failed to update payment: %w", err)
 	}
 
 	// Process the payment again
-	return uc.ProcessPayment(paymentID, "")
+	return uc.ProcessPayment(ctx, paymentID, "", actor)
+}
+
+// paymentCompletedOutboxEvents builds the payment completed, stock update and basket cleared events for the outbox
+func (uc *PaymentUseCase) paymentCompletedOutboxEvents(payment *entity.Payment, items []*entity.PaymentItem) ([]*entity.OutboxEvent, error) {
+	now := time.Now()
+	var outboxEvents []*entity.OutboxEvent
+
+	paymentCompletedEvent := &events.PaymentCompletedEvent{
+		EventID:       uuid.New().String(),
+		EventType:     events.PaymentCompletedEventType,
+		SchemaVersion: events.PaymentCompletedSchemaVersion,
+		Timestamp:     now,
+		PaymentID:     payment.ID,
+		UserID:        payment.UserID,
+		BasketID:      payment.BasketID,
+		Amount:        payment.Amount,
+		FeeAmount:     payment.FeeAmount,
+		NetAmount:     payment.NetAmount,
+		Currency:      payment.Currency,
+		Items:         uc.convertToPaymentItemEvents(items),
+		Metadata:      uc.convertMetadata(payment.Metadata),
+	}
+	outboxEvent, err := entity.NewOutboxEvent(payment.ID, paymentCompletedEvent.EventType, paymentCompletedEvent)
+	if err != nil {
+		return nil, err
+	}
+	outboxEvents = append(outboxEvents, outboxEvent)
+
+	for _, item := range items {
+		stockUpdateEvent := &events.StockUpdateEvent{
+			EventID:   uuid.New().String(),
+			EventType: events.StockUpdateEventType,
+			Timestamp: now,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Operation: "decrease",
+			Reason:    "Payment completed",
+			Metadata: map[string]interface{}{
+				"payment_id": payment.ID,
+				"user_id":    payment.UserID,
+			},
+		}
+		outboxEvent, err := entity.NewOutboxEvent(payment.ID, stockUpdateEvent.EventType, stockUpdateEvent)
+		if err != nil {
+			return nil, err
+		}
+		outboxEvents = append(outboxEvents, outboxEvent)
+	}
+
+	basketClearedEvent := &events.BasketClearedEvent{
+		EventID:   uuid.New().String(),
+		EventType: events.BasketClearedEventType,
+		Timestamp: now,
+		UserID:    payment.UserID,
+		BasketID:  payment.BasketID,
+		Reason:    "Payment completed",
+		Metadata: map[string]interface{}{
+			"payment_id": payment.ID,
+		},
+	}
+	// A partial checkout only takes the paid items out of the basket
+	if payment.Partial {
+		basketClearedEvent.Reason = "Partial checkout completed"
+		for _, item := range items {
+			basketClearedEvent.ProductIDs = append(basketClearedEvent.ProductIDs, item.ProductID)
+		}
+	}
+	outboxEvent, err = entity.NewOutboxEvent(payment.ID, basketClearedEvent.EventType, basketClearedEvent)
+	if err != nil {
+		return nil, err
+	}
+	outboxEvents = append(outboxEvents, outboxEvent)
+
+	return outboxEvents, nil
 }
 
 // convertToPaymentItemEvents converts entity.PaymentItem slice to events.PaymentItemEvent slice
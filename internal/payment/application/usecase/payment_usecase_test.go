@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+	"obs-tools-usage/internal/payment/domain/repository"
+	"obs-tools-usage/internal/payment/domain/service"
+	"obs-tools-usage/internal/payment/infrastructure/config"
+)
+
+// fakePaymentRepository stores created payments in memory. A non-zero createDelay makes
+// CreatePaymentWithItems wait that long, giving up early when its context is done.
+// Methods the tests do not exercise panic through the nil embedded interface.
+type fakePaymentRepository struct {
+	repository.PaymentRepository
+	createDelay time.Duration
+	created     []*entity.Payment
+}
+
+func (r *fakePaymentRepository) CreatePaymentWithItems(ctx context.Context, payment *entity.Payment, items []entity.PaymentItem) error {
+	if err := wait(ctx, r.createDelay); err != nil {
+		return err
+	}
+	r.created = append(r.created, payment)
+	return nil
+}
+
+// fakeBasketClient returns a copy of its basket after delay, giving up early when the context is done
+type fakeBasketClient struct {
+	service.BasketClient
+	basket *service.BasketInfo
+	delay  time.Duration
+}
+
+func (c *fakeBasketClient) GetBasket(ctx context.Context, userID string) (*service.BasketInfo, error) {
+	if err := wait(ctx, c.delay); err != nil {
+		return nil, err
+	}
+	basket := *c.basket
+	basket.Items = append([]service.BasketItem(nil), c.basket.Items...)
+	return &basket, nil
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newTestPaymentUseCase(repo repository.PaymentRepository, basketClient service.BasketClient, checkout config.CheckoutConfig) *PaymentUseCase {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewPaymentUseCase(repo, basketClient, nil, nil, logger,
+		config.ExpiryConfig{}, config.RetryConfig{}, nil, nil, config.FeeConfig{}, config.ProcessingConfig{},
+		config.CurrencyConfig{Default: "USD", Allowed: []string{"USD"}}, checkout)
+}
+
+func testBasket(price float64, quantity int) *service.BasketInfo {
+	subtotal := price * float64(quantity)
+	return &service.BasketInfo{
+		ID:     "basket-1",
+		UserID: "user-1",
+		Items: []service.BasketItem{
+			{ProductID: 1, Name: "Laptop", Price: price, Quantity: quantity, Subtotal: subtotal, Category: "electronics"},
+		},
+		Total:     subtotal,
+		ItemCount: quantity,
+	}
+}
+
+func createTestPayment(uc *PaymentUseCase) error {
+	_, err := uc.CreatePayment(context.Background(), "user-1", "basket-1", string(entity.PaymentMethodCreditCard), "stripe", "USD", "", nil, nil)
+	return err
+}
+
+func TestCreatePaymentTimesOut(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	tests := []struct {
+		name        string
+		basketDelay time.Duration
+		createDelay time.Duration
+	}{
+		{name: "slow basket service", basketDelay: 5 * time.Second},
+		{name: "slow database", createDelay: 5 * time.Second},
+		{name: "budget spent across both", basketDelay: 30 * time.Millisecond, createDelay: 30 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePaymentRepository{createDelay: tt.createDelay}
+			basketClient := &fakeBasketClient{basket: testBasket(100, 1), delay: tt.basketDelay}
+			uc := newTestPaymentUseCase(repo, basketClient, config.CheckoutConfig{Timeout: timeout})
+
+			start := time.Now()
+			err := createTestPayment(uc)
+			elapsed := time.Since(start)
+
+			if err == nil {
+				t.Fatal("expected the checkout to time out")
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error %v does not wrap context.DeadlineExceeded", err)
+			}
+			// "timed out" is what the HTTP layer maps to 504 Gateway Timeout
+			if !strings.Contains(err.Error(), "timed out") {
+				t.Errorf("error %q does not report a timeout", err)
+			}
+			if elapsed > time.Second {
+				t.Errorf("checkout returned after %s, want about %s", elapsed, timeout)
+			}
+			if len(repo.created) != 0 {
+				t.Errorf("%d payments stored by a timed out checkout", len(repo.created))
+			}
+		})
+	}
+}
+
+func TestCreatePaymentWithinTimeout(t *testing.T) {
+	repo := &fakePaymentRepository{createDelay: 5 * time.Millisecond}
+	basketClient := &fakeBasketClient{basket: testBasket(100, 1), delay: 5 * time.Millisecond}
+	uc := newTestPaymentUseCase(repo, basketClient, config.CheckoutConfig{Timeout: time.Second})
+
+	if err := createTestPayment(uc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.created) != 1 {
+		t.Errorf("%d payments stored, want 1", len(repo.created))
+	}
+}
+
+func TestCreatePaymentRejectsBasketOverMaxValueAfterPriceIncrease(t *testing.T) {
+	repo := &fakePaymentRepository{}
+	// Two laptops at 450 stay under the 1000 limit when they are added
+	basketClient := &fakeBasketClient{basket: testBasket(450, 2)}
+	uc := newTestPaymentUseCase(repo, basketClient, config.CheckoutConfig{MaxValue: 1000})
+
+	if err := createTestPayment(uc); err != nil {
+		t.Fatalf("checkout under the limit: %v", err)
+	}
+
+	// A refresh raises the price to 550; the basket total still shows the add-time value
+	basketClient.basket.Items[0].Price = 550
+	basketClient.basket.Items[0].Subtotal = 1100
+
+	err := createTestPayment(uc)
+	var exceeded *CheckoutValueExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("error %v is not a *CheckoutValueExceededError", err)
+	}
+	if exceeded.Total != 1100 || exceeded.MaxValue != 1000 {
+		t.Errorf("error reports total %.2f and maximum %.2f, want 1100.00 and 1000.00", exceeded.Total, exceeded.MaxValue)
+	}
+	if len(repo.created) != 1 {
+		t.Errorf("%d payments stored, want only the one under the limit", len(repo.created))
+	}
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/payment/application/dto"
+	"obs-tools-usage/internal/payment/domain/entity"
+	"obs-tools-usage/internal/payment/domain/service"
+	"obs-tools-usage/internal/payment/infrastructure/metrics"
+)
+
+const (
+	// reconcileActor is recorded in the payment audit log for changes made by reconciliation
+	reconcileActor = "reconciliation"
+	// providerNoRecordCode is recorded when the provider never received a processing payment's charge
+	providerNoRecordCode = "provider_no_record"
+)
+
+// ReconcilePayments asks the provider for the authoritative status of up to limit payments
+// that have been processing for longer than staleAfter, e.g. because the provider's answer
+// or webhook was lost, and moves each disagreeing payment to the provider's status,
+// publishing the matching events through the outbox.
+// Pending payments are not reconciled: they have not been sent to the provider yet.
+func (uc *PaymentUseCase) ReconcilePayments(ctx context.Context, staleAfter time.Duration, limit int) (*dto.ReconcileRunResponse, error) {
+	run := &dto.ReconcileRunResponse{
+		StartedAt: time.Now(),
+		Details:   []dto.ReconcileMismatchResponse{},
+	}
+
+	payments, err := uc.paymentRepo.GetStalePayments(ctx, entity.PaymentStatusProcessing, run.StartedAt.Add(-staleAfter), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, payment := range payments {
+		if ctx.Err() != nil {
+			break
+		}
+		run.Checked++
+
+		detail, mismatch := uc.reconcilePayment(ctx, payment)
+		switch {
+		case detail.Error != "":
+			run.Errors++
+			metrics.RecordReconcileResult(metrics.ReconcileResultError)
+		case detail.Fixed:
+			run.Fixed++
+			metrics.RecordReconcileResult(metrics.ReconcileResultFixed)
+		default:
+			metrics.RecordReconcileResult(metrics.ReconcileResultMatched)
+		}
+		if mismatch {
+			run.Mismatches++
+		}
+		if mismatch || detail.Error != "" {
+			run.Details = append(run.Details, detail)
+		}
+	}
+	run.FinishedAt = time.Now()
+
+	uc.reconcileMu.Lock()
+	uc.reconcileReport.Runs++
+	uc.reconcileReport.Checked += run.Checked
+	uc.reconcileReport.Mismatches += run.Mismatches
+	uc.reconcileReport.Fixed += run.Fixed
+	uc.reconcileReport.Errors += run.Errors
+	uc.reconcileReport.LastRun = run
+	uc.reconcileMu.Unlock()
+
+	if run.Mismatches > 0 || run.Errors > 0 {
+		uc.logger.WithFields(logrus.Fields{
+			"checked":    run.Checked,
+			"mismatches": run.Mismatches,
+			"fixed":      run.Fixed,
+			"errors":     run.Errors,
+		}).Warn("Payment reconciliation found mismatches")
+	}
+
+	return run, nil
+}
+
+// reconcilePayment checks one processing payment with the provider and applies the provider's
+// status. It reports whether the local status disagreed with the provider.
+func (uc *PaymentUseCase) reconcilePayment(ctx context.Context, payment *entity.Payment) (dto.ReconcileMismatchResponse, bool) {
+	detail := dto.ReconcileMismatchResponse{
+		PaymentID:   payment.ID,
+		LocalStatus: string(payment.Status),
+		CheckedAt:   time.Now(),
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, providerChargeTimeout)
+	defer cancel()
+
+	result, err := uc.provider.GetPaymentStatus(statusCtx, payment)
+	if err != nil {
+		uc.logger.WithError(err).WithField("payment_id", payment.ID).Error("Failed to query payment status from provider")
+		detail.Error = err.Error()
+		return detail, false
+	}
+	detail.ProviderStatus = string(result.Status)
+
+	switch result.Status {
+	case service.ProviderStatusPending:
+		// The provider is still working on the charge; check again on the next run
+		return detail, false
+
+	case service.ProviderStatusCompleted:
+		if result.Reference != "" && payment.ProviderID == "" {
+			payment.ProviderID = result.Reference
+		}
+		items, err := uc.paymentRepo.GetPaymentItems(ctx, payment.ID)
+		if err != nil {
+			detail.Error = fmt.Sprintf("failed to get payment items: %v", err)
+			return detail, true
+		}
+		if err := uc.completeChargedPayment(ctx, payment, items, reconcileActor, "reconciled: provider reports charge completed"); err != nil {
+			detail.Error = err.Error()
+			return detail, true
+		}
+
+	case service.ProviderStatusFailed, service.ProviderStatusNotFound:
+		decline := &service.ChargeResult{ErrorCode: result.ErrorCode, Reason: result.Reason}
+		if result.Status == service.ProviderStatusNotFound {
+			decline = &service.ChargeResult{ErrorCode: providerNoRecordCode, Reason: "The payment provider has no record of the charge"}
+		}
+		if _, err := uc.failDeclinedPayment(ctx, payment, decline, reconcileActor); err != nil {
+			detail.Error = err.Error()
+			return detail, true
+		}
+
+	default:
+		detail.Error = fmt.Sprintf("unknown provider status %q", result.Status)
+		return detail, false
+	}
+
+	detail.Fixed = true
+	detail.NewStatus = string(payment.Status)
+	uc.logger.WithFields(logrus.Fields{
+		"payment_id":      payment.ID,
+		"provider_status": result.Status,
+		"new_status":      payment.Status,
+	}).Info("Payment reconciled with provider")
+	return detail, true
+}
+
+// GetReconcileReport returns a summary of the reconciliation runs since the service started
+func (uc *PaymentUseCase) GetReconcileReport() *dto.ReconcileReportResponse {
+	uc.reconcileMu.Lock()
+	defer uc.reconcileMu.Unlock()
+
+	report := uc.reconcileReport
+	return &report
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"obs-tools-usage/internal/payment/application/dto"
+	"obs-tools-usage/internal/payment/domain/entity"
+)
+
+// GetUserTimeline returns up to limit of a user's payment events that happened after the cursor,
+// oldest first. Payment creations are merged with the audit log's status changes, which include
+// refunds and their amounts. Events sharing a timestamp are kept on the same page, so the next
+// page can resume strictly after the last event's time, unless more than limit events share it.
+func (uc *PaymentUseCase) GetUserTimeline(ctx context.Context, userID string, after time.Time, limit int) (*dto.UserTimelineResponse, error) {
+	// Fetch one extra event from each source to know whether another page follows
+	payments, err := uc.paymentRepo.GetUserPaymentsCreatedAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user timeline: %w", err)
+	}
+	logs, err := uc.paymentRepo.GetUserAuditLogsAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user timeline: %w", err)
+	}
+
+	events := make([]dto.TimelineEventResponse, 0, len(payments)+len(logs))
+	for _, payment := range payments {
+		events = append(events, paymentCreatedEvent(payment))
+	}
+	for _, log := range logs {
+		events = append(events, auditLogEvent(log))
+	}
+	// Stable, so a payment's creation stays ahead of a status change recorded at the same instant
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	response := &dto.UserTimelineResponse{UserID: userID}
+	if len(events) > limit {
+		cut := limit
+		for cut > 0 && events[cut-1].Timestamp.Equal(events[cut].Timestamp) {
+			cut--
+		}
+		if cut == 0 {
+			cut = limit
+		}
+		events = events[:cut]
+		response.NextCursor = events[cut-1].Timestamp.Format(time.RFC3339Nano)
+	}
+	response.Events = events
+	response.Count = len(events)
+	return response, nil
+}
+
+// paymentCreatedEvent converts the creation of a payment into a timeline event
+func paymentCreatedEvent(payment *entity.Payment) dto.TimelineEventResponse {
+	amount := payment.Amount
+	return dto.TimelineEventResponse{
+		Type:      dto.TimelineEventPaymentCreated,
+		Timestamp: payment.CreatedAt,
+		PaymentID: payment.ID,
+		Amount:    &amount,
+		Currency:  payment.Currency,
+		ToStatus:  string(entity.PaymentStatusPending),
+		Details: map[string]string{
+			"basket_id": payment.BasketID,
+			"method":    string(payment.Method),
+			"provider":  payment.Provider,
+		},
+	}
+}
+
+// auditLogEvent converts a recorded payment status change into a timeline event
+func auditLogEvent(log *entity.PaymentAuditLog) dto.TimelineEventResponse {
+	event := dto.TimelineEventResponse{
+		Type:       "payment_" + string(log.ToStatus),
+		Timestamp:  log.CreatedAt,
+		PaymentID:  log.PaymentID,
+		FromStatus: string(log.FromStatus),
+		ToStatus:   string(log.ToStatus),
+		Actor:      log.Actor,
+		Reason:     log.Reason,
+		Details:    log.Metadata,
+	}
+	if log.FromStatus == entity.PaymentStatusFailed && log.ToStatus == entity.PaymentStatusPending {
+		event.Type = dto.TimelineEventPaymentRetried
+	}
+	if refunded, err := strconv.ParseFloat(log.Metadata["refund_amount"], 64); err == nil {
+		event.Amount = &refunded
+	}
+	return event
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// PaymentAuditLog is an immutable record of a payment status change: who or what moved the
+// payment from one status to another, when and why. Rows are only ever inserted.
+type PaymentAuditLog struct {
+	ID         uint              `json:"id" gorm:"primaryKey;autoIncrement"`
+	PaymentID  string            `json:"payment_id" gorm:"not null;index"`
+	FromStatus PaymentStatus     `json:"from_status" gorm:"not null"`
+	ToStatus   PaymentStatus     `json:"to_status" gorm:"not null"`
+	Actor      string            `json:"actor" gorm:"not null"` // User ID of the caller, or the subsystem that made the change
+	Reason     string            `json:"reason"`
+	Metadata   map[string]string `json:"metadata" gorm:"type:json;serializer:json"`
+	CreatedAt  time.Time         `json:"created_at" gorm:"not null;index"`
+}
+
+// TableName overrides the table name used by PaymentAuditLog
+func (PaymentAuditLog) TableName() string {
+	return "payment_audit_logs"
+}
+
+// NewPaymentAuditLog records the move of payment from fromStatus to its current status
+func NewPaymentAuditLog(payment *Payment, fromStatus PaymentStatus, actor, reason string, metadata map[string]string) *PaymentAuditLog {
+	return &PaymentAuditLog{
+		PaymentID:  payment.ID,
+		FromStatus: fromStatus,
+		ToStatus:   payment.Status,
+		Actor:      actor,
+		Reason:     reason,
+		Metadata:   metadata,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxEvent represents an event stored alongside a payment change and relayed to Kafka afterwards
+type OutboxEvent struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	AggregateID   string    `json:"aggregate_id" gorm:"not null;index"`
+	EventType     string    `json:"event_type" gorm:"not null"`
+	Payload       string    `json:"payload" gorm:"type:text;not null"`
+	Attempts      int       `json:"attempts" gorm:"not null;default:0"`
+	LastError     string    `json:"last_error" gorm:"type:text"`
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"not null;index"`
+	CreatedAt     time.Time `json:"created_at"`
+	// PublishedAt is set once the event reached Kafka; published rows are kept until the retention window passes
+	PublishedAt *time.Time `json:"published_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name used by OutboxEvent
+func (OutboxEvent) TableName() string {
+	return "payment_outbox"
+}
+
+// NewOutboxEvent serializes an event payload into an outbox row that is due immediately
+func NewOutboxEvent(aggregateID, eventType string, payload interface{}) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s outbox event: %w", eventType, err)
+	}
+
+	now := time.Now()
+	return &OutboxEvent{
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(data),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
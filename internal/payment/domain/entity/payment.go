@@ -1,26 +1,71 @@
 package entity
 
 import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// metadataKeyPattern matches the metadata keys payments can be searched by
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// ValidMetadataKey reports whether payments can be searched by a metadata key: letters, digits
+// and underscores only, so the key is safe to embed in JSON paths and column names
+func ValidMetadataKey(key string) bool {
+	return metadataKeyPattern.MatchString(key)
+}
+
+// DefaultPaymentSort orders payment lists latest first
+const DefaultPaymentSort = "-created_at"
+
+// paymentSortFields are the fields payment lists can be ordered by
+var paymentSortFields = map[string]bool{
+	"created_at":   true,
+	"updated_at":   true,
+	"processed_at": true,
+	"amount":       true,
+}
+
+// PaymentSortField returns the field a sort orders by and whether the order is descending.
+// Sorts name a field, prefixed with "-" for descending; an empty sort is DefaultPaymentSort.
+func PaymentSortField(sort string) (string, bool) {
+	if sort == "" {
+		sort = DefaultPaymentSort
+	}
+	field := strings.TrimPrefix(sort, "-")
+	return field, field != sort
+}
+
+// ValidPaymentSort reports whether a sort orders by a sortable field
+func ValidPaymentSort(sort string) bool {
+	field, _ := PaymentSortField(sort)
+	return paymentSortFields[field]
+}
+
 // Payment represents a payment transaction
 type Payment struct {
 	ID          string            `json:"id" gorm:"primaryKey"`
 	UserID      string            `json:"user_id" gorm:"not null;index"`
 	BasketID    string            `json:"basket_id" gorm:"not null;index"`
 	Amount      float64           `json:"amount" gorm:"not null"`
+	FeeAmount   float64           `json:"fee_amount" gorm:"not null;default:0"` // Provider fee, set on completion
+	NetAmount   float64           `json:"net_amount" gorm:"not null;default:0"` // Amount minus FeeAmount
 	Currency    string            `json:"currency" gorm:"not null;default:'USD'"`
 	Status      PaymentStatus     `json:"status" gorm:"not null;default:'pending'"`
 	Method      PaymentMethod     `json:"method" gorm:"not null"`
 	Provider    string            `json:"provider" gorm:"not null"`
 	ProviderID  string            `json:"provider_id" gorm:"index"`
 	Description string            `json:"description"`
-	Metadata    map[string]string `json:"metadata" gorm:"type:json"`
+	Metadata    map[string]string `json:"metadata" gorm:"type:json;serializer:json"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 	ProcessedAt *time.Time        `json:"processed_at"`
 	ExpiresAt   *time.Time        `json:"expires_at"`
+	Attempts    int               `json:"attempts" gorm:"not null;default:0"`
+	LastRetryAt *time.Time        `json:"last_retry_at"`
+	Partial     bool              `json:"partial" gorm:"not null;default:false"` // Only some basket items were checked out
 }
 
 // PaymentStatus represents the status of a payment
@@ -35,6 +80,16 @@ const (
 	PaymentStatusRefunded  PaymentStatus = "refunded"
 )
 
+// paymentTransitions lists the statuses each payment status may legally move to
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:    {PaymentStatusProcessing, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusProcessing: {PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusCompleted:  {PaymentStatusRefunded},
+	PaymentStatusFailed:     {PaymentStatusPending},
+	PaymentStatusCancelled:  {},
+	PaymentStatusRefunded:   {},
+}
+
 // PaymentMethod represents the payment method
 type PaymentMethod string
 
@@ -49,15 +104,43 @@ const (
 
 // PaymentItem represents an item in the payment
 type PaymentItem struct {
-	ID          string  `json:"id" gorm:"primaryKey"`
-	PaymentID   string  `json:"payment_id" gorm:"not null;index"`
-	ProductID   int     `json:"product_id" gorm:"not null"`
-	Name        string  `json:"name" gorm:"not null"`
-	Quantity    int     `json:"quantity" gorm:"not null"`
-	Price       float64 `json:"price" gorm:"not null"`
-	Subtotal    float64 `json:"subtotal" gorm:"not null"`
-	Category    string  `json:"category"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             string    `json:"id" gorm:"primaryKey"`
+	PaymentID      string    `json:"payment_id" gorm:"not null;index"`
+	ProductID      int       `json:"product_id" gorm:"not null"`
+	Name           string    `json:"name" gorm:"not null"`
+	Quantity       int       `json:"quantity" gorm:"not null"`
+	Price          float64   `json:"price" gorm:"not null"`
+	Subtotal       float64   `json:"subtotal" gorm:"not null"`
+	TaxRate        float64   `json:"tax_rate" gorm:"not null;default:0"`
+	TaxAmount      float64   `json:"tax_amount" gorm:"not null;default:0"`
+	DiscountAmount float64   `json:"discount_amount" gorm:"not null;default:0"`
+	Category       string    `json:"category"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// LineTotal returns what the item costs after its discount and tax
+func (i *PaymentItem) LineTotal() float64 {
+	return i.Subtotal + i.TaxAmount - i.DiscountAmount
+}
+
+// CanTransitionTo checks if payment may move from its current status to the given status
+func (p *Payment) CanTransitionTo(status PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[p.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionTo moves payment to the given status if the transition is legal
+func (p *Payment) transitionTo(status PaymentStatus) error {
+	if !p.CanTransitionTo(status) {
+		return fmt.Errorf("invalid payment status transition from %s to %s", p.Status, status)
+	}
+	p.Status = status
+	p.UpdatedAt = time.Now()
+	return nil
 }
 
 // IsCompleted checks if payment is completed
@@ -82,44 +165,42 @@ func (p *Payment) IsProcessing() bool {
 
 // CanBeCancelled checks if payment can be cancelled
 func (p *Payment) CanBeCancelled() bool {
-	return p.Status == PaymentStatusPending || p.Status == PaymentStatusProcessing
+	return p.CanTransitionTo(PaymentStatusCancelled)
 }
 
 // CanBeRefunded checks if payment can be refunded
 func (p *Payment) CanBeRefunded() bool {
-	return p.Status == PaymentStatusCompleted
+	return p.CanTransitionTo(PaymentStatusRefunded)
 }
 
 // MarkAsProcessing marks payment as processing
-func (p *Payment) MarkAsProcessing() {
-	p.Status = PaymentStatusProcessing
-	p.UpdatedAt = time.Now()
+func (p *Payment) MarkAsProcessing() error {
+	return p.transitionTo(PaymentStatusProcessing)
 }
 
 // MarkAsCompleted marks payment as completed
-func (p *Payment) MarkAsCompleted() {
-	p.Status = PaymentStatusCompleted
-	now := time.Now()
-	p.ProcessedAt = &now
-	p.UpdatedAt = now
+func (p *Payment) MarkAsCompleted() error {
+	if err := p.transitionTo(PaymentStatusCompleted); err != nil {
+		return err
+	}
+	processedAt := p.UpdatedAt
+	p.ProcessedAt = &processedAt
+	return nil
 }
 
 // MarkAsFailed marks payment as failed
-func (p *Payment) MarkAsFailed() {
-	p.Status = PaymentStatusFailed
-	p.UpdatedAt = time.Now()
+func (p *Payment) MarkAsFailed() error {
+	return p.transitionTo(PaymentStatusFailed)
 }
 
 // MarkAsCancelled marks payment as cancelled
-func (p *Payment) MarkAsCancelled() {
-	p.Status = PaymentStatusCancelled
-	p.UpdatedAt = time.Now()
+func (p *Payment) MarkAsCancelled() error {
+	return p.transitionTo(PaymentStatusCancelled)
 }
 
 // MarkAsRefunded marks payment as refunded
-func (p *Payment) MarkAsRefunded() {
-	p.Status = PaymentStatusRefunded
-	p.UpdatedAt = time.Now()
+func (p *Payment) MarkAsRefunded() error {
+	return p.transitionTo(PaymentStatusRefunded)
 }
 
 // IsExpired checks if payment is expired
@@ -130,23 +211,55 @@ func (p *Payment) IsExpired() bool {
 	return time.Now().After(*p.ExpiresAt)
 }
 
-// CalculateTotal calculates the total amount from items
+// CalculateTotal calculates the total amount as the sum of the item line totals
 func (p *Payment) CalculateTotal(items []PaymentItem) {
 	total := 0.0
-	for _, item := range items {
-		total += item.Subtotal
+	for i := range items {
+		total += items[i].LineTotal()
 	}
 	p.Amount = total
 	p.UpdatedAt = time.Now()
 }
 
+// ApplyFee records the provider fee for the amount, a percentage plus a fixed part,
+// and the resulting net amount. The fee never exceeds the amount.
+func (p *Payment) ApplyFee(percent, fixed float64) {
+	fee := math.Round((p.Amount*percent/100+fixed)*100) / 100
+	if fee > p.Amount {
+		fee = p.Amount
+	}
+	if fee < 0 {
+		fee = 0
+	}
+	p.FeeAmount = fee
+	p.NetAmount = p.Amount - fee
+}
+
 // MarkAsPending marks payment as pending
-func (p *Payment) MarkAsPending() {
-	p.Status = PaymentStatusPending
-	p.UpdatedAt = time.Now()
+func (p *Payment) MarkAsPending() error {
+	return p.transitionTo(PaymentStatusPending)
+}
+
+// RecordRetryAttempt increments the retry counter and stamps the attempt time
+func (p *Payment) RecordRetryAttempt(at time.Time) {
+	p.Attempts++
+	p.LastRetryAt = &at
+	p.UpdatedAt = at
+}
+
+// RetryCooldownRemaining returns how long until the next retry is allowed after the last one
+func (p *Payment) RetryCooldownRemaining(cooldown time.Duration, now time.Time) time.Duration {
+	if p.LastRetryAt == nil || cooldown <= 0 {
+		return 0
+	}
+	remaining := p.LastRetryAt.Add(cooldown).Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
 }
 
 // CanBeRetried checks if payment can be retried
 func (p *Payment) CanBeRetried() bool {
-	return p.Status == PaymentStatusFailed
+	return p.CanTransitionTo(PaymentStatusPending)
 }
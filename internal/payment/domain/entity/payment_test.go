@@ -0,0 +1,105 @@
+package entity
+
+import "testing"
+
+var allPaymentStatuses = []PaymentStatus{
+	PaymentStatusPending,
+	PaymentStatusProcessing,
+	PaymentStatusCompleted,
+	PaymentStatusFailed,
+	PaymentStatusCancelled,
+	PaymentStatusRefunded,
+}
+
+func TestPaymentCanTransitionTo(t *testing.T) {
+	allowed := map[PaymentStatus]map[PaymentStatus]bool{
+		PaymentStatusPending: {
+			PaymentStatusProcessing: true,
+			PaymentStatusFailed:     true,
+			PaymentStatusCancelled:  true,
+		},
+		PaymentStatusProcessing: {
+			PaymentStatusCompleted: true,
+			PaymentStatusFailed:    true,
+			PaymentStatusCancelled: true,
+		},
+		PaymentStatusCompleted: {
+			PaymentStatusRefunded: true,
+		},
+		PaymentStatusFailed: {
+			PaymentStatusPending: true,
+		},
+		PaymentStatusCancelled: {},
+		PaymentStatusRefunded:  {},
+	}
+
+	for _, from := range allPaymentStatuses {
+		for _, to := range allPaymentStatuses {
+			want := allowed[from][to]
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				payment := &Payment{Status: from}
+				if got := payment.CanTransitionTo(to); got != want {
+					t.Errorf("CanTransitionTo(%s) from %s = %v, want %v", to, from, got, want)
+				}
+
+				err := payment.transitionTo(to)
+				if want {
+					if err != nil {
+						t.Fatalf("transitionTo(%s) from %s: unexpected error: %v", to, from, err)
+					}
+					if payment.Status != to {
+						t.Errorf("status = %s, want %s", payment.Status, to)
+					}
+					return
+				}
+				if err == nil {
+					t.Fatalf("transitionTo(%s) from %s: expected an error", to, from)
+				}
+				if payment.Status != from {
+					t.Errorf("status changed to %s on a rejected transition", payment.Status)
+				}
+			})
+		}
+	}
+}
+
+func TestPaymentMarkAsRejectsIllegalTransitions(t *testing.T) {
+	tests := []struct {
+		name string
+		from PaymentStatus
+		mark func(*Payment) error
+	}{
+		{"complete pending", PaymentStatusPending, (*Payment).MarkAsCompleted},
+		{"process completed", PaymentStatusCompleted, (*Payment).MarkAsProcessing},
+		{"refund pending", PaymentStatusPending, (*Payment).MarkAsRefunded},
+		{"reopen completed", PaymentStatusCompleted, (*Payment).MarkAsPending},
+		{"process refunded", PaymentStatusRefunded, (*Payment).MarkAsProcessing},
+		{"cancel completed", PaymentStatusCompleted, (*Payment).MarkAsCancelled},
+		{"fail cancelled", PaymentStatusCancelled, (*Payment).MarkAsFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{Status: tt.from}
+			if err := tt.mark(payment); err == nil {
+				t.Fatal("expected an error")
+			}
+			if payment.Status != tt.from {
+				t.Errorf("status changed to %s on a rejected transition", payment.Status)
+			}
+		})
+	}
+}
+
+func TestPaymentMarkAsCompletedSetsProcessedAt(t *testing.T) {
+	payment := &Payment{Status: PaymentStatusProcessing}
+	if err := payment.MarkAsCompleted(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.ProcessedAt == nil {
+		t.Fatal("ProcessedAt not set")
+	}
+	if !payment.ProcessedAt.Equal(payment.UpdatedAt) {
+		t.Errorf("ProcessedAt = %v, want %v", payment.ProcessedAt, payment.UpdatedAt)
+	}
+}
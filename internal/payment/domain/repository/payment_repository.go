@@ -1,76 +1,131 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"obs-tools-usage/internal/payment/domain/entity"
 )
 
+// ErrPaymentStatusConflict is returned by UpdatePaymentFromStatus when the stored status
+// of the payment is no longer the status it was read with
+var ErrPaymentStatusConflict = errors.New("payment status conflict")
+
 // PaymentRepository defines the interface for payment data access
 type PaymentRepository interface {
 	// Basic CRUD operations
-	CreatePayment(payment *entity.Payment) error
-	GetPayment(paymentID string) (*entity.Payment, error)
-	UpdatePayment(payment *entity.Payment) error
-	DeletePayment(paymentID string) error
+	CreatePayment(ctx context.Context, payment *entity.Payment) error
+	// CreatePaymentWithItems stores a payment and its items atomically, aborting when ctx is done
+	CreatePaymentWithItems(ctx context.Context, payment *entity.Payment, items []entity.PaymentItem) error
+	GetPayment(ctx context.Context, paymentID string) (*entity.Payment, error)
+	// UpdatePaymentFromStatus saves a payment with its audit entry and outbox events only if its stored
+	// status is still fromStatus, returning ErrPaymentStatusConflict when another request changed it first.
+	// Every status change goes through it so concurrent writers never overwrite each other.
+	UpdatePaymentFromStatus(ctx context.Context, payment *entity.Payment, fromStatus entity.PaymentStatus, audit *entity.PaymentAuditLog, events []*entity.OutboxEvent) error
+	DeletePayment(ctx context.Context, paymentID string) error
 	
 	// Query operations
-	GetPaymentsByUser(userID string) ([]*entity.Payment, error)
-	GetPaymentsByBasket(basketID string) ([]*entity.Payment, error)
-	GetPaymentsByStatus(status entity.PaymentStatus) ([]*entity.Payment, error)
-	GetPaymentsByDateRange(startDate, endDate string) ([]*entity.Payment, error)
+	GetPaymentsByUser(ctx context.Context, userID string, limit, offset int, sort string) ([]*entity.Payment, int64, error)
+	GetPaymentsByBasket(ctx context.Context, basketID string) ([]*entity.Payment, error)
+	GetPaymentsByStatus(ctx context.Context, status entity.PaymentStatus) ([]*entity.Payment, error)
+	GetUserPaymentsByStatus(ctx context.Context, userID string, status entity.PaymentStatus) ([]*entity.Payment, error)
+	GetPaymentsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*entity.Payment, error)
+	// GetStalePayments returns up to limit payments in status that were last updated before updatedBefore, oldest first
+	GetStalePayments(ctx context.Context, status entity.PaymentStatus, updatedBefore time.Time, limit int) ([]*entity.Payment, error)
+	// GetUserPaymentsCreatedAfter returns up to limit payments of a user created after the given time, oldest first.
+	// A zero time returns the user's first payments.
+	GetUserPaymentsCreatedAfter(ctx context.Context, userID string, after time.Time, limit int) ([]*entity.Payment, error)
 	
 	// Payment items
-	CreatePaymentItem(item *entity.PaymentItem) error
-	GetPaymentItems(paymentID string) ([]*entity.PaymentItem, error)
-	DeletePaymentItems(paymentID string) error
+	CreatePaymentItem(ctx context.Context, item *entity.PaymentItem) error
+	GetPaymentItems(ctx context.Context, paymentID string) ([]*entity.PaymentItem, error)
+	GetPaymentItemsForPayments(ctx context.Context, paymentIDs []string) (map[string][]*entity.PaymentItem, error)
+	DeletePaymentItems(ctx context.Context, paymentID string) error
 	
 	// Statistics and analytics
-	GetPaymentStats(userID string) (*PaymentStats, error)
-	GetTotalRevenue(startDate, endDate string) (float64, error)
-	GetPaymentCountByStatus(status entity.PaymentStatus) (int64, error)
+	GetPaymentStats(ctx context.Context, userID string) (*PaymentStats, error)
+	GetTotalRevenue(ctx context.Context, startDate, endDate string) (float64, error)
+	GetPaymentCountByStatus(ctx context.Context, status entity.PaymentStatus) (int64, error)
 	
 	// New query methods
-	GetPaymentsByAmountRange(minAmount, maxAmount float64) ([]*entity.Payment, error)
-	GetPaymentsByMethod(method string) ([]*entity.Payment, error)
-	GetPaymentsByProvider(provider string) ([]*entity.Payment, error)
-	GetPaymentAnalytics() (*PaymentAnalytics, error)
-	GetPaymentMethods() ([]string, error)
-	GetPaymentProviders() ([]string, error)
-	GetPaymentSummary() (*PaymentSummary, error)
+	GetPaymentsByAmountRange(ctx context.Context, minAmount, maxAmount float64, sort string) ([]*entity.Payment, error)
+	GetPaymentsByMethod(ctx context.Context, method, sort string) ([]*entity.Payment, error)
+	GetPaymentsByProvider(ctx context.Context, provider, sort string) ([]*entity.Payment, error)
+	GetPaymentsByMetadata(ctx context.Context, key, value string, limit, offset int, sort string) ([]*entity.Payment, int64, error)
+	GetPaymentAnalytics(ctx context.Context) (*PaymentAnalytics, error)
+	GetPaymentMethods(ctx context.Context) ([]string, error)
+	GetPaymentProviders(ctx context.Context) ([]string, error)
+	GetPaymentSummary(ctx context.Context) (*PaymentSummary, error)
+	
+	// Transactional outbox
+	GetDueOutboxEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, id uint, publishedAt time.Time) error
+	DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error)
+	CountPendingOutboxEvents(ctx context.Context) (int64, error)
+	RecordOutboxFailure(ctx context.Context, id uint, lastError string, nextAttemptAt time.Time) error
 	
+	// Audit log. Entries are written by UpdatePaymentFromStatus together with
+	// the status change they record and are never updated or deleted.
+	GetPaymentAuditLogs(ctx context.Context, paymentID string) ([]*entity.PaymentAuditLog, error)
+	// GetUserAuditLogsAfter returns up to limit audit entries of a user's payments recorded after
+	// the given time, oldest first. A zero time returns the user's first entries.
+	GetUserAuditLogsAfter(ctx context.Context, userID string, after time.Time, limit int) ([]*entity.PaymentAuditLog, error)
+
 	// Health check
 	Ping() error
 }
 
-// PaymentStats represents payment statistics
+// CurrencyTotal represents payment totals for a single currency
+type CurrencyTotal struct {
+	Currency      string  `json:"currency"`
+	Payments      int64   `json:"payments"`
+	TotalAmount   float64 `json:"total_amount"`
+	AverageAmount float64 `json:"average_amount"`
+	TotalFees     float64 `json:"total_fees"`
+	NetAmount     float64 `json:"net_amount"`
+}
+
+// PaymentStats represents payment statistics.
+// Amounts are only comparable within a currency, see ByCurrency.
 type PaymentStats struct {
-	TotalPayments     int64   `json:"total_payments"`
-	TotalAmount       float64 `json:"total_amount"`
-	CompletedPayments int64   `json:"completed_payments"`
-	FailedPayments    int64   `json:"failed_payments"`
-	PendingPayments   int64   `json:"pending_payments"`
-	AverageAmount     float64 `json:"average_amount"`
+	TotalPayments     int64           `json:"total_payments"`
+	TotalAmount       float64         `json:"total_amount"`
+	CompletedPayments int64           `json:"completed_payments"`
+	FailedPayments    int64           `json:"failed_payments"`
+	PendingPayments   int64           `json:"pending_payments"`
+	AverageAmount     float64         `json:"average_amount"`
+	ByCurrency        []CurrencyTotal `json:"by_currency"`
 }
 
-// PaymentAnalytics represents payment analytics
+// PaymentAnalytics represents payment analytics.
+// Revenue figures are gross; fees and net revenue are reported separately.
 type PaymentAnalytics struct {
 	TotalPayments     int64   `json:"total_payments"`
 	TotalRevenue      float64 `json:"total_revenue"`
+	TotalFees         float64 `json:"total_fees"`
+	NetRevenue        float64 `json:"net_revenue"`
 	SuccessRate       float64 `json:"success_rate"`
 	AverageAmount     float64 `json:"average_amount"`
 	TopPaymentMethod  string  `json:"top_payment_method"`
 	TopProvider       string  `json:"top_provider"`
 	DailyTransactions int64   `json:"daily_transactions"`
 	MonthlyRevenue    float64 `json:"monthly_revenue"`
+	MonthlyNetRevenue float64 `json:"monthly_net_revenue"`
 }
 
-// PaymentSummary represents payment summary
+// PaymentSummary represents payment summary.
+// Revenue is only comparable within a currency, see ByCurrency.
 type PaymentSummary struct {
-	TotalPayments     int64   `json:"total_payments"`
-	TotalRevenue      float64 `json:"total_revenue"`
-	PendingPayments   int64   `json:"pending_payments"`
-	CompletedPayments int64   `json:"completed_payments"`
-	FailedPayments    int64   `json:"failed_payments"`
-	RefundedPayments  int64   `json:"refunded_payments"`
-	SuccessRate       float64 `json:"success_rate"`
-	AverageAmount     float64 `json:"average_amount"`
+	TotalPayments     int64           `json:"total_payments"`
+	TotalRevenue      float64         `json:"total_revenue"` // Gross, before provider fees
+	TotalFees         float64         `json:"total_fees"`
+	NetRevenue        float64         `json:"net_revenue"`
+	PendingPayments   int64           `json:"pending_payments"`
+	CompletedPayments int64           `json:"completed_payments"`
+	FailedPayments    int64           `json:"failed_payments"`
+	RefundedPayments  int64           `json:"refunded_payments"`
+	SuccessRate       float64         `json:"success_rate"`
+	AverageAmount     float64         `json:"average_amount"`
+	ByCurrency        []CurrencyTotal `json:"by_currency"` // Completed payment revenue per currency
 }
@@ -30,10 +30,13 @@ type BasketInfo struct {
 
 // BasketItem represents a basket item
 type BasketItem struct {
-	ProductID int     `json:"product_id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Subtotal  float64 `json:"subtotal"`
-	Category  string  `json:"category"`
+	ProductID      int     `json:"product_id"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	Quantity       int     `json:"quantity"`
+	Subtotal       float64 `json:"subtotal"`
+	Category       string  `json:"category"`
+	TaxRate        float64 `json:"tax_rate"`
+	TaxAmount      float64 `json:"tax_amount"`
+	DiscountAmount float64 `json:"discount_amount"`
 }
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+)
+
+// ExchangeRateProvider defines the interface for currency conversion rates
+type ExchangeRateProvider interface {
+	// GetRate returns how many units of the target currency one unit of the source currency is worth
+	GetRate(ctx context.Context, from, to string) (float64, error)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+)
+
+// ChargeResult is a payment provider's answer to a charge request
+type ChargeResult struct {
+	Approved  bool
+	Reference string // Provider side transaction reference, if any
+	ErrorCode string // Machine readable decline code, e.g. card_declined
+	Reason    string
+}
+
+// ProviderPaymentStatus is the provider's authoritative view of a charge
+type ProviderPaymentStatus string
+
+// Provider payment statuses
+const (
+	ProviderStatusCompleted ProviderPaymentStatus = "completed"
+	ProviderStatusFailed    ProviderPaymentStatus = "failed"
+	ProviderStatusPending   ProviderPaymentStatus = "pending"   // The provider is still working on the charge
+	ProviderStatusNotFound  ProviderPaymentStatus = "not_found" // The provider never received the charge
+)
+
+// StatusResult is a payment provider's answer to a status query
+type StatusResult struct {
+	Status    ProviderPaymentStatus
+	Reference string // Provider side transaction reference, if any
+	ErrorCode string // Machine readable decline code for failed charges
+	Reason    string
+}
+
+// PaymentProvider defines the interface for charging payments with an external provider
+type PaymentProvider interface {
+	// Charge asks the provider to capture the payment. A declined charge is reported
+	// through ChargeResult; an error means the provider could not be reached.
+	Charge(ctx context.Context, payment *entity.Payment) (*ChargeResult, error)
+
+	// GetPaymentStatus asks the provider what became of the payment's charge, e.g. when
+	// its answer or webhook was lost. An error means the provider could not be reached.
+	GetPaymentStatus(ctx context.Context, payment *entity.Payment) (*StatusResult, error)
+}
@@ -6,10 +6,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"obs-tools-usage/api/proto/basket"
 	"obs-tools-usage/internal/payment/domain/service"
+	"obs-tools-usage/pkg/grpctls"
+	"obs-tools-usage/pkg/tracing"
 )
 
 // BasketClientImpl implements BasketClient interface using gRPC
@@ -20,9 +21,17 @@ type BasketClientImpl struct {
 }
 
 // NewBasketClientImpl creates a new basket client implementation
-func NewBasketClientImpl(basketServiceURL string, logger *logrus.Logger) (*BasketClientImpl, error) {
+func NewBasketClientImpl(basketServiceURL string, logger *logrus.Logger, tlsConfig grpctls.Config) (*BasketClientImpl, error) {
+	creds, err := tlsConfig.ClientCredentials()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create gRPC connection
-	conn, err := grpc.Dial(basketServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(basketServiceURL,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to basket service: %w", err)
 	}
@@ -67,12 +76,15 @@ func (c *BasketClientImpl) GetBasket(ctx context.Context, userID string) (*servi
 	// Convert basket items
 	for _, item := range resp.Basket.Items {
 		basketInfo.Items = append(basketInfo.Items, service.BasketItem{
-			ProductID: int(item.ProductId),
-			Name:      item.Name,
-			Price:     item.Price,
-			Quantity:  int(item.Quantity),
-			Subtotal:  item.Subtotal,
-			Category:  item.Category,
+			ProductID:      int(item.ProductId),
+			Name:           item.Name,
+			Price:          item.Price,
+			Quantity:       int(item.Quantity),
+			Subtotal:       item.Subtotal,
+			Category:       item.Category,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			DiscountAmount: item.DiscountAmount,
 		})
 	}
 
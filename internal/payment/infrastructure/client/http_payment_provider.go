@@ -0,0 +1,139 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+	"obs-tools-usage/internal/payment/domain/service"
+)
+
+// HTTPPaymentProvider charges payments through an external provider's HTTP API
+type HTTPPaymentProvider struct {
+	url    string
+	client *http.Client
+}
+
+// chargeRequest is the body posted to the provider
+type chargeRequest struct {
+	PaymentID string  `json:"payment_id"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+	Method    string  `json:"method"`
+	Provider  string  `json:"provider"`
+}
+
+// chargeResponse is the provider's answer to a charge request
+type chargeResponse struct {
+	Approved  bool   `json:"approved"`
+	Reference string `json:"reference"`
+	ErrorCode string `json:"error_code"`
+	Reason    string `json:"reason"`
+}
+
+// statusResponse is the provider's answer to a status query
+type statusResponse struct {
+	Status    string `json:"status"`
+	Reference string `json:"reference"`
+	ErrorCode string `json:"error_code"`
+	Reason    string `json:"reason"`
+}
+
+// NewHTTPPaymentProvider creates a provider client that posts charges to url
+func NewHTTPPaymentProvider(url string, timeout time.Duration) service.PaymentProvider {
+	return &HTTPPaymentProvider{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Charge posts the payment to the provider and maps its answer to a ChargeResult
+func (p *HTTPPaymentProvider) Charge(ctx context.Context, payment *entity.Payment) (*service.ChargeResult, error) {
+	body, err := json.Marshal(chargeRequest{
+		PaymentID: payment.ID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+		Method:    string(payment.Method),
+		Provider:  payment.Provider,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode charge request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build charge request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", payment.ID)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("payment provider unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("payment provider returned status %d", resp.StatusCode)
+	}
+
+	var answer chargeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to decode charge response: %w", err)
+	}
+
+	return &service.ChargeResult{
+		Approved:  answer.Approved,
+		Reference: answer.Reference,
+		ErrorCode: answer.ErrorCode,
+		Reason:    answer.Reason,
+	}, nil
+}
+
+// GetPaymentStatus fetches the charge status from {url}/{payment id}; a 404 means the
+// provider never received the charge
+func (p *HTTPPaymentProvider) GetPaymentStatus(ctx context.Context, payment *entity.Payment) (*service.StatusResult, error) {
+	statusURL := strings.TrimSuffix(p.url, "/") + "/" + url.PathEscape(payment.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("payment provider unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &service.StatusResult{Status: service.ProviderStatusNotFound}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("payment provider returned status %d", resp.StatusCode)
+	}
+
+	var answer statusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to decode status response: %w", err)
+	}
+
+	status := service.ProviderPaymentStatus(answer.Status)
+	switch status {
+	case service.ProviderStatusCompleted, service.ProviderStatusFailed, service.ProviderStatusPending:
+	default:
+		return nil, fmt.Errorf("payment provider returned unknown status %q", answer.Status)
+	}
+
+	return &service.StatusResult{
+		Status:    status,
+		Reference: answer.Reference,
+		ErrorCode: answer.ErrorCode,
+		Reason:    answer.Reason,
+	}, nil
+}
@@ -6,10 +6,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"obs-tools-usage/api/proto/product"
 	"obs-tools-usage/internal/payment/domain/service"
+	"obs-tools-usage/pkg/grpctls"
+	"obs-tools-usage/pkg/tracing"
 )
 
 // ProductClientImpl implements ProductClient interface using gRPC
@@ -20,9 +21,17 @@ type ProductClientImpl struct {
 }
 
 // NewProductClientImpl creates a new product client implementation
-func NewProductClientImpl(productServiceURL string, logger *logrus.Logger) (*ProductClientImpl, error) {
+func NewProductClientImpl(productServiceURL string, logger *logrus.Logger, tlsConfig grpctls.Config) (*ProductClientImpl, error) {
+	creds, err := tlsConfig.ClientCredentials()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create gRPC connection
-	conn, err := grpc.Dial(productServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(productServiceURL,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to product service: %w", err)
 	}
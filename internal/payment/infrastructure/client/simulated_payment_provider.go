@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+	"obs-tools-usage/internal/payment/domain/service"
+)
+
+// Simulated provider modes
+const (
+	ProviderModeAlwaysSucceed = "always_succeed"
+	ProviderModeAlwaysFail    = "always_fail"
+	ProviderModeRandom        = "random"
+	ProviderModeReal          = "real"
+)
+
+// simulatedDeclines are the decline codes a simulated provider picks from
+var simulatedDeclines = []service.ChargeResult{
+	{ErrorCode: "card_declined", Reason: "The card was declined by the issuer"},
+	{ErrorCode: "insufficient_funds", Reason: "The account has insufficient funds"},
+	{ErrorCode: "expired_card", Reason: "The card has expired"},
+	{ErrorCode: "do_not_honor", Reason: "The issuer declined the transaction without a reason"},
+	{ErrorCode: "processing_error", Reason: "The provider failed to process the transaction"},
+}
+
+// SimulatedPaymentProvider fakes a payment provider for development and QA.
+// Depending on its mode it approves every charge, declines every charge, or
+// declines a configurable fraction of them. Outcomes are remembered in memory
+// so status queries can be answered until the process restarts.
+type SimulatedPaymentProvider struct {
+	mode        string
+	failureRate float64
+	latency     time.Duration
+
+	mu      sync.Mutex
+	rand    *rand.Rand
+	charges map[string]service.StatusResult
+}
+
+// NewSimulatedPaymentProvider creates a simulated provider. failureRate (0..1) is only used in random mode.
+func NewSimulatedPaymentProvider(mode string, failureRate float64, latency time.Duration) service.PaymentProvider {
+	return &SimulatedPaymentProvider{
+		mode:        mode,
+		failureRate: failureRate,
+		latency:     latency,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		charges:     make(map[string]service.StatusResult),
+	}
+}
+
+// Charge waits for the simulated latency and approves or declines the payment according to the mode
+func (p *SimulatedPaymentProvider) Charge(ctx context.Context, payment *entity.Payment) (*service.ChargeResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("simulated provider: %w", ctx.Err())
+	case <-time.After(p.latency):
+	}
+
+	p.mu.Lock()
+	fail := p.mode == ProviderModeAlwaysFail || (p.mode == ProviderModeRandom && p.rand.Float64() < p.failureRate)
+	decline := simulatedDeclines[p.rand.Intn(len(simulatedDeclines))]
+	p.mu.Unlock()
+
+	if p.mode == ProviderModeAlwaysFail {
+		decline = simulatedDeclines[0]
+	}
+	if fail {
+		p.remember(payment.ID, service.StatusResult{
+			Status:    service.ProviderStatusFailed,
+			ErrorCode: decline.ErrorCode,
+			Reason:    decline.Reason,
+		})
+		return &decline, nil
+	}
+
+	result := &service.ChargeResult{
+		Approved:  true,
+		Reference: "sim_" + uuid.New().String(),
+	}
+	p.remember(payment.ID, service.StatusResult{
+		Status:    service.ProviderStatusCompleted,
+		Reference: result.Reference,
+	})
+	return result, nil
+}
+
+// GetPaymentStatus reports the remembered outcome of the payment's last charge
+func (p *SimulatedPaymentProvider) GetPaymentStatus(ctx context.Context, payment *entity.Payment) (*service.StatusResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("simulated provider: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result, ok := p.charges[payment.ID]
+	if !ok {
+		return &service.StatusResult{Status: service.ProviderStatusNotFound}, nil
+	}
+	return &result, nil
+}
+
+// remember records the outcome of a charge for later status queries
+func (p *SimulatedPaymentProvider) remember(paymentID string, result service.StatusResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.charges[paymentID] = result
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"obs-tools-usage/internal/payment/domain/service"
+)
+
+// StaticExchangeRateProvider converts currencies using fixed rates from configuration
+type StaticExchangeRateProvider struct {
+	baseCurrency string
+	rates        map[string]float64 // value of one unit of each currency in the base currency
+}
+
+// NewStaticExchangeRateProvider creates an exchange rate provider from fixed rates against baseCurrency
+func NewStaticExchangeRateProvider(baseCurrency string, rates map[string]float64) service.ExchangeRateProvider {
+	normalized := make(map[string]float64, len(rates)+1)
+	for currency, rate := range rates {
+		if rate > 0 {
+			normalized[strings.ToUpper(currency)] = rate
+		}
+	}
+	baseCurrency = strings.ToUpper(baseCurrency)
+	normalized[baseCurrency] = 1
+
+	return &StaticExchangeRateProvider{
+		baseCurrency: baseCurrency,
+		rates:        normalized,
+	}
+}
+
+// GetRate returns the conversion rate between two configured currencies
+func (p *StaticExchangeRateProvider) GetRate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("invalid currency conversion: no exchange rate for %s", from)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("invalid currency conversion: no exchange rate for %s", to)
+	}
+
+	return fromRate / toRate, nil
+}
@@ -3,20 +3,63 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"obs-tools-usage/pkg/grpctls"
 )
 
 // Config holds the configuration for the payment service
 type Config struct {
 	Port        string
+	GRPCPort    string
 	Environment string
+	MaxBodySize int64  // Maximum accepted HTTP request body size in bytes
+	GzipMinSize int    // Smallest response body in bytes compressed for clients that accept gzip
+	MaxPageSize int    // Largest limit accepted by list endpoints
+	APIPrefix   string // Path the versioned API is mounted under; unversioned paths are deprecated aliases
 	LogLevel    string
 	LogFormat   string
 	LogOutput   string
 	LogDir      string
 	LogFile     string
+	LogSampling LogSamplingConfig
+	JobLock     JobLockConfig
 	Database    DatabaseConfig
 	Basket      BasketConfig
 	Product     ProductConfig
+	Kafka       KafkaConfig
+	Outbox      OutboxConfig
+	Metrics     MetricsConfig
+	Tracing     TracingConfig
+	GRPCTLS     grpctls.Config
+	GRPCLimits  GRPCLimitsConfig
+	Expiry      ExpiryConfig
+	Retry       RetryConfig
+	Exchange    ExchangeConfig
+	Provider    ProviderConfig
+	Fees        FeeConfig
+	Processing  ProcessingConfig
+	Checkout    CheckoutConfig
+	Reconcile   ReconcileConfig
+	Currency    CurrencyConfig
+	HTTP        HTTPConfig
+
+	// EnableGRPCReflection registers the gRPC reflection service, which lets developers
+	// introspect and call the gRPC API with tools like grpcurl without the .proto files
+	EnableGRPCReflection bool
+
+	// AdminToken is the bearer token required by admin routes; empty disables them
+	AdminToken string
+
+	// SeedEndpointEnabled registers POST /admin/seed in development; off unless explicitly enabled
+	SeedEndpointEnabled bool
+}
+
+// HTTPConfig holds gin engine configuration
+type HTTPConfig struct {
+	Mode           string   // gin mode: debug or release
+	TrustedProxies []string // IPs or CIDRs of proxies, such as the gateway, whose X-Forwarded-For is trusted
 }
 
 // DatabaseConfig holds MariaDB configuration
@@ -29,6 +72,12 @@ type DatabaseConfig struct {
 	SSLMode  string
 	MaxConn  int
 	MaxIdle  int
+
+	ConnMaxLifetime time.Duration // Connections older than this are closed and replaced
+
+	Seed bool // Insert sample data at startup; rows that already exist are skipped
+
+	IndexedMetadataKeys []string // Payment metadata keys indexed for search by key/value
 }
 
 // BasketConfig holds basket service configuration
@@ -41,18 +90,169 @@ type ProductConfig struct {
 	ServiceURL string
 }
 
+// KafkaConfig holds Kafka publisher configuration
+type KafkaConfig struct {
+	Enabled bool
+	Brokers []string
+}
+
+// OutboxConfig holds transactional outbox relay configuration
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// Retention is how long published rows are kept before the janitor deletes them
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+// MetricsConfig holds metrics collection configuration
+type MetricsConfig struct {
+	ScrapeInterval time.Duration
+}
+
+// ExchangeConfig holds exchange rates used to convert payment totals between currencies
+type ExchangeConfig struct {
+	BaseCurrency string
+	Rates        map[string]float64 // Value of one unit of each currency in BaseCurrency
+}
+
+// ProviderConfig selects how payments are charged: always_succeed, always_fail,
+// random (declines FailureRate of charges) or real (posts to URL)
+type ProviderConfig struct {
+	Mode        string
+	FailureRate float64
+	Latency     time.Duration // Simulated processing time
+	URL         string
+	Timeout     time.Duration
+}
+
+// CurrencyConfig holds the currencies payments can be created in
+type CurrencyConfig struct {
+	Default string   // ISO 4217 code used when a payment request names no currency
+	Allowed []string // ISO 4217 codes accepted for new payments
+}
+
+// IsAllowed reports whether a currency code is accepted for new payments
+func (c CurrencyConfig) IsAllowed(code string) bool {
+	for _, allowed := range c.Allowed {
+		if strings.EqualFold(allowed, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessingConfig bounds concurrent payment processing
+type ProcessingConfig struct {
+	MaxInFlight int // Payments processed at once; further requests are rejected until one finishes. 0 disables the limit.
+}
+
+// ReconcileConfig controls the job that asks the provider about payments stuck in processing,
+// e.g. because the provider's answer or webhook was lost
+type ReconcileConfig struct {
+	Enabled    bool
+	Interval   time.Duration
+	StaleAfter time.Duration // How long a payment may stay in processing before it is reconciled
+	BatchSize  int           // Payments reconciled per run
+}
+
+// CheckoutConfig bounds the checkout orchestration that turns a basket into a payment
+type CheckoutConfig struct {
+	Timeout  time.Duration // Overall budget for reading the basket and storing the payment
+	MaxValue float64       // Largest basket total that can be checked out; 0 disables the limit
+}
+
+// FeeConfig holds the fee each payment provider charges: a percentage of the amount plus a fixed amount
+type FeeConfig struct {
+	Percent map[string]float64 // Percentage per provider, e.g. 2.9 for 2.9%
+	Fixed   map[string]float64 // Fixed amount per provider, in the payment currency
+}
+
+// For returns the fee model of a provider; providers that are not configured charge no fee
+func (c FeeConfig) For(provider string) (percent, fixed float64) {
+	return c.Percent[provider], c.Fixed[provider]
+}
+
+// ExpiryConfig holds how long a pending payment stays valid, per payment method
+type ExpiryConfig struct {
+	Default  time.Duration
+	ByMethod map[string]time.Duration
+}
+
+// For returns the expiry window for a payment method, falling back to the default
+func (c ExpiryConfig) For(method string) time.Duration {
+	if window, ok := c.ByMethod[method]; ok && window > 0 {
+		return window
+	}
+	if c.Default > 0 {
+		return c.Default
+	}
+	return 30 * time.Minute
+}
+
+// RetryConfig limits how often a failed payment may be retried
+type RetryConfig struct {
+	MaxAttempts int
+	Cooldown    time.Duration // Minimum time between two retries of the same payment
+}
+
+// JobLockConfig holds the lease that keeps periodic jobs to one replica at a time
+type JobLockConfig struct {
+	Enabled bool
+	TTL     time.Duration // How long a lease outlives a replica that stopped renewing it
+}
+
+// LogSamplingConfig holds sampling of high-volume Info logs
+type LogSamplingConfig struct {
+	Rate   int  // Keep 1 in Rate routine "operation completed" logs; 1 keeps all
+	Demote bool // Log them at Debug instead of sampling
+}
+
+// GRPCLimitsConfig holds the limits the gRPC server enforces on clients; zero keeps gRPC's default
+type GRPCLimitsConfig struct {
+	MaxConcurrentStreams int           // Streams a single connection may have open at once
+	MaxRecvMsgSize       int           // Largest message in bytes the server accepts
+	KeepaliveTime        time.Duration // Idle time after which the server pings the client
+	KeepaliveTimeout     time.Duration // How long a ping may go unanswered before the connection is closed
+	MaxConnectionIdle    time.Duration // Idle time after which a connection without streams is closed
+	MinPingInterval      time.Duration // Clients pinging more often than this are disconnected
+	PermitWithoutStream  bool          // Allow client pings on connections without active streams
+}
+
+// TracingConfig holds OpenTelemetry trace export configuration
+type TracingConfig struct {
+	Enabled  bool
+	Endpoint string // OTLP gRPC collector address
+	Insecure bool
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	environment := getEnv("ENVIRONMENT", "development")
 	
 	return &Config{
 		Port:        getEnv("PORT", "8082"),
+		GRPCPort:    getEnv("GRPC_PORT", "50052"),
 		Environment: environment,
+		MaxBodySize: int64(getEnvAsInt("MAX_REQUEST_BODY_BYTES", 1<<20)),
+		GzipMinSize: getEnvAsInt("GZIP_MIN_SIZE", 1024),
+		MaxPageSize: getEnvAsInt("MAX_PAGE_SIZE", 100),
+		APIPrefix:   getEnv("API_PREFIX", "/v1"),
 		LogLevel:    getLogLevelFromEnv(environment),
 		LogFormat:   getLogFormatFromEnv(environment),
 		LogOutput:   getLogOutputFromEnv(environment),
 		LogDir:      getEnv("LOG_DIR", "./logs"),
 		LogFile:     getEnv("LOG_FILE", "payment-service.log"),
+		LogSampling: LogSamplingConfig{
+			Rate:   getEnvAsInt("LOG_SAMPLE_RATE", 1),
+			Demote: getEnvAsBool("LOG_SAMPLE_DEMOTE", false),
+		},
+		JobLock: JobLockConfig{
+			Enabled: getEnvAsBool("JOB_LOCK_ENABLED", true),
+			TTL:     getEnvAsPositiveDuration("JOB_LOCK_TTL", 30*time.Second),
+		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "3306"),
@@ -60,8 +260,14 @@ func LoadConfig() *Config {
 			Password: getEnv("DB_PASSWORD", "password"),
 			Name:     getEnv("DB_NAME", "payment_service"),
 			SSLMode:  getEnv("DB_SSL_MODE", "false"),
-			MaxConn:  getEnvAsInt("DB_MAX_CONN", 100),
-			MaxIdle:  getEnvAsInt("DB_MAX_IDLE", 10),
+			MaxConn:  getEnvAsInt("DB_MAX_CONN", 25),
+			MaxIdle:  getEnvAsInt("DB_MAX_IDLE", 5),
+
+			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", time.Hour),
+
+			Seed: getEnvAsBool("DB_SEED", environment == "development"),
+
+			IndexedMetadataKeys: getEnvAsSlice("DB_INDEXED_METADATA_KEYS", []string{"order_ref"}),
 		},
 		Basket: BasketConfig{
 			ServiceURL: getEnv("BASKET_SERVICE_URL", "localhost:50051"),
@@ -69,6 +275,95 @@ func LoadConfig() *Config {
 		Product: ProductConfig{
 			ServiceURL: getEnv("PRODUCT_SERVICE_URL", "localhost:50050"),
 		},
+		Kafka: KafkaConfig{
+			Enabled: getEnvAsBool("KAFKA_ENABLED", true),
+			Brokers: getEnvAsSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvAsDuration("OUTBOX_POLL_INTERVAL", 2*time.Second),
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			RetryBackoff: getEnvAsDuration("OUTBOX_RETRY_BACKOFF", time.Second),
+			MaxBackoff:   getEnvAsDuration("OUTBOX_MAX_BACKOFF", 5*time.Minute),
+
+			Retention:       getEnvAsDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+			CleanupInterval: getEnvAsDuration("OUTBOX_CLEANUP_INTERVAL", time.Hour),
+		},
+		Metrics: MetricsConfig{
+			ScrapeInterval: getEnvAsPositiveDuration("METRICS_SCRAPE_INTERVAL", 5*time.Second),
+		},
+		Tracing: TracingConfig{
+			Enabled:  getEnvAsBool("TRACING_ENABLED", false),
+			Endpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			Insecure: getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		},
+		GRPCTLS: grpctls.Config{
+			// Only development falls back to insecure connections by default
+			Enabled:    getEnvAsBool("GRPC_TLS_ENABLED", environment != "development"),
+			CertFile:   getEnv("GRPC_TLS_CERT_FILE", ""),
+			KeyFile:    getEnv("GRPC_TLS_KEY_FILE", ""),
+			CAFile:     getEnv("GRPC_TLS_CA_FILE", ""),
+			ServerName: getEnv("GRPC_TLS_SERVER_NAME", ""),
+		},
+		GRPCLimits: GRPCLimitsConfig{
+			MaxConcurrentStreams: getEnvAsInt("GRPC_MAX_CONCURRENT_STREAMS", 100),
+			MaxRecvMsgSize:       getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", 4<<20),
+			KeepaliveTime:        getEnvAsDuration("GRPC_KEEPALIVE_TIME", 2*time.Minute),
+			KeepaliveTimeout:     getEnvAsDuration("GRPC_KEEPALIVE_TIMEOUT", 20*time.Second),
+			MaxConnectionIdle:    getEnvAsDuration("GRPC_MAX_CONNECTION_IDLE", 15*time.Minute),
+			MinPingInterval:      getEnvAsDuration("GRPC_KEEPALIVE_MIN_PING_INTERVAL", 30*time.Second),
+			PermitWithoutStream:  getEnvAsBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", true),
+		},
+		Exchange: ExchangeConfig{
+			BaseCurrency: getEnv("EXCHANGE_BASE_CURRENCY", "USD"),
+			Rates:        getEnvAsFloatMap("EXCHANGE_RATES", map[string]float64{}),
+		},
+		Provider: ProviderConfig{
+			Mode:        getEnv("PAYMENT_PROVIDER_MODE", "always_succeed"),
+			FailureRate: getEnvAsFloat("PAYMENT_PROVIDER_FAILURE_RATE", 0.2),
+			Latency:     getEnvAsDuration("PAYMENT_PROVIDER_LATENCY", time.Second),
+			URL:         getEnv("PAYMENT_PROVIDER_URL", ""),
+			Timeout:     getEnvAsDuration("PAYMENT_PROVIDER_TIMEOUT", 10*time.Second),
+		},
+		Currency: CurrencyConfig{
+			Default: getEnv("DEFAULT_CURRENCY", "USD"),
+			Allowed: getEnvAsSlice("ALLOWED_CURRENCIES", []string{"USD", "EUR", "GBP", "TRY", "JPY", "CHF", "CAD", "AUD"}),
+		},
+		Processing: ProcessingConfig{
+			MaxInFlight: getEnvAsInt("PAYMENT_MAX_IN_FLIGHT", 50),
+		},
+		Checkout: CheckoutConfig{
+			Timeout:  getEnvAsDuration("CHECKOUT_TIMEOUT", 10*time.Second),
+			MaxValue: getEnvAsFloat("MAX_CHECKOUT_VALUE", 0),
+		},
+		Reconcile: ReconcileConfig{
+			Enabled:    getEnvAsBool("RECONCILE_ENABLED", true),
+			Interval:   getEnvAsDuration("RECONCILE_INTERVAL", 5*time.Minute),
+			StaleAfter: getEnvAsDuration("RECONCILE_STALE_AFTER", 15*time.Minute),
+			BatchSize:  getEnvAsInt("RECONCILE_BATCH_SIZE", 100),
+		},
+		Fees: FeeConfig{
+			Percent: getEnvAsFloatMap("PAYMENT_PROVIDER_FEE_PERCENT", map[string]float64{}),
+			Fixed:   getEnvAsFloatMap("PAYMENT_PROVIDER_FEE_FIXED", map[string]float64{}),
+		},
+		Retry: RetryConfig{
+			MaxAttempts: getEnvAsInt("PAYMENT_RETRY_MAX_ATTEMPTS", 3),
+			Cooldown:    getEnvAsDuration("PAYMENT_RETRY_COOLDOWN", 30*time.Second),
+		},
+		Expiry: ExpiryConfig{
+			Default: getEnvAsDuration("PAYMENT_EXPIRY_DEFAULT", 30*time.Minute),
+			ByMethod: getEnvAsDurationMap("PAYMENT_EXPIRY_BY_METHOD", map[string]time.Duration{
+				"credit_card":   15 * time.Minute,
+				"debit_card":    15 * time.Minute,
+				"bank_transfer": 24 * time.Hour,
+			}),
+		},
+		HTTP: HTTPConfig{
+			Mode:           getGinModeFromEnv(environment),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}),
+		},
+		EnableGRPCReflection: getEnvAsBool("ENABLE_GRPC_REFLECTION", environment != "production"),
+		AdminToken:           getEnv("ADMIN_TOKEN", ""),
+		SeedEndpointEnabled:  getEnvAsBool("SEED_ENDPOINT_ENABLED", false),
 	}
 }
 
@@ -109,6 +404,98 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as boolean with a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsFloat gets an environment variable as float with a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration gets an environment variable as duration with a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsPositiveDuration gets an environment variable as a positive duration.
+// Values that are not positive fall back to the default.
+func getEnvAsPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := getEnvAsDuration(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice gets a comma-separated environment variable as a string slice with a default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// getEnvAsDurationMap gets an environment variable formatted as "key=duration,key=duration".
+// Malformed entries are skipped; an unset variable yields the default map.
+func getEnvAsDurationMap(key string, defaultValue map[string]time.Duration) map[string]time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || duration <= 0 {
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = duration
+	}
+	return result
+}
+
+// getGinModeFromEnv determines the gin mode, defaulting to release mode in production
+func getGinModeFromEnv(environment string) string {
+	if mode := os.Getenv("GIN_MODE"); mode != "" {
+		return mode
+	}
+	if environment == "production" {
+		return "release"
+	}
+	return "debug"
+}
+
 // getLogLevelFromEnv determines log level from environment
 func getLogLevelFromEnv(environment string) string {
 	// First check LOG_LEVEL environment variable
@@ -166,3 +553,26 @@ func getLogOutputFromEnv(environment string) string {
 		return "console"
 	}
 }
+
+// getEnvAsFloatMap gets an environment variable formatted as "key=value,key=value".
+// Malformed or non-positive entries are skipped; an unset variable yields the default map.
+func getEnvAsFloatMap(key string, defaultValue map[string]float64) map[string]float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || number <= 0 {
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = number
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/payment/domain/repository"
+	"obs-tools-usage/internal/payment/infrastructure/config"
+)
+
+// OutboxJanitor deletes published outbox events once they are older than the retention window
+type OutboxJanitor struct {
+	paymentRepo repository.PaymentRepository
+	config      config.OutboxConfig
+	logger      *logrus.Logger
+}
+
+// NewOutboxJanitor creates a new outbox janitor
+func NewOutboxJanitor(paymentRepo repository.PaymentRepository, cfg config.OutboxConfig, logger *logrus.Logger) *OutboxJanitor {
+	return &OutboxJanitor{
+		paymentRepo: paymentRepo,
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+// Start deletes expired published events every cleanup interval until the context is cancelled
+func (j *OutboxJanitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.config.CleanupInterval)
+	defer ticker.Stop()
+
+	j.logger.WithFields(logrus.Fields{
+		"cleanup_interval": j.config.CleanupInterval.String(),
+		"retention":        j.config.Retention.String(),
+	}).Info("Outbox janitor started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info("Outbox janitor stopped")
+			return
+		case <-ticker.C:
+			j.cleanup(ctx)
+		}
+	}
+}
+
+// cleanup deletes published events older than the retention window
+func (j *OutboxJanitor) cleanup(ctx context.Context) {
+	deleted, err := j.paymentRepo.DeletePublishedOutboxEvents(ctx, time.Now().Add(-j.config.Retention))
+	if err != nil {
+		j.logger.WithError(err).Error("Failed to delete published outbox events")
+		return
+	}
+	if deleted > 0 {
+		j.logger.WithField("deleted", deleted).Info("Deleted published outbox events past retention")
+	}
+}
@@ -0,0 +1,175 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+	"obs-tools-usage/internal/payment/domain/repository"
+	"obs-tools-usage/internal/payment/infrastructure/config"
+	"obs-tools-usage/internal/payment/infrastructure/metrics"
+	"obs-tools-usage/kafka/events"
+	"obs-tools-usage/kafka/publisher"
+)
+
+// OutboxRelay publishes events from the payment outbox table to Kafka.
+// Rows are marked published instead of deleted, so they are never relayed twice once marked;
+// OutboxJanitor removes them after the retention window. Failed rows are retried with
+// exponential backoff. A crash between publishing and marking can still resend an event,
+// so consumers deduplicate on the event ID.
+type OutboxRelay struct {
+	paymentRepo    repository.PaymentRepository
+	kafkaPublisher publisher.EventPublisher
+	config         config.OutboxConfig
+	logger         *logrus.Logger
+}
+
+// NewOutboxRelay creates a new outbox relay.
+// Without a real publisher, i.e. a nil or NoopPublisher, the relay does not run, so outbox
+// events stay pending until one is configured instead of being marked published and lost.
+func NewOutboxRelay(paymentRepo repository.PaymentRepository, kafkaPublisher publisher.EventPublisher, cfg config.OutboxConfig, logger *logrus.Logger) *OutboxRelay {
+	if _, noop := kafkaPublisher.(*publisher.NoopPublisher); noop {
+		kafkaPublisher = nil
+	}
+	return &OutboxRelay{
+		paymentRepo:    paymentRepo,
+		kafkaPublisher: kafkaPublisher,
+		config:         cfg,
+		logger:         logger,
+	}
+}
+
+// Start polls the outbox until the context is cancelled
+func (r *OutboxRelay) Start(ctx context.Context) {
+	if r.kafkaPublisher == nil {
+		r.logger.Warn("No Kafka publisher configured for outbox relay, events stay pending in the outbox")
+		return
+	}
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	r.logger.WithFields(logrus.Fields{
+		"poll_interval": r.config.PollInterval.String(),
+		"batch_size":    r.config.BatchSize,
+	}).Info("Outbox relay started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+			r.relayBatch(ctx)
+			r.updatePendingGauge(ctx)
+		}
+	}
+}
+
+// relayBatch publishes one batch of due outbox events
+func (r *OutboxRelay) relayBatch(ctx context.Context) {
+	outboxEvents, err := r.paymentRepo.GetDueOutboxEvents(ctx, r.config.BatchSize)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to load outbox events")
+		return
+	}
+
+	for _, outboxEvent := range outboxEvents {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := r.publish(ctx, outboxEvent); err != nil {
+			nextAttemptAt := time.Now().Add(r.backoff(outboxEvent.Attempts + 1))
+			r.logger.WithError(err).WithFields(logrus.Fields{
+				"outbox_id":       outboxEvent.ID,
+				"event_type":      outboxEvent.EventType,
+				"aggregate_id":    outboxEvent.AggregateID,
+				"attempts":        outboxEvent.Attempts + 1,
+				"next_attempt_at": nextAttemptAt,
+			}).Warn("Failed to publish outbox event, will retry")
+
+			if err := r.paymentRepo.RecordOutboxFailure(ctx, outboxEvent.ID, err.Error(), nextAttemptAt); err != nil {
+				r.logger.WithError(err).WithField("outbox_id", outboxEvent.ID).Error("Failed to record outbox failure")
+			}
+			continue
+		}
+
+		metrics.RecordOutboxPublished(outboxEvent.EventType)
+		if err := r.paymentRepo.MarkOutboxEventPublished(ctx, outboxEvent.ID, time.Now()); err != nil {
+			r.logger.WithError(err).WithField("outbox_id", outboxEvent.ID).Error("Failed to mark outbox event as published")
+		}
+	}
+}
+
+// updatePendingGauge refreshes the outbox_pending gauge
+func (r *OutboxRelay) updatePendingGauge(ctx context.Context) {
+	pending, err := r.paymentRepo.CountPendingOutboxEvents(ctx)
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to count pending outbox events")
+		return
+	}
+	metrics.SetOutboxPending(pending)
+}
+
+// publish decodes an outbox row and sends it through the matching publisher method
+func (r *OutboxRelay) publish(ctx context.Context, outboxEvent *entity.OutboxEvent) error {
+	payload := []byte(outboxEvent.Payload)
+
+	switch outboxEvent.EventType {
+	case events.PaymentCompletedEventType:
+		var event events.PaymentCompletedEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		return r.kafkaPublisher.PublishPaymentCompleted(ctx, &event)
+	case events.PaymentFailedEventType:
+		var event events.PaymentFailedEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		return r.kafkaPublisher.PublishPaymentFailed(ctx, &event)
+	case events.PaymentRefundedEventType:
+		var event events.PaymentRefundedEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		return r.kafkaPublisher.PublishPaymentRefunded(ctx, &event)
+	case events.PaymentCancelledEventType:
+		var event events.PaymentCancelledEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		return r.kafkaPublisher.PublishPaymentCancelled(ctx, &event)
+	case events.StockUpdateEventType:
+		var event events.StockUpdateEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		return r.kafkaPublisher.PublishStockUpdate(ctx, &event)
+	case events.BasketClearedEventType:
+		var event events.BasketClearedEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("failed to decode outbox payload: %w", err)
+		}
+		return r.kafkaPublisher.PublishBasketCleared(ctx, &event)
+	default:
+		return fmt.Errorf("unknown outbox event type: %s", outboxEvent.EventType)
+	}
+}
+
+// backoff returns the delay before the given attempt, doubling up to the configured maximum
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	delay := r.config.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= r.config.MaxBackoff {
+			return r.config.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,260 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics for payment service
+var (
+	// Runtime metrics
+	memoryAllocBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_memory_alloc_bytes",
+			Help: "Current memory allocation in bytes",
+		},
+	)
+
+	memoryHeapBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_memory_heap_bytes",
+			Help: "Heap memory size in bytes",
+		},
+	)
+
+	goroutinesTotal = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_goroutines_total",
+			Help: "Current number of goroutines",
+		},
+	)
+
+	// Database connection pool metrics
+	dbPoolOpenConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_db_pool_open_connections",
+			Help: "Number of established database connections, both in use and idle",
+		},
+	)
+
+	dbPoolInUseConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_db_pool_in_use_connections",
+			Help: "Number of database connections currently in use",
+		},
+	)
+
+	dbPoolIdleConnections = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_db_pool_idle_connections",
+			Help: "Number of idle database connections",
+		},
+	)
+
+	dbPoolWaitCount = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_db_pool_wait_count",
+			Help: "Total number of times a caller waited for a database connection",
+		},
+	)
+
+	// HTTP metrics
+	httpRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_http_requests_total",
+			Help: "Total number of HTTP requests to payment service",
+		},
+		[]string{"method", "endpoint", "status_code"},
+	)
+
+	httpRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "payment_http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "endpoint"},
+	)
+
+	// Business metrics
+	paymentRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_retries_total",
+			Help: "Total number of payment retry requests by result",
+		},
+		[]string{"result"},
+	)
+
+	paymentProviderChargesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_provider_charges_total",
+			Help: "Total number of payment provider charge attempts by result and decline code",
+		},
+		[]string{"result", "error_code"},
+	)
+
+	paymentProcessingInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_processing_in_flight",
+			Help: "Number of payment processings currently in flight",
+		},
+	)
+
+	paymentProcessingLimit = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_processing_in_flight_limit",
+			Help: "Maximum number of payment processings allowed in flight, 0 when unlimited",
+		},
+	)
+
+	paymentProcessingRejectedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "payment_processing_rejected_total",
+			Help: "Total number of payment processings rejected because the in-flight limit was reached",
+		},
+	)
+
+	// Transactional outbox metrics
+	outboxPending = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "payment_outbox_pending",
+			Help: "Number of outbox events waiting to be published",
+		},
+	)
+
+	outboxPublishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_outbox_published_total",
+			Help: "Total number of outbox events published to Kafka by event type",
+		},
+		[]string{"event_type"},
+	)
+
+	reconcileResultsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_reconcile_results_total",
+			Help: "Total number of stale payments checked against the provider by result",
+		},
+		[]string{"result"},
+	)
+
+	checkoutValueExceededTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "payment_checkout_value_exceeded_total",
+			Help: "Total number of checkouts rejected because the basket total exceeded the maximum checkout value",
+		},
+	)
+)
+
+// Reconciliation results recorded by RecordReconcileResult
+const (
+	ReconcileResultMatched = "matched"
+	ReconcileResultFixed   = "fixed"
+	ReconcileResultError   = "error"
+)
+
+// RecordHTTPRequest records HTTP request metrics
+func RecordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(statusCode)).Inc()
+	httpRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+}
+
+// Payment provider charge results
+const (
+	ChargeResultApproved    = "approved"
+	ChargeResultDeclined    = "declined"
+	ChargeResultUnavailable = "unavailable"
+)
+
+// RecordProviderCharge counts a charge attempt with its result and decline code (empty when approved)
+func RecordProviderCharge(result, errorCode string) {
+	paymentProviderChargesTotal.WithLabelValues(result, errorCode).Inc()
+}
+
+// Payment retry results
+const (
+	RetryResultAccepted     = "accepted"
+	RetryResultLimitReached = "limit_reached"
+	RetryResultCoolingDown  = "cooling_down"
+)
+
+// RecordPaymentRetry counts a payment retry request with its result
+func RecordPaymentRetry(result string) {
+	paymentRetriesTotal.WithLabelValues(result).Inc()
+}
+
+// SetProcessingLimit records the configured in-flight payment processing limit
+func SetProcessingLimit(limit int) {
+	paymentProcessingLimit.Set(float64(limit))
+}
+
+// SetProcessingInFlight records how many payment processings are in flight
+func SetProcessingInFlight(count int) {
+	paymentProcessingInFlight.Set(float64(count))
+}
+
+// RecordProcessingRejected counts a payment processing rejected at the in-flight limit
+func RecordProcessingRejected() {
+	paymentProcessingRejectedTotal.Inc()
+}
+
+// SetOutboxPending records how many outbox events are waiting to be published
+func SetOutboxPending(count int64) {
+	outboxPending.Set(float64(count))
+}
+
+// RecordOutboxPublished counts an outbox event published to Kafka
+func RecordOutboxPublished(eventType string) {
+	outboxPublishedTotal.WithLabelValues(eventType).Inc()
+}
+
+// RecordReconcileResult counts a stale payment checked against the provider
+func RecordReconcileResult(result string) {
+	reconcileResultsTotal.WithLabelValues(result).Inc()
+}
+
+// RecordCheckoutValueExceeded counts a checkout rejected for exceeding the maximum checkout value
+func RecordCheckoutValueExceeded() {
+	checkoutValueExceededTotal.Inc()
+}
+
+// UpdateSystemMetrics updates runtime metrics and, when sqlDB is set, database pool metrics
+func UpdateSystemMetrics(sqlDB *sql.DB) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	memoryAllocBytes.Set(float64(memStats.Alloc))
+	memoryHeapBytes.Set(float64(memStats.HeapAlloc))
+	goroutinesTotal.Set(float64(runtime.NumGoroutine()))
+
+	if sqlDB != nil {
+		stats := sqlDB.Stats()
+		dbPoolOpenConnections.Set(float64(stats.OpenConnections))
+		dbPoolInUseConnections.Set(float64(stats.InUse))
+		dbPoolIdleConnections.Set(float64(stats.Idle))
+		dbPoolWaitCount.Set(float64(stats.WaitCount))
+	}
+}
+
+// StartSystemMetricsCollector calls UpdateSystemMetrics on every interval until the context is cancelled
+func StartSystemMetricsCollector(ctx context.Context, sqlDB *sql.DB, interval time.Duration) {
+	UpdateSystemMetrics(sqlDB)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			UpdateSystemMetrics(sqlDB)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+)
+
+// appendAuditLog inserts an audit entry within tx; a nil entry is skipped.
+// The audit log is append-only, so this is the only write path for it.
+func appendAuditLog(tx *gorm.DB, audit *entity.PaymentAuditLog) error {
+	if audit == nil {
+		return nil
+	}
+	if err := tx.Create(audit).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// GetPaymentAuditLogs retrieves the audit trail of a payment, oldest first
+func (r *PaymentRepositoryImpl) GetPaymentAuditLogs(ctx context.Context, paymentID string) ([]*entity.PaymentAuditLog, error) {
+	var logs []*entity.PaymentAuditLog
+	if err := r.db.WithContext(ctx).Where("payment_id = ?", paymentID).Order("created_at ASC, id ASC").Find(&logs).Error; err != nil {
+		r.logger.WithError(err).WithField("payment_id", paymentID).Error("Failed to get payment audit logs")
+		return nil, fmt.Errorf("failed to get payment audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+// GetUserAuditLogsAfter retrieves up to limit audit entries of a user's payments recorded after
+// the given time, oldest first
+func (r *PaymentRepositoryImpl) GetUserAuditLogsAfter(ctx context.Context, userID string, after time.Time, limit int) ([]*entity.PaymentAuditLog, error) {
+	db := r.db.WithContext(ctx).Select("payment_audit_logs.*").
+		Joins("JOIN payments ON payments.id = payment_audit_logs.payment_id").
+		Where("payments.user_id = ?", userID)
+	if !after.IsZero() {
+		db = db.Where("payment_audit_logs.created_at > ?", after)
+	}
+
+	var logs []*entity.PaymentAuditLog
+	if err := db.Order("payment_audit_logs.created_at ASC, payment_audit_logs.id ASC").Limit(limit).Find(&logs).Error; err != nil {
+		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user audit logs")
+		return nil, fmt.Errorf("failed to get user audit logs: %w", err)
+	}
+	return logs, nil
+}
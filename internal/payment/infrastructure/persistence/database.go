@@ -7,9 +7,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"obs-tools-usage/internal/payment/domain/entity"
 	"obs-tools-usage/internal/payment/infrastructure/config"
+	"obs-tools-usage/pkg/seed"
 )
 
 // Database represents the database connection
@@ -47,9 +49,13 @@ func NewDatabase(cfg *config.Config, logger *logrus.Logger) (*Database, error) {
 
 	sqlDB.SetMaxOpenConns(cfg.Database.MaxConn)
 	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdle)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
-	logger.Info("Connected to MariaDB database")
+	logger.WithFields(logrus.Fields{
+		"max_open_conns":    cfg.Database.MaxConn,
+		"max_idle_conns":    cfg.Database.MaxIdle,
+		"conn_max_lifetime": cfg.Database.ConnMaxLifetime,
+	}).Info("Connected to MariaDB database")
 
 	return &Database{
 		DB:     db,
@@ -64,6 +70,8 @@ func (d *Database) Migrate() error {
 	err := d.DB.AutoMigrate(
 		&entity.Payment{},
 		&entity.PaymentItem{},
+		&entity.OutboxEvent{},
+		&entity.PaymentAuditLog{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -91,18 +99,11 @@ func (d *Database) Health() error {
 	return sqlDB.Ping()
 }
 
-// SeedData page seeds the database with initial data
-func (d *Database) SeedData() error {
+// SeedData seeds the database with sample payments. Payments that already exist are skipped,
+// so seeding can be re-run.
+func (d *Database) SeedData() (seed.Result, error) {
 	d.Logger.Info("Seeding database with initial data...")
 
-	// Check if data already exists
-	var count int64
-	d.DB.Model(&entity.Payment{}).Count(&count)
-	if count > 0 {
-		d.Logger.Info("Database already has data, skipping seed")
-		return nil
-	}
-
 	// Create sample payments for testing
 	samplePayments := []entity.Payment{
 		{
@@ -134,12 +135,23 @@ func (d *Database) SeedData() error {
 		},
 	}
 
+	var result seed.Result
 	for _, payment := range samplePayments {
-		if err := d.DB.Create(&payment).Error; err != nil {
-			d.Logger.WithError(err).Error("Failed to create sample payment")
+		created := d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&payment)
+		if created.Error != nil {
+			d.Logger.WithError(created.Error).Error("Failed to create sample payment")
+			return result, fmt.Errorf("failed to seed payment %s: %w", payment.ID, created.Error)
 		}
+		if created.RowsAffected == 0 {
+			result.Skipped++
+			continue
+		}
+		result.Inserted++
 	}
 
-	d.Logger.Info("Database seeding completed successfully")
-	return nil
+	d.Logger.WithFields(logrus.Fields{
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+	}).Info("Database seeding completed successfully")
+	return result, nil
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+)
+
+// metadataColumn returns the generated column holding the value of an indexed metadata key on MariaDB/MySQL
+func metadataColumn(key string) string {
+	return "meta_" + key
+}
+
+// metadataValueExpr returns the SQL expression extracting a metadata key's value as text in the given
+// dialect. The key must satisfy entity.ValidMetadataKey, which makes it safe to embed in the SQL.
+func metadataValueExpr(dialect, key string) string {
+	if dialect == "postgres" {
+		return fmt.Sprintf("metadata ->> '%s'", key)
+	}
+	return fmt.Sprintf(`JSON_UNQUOTE(JSON_EXTRACT(metadata, '$."%s"'))`, key)
+}
+
+// IndexMetadataKeys indexes the values of the given metadata keys so payments can be searched by them.
+// On MariaDB/MySQL each key gets an indexed virtual column, on Postgres an expression index.
+// Keys that are already indexed are left as they are.
+func (d *Database) IndexMetadataKeys(keys []string) error {
+	dialect := d.DB.Dialector.Name()
+	migrator := d.DB.Migrator()
+
+	for _, key := range keys {
+		if !entity.ValidMetadataKey(key) {
+			return fmt.Errorf("invalid metadata key to index: %q", key)
+		}
+		column := metadataColumn(key)
+		index := "idx_payments_" + column
+
+		if dialect == "postgres" {
+			if err := d.DB.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON payments ((%s))", index, metadataValueExpr(dialect, key))).Error; err != nil {
+				return fmt.Errorf("failed to index metadata key %s: %w", key, err)
+			}
+			continue
+		}
+
+		if !migrator.HasColumn(&entity.Payment{}, column) {
+			if err := d.DB.Exec(fmt.Sprintf("ALTER TABLE payments ADD COLUMN %s VARCHAR(255) AS (%s) VIRTUAL", column, metadataValueExpr(dialect, key))).Error; err != nil {
+				return fmt.Errorf("failed to add metadata column for key %s: %w", key, err)
+			}
+		}
+		if !migrator.HasIndex(&entity.Payment{}, index) {
+			if err := d.DB.Exec(fmt.Sprintf("CREATE INDEX %s ON payments (%s)", index, column)).Error; err != nil {
+				return fmt.Errorf("failed to index metadata key %s: %w", key, err)
+			}
+		}
+	}
+
+	d.Logger.WithField("keys", keys).Info("Payment metadata keys indexed")
+	return nil
+}
+
+// GetPaymentsByMetadata retrieves a page of payments whose metadata maps key to value in sort order,
+// along with the total number of matching payments. The key must satisfy entity.ValidMetadataKey.
+func (r *PaymentRepositoryImpl) GetPaymentsByMetadata(ctx context.Context, key, value string, limit, offset int, sort string) ([]*entity.Payment, int64, error) {
+	r.logger.WithFields(logrus.Fields{
+		"meta_key": key,
+		"limit":    limit,
+		"offset":   offset,
+	}).Debug("Getting payments by metadata from database")
+
+	if !entity.ValidMetadataKey(key) {
+		return nil, 0, fmt.Errorf("invalid metadata key: %q", key)
+	}
+
+	// Indexed keys are read from their virtual column so MariaDB/MySQL use its index
+	condition := metadataValueExpr(r.db.Dialector.Name(), key) + " = ?"
+	if r.indexedMetadataKeys[key] && r.db.Dialector.Name() != "postgres" {
+		condition = metadataColumn(key) + " = ?"
+	}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&entity.Payment{}).Where(condition, value).Count(&total).Error; err != nil {
+		r.logger.WithError(err).WithField("meta_key", key).Error("Failed to count payments by metadata")
+		return nil, 0, fmt.Errorf("failed to count payments by metadata: %w", err)
+	}
+
+	query := r.db.WithContext(ctx).Where(condition, value).Order(paymentOrder(sort))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var payments []*entity.Payment
+	if err := query.Find(&payments).Error; err != nil {
+		r.logger.WithError(err).WithField("meta_key", key).Error("Failed to get payments by metadata")
+		return nil, 0, fmt.Errorf("failed to get payments by metadata: %w", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"meta_key":       key,
+		"payments_count": len(payments),
+		"total":          total,
+	}).Debug("Successfully retrieved payments by metadata")
+
+	return payments, total, nil
+}
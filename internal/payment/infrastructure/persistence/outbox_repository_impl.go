@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"obs-tools-usage/internal/payment/domain/entity"
+)
+
+// GetDueOutboxEvents retrieves unpublished outbox events whose next attempt is due, oldest first
+func (r *PaymentRepositoryImpl) GetDueOutboxEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	var events []*entity.OutboxEvent
+	if err := r.db.WithContext(ctx).Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).Order("id ASC").Limit(limit).Find(&events).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to get due outbox events")
+		return nil, fmt.Errorf("failed to get due outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkOutboxEventPublished records that an outbox event has been published so it is never relayed again
+func (r *PaymentRepositoryImpl) MarkOutboxEventPublished(ctx context.Context, id uint, publishedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).Where("id = ? AND published_at IS NULL", id).Update("published_at", publishedAt).Error; err != nil {
+		r.logger.WithError(err).WithField("outbox_id", id).Error("Failed to mark outbox event as published")
+		return fmt.Errorf("failed to mark outbox event as published: %w", err)
+	}
+	return nil
+}
+
+// DeletePublishedOutboxEvents removes outbox events published before the given time and returns how many were removed
+func (r *PaymentRepositoryImpl) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("published_at IS NOT NULL AND published_at < ?", publishedBefore).Delete(&entity.OutboxEvent{})
+	if result.Error != nil {
+		r.logger.WithError(result.Error).Error("Failed to delete published outbox events")
+		return 0, fmt.Errorf("failed to delete published outbox events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CountPendingOutboxEvents returns how many outbox events have not been published yet
+func (r *PaymentRepositoryImpl) CountPendingOutboxEvents(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).Where("published_at IS NULL").Count(&count).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to count pending outbox events")
+		return 0, fmt.Errorf("failed to count pending outbox events: %w", err)
+	}
+	return count, nil
+}
+
+// RecordOutboxFailure records a failed publish attempt and schedules the next one
+func (r *PaymentRepositoryImpl) RecordOutboxFailure(ctx context.Context, id uint, lastError string, nextAttemptAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error; err != nil {
+		r.logger.WithError(err).WithField("outbox_id", id).Error("Failed to record outbox failure")
+		return fmt.Errorf("failed to record outbox failure: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -13,23 +14,30 @@ import (
 
 // PaymentRepositoryImpl implements PaymentRepository interface using MariaDB
 type PaymentRepositoryImpl struct {
-	db     *gorm.DB
-	logger *logrus.Logger
+	db                  *gorm.DB
+	logger              *logrus.Logger
+	indexedMetadataKeys map[string]bool // Metadata keys with an indexed virtual column, see Database.IndexMetadataKeys
 }
 
 // NewPaymentRepositoryImpl creates a new payment repository implementation
-func NewPaymentRepositoryImpl(db *gorm.DB, logger *logrus.Logger) repository.PaymentRepository {
+func NewPaymentRepositoryImpl(db *gorm.DB, logger *logrus.Logger, indexedMetadataKeys []string) repository.PaymentRepository {
+	indexed := make(map[string]bool, len(indexedMetadataKeys))
+	for _, key := range indexedMetadataKeys {
+		indexed[key] = true
+	}
+
 	return &PaymentRepositoryImpl{
-		db:     db,
-		logger: logger,
+		db:                  db,
+		logger:              logger,
+		indexedMetadataKeys: indexed,
 	}
 }
 
 // CreatePayment creates a new payment
-func (r *PaymentRepositoryImpl) CreatePayment(payment *entity.Payment) error {
+func (r *PaymentRepositoryImpl) CreatePayment(ctx context.Context, payment *entity.Payment) error {
 	r.logger.WithField("payment_id", payment.ID).Debug("Creating payment in database")
 
-	if err := r.db.Create(payment).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(payment).Error; err != nil {
 		r.logger.WithError(err).WithField("payment_id", payment.ID).Error("Failed to create payment")
 		return fmt.Errorf("failed to create payment: %w", err)
 	}
@@ -44,12 +52,46 @@ func (r *PaymentRepositoryImpl) CreatePayment(payment *entity.Payment) error {
 	return nil
 }
 
+// CreatePaymentWithItems creates a payment and its items in one transaction bound to ctx,
+// so a cancelled or timed out checkout never leaves a payment without its items
+func (r *PaymentRepositoryImpl) CreatePaymentWithItems(ctx context.Context, payment *entity.Payment, items []entity.PaymentItem) error {
+	r.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"item_count": len(items),
+	}).Debug("Creating payment with items in database")
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payment).Error; err != nil {
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+		if len(items) > 0 {
+			if err := tx.Create(&items).Error; err != nil {
+				return fmt.Errorf("failed to create payment items: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("payment_id", payment.ID).Error("Failed to create payment with items")
+		return err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"payment_id": payment.ID,
+		"user_id":    payment.UserID,
+		"amount":     payment.Amount,
+		"item_count": len(items),
+	}).Debug("Successfully created payment with items")
+
+	return nil
+}
+
 // GetPayment retrieves a payment by ID
-func (r *PaymentRepositoryImpl) GetPayment(paymentID string) (*entity.Payment, error) {
+func (r *PaymentRepositoryImpl) GetPayment(ctx context.Context, paymentID string) (*entity.Payment, error) {
 	r.logger.WithField("payment_id", paymentID).Debug("Getting payment from database")
 
 	var payment entity.Payment
-	if err := r.db.Where("id = ?", paymentID).First(&payment).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", paymentID).First(&payment).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("payment not found: %s", paymentID)
 		}
@@ -61,25 +103,46 @@ func (r *PaymentRepositoryImpl) GetPayment(paymentID string) (*entity.Payment, e
 	return &payment, nil
 }
 
-// UpdatePayment updates an existing payment
-func (r *PaymentRepositoryImpl) UpdatePayment(payment *entity.Payment) error {
-	r.logger.WithField("payment_id", payment.ID).Debug("Updating payment in database")
+// UpdatePaymentFromStatus saves a payment, appends audit to the audit log when set and enqueues its
+// outbox events in a single transaction, provided the stored status is still fromStatus
+func (r *PaymentRepositoryImpl) UpdatePaymentFromStatus(ctx context.Context, payment *entity.Payment, fromStatus entity.PaymentStatus, audit *entity.PaymentAuditLog, events []*entity.OutboxEvent) error {
+	r.logger.WithFields(logrus.Fields{
+		"payment_id":  payment.ID,
+		"from_status": fromStatus,
+	}).Debug("Updating payment from status in database")
 
 	payment.UpdatedAt = time.Now()
-	if err := r.db.Save(payment).Error; err != nil {
-		r.logger.WithError(err).WithField("payment_id", payment.ID).Error("Failed to update payment")
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Select("*") writes every column; the status condition makes the update a compare-and-swap
+		result := tx.Model(payment).Where("status = ?", fromStatus).Select("*").Updates(payment)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s is no longer %s", repository.ErrPaymentStatusConflict, payment.ID, fromStatus)
+		}
+		if err := appendAuditLog(tx, audit); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.Create(&events).Error
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("payment_id", payment.ID).Error("Failed to update payment from status")
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
-	r.logger.WithField("payment_id", payment.ID).Debug("Successfully updated payment")
+	r.logger.WithField("payment_id", payment.ID).Debug("Successfully updated payment from status")
 	return nil
 }
 
 // DeletePayment deletes a payment
-func (r *PaymentRepositoryImpl) DeletePayment(paymentID string) error {
+func (r *PaymentRepositoryImpl) DeletePayment(ctx context.Context, paymentID string) error {
 	r.logger.WithField("payment_id", paymentID).Debug("Deleting payment from database")
 
-	if err := r.db.Where("id = ?", paymentID).Delete(&entity.Payment{}).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", paymentID).Delete(&entity.Payment{}).Error; err != nil {
 		r.logger.WithError(err).WithField("payment_id", paymentID).Error("Failed to delete payment")
 		return fmt.Errorf("failed to delete payment: %w", err)
 	}
@@ -88,30 +151,63 @@ func (r *PaymentRepositoryImpl) DeletePayment(paymentID string) error {
 	return nil
 }
 
-// GetPaymentsByUser retrieves payments by user ID
-func (r *PaymentRepositoryImpl) GetPaymentsByUser(userID string) ([]*entity.Payment, error) {
-	r.logger.WithField("user_id", userID).Debug("Getting payments by user from database")
+// paymentOrder returns the ORDER BY clause of a payment list sort. Only whitelisted fields reach
+// the clause, anything else falls back to the default; ties are broken by id in the same direction
+// so payments sharing a timestamp keep their place across pages.
+func paymentOrder(sort string) string {
+	if !entity.ValidPaymentSort(sort) {
+		sort = entity.DefaultPaymentSort
+	}
+	field, desc := entity.PaymentSortField(sort)
+	if desc {
+		return field + " DESC, id DESC"
+	}
+	return field + " ASC, id ASC"
+}
+
+// GetPaymentsByUser retrieves a page of payments by user ID in sort order along with the user's total payment count
+func (r *PaymentRepositoryImpl) GetPaymentsByUser(ctx context.Context, userID string, limit, offset int, sort string) ([]*entity.Payment, int64, error) {
+	r.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"limit":   limit,
+		"offset":  offset,
+	}).Debug("Getting payments by user from database")
+
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&entity.Payment{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to count payments by user")
+		return nil, 0, fmt.Errorf("failed to count payments by user: %w", err)
+	}
+
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID).Order(paymentOrder(sort))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
 
 	var payments []*entity.Payment
-	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&payments).Error; err != nil {
+	if err := query.Find(&payments).Error; err != nil {
 		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to get payments by user")
-		return nil, fmt.Errorf("failed to get payments by user: %w", err)
+		return nil, 0, fmt.Errorf("failed to get payments by user: %w", err)
 	}
 
 	r.logger.WithFields(logrus.Fields{
 		"user_id":        userID,
 		"payments_count": len(payments),
+		"total":          total,
 	}).Debug("Successfully retrieved payments by user")
 
-	return payments, nil
+	return payments, total, nil
 }
 
 // GetPaymentsByBasket retrieves payments by basket ID
-func (r *PaymentRepositoryImpl) GetPaymentsByBasket(basketID string) ([]*entity.Payment, error) {
+func (r *PaymentRepositoryImpl) GetPaymentsByBasket(ctx context.Context, basketID string) ([]*entity.Payment, error) {
 	r.logger.WithField("basket_id", basketID).Debug("Getting payments by basket from database")
 
 	var payments []*entity.Payment
-	if err := r.db.Where("basket_id = ?", basketID).Order("created_at DESC").Find(&payments).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("basket_id = ?", basketID).Order("created_at DESC, id DESC").Find(&payments).Error; err != nil {
 		r.logger.WithError(err).WithField("basket_id", basketID).Error("Failed to get payments by basket")
 		return nil, fmt.Errorf("failed to get payments by basket: %w", err)
 	}
@@ -125,11 +221,11 @@ func (r *PaymentRepositoryImpl) GetPaymentsByBasket(basketID string) ([]*entity.
 }
 
 // GetPaymentsByStatus retrieves payments by status
-func (r *PaymentRepositoryImpl) GetPaymentsByStatus(status entity.PaymentStatus) ([]*entity.Payment, error) {
+func (r *PaymentRepositoryImpl) GetPaymentsByStatus(ctx context.Context, status entity.PaymentStatus) ([]*entity.Payment, error) {
 	r.logger.WithField("status", status).Debug("Getting payments by status from database")
 
 	var payments []*entity.Payment
-	if err := r.db.Where("status = ?", status).Order("created_at DESC").Find(&payments).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at DESC, id DESC").Find(&payments).Error; err != nil {
 		r.logger.WithError(err).WithField("status", status).Error("Failed to get payments by status")
 		return nil, fmt.Errorf("failed to get payments by status: %w", err)
 	}
@@ -142,15 +238,65 @@ func (r *PaymentRepositoryImpl) GetPaymentsByStatus(status entity.PaymentStatus)
 	return payments, nil
 }
 
-// GetPaymentsByDateRange retrieves payments within a date range
-func (r *PaymentRepositoryImpl) GetPaymentsByDateRange(startDate, endDate string) ([]*entity.Payment, error) {
+// GetUserPaymentsByStatus retrieves a user's payments in a status, oldest first
+func (r *PaymentRepositoryImpl) GetUserPaymentsByStatus(ctx context.Context, userID string, status entity.PaymentStatus) ([]*entity.Payment, error) {
+	var payments []*entity.Payment
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, status).Order("created_at ASC, id ASC").Find(&payments).Error; err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"user_id": userID,
+			"status":  status,
+		}).Error("Failed to get user payments by status")
+		return nil, fmt.Errorf("failed to get user payments by status: %w", err)
+	}
+	return payments, nil
+}
+
+// GetStalePayments retrieves payments left in a status since before updatedBefore, oldest first
+func (r *PaymentRepositoryImpl) GetStalePayments(ctx context.Context, status entity.PaymentStatus, updatedBefore time.Time, limit int) ([]*entity.Payment, error) {
+	r.logger.WithFields(logrus.Fields{
+		"status":         status,
+		"updated_before": updatedBefore,
+	}).Debug("Getting stale payments from database")
+
+	var payments []*entity.Payment
+	if err := r.db.WithContext(ctx).Where("status = ? AND updated_at < ?", status, updatedBefore).Order("updated_at ASC, id ASC").Limit(limit).Find(&payments).Error; err != nil {
+		r.logger.WithError(err).WithField("status", status).Error("Failed to get stale payments")
+		return nil, fmt.Errorf("failed to get stale payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+// GetUserPaymentsCreatedAfter retrieves up to limit payments of a user created after the given time, oldest first
+func (r *PaymentRepositoryImpl) GetUserPaymentsCreatedAfter(ctx context.Context, userID string, after time.Time, limit int) ([]*entity.Payment, error) {
+	r.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"after":   after,
+	}).Debug("Getting user payments created after cursor from database")
+
+	db := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !after.IsZero() {
+		db = db.Where("created_at > ?", after)
+	}
+
+	var payments []*entity.Payment
+	if err := db.Order("created_at ASC, id ASC").Limit(limit).Find(&payments).Error; err != nil {
+		r.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user payments created after cursor")
+		return nil, fmt.Errorf("failed to get user payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+// GetPaymentsByDateRange retrieves payments created within an inclusive date range
+func (r *PaymentRepositoryImpl) GetPaymentsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*entity.Payment, error) {
 	r.logger.WithFields(logrus.Fields{
 		"start_date": startDate,
 		"end_date":   endDate,
 	}).Debug("Getting payments by date range from database")
 
 	var payments []*entity.Payment
-	if err := r.db.Where("created_at BETWEEN ? AND ?", startDate, endDate).Order("created_at DESC").Find(&payments).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("created_at BETWEEN ? AND ?", startDate, endDate).Order("created_at DESC, id DESC").Find(&payments).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get payments by date range")
 		return nil, fmt.Errorf("failed to get payments by date range: %w", err)
 	}
@@ -165,10 +311,10 @@ func (r *PaymentRepositoryImpl) GetPaymentsByDateRange(startDate, endDate string
 }
 
 // CreatePaymentItem creates a payment item
-func (r *PaymentRepositoryImpl) CreatePaymentItem(item *entity.PaymentItem) error {
+func (r *PaymentRepositoryImpl) CreatePaymentItem(ctx context.Context, item *entity.PaymentItem) error {
 	r.logger.WithField("payment_id", item.PaymentID).Debug("Creating payment item in database")
 
-	if err := r.db.Create(item).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(item).Error; err != nil {
 		r.logger.WithError(err).WithField("payment_id", item.PaymentID).Error("Failed to create payment item")
 		return fmt.Errorf("failed to create payment item: %w", err)
 	}
@@ -178,11 +324,11 @@ func (r *PaymentRepositoryImpl) CreatePaymentItem(item *entity.PaymentItem) erro
 }
 
 // GetPaymentItems retrieves payment items by payment ID
-func (r *PaymentRepositoryImpl) GetPaymentItems(paymentID string) ([]*entity.PaymentItem, error) {
+func (r *PaymentRepositoryImpl) GetPaymentItems(ctx context.Context, paymentID string) ([]*entity.PaymentItem, error) {
 	r.logger.WithField("payment_id", paymentID).Debug("Getting payment items from database")
 
 	var items []*entity.PaymentItem
-	if err := r.db.Where("payment_id = ?", paymentID).Find(&items).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("payment_id = ?", paymentID).Find(&items).Error; err != nil {
 		r.logger.WithError(err).WithField("payment_id", paymentID).Error("Failed to get payment items")
 		return nil, fmt.Errorf("failed to get payment items: %w", err)
 	}
@@ -195,11 +341,38 @@ func (r *PaymentRepositoryImpl) GetPaymentItems(paymentID string) ([]*entity.Pay
 	return items, nil
 }
 
+// GetPaymentItemsForPayments retrieves the items of several payments with a single query, keyed by payment ID
+func (r *PaymentRepositoryImpl) GetPaymentItemsForPayments(ctx context.Context, paymentIDs []string) (map[string][]*entity.PaymentItem, error) {
+	itemsByPayment := make(map[string][]*entity.PaymentItem, len(paymentIDs))
+	if len(paymentIDs) == 0 {
+		return itemsByPayment, nil
+	}
+
+	r.logger.WithField("payments_count", len(paymentIDs)).Debug("Getting payment items for payments from database")
+
+	var items []*entity.PaymentItem
+	if err := r.db.WithContext(ctx).Where("payment_id IN ?", paymentIDs).Find(&items).Error; err != nil {
+		r.logger.WithError(err).Error("Failed to get payment items for payments")
+		return nil, fmt.Errorf("failed to get payment items: %w", err)
+	}
+
+	for _, item := range items {
+		itemsByPayment[item.PaymentID] = append(itemsByPayment[item.PaymentID], item)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"payments_count": len(paymentIDs),
+		"items_count":    len(items),
+	}).Debug("Successfully retrieved payment items for payments")
+
+	return itemsByPayment, nil
+}
+
 // DeletePaymentItems deletes payment items by payment ID
-func (r *PaymentRepositoryImpl) DeletePaymentItems(paymentID string) error {
+func (r *PaymentRepositoryImpl) DeletePaymentItems(ctx context.Context, paymentID string) error {
 	r.logger.WithField("payment_id", paymentID).Debug("Deleting payment items from database")
 
-	if err := r.db.Where("payment_id = ?", paymentID).Delete(&entity.PaymentItem{}).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("payment_id = ?", paymentID).Delete(&entity.PaymentItem{}).Error; err != nil {
 		r.logger.WithError(err).WithField("payment_id", paymentID).Error("Failed to delete payment items")
 		return fmt.Errorf("failed to delete payment items: %w", err)
 	}
@@ -209,33 +382,34 @@ func (r *PaymentRepositoryImpl) DeletePaymentItems(paymentID string) error {
 }
 
 // GetPaymentStats retrieves payment statistics for a user
-func (r *PaymentRepositoryImpl) GetPaymentStats(userID string) (*repository.PaymentStats, error) {
+func (r *PaymentRepositoryImpl) GetPaymentStats(ctx context.Context, userID string) (*repository.PaymentStats, error) {
 	r.logger.WithField("user_id", userID).Debug("Getting payment stats from database")
 
 	var stats repository.PaymentStats
+	db := r.db.WithContext(ctx)
 
 	// Get total payments count
-	if err := r.db.Model(&entity.Payment{}).Where("user_id = ?", userID).Count(&stats.TotalPayments).Error; err != nil {
+	if err := db.Model(&entity.Payment{}).Where("user_id = ?", userID).Count(&stats.TotalPayments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get total payments count: %w", err)
 	}
 
 	// Get total amount
-	if err := r.db.Model(&entity.Payment{}).Where("user_id = ?", userID).Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalAmount).Error; err != nil {
+	if err := db.Model(&entity.Payment{}).Where("user_id = ?", userID).Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalAmount).Error; err != nil {
 		return nil, fmt.Errorf("failed to get total amount: %w", err)
 	}
 
 	// Get completed payments count
-	if err := r.db.Model(&entity.Payment{}).Where("user_id = ? AND status = ?", userID, entity.PaymentStatusCompleted).Count(&stats.CompletedPayments).Error; err != nil {
+	if err := db.Model(&entity.Payment{}).Where("user_id = ? AND status = ?", userID, entity.PaymentStatusCompleted).Count(&stats.CompletedPayments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get completed payments count: %w", err)
 	}
 
 	// Get failed payments count
-	if err := r.db.Model(&entity.Payment{}).Where("user_id = ? AND status = ?", userID, entity.PaymentStatusFailed).Count(&stats.FailedPayments).Error; err != nil {
+	if err := db.Model(&entity.Payment{}).Where("user_id = ? AND status = ?", userID, entity.PaymentStatusFailed).Count(&stats.FailedPayments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get failed payments count: %w", err)
 	}
 
 	// Get pending payments count
-	if err := r.db.Model(&entity.Payment{}).Where("user_id = ? AND status = ?", userID, entity.PaymentStatusPending).Count(&stats.PendingPayments).Error; err != nil {
+	if err := db.Model(&entity.Payment{}).Where("user_id = ? AND status = ?", userID, entity.PaymentStatusPending).Count(&stats.PendingPayments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get pending payments count: %w", err)
 	}
 
@@ -244,6 +418,11 @@ func (r *PaymentRepositoryImpl) GetPaymentStats(userID string) (*repository.Paym
 		stats.AverageAmount = stats.TotalAmount / float64(stats.TotalPayments)
 	}
 
+	// Get totals per currency
+	if err := r.currencyTotals(db.Model(&entity.Payment{}).Where("user_id = ?", userID), &stats.ByCurrency); err != nil {
+		return nil, fmt.Errorf("failed to get totals by currency: %w", err)
+	}
+
 	r.logger.WithFields(logrus.Fields{
 		"user_id":           userID,
 		"total_payments":    stats.TotalPayments,
@@ -255,14 +434,14 @@ func (r *PaymentRepositoryImpl) GetPaymentStats(userID string) (*repository.Paym
 }
 
 // GetTotalRevenue retrieves total revenue within a date range
-func (r *PaymentRepositoryImpl) GetTotalRevenue(startDate, endDate string) (float64, error) {
+func (r *PaymentRepositoryImpl) GetTotalRevenue(ctx context.Context, startDate, endDate string) (float64, error) {
 	r.logger.WithFields(logrus.Fields{
 		"start_date": startDate,
 		"end_date":   endDate,
 	}).Debug("Getting total revenue from database")
 
 	var totalRevenue float64
-	if err := r.db.Model(&entity.Payment{}).Where("status = ? AND created_at BETWEEN ? AND ?", entity.PaymentStatusCompleted, startDate, endDate).Select("COALESCE(SUM(amount), 0)").Scan(&totalRevenue).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Payment{}).Where("status = ? AND created_at BETWEEN ? AND ?", entity.PaymentStatusCompleted, startDate, endDate).Select("COALESCE(SUM(amount), 0)").Scan(&totalRevenue).Error; err != nil {
 		r.logger.WithError(err).Error("Failed to get total revenue")
 		return 0, fmt.Errorf("failed to get total revenue: %w", err)
 	}
@@ -277,11 +456,11 @@ func (r *PaymentRepositoryImpl) GetTotalRevenue(startDate, endDate string) (floa
 }
 
 // GetPaymentCountByStatus retrieves payment count by status
-func (r *PaymentRepositoryImpl) GetPaymentCountByStatus(status entity.PaymentStatus) (int64, error) {
+func (r *PaymentRepositoryImpl) GetPaymentCountByStatus(ctx context.Context, status entity.PaymentStatus) (int64, error) {
 	r.logger.WithField("status", status).Debug("Getting payment count by status from database")
 
 	var count int64
-	if err := r.db.Model(&entity.Payment{}).Where("status = ?", status).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Payment{}).Where("status = ?", status).Count(&count).Error; err != nil {
 		r.logger.WithError(err).WithField("status", status).Error("Failed to get payment count by status")
 		return 0, fmt.Errorf("failed to get payment count by status: %w", err)
 	}
@@ -303,102 +482,109 @@ func (r *PaymentRepositoryImpl) Ping() error {
 	return sqlDB.Ping()
 }
 
-// GetPaymentsByAmountRange retrieves payments by amount range
-func (r *PaymentRepositoryImpl) GetPaymentsByAmountRange(minAmount, maxAmount float64) ([]*entity.Payment, error) {
+// GetPaymentsByAmountRange retrieves payments by amount range in sort order
+func (r *PaymentRepositoryImpl) GetPaymentsByAmountRange(ctx context.Context, minAmount, maxAmount float64, sort string) ([]*entity.Payment, error) {
 	var payments []*entity.Payment
-	err := r.db.Where("amount >= ? AND amount <= ?", minAmount, maxAmount).Find(&payments).Error
+	err := r.db.WithContext(ctx).Where("amount >= ? AND amount <= ?", minAmount, maxAmount).Order(paymentOrder(sort)).Find(&payments).Error
 	return payments, err
 }
 
-// GetPaymentsByMethod retrieves payments by method
-func (r *PaymentRepositoryImpl) GetPaymentsByMethod(method string) ([]*entity.Payment, error) {
+// GetPaymentsByMethod retrieves payments by method in sort order
+func (r *PaymentRepositoryImpl) GetPaymentsByMethod(ctx context.Context, method, sort string) ([]*entity.Payment, error) {
 	var payments []*entity.Payment
-	err := r.db.Where("method = ?", method).Find(&payments).Error
+	err := r.db.WithContext(ctx).Where("method = ?", method).Order(paymentOrder(sort)).Find(&payments).Error
 	return payments, err
 }
 
-// GetPaymentsByProvider retrieves payments by provider
-func (r *PaymentRepositoryImpl) GetPaymentsByProvider(provider string) ([]*entity.Payment, error) {
+// GetPaymentsByProvider retrieves payments by provider in sort order
+func (r *PaymentRepositoryImpl) GetPaymentsByProvider(ctx context.Context, provider, sort string) ([]*entity.Payment, error) {
 	var payments []*entity.Payment
-	err := r.db.Where("provider = ?", provider).Find(&payments).Error
+	err := r.db.WithContext(ctx).Where("provider = ?", provider).Order(paymentOrder(sort)).Find(&payments).Error
 	return payments, err
 }
 
 // GetPaymentAnalytics retrieves payment analytics
-func (r *PaymentRepositoryImpl) GetPaymentAnalytics() (*repository.PaymentAnalytics, error) {
+func (r *PaymentRepositoryImpl) GetPaymentAnalytics(ctx context.Context) (*repository.PaymentAnalytics, error) {
 	var analytics repository.PaymentAnalytics
+	db := r.db.WithContext(ctx)
 	
 	// Total payments
-	r.db.Model(&entity.Payment{}).Count(&analytics.TotalPayments)
+	db.Model(&entity.Payment{}).Count(&analytics.TotalPayments)
 	
-	// Total revenue
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(amount), 0)").Scan(&analytics.TotalRevenue)
+	// Gross revenue, provider fees and net revenue
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(amount), 0)").Scan(&analytics.TotalRevenue)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(fee_amount), 0)").Scan(&analytics.TotalFees)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(net_amount), 0)").Scan(&analytics.NetRevenue)
 	
 	// Success rate
 	var completed, total int64
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Count(&completed)
-	r.db.Model(&entity.Payment{}).Count(&total)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Count(&completed)
+	db.Model(&entity.Payment{}).Count(&total)
 	if total > 0 {
 		analytics.SuccessRate = float64(completed) / float64(total) * 100
 	}
 	
 	// Average amount
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(AVG(amount), 0)").Scan(&analytics.AverageAmount)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(AVG(amount), 0)").Scan(&analytics.AverageAmount)
 	
 	// Top payment method
 	var topMethod string
-	r.db.Model(&entity.Payment{}).Select("method").Group("method").Order("COUNT(*) DESC").Limit(1).Scan(&topMethod)
+	db.Model(&entity.Payment{}).Select("method").Group("method").Order("COUNT(*) DESC").Limit(1).Scan(&topMethod)
 	analytics.TopPaymentMethod = topMethod
 	
 	// Top provider
 	var topProvider string
-	r.db.Model(&entity.Payment{}).Select("provider").Group("provider").Order("COUNT(*) DESC").Limit(1).Scan(&topProvider)
+	db.Model(&entity.Payment{}).Select("provider").Group("provider").Order("COUNT(*) DESC").Limit(1).Scan(&topProvider)
 	analytics.TopProvider = topProvider
 	
 	// Daily transactions (last 24 hours)
-	r.db.Model(&entity.Payment{}).Where("created_at >= DATE_SUB(NOW(), INTERVAL 1 DAY)").Count(&analytics.DailyTransactions)
+	db.Model(&entity.Payment{}).Where("created_at >= DATE_SUB(NOW(), INTERVAL 1 DAY)").Count(&analytics.DailyTransactions)
 	
-	// Monthly revenue (current month)
-	r.db.Model(&entity.Payment{}).Where("status = ? AND created_at >= DATE_FORMAT(NOW(), '%Y-%m-01')", entity.PaymentStatusCompleted).Select("COALESCE(SUM(amount), 0)").Scan(&analytics.MonthlyRevenue)
+	// Monthly gross and net revenue (current month)
+	db.Model(&entity.Payment{}).Where("status = ? AND created_at >= DATE_FORMAT(NOW(), '%Y-%m-01')", entity.PaymentStatusCompleted).Select("COALESCE(SUM(amount), 0)").Scan(&analytics.MonthlyRevenue)
+	db.Model(&entity.Payment{}).Where("status = ? AND created_at >= DATE_FORMAT(NOW(), '%Y-%m-01')", entity.PaymentStatusCompleted).Select("COALESCE(SUM(net_amount), 0)").Scan(&analytics.MonthlyNetRevenue)
 	
 	return &analytics, nil
 }
 
 // GetPaymentMethods retrieves available payment methods
-func (r *PaymentRepositoryImpl) GetPaymentMethods() ([]string, error) {
+func (r *PaymentRepositoryImpl) GetPaymentMethods(ctx context.Context) ([]string, error) {
 	var methods []string
-	err := r.db.Model(&entity.Payment{}).Distinct("method").Pluck("method", &methods).Error
+	err := r.db.WithContext(ctx).Model(&entity.Payment{}).Distinct("method").Pluck("method", &methods).Error
 	return methods, err
 }
 
 // GetPaymentProviders retrieves available payment providers
-func (r *PaymentRepositoryImpl) GetPaymentProviders() ([]string, error) {
+func (r *PaymentRepositoryImpl) GetPaymentProviders(ctx context.Context) ([]string, error) {
 	var providers []string
-	err := r.db.Model(&entity.Payment{}).Distinct("provider").Pluck("provider", &providers).Error
+	err := r.db.WithContext(ctx).Model(&entity.Payment{}).Distinct("provider").Pluck("provider", &providers).Error
 	return providers, err
 }
 
 // GetPaymentSummary retrieves payment summary
-func (r *PaymentRepositoryImpl) GetPaymentSummary() (*repository.PaymentSummary, error) {
+func (r *PaymentRepositoryImpl) GetPaymentSummary(ctx context.Context) (*repository.PaymentSummary, error) {
 	var summary repository.PaymentSummary
+	db := r.db.WithContext(ctx)
 	
 	// Total payments
-	r.db.Model(&entity.Payment{}).Count(&summary.TotalPayments)
+	db.Model(&entity.Payment{}).Count(&summary.TotalPayments)
 	
-	// Total revenue
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(amount), 0)").Scan(&summary.TotalRevenue)
+	// Gross revenue, provider fees and net revenue
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(amount), 0)").Scan(&summary.TotalRevenue)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(fee_amount), 0)").Scan(&summary.TotalFees)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(SUM(net_amount), 0)").Scan(&summary.NetRevenue)
 	
 	// Pending payments
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusPending).Count(&summary.PendingPayments)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusPending).Count(&summary.PendingPayments)
 	
 	// Completed payments
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Count(&summary.CompletedPayments)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Count(&summary.CompletedPayments)
 	
 	// Failed payments
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusFailed).Count(&summary.FailedPayments)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusFailed).Count(&summary.FailedPayments)
 	
 	// Refunded payments
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusRefunded).Count(&summary.RefundedPayments)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusRefunded).Count(&summary.RefundedPayments)
 	
 	// Success rate
 	if summary.TotalPayments > 0 {
@@ -406,7 +592,21 @@ func (r *PaymentRepositoryImpl) GetPaymentSummary() (*repository.PaymentSummary,
 	}
 	
 	// Average amount
-	r.db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(AVG(amount), 0)").Scan(&summary.AverageAmount)
+	db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted).Select("COALESCE(AVG(amount), 0)").Scan(&summary.AverageAmount)
+	
+	// Revenue per currency
+	if err := r.currencyTotals(db.Model(&entity.Payment{}).Where("status = ?", entity.PaymentStatusCompleted), &summary.ByCurrency); err != nil {
+		return nil, fmt.Errorf("failed to get revenue by currency: %w", err)
+	}
 	
 	return &summary, nil
 }
+
+// currencyTotals groups the payments matched by query by currency
+func (r *PaymentRepositoryImpl) currencyTotals(query *gorm.DB, totals *[]repository.CurrencyTotal) error {
+	return query.
+		Select("currency, COUNT(*) AS payments, COALESCE(SUM(amount), 0) AS total_amount, COALESCE(AVG(amount), 0) AS average_amount, COALESCE(SUM(fee_amount), 0) AS total_fees, COALESCE(SUM(net_amount), 0) AS net_amount").
+		Group("currency").
+		Order("currency").
+		Scan(totals).Error
+}
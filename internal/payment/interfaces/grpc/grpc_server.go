@@ -13,6 +13,9 @@ import (
 	"obs-tools-usage/internal/payment/application/query"
 )
 
+// grpcActor is recorded in the payment audit log for changes requested over gRPC
+const grpcActor = "grpc"
+
 // PaymentGRPCServer implements the PaymentService gRPC server
 type PaymentGRPCServer struct {
 	payment.UnimplementedPaymentServiceServer
@@ -40,7 +43,7 @@ func (s *PaymentGRPCServer) CreatePayment(ctx context.Context, req *payment.Crea
 	}).Debug("gRPC CreatePayment request received")
 
 	// Handle command
-	paymentResponse, err := s.commandHandler.HandleCreatePayment(command.CreatePaymentCommand{
+	paymentResponse, err := s.commandHandler.HandleCreatePayment(ctx, command.CreatePaymentCommand{
 		UserID:      req.UserId,
 		BasketID:    req.BasketId,
 		Method:      req.Method,
@@ -78,7 +81,7 @@ func (s *PaymentGRPCServer) GetPayment(ctx context.Context, req *payment.GetPaym
 	s.logger.WithField("payment_id", req.PaymentId).Debug("gRPC GetPayment request received")
 
 	// Handle query
-	paymentResponse, err := s.queryHandler.HandleGetPayment(query.GetPaymentQuery{PaymentID: req.PaymentId})
+	paymentResponse, err := s.queryHandler.HandleGetPayment(ctx, query.GetPaymentQuery{PaymentID: req.PaymentId})
 	if err != nil {
 		s.logger.WithError(err).WithField("payment_id", req.PaymentId).Error("Failed to get payment")
 		return &payment.GetPaymentResponse{
@@ -107,10 +110,11 @@ func (s *PaymentGRPCServer) UpdatePayment(ctx context.Context, req *payment.Upda
 	}).Debug("gRPC UpdatePayment request received")
 
 	// Handle command
-	paymentResponse, err := s.commandHandler.HandleUpdatePayment(command.UpdatePaymentCommand{
+	paymentResponse, err := s.commandHandler.HandleUpdatePayment(ctx, command.UpdatePaymentCommand{
 		PaymentID: req.PaymentId,
 		Status:    req.Status,
 		Metadata:  make(map[string]string),
+		Actor:     grpcActor,
 	})
 	if err != nil {
 		s.logger.WithError(err).WithField("payment_id", req.PaymentId).Error("Failed to update payment")
@@ -143,9 +147,10 @@ func (s *PaymentGRPCServer) ProcessPayment(ctx context.Context, req *payment.Pro
 	}).Debug("gRPC ProcessPayment request received")
 
 	// Handle command
-	paymentResponse, err := s.commandHandler.HandleProcessPayment(command.ProcessPaymentCommand{
+	paymentResponse, err := s.commandHandler.HandleProcessPayment(ctx, command.ProcessPaymentCommand{
 		PaymentID:  req.PaymentId,
 		ProviderID: req.ProviderId,
+		Actor:      grpcActor,
 	})
 	if err != nil {
 		s.logger.WithError(err).WithField("payment_id", req.PaymentId).Error("Failed to process payment")
@@ -179,10 +184,11 @@ func (s *PaymentGRPCServer) RefundPayment(ctx context.Context, req *payment.Refu
 	}).Debug("gRPC RefundPayment request received")
 
 	// Handle command
-	paymentResponse, err := s.commandHandler.HandleRefundPayment(command.RefundPaymentCommand{
+	paymentResponse, err := s.commandHandler.HandleRefundPayment(ctx, command.RefundPaymentCommand{
 		PaymentID: req.PaymentId,
 		Amount:    req.Amount,
 		Reason:    req.Reason,
+		Actor:     grpcActor,
 	})
 	if err != nil {
 		s.logger.WithError(err).WithField("payment_id", req.PaymentId).Error("Failed to refund payment")
@@ -212,7 +218,7 @@ func (s *PaymentGRPCServer) GetPaymentsByUser(ctx context.Context, req *payment.
 	s.logger.WithField("user_id", req.UserId).Debug("gRPC GetPaymentsByUser request received")
 
 	// Handle query
-	payments, err := s.queryHandler.HandleGetPaymentsByUser(query.GetPaymentsByUserQuery{UserID: req.UserId})
+	page, err := s.queryHandler.HandleGetPaymentsByUser(ctx, query.GetPaymentsByUserQuery{UserID: req.UserId})
 	if err != nil {
 		s.logger.WithError(err).WithField("user_id", req.UserId).Error("Failed to get payments by user")
 		return &payment.GetPaymentsByUserResponse{
@@ -223,13 +229,13 @@ func (s *PaymentGRPCServer) GetPaymentsByUser(ctx context.Context, req *payment.
 
 	// Convert to gRPC response
 	var grpcPayments []*payment.Payment
-	for _, paymentResponse := range payments {
+	for _, paymentResponse := range page.Payments {
 		grpcPayments = append(grpcPayments, s.convertToGRPCPayment(paymentResponse))
 	}
 
 	s.logger.WithFields(logrus.Fields{
 		"user_id":        req.UserId,
-		"payments_count": len(payments),
+		"payments_count": len(page.Payments),
 	}).Info("Successfully retrieved payments by user via gRPC")
 
 	return &payment.GetPaymentsByUserResponse{
@@ -244,7 +250,7 @@ func (s *PaymentGRPCServer) GetPaymentStats(ctx context.Context, req *payment.Ge
 	s.logger.WithField("user_id", req.UserId).Debug("gRPC GetPaymentStats request received")
 
 	// Handle query
-	stats, err := s.queryHandler.HandleGetPaymentStats(query.GetPaymentStatsQuery{UserID: req.UserId})
+	stats, err := s.queryHandler.HandleGetPaymentStats(ctx, query.GetPaymentStatsQuery{UserID: req.UserId})
 	if err != nil {
 		s.logger.WithError(err).WithField("user_id", req.UserId).Error("Failed to get payment stats")
 		return &payment.GetPaymentStatsResponse{
@@ -1,29 +1,37 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"obs-tools-usage/internal/payment/application/usecase"
+	"obs-tools-usage/pkg/respond"
 )
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
 // HandleError handles errors and returns appropriate HTTP responses
 func HandleError(c *gin.Context, err error) {
 	if err == nil {
 		return
 	}
 
+	var exceeded *usecase.CheckoutValueExceededError
+	if errors.As(err, &exceeded) {
+		respond.ErrorWithDetails(c, http.StatusUnprocessableEntity, respond.Code(usecase.CheckoutValueExceededCode), err.Error(), gin.H{
+			"total":              exceeded.Total,
+			"max_checkout_value": exceeded.MaxValue,
+		})
+		return
+	}
+
 	errorMsg := err.Error()
 	statusCode := http.StatusInternalServerError
 
 	// Determine status code based on error message
 	switch {
+	case strings.Contains(errorMsg, "timed out"):
+		statusCode = http.StatusGatewayTimeout
 	case strings.Contains(errorMsg, "not found") || strings.Contains(errorMsg, "payment not found"):
 		statusCode = http.StatusNotFound
 	case strings.Contains(errorMsg, "validation") || strings.Contains(errorMsg, "invalid"):
@@ -32,8 +40,10 @@ func HandleError(c *gin.Context, err error) {
 		statusCode = http.StatusUnauthorized
 	case strings.Contains(errorMsg, "forbidden"):
 		statusCode = http.StatusForbidden
-	case strings.Contains(errorMsg, "conflict"):
+	case strings.Contains(errorMsg, "conflict") || strings.Contains(errorMsg, "retry limit reached"):
 		statusCode = http.StatusConflict
+	case strings.Contains(errorMsg, "retry cooldown") || strings.Contains(errorMsg, "too busy"):
+		statusCode = http.StatusTooManyRequests
 	case strings.Contains(errorMsg, "expired"):
 		statusCode = http.StatusGone
 	case strings.Contains(errorMsg, "cannot be processed") || strings.Contains(errorMsg, "cannot be refunded"):
@@ -44,8 +54,5 @@ func HandleError(c *gin.Context, err error) {
 		statusCode = http.StatusBadRequest
 	}
 
-	c.JSON(statusCode, ErrorResponse{
-		Error:   http.StatusText(statusCode),
-		Message: errorMsg,
-	})
+	respond.Error(c, statusCode, errorMsg)
 }
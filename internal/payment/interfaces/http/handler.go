@@ -7,22 +7,32 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"obs-tools-usage/internal/payment/application/command"
-	"obs-tools-usage/internal/payment/application/dto"
 	"obs-tools-usage/internal/payment/application/handler"
 	"obs-tools-usage/internal/payment/application/query"
+	"obs-tools-usage/pkg/daterange"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/pagination"
+	"obs-tools-usage/pkg/respond"
 )
 
+const defaultPaymentsPageSize = 20
+
 // Handler handles HTTP requests using CQRS pattern
 type Handler struct {
 	commandHandler *handler.CommandHandler
 	queryHandler   *handler.QueryHandler
+	health         *health.Checker
+	maxPageSize    int // Largest limit accepted by the list endpoints
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler) *Handler {
+func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler, checker *health.Checker, maxPageSize int) *Handler {
 	return &Handler{
 		commandHandler: commandHandler,
 		queryHandler:   queryHandler,
+		health:         checker,
+		maxPageSize:    maxPageSize,
 	}
 }
 
@@ -30,440 +40,540 @@ func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.Qu
 func (h *Handler) CreatePayment(c *gin.Context) {
 	var cmd command.CreatePaymentCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
-	payment, err := h.commandHandler.HandleCreatePayment(cmd)
+	payment, err := h.commandHandler.HandleCreatePayment(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, payment)
+	respond.OK(c, http.StatusCreated, payment)
 }
 
 // GetPayment handles GET /payments/:id
 func (h *Handler) GetPayment(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
 		return
 	}
 
-	payment, err := h.queryHandler.HandleGetPayment(query.GetPaymentQuery{PaymentID: paymentID})
+	payment, err := h.queryHandler.HandleGetPayment(c.Request.Context(), query.GetPaymentQuery{PaymentID: paymentID})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	respond.OK(c, http.StatusOK, payment)
 }
 
 // UpdatePayment handles PUT /payments/:id
 func (h *Handler) UpdatePayment(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
 		return
 	}
 
 	var cmd command.UpdatePaymentCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
 	cmd.PaymentID = paymentID
+	cmd.Actor = requestActor(c)
 
-	payment, err := h.commandHandler.HandleUpdatePayment(cmd)
+	payment, err := h.commandHandler.HandleUpdatePayment(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	respond.OK(c, http.StatusOK, payment)
 }
 
 // ProcessPayment handles POST /payments/:id/process
 func (h *Handler) ProcessPayment(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
 		return
 	}
 
 	var cmd command.ProcessPaymentCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
 	cmd.PaymentID = paymentID
+	cmd.Actor = requestActor(c)
 
-	payment, err := h.commandHandler.HandleProcessPayment(cmd)
+	payment, err := h.commandHandler.HandleProcessPayment(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	respond.OK(c, http.StatusOK, payment)
 }
 
 // RefundPayment handles POST /payments/:id/refund
 func (h *Handler) RefundPayment(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
 		return
 	}
 
 	var cmd command.RefundPaymentCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		respond.Error(c, middleware.BindErrorStatus(err), err.Error())
 		return
 	}
 
 	cmd.PaymentID = paymentID
+	cmd.Actor = requestActor(c)
 
-	payment, err := h.commandHandler.HandleRefundPayment(cmd)
+	payment, err := h.commandHandler.HandleRefundPayment(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	respond.OK(c, http.StatusOK, payment)
 }
 
-// GetPaymentsByUser handles GET /payments/user/:user_id
+// GetPaymentsByUser handles GET /payments/user/:user_id?limit=&offset=&sort=
 func (h *Handler) GetPaymentsByUser(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	limit, offset := pagination.Parse(c.Request.URL.Query(), defaultPaymentsPageSize, h.maxPageSize)
+
+	payments, err := h.queryHandler.HandleGetPaymentsByUser(c.Request.Context(), query.GetPaymentsByUserQuery{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+		Sort:   c.Query("sort"),
+	})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	payments.Pagination = pagination.NewPage(c.Request.URL, payments.Total, payments.Limit, payments.Offset)
+
+	respond.OK(c, http.StatusOK, payments)
+}
+
+// GetUserTimeline handles GET /payments/user/:user_id/timeline?after=&limit=, returning the user's
+// payment activity oldest first. after is the next_cursor of the previous page.
+func (h *Handler) GetUserTimeline(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
-	payments, err := h.queryHandler.HandleGetPaymentsByUser(query.GetPaymentsByUserQuery{UserID: userID})
+	var after time.Time
+	if cursor := c.Query("after"); cursor != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, cursor)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, "after must be an RFC 3339 timestamp")
+			return
+		}
+		after = parsed
+	}
+
+	limit, _ := pagination.Parse(c.Request.URL.Query(), defaultPaymentsPageSize, h.maxPageSize)
+
+	timeline, err := h.queryHandler.HandleGetUserTimeline(c.Request.Context(), query.GetUserTimelineQuery{
+		UserID: userID,
+		After:  after,
+		Limit:  limit,
+	})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payments)
+	respond.OK(c, http.StatusOK, timeline)
 }
 
-// GetPaymentStats handles GET /payments/stats/:user_id
+// GetPaymentStats handles GET /payments/stats/:user_id?currency=
 func (h *Handler) GetPaymentStats(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid user ID",
-			Message: "User ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
-	stats, err := h.queryHandler.HandleGetPaymentStats(query.GetPaymentStatsQuery{UserID: userID})
+	stats, err := h.queryHandler.HandleGetPaymentStats(c.Request.Context(), query.GetPaymentStatsQuery{
+		UserID:         userID,
+		TargetCurrency: c.Query("currency"),
+	})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	respond.OK(c, http.StatusOK, stats)
 }
 
 // GetPaymentsByStatus handles GET /payments/status/:status
 func (h *Handler) GetPaymentsByStatus(c *gin.Context) {
 	status := c.Param("status")
 	if status == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid status",
-			Message: "Status is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Status is required")
 		return
 	}
 
-	payments, err := h.queryHandler.HandleGetPaymentsByStatus(query.GetPaymentsByStatusQuery{Status: status})
+	payments, err := h.queryHandler.HandleGetPaymentsByStatus(c.Request.Context(), query.GetPaymentsByStatusQuery{Status: status})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payments)
+	respond.OK(c, http.StatusOK, payments)
 }
 
 // GetPaymentsByDateRange handles GET /payments/date/:start/:end
+// Each bound is an RFC3339 timestamp or a YYYY-MM-DD date; an end date includes the whole day.
 func (h *Handler) GetPaymentsByDateRange(c *gin.Context) {
-	startDate := c.Param("start")
-	endDate := c.Param("end")
-
-	if startDate == "" || endDate == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid date range",
-			Message: "Start and end dates are required",
-		})
+	dateRange, err := daterange.Parse(c.Param("start"), c.Param("end"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	payments, err := h.queryHandler.HandleGetPaymentsByDateRange(query.GetPaymentsByDateRangeQuery{
-		StartDate: startDate,
-		EndDate:   endDate,
+	payments, err := h.queryHandler.HandleGetPaymentsByDateRange(c.Request.Context(), query.GetPaymentsByDateRangeQuery{
+		StartDate: dateRange.Start,
+		EndDate:   dateRange.End,
 	})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payments)
+	respond.OK(c, http.StatusOK, payments)
 }
 
-// GetPaymentsByAmountRange handles GET /payments/amount/:min/:max
+// GetPaymentsByAmountRange handles GET /payments/amount/:min/:max?sort=
 func (h *Handler) GetPaymentsByAmountRange(c *gin.Context) {
 	minAmountStr := c.Param("min")
 	maxAmountStr := c.Param("max")
 
 	minAmount, err := strconv.ParseFloat(minAmountStr, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid min amount",
-			Message: "Min amount must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Min amount must be a valid number")
 		return
 	}
 
 	maxAmount, err := strconv.ParseFloat(maxAmountStr, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid max amount",
-			Message: "Max amount must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Max amount must be a valid number")
 		return
 	}
 
-	payments, err := h.queryHandler.HandleGetPaymentsByAmountRange(query.GetPaymentsByAmountRangeQuery{
+	payments, err := h.queryHandler.HandleGetPaymentsByAmountRange(c.Request.Context(), query.GetPaymentsByAmountRangeQuery{
 		MinAmount: minAmount,
 		MaxAmount: maxAmount,
+		Sort:      c.Query("sort"),
 	})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payments)
+	respond.OK(c, http.StatusOK, payments)
 }
 
-// GetPaymentsByMethod handles GET /payments/method/:method
+// GetPaymentsByMethod handles GET /payments/method/:method?sort=
 func (h *Handler) GetPaymentsByMethod(c *gin.Context) {
 	method := c.Param("method")
 	if method == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid method",
-			Message: "Payment method is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment method is required")
 		return
 	}
 
-	payments, err := h.queryHandler.HandleGetPaymentsByMethod(query.GetPaymentsByMethodQuery{Method: method})
+	payments, err := h.queryHandler.HandleGetPaymentsByMethod(c.Request.Context(), query.GetPaymentsByMethodQuery{Method: method, Sort: c.Query("sort")})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payments)
+	respond.OK(c, http.StatusOK, payments)
 }
 
-// GetPaymentsByProvider handles GET /payments/provider/:provider
+// GetPaymentsByProvider handles GET /payments/provider/:provider?sort=
 func (h *Handler) GetPaymentsByProvider(c *gin.Context) {
 	provider := c.Param("provider")
 	if provider == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid provider",
-			Message: "Payment provider is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment provider is required")
+		return
+	}
+
+	payments, err := h.queryHandler.HandleGetPaymentsByProvider(c.Request.Context(), query.GetPaymentsByProviderQuery{Provider: provider, Sort: c.Query("sort")})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, payments)
+}
+
+// GetPaymentsByMetadata handles GET /payments?meta_key=&meta_value=&limit=&offset=&sort=, returning the
+// payments whose metadata maps meta_key to meta_value
+func (h *Handler) GetPaymentsByMetadata(c *gin.Context) {
+	key := c.Query("meta_key")
+	value := c.Query("meta_value")
+	if key == "" || value == "" {
+		respond.Error(c, http.StatusBadRequest, "meta_key and meta_value are required")
 		return
 	}
 
-	payments, err := h.queryHandler.HandleGetPaymentsByProvider(query.GetPaymentsByProviderQuery{Provider: provider})
+	limit, offset := pagination.Parse(c.Request.URL.Query(), defaultPaymentsPageSize, h.maxPageSize)
+
+	payments, err := h.queryHandler.HandleGetPaymentsByMetadata(c.Request.Context(), query.GetPaymentsByMetadataQuery{
+		Key:    key,
+		Value:  value,
+		Limit:  limit,
+		Offset: offset,
+		Sort:   c.Query("sort"),
+	})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
+	payments.Pagination = pagination.NewPage(c.Request.URL, payments.Total, payments.Limit, payments.Offset)
 
-	c.JSON(http.StatusOK, payments)
+	respond.OK(c, http.StatusOK, payments)
 }
 
 // GetPaymentItems handles GET /payments/:id/items
 func (h *Handler) GetPaymentItems(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
+		return
+	}
+
+	items, err := h.queryHandler.HandleGetPaymentItems(c.Request.Context(), query.GetPaymentItemsQuery{PaymentID: paymentID})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, items)
+}
+
+// GetPaymentAuditLog handles GET /payments/:id/audit, returning the payment's status changes oldest first
+func (h *Handler) GetPaymentAuditLog(c *gin.Context) {
+	paymentID := c.Param("id")
+	if paymentID == "" {
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
+		return
+	}
+
+	auditLog, err := h.queryHandler.HandleGetPaymentAuditLog(c.Request.Context(), query.GetPaymentAuditLogQuery{PaymentID: paymentID})
+	if err != nil {
+		HandleError(c, err)
 		return
 	}
 
-	items, err := h.queryHandler.HandleGetPaymentItems(query.GetPaymentItemsQuery{PaymentID: paymentID})
+	respond.OK(c, http.StatusOK, auditLog)
+}
+
+// GetReconcileReport handles GET /payments/reconcile/report, summarizing the payments
+// reconciliation found out of sync with the provider and fixed
+func (h *Handler) GetReconcileReport(c *gin.Context) {
+	report, err := h.queryHandler.HandleGetReconcileReport(query.GetReconcileReportQuery{})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	respond.OK(c, http.StatusOK, report)
 }
 
 // GetPaymentAnalytics handles GET /payments/analytics
 func (h *Handler) GetPaymentAnalytics(c *gin.Context) {
-	analytics, err := h.queryHandler.HandleGetPaymentAnalytics(query.GetPaymentAnalyticsQuery{})
+	analytics, err := h.queryHandler.HandleGetPaymentAnalytics(c.Request.Context(), query.GetPaymentAnalyticsQuery{})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, analytics)
+	respond.OK(c, http.StatusOK, analytics)
 }
 
 // GetPaymentMethods handles GET /payments/methods
 func (h *Handler) GetPaymentMethods(c *gin.Context) {
-	methods, err := h.queryHandler.HandleGetPaymentMethods(query.GetPaymentMethodsQuery{})
+	methods, err := h.queryHandler.HandleGetPaymentMethods(c.Request.Context(), query.GetPaymentMethodsQuery{})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, methods)
+	respond.OK(c, http.StatusOK, methods)
 }
 
 // GetPaymentProviders handles GET /payments/providers
 func (h *Handler) GetPaymentProviders(c *gin.Context) {
-	providers, err := h.queryHandler.HandleGetPaymentProviders(query.GetPaymentProvidersQuery{})
+	providers, err := h.queryHandler.HandleGetPaymentProviders(c.Request.Context(), query.GetPaymentProvidersQuery{})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, providers)
+	respond.OK(c, http.StatusOK, providers)
 }
 
-// GetPaymentSummary handles GET /payments/summary
+// GetPaymentSummary handles GET /payments/summary?currency=
 func (h *Handler) GetPaymentSummary(c *gin.Context) {
-	summary, err := h.queryHandler.HandleGetPaymentSummary(query.GetPaymentSummaryQuery{})
+	summary, err := h.queryHandler.HandleGetPaymentSummary(c.Request.Context(), query.GetPaymentSummaryQuery{TargetCurrency: c.Query("currency")})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, summary)
+	respond.OK(c, http.StatusOK, summary)
 }
 
 // CancelPayment handles POST /payments/:id/cancel
 func (h *Handler) CancelPayment(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
+		return
+	}
+
+	cmd := command.CancelPaymentCommand{PaymentID: paymentID, Actor: requestActor(c)}
+
+	payment, err := h.commandHandler.HandleCancelPayment(c.Request.Context(), cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, payment)
+}
+
+// CancelAllPayments handles POST /payments/user/:user_id/cancel-all
+func (h *Handler) CancelAllPayments(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		respond.Error(c, http.StatusBadRequest, "User ID is required")
 		return
 	}
 
-	cmd := command.CancelPaymentCommand{PaymentID: paymentID}
+	cmd := command.CancelAllPaymentsCommand{UserID: userID, Actor: requestActor(c)}
 
-	payment, err := h.commandHandler.HandleCancelPayment(cmd)
+	response, err := h.commandHandler.HandleCancelAllPayments(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // RetryPayment handles POST /payments/:id/retry
 func (h *Handler) RetryPayment(c *gin.Context) {
 	paymentID := c.Param("id")
 	if paymentID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid payment ID",
-			Message: "Payment ID is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Payment ID is required")
 		return
 	}
 
-	cmd := command.RetryPaymentCommand{PaymentID: paymentID}
+	cmd := command.RetryPaymentCommand{PaymentID: paymentID, Actor: requestActor(c)}
 
-	payment, err := h.commandHandler.HandleRetryPayment(cmd)
+	payment, err := h.commandHandler.HandleRetryPayment(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	respond.OK(c, http.StatusOK, payment)
 }
 
-// HealthCheck handles GET /health
+// requestActor identifies the caller of a state-changing request for the audit log:
+// the user ID forwarded by the gateway, or "api" when there is none
+func requestActor(c *gin.Context) string {
+	if userID := c.GetHeader(middleware.UserIDHeader); userID != "" {
+		return userID
+	}
+	return "api"
+}
+
+// HealthCheck handles GET /health, reporting the database and the basket and product services
 func (h *Handler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, dto.HealthResponse{
-		Service:   "payment-service",
-		Status:    "healthy",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Version:   "1.0.0",
-	})
+	h.health.ServeHealth(c)
 }
 
-// SetupRoutes sets up all routes
-func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler) {
-	handler := NewHandler(commandHandler, queryHandler)
+// ReadinessCheck handles GET /ready, reporting whether the database is reachable
+func (h *Handler) ReadinessCheck(c *gin.Context) {
+	h.health.ServeReady(c)
+}
+
+// LivenessCheck handles GET /live, reporting only that the process is running
+func (h *Handler) LivenessCheck(c *gin.Context) {
+	h.health.ServeLive(c)
+}
+
+// SetupRoutes sets up all routes. The API is mounted under apiPrefix, such as /v1, and
+// served unversioned as a deprecated alias; health checks stay at the root.
+func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler, checker *health.Checker, maxPageSize int, adminToken string, apiPrefix string) {
+	handler := NewHandler(commandHandler, queryHandler, checker, maxPageSize)
+
+	registerPaymentRoutes(r.Group(apiPrefix), handler, adminToken)
+	if apiPrefix != "" {
+		// Unversioned aliases, kept working during the deprecation window
+		registerPaymentRoutes(r.Group("", middleware.DeprecatedAlias("", apiPrefix)), handler, adminToken)
+	}
 
+	// Health checks
+	r.GET("/health", handler.HealthCheck)
+	r.GET("/ready", handler.ReadinessCheck)
+	r.GET("/live", handler.LivenessCheck)
+}
+
+// registerPaymentRoutes registers the payment API on routes
+func registerPaymentRoutes(routes gin.IRoutes, handler *Handler, adminToken string) {
 	// Payment routes
-	r.POST("/payments", handler.CreatePayment)
-	r.GET("/payments/:id", handler.GetPayment)
-	r.PUT("/payments/:id", handler.UpdatePayment)
-	r.POST("/payments/:id/process", handler.ProcessPayment)
-	r.POST("/payments/:id/refund", handler.RefundPayment)
-	r.POST("/payments/:id/cancel", handler.CancelPayment)
-	r.POST("/payments/:id/retry", handler.RetryPayment)
-	r.GET("/payments/user/:user_id", handler.GetPaymentsByUser)
-	r.GET("/payments/stats/:user_id", handler.GetPaymentStats)
+	routes.POST("/payments", handler.CreatePayment)
+	routes.GET("/payments", handler.GetPaymentsByMetadata)
+	routes.GET("/payments/:id", handler.GetPayment)
+	routes.PUT("/payments/:id", handler.UpdatePayment)
+	routes.POST("/payments/:id/process", handler.ProcessPayment)
+	routes.POST("/payments/:id/refund", handler.RefundPayment)
+	routes.POST("/payments/:id/cancel", handler.CancelPayment)
+	routes.POST("/payments/:id/retry", handler.RetryPayment)
+	routes.GET("/payments/user/:user_id", handler.GetPaymentsByUser)
+	routes.GET("/payments/stats/:user_id", handler.GetPaymentStats)
 
 	// Query routes
-	r.GET("/payments/status/:status", handler.GetPaymentsByStatus)
-	r.GET("/payments/date/:start/:end", handler.GetPaymentsByDateRange)
-	r.GET("/payments/amount/:min/:max", handler.GetPaymentsByAmountRange)
-	r.GET("/payments/method/:method", handler.GetPaymentsByMethod)
-	r.GET("/payments/provider/:provider", handler.GetPaymentsByProvider)
-	r.GET("/payments/:id/items", handler.GetPaymentItems)
-	r.GET("/payments/analytics", handler.GetPaymentAnalytics)
-	r.GET("/payments/methods", handler.GetPaymentMethods)
-	r.GET("/payments/providers", handler.GetPaymentProviders)
-	r.GET("/payments/summary", handler.GetPaymentSummary)
-
-	// Health check
-	r.GET("/health", handler.HealthCheck)
+	routes.GET("/payments/status/:status", handler.GetPaymentsByStatus)
+	routes.GET("/payments/date/:start/:end", handler.GetPaymentsByDateRange)
+	routes.GET("/payments/amount/:min/:max", handler.GetPaymentsByAmountRange)
+	routes.GET("/payments/method/:method", handler.GetPaymentsByMethod)
+	routes.GET("/payments/provider/:provider", handler.GetPaymentsByProvider)
+	routes.GET("/payments/:id/items", handler.GetPaymentItems)
+	routes.GET("/payments/analytics", handler.GetPaymentAnalytics)
+	routes.GET("/payments/methods", handler.GetPaymentMethods)
+	routes.GET("/payments/providers", handler.GetPaymentProviders)
+	routes.GET("/payments/summary", handler.GetPaymentSummary)
+
+	// Admin routes
+	routes.GET("/payments/:id/audit", middleware.AdminAuth(adminToken), handler.GetPaymentAuditLog)
+	routes.GET("/payments/user/:user_id/timeline", middleware.AdminAuth(adminToken), handler.GetUserTimeline)
+	routes.GET("/payments/reconcile/report", middleware.AdminAuth(adminToken), handler.GetReconcileReport)
+	routes.POST("/payments/user/:user_id/cancel-all", middleware.AdminAuth(adminToken), handler.CancelAllPayments)
 }
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"obs-tools-usage/internal/product/application/dto"
+)
+
+// CreateCategoryCommand represents a command to create a category
+type CreateCategoryCommand struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// ToDTO converts command to DTO
+func (c *CreateCategoryCommand) ToDTO() dto.CreateCategoryRequest {
+	return dto.CreateCategoryRequest{
+		Name:        c.Name,
+		Description: c.Description,
+	}
+}
+
+// UpdateCategoryCommand represents a command to update a category
+type UpdateCategoryCommand struct {
+	Name        string `json:"-"`
+	Description string `json:"description"`
+}
+
+// ToDTO converts command to DTO
+func (c *UpdateCategoryCommand) ToDTO() dto.UpdateCategoryRequest {
+	return dto.UpdateCategoryRequest{
+		Description: c.Description,
+	}
+}
+
+// RenameCategoryCommand represents a command to rename a category
+type RenameCategoryCommand struct {
+	OldName string `json:"-"`
+	NewName string `json:"new_name" binding:"required"`
+}
+
+// DeleteCategoryCommand represents a command to delete a category
+type DeleteCategoryCommand struct {
+	Name       string `json:"name" binding:"required"`
+	ReassignTo string `json:"reassign_to"`
+}
@@ -6,20 +6,22 @@ import (
 
 // CreateProductCommand represents a command to create a product
 type CreateProductCommand struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	Category    string  `json:"category"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,min=0"`
+	Stock             int     `json:"stock" binding:"min=0"`
+	Category          string  `json:"category"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
 }
 
 // ToDTO converts command to DTO
 func (c *CreateProductCommand) ToDTO() dto.CreateProductRequest {
 	return dto.CreateProductRequest{
-		Name:        c.Name,
-		Description: c.Description,
-		Price:       c.Price,
-		Stock:       c.Stock,
-		Category:    c.Category,
+		Name:              c.Name,
+		Description:       c.Description,
+		Price:             c.Price,
+		Stock:             c.Stock,
+		Category:          c.Category,
+		LowStockThreshold: c.LowStockThreshold,
 	}
 }
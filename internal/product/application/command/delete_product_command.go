@@ -2,5 +2,11 @@ package command
 
 // DeleteProductCommand represents a command to delete a product
 type DeleteProductCommand struct {
+	ID   int  `json:"id" binding:"required"`
+	Hard bool `json:"hard"`
+}
+
+// RestoreProductCommand represents a command to restore a soft-deleted product
+type RestoreProductCommand struct {
 	ID int `json:"id" binding:"required"`
 }
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"time"
+
+	"obs-tools-usage/internal/product/application/dto"
+)
+
+// CreatePromotionCommand represents a command to create a promotion
+type CreatePromotionCommand struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	Discount    float64    `json:"discount" binding:"required"`
+	StartDate   *time.Time `json:"start_date"`
+	EndDate     time.Time  `json:"end_date" binding:"required"`
+}
+
+// ToDTO converts command to DTO
+func (c *CreatePromotionCommand) ToDTO() dto.CreatePromotionRequest {
+	return dto.CreatePromotionRequest{
+		Title:       c.Title,
+		Description: c.Description,
+		Discount:    c.Discount,
+		StartDate:   c.StartDate,
+		EndDate:     c.EndDate,
+	}
+}
@@ -6,21 +6,25 @@ import (
 
 // UpdateProductCommand represents a command to update a product
 type UpdateProductCommand struct {
-	ID          int     `json:"id" binding:"required"`
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	Category    string  `json:"category"`
+	ID                int     `json:"id" binding:"required"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,min=0"`
+	Stock             int     `json:"stock" binding:"min=0"`
+	Category          string  `json:"category"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
+	ChangedBy         string  `json:"changed_by"`
 }
 
 // ToDTO converts command to DTO
 func (c *UpdateProductCommand) ToDTO() dto.UpdateProductRequest {
 	return dto.UpdateProductRequest{
-		Name:        c.Name,
-		Description: c.Description,
-		Price:       c.Price,
-		Stock:       c.Stock,
-		Category:    c.Category,
+		Name:              c.Name,
+		Description:       c.Description,
+		Price:             c.Price,
+		Stock:             c.Stock,
+		Category:          c.Category,
+		LowStockThreshold: c.LowStockThreshold,
+		ChangedBy:         c.ChangedBy,
 	}
 }
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"obs-tools-usage/internal/product/application/dto"
+)
+
+// UpsertProductBySKUCommand represents a command to create or update a product by its external SKU
+type UpsertProductBySKUCommand struct {
+	SKU               string  `json:"sku"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,min=0"`
+	Stock             int     `json:"stock" binding:"min=0"`
+	Category          string  `json:"category"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
+	ChangedBy         string  `json:"changed_by"`
+}
+
+// ToDTO converts command to DTO
+func (c *UpsertProductBySKUCommand) ToDTO() dto.UpsertProductRequest {
+	return dto.UpsertProductRequest{
+		Name:              c.Name,
+		Description:       c.Description,
+		Price:             c.Price,
+		Stock:             c.Stock,
+		Category:          c.Category,
+		LowStockThreshold: c.LowStockThreshold,
+		ChangedBy:         c.ChangedBy,
+	}
+}
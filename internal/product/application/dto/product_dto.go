@@ -1,41 +1,64 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"obs-tools-usage/pkg/pagination"
+)
 
 // CreateProductRequest represents the request payload for creating a product
 type CreateProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	Category    string  `json:"category"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,min=0"`
+	Stock             int     `json:"stock" binding:"min=0"`
+	Category          string  `json:"category"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
 }
 
 // UpdateProductRequest represents the request payload for updating a product
 type UpdateProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,min=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	Category    string  `json:"category"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,min=0"`
+	Stock             int     `json:"stock" binding:"min=0"`
+	Category          string  `json:"category"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
+	ChangedBy         string  `json:"changed_by"`
+}
+
+// UpsertProductRequest represents the request payload for creating or updating a product by SKU
+type UpsertProductRequest struct {
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,min=0"`
+	Stock             int     `json:"stock" binding:"min=0"`
+	Category          string  `json:"category"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
+	ChangedBy         string  `json:"changed_by"`
 }
 
 // ProductResponse represents the response payload for product operations
 type ProductResponse struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	Category    string    `json:"category"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                int        `json:"id"`
+	Name              string     `json:"name"`
+	Description       string     `json:"description"`
+	Price             float64    `json:"price"`
+	Stock             int        `json:"stock"`
+	Category          string     `json:"category"`
+	SKU               *string    `json:"sku,omitempty"`
+	LowStockThreshold *int       `json:"low_stock_threshold,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	Score             *float64   `json:"score,omitempty"` // Search relevance, set only for full-text searches
 }
 
 // ProductsResponse represents the response payload for multiple products
 type ProductsResponse struct {
-	Products []ProductResponse `json:"products"`
-	Count    int               `json:"count"`
+	Products   []ProductResponse `json:"products"`
+	Count      int               `json:"count"`
+	Pagination *pagination.Page  `json:"pagination,omitempty"`
 }
 
 // SuccessResponse represents a success response
@@ -44,12 +67,6 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
 // ProductStatsResponse represents product statistics response
 type ProductStatsResponse struct {
 	TotalProducts      int64   `json:"total_products"`
@@ -60,9 +77,22 @@ type ProductStatsResponse struct {
 	OutOfStockProducts int64   `json:"out_of_stock_products"`
 }
 
+// CreateCategoryRequest represents the request payload for creating a category
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// UpdateCategoryRequest represents the request payload for updating a category
+type UpdateCategoryRequest struct {
+	Description string `json:"description"`
+}
+
 // CategoryResponse represents a category response
 type CategoryResponse struct {
+	ID           int     `json:"id,omitempty"`
 	Name         string  `json:"name"`
+	Description  string  `json:"description,omitempty"`
 	ProductCount int64   `json:"product_count"`
 	AveragePrice float64 `json:"average_price"`
 }
@@ -73,10 +103,47 @@ type CategoriesResponse struct {
 	Count      int                `json:"count"`
 }
 
-// HealthResponse represents a health check response
-type HealthResponse struct {
-	Service   string `json:"service"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Version   string `json:"version"`
+// PriceHistoryResponse represents a single recorded price change
+type PriceHistoryResponse struct {
+	OldPrice  float64   `json:"old_price"`
+	NewPrice  float64   `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
+	ChangedBy string    `json:"changed_by,omitempty"`
+}
+
+// PriceHistoriesResponse represents the price history of a product
+type PriceHistoriesResponse struct {
+	ProductID int                    `json:"product_id"`
+	History   []PriceHistoryResponse `json:"history"`
+	Count     int                    `json:"count"`
+}
+
+// CreatePromotionRequest represents the request payload for creating a promotion.
+// A missing start date starts the promotion right away.
+type CreatePromotionRequest struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Discount    float64    `json:"discount"`
+	StartDate   *time.Time `json:"start_date"`
+	EndDate     time.Time  `json:"end_date"`
+}
+
+// PromotionResponse represents a promotion response
+type PromotionResponse struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Discount    float64    `json:"discount"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     time.Time  `json:"end_date"`
+	Status      string     `json:"status"` // scheduled, active or ended
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PromotionsResponse represents a page of promotions
+type PromotionsResponse struct {
+	Promotions []PromotionResponse `json:"promotions"`
+	Count      int                 `json:"count"`
+	Pagination *pagination.Page    `json:"pagination,omitempty"`
 }
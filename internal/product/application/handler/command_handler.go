@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+
 	"obs-tools-usage/internal/product/application/command"
 	"obs-tools-usage/internal/product/application/usecase"
 	"obs-tools-usage/internal/product/domain/entity"
@@ -19,16 +21,51 @@ func NewCommandHandler(productUseCase *usecase.ProductUseCase) *CommandHandler {
 }
 
 // HandleCreateProduct handles CreateProductCommand
-func (h *CommandHandler) HandleCreateProduct(cmd command.CreateProductCommand) (*entity.Product, error) {
-	return h.productUseCase.CreateProduct(cmd.ToDTO())
+func (h *CommandHandler) HandleCreateProduct(ctx context.Context, cmd command.CreateProductCommand) (*entity.Product, error) {
+	return h.productUseCase.CreateProduct(ctx, cmd.ToDTO())
 }
 
 // HandleUpdateProduct handles UpdateProductCommand
-func (h *CommandHandler) HandleUpdateProduct(cmd command.UpdateProductCommand) (*entity.Product, error) {
-	return h.productUseCase.UpdateProduct(cmd.ID, cmd.ToDTO())
+func (h *CommandHandler) HandleUpdateProduct(ctx context.Context, cmd command.UpdateProductCommand) (*entity.Product, error) {
+	return h.productUseCase.UpdateProduct(ctx, cmd.ID, cmd.ToDTO())
+}
+
+// HandleUpsertProductBySKU handles UpsertProductBySKUCommand
+func (h *CommandHandler) HandleUpsertProductBySKU(ctx context.Context, cmd command.UpsertProductBySKUCommand) (*entity.Product, bool, error) {
+	return h.productUseCase.UpsertProductBySKU(ctx, cmd.SKU, cmd.ToDTO())
 }
 
 // HandleDeleteProduct handles DeleteProductCommand
-func (h *CommandHandler) HandleDeleteProduct(cmd command.DeleteProductCommand) error {
-	return h.productUseCase.DeleteProduct(cmd.ID)
+func (h *CommandHandler) HandleDeleteProduct(ctx context.Context, cmd command.DeleteProductCommand) error {
+	return h.productUseCase.DeleteProduct(ctx, cmd.ID, cmd.Hard)
+}
+
+// HandleRestoreProduct handles RestoreProductCommand
+func (h *CommandHandler) HandleRestoreProduct(ctx context.Context, cmd command.RestoreProductCommand) (*entity.Product, error) {
+	return h.productUseCase.RestoreProduct(ctx, cmd.ID)
+}
+
+// HandleCreateCategory handles CreateCategoryCommand
+func (h *CommandHandler) HandleCreateCategory(ctx context.Context, cmd command.CreateCategoryCommand) (*entity.ProductCategory, error) {
+	return h.productUseCase.CreateCategory(ctx, cmd.ToDTO())
+}
+
+// HandleUpdateCategory handles UpdateCategoryCommand
+func (h *CommandHandler) HandleUpdateCategory(ctx context.Context, cmd command.UpdateCategoryCommand) (*entity.ProductCategory, error) {
+	return h.productUseCase.UpdateCategory(ctx, cmd.Name, cmd.ToDTO())
+}
+
+// HandleRenameCategory handles RenameCategoryCommand
+func (h *CommandHandler) HandleRenameCategory(ctx context.Context, cmd command.RenameCategoryCommand) (*entity.ProductCategory, error) {
+	return h.productUseCase.RenameCategory(ctx, cmd.OldName, cmd.NewName)
+}
+
+// HandleDeleteCategory handles DeleteCategoryCommand
+func (h *CommandHandler) HandleDeleteCategory(ctx context.Context, cmd command.DeleteCategoryCommand) error {
+	return h.productUseCase.DeleteCategory(ctx, cmd.Name, cmd.ReassignTo)
+}
+
+// HandleCreatePromotion handles CreatePromotionCommand
+func (h *CommandHandler) HandleCreatePromotion(ctx context.Context, cmd command.CreatePromotionCommand) (*entity.Promotion, error) {
+	return h.productUseCase.CreatePromotion(ctx, cmd.ToDTO())
 }
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/product/application/usecase"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/kafka/events"
+	"obs-tools-usage/kafka/publisher"
+)
+
+// PromotionScheduler publishes a PromotionCreatedEvent for every promotion once it starts.
+// It sleeps until the next scheduled start, waking early when a promotion is created and at
+// least every poll interval, so promotions stored by another replica are not missed.
+// Promotions are marked published only after the event is sent; a crash in between republishes
+// the same event ID, which consumers skip as a duplicate.
+type PromotionScheduler struct {
+	productUseCase *usecase.ProductUseCase
+	publisher      publisher.PromotionEventPublisher
+	pollInterval   time.Duration
+	batchSize      int
+	logger         *logrus.Logger
+}
+
+// NewPromotionScheduler creates a new promotion scheduler
+func NewPromotionScheduler(productUseCase *usecase.ProductUseCase, promotionPublisher publisher.PromotionEventPublisher, pollInterval time.Duration, batchSize int, logger *logrus.Logger) *PromotionScheduler {
+	return &PromotionScheduler{
+		productUseCase: productUseCase,
+		publisher:      promotionPublisher,
+		pollInterval:   pollInterval,
+		batchSize:      batchSize,
+		logger:         logger,
+	}
+}
+
+// Start publishes due promotions until ctx is cancelled
+func (s *PromotionScheduler) Start(ctx context.Context) {
+	s.logger.WithField("poll_interval", s.pollInterval.String()).Info("Promotion scheduler started")
+
+	for {
+		s.publishDue(ctx)
+
+		timer := time.NewTimer(s.nextWake(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("Promotion scheduler stopped")
+			return
+		case <-s.productUseCase.PromotionsChanged():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// publishDue publishes the events of every running promotion that has not been announced yet
+func (s *PromotionScheduler) publishDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		promotions, err := s.productUseCase.GetDuePromotions(ctx, time.Now(), s.batchSize)
+		if err != nil {
+			s.logger.WithError(err).Warn("Failed to load due promotions")
+			return
+		}
+
+		for _, promotion := range promotions {
+			if err := s.publish(ctx, promotion); err != nil {
+				// Retried on the next wake; later promotions wait so events keep their start order
+				s.logger.WithError(err).WithField("promotion_id", promotion.ID).Warn("Failed to publish promotion created event")
+				return
+			}
+		}
+
+		if len(promotions) < s.batchSize {
+			return
+		}
+	}
+}
+
+// publish sends a promotion's PromotionCreatedEvent and marks the promotion published
+func (s *PromotionScheduler) publish(ctx context.Context, promotion entity.Promotion) error {
+	promotionID := strconv.Itoa(promotion.ID)
+	event := &events.PromotionCreatedEvent{
+		// Derived from the promotion so a republish after a crash is deduplicated downstream
+		EventID:     "promotion-created-" + promotionID,
+		PromotionID: promotionID,
+		Title:       promotion.Title,
+		Description: promotion.Description,
+		Discount:    promotion.Discount,
+		StartDate:   promotion.StartDate.Format(time.RFC3339),
+		EndDate:     promotion.EndDate.Format(time.RFC3339),
+	}
+	if err := s.publisher.PublishPromotionCreated(ctx, event); err != nil {
+		return err
+	}
+
+	return s.productUseCase.MarkPromotionPublished(ctx, promotion.ID, time.Now())
+}
+
+// nextWake returns how long to sleep before the next scheduled promotion starts, at most the poll interval
+func (s *PromotionScheduler) nextWake(ctx context.Context) time.Duration {
+	now := time.Now()
+	next, err := s.productUseCase.GetNextPromotionStart(ctx, now)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to load next promotion start")
+		return s.pollInterval
+	}
+	if next == nil {
+		return s.pollInterval
+	}
+
+	wait := next.Sub(now)
+	if wait > s.pollInterval {
+		return s.pollInterval
+	}
+	return wait
+}
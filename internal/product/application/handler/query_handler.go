@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"context"
+	"time"
+
 	"obs-tools-usage/internal/product/application/query"
 	"obs-tools-usage/internal/product/application/usecase"
 	"obs-tools-usage/internal/product/domain/entity"
@@ -19,61 +22,118 @@ func NewQueryHandler(productUseCase *usecase.ProductUseCase) *QueryHandler {
 }
 
 // HandleGetProduct handles GetProductQuery
-func (h *QueryHandler) HandleGetProduct(q query.GetProductQuery) (*entity.Product, error) {
-	return h.productUseCase.GetProductByID(q.ID)
+func (h *QueryHandler) HandleGetProduct(ctx context.Context, q query.GetProductQuery) (*entity.Product, error) {
+	if q.IncludeDeleted {
+		return h.productUseCase.GetProductByIDIncludingDeleted(ctx, q.ID)
+	}
+	return h.productUseCase.GetProductByID(ctx, q.ID)
+}
+
+// HandleGetPriceHistory handles GetPriceHistoryQuery
+func (h *QueryHandler) HandleGetPriceHistory(ctx context.Context, q query.GetPriceHistoryQuery) ([]entity.ProductPriceHistory, error) {
+	return h.productUseCase.GetPriceHistory(ctx, q.ProductID, q.Limit)
 }
 
 // HandleGetProducts handles GetProductsQuery
-func (h *QueryHandler) HandleGetProducts(q query.GetProductsQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetAllProducts()
+func (h *QueryHandler) HandleGetProducts(ctx context.Context, q query.GetProductsQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetAllProducts(ctx)
+}
+
+// HandleGetProductsPage handles GetProductsPageQuery
+func (h *QueryHandler) HandleGetProductsPage(ctx context.Context, q query.GetProductsPageQuery) ([]entity.Product, int64, error) {
+	return h.productUseCase.GetProductsPage(ctx, q.Limit, q.Offset, q.Sort)
 }
 
 // HandleGetTopMostExpensive handles GetTopMostExpensiveQuery
-func (h *QueryHandler) HandleGetTopMostExpensive(q query.GetTopMostExpensiveQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetTopMostExpensive(q.Limit)
+func (h *QueryHandler) HandleGetTopMostExpensive(ctx context.Context, q query.GetTopMostExpensiveQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetTopMostExpensive(ctx, q.Limit, q.Category)
 }
 
 // HandleGetLowStockProducts handles GetLowStockProductsQuery
-func (h *QueryHandler) HandleGetLowStockProducts(q query.GetLowStockProductsQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetLowStockProducts(q.MaxStock)
+func (h *QueryHandler) HandleGetLowStockProducts(ctx context.Context, q query.GetLowStockProductsQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetLowStockProducts(ctx, q.MaxStock)
+}
+
+// HandleGetProductsAtLowStock handles GetProductsAtLowStockQuery
+func (h *QueryHandler) HandleGetProductsAtLowStock(ctx context.Context, q query.GetProductsAtLowStockQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetProductsAtLowStock(ctx)
 }
 
 // HandleGetProductsByCategory handles GetProductsByCategoryQuery
-func (h *QueryHandler) HandleGetProductsByCategory(q query.GetProductsByCategoryQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetProductsByCategory(q.Category)
+func (h *QueryHandler) HandleGetProductsByCategory(ctx context.Context, q query.GetProductsByCategoryQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetProductsByCategory(ctx, q.Category)
 }
 
 // HandleGetProductsByPriceRange handles GetProductsByPriceRangeQuery
-func (h *QueryHandler) HandleGetProductsByPriceRange(q query.GetProductsByPriceRangeQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetProductsByPriceRange(q.MinPrice, q.MaxPrice)
+func (h *QueryHandler) HandleGetProductsByPriceRange(ctx context.Context, q query.GetProductsByPriceRangeQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetProductsByPriceRange(ctx, q.MinPrice, q.MaxPrice)
 }
 
 // HandleGetProductsByName handles GetProductsByNameQuery
-func (h *QueryHandler) HandleGetProductsByName(q query.GetProductsByNameQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetProductsByName(q.Name)
+func (h *QueryHandler) HandleGetProductsByName(ctx context.Context, q query.GetProductsByNameQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetProductsByName(ctx, q.Name)
+}
+
+// HandleSearchProducts handles SearchProductsQuery
+func (h *QueryHandler) HandleSearchProducts(ctx context.Context, q query.SearchProductsQuery) ([]entity.ProductMatch, int64, error) {
+	return h.productUseCase.SearchProducts(ctx, entity.ProductSearch{
+		Text:        q.Text,
+		Category:    q.Category,
+		MinPrice:    q.MinPrice,
+		MaxPrice:    q.MaxPrice,
+		InStockOnly: q.InStockOnly,
+		Sort:        q.Sort,
+		Limit:       q.Limit,
+		Offset:      q.Offset,
+	})
+}
+
+// HandleExportProducts handles ExportProductsQuery, calling fn for each product
+func (h *QueryHandler) HandleExportProducts(ctx context.Context, q query.ExportProductsQuery, fn func(entity.Product) error) error {
+	return h.productUseCase.StreamProducts(ctx, q.Category, fn)
+}
+
+// HandleWatchProducts handles subscribing to product changes
+func (h *QueryHandler) HandleWatchProducts(q query.WatchProductsQuery) *usecase.ProductWatch {
+	return h.productUseCase.WatchProducts(q.Category)
 }
 
 // HandleGetProductStats handles GetProductStatsQuery
-func (h *QueryHandler) HandleGetProductStats(q query.GetProductStatsQuery) (*entity.ProductStats, error) {
-	return h.productUseCase.GetProductStats()
+func (h *QueryHandler) HandleGetProductStats(ctx context.Context, q query.GetProductStatsQuery) (*entity.ProductStats, time.Time, error) {
+	return h.productUseCase.GetProductStats(ctx, q.Fresh)
 }
 
 // HandleGetCategories handles GetCategoriesQuery
-func (h *QueryHandler) HandleGetCategories(q query.GetCategoriesQuery) ([]entity.Category, error) {
-	return h.productUseCase.GetCategories()
+func (h *QueryHandler) HandleGetCategories(ctx context.Context, q query.GetCategoriesQuery) ([]entity.Category, error) {
+	return h.productUseCase.GetCategories(ctx)
+}
+
+// HandleGetCategory handles GetCategoryQuery
+func (h *QueryHandler) HandleGetCategory(ctx context.Context, q query.GetCategoryQuery) (*entity.ProductCategory, error) {
+	return h.productUseCase.GetCategory(ctx, q.Name)
 }
 
 // HandleGetProductsByStock handles GetProductsByStockQuery
-func (h *QueryHandler) HandleGetProductsByStock(q query.GetProductsByStockQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetProductsByStock(q.Stock)
+func (h *QueryHandler) HandleGetProductsByStock(ctx context.Context, q query.GetProductsByStockQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetProductsByStock(ctx, q.Stock)
 }
 
 // HandleGetRandomProducts handles GetRandomProductsQuery
-func (h *QueryHandler) HandleGetRandomProducts(q query.GetRandomProductsQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetRandomProducts(q.Count)
+func (h *QueryHandler) HandleGetRandomProducts(ctx context.Context, q query.GetRandomProductsQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetRandomProducts(ctx, q.Count)
 }
 
 // HandleGetProductsByDateRange handles GetProductsByDateRangeQuery
-func (h *QueryHandler) HandleGetProductsByDateRange(q query.GetProductsByDateRangeQuery) ([]entity.Product, error) {
-	return h.productUseCase.GetProductsByDateRange(q.StartDate, q.EndDate)
+func (h *QueryHandler) HandleGetProductsByDateRange(ctx context.Context, q query.GetProductsByDateRangeQuery) ([]entity.Product, error) {
+	return h.productUseCase.GetProductsByDateRange(ctx, q.StartDate, q.EndDate)
+}
+
+// HandleGetPromotion handles GetPromotionQuery
+func (h *QueryHandler) HandleGetPromotion(ctx context.Context, q query.GetPromotionQuery) (*entity.Promotion, error) {
+	return h.productUseCase.GetPromotion(ctx, q.ID)
+}
+
+// HandleGetPromotions handles GetPromotionsQuery
+func (h *QueryHandler) HandleGetPromotions(ctx context.Context, q query.GetPromotionsQuery) ([]entity.Promotion, int64, error) {
+	return h.productUseCase.GetPromotionsPage(ctx, q.Limit, q.Offset)
 }
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/product/application/usecase"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/kafka/consumer"
+	"obs-tools-usage/kafka/events"
+	"obs-tools-usage/kafka/publisher"
+)
+
+// Stock update operations carried by StockUpdateEvent
+const (
+	stockOperationDecrease = "decrease"
+	stockOperationIncrease = "increase"
+)
+
+var _ consumer.StockEventHandler = (*StockEventHandler)(nil)
+
+// StockEventHandler applies stock updates from Kafka to the catalog and publishes
+// StockLow/StockOut alerts when an update crosses a threshold
+type StockEventHandler struct {
+	productUseCase    *usecase.ProductUseCase
+	publisher         publisher.StockEventPublisher
+	lowStockThreshold int // Applies to products without their own threshold
+	logger            *logrus.Logger
+}
+
+// NewStockEventHandler creates a new stock event handler
+func NewStockEventHandler(productUseCase *usecase.ProductUseCase, stockPublisher publisher.StockEventPublisher, lowStockThreshold int, logger *logrus.Logger) *StockEventHandler {
+	return &StockEventHandler{
+		productUseCase:    productUseCase,
+		publisher:         stockPublisher,
+		lowStockThreshold: lowStockThreshold,
+		logger:            logger,
+	}
+}
+
+// HandleStockUpdate applies a stock increase or decrease.
+// Events with an unknown operation or a non-positive quantity can never be applied and are skipped.
+func (h *StockEventHandler) HandleStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error {
+	logger := h.logger.WithFields(logrus.Fields{
+		"event_id":   event.EventID,
+		"product_id": event.ProductID,
+		"quantity":   event.Quantity,
+		"operation":  event.Operation,
+		"reason":     event.Reason,
+	})
+
+	if event.Quantity <= 0 {
+		logger.Warn("Stock update with non-positive quantity, skipping")
+		return nil
+	}
+
+	var delta int
+	switch event.Operation {
+	case stockOperationDecrease:
+		delta = -event.Quantity
+	case stockOperationIncrease:
+		delta = event.Quantity
+	default:
+		logger.Warn("Stock update with unknown operation, skipping")
+		return nil
+	}
+
+	// The event ID is recorded with the stock change, so a redelivered event is not applied twice
+	product, previousStock, applied, err := h.productUseCase.AdjustStock(ctx, event.ProductID, delta, event.EventID)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		logger.Debug("Stock event already applied, skipping")
+		return nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"previous_stock": previousStock,
+		"stock":          product.Stock,
+	}).Info("Stock update applied")
+
+	// The stock is already adjusted, so alert failures are logged rather than retried
+	if err := h.publishStockAlerts(ctx, product, previousStock); err != nil {
+		logger.WithError(err).Warn("Failed to publish stock alert")
+	}
+	return nil
+}
+
+// publishStockAlerts publishes a StockOut event when the stock ran out, or a StockLow event
+// when it dropped to or below the product's low stock threshold. Each fires only when the threshold is crossed.
+func (h *StockEventHandler) publishStockAlerts(ctx context.Context, product *entity.Product, previousStock int) error {
+	now := time.Now().Format(time.RFC3339)
+	threshold := product.StockThreshold(h.lowStockThreshold)
+
+	switch {
+	case product.Stock == 0 && previousStock > 0:
+		return h.publisher.PublishStockOut(ctx, &events.StockOutEvent{
+			EventID:     uuid.New().String(),
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Timestamp:   now,
+		})
+
+	case product.Stock <= threshold && previousStock > threshold:
+		return h.publisher.PublishStockLow(ctx, &events.StockLowEvent{
+			EventID:      uuid.New().String(),
+			ProductID:    product.ID,
+			ProductName:  product.Name,
+			CurrentStock: product.Stock,
+			Threshold:    threshold,
+			Timestamp:    now,
+		})
+	}
+	return nil
+}
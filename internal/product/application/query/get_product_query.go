@@ -2,5 +2,23 @@ package query
 
 // GetProductQuery represents a query to get a product by ID
 type GetProductQuery struct {
+	ID             int  `json:"id" binding:"required"`
+	IncludeDeleted bool `json:"include_deleted"`
+}
+
+// GetPriceHistoryQuery represents a query to get the price history of a product
+type GetPriceHistoryQuery struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Limit     int `json:"limit"`
+}
+
+// GetPromotionQuery represents a query to get a promotion by ID
+type GetPromotionQuery struct {
 	ID int `json:"id" binding:"required"`
 }
+
+// GetPromotionsQuery represents a query to get a page of promotions
+type GetPromotionsQuery struct {
+	Limit  int `json:"limit" binding:"required,min=1"`
+	Offset int `json:"offset" binding:"min=0"`
+}
@@ -1,13 +1,23 @@
 package query
 
+import "time"
+
 // GetProductsQuery represents a query to get all products
 type GetProductsQuery struct {
 	// No filters for now, can add pagination/filters later
 }
 
+// GetProductsPageQuery represents a query to get a page of products
+type GetProductsPageQuery struct {
+	Limit  int    `json:"limit" binding:"required,min=1"`
+	Offset int    `json:"offset" binding:"min=0"`
+	Sort   string `json:"sort,omitempty"` // Field to order by, prefixed with "-" for descending; defaults to id
+}
+
 // GetTopMostExpensiveQuery represents a query to get top most expensive products
 type GetTopMostExpensiveQuery struct {
-	Limit int `json:"limit" binding:"required,min=1"`
+	Limit    int    `json:"limit" binding:"required,min=1"`
+	Category string `json:"category,omitempty"`
 }
 
 // GetLowStockProductsQuery represents a query to get low stock products
@@ -15,6 +25,9 @@ type GetLowStockProductsQuery struct {
 	MaxStock int `json:"max_stock" binding:"required,min=0"`
 }
 
+// GetProductsAtLowStockQuery represents a query to get products at or below their own low stock threshold
+type GetProductsAtLowStockQuery struct{}
+
 // GetProductsByCategoryQuery represents a query to get products by category
 type GetProductsByCategoryQuery struct {
 	Category string `json:"category" binding:"required"`
@@ -31,12 +44,41 @@ type GetProductsByNameQuery struct {
 	Name string `json:"name" binding:"required"`
 }
 
+// SearchProductsQuery represents a query combining optional product filters with sorting and pagination
+type SearchProductsQuery struct {
+	Text        string   `json:"q,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	MinPrice    *float64 `json:"min_price,omitempty"`
+	MaxPrice    *float64 `json:"max_price,omitempty"`
+	InStockOnly bool     `json:"in_stock,omitempty"`
+	Sort        string   `json:"sort,omitempty"`
+	Limit       int      `json:"limit" binding:"required,min=1"`
+	Offset      int      `json:"offset" binding:"min=0"`
+}
+
+// ExportProductsQuery represents a query to stream all products, optionally of one category
+type ExportProductsQuery struct {
+	Category string `json:"category,omitempty"`
+}
+
+// WatchProductsQuery represents a subscription to product changes, optionally of one category
+type WatchProductsQuery struct {
+	Category string `json:"category,omitempty"`
+}
+
 // GetProductStatsQuery represents a query to get product statistics
-type GetProductStatsQuery struct{}
+type GetProductStatsQuery struct {
+	Fresh bool `json:"fresh"` // Recompute instead of serving the cached snapshot
+}
 
 // GetCategoriesQuery represents a query to get categories
 type GetCategoriesQuery struct{}
 
+// GetCategoryQuery represents a query to get a single category by name
+type GetCategoryQuery struct {
+	Name string `json:"name" binding:"required"`
+}
+
 // GetProductsByStockQuery represents a query to get products by stock
 type GetProductsByStockQuery struct {
 	Stock int `json:"stock" binding:"required"`
@@ -49,6 +91,6 @@ type GetRandomProductsQuery struct {
 
 // GetProductsByDateRangeQuery represents a query to get products by date range
 type GetProductsByDateRangeQuery struct {
-	StartDate string `json:"start_date" binding:"required"`
-	EndDate   string `json:"end_date" binding:"required"`
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"` // Inclusive
 }
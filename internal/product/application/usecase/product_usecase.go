@@ -1,35 +1,130 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 	"obs-tools-usage/internal/product/application/dto"
 	"obs-tools-usage/internal/product/domain/entity"
 	"obs-tools-usage/internal/product/domain/repository"
 	"obs-tools-usage/internal/product/domain/service"
+	"obs-tools-usage/internal/product/infrastructure/cache"
+	"obs-tools-usage/internal/product/infrastructure/config"
+	"obs-tools-usage/internal/product/infrastructure/external"
+)
+
+// statsReadKey is the singleflight key shared by product statistics recomputes
+const statsReadKey = "stats"
+
+// otherCategoryLabel labels the category cache metrics of listings without products, so
+// lookups of arbitrary category names cannot grow the metrics' label set
+const otherCategoryLabel = "other"
+
+// Triggers of a product statistics recompute, as recorded by product_stats_recompute_total
+const (
+	statsTriggerScheduled = "scheduled" // The periodic refresher
+	statsTriggerForced    = "forced"    // A caller asked for fresh statistics
+	statsTriggerMiss      = "miss"      // No snapshot had been computed yet
 )
 
 // ProductUseCase handles product business logic
 type ProductUseCase struct {
-	productRepo       repository.ProductRepository
-	domainService     *service.ProductDomainService
+	productRepo    repository.ProductRepository
+	domainService  *service.ProductDomainService
+	productReads   singleflight.Group
+	productCache   *cache.TTLCache[int, entity.Product]
+	categoryCache  cache.ProductListCache
+	productWatches *productWatchHub
+	metrics        *external.Metrics
+
+	// promotionsChanged wakes the promotion scheduler when a promotion is created
+	promotionsChanged chan struct{}
+
+	statsMu          sync.RWMutex
+	stats            *entity.ProductStats
+	statsRefreshedAt time.Time
 }
 
-// NewProductUseCase creates a new product use case
-func NewProductUseCase(productRepo repository.ProductRepository) *ProductUseCase {
+// NewProductUseCase creates a new product use case; categoryCache holds product listings by category
+func NewProductUseCase(productRepo repository.ProductRepository, cacheConfig config.CacheConfig, validation config.ValidationConfig, metrics *external.Metrics, categoryCache cache.ProductListCache) *ProductUseCase {
 	return &ProductUseCase{
-		productRepo:   productRepo,
-		domainService: service.NewProductDomainService(),
+		productRepo: productRepo,
+		domainService: service.NewProductDomainService(service.ValidationRules{
+			AllowedCategories:    validation.AllowedCategories,
+			MaxNameLength:        validation.MaxNameLength,
+			MaxDescriptionLength: validation.MaxDescriptionLength,
+		}),
+		productCache:   cache.NewTTLCache[int, entity.Product]("product", cacheConfig.ProductTTL, metrics),
+		categoryCache:  categoryCache,
+		productWatches: newProductWatchHub(metrics),
+		metrics:        metrics,
+
+		promotionsChanged: make(chan struct{}, 1),
 	}
 }
 
 // GetAllProducts returns all products
-func (uc *ProductUseCase) GetAllProducts() ([]entity.Product, error) {
-	return uc.productRepo.GetAllProducts()
+func (uc *ProductUseCase) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
+	return uc.productRepo.GetAllProducts(ctx)
+}
+
+// GetProductsPage returns a page of products and the total number of products
+func (uc *ProductUseCase) GetProductsPage(ctx context.Context, limit, offset int, sort string) ([]entity.Product, int64, error) {
+	if !entity.ValidProductSort(sort) {
+		return nil, 0, fmt.Errorf("invalid sort field: %s", sort)
+	}
+	return uc.productRepo.GetProductsPage(ctx, limit, offset, sort)
+}
+
+// GetProductByID returns a product by its ID.
+// Concurrent reads of the same product share one database query, and results are
+// briefly cached when a product cache TTL is configured. The shared query runs in the
+// first caller's trace but is not cancelled with it, since other callers may be waiting.
+func (uc *ProductUseCase) GetProductByID(ctx context.Context, id int) (*entity.Product, error) {
+	if cached, ok := uc.productCache.Get(id); ok {
+		return &cached, nil
+	}
+
+	generation := uc.productCache.Generation()
+	result, err, _ := uc.productReads.Do(strconv.Itoa(id), func() (interface{}, error) {
+		product, err := uc.productRepo.GetProductByID(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		uc.productCache.SetIfGeneration(id, *product, generation)
+		return *product, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	// Each caller gets its own copy so shared results cannot be mutated
+	product := result.(entity.Product)
+	return &product, nil
+}
+
+// invalidateProduct drops a product from the read cache after it changes
+func (uc *ProductUseCase) invalidateProduct(id int) {
+	uc.productCache.Delete(id)
+	uc.productReads.Forget(strconv.Itoa(id))
 }
 
-// GetProductByID returns a product by its ID
-func (uc *ProductUseCase) GetProductByID(id int) (*entity.Product, error) {
-	product, err := uc.productRepo.GetProductByID(id)
+// invalidateCategories drops the cached listings of categories whose products changed
+func (uc *ProductUseCase) invalidateCategories(categories ...string) {
+	for _, category := range categories {
+		uc.categoryCache.Delete(category)
+	}
+}
+
+// GetProductByIDIncludingDeleted returns a product by its ID even if it has been soft-deleted
+func (uc *ProductUseCase) GetProductByIDIncludingDeleted(ctx context.Context, id int) (*entity.Product, error) {
+	product, err := uc.productRepo.GetProductByIDUnscoped(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
@@ -37,114 +132,428 @@ func (uc *ProductUseCase) GetProductByID(id int) (*entity.Product, error) {
 }
 
 // CreateProduct creates a new product
-func (uc *ProductUseCase) CreateProduct(req dto.CreateProductRequest) (*entity.Product, error) {
+func (uc *ProductUseCase) CreateProduct(ctx context.Context, req dto.CreateProductRequest) (*entity.Product, error) {
 	// Convert DTO to entity
 	product := entity.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
-		Category:    req.Category,
+		Name:              req.Name,
+		Description:       req.Description,
+		Price:             req.Price,
+		Stock:             req.Stock,
+		Category:          req.Category,
+		LowStockThreshold: req.LowStockThreshold,
 	}
 
 	// Validate using domain service
-	if err := uc.domainService.ValidateProduct(product); err != nil {
+	if err := uc.domainService.Validate(product); err != nil {
 		return nil, err
 	}
 
 	// Create product
-	createdProduct, err := uc.productRepo.CreateProduct(product)
+	createdProduct, err := uc.productRepo.CreateProduct(ctx, product)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create product: %w", err)
 	}
+	uc.invalidateCategories(createdProduct.Category)
+	uc.productWatches.publish(entity.ProductChangeCreated, *createdProduct)
 
 	return createdProduct, nil
 }
 
 // UpdateProduct updates an existing product
-func (uc *ProductUseCase) UpdateProduct(id int, req dto.UpdateProductRequest) (*entity.Product, error) {
+func (uc *ProductUseCase) UpdateProduct(ctx context.Context, id int, req dto.UpdateProductRequest) (*entity.Product, error) {
 	// Check if product exists
-	existingProduct, err := uc.productRepo.GetProductByID(id)
+	existingProduct, err := uc.productRepo.GetProductByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
 
+	// Listings of the old category change too when the product moves
+	previousCategory := existingProduct.Category
+
 	// Update fields
 	existingProduct.Name = req.Name
 	existingProduct.Description = req.Description
 	existingProduct.Price = req.Price
 	existingProduct.Stock = req.Stock
 	existingProduct.Category = req.Category
+	existingProduct.LowStockThreshold = req.LowStockThreshold
 
 	// Validate using domain service
-	if err := uc.domainService.ValidateProduct(*existingProduct); err != nil {
+	if err := uc.domainService.Validate(*existingProduct); err != nil {
 		return nil, err
 	}
 
 	// Update product
-	updatedProduct, err := uc.productRepo.UpdateProduct(*existingProduct)
+	updatedProduct, err := uc.productRepo.UpdateProduct(ctx, *existingProduct, req.ChangedBy)
+	uc.invalidateProduct(id)
+	uc.invalidateCategories(previousCategory, req.Category)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
+	uc.productWatches.publish(entity.ProductChangeUpdated, *updatedProduct)
 
 	return updatedProduct, nil
 }
 
-// DeleteProduct deletes a product by its ID
-func (uc *ProductUseCase) DeleteProduct(id int) error {
-	err := uc.productRepo.DeleteProduct(id)
+// UpsertProductBySKU creates the product with the given external SKU, or updates it when it
+// already exists, so catalog syncs can be re-run without creating duplicates.
+// It reports whether the product was created.
+func (uc *ProductUseCase) UpsertProductBySKU(ctx context.Context, sku string, req dto.UpsertProductRequest) (*entity.Product, bool, error) {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil, false, errors.New("invalid sku: cannot be empty")
+	}
+	if len(sku) > 64 {
+		return nil, false, errors.New("invalid sku: cannot exceed 64 characters")
+	}
+
+	product := entity.Product{
+		Name:              req.Name,
+		Description:       req.Description,
+		Price:             req.Price,
+		Stock:             req.Stock,
+		Category:          req.Category,
+		SKU:               &sku,
+		LowStockThreshold: req.LowStockThreshold,
+	}
+
+	// Validate using domain service
+	if err := uc.domainService.Validate(product); err != nil {
+		return nil, false, err
+	}
+
+	upsertedProduct, created, err := uc.productRepo.UpsertProductBySKU(ctx, product, req.ChangedBy)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to upsert product: %w", err)
+	}
+	uc.invalidateProduct(upsertedProduct.ID)
+	if created {
+		uc.invalidateCategories(upsertedProduct.Category)
+		uc.productWatches.publish(entity.ProductChangeCreated, *upsertedProduct)
+	} else {
+		// The category the product had before the update is unknown here
+		uc.categoryCache.Clear()
+		uc.productWatches.publish(entity.ProductChangeUpdated, *upsertedProduct)
+	}
+
+	return upsertedProduct, created, nil
+}
+
+// AdjustStock changes a product's stock by delta, e.g. when a sale or refund is applied.
+// It returns the updated product and the stock it had before the change. When the change
+// comes from an event, eventID makes it apply once: a repeated event reports applied as false.
+func (uc *ProductUseCase) AdjustStock(ctx context.Context, id int, delta int, eventID string) (*entity.Product, int, bool, error) {
+	if delta == 0 {
+		return nil, 0, false, errors.New("invalid stock adjustment: delta cannot be zero")
+	}
+
+	product, previousStock, applied, err := uc.productRepo.AdjustStock(ctx, id, delta, eventID)
+	if err != nil {
+		uc.invalidateProduct(id)
+		return nil, 0, false, fmt.Errorf("failed to adjust stock: %w", err)
+	}
+	if !applied {
+		return nil, 0, false, nil
+	}
+	uc.invalidateProduct(id)
+	uc.invalidateCategories(product.Category)
+	uc.productWatches.publish(entity.ProductChangeUpdated, *product)
+	return product, previousStock, true, nil
+}
+
+// GetPriceHistory returns the recorded price changes of a product, newest first
+func (uc *ProductUseCase) GetPriceHistory(ctx context.Context, id int, limit int) ([]entity.ProductPriceHistory, error) {
+	if _, err := uc.productRepo.GetProductByIDUnscoped(ctx, id); err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	history, err := uc.productRepo.GetPriceHistory(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	return history, nil
+}
+
+// DeleteProduct soft-deletes a product by its ID, or removes it permanently when hard is set
+func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id int, hard bool) error {
+	// Load the product first so watchers learn which product, and category, was removed
+	product, err := uc.productRepo.GetProductByIDUnscoped(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
+
+	if hard {
+		err = uc.productRepo.HardDeleteProduct(ctx, id)
+	} else {
+		err = uc.productRepo.DeleteProduct(ctx, id)
+	}
+	uc.invalidateProduct(id)
+	uc.invalidateCategories(product.Category)
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	uc.productWatches.publish(entity.ProductChangeDeleted, *product)
 	return nil
 }
 
-// GetTopMostExpensive returns the top N most expensive products
-func (uc *ProductUseCase) GetTopMostExpensive(limit int) ([]entity.Product, error) {
-	return uc.productRepo.GetTopMostExpensive(limit)
+// RestoreProduct restores a soft-deleted product
+func (uc *ProductUseCase) RestoreProduct(ctx context.Context, id int) (*entity.Product, error) {
+	product, err := uc.productRepo.RestoreProduct(ctx, id)
+	uc.invalidateProduct(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore product: %w", err)
+	}
+	uc.invalidateCategories(product.Category)
+	uc.productWatches.publish(entity.ProductChangeCreated, *product)
+	return product, nil
+}
+
+// GetTopMostExpensive returns the top N most expensive products, optionally within a category
+func (uc *ProductUseCase) GetTopMostExpensive(ctx context.Context, limit int, category string) ([]entity.Product, error) {
+	return uc.productRepo.GetTopMostExpensive(ctx, limit, category)
 }
 
 // GetLowStockProducts returns products with stock less than or equal to maxStock
-func (uc *ProductUseCase) GetLowStockProducts(maxStock int) ([]entity.Product, error) {
-	return uc.productRepo.GetLowStockProducts(maxStock)
+func (uc *ProductUseCase) GetLowStockProducts(ctx context.Context, maxStock int) ([]entity.Product, error) {
+	return uc.productRepo.GetLowStockProducts(ctx, maxStock)
+}
+
+// GetProductsAtLowStock returns in-stock products at or below their own low stock threshold
+func (uc *ProductUseCase) GetProductsAtLowStock(ctx context.Context) ([]entity.Product, error) {
+	return uc.productRepo.GetProductsAtLowStock(ctx)
+}
+
+// GetProductsByCategory returns products belonging to a specific category.
+// Listings are cached for the category cache TTL and invalidated when a product of the category changes.
+func (uc *ProductUseCase) GetProductsByCategory(ctx context.Context, category string) ([]entity.Product, error) {
+	if !uc.categoryCache.Enabled() {
+		return uc.productRepo.GetProductsByCategory(ctx, category)
+	}
+
+	if cached, ok := uc.categoryCache.Get(category); ok {
+		uc.metrics.RecordCategoryCacheHit(categoryLabel(category, cached))
+		// Each caller gets its own copy so cached listings cannot be mutated
+		return append([]entity.Product(nil), cached...), nil
+	}
+
+	generation := uc.categoryCache.Generation()
+	products, err := uc.productRepo.GetProductsByCategory(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	uc.metrics.RecordCategoryCacheMiss(categoryLabel(category, products))
+	uc.categoryCache.SetIfGeneration(category, append([]entity.Product(nil), products...), generation)
+
+	return products, nil
 }
 
-// GetProductsByCategory returns products belonging to a specific category
-func (uc *ProductUseCase) GetProductsByCategory(category string) ([]entity.Product, error) {
-	return uc.productRepo.GetProductsByCategory(category)
+// categoryLabel returns the category cache metrics label of a category listing
+func categoryLabel(category string, products []entity.Product) string {
+	if len(products) == 0 {
+		return otherCategoryLabel
+	}
+	return category
 }
 
 // GetProductsByPriceRange returns products by price range
-func (uc *ProductUseCase) GetProductsByPriceRange(minPrice, maxPrice float64) ([]entity.Product, error) {
-	return uc.productRepo.GetProductsByPriceRange(minPrice, maxPrice)
+func (uc *ProductUseCase) GetProductsByPriceRange(ctx context.Context, minPrice, maxPrice float64) ([]entity.Product, error) {
+	return uc.productRepo.GetProductsByPriceRange(ctx, minPrice, maxPrice)
 }
 
 // GetProductsByName returns products by name
-func (uc *ProductUseCase) GetProductsByName(name string) ([]entity.Product, error) {
-	return uc.productRepo.GetProductsByName(name)
+func (uc *ProductUseCase) GetProductsByName(ctx context.Context, name string) ([]entity.Product, error) {
+	return uc.productRepo.GetProductsByName(ctx, name)
+}
+
+// SearchProducts returns a page of products matching the search and the total number of matches
+func (uc *ProductUseCase) SearchProducts(ctx context.Context, search entity.ProductSearch) ([]entity.ProductMatch, int64, error) {
+	if !search.ValidSort() {
+		return nil, 0, fmt.Errorf("invalid sort field: %s", search.Sort)
+	}
+	return uc.productRepo.SearchProducts(ctx, search)
+}
+
+// StreamProducts calls fn for every product, optionally limited to a category, without loading the catalog into memory
+func (uc *ProductUseCase) StreamProducts(ctx context.Context, category string, fn func(entity.Product) error) error {
+	return uc.productRepo.StreamProducts(ctx, category, fn)
+}
+
+// GetProductStats returns the cached product statistics snapshot and when it was computed.
+// The snapshot is recomputed when fresh is set or when none has been computed yet.
+func (uc *ProductUseCase) GetProductStats(ctx context.Context, fresh bool) (*entity.ProductStats, time.Time, error) {
+	if fresh {
+		return uc.refreshProductStats(ctx, statsTriggerForced)
+	}
+
+	uc.statsMu.RLock()
+	stats, refreshedAt := uc.stats, uc.statsRefreshedAt
+	uc.statsMu.RUnlock()
+
+	if stats == nil {
+		uc.metrics.RecordCacheMiss(statsReadKey)
+		return uc.refreshProductStats(ctx, statsTriggerMiss)
+	}
+	uc.metrics.RecordCacheHit(statsReadKey)
+	snapshot := *stats
+	return &snapshot, refreshedAt, nil
+}
+
+// RefreshProductStats recomputes the product statistics snapshot and syncs the business gauges with it.
+// Concurrent refreshes share one set of database queries.
+func (uc *ProductUseCase) RefreshProductStats(ctx context.Context) (*entity.ProductStats, time.Time, error) {
+	return uc.refreshProductStats(ctx, statsTriggerScheduled)
+}
+
+// refreshProductStats recomputes the snapshot, recording the recompute under trigger.
+// Callers that join a recompute already in flight are counted as coalesced instead; like
+// product reads, the shared recompute outlives the cancellation of the caller that started it.
+func (uc *ProductUseCase) refreshProductStats(ctx context.Context, trigger string) (*entity.ProductStats, time.Time, error) {
+	recomputed := false
+	_, err, _ := uc.productReads.Do(statsReadKey, func() (interface{}, error) {
+		recomputed = true
+		stats, err := uc.productRepo.GetProductStats(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		refreshedAt := time.Now()
+		uc.statsMu.Lock()
+		uc.stats = stats
+		uc.statsRefreshedAt = refreshedAt
+		uc.statsMu.Unlock()
+
+		uc.metrics.UpdateProductStatsMetrics(*stats)
+		uc.metrics.RecordProductStatsRecompute(trigger, refreshedAt)
+		return nil, nil
+	})
+	if !recomputed {
+		uc.metrics.RecordProductStatsCoalesced()
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	uc.statsMu.RLock()
+	defer uc.statsMu.RUnlock()
+	snapshot := *uc.stats
+	return &snapshot, uc.statsRefreshedAt, nil
 }
 
-// GetProductStats returns product statistics
-func (uc *ProductUseCase) GetProductStats() (*entity.ProductStats, error) {
-	return uc.productRepo.GetProductStats()
+// StartStatsRefresher recomputes the product statistics snapshot every interval until ctx is cancelled
+func (uc *ProductUseCase) StartStatsRefresher(ctx context.Context, interval time.Duration) {
+	logger := config.GetLogger()
+
+	refresh := func() {
+		if _, _, err := uc.RefreshProductStats(ctx); err != nil {
+			logger.WithError(err).Warn("Failed to refresh product stats, serving previous snapshot")
+		}
+	}
+	refresh()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
 }
 
 // GetCategories returns all categories
-func (uc *ProductUseCase) GetCategories() ([]entity.Category, error) {
-	return uc.productRepo.GetCategories()
+func (uc *ProductUseCase) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	return uc.productRepo.GetCategories(ctx)
+}
+
+// GetCategory returns a managed category by name
+func (uc *ProductUseCase) GetCategory(ctx context.Context, name string) (*entity.ProductCategory, error) {
+	return uc.productRepo.GetCategoryByName(ctx, name)
+}
+
+// CreateCategory creates a new managed category
+func (uc *ProductUseCase) CreateCategory(ctx context.Context, req dto.CreateCategoryRequest) (*entity.ProductCategory, error) {
+	if err := uc.domainService.ValidateCategoryName(req.Name); err != nil {
+		return nil, err
+	}
+
+	category, err := uc.productRepo.CreateCategory(ctx, entity.ProductCategory{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+// UpdateCategory updates the description of a managed category
+func (uc *ProductUseCase) UpdateCategory(ctx context.Context, name string, req dto.UpdateCategoryRequest) (*entity.ProductCategory, error) {
+	category, err := uc.productRepo.GetCategoryByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Description = req.Description
+
+	updatedCategory, err := uc.productRepo.UpdateCategory(ctx, *category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	return updatedCategory, nil
+}
+
+// RenameCategory renames a category and every product that references it
+func (uc *ProductUseCase) RenameCategory(ctx context.Context, oldName, newName string) (*entity.ProductCategory, error) {
+	if err := uc.domainService.ValidateCategoryName(newName); err != nil {
+		return nil, err
+	}
+	if oldName == newName {
+		return nil, fmt.Errorf("invalid category: new name must differ from %q", oldName)
+	}
+
+	category, err := uc.productRepo.RenameCategory(ctx, oldName, newName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename category: %w", err)
+	}
+	uc.invalidateCategories(oldName, newName)
+
+	return category, nil
+}
+
+// DeleteCategory deletes a category, optionally reassigning its products to another category
+func (uc *ProductUseCase) DeleteCategory(ctx context.Context, name, reassignTo string) error {
+	if reassignTo != "" {
+		if err := uc.domainService.ValidateCategoryName(reassignTo); err != nil {
+			return err
+		}
+		if reassignTo == name {
+			return fmt.Errorf("invalid category: cannot reassign %q to itself", name)
+		}
+	}
+
+	if err := uc.productRepo.DeleteCategory(ctx, name, reassignTo); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	uc.invalidateCategories(name, reassignTo)
+	return nil
 }
 
 // GetProductsByStock returns products by stock level
-func (uc *ProductUseCase) GetProductsByStock(stock int) ([]entity.Product, error) {
-	return uc.productRepo.GetProductsByStock(stock)
+func (uc *ProductUseCase) GetProductsByStock(ctx context.Context, stock int) ([]entity.Product, error) {
+	return uc.productRepo.GetProductsByStock(ctx, stock)
 }
 
 // GetRandomProducts returns random products
-func (uc *ProductUseCase) GetRandomProducts(count int) ([]entity.Product, error) {
-	return uc.productRepo.GetRandomProducts(count)
+func (uc *ProductUseCase) GetRandomProducts(ctx context.Context, count int) ([]entity.Product, error) {
+	return uc.productRepo.GetRandomProducts(ctx, count)
 }
 
 // GetProductsByDateRange returns products by date range
-func (uc *ProductUseCase) GetProductsByDateRange(startDate, endDate string) ([]entity.Product, error) {
-	return uc.productRepo.GetProductsByDateRange(startDate, endDate)
+func (uc *ProductUseCase) GetProductsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]entity.Product, error) {
+	return uc.productRepo.GetProductsByDateRange(ctx, startDate, endDate)
 }
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/internal/product/infrastructure/config"
+	"obs-tools-usage/internal/product/infrastructure/external"
+)
+
+// productWatchBufferSize is how many changes a watcher may fall behind before it is dropped
+const productWatchBufferSize = 256
+
+// ProductWatch is a subscription to product catalog changes.
+// Its Changes channel is closed when the watch is closed or the watcher is dropped for falling behind.
+type ProductWatch struct {
+	id       uint64
+	category string
+	changes  chan entity.ProductChange
+	hub      *productWatchHub
+}
+
+// Changes returns the channel the watched product changes are delivered on
+func (w *ProductWatch) Changes() <-chan entity.ProductChange {
+	return w.changes
+}
+
+// Close stops the watch; it is safe to call more than once
+func (w *ProductWatch) Close() {
+	w.hub.remove(w.id)
+}
+
+// productWatchHub fans product changes out to watchers without ever blocking the writer
+type productWatchHub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	watches map[uint64]*ProductWatch
+	metrics *external.Metrics
+	logger  *logrus.Logger
+}
+
+func newProductWatchHub(metrics *external.Metrics) *productWatchHub {
+	return &productWatchHub{
+		watches: make(map[uint64]*ProductWatch),
+		metrics: metrics,
+		logger:  config.GetLogger(),
+	}
+}
+
+// add registers a watcher for changes to products of category, or of every category when empty
+func (h *productWatchHub) add(category string) *ProductWatch {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	watch := &ProductWatch{
+		id:       h.nextID,
+		category: category,
+		changes:  make(chan entity.ProductChange, productWatchBufferSize),
+		hub:      h,
+	}
+	h.watches[watch.id] = watch
+	h.metrics.SetProductWatchSubscribers(len(h.watches))
+	return watch
+}
+
+// remove unregisters a watcher and closes its channel
+func (h *productWatchHub) remove(id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if watch, ok := h.watches[id]; ok {
+		delete(h.watches, id)
+		close(watch.changes)
+		h.metrics.SetProductWatchSubscribers(len(h.watches))
+	}
+}
+
+// publish delivers a change to every matching watcher. A watcher whose buffer is full is
+// dropped with a warning instead of holding up the write that produced the change.
+func (h *productWatchHub) publish(changeType entity.ProductChangeType, product entity.Product) {
+	change := entity.ProductChange{
+		Type:       changeType,
+		Product:    product,
+		OccurredAt: time.Now(),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, watch := range h.watches {
+		if watch.category != "" && watch.category != product.Category {
+			continue
+		}
+		select {
+		case watch.changes <- change:
+		default:
+			delete(h.watches, id)
+			close(watch.changes)
+			h.metrics.RecordProductWatchDropped()
+			h.logger.WithFields(logrus.Fields{
+				"watch_id":    id,
+				"category":    watch.category,
+				"buffer_size": productWatchBufferSize,
+			}).Warn("Dropping product watcher that fell behind")
+		}
+	}
+	h.metrics.SetProductWatchSubscribers(len(h.watches))
+}
+
+// WatchProducts subscribes to product creates, updates and deletes, optionally limited to a category.
+// Callers must Close the watch when they stop reading from it.
+func (uc *ProductUseCase) WatchProducts(category string) *ProductWatch {
+	return uc.productWatches.add(category)
+}
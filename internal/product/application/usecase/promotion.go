@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"obs-tools-usage/internal/product/application/dto"
+	"obs-tools-usage/internal/product/domain/entity"
+)
+
+// CreatePromotion validates and stores a promotion. Its PromotionCreatedEvent is published by the
+// promotion scheduler when the promotion starts, right away when it is already running.
+func (uc *ProductUseCase) CreatePromotion(ctx context.Context, req dto.CreatePromotionRequest) (*entity.Promotion, error) {
+	promotion := entity.Promotion{
+		Title:       req.Title,
+		Description: req.Description,
+		Discount:    req.Discount,
+		EndDate:     req.EndDate,
+	}
+	if req.StartDate != nil {
+		promotion.StartDate = *req.StartDate
+	} else {
+		promotion.StartDate = time.Now()
+	}
+
+	if err := uc.domainService.ValidatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.productRepo.CreatePromotion(ctx, promotion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	// Let the scheduler pick up an earlier start than the one it is waiting for
+	select {
+	case uc.promotionsChanged <- struct{}{}:
+	default:
+	}
+
+	return created, nil
+}
+
+// GetPromotion returns a promotion by its ID
+func (uc *ProductUseCase) GetPromotion(ctx context.Context, id int) (*entity.Promotion, error) {
+	return uc.productRepo.GetPromotionByID(ctx, id)
+}
+
+// GetPromotionsPage returns a page of promotions and the total number of promotions
+func (uc *ProductUseCase) GetPromotionsPage(ctx context.Context, limit, offset int) ([]entity.Promotion, int64, error) {
+	return uc.productRepo.GetPromotionsPage(ctx, limit, offset)
+}
+
+// GetDuePromotions returns up to limit running promotions whose PromotionCreatedEvent is not yet published
+func (uc *ProductUseCase) GetDuePromotions(ctx context.Context, now time.Time, limit int) ([]entity.Promotion, error) {
+	return uc.productRepo.GetDuePromotions(ctx, now, limit)
+}
+
+// GetNextPromotionStart returns when the next scheduled promotion starts, or nil when none is scheduled
+func (uc *ProductUseCase) GetNextPromotionStart(ctx context.Context, after time.Time) (*time.Time, error) {
+	return uc.productRepo.GetNextPromotionStart(ctx, after)
+}
+
+// MarkPromotionPublished records that a promotion's PromotionCreatedEvent was published
+func (uc *ProductUseCase) MarkPromotionPublished(ctx context.Context, id int, publishedAt time.Time) error {
+	return uc.productRepo.MarkPromotionPublished(ctx, id, publishedAt)
+}
+
+// PromotionsChanged returns a channel signalled, without blocking writers, whenever a promotion is created
+func (uc *ProductUseCase) PromotionsChanged() <-chan struct{} {
+	return uc.promotionsChanged
+}
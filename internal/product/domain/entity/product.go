@@ -1,19 +1,43 @@
 package entity
 
 import (
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Product represents a product in the system
 type Product struct {
-	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name" binding:"required"`
-	Description string    `json:"description" db:"description"`
-	Price       float64   `json:"price" db:"price" binding:"required,min=0"`
-	Stock       int       `json:"stock" db:"stock" binding:"min=0"`
-	Category    string    `json:"category" db:"category"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                int            `json:"id" db:"id"`
+	Name              string         `json:"name" db:"name" binding:"required"`
+	Description       string         `json:"description" db:"description"`
+	Price             float64        `json:"price" db:"price" binding:"required,min=0"`
+	Stock             int            `json:"stock" db:"stock" binding:"min=0"`
+	Category          string         `json:"category" db:"category"`
+	SKU               *string        `json:"sku,omitempty" db:"sku" gorm:"uniqueIndex;size:64"`      // Optional external catalog key, unique when present
+	LowStockThreshold *int           `json:"low_stock_threshold,omitempty" db:"low_stock_threshold"` // Reorder point; the global threshold applies when unset
+	CreatedAt         time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at" db:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at,omitempty" db:"deleted_at" gorm:"index"`
+}
+
+// IsDeleted reports whether the product has been soft-deleted
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt.Valid
+}
+
+// StockThreshold returns the product's low stock threshold, or defaultThreshold when the product has none
+func (p *Product) StockThreshold(defaultThreshold int) int {
+	if p.LowStockThreshold != nil {
+		return *p.LowStockThreshold
+	}
+	return defaultThreshold
+}
+
+// IsLowStock reports whether the product is in stock but at or below its low stock threshold
+func (p *Product) IsLowStock(defaultThreshold int) bool {
+	return p.Stock > 0 && p.Stock <= p.StockThreshold(defaultThreshold)
 }
 
 // CreateProductRequest represents the request payload for creating a product
@@ -37,14 +61,16 @@ type UpdateProductRequest struct {
 // ToDTO converts a Product entity to a DTO-compatible struct
 func (p *Product) ToDTO() map[string]interface{} {
 	return map[string]interface{}{
-		"id":          p.ID,
-		"name":        p.Name,
-		"description": p.Description,
-		"price":       p.Price,
-		"stock":       p.Stock,
-		"category":    p.Category,
-		"created_at":  p.CreatedAt,
-		"updated_at":  p.UpdatedAt,
+		"id":                  p.ID,
+		"name":                p.Name,
+		"description":         p.Description,
+		"price":               p.Price,
+		"stock":               p.Stock,
+		"category":            p.Category,
+		"sku":                 p.SKU,
+		"low_stock_threshold": p.LowStockThreshold,
+		"created_at":          p.CreatedAt,
+		"updated_at":          p.UpdatedAt,
 	}
 }
 
@@ -84,9 +110,111 @@ type ProductStats struct {
 	OutOfStockProducts int64   `json:"out_of_stock_products"`
 }
 
+// productSortFields are the fields a product search can be ordered by
+var productSortFields = map[string]bool{
+	"id":         true,
+	"name":       true,
+	"price":      true,
+	"stock":      true,
+	"created_at": true,
+}
+
+// ProductSearch holds the optional filters, order and page of a product search.
+// Empty filters match every product.
+type ProductSearch struct {
+	Text        string // Full-text query matched against name and description; results are ranked by relevance unless Sort is set
+	Category    string
+	MinPrice    *float64
+	MaxPrice    *float64
+	InStockOnly bool
+	Sort        string // Field to order by, prefixed with "-" for descending; defaults to id
+	Limit       int
+	Offset      int
+}
+
+// ProductMatch is a product found by a search with its relevance to the search text.
+// Score is zero when the search has no text.
+type ProductMatch struct {
+	Product
+	Score float64 `json:"score" gorm:"column:score"`
+}
+
+// SortField returns the field the search is ordered by and whether the order is descending
+func (s ProductSearch) SortField() (string, bool) {
+	return ProductSortField(s.Sort)
+}
+
+// ValidSort reports whether the search is ordered by a sortable field
+func (s ProductSearch) ValidSort() bool {
+	return ValidProductSort(s.Sort)
+}
+
+// ProductSortField returns the field a product list sort orders by and whether the order is descending.
+// Sorts name a field, prefixed with "-" for descending; an empty sort orders by id.
+func ProductSortField(sort string) (string, bool) {
+	if sort == "" {
+		return "id", false
+	}
+	field := strings.TrimPrefix(sort, "-")
+	return field, field != sort
+}
+
+// ValidProductSort reports whether a product list sort orders by a sortable field
+func ValidProductSort(sort string) bool {
+	field, _ := ProductSortField(sort)
+	return productSortFields[field]
+}
+
+// ProductChangeType identifies how a product in the catalog changed
+type ProductChangeType string
+
+const (
+	ProductChangeCreated ProductChangeType = "created"
+	ProductChangeUpdated ProductChangeType = "updated"
+	ProductChangeDeleted ProductChangeType = "deleted"
+)
+
+// ProductChange is a single change to the product catalog, delivered to product watchers
+type ProductChange struct {
+	Type       ProductChangeType `json:"type"`
+	Product    Product           `json:"product"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
 // Category represents a product category
 type Category struct {
+	ID           int     `json:"id,omitempty"`
 	Name         string  `json:"name"`
+	Description  string  `json:"description,omitempty"`
 	ProductCount int64   `json:"product_count"`
 	AveragePrice float64 `json:"average_price"`
 }
+
+// ProductCategory represents a managed category stored in its own table
+type ProductCategory struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by ProductCategory
+func (ProductCategory) TableName() string {
+	return "categories"
+}
+
+// ProductPriceHistory records a single price change of a product
+type ProductPriceHistory struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	ProductID int       `json:"product_id" gorm:"index;not null"`
+	OldPrice  float64   `json:"old_price" gorm:"not null"`
+	NewPrice  float64   `json:"new_price" gorm:"not null"`
+	ChangedAt time.Time `json:"changed_at" gorm:"index;not null"`
+	ChangedBy string    `json:"changed_by"`
+}
+
+// TableName overrides the table name used by ProductPriceHistory
+func (ProductPriceHistory) TableName() string {
+	return "product_price_history"
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// Promotion is a time-boxed discount announced to users by a PromotionCreatedEvent once it starts
+type Promotion struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	Title       string     `json:"title" gorm:"not null"`
+	Description string     `json:"description"`
+	Discount    float64    `json:"discount" gorm:"not null"` // Percentage off
+	StartDate   time.Time  `json:"start_date" gorm:"index;not null"`
+	EndDate     time.Time  `json:"end_date" gorm:"not null"`
+	PublishedAt *time.Time `json:"published_at,omitempty" gorm:"index"` // When its PromotionCreatedEvent was published; nil while scheduled
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the table name used by Promotion
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// IsActive reports whether the promotion runs at t
+func (p *Promotion) IsActive(t time.Time) bool {
+	return !t.Before(p.StartDate) && t.Before(p.EndDate)
+}
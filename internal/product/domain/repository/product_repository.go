@@ -1,24 +1,57 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"obs-tools-usage/internal/product/domain/entity"
 )
 
 // ProductRepository defines the interface for product data access
 type ProductRepository interface {
-	GetAllProducts() ([]entity.Product, error)
-	GetProductByID(id int) (*entity.Product, error)
-	CreateProduct(product entity.Product) (*entity.Product, error)
-	UpdateProduct(product entity.Product) (*entity.Product, error)
-	DeleteProduct(id int) error
-	GetTopMostExpensive(limit int) ([]entity.Product, error)
-	GetLowStockProducts(maxStock int) ([]entity.Product, error)
-	GetProductsByCategory(category string) ([]entity.Product, error)
-	GetProductsByPriceRange(minPrice, maxPrice float64) ([]entity.Product, error)
-	GetProductsByName(name string) ([]entity.Product, error)
-	GetProductStats() (*entity.ProductStats, error)
-	GetCategories() ([]entity.Category, error)
-	GetProductsByStock(stock int) ([]entity.Product, error)
-	GetRandomProducts(count int) ([]entity.Product, error)
-	GetProductsByDateRange(startDate, endDate string) ([]entity.Product, error)
+	GetAllProducts(ctx context.Context) ([]entity.Product, error)
+	GetProductsPage(ctx context.Context, limit, offset int, sort string) ([]entity.Product, int64, error)
+	GetProductByID(ctx context.Context, id int) (*entity.Product, error)
+	GetProductByIDUnscoped(ctx context.Context, id int) (*entity.Product, error)
+	CreateProduct(ctx context.Context, product entity.Product) (*entity.Product, error)
+	UpdateProduct(ctx context.Context, product entity.Product, changedBy string) (*entity.Product, error)
+	UpsertProductBySKU(ctx context.Context, product entity.Product, changedBy string) (*entity.Product, bool, error)
+	AdjustStock(ctx context.Context, id int, delta int, eventID string) (*entity.Product, int, bool, error)
+	DeleteProduct(ctx context.Context, id int) error
+	HardDeleteProduct(ctx context.Context, id int) error
+	RestoreProduct(ctx context.Context, id int) (*entity.Product, error)
+	GetTopMostExpensive(ctx context.Context, limit int, category string) ([]entity.Product, error)
+	GetLowStockProducts(ctx context.Context, maxStock int) ([]entity.Product, error)
+	GetProductsAtLowStock(ctx context.Context) ([]entity.Product, error)
+	GetProductsByCategory(ctx context.Context, category string) ([]entity.Product, error)
+	GetProductsByPriceRange(ctx context.Context, minPrice, maxPrice float64) ([]entity.Product, error)
+	GetProductsByName(ctx context.Context, name string) ([]entity.Product, error)
+	SearchProducts(ctx context.Context, search entity.ProductSearch) ([]entity.ProductMatch, int64, error)
+	StreamProducts(ctx context.Context, category string, fn func(entity.Product) error) error
+	GetProductStats(ctx context.Context) (*entity.ProductStats, error)
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	GetProductsByStock(ctx context.Context, stock int) ([]entity.Product, error)
+	GetRandomProducts(ctx context.Context, count int) ([]entity.Product, error)
+	GetProductsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]entity.Product, error)
+
+	// Category management
+	CreateCategory(ctx context.Context, category entity.ProductCategory) (*entity.ProductCategory, error)
+	GetCategoryByName(ctx context.Context, name string) (*entity.ProductCategory, error)
+	UpdateCategory(ctx context.Context, category entity.ProductCategory) (*entity.ProductCategory, error)
+	RenameCategory(ctx context.Context, oldName, newName string) (*entity.ProductCategory, error)
+	DeleteCategory(ctx context.Context, name, reassignTo string) error
+
+	// Price history
+	GetPriceHistory(ctx context.Context, productID int, limit int) ([]entity.ProductPriceHistory, error)
+
+	// Promotions
+	CreatePromotion(ctx context.Context, promotion entity.Promotion) (*entity.Promotion, error)
+	GetPromotionByID(ctx context.Context, id int) (*entity.Promotion, error)
+	GetPromotionsPage(ctx context.Context, limit, offset int) ([]entity.Promotion, int64, error)
+	GetDuePromotions(ctx context.Context, now time.Time, limit int) ([]entity.Promotion, error)
+	GetNextPromotionStart(ctx context.Context, after time.Time) (*time.Time, error)
+	MarkPromotionPublished(ctx context.Context, id int, publishedAt time.Time) error
+
+	// Health check
+	Ping() error
 }
@@ -2,27 +2,136 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"obs-tools-usage/internal/product/domain/entity"
 )
 
+// ValidationRules holds the configurable product validation limits
+type ValidationRules struct {
+	AllowedCategories    []string // Categories a product may be in; empty allows any
+	MaxNameLength        int      // 0 disables the check
+	MaxDescriptionLength int      // 0 disables the check
+}
+
+// FieldError describes a single invalid product field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a product
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error joins the field errors into one message
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
 // ProductDomainService handles domain-specific business logic
-type ProductDomainService struct{}
+type ProductDomainService struct {
+	rules ValidationRules
+}
 
 // NewProductDomainService creates a new domain service
-func NewProductDomainService() *ProductDomainService {
-	return &ProductDomainService{}
+func NewProductDomainService(rules ValidationRules) *ProductDomainService {
+	return &ProductDomainService{rules: rules}
 }
 
-// ValidateProduct performs domain validation on product data
-func (s *ProductDomainService) ValidateProduct(product entity.Product) error {
-	if product.Name == "" {
-		return errors.New("product name cannot be empty")
+// Validate checks every product field and returns a *ValidationError listing all invalid ones
+func (s *ProductDomainService) Validate(product entity.Product) error {
+	var fields []FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, FieldError{Field: field, Message: message})
+	}
+
+	name := strings.TrimSpace(product.Name)
+	switch {
+	case name == "":
+		invalid("name", "is required")
+	case s.rules.MaxNameLength > 0 && utf8.RuneCountInString(name) > s.rules.MaxNameLength:
+		invalid("name", fmt.Sprintf("must be at most %d characters", s.rules.MaxNameLength))
 	}
-	if product.Price < 0 {
-		return errors.New("product price cannot be negative")
+	if s.rules.MaxDescriptionLength > 0 && utf8.RuneCountInString(product.Description) > s.rules.MaxDescriptionLength {
+		invalid("description", fmt.Sprintf("must be at most %d characters", s.rules.MaxDescriptionLength))
+	}
+	if product.Price <= 0 {
+		invalid("price", "must be greater than 0")
 	}
 	if product.Stock < 0 {
-		return errors.New("product stock cannot be negative")
+		invalid("stock", "cannot be negative")
+	}
+	if !s.isAllowedCategory(product.Category) {
+		invalid("category", "must be one of "+strings.Join(s.rules.AllowedCategories, ", "))
+	}
+	if product.LowStockThreshold != nil && *product.LowStockThreshold < 0 {
+		invalid("low_stock_threshold", "cannot be negative")
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// isAllowedCategory reports whether a product may be in category
+func (s *ProductDomainService) isAllowedCategory(category string) bool {
+	if len(s.rules.AllowedCategories) == 0 {
+		return true
+	}
+	for _, allowed := range s.rules.AllowedCategories {
+		if strings.EqualFold(strings.TrimSpace(allowed), category) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCategoryName performs domain validation on a category name
+func (s *ProductDomainService) ValidateCategoryName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("invalid category: name cannot be empty")
+	}
+	if len(name) > 100 {
+		return errors.New("invalid category: name cannot exceed 100 characters")
+	}
+	return nil
+}
+
+// ValidatePromotion checks every promotion field and returns a *ValidationError listing all invalid ones
+func (s *ProductDomainService) ValidatePromotion(promotion entity.Promotion) error {
+	var fields []FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, FieldError{Field: field, Message: message})
+	}
+
+	title := strings.TrimSpace(promotion.Title)
+	switch {
+	case title == "":
+		invalid("title", "is required")
+	case s.rules.MaxNameLength > 0 && utf8.RuneCountInString(title) > s.rules.MaxNameLength:
+		invalid("title", fmt.Sprintf("must be at most %d characters", s.rules.MaxNameLength))
+	}
+	if s.rules.MaxDescriptionLength > 0 && utf8.RuneCountInString(promotion.Description) > s.rules.MaxDescriptionLength {
+		invalid("description", fmt.Sprintf("must be at most %d characters", s.rules.MaxDescriptionLength))
+	}
+	if promotion.Discount <= 0 || promotion.Discount > 100 {
+		invalid("discount", "must be greater than 0 and at most 100")
+	}
+	if !promotion.EndDate.After(promotion.StartDate) {
+		invalid("end_date", "must be after start_date")
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
 	}
 	return nil
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"obs-tools-usage/internal/product/domain/entity"
+)
+
+func TestValidate(t *testing.T) {
+	rules := ValidationRules{
+		AllowedCategories:    []string{"Electronics", " Books "},
+		MaxNameLength:        10,
+		MaxDescriptionLength: 20,
+	}
+	negative := -1
+	zero := 0
+
+	tests := []struct {
+		name   string
+		modify func(*entity.Product)
+		fields []string // Invalid fields expected, in order; empty when the product is valid
+	}{
+		{name: "valid product", modify: func(p *entity.Product) {}},
+
+		{name: "empty name", modify: func(p *entity.Product) { p.Name = "" }, fields: []string{"name"}},
+		{name: "blank name", modify: func(p *entity.Product) { p.Name = "   " }, fields: []string{"name"}},
+		{name: "name over the maximum length", modify: func(p *entity.Product) { p.Name = "Laptop Pro X" }, fields: []string{"name"}},
+		{name: "name at the maximum length", modify: func(p *entity.Product) { p.Name = "Laptop Pro" }},
+		{name: "name length counts characters, not bytes", modify: func(p *entity.Product) { p.Name = "Ünïcödé ßß" }},
+
+		{name: "description over the maximum length", modify: func(p *entity.Product) { p.Description = strings.Repeat("a", 21) }, fields: []string{"description"}},
+		{name: "description at the maximum length", modify: func(p *entity.Product) { p.Description = strings.Repeat("a", 20) }},
+		{name: "empty description", modify: func(p *entity.Product) { p.Description = "" }},
+
+		{name: "zero price", modify: func(p *entity.Product) { p.Price = 0 }, fields: []string{"price"}},
+		{name: "negative price", modify: func(p *entity.Product) { p.Price = -5 }, fields: []string{"price"}},
+		{name: "smallest positive price", modify: func(p *entity.Product) { p.Price = 0.01 }},
+
+		{name: "negative stock", modify: func(p *entity.Product) { p.Stock = -1 }, fields: []string{"stock"}},
+		{name: "zero stock", modify: func(p *entity.Product) { p.Stock = 0 }},
+
+		{name: "category not allowed", modify: func(p *entity.Product) { p.Category = "Toys" }, fields: []string{"category"}},
+		{name: "empty category not allowed", modify: func(p *entity.Product) { p.Category = "" }, fields: []string{"category"}},
+		{name: "allowed category matches case-insensitively", modify: func(p *entity.Product) { p.Category = "electronics" }},
+		{name: "allowed category is trimmed", modify: func(p *entity.Product) { p.Category = "Books" }},
+
+		{name: "negative low stock threshold", modify: func(p *entity.Product) { p.LowStockThreshold = &negative }, fields: []string{"low_stock_threshold"}},
+		{name: "zero low stock threshold", modify: func(p *entity.Product) { p.LowStockThreshold = &zero }},
+
+		{
+			name: "every invalid field is reported",
+			modify: func(p *entity.Product) {
+				p.Name = ""
+				p.Price = 0
+				p.Stock = -3
+				p.Category = "Toys"
+			},
+			fields: []string{"name", "price", "stock", "category"},
+		},
+	}
+
+	s := NewProductDomainService(rules)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := entity.Product{
+				Name:        "Laptop",
+				Description: "A fast laptop",
+				Price:       999.99,
+				Stock:       5,
+				Category:    "Electronics",
+			}
+			tt.modify(&product)
+
+			err := s.Validate(product)
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error %v is not a *ValidationError", err)
+			}
+			if len(validationErr.Fields) != len(tt.fields) {
+				t.Fatalf("invalid fields = %+v, want %v", validationErr.Fields, tt.fields)
+			}
+			for i, field := range tt.fields {
+				if validationErr.Fields[i].Field != field {
+					t.Errorf("invalid field %d = %s, want %s", i, validationErr.Fields[i].Field, field)
+				}
+				if validationErr.Fields[i].Message == "" {
+					t.Errorf("invalid field %s has no message", field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateWithoutLimits(t *testing.T) {
+	s := NewProductDomainService(ValidationRules{})
+
+	product := entity.Product{
+		Name:        strings.Repeat("n", 500),
+		Description: strings.Repeat("d", 5000),
+		Price:       1,
+		Category:    "Anything",
+	}
+	if err := s.Validate(product); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/internal/product/infrastructure/config"
+	"obs-tools-usage/internal/product/infrastructure/external"
+)
+
+// ProductListCache caches lists of products by key. TTLCache keeps them in memory per replica;
+// RedisProductListCache shares them, and their invalidations, between replicas.
+type ProductListCache interface {
+	Enabled() bool
+	Get(key string) ([]entity.Product, bool)
+	Generation() uint64
+	SetIfGeneration(key string, products []entity.Product, generation uint64)
+	Delete(key string)
+	Clear()
+}
+
+// NewCategoryCache creates the cache of product listings by category on the configured backend.
+// The redis backend fails when Redis cannot be reached.
+func NewCategoryCache(cfg config.CacheConfig, metrics *external.Metrics, logger *logrus.Logger) (ProductListCache, error) {
+	switch cfg.CategoryBackend {
+	case "", config.CacheBackendMemory:
+		return NewTTLCache[string, []entity.Product]("category", cfg.CategoryTTL, metrics), nil
+	case config.CacheBackendRedis:
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to connect to category cache redis at %s: %w", cfg.RedisAddr, err)
+		}
+		return NewRedisProductListCache(client, "product:category:", "category", cfg.CategoryTTL, metrics, logger), nil
+	default:
+		return nil, fmt.Errorf("invalid category cache backend %q: must be %s or %s", cfg.CategoryBackend, config.CacheBackendMemory, config.CacheBackendRedis)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/internal/product/infrastructure/external"
+)
+
+// redisCacheTimeout bounds every Redis round trip so a slow cache never holds up a read for long
+const redisCacheTimeout = 200 * time.Millisecond
+
+// RedisProductListCache caches product lists as JSON in Redis, shared by every replica.
+// Redis errors are logged and treated as misses, so reads fall back to the database.
+// The generation only guards loads racing with invalidations on the same replica; loads racing
+// with another replica's invalidation are bounded by the TTL.
+type RedisProductListCache struct {
+	client  *redis.Client
+	prefix  string
+	name    string
+	ttl     time.Duration
+	metrics *external.Metrics
+	logger  *logrus.Logger
+
+	generation atomic.Uint64
+}
+
+// NewRedisProductListCache creates a cache storing lists under keys starting with prefix; name labels its hit and miss metrics
+func NewRedisProductListCache(client *redis.Client, prefix, name string, ttl time.Duration, metrics *external.Metrics, logger *logrus.Logger) *RedisProductListCache {
+	return &RedisProductListCache{
+		client:  client,
+		prefix:  prefix,
+		name:    name,
+		ttl:     ttl,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+// Enabled reports whether the cache stores entries
+func (c *RedisProductListCache) Enabled() bool {
+	return c.ttl > 0
+}
+
+// Get returns a cached list if present and not expired
+func (c *RedisProductListCache) Get(key string) ([]entity.Product, bool) {
+	if !c.Enabled() {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.logger.WithError(err).WithField("key", key).Warn("Failed to read product list cache")
+		}
+		c.metrics.RecordCacheMiss(c.name)
+		return nil, false
+	}
+
+	var products []entity.Product
+	if err := json.Unmarshal(data, &products); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to decode cached product list")
+		c.metrics.RecordCacheMiss(c.name)
+		return nil, false
+	}
+
+	c.metrics.RecordCacheHit(c.name)
+	return products, true
+}
+
+// Generation returns a token that changes whenever this replica invalidates an entry
+func (c *RedisProductListCache) Generation() uint64 {
+	return c.generation.Load()
+}
+
+// SetIfGeneration stores a list unless this replica invalidated the cache since generation was read
+func (c *RedisProductListCache) SetIfGeneration(key string, products []entity.Product, generation uint64) {
+	if !c.Enabled() || c.generation.Load() != generation {
+		return
+	}
+
+	data, err := json.Marshal(products)
+	if err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to encode product list for the cache")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+	if err := c.client.Set(ctx, c.prefix+key, data, c.ttl).Err(); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to write product list cache")
+	}
+}
+
+// Delete invalidates a single entry
+func (c *RedisProductListCache) Delete(key string) {
+	c.generation.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCacheTimeout)
+	defer cancel()
+	if err := c.client.Del(ctx, c.prefix+key).Err(); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to invalidate product list cache")
+	}
+}
+
+// Clear invalidates every entry under the cache's prefix
+func (c *RedisProductListCache) Clear() {
+	c.generation.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*redisCacheTimeout)
+	defer cancel()
+
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		c.logger.WithError(err).Warn("Failed to list product list cache entries")
+		return
+	}
+	if len(keys) == 0 {
+		return
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		c.logger.WithError(err).Warn("Failed to clear product list cache")
+	}
+}
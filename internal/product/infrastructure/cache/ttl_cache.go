@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"obs-tools-usage/internal/product/infrastructure/external"
+)
+
+// TTLCache is a small in-memory cache whose entries expire after a fixed TTL.
+// A zero TTL disables caching: Get always misses and Set is a no-op.
+type TTLCache[K comparable, V any] struct {
+	name    string
+	ttl     time.Duration
+	metrics *external.Metrics
+
+	mu         sync.RWMutex
+	entries    map[K]ttlEntry[V]
+	generation uint64
+}
+
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// NewTTLCache creates a cache; name labels its hit and miss metrics
+func NewTTLCache[K comparable, V any](name string, ttl time.Duration, metrics *external.Metrics) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		name:    name,
+		ttl:     ttl,
+		metrics: metrics,
+		entries: make(map[K]ttlEntry[V]),
+	}
+}
+
+// Enabled reports whether the cache stores entries
+func (c *TTLCache[K, V]) Enabled() bool {
+	return c.ttl > 0
+}
+
+// Get returns a cached value if present and not expired
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if !c.Enabled() {
+		return zero, false
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		c.metrics.RecordCacheMiss(c.name)
+		return zero, false
+	}
+
+	c.metrics.RecordCacheHit(c.name)
+	return entry.value, true
+}
+
+// Generation returns a token that changes whenever an entry is invalidated.
+// Pass it to SetIfGeneration so a load that raced with an invalidation is not cached.
+func (c *TTLCache[K, V]) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// SetIfGeneration stores a value unless the cache was invalidated since generation was read
+func (c *TTLCache[K, V]) SetIfGeneration(key K, value V, generation uint64) {
+	if !c.Enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+	c.entries[key] = ttlEntry[V]{value: value, expiresAt: time.Now().Add(c.ttl)}
+	c.evictExpiredLocked()
+}
+
+// Delete invalidates a single entry
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	c.generation++
+}
+
+// Clear invalidates every entry
+func (c *TTLCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[K]ttlEntry[V])
+	c.generation++
+}
+
+// evictExpiredLocked drops expired entries once the map grows, keeping memory bounded by the TTL window
+func (c *TTLCache[K, V]) evictExpiredLocked() {
+	if len(c.entries) < 1024 {
+		return
+	}
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -3,19 +3,55 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"obs-tools-usage/pkg/grpctls"
 )
 
 // Config holds the configuration for the product service
 type Config struct {
 	Port        string
+	GRPCPort    string
 	Environment string
+	MaxBodySize int64  // Maximum accepted HTTP request body size in bytes
+	GzipMinSize int    // Smallest response body in bytes compressed for clients that accept gzip
+	MaxPageSize int    // Largest limit accepted by list endpoints
+	APIPrefix   string // Path the versioned API is mounted under; unversioned paths are deprecated aliases
 	LogLevel    string
 	LogFormat   string
 	LogOutput   string
 	LogDir      string
 	LogFile     string
+	LogSampling LogSamplingConfig
 	LogRotation LogRotationConfig
 	Database    DatabaseConfig
+	Metrics     MetricsConfig
+	Tracing     TracingConfig
+	GRPCTLS     grpctls.Config
+	GRPCLimits  GRPCLimitsConfig
+	Cache       CacheConfig
+	HTTP        HTTPConfig
+	Kafka       KafkaConfig
+	Stock       StockConfig
+	Validation  ValidationConfig
+	Promotion   PromotionConfig
+
+	// EnableGRPCReflection registers the gRPC reflection service, which lets developers
+	// introspect and call the gRPC API with tools like grpcurl without the .proto files
+	EnableGRPCReflection bool
+
+	// AdminToken is the bearer token required by admin routes; empty disables them
+	AdminToken string
+
+	// SeedEndpointEnabled registers POST /admin/seed in development; off unless explicitly enabled
+	SeedEndpointEnabled bool
+}
+
+// HTTPConfig holds gin engine configuration
+type HTTPConfig struct {
+	Mode           string   // gin mode: debug or release
+	TrustedProxies []string // IPs or CIDRs of proxies, such as the gateway, whose X-Forwarded-For is trusted
 }
 
 // DatabaseConfig holds database configuration
@@ -26,6 +62,88 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// Connection pool
+	MaxOpenConns    int           // Upper bound on open connections; 0 means unlimited
+	MaxIdleConns    int           // Connections kept open while idle
+	ConnMaxLifetime time.Duration // Connections older than this are closed and replaced
+
+	Seed bool // Insert sample data at startup; rows that already exist are skipped
+}
+
+// MetricsConfig holds metrics collection configuration
+type MetricsConfig struct {
+	ScrapeInterval time.Duration // How often runtime and DB pool gauges are refreshed
+}
+
+// LogSamplingConfig holds sampling of high-volume Info logs
+type LogSamplingConfig struct {
+	Rate   int  // Keep 1 in Rate routine "operation completed" logs; 1 keeps all
+	Demote bool // Log them at Debug instead of sampling
+}
+
+// Backends of the category listing cache
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+)
+
+// CacheConfig holds read cache configuration
+type CacheConfig struct {
+	ProductTTL           time.Duration // How long GetProductByID results are cached; 0 disables the cache
+	CategoryTTL          time.Duration // How long GetProductsByCategory results are cached; 0 disables the cache
+	CategoryBackend      string        // Where category listings are cached: memory, per replica, or redis, shared by every replica
+	RedisAddr            string        // Redis address used by the redis backend
+	RedisPassword        string
+	RedisDB              int
+	StatsRefreshInterval time.Duration // How often the /products/stats snapshot is recomputed
+}
+
+// KafkaConfig holds the stock event consumer and publisher configuration
+type KafkaConfig struct {
+	Enabled         bool
+	Brokers         []string
+	ConsumerGroup   string
+	MaxRetries      int           // Retries per message before it is skipped or dead-lettered
+	RetryBackoff    time.Duration // Delay before the first retry, doubled on each attempt
+	MaxRetryBackoff time.Duration
+	DeadLetterTopic string // Empty disables dead-lettering
+}
+
+// StockConfig holds stock level alerting configuration
+type StockConfig struct {
+	LowStockThreshold int // A StockLowEvent is emitted when stock drops to or below this level
+}
+
+// PromotionConfig controls the scheduler that publishes promotions once they start
+type PromotionConfig struct {
+	PollInterval time.Duration // Longest the scheduler sleeps before checking for promotions stored by other replicas
+	BatchSize    int           // Due promotions loaded per query
+}
+
+// ValidationConfig holds the limits products are validated against on create and update
+type ValidationConfig struct {
+	AllowedCategories    []string // Empty allows any category
+	MaxNameLength        int
+	MaxDescriptionLength int
+}
+
+// GRPCLimitsConfig holds the limits the gRPC server enforces on clients; zero keeps gRPC's default
+type GRPCLimitsConfig struct {
+	MaxConcurrentStreams int           // Streams a single connection may have open at once
+	MaxRecvMsgSize       int           // Largest message in bytes the server accepts
+	KeepaliveTime        time.Duration // Idle time after which the server pings the client
+	KeepaliveTimeout     time.Duration // How long a ping may go unanswered before the connection is closed
+	MaxConnectionIdle    time.Duration // Idle time after which a connection without streams is closed
+	MinPingInterval      time.Duration // Clients pinging more often than this are disconnected
+	PermitWithoutStream  bool          // Allow client pings on connections without active streams
+}
+
+// TracingConfig holds OpenTelemetry trace export configuration
+type TracingConfig struct {
+	Enabled  bool
+	Endpoint string // OTLP gRPC collector address
+	Insecure bool
 }
 
 // LogRotationConfig holds log rotation configuration
@@ -43,12 +161,21 @@ func LoadConfig() *Config {
 	
 	return &Config{
 		Port:        getEnv("PORT", "8080"),
+		GRPCPort:    getEnv("GRPC_PORT", "50050"),
 		Environment: environment,
+		MaxBodySize: int64(getEnvAsInt("MAX_REQUEST_BODY_BYTES", 1<<20)),
+		GzipMinSize: getEnvAsInt("GZIP_MIN_SIZE", 1024),
+		MaxPageSize: getEnvAsInt("MAX_PAGE_SIZE", 100),
+		APIPrefix:   getEnv("API_PREFIX", "/v1"),
 		LogLevel:    getLogLevelFromEnv(environment),
 		LogFormat:   getLogFormatFromEnv(environment),
 		LogOutput:   getLogOutputFromEnv(environment),
 		LogDir:      getEnv("LOG_DIR", "./logs"),
 		LogFile:     getEnv("LOG_FILE", "product-service.log"),
+		LogSampling: LogSamplingConfig{
+			Rate:   getEnvAsInt("LOG_SAMPLE_RATE", 1),
+			Demote: getEnvAsBool("LOG_SAMPLE_DEMOTE", false),
+		},
 		LogRotation: LogRotationConfig{
 			Enabled:    true,
 			MaxSize:    100,
@@ -63,7 +190,75 @@ func LoadConfig() *Config {
 			Password: getEnv("DB_PASSWORD", ""),
 			DBName:   getEnv("DB_NAME", "obs_tools"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", time.Hour),
+
+			Seed: getEnvAsBool("DB_SEED", environment == "development"),
+		},
+		Metrics: MetricsConfig{
+			ScrapeInterval: getEnvAsDuration("METRICS_SCRAPE_INTERVAL", 5*time.Second),
+		},
+		Tracing: TracingConfig{
+			Enabled:  getEnvAsBool("TRACING_ENABLED", false),
+			Endpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			Insecure: getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		},
+		GRPCTLS: grpctls.Config{
+			// Only development falls back to insecure connections by default
+			Enabled:    getEnvAsBool("GRPC_TLS_ENABLED", environment != "development"),
+			CertFile:   getEnv("GRPC_TLS_CERT_FILE", ""),
+			KeyFile:    getEnv("GRPC_TLS_KEY_FILE", ""),
+			CAFile:     getEnv("GRPC_TLS_CA_FILE", ""),
+			ServerName: getEnv("GRPC_TLS_SERVER_NAME", ""),
+		},
+		GRPCLimits: GRPCLimitsConfig{
+			MaxConcurrentStreams: getEnvAsInt("GRPC_MAX_CONCURRENT_STREAMS", 100),
+			MaxRecvMsgSize:       getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", 4<<20),
+			KeepaliveTime:        getEnvAsDuration("GRPC_KEEPALIVE_TIME", 2*time.Minute),
+			KeepaliveTimeout:     getEnvAsDuration("GRPC_KEEPALIVE_TIMEOUT", 20*time.Second),
+			MaxConnectionIdle:    getEnvAsDuration("GRPC_MAX_CONNECTION_IDLE", 15*time.Minute),
+			MinPingInterval:      getEnvAsDuration("GRPC_KEEPALIVE_MIN_PING_INTERVAL", 30*time.Second),
+			PermitWithoutStream:  getEnvAsBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", true),
+		},
+		Cache: CacheConfig{
+			ProductTTL:           getEnvAsDuration("PRODUCT_CACHE_TTL", 5*time.Second),
+			CategoryTTL:          getEnvAsDuration("CATEGORY_CACHE_TTL", 10*time.Second),
+			CategoryBackend:      getEnv("CATEGORY_CACHE_BACKEND", CacheBackendMemory),
+			RedisAddr:            getEnv("CACHE_REDIS_ADDR", "localhost:6379"),
+			RedisPassword:        getEnv("CACHE_REDIS_PASSWORD", ""),
+			RedisDB:              getEnvAsInt("CACHE_REDIS_DB", 0),
+			StatsRefreshInterval: getEnvAsDuration("PRODUCT_STATS_REFRESH_INTERVAL", 30*time.Second),
 		},
+		HTTP: HTTPConfig{
+			Mode:           getGinModeFromEnv(environment),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}),
+		},
+		Kafka: KafkaConfig{
+			Enabled:         getEnvAsBool("KAFKA_ENABLED", false),
+			Brokers:         getEnvAsSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
+			ConsumerGroup:   getEnv("KAFKA_CONSUMER_GROUP", "product-service"),
+			MaxRetries:      getEnvAsInt("KAFKA_CONSUMER_MAX_RETRIES", 3),
+			RetryBackoff:    getEnvAsDuration("KAFKA_CONSUMER_RETRY_BACKOFF", 500*time.Millisecond),
+			MaxRetryBackoff: getEnvAsDuration("KAFKA_CONSUMER_MAX_RETRY_BACKOFF", 10*time.Second),
+			DeadLetterTopic: getEnv("KAFKA_CONSUMER_DEAD_LETTER_TOPIC", ""),
+		},
+		Stock: StockConfig{
+			LowStockThreshold: getEnvAsInt("LOW_STOCK_THRESHOLD", 10),
+		},
+		Validation: ValidationConfig{
+			AllowedCategories:    getEnvAsSlice("PRODUCT_ALLOWED_CATEGORIES", nil),
+			MaxNameLength:        getEnvAsInt("PRODUCT_MAX_NAME_LENGTH", 200),
+			MaxDescriptionLength: getEnvAsInt("PRODUCT_MAX_DESCRIPTION_LENGTH", 2000),
+		},
+		Promotion: PromotionConfig{
+			PollInterval: getEnvAsDuration("PROMOTION_POLL_INTERVAL", time.Minute),
+			BatchSize:    getEnvAsInt("PROMOTION_BATCH_SIZE", 100),
+		},
+		EnableGRPCReflection: getEnvAsBool("ENABLE_GRPC_REFLECTION", environment != "production"),
+		AdminToken:           getEnv("ADMIN_TOKEN", ""),
+		SeedEndpointEnabled:  getEnvAsBool("SEED_ENDPOINT_ENABLED", false),
 	}
 }
 
@@ -76,6 +271,15 @@ func (c *Config) GetPort() int {
 	return port
 }
 
+// GetGRPCPort returns the gRPC port as an integer
+func (c *Config) GetGRPCPort() int {
+	port, err := strconv.Atoi(c.GRPCPort)
+	if err != nil {
+		return 50050
+	}
+	return port
+}
+
 // IsDevelopment returns true if environment is development
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -100,6 +304,66 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsInt gets an environment variable as integer with a default value
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsBool gets an environment variable as boolean with a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration gets an environment variable as a duration with a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice gets a comma-separated environment variable as a string slice with a default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// getGinModeFromEnv determines the gin mode, defaulting to release mode in production
+func getGinModeFromEnv(environment string) string {
+	if mode := os.Getenv("GIN_MODE"); mode != "" {
+		return mode
+	}
+	if environment == "production" {
+		return "release"
+	}
+	return "debug"
+}
+
 // getLogLevelFromEnv determines log level from environment
 func getLogLevelFromEnv(environment string) string {
 	// First check LOG_LEVEL environment variable
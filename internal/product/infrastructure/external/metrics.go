@@ -1,8 +1,11 @@
 package external
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,242 +15,430 @@ import (
 	"obs-tools-usage/internal/product/domain/entity"
 )
 
-// Prometheus metrics
-var (
+// Metrics holds the product service's Prometheus metrics. Each instance registers its
+// collectors with its own registry, so tests can build one per prometheus.NewRegistry
+// without tripping duplicate registration panics.
+type Metrics struct {
 	// HTTP metrics
-	httpRequestsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_requests_total",
-			Help: "Total number of HTTP requests",
-		},
-		[]string{"method", "endpoint", "status_code"},
-	)
-
-	httpRequestDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_duration_seconds",
-			Help:    "HTTP request duration in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "endpoint"},
-	)
-
-	httpRequestSize = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_size_bytes",
-			Help:    "HTTP request size in bytes",
-			Buckets: prometheus.ExponentialBuckets(100, 10, 8),
-		},
-		[]string{"method", "endpoint"},
-	)
-
-	httpResponseSize = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_response_size_bytes",
-			Help:    "HTTP response size in bytes",
-			Buckets: prometheus.ExponentialBuckets(100, 10, 8),
-		},
-		[]string{"method", "endpoint"},
-	)
+	httpRequestsTotal   *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	httpRequestSize     *prometheus.HistogramVec
+	httpResponseSize    *prometheus.HistogramVec
 
 	// Business metrics
-	productsTotal = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "products_total",
-			Help: "Total number of products",
-		},
-	)
-
-	productsByCategory = promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "products_by_category_total",
-			Help: "Total number of products by category",
-		},
-		[]string{"category"},
-	)
-
-	productsLowStock = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "products_low_stock_total",
-			Help: "Total number of products with low stock",
-		},
-	)
-
-	productsOutOfStock = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "products_out_of_stock_total",
-			Help: "Total number of products out of stock",
-		},
-	)
-
-	productsHighValue = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "products_high_value_total",
-			Help: "Total number of high-value products (>1000)",
-		},
-	)
-
-	averageProductPrice = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "average_product_price",
-			Help: "Average product price",
-		},
-	)
-
-	totalInventoryValue = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "total_inventory_value",
-			Help: "Total inventory value (price * stock)",
-		},
-	)
-
-	productsCreatedTotal = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "products_created_total",
-			Help: "Total number of products created",
-		},
-	)
-
-	productsUpdatedTotal = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "products_updated_total",
-			Help: "Total number of products updated",
-		},
-	)
-
-	productsDeletedTotal = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "products_deleted_total",
-			Help: "Total number of products deleted",
-		},
-	)
+	productsTotal        prometheus.Gauge
+	productsByCategory   *prometheus.GaugeVec
+	productsLowStock     prometheus.Gauge
+	productsOutOfStock   prometheus.Gauge
+	productsHighValue    prometheus.Gauge
+	averageProductPrice  prometheus.Gauge
+	totalInventoryValue  prometheus.Gauge
+	productsCreatedTotal prometheus.Counter
+	productsUpdatedTotal prometheus.Counter
+	productsDeletedTotal prometheus.Counter
 
 	// Stock level metrics
-	stockLevels = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "product_stock_levels",
-			Help:    "Distribution of product stock levels",
-			Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
-		},
-		[]string{"category"},
-	)
+	stockLevels *prometheus.HistogramVec
 
 	// Price range metrics
-	priceRanges = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "product_price_ranges",
-			Help:    "Distribution of product prices",
-			Buckets: []float64{0, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
-		},
-		[]string{"category"},
-	)
+	priceRanges *prometheus.HistogramVec
 
 	// System metrics
-	memoryAllocBytes = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "memory_alloc_bytes",
-			Help: "Current memory allocation in bytes",
-		},
-	)
+	memoryAllocBytes prometheus.Gauge
+	memorySysBytes   prometheus.Gauge
+	memoryHeapBytes  prometheus.Gauge
+	memoryStackBytes prometheus.Gauge
+	gcDuration       prometheus.Histogram
+	gcCount          prometheus.Counter
+	goroutinesTotal  prometheus.Gauge
+	cgoCalls         prometheus.Counter
+	threadsTotal     prometheus.Gauge
 
-	memorySysBytes = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "memory_sys_bytes",
-			Help: "Total memory obtained from OS in bytes",
-		},
-	)
+	// CPU metrics (approximated)
+	cpuUsagePercent prometheus.Gauge
 
-	memoryHeapBytes = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "memory_heap_bytes",
-			Help: "Heap memory size in bytes",
-		},
-	)
+	// Application metrics
+	httpConnections  prometheus.Gauge
+	requestQueueSize prometheus.Gauge
 
-	memoryStackBytes = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "memory_stack_bytes",
-			Help: "Stack memory size in bytes",
-		},
-	)
+	// Database metrics
+	databaseOperationsTotal   *prometheus.CounterVec
+	databaseOperationDuration *prometheus.HistogramVec
 
-	gcDuration = promauto.NewHistogram(
-		prometheus.HistogramOpts{
-			Name:    "gc_duration_seconds",
-			Help:    "GC duration in seconds",
-			Buckets: prometheus.ExponentialBuckets(0.0001, 2, 15),
-		},
-	)
+	// Cache metrics
+	cacheHitsTotal   *prometheus.CounterVec
+	cacheMissesTotal *prometheus.CounterVec
 
-	gcCount = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "gc_count_total",
-			Help: "Total number of GC cycles",
-		},
-	)
+	// Category listing cache metrics
+	categoryCacheHitsTotal   *prometheus.CounterVec
+	categoryCacheMissesTotal *prometheus.CounterVec
 
-	goroutinesTotal = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "goroutines_total",
-			Help: "Current number of goroutines",
-		},
-	)
+	// Database connection pool metrics
+	dbPoolOpenConnections  prometheus.Gauge
+	dbPoolInUseConnections prometheus.Gauge
+	dbPoolIdleConnections  prometheus.Gauge
+	dbPoolWaitCount        prometheus.Gauge
 
-	cgoCalls = promauto.NewCounter(
-		prometheus.CounterOpts{
-			Name: "cgo_calls_total",
-			Help: "Total number of CGO calls",
-		},
-	)
+	// Product watch stream metrics
+	productWatchSubscribers  prometheus.Gauge
+	productWatchDroppedTotal prometheus.Counter
 
-	threadsTotal = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "threads_total",
-			Help: "Current number of OS threads",
-		},
-	)
+	// Product stats cache metrics
+	productStatsRecomputeTotal *prometheus.CounterVec
+	productStatsCoalescedTotal prometheus.Counter
+	productStatsRefreshedAt    atomic.Int64 // Unix nanoseconds of the served snapshot; zero before the first
 
-	// CPU metrics (approximated)
-	cpuUsagePercent = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "cpu_usage_percent",
-			Help: "CPU usage percentage (approximated)",
-		},
-	)
+	// Runtime counters remembered between system metric updates
+	lastGCCount  uint32
+	lastCGOCalls uint64
+	lastCPUTime  time.Time
+	lastGCPause  time.Duration
+}
 
-	// Application metrics
-	httpConnections = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "http_connections_active",
-			Help: "Number of active HTTP connections",
-		},
-	)
+// NewMetrics creates the product service metrics and registers them with registry
+func NewMetrics(registry *prometheus.Registry) *Metrics {
+	factory := promauto.With(registry)
+
+	m := &Metrics{
+		// HTTP metrics
+		httpRequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_total",
+				Help: "Total number of HTTP requests",
+			},
+			[]string{"method", "endpoint", "status_code"},
+		),
+
+		httpRequestDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "HTTP request duration in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method", "endpoint"},
+		),
+
+		httpRequestSize: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_size_bytes",
+				Help:    "HTTP request size in bytes",
+				Buckets: prometheus.ExponentialBuckets(100, 10, 8),
+			},
+			[]string{"method", "endpoint"},
+		),
+
+		httpResponseSize: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_response_size_bytes",
+				Help:    "HTTP response size in bytes",
+				Buckets: prometheus.ExponentialBuckets(100, 10, 8),
+			},
+			[]string{"method", "endpoint"},
+		),
+
+		// Business metrics
+		productsTotal: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "products_total",
+				Help: "Total number of products",
+			},
+		),
+
+		productsByCategory: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "products_by_category_total",
+				Help: "Total number of products by category",
+			},
+			[]string{"category"},
+		),
+
+		productsLowStock: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "products_low_stock_total",
+				Help: "Total number of products with low stock",
+			},
+		),
+
+		productsOutOfStock: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "products_out_of_stock_total",
+				Help: "Total number of products out of stock",
+			},
+		),
+
+		productsHighValue: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "products_high_value_total",
+				Help: "Total number of high-value products (>1000)",
+			},
+		),
+
+		averageProductPrice: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "average_product_price",
+				Help: "Average product price",
+			},
+		),
+
+		totalInventoryValue: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "total_inventory_value",
+				Help: "Total inventory value (price * stock)",
+			},
+		),
+
+		productsCreatedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "products_created_total",
+				Help: "Total number of products created",
+			},
+		),
+
+		productsUpdatedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "products_updated_total",
+				Help: "Total number of products updated",
+			},
+		),
+
+		productsDeletedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "products_deleted_total",
+				Help: "Total number of products deleted",
+			},
+		),
+
+		// Stock level metrics
+		stockLevels: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "product_stock_levels",
+				Help:    "Distribution of product stock levels",
+				Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+			},
+			[]string{"category"},
+		),
+
+		// Price range metrics
+		priceRanges: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "product_price_ranges",
+				Help:    "Distribution of product prices",
+				Buckets: []float64{0, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
+			},
+			[]string{"category"},
+		),
+
+		// System metrics
+		memoryAllocBytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "memory_alloc_bytes",
+				Help: "Current memory allocation in bytes",
+			},
+		),
+
+		memorySysBytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "memory_sys_bytes",
+				Help: "Total memory obtained from OS in bytes",
+			},
+		),
+
+		memoryHeapBytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "memory_heap_bytes",
+				Help: "Heap memory size in bytes",
+			},
+		),
+
+		memoryStackBytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "memory_stack_bytes",
+				Help: "Stack memory size in bytes",
+			},
+		),
+
+		gcDuration: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "gc_duration_seconds",
+				Help:    "GC duration in seconds",
+				Buckets: prometheus.ExponentialBuckets(0.0001, 2, 15),
+			},
+		),
+
+		gcCount: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "gc_count_total",
+				Help: "Total number of GC cycles",
+			},
+		),
+
+		goroutinesTotal: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "goroutines_total",
+				Help: "Current number of goroutines",
+			},
+		),
+
+		cgoCalls: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "cgo_calls_total",
+				Help: "Total number of CGO calls",
+			},
+		),
+
+		threadsTotal: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "threads_total",
+				Help: "Current number of OS threads",
+			},
+		),
+
+		// CPU metrics (approximated)
+		cpuUsagePercent: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "cpu_usage_percent",
+				Help: "CPU usage percentage (approximated)",
+			},
+		),
+
+		// Application metrics
+		httpConnections: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "http_connections_active",
+				Help: "Number of active HTTP connections",
+			},
+		),
+
+		requestQueueSize: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "request_queue_size",
+				Help: "Current request queue size",
+			},
+		),
+
+		// Database metrics
+		databaseOperationsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "database_operations_total",
+				Help: "Total number of database operations",
+			},
+			[]string{"operation", "status"},
+		),
+
+		databaseOperationDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "database_operation_duration_seconds",
+				Help:    "Database operation duration in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"operation"},
+		),
+
+		// Cache metrics
+		cacheHitsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_hits_total",
+				Help: "Total number of in-memory cache hits",
+			},
+			[]string{"cache"},
+		),
+
+		cacheMissesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cache_misses_total",
+				Help: "Total number of in-memory cache misses",
+			},
+			[]string{"cache"},
+		),
+
+		// Category listing cache metrics
+		categoryCacheHitsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "category_cache_hits_total",
+				Help: "Total number of product category listings served from the cache",
+			},
+			[]string{"category"},
+		),
+
+		categoryCacheMissesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "category_cache_misses_total",
+				Help: "Total number of product category listings loaded from the database",
+			},
+			[]string{"category"},
+		),
+
+		// Database connection pool metrics
+		dbPoolOpenConnections: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_pool_open_connections",
+				Help: "Number of established database connections, both in use and idle",
+			},
+		),
+
+		dbPoolInUseConnections: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_pool_in_use_connections",
+				Help: "Number of database connections currently in use",
+			},
+		),
+
+		dbPoolIdleConnections: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_pool_idle_connections",
+				Help: "Number of idle database connections",
+			},
+		),
+
+		dbPoolWaitCount: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_pool_wait_count",
+				Help: "Total number of times a caller waited for a database connection",
+			},
+		),
+
+		// Product watch stream metrics
+		productWatchSubscribers: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "product_watch_subscribers",
+				Help: "Number of clients currently watching product catalog changes",
+			},
+		),
+
+		productWatchDroppedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "product_watch_dropped_total",
+				Help: "Total number of product watchers dropped for falling behind",
+			},
+		),
+
+		// Product stats cache metrics
+		productStatsRecomputeTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "product_stats_recompute_total",
+				Help: "Total number of product statistics recomputes by trigger",
+			},
+			[]string{"trigger"},
+		),
+
+		productStatsCoalescedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "product_stats_coalesced_total",
+				Help: "Total number of product statistics requests served by another request's recompute",
+			},
+		),
+	}
 
-	requestQueueSize = promauto.NewGauge(
+	factory.NewGaugeFunc(
 		prometheus.GaugeOpts{
-			Name: "request_queue_size",
-			Help: "Current request queue size",
+			Name: "product_stats_cache_age_seconds",
+			Help: "Age of the cached product statistics snapshot, zero until the first is computed",
 		},
+		m.productStatsCacheAge,
 	)
 
-	// Database metrics
-	databaseOperationsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "database_operations_total",
-			Help: "Total number of database operations",
-		},
-		[]string{"operation", "status"},
-	)
-
-	databaseOperationDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "database_operation_duration_seconds",
-			Help:    "Database operation duration in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"operation"},
-	)
-)
+	return m
+}
 
 // PerformanceMetrics holds performance-related metrics
 type PerformanceMetrics struct {
@@ -349,50 +540,101 @@ func LogSlowQueries(logger *logrus.Entry, operation string, duration time.Durati
 // Prometheus metrics functions
 
 // RecordHTTPRequest records HTTP request metrics
-func RecordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration, requestSize, responseSize int) {
+func (m *Metrics) RecordHTTPRequest(method, endpoint string, statusCode int, duration time.Duration, requestSize, responseSize int) {
 	statusCodeStr := fmt.Sprintf("%d", statusCode)
 	
-	httpRequestsTotal.WithLabelValues(method, endpoint, statusCodeStr).Inc()
-	httpRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
-	httpRequestSize.WithLabelValues(method, endpoint).Observe(float64(requestSize))
-	httpResponseSize.WithLabelValues(method, endpoint).Observe(float64(responseSize))
+	m.httpRequestsTotal.WithLabelValues(method, endpoint, statusCodeStr).Inc()
+	m.httpRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+	m.httpRequestSize.WithLabelValues(method, endpoint).Observe(float64(requestSize))
+	m.httpResponseSize.WithLabelValues(method, endpoint).Observe(float64(responseSize))
 }
 
 // RecordDatabaseOperation records database operation metrics
-func RecordDatabaseOperation(operation, status string, duration time.Duration) {
-	databaseOperationsTotal.WithLabelValues(operation, status).Inc()
-	databaseOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
+func (m *Metrics) RecordDatabaseOperation(operation, status string, duration time.Duration) {
+	m.databaseOperationsTotal.WithLabelValues(operation, status).Inc()
+	m.databaseOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// RecordCacheHit records an in-memory cache hit
+func (m *Metrics) RecordCacheHit(cache string) {
+	m.cacheHitsTotal.WithLabelValues(cache).Inc()
+}
+
+// RecordCacheMiss records an in-memory cache miss
+func (m *Metrics) RecordCacheMiss(cache string) {
+	m.cacheMissesTotal.WithLabelValues(cache).Inc()
+}
+
+// RecordCategoryCacheHit records a category listing served from the cache
+func (m *Metrics) RecordCategoryCacheHit(category string) {
+	m.categoryCacheHitsTotal.WithLabelValues(category).Inc()
+}
+
+// RecordCategoryCacheMiss records a category listing loaded from the database
+func (m *Metrics) RecordCategoryCacheMiss(category string) {
+	m.categoryCacheMissesTotal.WithLabelValues(category).Inc()
+}
+
+// SetProductWatchSubscribers records the number of active product watchers
+func (m *Metrics) SetProductWatchSubscribers(count int) {
+	m.productWatchSubscribers.Set(float64(count))
+}
+
+// RecordProductWatchDropped records a product watcher dropped for falling behind
+func (m *Metrics) RecordProductWatchDropped() {
+	m.productWatchDroppedTotal.Inc()
+}
+
+// RecordProductStatsRecompute records a product statistics recompute and when its snapshot was taken
+func (m *Metrics) RecordProductStatsRecompute(trigger string, refreshedAt time.Time) {
+	m.productStatsRecomputeTotal.WithLabelValues(trigger).Inc()
+	m.productStatsRefreshedAt.Store(refreshedAt.UnixNano())
+}
+
+// RecordProductStatsCoalesced records a request that waited on a recompute already in flight
+func (m *Metrics) RecordProductStatsCoalesced() {
+	m.productStatsCoalescedTotal.Inc()
+}
+
+// productStatsCacheAge reports the age in seconds of the cached product statistics snapshot
+func (m *Metrics) productStatsCacheAge() float64 {
+	refreshedAt := m.productStatsRefreshedAt.Load()
+	if refreshedAt == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, refreshedAt)).Seconds()
 }
 
 // RecordProductCreated records product creation metric
-func RecordProductCreated() {
-	productsCreatedTotal.Inc()
-	UpdateProductsTotal()
+func (m *Metrics) RecordProductCreated() {
+	m.productsCreatedTotal.Inc()
+	m.UpdateProductsTotal()
 }
 
 // RecordProductUpdated records product update metric
-func RecordProductUpdated() {
-	productsUpdatedTotal.Inc()
-	UpdateProductsTotal()
+func (m *Metrics) RecordProductUpdated() {
+	m.productsUpdatedTotal.Inc()
+	m.UpdateProductsTotal()
 }
 
 // RecordProductDeleted records product deletion metric
-func RecordProductDeleted() {
-	productsDeletedTotal.Inc()
-	UpdateProductsTotal()
+func (m *Metrics) RecordProductDeleted() {
+	m.productsDeletedTotal.Inc()
+	m.UpdateProductsTotal()
 }
 
 // UpdateProductsTotal updates the total products count
-func UpdateProductsTotal() {
+func (m *Metrics) UpdateProductsTotal() {
 	// This would typically query the database for actual count
 	// For now, we'll use a simple counter approach
 	// In a real implementation, you'd query the repository
 }
 
-// UpdateBusinessMetrics updates all business metrics
-func UpdateBusinessMetrics(products []entity.Product) {
+// UpdateBusinessMetrics updates all business metrics.
+// A product counts as low stock at or below its own threshold, or lowStockThreshold when it has none.
+func (m *Metrics) UpdateBusinessMetrics(products []entity.Product, lowStockThreshold int) {
 	// Reset category counters
-	productsByCategory.Reset()
+	m.productsByCategory.Reset()
 	
 	// Counters
 	totalCount := len(products)
@@ -412,7 +654,7 @@ func UpdateBusinessMetrics(products []entity.Product) {
 		// Stock level checks
 		if product.Stock == 0 {
 			outOfStockCount++
-		} else if product.Stock < 10 {
+		} else if product.IsLowStock(lowStockThreshold) {
 			lowStockCount++
 		}
 		
@@ -426,106 +668,130 @@ func UpdateBusinessMetrics(products []entity.Product) {
 		totalInventoryValueCalc += product.Price * float64(product.Stock)
 		
 		// Record stock level distribution
-		stockLevels.WithLabelValues(product.Category).Observe(float64(product.Stock))
+		m.stockLevels.WithLabelValues(product.Category).Observe(float64(product.Stock))
 		
 		// Record price distribution
-		priceRanges.WithLabelValues(product.Category).Observe(product.Price)
+		m.priceRanges.WithLabelValues(product.Category).Observe(product.Price)
 	}
 	
 	// Update gauges
-	productsTotal.Set(float64(totalCount))
-	productsLowStock.Set(float64(lowStockCount))
-	productsOutOfStock.Set(float64(outOfStockCount))
-	productsHighValue.Set(float64(highValueCount))
+	m.productsTotal.Set(float64(totalCount))
+	m.productsLowStock.Set(float64(lowStockCount))
+	m.productsOutOfStock.Set(float64(outOfStockCount))
+	m.productsHighValue.Set(float64(highValueCount))
 	
 	// Calculate and set average price
 	if totalCount > 0 {
-		averageProductPrice.Set(totalPrice / float64(totalCount))
+		m.averageProductPrice.Set(totalPrice / float64(totalCount))
 	} else {
-		averageProductPrice.Set(0)
+		m.averageProductPrice.Set(0)
 	}
 	
 	// Set total inventory value
-	totalInventoryValue.Set(totalInventoryValueCalc)
+	m.totalInventoryValue.Set(totalInventoryValueCalc)
 	
 	// Update category counters
 	for category, count := range categoryCounts {
-		productsByCategory.WithLabelValues(category).Set(float64(count))
+		m.productsByCategory.WithLabelValues(category).Set(float64(count))
 	}
 }
 
+// UpdateProductStatsMetrics syncs the inventory gauges with a product statistics snapshot.
+// The low stock gauge excludes out of stock products, matching UpdateBusinessMetrics.
+func (m *Metrics) UpdateProductStatsMetrics(stats entity.ProductStats) {
+	m.productsTotal.Set(float64(stats.TotalProducts))
+	m.productsLowStock.Set(float64(stats.LowStockProducts - stats.OutOfStockProducts))
+	m.productsOutOfStock.Set(float64(stats.OutOfStockProducts))
+	m.averageProductPrice.Set(stats.AveragePrice)
+	m.totalInventoryValue.Set(stats.TotalValue)
+}
+
 // RecordProductStockLevel records individual product stock level
-func RecordProductStockLevel(product entity.Product) {
-	stockLevels.WithLabelValues(product.Category).Observe(float64(product.Stock))
-	priceRanges.WithLabelValues(product.Category).Observe(product.Price)
+func (m *Metrics) RecordProductStockLevel(product entity.Product) {
+	m.stockLevels.WithLabelValues(product.Category).Observe(float64(product.Stock))
+	m.priceRanges.WithLabelValues(product.Category).Observe(product.Price)
 }
 
 // RecordLowStockAlert records low stock alert
-func RecordLowStockAlert(product entity.Product) {
+func (m *Metrics) RecordLowStockAlert(product entity.Product) {
 	// This could be used for alerting when stock is low
 	// For now, we just record the metric
-	productsLowStock.Inc()
+	m.productsLowStock.Inc()
 }
 
 // RecordOutOfStockAlert records out of stock alert
-func RecordOutOfStockAlert(product entity.Product) {
+func (m *Metrics) RecordOutOfStockAlert(product entity.Product) {
 	// This could be used for alerting when product is out of stock
-	productsOutOfStock.Inc()
+	m.productsOutOfStock.Inc()
 }
 
-// UpdateSystemMetrics updates system-level metrics
-func UpdateSystemMetrics() {
+// UpdateSystemMetrics updates system-level metrics and, when sqlDB is set, database pool metrics
+func (m *Metrics) UpdateSystemMetrics(sqlDB *sql.DB) {
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
 	
 	// Memory metrics
-	memoryAllocBytes.Set(float64(memStats.Alloc))
-	memorySysBytes.Set(float64(memStats.Sys))
-	memoryHeapBytes.Set(float64(memStats.HeapAlloc))
-	memoryStackBytes.Set(float64(memStats.StackInuse))
+	m.memoryAllocBytes.Set(float64(memStats.Alloc))
+	m.memorySysBytes.Set(float64(memStats.Sys))
+	m.memoryHeapBytes.Set(float64(memStats.HeapAlloc))
+	m.memoryStackBytes.Set(float64(memStats.StackInuse))
 	
 	// GC metrics
-	gcCount.Add(float64(memStats.NumGC - lastGCCount))
-	lastGCCount = memStats.NumGC
+	m.gcCount.Add(float64(memStats.NumGC - m.lastGCCount))
+	m.lastGCCount = memStats.NumGC
 	
 	// Record GC duration if available
 	if memStats.PauseTotalNs > 0 {
 		avgGCPause := float64(memStats.PauseTotalNs) / float64(memStats.NumGC) / 1e9
-		gcDuration.Observe(avgGCPause)
+		m.gcDuration.Observe(avgGCPause)
 	}
 	
 	// Goroutine and thread metrics
-	goroutinesTotal.Set(float64(runtime.NumGoroutine()))
+	m.goroutinesTotal.Set(float64(runtime.NumGoroutine()))
 	
 	// CGO calls (not available in runtime.MemStats)
-	// cgoCalls.Add(float64(memStats.CGOCall - lastCGOCalls))
-	// lastCGOCalls = memStats.CGOCall
+	// m.cgoCalls.Add(float64(memStats.CGOCall - m.lastCGOCalls))
+	// m.lastCGOCalls = memStats.CGOCall
 	
 	// Approximate CPU usage (this is a simple approximation)
-	updateCPUUsage()
+	m.updateCPUUsage()
 	
 	// Application metrics
-	updateApplicationMetrics()
+	m.updateApplicationMetrics()
+
+	// Database connection pool metrics
+	if sqlDB != nil {
+		m.updateDBPoolMetrics(sqlDB.Stats())
+	}
 }
 
-// Global variables to track changes
-var (
-	lastGCCount  uint32
-	lastCGOCalls uint64
-	lastCPUTime  time.Time
-	lastGCPause  time.Duration
-)
+// StartSystemMetricsCollector calls UpdateSystemMetrics on every interval until the context is cancelled
+func (m *Metrics) StartSystemMetricsCollector(ctx context.Context, sqlDB *sql.DB, interval time.Duration) {
+	m.UpdateSystemMetrics(sqlDB)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.UpdateSystemMetrics(sqlDB)
+		}
+	}
+}
 
 // updateCPUUsage approximates CPU usage
-func updateCPUUsage() {
+func (m *Metrics) updateCPUUsage() {
 	now := time.Now()
-	if !lastCPUTime.IsZero() {
+	if !m.lastCPUTime.IsZero() {
 		// Simple approximation based on GC activity and goroutines
 		var memStats runtime.MemStats
 		runtime.ReadMemStats(&memStats)
 		
 		// Approximate CPU usage based on GC pressure and goroutine count
-		gcPressure := float64(memStats.NumGC) / float64(now.Sub(lastCPUTime).Seconds())
+		gcPressure := float64(memStats.NumGC) / float64(now.Sub(m.lastCPUTime).Seconds())
 		goroutinePressure := float64(runtime.NumGoroutine()) / 100.0
 		
 		// Combine factors for approximation (this is not precise)
@@ -534,23 +800,31 @@ func updateCPUUsage() {
 			cpuUsage = 100
 		}
 		
-		cpuUsagePercent.Set(cpuUsage)
+		m.cpuUsagePercent.Set(cpuUsage)
 	}
-	lastCPUTime = now
+	m.lastCPUTime = now
 }
 
 // updateApplicationMetrics updates application-specific metrics
-func updateApplicationMetrics() {
+func (m *Metrics) updateApplicationMetrics() {
 	// These would be updated based on actual application state
 	// For now, we'll use simple approximations
 	
 	// Approximate active connections based on goroutines
 	goroutineCount := runtime.NumGoroutine()
 	estimatedConnections := float64(goroutineCount) * 0.1 // Rough estimate
-	httpConnections.Set(estimatedConnections)
+	m.httpConnections.Set(estimatedConnections)
 	
 	// Request queue size (simplified)
-	requestQueueSize.Set(0) // In a real app, this would track actual queue size
+	m.requestQueueSize.Set(0) // In a real app, this would track actual queue size
+}
+
+// updateDBPoolMetrics exports the database/sql connection pool statistics
+func (m *Metrics) updateDBPoolMetrics(stats sql.DBStats) {
+	m.dbPoolOpenConnections.Set(float64(stats.OpenConnections))
+	m.dbPoolInUseConnections.Set(float64(stats.InUse))
+	m.dbPoolIdleConnections.Set(float64(stats.Idle))
+	m.dbPoolWaitCount.Set(float64(stats.WaitCount))
 }
 
 // GetPrometheusMetrics returns the Prometheus registry
@@ -0,0 +1,325 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"obs-tools-usage/internal/product/domain/entity"
+)
+
+// CreateCategory creates a new managed category
+func (r *ProductRepositoryImpl) CreateCategory(ctx context.Context, category entity.ProductCategory) (*entity.ProductCategory, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "CreateCategory",
+		"category":  category.Name,
+	}).Debug("Database operation started")
+
+	var existing int64
+	if err := r.db.WithContext(ctx).Model(&entity.ProductCategory{}).Where("name = ?", category.Name).Count(&existing).Error; err != nil {
+		r.metrics.RecordDatabaseOperation("CreateCategory", "INSERT", time.Since(start))
+		return nil, err
+	}
+	if existing > 0 {
+		r.metrics.RecordDatabaseOperation("CreateCategory", "INSERT", time.Since(start))
+		return nil, fmt.Errorf("category conflict: %q already exists", category.Name)
+	}
+
+	result := r.db.WithContext(ctx).Create(&category)
+	duration := time.Since(start)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation": "CreateCategory",
+			"action":    "INSERT",
+			"category":  category.Name,
+			"error":     result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+
+		r.metrics.RecordDatabaseOperation("CreateCategory", "INSERT", duration)
+		return nil, result.Error
+	}
+
+	r.metrics.RecordDatabaseOperation("CreateCategory", "INSERT", duration)
+
+	r.logger.WithFields(logrus.Fields{
+		"operation": "CreateCategory",
+		"action":    "INSERT",
+		"category_id": category.ID,
+		"category":  category.Name,
+		"duration_ms": duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return &category, nil
+}
+
+// GetCategoryByName returns a managed category by its name
+func (r *ProductRepositoryImpl) GetCategoryByName(ctx context.Context, name string) (*entity.ProductCategory, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "GetCategoryByName",
+		"category":  name,
+	}).Debug("Database operation started")
+
+	var category entity.ProductCategory
+	result := r.db.WithContext(ctx).Where("name = ?", name).First(&category)
+	duration := time.Since(start)
+
+	if result.Error != nil {
+		r.metrics.RecordDatabaseOperation("GetCategoryByName", "SELECT", duration)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			r.logger.WithFields(logrus.Fields{
+				"operation": "GetCategoryByName",
+				"action":    "SELECT",
+				"category":  name,
+				"duration_ms": duration.Milliseconds(),
+			}).Warn("Category not found")
+			return nil, errors.New("category not found")
+		}
+
+		r.logger.WithFields(logrus.Fields{
+			"operation": "GetCategoryByName",
+			"action":    "SELECT",
+			"category":  name,
+			"error":     result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+
+	r.metrics.RecordDatabaseOperation("GetCategoryByName", "SELECT", duration)
+
+	r.logger.WithFields(logrus.Fields{
+		"operation": "GetCategoryByName",
+		"action":    "SELECT",
+		"category":  name,
+		"duration_ms": duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return &category, nil
+}
+
+// UpdateCategory updates the mutable fields of a managed category
+func (r *ProductRepositoryImpl) UpdateCategory(ctx context.Context, category entity.ProductCategory) (*entity.ProductCategory, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation":   "UpdateCategory",
+		"category_id": category.ID,
+		"category":    category.Name,
+	}).Debug("Database operation started")
+
+	result := r.db.WithContext(ctx).Save(&category)
+	duration := time.Since(start)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "UpdateCategory",
+			"action":      "UPDATE",
+			"category_id": category.ID,
+			"error":       result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+
+		r.metrics.RecordDatabaseOperation("UpdateCategory", "UPDATE", duration)
+		return nil, result.Error
+	}
+
+	r.metrics.RecordDatabaseOperation("UpdateCategory", "UPDATE", duration)
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":   "UpdateCategory",
+		"action":      "UPDATE",
+		"category_id": category.ID,
+		"category":    category.Name,
+		"duration_ms": duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return &category, nil
+}
+
+// RenameCategory renames a category and moves every product referencing it in a single transaction.
+// Categories that only exist on products are registered under the new name.
+func (r *ProductRepositoryImpl) RenameCategory(ctx context.Context, oldName, newName string) (*entity.ProductCategory, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "RenameCategory",
+		"old_name":  oldName,
+		"new_name":  newName,
+	}).Debug("Database operation started")
+
+	var renamed entity.ProductCategory
+	var movedProducts int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var conflicts int64
+		if err := tx.Model(&entity.ProductCategory{}).Where("name = ?", newName).Count(&conflicts).Error; err != nil {
+			return err
+		}
+		if conflicts > 0 {
+			return fmt.Errorf("category conflict: %q already exists", newName)
+		}
+
+		found, err := findCategoryForUpdate(tx, oldName, &renamed)
+		if err != nil {
+			return err
+		}
+
+		productCount, err := countProductsInCategory(tx, oldName)
+		if err != nil {
+			return err
+		}
+		if !found && productCount == 0 {
+			return errors.New("category not found")
+		}
+
+		renamed.Name = newName
+		if found {
+			if err := tx.Save(&renamed).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Create(&renamed).Error; err != nil {
+			return err
+		}
+
+		// Soft-deleted products follow the rename so they stay consistent if restored
+		result := tx.Unscoped().Model(&entity.Product{}).Where("category = ?", oldName).Updates(map[string]interface{}{
+			"category":   newName,
+			"updated_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		movedProducts = result.RowsAffected
+		return nil
+	})
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("RenameCategory", "UPDATE", duration)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation": "RenameCategory",
+			"action":    "UPDATE",
+			"old_name":  oldName,
+			"new_name":  newName,
+			"error":     err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation": "RenameCategory",
+		"action":    "UPDATE",
+		"old_name":  oldName,
+		"new_name":  newName,
+		"products_moved": movedProducts,
+		"duration_ms": duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return &renamed, nil
+}
+
+// DeleteCategory deletes a category. It refuses while products still reference it
+// unless reassignTo names a category that those products should be moved to.
+func (r *ProductRepositoryImpl) DeleteCategory(ctx context.Context, name, reassignTo string) error {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation":   "DeleteCategory",
+		"category":    name,
+		"reassign_to": reassignTo,
+	}).Debug("Database operation started")
+
+	var movedProducts int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var category entity.ProductCategory
+		found, err := findCategoryForUpdate(tx, name, &category)
+		if err != nil {
+			return err
+		}
+
+		productCount, err := countProductsInCategory(tx, name)
+		if err != nil {
+			return err
+		}
+		if !found && productCount == 0 {
+			return errors.New("category not found")
+		}
+
+		if productCount > 0 {
+			if reassignTo == "" {
+				return fmt.Errorf("category conflict: %q is still used by %d products", name, productCount)
+			}
+
+			target := entity.ProductCategory{Name: reassignTo}
+			if err := tx.Where("name = ?", reassignTo).FirstOrCreate(&target).Error; err != nil {
+				return err
+			}
+
+			result := tx.Unscoped().Model(&entity.Product{}).Where("category = ?", name).Updates(map[string]interface{}{
+				"category":   reassignTo,
+				"updated_at": time.Now(),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			movedProducts = result.RowsAffected
+		}
+
+		if found {
+			return tx.Delete(&category).Error
+		}
+		return nil
+	})
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("DeleteCategory", "DELETE", duration)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation": "DeleteCategory",
+			"action":    "DELETE",
+			"category":  name,
+			"error":     err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":      "DeleteCategory",
+		"action":         "DELETE",
+		"category":       name,
+		"reassign_to":    reassignTo,
+		"products_moved": movedProducts,
+		"duration_ms":    duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return nil
+}
+
+// findCategoryForUpdate loads and row-locks a managed category by name, reporting whether it
+// exists. The lock serializes concurrent renames and merges of the same category.
+func findCategoryForUpdate(tx *gorm.DB, name string, category *entity.ProductCategory) (bool, error) {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// countProductsInCategory counts the products referencing a category name
+func countProductsInCategory(tx *gorm.DB, name string) (int64, error) {
+	var count int64
+	err := tx.Model(&entity.Product{}).Where("category = ?", name).Count(&count).Error
+	return count, err
+}
@@ -10,6 +10,8 @@ import (
 	"gorm.io/gorm/logger"
 	"obs-tools-usage/internal/product/domain/entity"
 	"obs-tools-usage/internal/product/infrastructure/config"
+	"obs-tools-usage/kafka/consumer"
+	"obs-tools-usage/pkg/seed"
 )
 
 // gormLogWriter implements logger.Writer interface for GORM
@@ -60,16 +62,19 @@ func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	logger := config.GetLogger()
 	logger.WithFields(logrus.Fields{
-		"host":     cfg.Host,
-		"port":     cfg.Port,
-		"database": cfg.DBName,
-		"user":     cfg.User,
+		"host":              cfg.Host,
+		"port":              cfg.Port,
+		"database":          cfg.DBName,
+		"user":              cfg.User,
+		"max_open_conns":    cfg.MaxOpenConns,
+		"max_idle_conns":    cfg.MaxIdleConns,
+		"conn_max_lifetime": cfg.ConnMaxLifetime,
 	}).Info("Database connected successfully")
 
 	return &Database{
@@ -79,6 +84,12 @@ func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
 	}, nil
 }
 
+// Full-text search over products: name matches weigh more than description matches
+const (
+	productSearchVectorColumn = "search_vector"
+	productSearchConfig       = "english"
+)
+
 // Migrate runs database migrations
 func (d *Database) Migrate() error {
 	d.Logger.Info("Running database migrations...")
@@ -89,10 +100,58 @@ func (d *Database) Migrate() error {
 		return fmt.Errorf("failed to migrate Product model: %w", err)
 	}
 
+	if err := d.migrateProductSearch(); err != nil {
+		d.Logger.WithError(err).Error("Failed to migrate product search index")
+		return fmt.Errorf("failed to migrate product search index: %w", err)
+	}
+
+	// Auto migrate ProductCategory model
+	if err := d.DB.AutoMigrate(&entity.ProductCategory{}); err != nil {
+		d.Logger.WithError(err).Error("Failed to migrate ProductCategory model")
+		return fmt.Errorf("failed to migrate ProductCategory model: %w", err)
+	}
+
+	// Auto migrate ProductPriceHistory model
+	if err := d.DB.AutoMigrate(&entity.ProductPriceHistory{}); err != nil {
+		d.Logger.WithError(err).Error("Failed to migrate ProductPriceHistory model")
+		return fmt.Errorf("failed to migrate ProductPriceHistory model: %w", err)
+	}
+
+	// Auto migrate Promotion model
+	if err := d.DB.AutoMigrate(&entity.Promotion{}); err != nil {
+		d.Logger.WithError(err).Error("Failed to migrate Promotion model")
+		return fmt.Errorf("failed to migrate Promotion model: %w", err)
+	}
+
+	// Auto migrate the processed events table, recording stock events already applied
+	if err := d.DB.AutoMigrate(&consumer.ProcessedEvent{}); err != nil {
+		d.Logger.WithError(err).Error("Failed to migrate ProcessedEvent model")
+		return fmt.Errorf("failed to migrate ProcessedEvent model: %w", err)
+	}
+
 	d.Logger.Info("Database migrations completed successfully")
 	return nil
 }
 
+// migrateProductSearch adds the generated full-text search column over product name and
+// description and its GIN index. It is a no-op on databases without PostgreSQL full-text search.
+func (d *Database) migrateProductSearch() error {
+	if d.DB.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	addColumn := "ALTER TABLE products ADD COLUMN IF NOT EXISTS " + productSearchVectorColumn + " tsvector " +
+		"GENERATED ALWAYS AS (" +
+		"setweight(to_tsvector('" + productSearchConfig + "', coalesce(name, '')), 'A') || " +
+		"setweight(to_tsvector('" + productSearchConfig + "', coalesce(description, '')), 'B')" +
+		") STORED"
+	if err := d.DB.Exec(addColumn).Error; err != nil {
+		return err
+	}
+
+	return d.DB.Exec("CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (" + productSearchVectorColumn + ")").Error
+}
+
 // Close closes the database connection
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
@@ -123,18 +182,11 @@ func (d *Database) Health() error {
 	return nil
 }
 
-// SeedData seeds the database with initial data
-func (d *Database) SeedData() error {
+// SeedData seeds the database with sample products. Products that already exist,
+// matched by name, are skipped, so seeding can be re-run.
+func (d *Database) SeedData() (seed.Result, error) {
 	d.Logger.Info("Seeding database with initial data...")
 
-	// Check if data already exists
-	var count int64
-	d.DB.Model(&entity.Product{}).Count(&count)
-	if count > 0 {
-		d.Logger.Info("Database already contains data, skipping seed")
-		return nil
-	}
-
 	// Sample products
 	products := []entity.Product{
 		{
@@ -209,17 +261,30 @@ func (d *Database) SeedData() error {
 		},
 	}
 
-	// Create products
+	// Create the products that do not exist yet
+	var result seed.Result
 	for _, product := range products {
+		var count int64
+		if err := d.DB.Model(&entity.Product{}).Where("name = ?", product.Name).Count(&count).Error; err != nil {
+			return result, fmt.Errorf("failed to check product %s: %w", product.Name, err)
+		}
+		if count > 0 {
+			result.Skipped++
+			continue
+		}
+
 		product.CreatedAt = time.Now()
 		product.UpdatedAt = time.Now()
-		
 		if err := d.DB.Create(&product).Error; err != nil {
 			d.Logger.WithError(err).WithField("product", product.Name).Error("Failed to seed product")
-			return fmt.Errorf("failed to seed product %s: %w", product.Name, err)
+			return result, fmt.Errorf("failed to seed product %s: %w", product.Name, err)
 		}
+		result.Inserted++
 	}
 
-	d.Logger.WithField("count", len(products)).Info("Database seeded successfully")
-	return nil
+	d.Logger.WithFields(logrus.Fields{
+		"inserted": result.Inserted,
+		"skipped":  result.Skipped,
+	}).Info("Database seeded successfully")
+	return result, nil
 }
@@ -0,0 +1,86 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"obs-tools-usage/internal/product/domain/entity"
+)
+
+// benchmarkProductRows is how many products the random sampling benchmarks run against; it is
+// above randomSampleMinRows so the TABLESAMPLE strategy samples instead of returning nothing
+const benchmarkProductRows = 50000
+
+// newBenchmarkRepository connects to the scratch PostgreSQL database named by
+// PRODUCT_BENCHMARK_DSN, seeding its products table up to benchmarkProductRows rows.
+// The benchmark is skipped when the variable is unset.
+func newBenchmarkRepository(b *testing.B) *ProductRepositoryImpl {
+	b.Helper()
+	dsn := os.Getenv("PRODUCT_BENCHMARK_DSN")
+	if dsn == "" {
+		b.Skip("PRODUCT_BENCHMARK_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		b.Fatalf("connect: %v", err)
+	}
+	if err := db.AutoMigrate(&entity.Product{}); err != nil {
+		b.Fatalf("migrate products: %v", err)
+	}
+
+	var existing int64
+	if err := db.Model(&entity.Product{}).Count(&existing).Error; err != nil {
+		b.Fatalf("count products: %v", err)
+	}
+	if missing := benchmarkProductRows - int(existing); missing > 0 {
+		products := make([]entity.Product, missing)
+		for i := range products {
+			products[i] = entity.Product{
+				Name:     fmt.Sprintf("Benchmark product %d", int(existing)+i),
+				Price:    float64(i%1000) + 0.99,
+				Stock:    i % 50,
+				Category: "benchmark",
+			}
+		}
+		if err := db.CreateInBatches(products, 1000).Error; err != nil {
+			b.Fatalf("seed products: %v", err)
+		}
+	}
+	// Refresh the planner's row estimate, which decides whether sampling applies
+	if err := db.Exec("ANALYZE products").Error; err != nil {
+		b.Fatalf("analyze products: %v", err)
+	}
+
+	return &ProductRepositoryImpl{db: db}
+}
+
+func BenchmarkSortRandomProducts(b *testing.B) {
+	r := newBenchmarkRepository(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.sortRandomProducts(context.Background(), maxRandomProducts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSampleRandomProducts(b *testing.B) {
+	r := newBenchmarkRepository(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		products, err := r.sampleRandomProducts(context.Background(), maxRandomProducts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(products) == 0 {
+			b.Fatal("table sample returned no products; is the planner estimate below randomSampleMinRows?")
+		}
+	}
+}
@@ -1,37 +1,52 @@
 package persistence
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"obs-tools-usage/internal/product/domain/entity"
 	"obs-tools-usage/internal/product/infrastructure/config"
 	"obs-tools-usage/internal/product/infrastructure/external"
+	"obs-tools-usage/kafka/consumer"
 )
 
+// stockConsumerName identifies the stock consumer in the processed events table
+const stockConsumerName = "product-stock"
+
 // ProductRepositoryImpl implements the ProductRepository interface using GORM
 type ProductRepositoryImpl struct {
-	db     *gorm.DB
-	logger *logrus.Entry
+	db                *gorm.DB
+	lowStockThreshold int // Applies to products without their own low stock threshold
+	metrics           *external.Metrics
+	logger            *logrus.Entry
 }
 
 // NewProductRepositoryImpl creates a new product repository implementation
-func NewProductRepositoryImpl(db *gorm.DB) *ProductRepositoryImpl {
+func NewProductRepositoryImpl(db *gorm.DB, lowStockThreshold int, metrics *external.Metrics) *ProductRepositoryImpl {
 	return &ProductRepositoryImpl{
-		db:     db,
-		logger: config.GetLogger().WithField("component", "repository"),
+		db:                db,
+		lowStockThreshold: lowStockThreshold,
+		metrics:           metrics,
+		logger:            config.GetLogger().WithField("component", "repository"),
 	}
 }
 
+// atOrBelowThreshold matches products whose stock is at or below their own low stock
+// threshold, falling back to the repository default when a product has none
+const atOrBelowThreshold = "stock <= COALESCE(low_stock_threshold, ?)"
+
 // GetAllProducts returns all products
-func (r *ProductRepositoryImpl) GetAllProducts() ([]entity.Product, error) {
+func (r *ProductRepositoryImpl) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithField("operation", "GetAllProducts").Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Find(&products)
+	result := r.db.WithContext(ctx).Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -43,15 +58,15 @@ func (r *ProductRepositoryImpl) GetAllProducts() ([]entity.Product, error) {
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("GetAllProducts", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetAllProducts", "SELECT", duration)
 		return nil, result.Error
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("GetAllProducts", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetAllProducts", "SELECT", duration)
 
 	// Update business metrics
-	external.UpdateBusinessMetrics(products)
+	r.metrics.UpdateBusinessMetrics(products, r.lowStockThreshold)
 
 	// Log slow queries
 	external.LogSlowQueries(r.logger.WithField("source", "repository"), "GetAllProducts", duration, 100*time.Millisecond)
@@ -66,34 +81,105 @@ func (r *ProductRepositoryImpl) GetAllProducts() ([]entity.Product, error) {
 	return products, nil
 }
 
-// GetProductByID returns a product by its ID
-func (r *ProductRepositoryImpl) GetProductByID(id int) (*entity.Product, error) {
+// productOrder returns the ORDER BY clause of a product list sort. Only whitelisted fields reach
+// the clause, anything else orders by id; ties are broken by id in the same direction for stable pages.
+func productOrder(sort string) string {
+	field, desc := entity.ProductSortField(sort)
+	if !entity.ValidProductSort(sort) {
+		field, desc = "id", false
+	}
+	if field == "id" {
+		if desc {
+			return "id DESC"
+		}
+		return "id"
+	}
+	if desc {
+		return field + " DESC, id DESC"
+	}
+	return field + ", id"
+}
+
+// GetProductsPage returns a page of products in sort order and the total number of products
+func (r *ProductRepositoryImpl) GetProductsPage(ctx context.Context, limit, offset int, sort string) ([]entity.Product, int64, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "GetProductsPage",
+		"limit":     limit,
+		"offset":    offset,
+		"sort":      sort,
+	}).Debug("Database operation started")
+
+	var total int64
+	var products []entity.Product
+	err := r.db.WithContext(ctx).Model(&entity.Product{}).Count(&total).Error
+	if err == nil {
+		err = r.db.WithContext(ctx).Order(productOrder(sort)).Limit(limit).Offset(offset).Find(&products).Error
+	}
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("GetProductsPage", "SELECT", duration)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "GetProductsPage",
+			"action":      "SELECT",
+			"error":       err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, 0, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "GetProductsPage",
+		"action":       "SELECT",
+		"limit":        limit,
+		"offset":       offset,
+		"total":        total,
+		"record_count": len(products),
+		"duration_ms":  duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return products, total, nil
+}
+
+// GetProductByID returns a product by its ID, excluding soft-deleted products
+func (r *ProductRepositoryImpl) GetProductByID(ctx context.Context, id int) (*entity.Product, error) {
+	return r.findProductByID(r.db.WithContext(ctx), "GetProductByID", id)
+}
+
+// GetProductByIDUnscoped returns a product by its ID, including soft-deleted products
+func (r *ProductRepositoryImpl) GetProductByIDUnscoped(ctx context.Context, id int) (*entity.Product, error) {
+	return r.findProductByID(r.db.WithContext(ctx).Unscoped(), "GetProductByIDUnscoped", id)
+}
+
+// findProductByID loads a product by its ID using the given scope
+func (r *ProductRepositoryImpl) findProductByID(db *gorm.DB, operation string, id int) (*entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
-		"operation": "GetProductByID",
+		"operation": operation,
 		"product_id": id,
 	}).Debug("Database operation started")
 
 	var product entity.Product
-	result := r.db.First(&product, id)
+	result := db.First(&product, id)
 	duration := time.Since(start)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			r.logger.WithFields(logrus.Fields{
-				"operation": "GetProductByID",
+				"operation": operation,
 				"action":    "SELECT",
 				"product_id": id,
 				"duration_ms": duration.Milliseconds(),
 			}).Warn("Product not found")
 
 			// Record failed database operation
-			external.RecordDatabaseOperation("GetProductByID", "SELECT", duration)
+			r.metrics.RecordDatabaseOperation(operation, "SELECT", duration)
 			return nil, errors.New("product not found")
 		}
 
 		r.logger.WithFields(logrus.Fields{
-			"operation": "GetProductByID",
+			"operation": operation,
 			"action":    "SELECT",
 			"product_id": id,
 			"error":     result.Error.Error(),
@@ -101,18 +187,18 @@ func (r *ProductRepositoryImpl) GetProductByID(id int) (*entity.Product, error)
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("GetProductByID", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation(operation, "SELECT", duration)
 		return nil, result.Error
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("GetProductByID", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation(operation, "SELECT", duration)
 
 	// Log slow queries
-	external.LogSlowQueries(r.logger.WithField("source", "repository"), "GetProductByID", duration, 50*time.Millisecond)
+	external.LogSlowQueries(r.logger.WithField("source", "repository"), operation, duration, 50*time.Millisecond)
 
 	r.logger.WithFields(logrus.Fields{
-		"operation": "GetProductByID",
+		"operation": operation,
 		"action":    "SELECT",
 		"product_id": id,
 		"duration_ms": duration.Milliseconds(),
@@ -122,7 +208,7 @@ func (r *ProductRepositoryImpl) GetProductByID(id int) (*entity.Product, error)
 }
 
 // CreateProduct creates a new product
-func (r *ProductRepositoryImpl) CreateProduct(product entity.Product) (*entity.Product, error) {
+func (r *ProductRepositoryImpl) CreateProduct(ctx context.Context, product entity.Product) (*entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "CreateProduct",
@@ -130,7 +216,7 @@ func (r *ProductRepositoryImpl) CreateProduct(product entity.Product) (*entity.P
 		"category":  product.Category,
 	}).Debug("Database operation started")
 
-	result := r.db.Create(&product)
+	result := r.db.WithContext(ctx).Create(&product)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -142,12 +228,12 @@ func (r *ProductRepositoryImpl) CreateProduct(product entity.Product) (*entity.P
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("CreateProduct", "INSERT", duration)
+		r.metrics.RecordDatabaseOperation("CreateProduct", "INSERT", duration)
 		return nil, result.Error
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("CreateProduct", "INSERT", duration)
+	r.metrics.RecordDatabaseOperation("CreateProduct", "INSERT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "CreateProduct",
@@ -157,12 +243,103 @@ func (r *ProductRepositoryImpl) CreateProduct(product entity.Product) (*entity.P
 		"duration_ms": duration.Milliseconds(),
 	}).Info("Database operation completed")
 
-	external.RecordProductCreated()
+	r.metrics.RecordProductCreated()
 	return &product, nil
 }
 
+// UpsertProductBySKU inserts the product or, when a product with its SKU already exists, updates it
+// in the same INSERT ... ON CONFLICT statement. A soft-deleted product with the SKU is restored.
+// It reports whether the product was created.
+func (r *ProductRepositoryImpl) UpsertProductBySKU(ctx context.Context, product entity.Product, changedBy string) (*entity.Product, bool, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "UpsertProductBySKU",
+		"sku":       *product.SKU,
+		"name":      product.Name,
+	}).Debug("Database operation started")
+
+	var created bool
+	var history *entity.ProductPriceHistory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the current row, if any, so its price can be recorded in the history
+		var current entity.Product
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "price").
+			Where("sku = ?", *product.SKU).
+			Take(&current).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		created = errors.Is(err, gorm.ErrRecordNotFound)
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "sku"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "description", "price", "stock", "category", "low_stock_threshold", "updated_at", "deleted_at"}),
+		}).Create(&product).Error
+		if err != nil {
+			return err
+		}
+
+		// Reload so an update returns the stored ID and creation time
+		if err := tx.Where("sku = ?", *product.SKU).Take(&product).Error; err != nil {
+			return err
+		}
+
+		if created || current.Price == product.Price {
+			return nil
+		}
+		history = &entity.ProductPriceHistory{
+			ProductID: product.ID,
+			OldPrice:  current.Price,
+			NewPrice:  product.Price,
+			ChangedAt: time.Now(),
+			ChangedBy: changedBy,
+		}
+		return tx.Create(history).Error
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "UpsertProductBySKU",
+			"action":      "UPSERT",
+			"sku":         *product.SKU,
+			"error":       err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+
+		// Record failed database operation
+		r.metrics.RecordDatabaseOperation("UpsertProductBySKU", "UPSERT", duration)
+		return nil, false, err
+	}
+
+	// Record successful database operation
+	r.metrics.RecordDatabaseOperation("UpsertProductBySKU", "UPSERT", duration)
+
+	fields := logrus.Fields{
+		"operation":   "UpsertProductBySKU",
+		"action":      "UPSERT",
+		"product_id":  product.ID,
+		"sku":         *product.SKU,
+		"created":     created,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if history != nil {
+		fields["old_price"] = history.OldPrice
+		fields["new_price"] = history.NewPrice
+	}
+	r.logger.WithFields(fields).Info("Database operation completed")
+
+	if created {
+		r.metrics.RecordProductCreated()
+	} else {
+		r.metrics.RecordProductUpdated()
+	}
+	return &product, created, nil
+}
+
 // UpdateProduct updates an existing product
-func (r *ProductRepositoryImpl) UpdateProduct(product entity.Product) (*entity.Product, error) {
+func (r *ProductRepositoryImpl) UpdateProduct(ctx context.Context, product entity.Product, changedBy string) (*entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "UpdateProduct",
@@ -170,52 +347,223 @@ func (r *ProductRepositoryImpl) UpdateProduct(product entity.Product) (*entity.P
 		"name":      product.Name,
 	}).Debug("Database operation started")
 
-	result := r.db.Save(&product)
+	// The price history row is written in the same transaction as the update
+	// so the history can never diverge from the current price
+	var history *entity.ProductPriceHistory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current entity.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price").First(&current, product.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return err
+		}
+
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+
+		if current.Price == product.Price {
+			return nil
+		}
+		history = &entity.ProductPriceHistory{
+			ProductID: product.ID,
+			OldPrice:  current.Price,
+			NewPrice:  product.Price,
+			ChangedAt: time.Now(),
+			ChangedBy: changedBy,
+		}
+		return tx.Create(history).Error
+	})
 	duration := time.Since(start)
 
-	if result.Error != nil {
+	if err != nil {
 		r.logger.WithFields(logrus.Fields{
 			"operation": "UpdateProduct",
 			"action":    "UPDATE",
 			"product_id": product.ID,
-			"error":     result.Error.Error(),
+			"error":     err.Error(),
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("UpdateProduct", "UPDATE", duration)
-		return nil, result.Error
+		r.metrics.RecordDatabaseOperation("UpdateProduct", "UPDATE", duration)
+		return nil, err
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("UpdateProduct", "UPDATE", duration)
+	r.metrics.RecordDatabaseOperation("UpdateProduct", "UPDATE", duration)
 
-	r.logger.WithFields(logrus.Fields{
+	fields := logrus.Fields{
 		"operation": "UpdateProduct",
 		"action":    "UPDATE",
 		"product_id": product.ID,
 		"name":      product.Name,
 		"duration_ms": duration.Milliseconds(),
-	}).Info("Database operation completed")
+	}
+	if history != nil {
+		fields["old_price"] = history.OldPrice
+		fields["new_price"] = history.NewPrice
+	}
+	r.logger.WithFields(fields).Info("Database operation completed")
 
-	external.RecordProductUpdated()
+	r.metrics.RecordProductUpdated()
 	return &product, nil
 }
 
-// DeleteProduct deletes a product by its ID
-func (r *ProductRepositoryImpl) DeleteProduct(id int) error {
+// AdjustStock adds delta to a product's stock under a row lock and returns the updated product
+// with its previous stock. Stock never drops below zero: a larger decrease empties it instead,
+// since the sale it reflects has already happened.
+// A non-empty eventID is recorded as processed in the same transaction; when it already is,
+// the stock is left untouched and applied is false.
+func (r *ProductRepositoryImpl) AdjustStock(ctx context.Context, id int, delta int, eventID string) (*entity.Product, int, bool, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation":  "AdjustStock",
+		"product_id": id,
+		"delta":      delta,
+		"event_id":   eventID,
+	}).Debug("Database operation started")
+
+	var product entity.Product
+	var previousStock int
+	applied := true
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if eventID != "" {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&consumer.ProcessedEvent{
+				Consumer:    stockConsumerName,
+				EventID:     eventID,
+				ProcessedAt: time.Now(),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				applied = false
+				return nil
+			}
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("product not found")
+			}
+			return err
+		}
+
+		previousStock = product.Stock
+		product.Stock += delta
+		if product.Stock < 0 {
+			product.Stock = 0
+		}
+		return tx.Model(&product).Update("stock", product.Stock).Error
+	})
+	duration := time.Since(start)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "AdjustStock",
+			"action":      "UPDATE",
+			"product_id":  id,
+			"error":       err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+
+		// Record failed database operation
+		r.metrics.RecordDatabaseOperation("AdjustStock", "UPDATE", duration)
+		return nil, 0, false, err
+	}
+
+	// Record successful database operation
+	r.metrics.RecordDatabaseOperation("AdjustStock", "UPDATE", duration)
+
+	if !applied {
+		r.logger.WithFields(logrus.Fields{
+			"operation":  "AdjustStock",
+			"product_id": id,
+			"event_id":   eventID,
+		}).Info("Stock event already applied, stock left unchanged")
+		return nil, 0, false, nil
+	}
+
+	fields := logrus.Fields{
+		"operation":      "AdjustStock",
+		"action":         "UPDATE",
+		"product_id":     id,
+		"delta":          delta,
+		"previous_stock": previousStock,
+		"stock":          product.Stock,
+		"duration_ms":    duration.Milliseconds(),
+	}
+	if previousStock+delta < 0 {
+		r.logger.WithFields(fields).Warn("Stock decrease exceeded available stock, stock set to zero")
+	} else {
+		r.logger.WithFields(fields).Info("Database operation completed")
+	}
+
+	return &product, previousStock, true, nil
+}
+
+// GetPriceHistory returns the price changes of a product, newest first
+func (r *ProductRepositoryImpl) GetPriceHistory(ctx context.Context, productID int, limit int) ([]entity.ProductPriceHistory, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation":  "GetPriceHistory",
+		"product_id": productID,
+		"limit":      limit,
+	}).Debug("Database operation started")
+
+	var history []entity.ProductPriceHistory
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("changed_at DESC").Order("id DESC").Limit(limit).Find(&history)
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("GetPriceHistory", "SELECT", duration)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "GetPriceHistory",
+			"action":      "SELECT",
+			"product_id":  productID,
+			"error":       result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":   "GetPriceHistory",
+		"action":      "SELECT",
+		"product_id":  productID,
+		"count":       len(history),
+		"duration_ms": duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return history, nil
+}
+
+// DeleteProduct soft-deletes a product by its ID so it can be restored later
+func (r *ProductRepositoryImpl) DeleteProduct(ctx context.Context, id int) error {
+	return r.deleteProduct(r.db.WithContext(ctx), "DeleteProduct", id)
+}
+
+// HardDeleteProduct permanently removes a product by its ID, including soft-deleted products
+func (r *ProductRepositoryImpl) HardDeleteProduct(ctx context.Context, id int) error {
+	return r.deleteProduct(r.db.WithContext(ctx).Unscoped(), "HardDeleteProduct", id)
+}
+
+// deleteProduct deletes a product by its ID using the given scope
+func (r *ProductRepositoryImpl) deleteProduct(db *gorm.DB, operation string, id int) error {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
-		"operation": "DeleteProduct",
+		"operation": operation,
 		"product_id": id,
 	}).Debug("Database operation started")
 
-	result := r.db.Delete(&entity.Product{}, id)
+	result := db.Delete(&entity.Product{}, id)
 	duration := time.Since(start)
 
 	if result.Error != nil {
 		r.logger.WithFields(logrus.Fields{
-			"operation": "DeleteProduct",
+			"operation": operation,
 			"action":    "DELETE",
 			"product_id": id,
 			"error":     result.Error.Error(),
@@ -223,47 +571,99 @@ func (r *ProductRepositoryImpl) DeleteProduct(id int) error {
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("DeleteProduct", "DELETE", duration)
+		r.metrics.RecordDatabaseOperation(operation, "DELETE", duration)
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
 		r.logger.WithFields(logrus.Fields{
-			"operation": "DeleteProduct",
+			"operation": operation,
 			"action":    "DELETE",
 			"product_id": id,
 			"duration_ms": duration.Milliseconds(),
 		}).Warn("Product not found for deletion")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("DeleteProduct", "DELETE", duration)
+		r.metrics.RecordDatabaseOperation(operation, "DELETE", duration)
 		return errors.New("product not found")
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("DeleteProduct", "DELETE", duration)
+	r.metrics.RecordDatabaseOperation(operation, "DELETE", duration)
 
 	r.logger.WithFields(logrus.Fields{
-		"operation": "DeleteProduct",
+		"operation": operation,
 		"action":    "DELETE",
 		"product_id": id,
 		"duration_ms": duration.Milliseconds(),
 	}).Info("Database operation completed")
 
-	external.RecordProductDeleted()
+	r.metrics.RecordProductDeleted()
 	return nil
 }
 
-// GetTopMostExpensive returns the top N most expensive products
-func (r *ProductRepositoryImpl) GetTopMostExpensive(limit int) ([]entity.Product, error) {
+// RestoreProduct clears the deletion mark of a soft-deleted product
+func (r *ProductRepositoryImpl) RestoreProduct(ctx context.Context, id int) (*entity.Product, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "RestoreProduct",
+		"product_id": id,
+	}).Debug("Database operation started")
+
+	result := r.db.WithContext(ctx).Unscoped().Model(&entity.Product{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": time.Now(),
+		})
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("RestoreProduct", "UPDATE", duration)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation": "RestoreProduct",
+			"action":    "UPDATE",
+			"product_id": id,
+			"error":     result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Distinguish between an unknown product and one that is not deleted
+		if _, err := r.GetProductByID(ctx, id); err == nil {
+			return nil, errors.New("product conflict: product is not deleted")
+		}
+		return nil, errors.New("product not found")
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation": "RestoreProduct",
+		"action":    "UPDATE",
+		"product_id": id,
+		"duration_ms": duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return r.GetProductByID(ctx, id)
+}
+
+// GetTopMostExpensive returns the top N most expensive products, optionally filtered by category
+func (r *ProductRepositoryImpl) GetTopMostExpensive(ctx context.Context, limit int, category string) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetTopMostExpensive",
 		"limit":     limit,
+		"category":  category,
 	}).Debug("Database operation started")
 
+	db := r.db.WithContext(ctx)
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+
 	var products []entity.Product
-	result := r.db.Order("price DESC").Limit(limit).Find(&products)
+	result := db.Order("price DESC").Limit(limit).Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -276,12 +676,12 @@ func (r *ProductRepositoryImpl) GetTopMostExpensive(limit int) ([]entity.Product
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("GetTopMostExpensive", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetTopMostExpensive", "SELECT", duration)
 		return nil, result.Error
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("GetTopMostExpensive", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetTopMostExpensive", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetTopMostExpensive",
@@ -295,7 +695,7 @@ func (r *ProductRepositoryImpl) GetTopMostExpensive(limit int) ([]entity.Product
 }
 
 // GetLowStockProducts returns products with stock less than or equal to maxStock
-func (r *ProductRepositoryImpl) GetLowStockProducts(maxStock int) ([]entity.Product, error) {
+func (r *ProductRepositoryImpl) GetLowStockProducts(ctx context.Context, maxStock int) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetLowStockProducts",
@@ -303,7 +703,7 @@ func (r *ProductRepositoryImpl) GetLowStockProducts(maxStock int) ([]entity.Prod
 	}).Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Where("stock <= ?", maxStock).Order("stock ASC").Find(&products)
+	result := r.db.WithContext(ctx).Where("stock <= ?", maxStock).Order("stock ASC").Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -316,12 +716,12 @@ func (r *ProductRepositoryImpl) GetLowStockProducts(maxStock int) ([]entity.Prod
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("GetLowStockProducts", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetLowStockProducts", "SELECT", duration)
 		return nil, result.Error
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("GetLowStockProducts", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetLowStockProducts", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetLowStockProducts",
@@ -334,8 +734,46 @@ func (r *ProductRepositoryImpl) GetLowStockProducts(maxStock int) ([]entity.Prod
 	return products, nil
 }
 
+// GetProductsAtLowStock returns in-stock products at or below their own low stock threshold
+func (r *ProductRepositoryImpl) GetProductsAtLowStock(ctx context.Context) ([]entity.Product, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation":         "GetProductsAtLowStock",
+		"default_threshold": r.lowStockThreshold,
+	}).Debug("Database operation started")
+
+	var products []entity.Product
+	result := r.db.WithContext(ctx).Where("stock > 0").Where(atOrBelowThreshold, r.lowStockThreshold).Order("stock ASC").Find(&products)
+	duration := time.Since(start)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "GetProductsAtLowStock",
+			"action":      "SELECT",
+			"error":       result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+
+		// Record failed database operation
+		r.metrics.RecordDatabaseOperation("GetProductsAtLowStock", "SELECT", duration)
+		return nil, result.Error
+	}
+
+	// Record successful database operation
+	r.metrics.RecordDatabaseOperation("GetProductsAtLowStock", "SELECT", duration)
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "GetProductsAtLowStock",
+		"action":       "SELECT",
+		"duration_ms":  duration.Milliseconds(),
+		"record_count": len(products),
+	}).Info("Database operation completed")
+
+	return products, nil
+}
+
 // GetProductsByCategory returns products belonging to a specific category
-func (r *ProductRepositoryImpl) GetProductsByCategory(category string) ([]entity.Product, error) {
+func (r *ProductRepositoryImpl) GetProductsByCategory(ctx context.Context, category string) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByCategory",
@@ -343,7 +781,7 @@ func (r *ProductRepositoryImpl) GetProductsByCategory(category string) ([]entity
 	}).Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Where("category = ?", category).Find(&products)
+	result := r.db.WithContext(ctx).Where("category = ?", category).Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -356,12 +794,12 @@ func (r *ProductRepositoryImpl) GetProductsByCategory(category string) ([]entity
 		}).Error("Database operation failed")
 
 		// Record failed database operation
-		external.RecordDatabaseOperation("GetProductsByCategory", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetProductsByCategory", "SELECT", duration)
 		return nil, result.Error
 	}
 
 	// Record successful database operation
-	external.RecordDatabaseOperation("GetProductsByCategory", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetProductsByCategory", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByCategory",
@@ -374,7 +812,7 @@ func (r *ProductRepositoryImpl) GetProductsByCategory(category string) ([]entity
 	return products, nil
 }
 // GetProductsByPriceRange returns products by price range
-func (r *ProductRepositoryImpl) GetProductsByPriceRange(minPrice, maxPrice float64) ([]entity.Product, error) {
+func (r *ProductRepositoryImpl) GetProductsByPriceRange(ctx context.Context, minPrice, maxPrice float64) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByPriceRange",
@@ -383,7 +821,7 @@ func (r *ProductRepositoryImpl) GetProductsByPriceRange(minPrice, maxPrice float
 	}).Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Where("price BETWEEN ? AND ?", minPrice, maxPrice).Find(&products)
+	result := r.db.WithContext(ctx).Where("price BETWEEN ? AND ?", minPrice, maxPrice).Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -396,11 +834,11 @@ func (r *ProductRepositoryImpl) GetProductsByPriceRange(minPrice, maxPrice float
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
-		external.RecordDatabaseOperation("GetProductsByPriceRange", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetProductsByPriceRange", "SELECT", duration)
 		return nil, result.Error
 	}
 
-	external.RecordDatabaseOperation("GetProductsByPriceRange", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetProductsByPriceRange", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByPriceRange",
@@ -415,7 +853,7 @@ func (r *ProductRepositoryImpl) GetProductsByPriceRange(minPrice, maxPrice float
 }
 
 // GetProductsByName returns products by name
-func (r *ProductRepositoryImpl) GetProductsByName(name string) ([]entity.Product, error) {
+func (r *ProductRepositoryImpl) GetProductsByName(ctx context.Context, name string) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByName",
@@ -423,7 +861,7 @@ func (r *ProductRepositoryImpl) GetProductsByName(name string) ([]entity.Product
 	}).Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Where("name ILIKE ?", "%"+name+"%").Find(&products)
+	result := r.db.WithContext(ctx).Where("name ILIKE ?", "%"+name+"%").Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -435,11 +873,11 @@ func (r *ProductRepositoryImpl) GetProductsByName(name string) ([]entity.Product
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
-		external.RecordDatabaseOperation("GetProductsByName", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetProductsByName", "SELECT", duration)
 		return nil, result.Error
 	}
 
-	external.RecordDatabaseOperation("GetProductsByName", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetProductsByName", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByName",
@@ -452,45 +890,198 @@ func (r *ProductRepositoryImpl) GetProductsByName(name string) ([]entity.Product
 	return products, nil
 }
 
+// SearchProducts returns a page of products matching every set filter of the search and the total number of matches.
+// Search text is matched with PostgreSQL full-text search over name and description and ranked by relevance;
+// other dialects fall back to a case-insensitive substring match.
+func (r *ProductRepositoryImpl) SearchProducts(ctx context.Context, search entity.ProductSearch) ([]entity.ProductMatch, int64, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "SearchProducts",
+		"text":      search.Text,
+		"category":  search.Category,
+		"sort":      search.Sort,
+	}).Debug("Database operation started")
+
+	// A price range whose minimum is above its maximum matches nothing
+	if search.MinPrice != nil && search.MaxPrice != nil && *search.MinPrice > *search.MaxPrice {
+		return []entity.ProductMatch{}, 0, nil
+	}
+
+	fullText := search.Text != "" && r.supportsFullTextSearch()
+	pattern := "%" + strings.ToLower(search.Text) + "%"
+
+	order := productOrder(search.Sort)
+	if search.Text != "" && search.Sort == "" {
+		order = "score DESC, id"
+	}
+
+	// Without full-text search, name matches rank above description-only matches
+	scoreSelect, scoreArgs := "products.*, 0 AS score", []interface{}{}
+	switch {
+	case fullText:
+		scoreSelect = "products.*, ts_rank(" + productSearchVectorColumn + ", plainto_tsquery('" + productSearchConfig + "', ?)) AS score"
+		scoreArgs = append(scoreArgs, search.Text)
+	case search.Text != "":
+		scoreSelect = "products.*, CASE WHEN LOWER(name) LIKE ? THEN 1.0 ELSE 0.5 END AS score"
+		scoreArgs = append(scoreArgs, pattern)
+	}
+
+	var total int64
+	var matches []entity.ProductMatch
+	err := r.searchFilters(ctx, search, fullText).Count(&total).Error
+	if err == nil && total > int64(search.Offset) {
+		err = r.searchFilters(ctx, search, fullText).Select(scoreSelect, scoreArgs...).Order(order).Limit(search.Limit).Offset(search.Offset).Find(&matches).Error
+	}
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("SearchProducts", "SELECT", duration)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "SearchProducts",
+			"action":      "SELECT",
+			"error":       err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, 0, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "SearchProducts",
+		"action":       "SELECT",
+		"full_text":    fullText,
+		"total":        total,
+		"record_count": len(matches),
+		"duration_ms":  duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return matches, total, nil
+}
+
+// searchFilters returns a products query restricted by every set filter of the search.
+// fullText matches the search text against the search vector instead of by substring.
+func (r *ProductRepositoryImpl) searchFilters(ctx context.Context, search entity.ProductSearch, fullText bool) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&entity.Product{})
+	if fullText {
+		db = db.Where(productSearchVectorColumn+" @@ plainto_tsquery('"+productSearchConfig+"', ?)", search.Text)
+	} else if search.Text != "" {
+		pattern := "%" + strings.ToLower(search.Text) + "%"
+		db = db.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", pattern, pattern)
+	}
+	if search.Category != "" {
+		db = db.Where("category = ?", search.Category)
+	}
+	if search.MinPrice != nil {
+		db = db.Where("price >= ?", *search.MinPrice)
+	}
+	if search.MaxPrice != nil {
+		db = db.Where("price <= ?", *search.MaxPrice)
+	}
+	if search.InStockOnly {
+		db = db.Where("stock > 0")
+	}
+	return db
+}
+
+// supportsFullTextSearch reports whether the database provides the products search vector
+func (r *ProductRepositoryImpl) supportsFullTextSearch() bool {
+	return r.db.Dialector.Name() == "postgres"
+}
+
+// StreamProducts calls fn for every product, optionally limited to a category, in id order.
+// Rows are read one at a time so memory use does not grow with the catalog. An error from fn stops the stream.
+func (r *ProductRepositoryImpl) StreamProducts(ctx context.Context, category string, fn func(entity.Product) error) error {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "StreamProducts",
+		"category":  category,
+	}).Debug("Database operation started")
+
+	db := r.db.WithContext(ctx).Model(&entity.Product{})
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+
+	count := 0
+	rows, err := db.Order("id").Rows()
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var product entity.Product
+			if err = r.db.ScanRows(rows, &product); err != nil {
+				break
+			}
+			if err = fn(product); err != nil {
+				break
+			}
+			count++
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+	}
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("StreamProducts", "SELECT", duration)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":    "StreamProducts",
+			"action":       "SELECT",
+			"error":        err.Error(),
+			"record_count": count,
+			"duration_ms":  duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "StreamProducts",
+		"action":       "SELECT",
+		"record_count": count,
+		"duration_ms":  duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return nil
+}
+
 // GetProductStats returns product statistics
-func (r *ProductRepositoryImpl) GetProductStats() (*entity.ProductStats, error) {
+func (r *ProductRepositoryImpl) GetProductStats(ctx context.Context) (*entity.ProductStats, error) {
 	start := time.Now()
 	r.logger.WithField("operation", "GetProductStats").Debug("Database operation started")
 
 	var stats entity.ProductStats
 	
 	// Get total products count
-	if err := r.db.Model(&entity.Product{}).Count(&stats.TotalProducts).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Product{}).Count(&stats.TotalProducts).Error; err != nil {
 		return nil, err
 	}
 
 	// Get total categories count
-	if err := r.db.Model(&entity.Product{}).Distinct("category").Count(&stats.TotalCategories).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Product{}).Distinct("category").Count(&stats.TotalCategories).Error; err != nil {
 		return nil, err
 	}
 
 	// Get average price
-	if err := r.db.Model(&entity.Product{}).Select("AVG(price)").Scan(&stats.AveragePrice).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Product{}).Select("AVG(price)").Scan(&stats.AveragePrice).Error; err != nil {
 		return nil, err
 	}
 
 	// Get total value
-	if err := r.db.Model(&entity.Product{}).Select("SUM(price * stock)").Scan(&stats.TotalValue).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Product{}).Select("SUM(price * stock)").Scan(&stats.TotalValue).Error; err != nil {
 		return nil, err
 	}
 
 	// Get low stock products count
-	if err := r.db.Model(&entity.Product{}).Where("stock <= 10").Count(&stats.LowStockProducts).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Product{}).Where(atOrBelowThreshold, r.lowStockThreshold).Count(&stats.LowStockProducts).Error; err != nil {
 		return nil, err
 	}
 
 	// Get out of stock products count
-	if err := r.db.Model(&entity.Product{}).Where("stock = 0").Count(&stats.OutOfStockProducts).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&entity.Product{}).Where("stock = 0").Count(&stats.OutOfStockProducts).Error; err != nil {
 		return nil, err
 	}
 
 	duration := time.Since(start)
-	external.RecordDatabaseOperation("GetProductStats", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetProductStats", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductStats",
@@ -503,16 +1094,22 @@ func (r *ProductRepositoryImpl) GetProductStats() (*entity.ProductStats, error)
 	return &stats, nil
 }
 
-// GetCategories returns all categories
-func (r *ProductRepositoryImpl) GetCategories() ([]entity.Category, error) {
+// GetCategories returns all categories from the categories table, falling back
+// to distinct product categories that have not been registered yet
+func (r *ProductRepositoryImpl) GetCategories(ctx context.Context) ([]entity.Category, error) {
 	start := time.Now()
 	r.logger.WithField("operation", "GetCategories").Debug("Database operation started")
 
-	var categories []entity.Category
-	result := r.db.Model(&entity.Product{}).
+	var derived []entity.Category
+	result := r.db.WithContext(ctx).Model(&entity.Product{}).
 		Select("category as name, COUNT(*) as product_count, AVG(price) as average_price").
 		Group("category").
-		Find(&categories)
+		Find(&derived)
+
+	var managed []entity.ProductCategory
+	if result.Error == nil {
+		result = r.db.WithContext(ctx).Order("name ASC").Find(&managed)
+	}
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -523,24 +1120,50 @@ func (r *ProductRepositoryImpl) GetCategories() ([]entity.Category, error) {
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
-		external.RecordDatabaseOperation("GetCategories", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetCategories", "SELECT", duration)
 		return nil, result.Error
 	}
 
-	external.RecordDatabaseOperation("GetCategories", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetCategories", "SELECT", duration)
+
+	statsByName := make(map[string]entity.Category, len(derived))
+	for _, category := range derived {
+		statsByName[category.Name] = category
+	}
+
+	categories := make([]entity.Category, 0, len(managed)+len(derived))
+	for _, m := range managed {
+		stats := statsByName[m.Name]
+		categories = append(categories, entity.Category{
+			ID:           m.ID,
+			Name:         m.Name,
+			Description:  m.Description,
+			ProductCount: stats.ProductCount,
+			AveragePrice: stats.AveragePrice,
+		})
+		delete(statsByName, m.Name)
+	}
+
+	// Keep categories that only exist on products for compatibility
+	for _, category := range derived {
+		if _, ok := statsByName[category.Name]; ok {
+			categories = append(categories, category)
+		}
+	}
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetCategories",
 		"action":    "SELECT",
 		"duration_ms": duration.Milliseconds(),
 		"record_count": len(categories),
+		"managed_count": len(managed),
 	}).Info("Database operation completed")
 
 	return categories, nil
 }
 
 // GetProductsByStock returns products by stock level
-func (r *ProductRepositoryImpl) GetProductsByStock(stock int) ([]entity.Product, error) {
+func (r *ProductRepositoryImpl) GetProductsByStock(ctx context.Context, stock int) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByStock",
@@ -548,7 +1171,7 @@ func (r *ProductRepositoryImpl) GetProductsByStock(stock int) ([]entity.Product,
 	}).Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Where("stock = ?", stock).Find(&products)
+	result := r.db.WithContext(ctx).Where("stock = ?", stock).Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -560,11 +1183,11 @@ func (r *ProductRepositoryImpl) GetProductsByStock(stock int) ([]entity.Product,
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
-		external.RecordDatabaseOperation("GetProductsByStock", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetProductsByStock", "SELECT", duration)
 		return nil, result.Error
 	}
 
-	external.RecordDatabaseOperation("GetProductsByStock", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetProductsByStock", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByStock",
@@ -577,32 +1200,52 @@ func (r *ProductRepositoryImpl) GetProductsByStock(stock int) ([]entity.Product,
 	return products, nil
 }
 
-// GetRandomProducts returns random products
-func (r *ProductRepositoryImpl) GetRandomProducts(count int) ([]entity.Product, error) {
+// Random product sampling limits
+const (
+	// maxRandomProducts caps how many random products one call returns
+	maxRandomProducts = 50
+	// randomSampleMinRows is the estimated table size from which products are sampled with
+	// TABLESAMPLE instead of sorting the whole table by RANDOM()
+	randomSampleMinRows = 10000
+	// randomSampleOversample is how many more rows than requested the sample aims for, so
+	// soft-deleted rows and sampling variance rarely leave it short
+	randomSampleOversample = 4
+)
+
+// GetRandomProducts returns up to count random products. Small tables are sorted by RANDOM();
+// large ones are sampled first so the sort only covers the sample, falling back to the full
+// sort when the sample comes up short.
+func (r *ProductRepositoryImpl) GetRandomProducts(ctx context.Context, count int) ([]entity.Product, error) {
+	if count > maxRandomProducts {
+		count = maxRandomProducts
+	}
+
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetRandomProducts",
 		"count":     count,
 	}).Debug("Database operation started")
 
-	var products []entity.Product
-	result := r.db.Order("RANDOM()").Limit(count).Find(&products)
+	products, err := r.sampleRandomProducts(ctx, count)
+	if err == nil && len(products) < count {
+		products, err = r.sortRandomProducts(ctx, count)
+	}
 	duration := time.Since(start)
 
-	if result.Error != nil {
+	if err != nil {
 		r.logger.WithFields(logrus.Fields{
 			"operation": "GetRandomProducts",
 			"action":    "SELECT",
 			"count":     count,
-			"error":     result.Error.Error(),
+			"error":     err.Error(),
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
-		external.RecordDatabaseOperation("GetRandomProducts", "SELECT", duration)
-		return nil, result.Error
+		r.metrics.RecordDatabaseOperation("GetRandomProducts", "SELECT", duration)
+		return nil, err
 	}
 
-	external.RecordDatabaseOperation("GetRandomProducts", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetRandomProducts", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetRandomProducts",
@@ -615,8 +1258,34 @@ func (r *ProductRepositoryImpl) GetRandomProducts(count int) ([]entity.Product,
 	return products, nil
 }
 
-// GetProductsByDateRange returns products by date range
-func (r *ProductRepositoryImpl) GetProductsByDateRange(startDate, endDate string) ([]entity.Product, error) {
+// sampleRandomProducts selects count random products from a TABLESAMPLE of the products table
+// when the planner estimates it holds at least randomSampleMinRows rows. Smaller tables, or a
+// failed estimate, yield no products so the caller sorts the whole table instead.
+func (r *ProductRepositoryImpl) sampleRandomProducts(ctx context.Context, count int) ([]entity.Product, error) {
+	var estimate float64
+	err := r.db.WithContext(ctx).Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", "products").Scan(&estimate).Error
+	if err != nil || estimate < randomSampleMinRows {
+		return nil, nil
+	}
+
+	percent := float64(count*randomSampleOversample) * 100 / estimate
+	var products []entity.Product
+	err = r.db.WithContext(ctx).Table("products TABLESAMPLE BERNOULLI (?)", percent).
+		Order("RANDOM()").
+		Limit(count).
+		Find(&products).Error
+	return products, err
+}
+
+// sortRandomProducts selects count random products by sorting the whole products table by RANDOM()
+func (r *ProductRepositoryImpl) sortRandomProducts(ctx context.Context, count int) ([]entity.Product, error) {
+	var products []entity.Product
+	err := r.db.WithContext(ctx).Order("RANDOM()").Limit(count).Find(&products).Error
+	return products, err
+}
+
+// GetProductsByDateRange returns products created within an inclusive date range
+func (r *ProductRepositoryImpl) GetProductsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]entity.Product, error) {
 	start := time.Now()
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByDateRange",
@@ -625,7 +1294,7 @@ func (r *ProductRepositoryImpl) GetProductsByDateRange(startDate, endDate string
 	}).Debug("Database operation started")
 
 	var products []entity.Product
-	result := r.db.Where("created_at BETWEEN ? AND ?", startDate, endDate).Find(&products)
+	result := r.db.WithContext(ctx).Where("created_at BETWEEN ? AND ?", startDate, endDate).Find(&products)
 	duration := time.Since(start)
 
 	if result.Error != nil {
@@ -638,11 +1307,11 @@ func (r *ProductRepositoryImpl) GetProductsByDateRange(startDate, endDate string
 			"duration_ms": duration.Milliseconds(),
 		}).Error("Database operation failed")
 
-		external.RecordDatabaseOperation("GetProductsByDateRange", "SELECT", duration)
+		r.metrics.RecordDatabaseOperation("GetProductsByDateRange", "SELECT", duration)
 		return nil, result.Error
 	}
 
-	external.RecordDatabaseOperation("GetProductsByDateRange", "SELECT", duration)
+	r.metrics.RecordDatabaseOperation("GetProductsByDateRange", "SELECT", duration)
 
 	r.logger.WithFields(logrus.Fields{
 		"operation": "GetProductsByDateRange",
@@ -655,3 +1324,12 @@ func (r *ProductRepositoryImpl) GetProductsByDateRange(startDate, endDate string
 
 	return products, nil
 }
+
+// Ping checks database connectivity
+func (r *ProductRepositoryImpl) Ping() error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
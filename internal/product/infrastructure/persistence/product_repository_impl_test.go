@@ -0,0 +1,150 @@
+package persistence
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"obs-tools-usage/internal/product/domain/entity"
+)
+
+// newDryRunRepository returns a repository whose queries are built but never sent to a database
+func newDryRunRepository(t *testing.T) *ProductRepositoryImpl {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=products"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	return &ProductRepositoryImpl{db: db}
+}
+
+// newUnreachableRepository returns a repository whose every query fails to connect
+func newUnreachableRepository(t *testing.T) *ProductRepositoryImpl {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 dbname=products connect_timeout=1"}), &gorm.Config{
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &ProductRepositoryImpl{db: db, logger: logrus.NewEntry(logger)}
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestSearchFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		search   entity.ProductSearch
+		fullText bool
+		clauses  []string
+		vars     []interface{}
+	}{
+		{
+			name:   "empty filters match every product",
+			search: entity.ProductSearch{},
+		},
+		{
+			name:    "category only",
+			search:  entity.ProductSearch{Category: "electronics"},
+			clauses: []string{"category = $1"},
+			vars:    []interface{}{"electronics"},
+		},
+		{
+			name:    "price range",
+			search:  entity.ProductSearch{MinPrice: float64Ptr(10), MaxPrice: float64Ptr(100)},
+			clauses: []string{"price >= $1", "price <= $2"},
+			vars:    []interface{}{10.0, 100.0},
+		},
+		{
+			name:    "substring text with category, maximum price and stock",
+			search:  entity.ProductSearch{Text: "Pro", Category: "laptops", MaxPrice: float64Ptr(1000), InStockOnly: true},
+			clauses: []string{"(LOWER(name) LIKE $1 OR LOWER(description) LIKE $2)", "category = $3", "price <= $4", "stock > 0"},
+			vars:    []interface{}{"%pro%", "%pro%", "laptops", 1000.0},
+		},
+		{
+			name:     "full-text search with minimum price",
+			search:   entity.ProductSearch{Text: "wireless mouse", MinPrice: float64Ptr(5)},
+			fullText: true,
+			clauses:  []string{productSearchVectorColumn + " @@ plainto_tsquery('" + productSearchConfig + "', $1)", "price >= $2"},
+			vars:     []interface{}{"wireless mouse", 5.0},
+		},
+	}
+
+	repo := newDryRunRepository(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := repo.searchFilters(context.Background(), tt.search, tt.fullText).Find(&[]entity.Product{}).Statement
+			sql := stmt.SQL.String()
+
+			// Only the soft delete condition applies without filters
+			want := `WHERE "products"."deleted_at" IS NULL`
+			if len(tt.clauses) > 0 {
+				want = "WHERE " + strings.Join(tt.clauses, " AND ") + ` AND "products"."deleted_at" IS NULL`
+			}
+			if !strings.HasSuffix(sql, want) {
+				t.Errorf("SQL = %q, want it to end with %q", sql, want)
+			}
+
+			if len(stmt.Vars) != len(tt.vars) {
+				t.Fatalf("vars = %v, want %v", stmt.Vars, tt.vars)
+			}
+			for i, v := range tt.vars {
+				if stmt.Vars[i] != v {
+					t.Errorf("var %d = %v, want %v", i, stmt.Vars[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchProductsContradictoryPriceRange(t *testing.T) {
+	// The database is unreachable, so only a search that never queries it succeeds
+	repo := newUnreachableRepository(t)
+
+	matches, total, err := repo.SearchProducts(context.Background(), entity.ProductSearch{
+		Category: "laptops",
+		MinPrice: float64Ptr(500),
+		MaxPrice: float64Ptr(100),
+		Limit:    20,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 0 || len(matches) != 0 {
+		t.Errorf("got %d matches of %d, want none", len(matches), total)
+	}
+	if matches == nil {
+		t.Error("matches is nil, want an empty slice")
+	}
+}
+
+func TestFindCategoryForUpdateLocksTheRow(t *testing.T) {
+	r := newDryRunRepository(t)
+	var statement string
+	if err := r.db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		statement = db.Statement.SQL.String()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var category entity.ProductCategory
+	if _, err := findCategoryForUpdate(r.db, "electronics", &category); err != nil {
+		t.Fatalf("findCategoryForUpdate: %v", err)
+	}
+	if !strings.HasSuffix(statement, "FOR UPDATE") {
+		t.Errorf("query %q does not lock the category row", statement)
+	}
+}
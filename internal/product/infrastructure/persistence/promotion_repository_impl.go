@@ -0,0 +1,199 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"obs-tools-usage/internal/product/domain/entity"
+)
+
+// CreatePromotion stores a new promotion
+func (r *ProductRepositoryImpl) CreatePromotion(ctx context.Context, promotion entity.Promotion) (*entity.Promotion, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "CreatePromotion",
+		"title":     promotion.Title,
+	}).Debug("Database operation started")
+
+	result := r.db.WithContext(ctx).Create(&promotion)
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("CreatePromotion", "INSERT", duration)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "CreatePromotion",
+			"action":      "INSERT",
+			"title":       promotion.Title,
+			"error":       result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "CreatePromotion",
+		"action":       "INSERT",
+		"promotion_id": promotion.ID,
+		"start_date":   promotion.StartDate,
+		"duration_ms":  duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return &promotion, nil
+}
+
+// GetPromotionByID returns a promotion by its ID
+func (r *ProductRepositoryImpl) GetPromotionByID(ctx context.Context, id int) (*entity.Promotion, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "GetPromotionByID",
+		"promotion_id": id,
+	}).Debug("Database operation started")
+
+	var promotion entity.Promotion
+	result := r.db.WithContext(ctx).First(&promotion, id)
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("GetPromotionByID", "SELECT", duration)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+
+		r.logger.WithFields(logrus.Fields{
+			"operation":    "GetPromotionByID",
+			"action":       "SELECT",
+			"promotion_id": id,
+			"error":        result.Error.Error(),
+			"duration_ms":  duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+
+	return &promotion, nil
+}
+
+// GetPromotionsPage returns a page of promotions, latest start first, with the total number of promotions
+func (r *ProductRepositoryImpl) GetPromotionsPage(ctx context.Context, limit, offset int) ([]entity.Promotion, int64, error) {
+	start := time.Now()
+	r.logger.WithFields(logrus.Fields{
+		"operation": "GetPromotionsPage",
+		"limit":     limit,
+		"offset":    offset,
+	}).Debug("Database operation started")
+
+	var total int64
+	var promotions []entity.Promotion
+	err := r.db.WithContext(ctx).Model(&entity.Promotion{}).Count(&total).Error
+	if err == nil {
+		err = r.db.WithContext(ctx).Order("start_date DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&promotions).Error
+	}
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("GetPromotionsPage", "SELECT", duration)
+
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "GetPromotionsPage",
+			"action":      "SELECT",
+			"error":       err.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, 0, err
+	}
+
+	return promotions, total, nil
+}
+
+// GetDuePromotions returns up to limit unpublished promotions that have started and not yet ended at now,
+// earliest start first
+func (r *ProductRepositoryImpl) GetDuePromotions(ctx context.Context, now time.Time, limit int) ([]entity.Promotion, error) {
+	start := time.Now()
+
+	var promotions []entity.Promotion
+	result := r.db.WithContext(ctx).
+		Where("published_at IS NULL AND start_date <= ? AND end_date > ?", now, now).
+		Order("start_date").Order("id").
+		Limit(limit).
+		Find(&promotions)
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("GetDuePromotions", "SELECT", duration)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "GetDuePromotions",
+			"action":      "SELECT",
+			"error":       result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+
+	return promotions, nil
+}
+
+// GetNextPromotionStart returns the earliest start after the given time of an unpublished promotion,
+// or nil when none is scheduled
+func (r *ProductRepositoryImpl) GetNextPromotionStart(ctx context.Context, after time.Time) (*time.Time, error) {
+	start := time.Now()
+
+	var promotion entity.Promotion
+	result := r.db.WithContext(ctx).
+		Select("start_date").
+		Where("published_at IS NULL AND start_date > ?", after).
+		Order("start_date").
+		Limit(1).
+		Find(&promotion)
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("GetNextPromotionStart", "SELECT", duration)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":   "GetNextPromotionStart",
+			"action":      "SELECT",
+			"error":       result.Error.Error(),
+			"duration_ms": duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &promotion.StartDate, nil
+}
+
+// MarkPromotionPublished records that a promotion's PromotionCreatedEvent was published
+func (r *ProductRepositoryImpl) MarkPromotionPublished(ctx context.Context, id int, publishedAt time.Time) error {
+	start := time.Now()
+
+	result := r.db.WithContext(ctx).Model(&entity.Promotion{}).
+		Where("id = ? AND published_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"published_at": publishedAt,
+			"updated_at":   time.Now(),
+		})
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseOperation("MarkPromotionPublished", "UPDATE", duration)
+
+	if result.Error != nil {
+		r.logger.WithFields(logrus.Fields{
+			"operation":    "MarkPromotionPublished",
+			"action":       "UPDATE",
+			"promotion_id": id,
+			"error":        result.Error.Error(),
+			"duration_ms":  duration.Milliseconds(),
+		}).Error("Database operation failed")
+		return result.Error
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"operation":    "MarkPromotionPublished",
+		"action":       "UPDATE",
+		"promotion_id": id,
+		"duration_ms":  duration.Milliseconds(),
+	}).Info("Database operation completed")
+
+	return nil
+}
@@ -4,15 +4,23 @@
 package main
 
 import (
+	"context"
+
 	"obs-tools-usage/internal/product/application/handler"
 	"obs-tools-usage/internal/product/application/usecase"
 	"obs-tools-usage/internal/product/domain/repository"
+	"obs-tools-usage/internal/product/infrastructure/cache"
 	"obs-tools-usage/internal/product/infrastructure/config"
+	"obs-tools-usage/internal/product/infrastructure/external"
 	"obs-tools-usage/internal/product/infrastructure/persistence"
 	"obs-tools-usage/internal/product/interfaces/grpc"
 	"obs-tools-usage/internal/product/interfaces/http"
 
+	"obs-tools-usage/pkg/grpclimits"
+	"obs-tools-usage/pkg/health"
+
 	"github.com/google/wire"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -25,10 +33,16 @@ var ProviderSet = wire.NewSet(
 	// Database
 	NewDatabaseProvider,
 
+	// Metrics
+	NewMetricsProvider,
+
 	// Repository
 	NewProductRepositoryProvider,
 
 	// Use Case
+	NewCacheConfigProvider,
+	NewCategoryCacheProvider,
+	NewValidationConfigProvider,
 	usecase.NewProductUseCase,
 
 	// Handlers
@@ -36,11 +50,12 @@ var ProviderSet = wire.NewSet(
 	handler.NewQueryHandler,
 
 	// HTTP
+	NewHealthCheckerProvider,
 	http.NewHandler,
 	http.SetupRoutes,
 
 	// gRPC
-	grpc.NewGRPCServer,
+	NewGRPCServerProvider,
 )
 
 // DatabaseProvider provides database connection
@@ -48,17 +63,48 @@ func NewDatabaseProvider(cfg *config.Config) (*persistence.Database, error) {
 	return persistence.NewDatabase(&cfg.Database)
 }
 
+// MetricsProvider provides the service metrics, registered with the registry served on /metrics
+func NewMetricsProvider() *external.Metrics {
+	return external.NewMetrics(external.GetPrometheusMetrics())
+}
+
+// CacheConfigProvider provides the read cache configuration
+func NewCacheConfigProvider(cfg *config.Config) config.CacheConfig {
+	return cfg.Cache
+}
+
+// CategoryCacheProvider provides the cache of product listings by category on the configured backend
+func NewCategoryCacheProvider(cfg config.CacheConfig, metrics *external.Metrics, logger *logrus.Logger) (cache.ProductListCache, error) {
+	return cache.NewCategoryCache(cfg, metrics, logger)
+}
+
+// ValidationConfigProvider provides the product validation configuration
+func NewValidationConfigProvider(cfg *config.Config) config.ValidationConfig {
+	return cfg.Validation
+}
+
 // ProductRepositoryProvider provides product repository
-func NewProductRepositoryProvider(db *gorm.DB) repository.ProductRepository {
-	return persistence.NewProductRepositoryImpl(db)
+func NewProductRepositoryProvider(db *gorm.DB, cfg *config.Config, metrics *external.Metrics) repository.ProductRepository {
+	return persistence.NewProductRepositoryImpl(db, cfg.Stock.LowStockThreshold, metrics)
 }
 
 // HTTPHandlerProvider provides HTTP handler
 func NewHTTPHandlerProvider(
 	commandHandler *handler.CommandHandler,
 	queryHandler *handler.QueryHandler,
+	checker *health.Checker,
+	cfg *config.Config,
 ) *http.Handler {
-	return http.NewHandler(commandHandler, queryHandler)
+	return http.NewHandler(commandHandler, queryHandler, checker, cfg.MaxPageSize)
+}
+
+// HealthCheckerProvider provides the health checker backing /health and /ready
+func NewHealthCheckerProvider(productRepo repository.ProductRepository) *health.Checker {
+	checker := health.NewChecker("product-service", "1.0.0")
+	checker.AddReadinessCheck("database", func(ctx context.Context) error {
+		return productRepo.Ping()
+	})
+	return checker
 }
 
 // GRPCServerProvider provides gRPC server
@@ -66,6 +112,15 @@ func NewGRPCServerProvider(
 	commandHandler *handler.CommandHandler,
 	queryHandler *handler.QueryHandler,
 	productRepo repository.ProductRepository,
+	cfg *config.Config,
 ) *grpc.GRPCServer {
-	return grpc.NewGRPCServer(commandHandler, queryHandler, productRepo)
+	return grpc.NewGRPCServer(commandHandler, queryHandler, productRepo, cfg.EnableGRPCReflection, cfg.GRPCTLS, grpclimits.Config{
+		MaxConcurrentStreams: uint32(cfg.GRPCLimits.MaxConcurrentStreams),
+		MaxRecvMsgSize:       cfg.GRPCLimits.MaxRecvMsgSize,
+		KeepaliveTime:        cfg.GRPCLimits.KeepaliveTime,
+		KeepaliveTimeout:     cfg.GRPCLimits.KeepaliveTimeout,
+		MaxConnectionIdle:    cfg.GRPCLimits.MaxConnectionIdle,
+		MinPingInterval:      cfg.GRPCLimits.MinPingInterval,
+		PermitWithoutStream:  cfg.GRPCLimits.PermitWithoutStream,
+	})
 }
@@ -8,7 +8,9 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	"obs-tools-usage/internal/product/application/command"
 	"obs-tools-usage/internal/product/application/handler"
@@ -17,6 +19,10 @@ import (
 	"obs-tools-usage/internal/product/domain/repository"
 	"obs-tools-usage/internal/product/infrastructure/config"
 	"obs-tools-usage/internal/product/infrastructure/external"
+	"obs-tools-usage/pkg/grpclimits"
+	"obs-tools-usage/pkg/grpctls"
+	"obs-tools-usage/pkg/interceptor"
+	"obs-tools-usage/pkg/tracing"
 
 	pb "obs-tools-usage/api/proto/product"
 )
@@ -29,32 +35,63 @@ type GRPCServer struct {
 	repository     repository.ProductRepository
 	logger         *logrus.Logger
 	grpcServer     *grpc.Server
+	// stopping is closed on Stop so open product watches end and graceful stop can complete
+	stopping chan struct{}
+	// enableReflection registers the reflection service on Start
+	enableReflection bool
+	// tlsConfig holds the certificates of mutual TLS with clients, loaded on Start
+	tlsConfig grpctls.Config
+	// limits holds the keepalive, stream and message size limits enforced on clients
+	limits grpclimits.Config
 }
 
-// NewGRPCServer creates a new gRPC server instance
+// NewGRPCServer creates a new gRPC server instance.
+// enableReflection registers the gRPC reflection service so grpcurl can introspect it.
 func NewGRPCServer(
 	commandHandler *handler.CommandHandler,
 	queryHandler *handler.QueryHandler,
 	repository repository.ProductRepository,
+	enableReflection bool,
+	tlsConfig grpctls.Config,
+	limits grpclimits.Config,
 ) *GRPCServer {
 	return &GRPCServer{
-		commandHandler: commandHandler,
-		queryHandler:   queryHandler,
-		repository:     repository,
-		logger:         config.GetLogger(),
+		commandHandler:   commandHandler,
+		queryHandler:     queryHandler,
+		repository:       repository,
+		logger:           config.GetLogger(),
+		stopping:         make(chan struct{}),
+		enableReflection: enableReflection,
+		tlsConfig:        tlsConfig,
+		limits:           limits,
 	}
 }
 
 // Start starts the gRPC server
 func (s *GRPCServer) Start(port int) error {
+	creds, err := s.tlsConfig.ServerCredentials()
+	if err != nil {
+		return err
+	}
+
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	s.grpcServer = grpc.NewServer()
+	opts := append([]grpc.ServerOption{
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor()),
+		grpc.ChainUnaryInterceptor(interceptor.UnaryServerInterceptors(s.logger)...),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor()),
+	}, s.limits.ServerOptions()...)
+	s.grpcServer = grpc.NewServer(opts...)
+	s.logger.WithFields(s.limits.LogFields()).Info("gRPC server limits applied")
 	pb.RegisterProductServiceServer(s.grpcServer, s)
-	reflection.Register(s.grpcServer) // Enable reflection for grpcurl
+	if s.enableReflection {
+		reflection.Register(s.grpcServer) // Enable reflection for grpcurl
+		s.logger.Info("gRPC reflection enabled")
+	}
 
 	s.logger.WithField("port", port).Info("Starting gRPC server")
 	if err := s.grpcServer.Serve(lis); err != nil {
@@ -66,6 +103,7 @@ func (s *GRPCServer) Start(port int) error {
 // Stop stops the gRPC server
 func (s *GRPCServer) Stop() {
 	s.logger.Info("Stopping gRPC server...")
+	close(s.stopping)
 	s.grpcServer.GracefulStop()
 	s.logger.Info("gRPC server stopped")
 }
@@ -74,7 +112,7 @@ func (s *GRPCServer) Stop() {
 func (s *GRPCServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.ProductResponse, error) {
 	s.logger.WithField("product_id", req.Id).Debug("GetProduct gRPC request")
 
-	product, err := s.queryHandler.HandleGetProduct(query.GetProductQuery{ID: int(req.Id)})
+	product, err := s.queryHandler.HandleGetProduct(ctx, query.GetProductQuery{ID: int(req.Id)})
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get product")
 		return nil, err
@@ -101,7 +139,7 @@ func (s *GRPCServer) CreateProduct(ctx context.Context, req *pb.CreateProductReq
 		Category:    req.Category,
 	}
 
-	createdProduct, err := s.commandHandler.HandleCreateProduct(cmd)
+	createdProduct, err := s.commandHandler.HandleCreateProduct(ctx, cmd)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to create product")
 		return nil, err
@@ -125,7 +163,7 @@ func (s *GRPCServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductReq
 	}).Debug("UpdateProduct gRPC request")
 
 	// Get old product for logging
-	oldProduct, _ := s.repository.GetProductByID(int(req.Id))
+	oldProduct, _ := s.repository.GetProductByID(ctx, int(req.Id))
 
 	cmd := command.UpdateProductCommand{
 		ID:          int(req.Id),
@@ -136,7 +174,7 @@ func (s *GRPCServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductReq
 		Category:    req.Category,
 	}
 
-	updatedProduct, err := s.commandHandler.HandleUpdateProduct(cmd)
+	updatedProduct, err := s.commandHandler.HandleUpdateProduct(ctx, cmd)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to update product")
 		return nil, err
@@ -157,9 +195,9 @@ func (s *GRPCServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductReq
 	s.logger.WithField("product_id", req.Id).Debug("DeleteProduct gRPC request")
 
 	// Get product before deletion for logging
-	product, _ := s.repository.GetProductByID(int(req.Id))
+	product, _ := s.repository.GetProductByID(ctx, int(req.Id))
 
-	err := s.commandHandler.HandleDeleteProduct(command.DeleteProductCommand{ID: int(req.Id)})
+	err := s.commandHandler.HandleDeleteProduct(ctx, command.DeleteProductCommand{ID: int(req.Id)})
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to delete product")
 		return nil, err
@@ -179,7 +217,7 @@ func (s *GRPCServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductReq
 func (s *GRPCServer) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
 	s.logger.Debug("ListProducts gRPC request")
 
-	products, err := s.queryHandler.HandleGetProducts(query.GetProductsQuery{})
+	products, err := s.queryHandler.HandleGetProducts(ctx, query.GetProductsQuery{})
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to list products")
 		return nil, err
@@ -199,7 +237,7 @@ func (s *GRPCServer) ListProducts(ctx context.Context, req *pb.ListProductsReque
 func (s *GRPCServer) GetTopMostExpensiveProducts(ctx context.Context, req *pb.GetTopMostExpensiveProductsRequest) (*pb.ListProductsResponse, error) {
 	s.logger.WithField("limit", req.Limit).Debug("GetTopMostExpensiveProducts gRPC request")
 
-	products, err := s.queryHandler.HandleGetTopMostExpensive(query.GetTopMostExpensiveQuery{Limit: int(req.Limit)})
+	products, err := s.queryHandler.HandleGetTopMostExpensive(ctx, query.GetTopMostExpensiveQuery{Limit: int(req.Limit)})
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get top most expensive products")
 		return nil, err
@@ -219,7 +257,7 @@ func (s *GRPCServer) GetTopMostExpensiveProducts(ctx context.Context, req *pb.Ge
 func (s *GRPCServer) GetLowStockProducts(ctx context.Context, req *pb.GetLowStockProductsRequest) (*pb.ListProductsResponse, error) {
 	s.logger.WithField("max_stock", req.MaxStock).Debug("GetLowStockProducts gRPC request")
 
-	products, err := s.queryHandler.HandleGetLowStockProducts(query.GetLowStockProductsQuery{MaxStock: int(req.MaxStock)})
+	products, err := s.queryHandler.HandleGetLowStockProducts(ctx, query.GetLowStockProductsQuery{MaxStock: int(req.MaxStock)})
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get low stock products")
 		return nil, err
@@ -239,7 +277,7 @@ func (s *GRPCServer) GetLowStockProducts(ctx context.Context, req *pb.GetLowStoc
 func (s *GRPCServer) GetProductsByCategory(ctx context.Context, req *pb.GetProductsByCategoryRequest) (*pb.ListProductsResponse, error) {
 	s.logger.WithField("category", req.Category).Debug("GetProductsByCategory gRPC request")
 
-	products, err := s.queryHandler.HandleGetProductsByCategory(query.GetProductsByCategoryQuery{Category: req.Category})
+	products, err := s.queryHandler.HandleGetProductsByCategory(ctx, query.GetProductsByCategoryQuery{Category: req.Category})
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get products by category")
 		return nil, err
@@ -255,6 +293,69 @@ func (s *GRPCServer) GetProductsByCategory(ctx context.Context, req *pb.GetProdu
 	}, nil
 }
 
+// WatchProducts implements the WatchProducts gRPC method, streaming product changes until the client
+// disconnects. With include_snapshot every current product is sent first; the watch is opened before
+// the snapshot is read so no change is missed, though a product changed meanwhile may be sent twice.
+func (s *GRPCServer) WatchProducts(req *pb.WatchProductsRequest, stream pb.ProductService_WatchProductsServer) error {
+	s.logger.WithFields(logrus.Fields{
+		"category":         req.Category,
+		"include_snapshot": req.IncludeSnapshot,
+	}).Debug("WatchProducts gRPC request")
+
+	watch := s.queryHandler.HandleWatchProducts(query.WatchProductsQuery{Category: req.Category})
+	defer watch.Close()
+
+	if req.IncludeSnapshot {
+		snapshotAt := time.Now().Format(time.RFC3339)
+		err := s.queryHandler.HandleExportProducts(stream.Context(), query.ExportProductsQuery{Category: req.Category}, func(product entity.Product) error {
+			return stream.Send(&pb.ProductEvent{
+				Type:       pb.ProductEventType_PRODUCT_EVENT_TYPE_SNAPSHOT,
+				Product:    s.productToProto(&product),
+				OccurredAt: snapshotAt,
+			})
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to send product snapshot")
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case change, ok := <-watch.Changes():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell behind product changes and was dropped; reconnect to resume")
+			}
+			if err := stream.Send(s.productChangeToProto(change)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// productChangeToProto converts a product change to a protobuf ProductEvent message
+func (s *GRPCServer) productChangeToProto(change entity.ProductChange) *pb.ProductEvent {
+	eventType := pb.ProductEventType_PRODUCT_EVENT_TYPE_UNSPECIFIED
+	switch change.Type {
+	case entity.ProductChangeCreated:
+		eventType = pb.ProductEventType_PRODUCT_EVENT_TYPE_CREATED
+	case entity.ProductChangeUpdated:
+		eventType = pb.ProductEventType_PRODUCT_EVENT_TYPE_UPDATED
+	case entity.ProductChangeDeleted:
+		eventType = pb.ProductEventType_PRODUCT_EVENT_TYPE_DELETED
+	}
+
+	return &pb.ProductEvent{
+		Type:       eventType,
+		Product:    s.productToProto(&change.Product),
+		OccurredAt: change.OccurredAt.Format(time.RFC3339),
+	}
+}
+
 // productToProto converts an internal Product model to a protobuf Product message
 func (s *GRPCServer) productToProto(p *entity.Product) *pb.Product {
 	return &pb.Product{
@@ -1,16 +1,43 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"obs-tools-usage/internal/product/domain/service"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/respond"
 )
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
+var registerFieldNamesOnce sync.Once
+
+// registerFieldNames makes binding errors name fields by their JSON name, matching the
+// field names of domain validation errors. Registration happens once.
+func registerFieldNames() {
+	registerFieldNamesOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" || name == "" {
+				return field.Name
+			}
+			return name
+		})
+	})
+}
+
+// respondValidationError writes a 400 listing the invalid fields
+func respondValidationError(c *gin.Context, message string, fields []service.FieldError) {
+	respond.ErrorWithDetails(c, http.StatusBadRequest, respond.CodeValidation, message, gin.H{"fields": fields})
 }
 
 // HealthResponse represents a health check response
@@ -27,6 +54,12 @@ func HandleError(c *gin.Context, err error) {
 		return
 	}
 
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		respondValidationError(c, validationErr.Error(), validationErr.Fields)
+		return
+	}
+
 	errorMsg := err.Error()
 	statusCode := http.StatusInternalServerError
 
@@ -44,8 +77,35 @@ func HandleError(c *gin.Context, err error) {
 		statusCode = http.StatusConflict
 	}
 
-	c.JSON(statusCode, ErrorResponse{
-		Error:   http.StatusText(statusCode),
-		Message: errorMsg,
-	})
-}
\ No newline at end of file
+	respond.Error(c, statusCode, errorMsg)
+}
+
+// HandleBindError responds to a request binding failure, listing the invalid fields when
+// validation failed; other failures get the status of middleware.BindErrorStatus
+func HandleBindError(c *gin.Context, err error) {
+	var bindErrs validator.ValidationErrors
+	if errors.As(err, &bindErrs) {
+		fields := make([]service.FieldError, len(bindErrs))
+		for i, fieldErr := range bindErrs {
+			fields[i] = service.FieldError{Field: fieldErr.Field(), Message: bindingMessage(fieldErr)}
+		}
+		respondValidationError(c, err.Error(), fields)
+		return
+	}
+
+	respond.Error(c, middleware.BindErrorStatus(err), err.Error())
+}
+
+// bindingMessage returns a human readable message for a failed binding tag
+func bindingMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	default:
+		return "failed " + fieldErr.Tag() + " validation"
+	}
+}
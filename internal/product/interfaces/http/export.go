@@ -0,0 +1,166 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"obs-tools-usage/internal/product/application/query"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/pkg/respond"
+)
+
+// exportFlushInterval is how many rows are written between flushes of the export stream
+const exportFlushInterval = 100
+
+// exportColumn is a product field that can be selected in an export
+type exportColumn struct {
+	name  string
+	value func(entity.Product) interface{}
+}
+
+// exportColumns lists the exportable product fields in their default order
+var exportColumns = []exportColumn{
+	{"id", func(p entity.Product) interface{} { return p.ID }},
+	{"name", func(p entity.Product) interface{} { return p.Name }},
+	{"description", func(p entity.Product) interface{} { return p.Description }},
+	{"price", func(p entity.Product) interface{} { return p.Price }},
+	{"stock", func(p entity.Product) interface{} { return p.Stock }},
+	{"category", func(p entity.Product) interface{} { return p.Category }},
+	{"created_at", func(p entity.Product) interface{} { return p.CreatedAt }},
+	{"updated_at", func(p entity.Product) interface{} { return p.UpdatedAt }},
+}
+
+// parseExportColumns resolves a comma-separated columns parameter, defaulting to every column
+func parseExportColumns(raw string) ([]exportColumn, error) {
+	if strings.TrimSpace(raw) == "" {
+		return exportColumns, nil
+	}
+
+	var columns []exportColumn
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, column := range exportColumns {
+			if column.name == name {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+	}
+	return columns, nil
+}
+
+// formatCSVValue renders an export value as a CSV field
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ExportProducts handles GET /products/export?format=csv|ndjson&category=&columns=
+// It streams every matching product straight to the response instead of building a page in memory.
+func (h *Handler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		respond.Error(c, http.StatusBadRequest, "Format must be csv or ndjson")
+		return
+	}
+
+	columns, err := parseExportColumns(c.Query("columns"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := query.ExportProductsQuery{Category: c.Query("category")}
+
+	filename := "products." + format
+	if q.Category != "" {
+		filename = "products-" + q.Category + "." + format
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var writeRow func(entity.Product) error
+	var flush func() error
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer := csv.NewWriter(c.Writer)
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = column.name
+		}
+		// The header row stays buffered until the first flush
+		writer.Write(record)
+		writeRow = func(product entity.Product) error {
+			for i, column := range columns {
+				record[i] = formatCSVValue(column.value(product))
+			}
+			return writer.Write(record)
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case "ndjson":
+		c.Header("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(c.Writer)
+		writeRow = func(product entity.Product) error {
+			row := make(map[string]interface{}, len(columns))
+			for _, column := range columns {
+				row[column.name] = column.value(product)
+			}
+			return encoder.Encode(row)
+		}
+		flush = func() error { return nil }
+	}
+
+	rows := 0
+	err = h.queryHandler.HandleExportProducts(c.Request.Context(), q, func(product entity.Product) error {
+		if err := writeRow(product); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushInterval == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		HandleError(c, err)
+		return
+	}
+	if err != nil {
+		// Headers are already sent, so a failure mid-stream can only truncate the export
+		c.Error(err)
+		return
+	}
+
+	if err := flush(); err != nil {
+		c.Error(err)
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,33 +11,78 @@ import (
 	"obs-tools-usage/internal/product/application/dto"
 	"obs-tools-usage/internal/product/application/handler"
 	"obs-tools-usage/internal/product/application/query"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/pkg/daterange"
+	"obs-tools-usage/pkg/health"
+	"obs-tools-usage/pkg/middleware"
+	"obs-tools-usage/pkg/pagination"
+	"obs-tools-usage/pkg/respond"
+)
+
+const (
+	// defaultTopProductsLimit is used when GET /products/top is called without a limit
+	defaultTopProductsLimit = 5
+	// maxTopProductsLimit caps the limit accepted by GET /products/top
+	maxTopProductsLimit = 50
+	// defaultPriceHistoryLimit is used when GET /products/:id/price-history is called without a limit
+	defaultPriceHistoryLimit = 50
+	// maxPriceHistoryLimit caps the limit accepted by GET /products/:id/price-history
+	maxPriceHistoryLimit = 500
+	// defaultProductsPageSize is used when GET /products/search is called without a limit
+	defaultProductsPageSize = 100
 )
 
 // Handler handles HTTP requests using CQRS pattern
 type Handler struct {
 	commandHandler *handler.CommandHandler
 	queryHandler   *handler.QueryHandler
+	health         *health.Checker
+	maxPageSize    int // Largest limit accepted by the list endpoints
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler) *Handler {
+func NewHandler(commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler, checker *health.Checker, maxPageSize int) *Handler {
+	registerFieldNames()
 	return &Handler{
 		commandHandler: commandHandler,
 		queryHandler:   queryHandler,
+		health:         checker,
+		maxPageSize:    maxPageSize,
 	}
 }
 
-// GetAllProducts handles GET /products
+// GetAllProducts handles GET /products?limit=&offset=&sort=
+// Without a limit or sort every product is returned on a single page.
 func (h *Handler) GetAllProducts(c *gin.Context) {
-	products, err := h.queryHandler.HandleGetProducts(query.GetProductsQuery{})
+	limit, offset := 0, 0
+	if c.Query("limit") != "" || c.Query("sort") != "" {
+		limit, offset = pagination.Parse(c.Request.URL.Query(), h.maxPageSize, h.maxPageSize)
+	}
+
+	var err error
+	var products []entity.Product
+	var total int64
+	if limit > 0 {
+		products, total, err = h.queryHandler.HandleGetProductsPage(c.Request.Context(), query.GetProductsPageQuery{
+			Limit:  limit,
+			Offset: offset,
+			Sort:   c.Query("sort"),
+		})
+	} else {
+		products, err = h.queryHandler.HandleGetProducts(c.Request.Context(), query.GetProductsQuery{})
+		total = int64(len(products))
+		limit = len(products)
+		offset = 0
+	}
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
 	response := dto.ProductsResponse{
-		Products: make([]dto.ProductResponse, len(products)),
-		Count:    len(products),
+		Products:   make([]dto.ProductResponse, len(products)),
+		Count:      len(products),
+		Pagination: pagination.NewPage(c.Request.URL, total, limit, offset),
 	}
 
 	for i, product := range products {
@@ -52,64 +98,77 @@ func (h *Handler) GetAllProducts(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
-// GetProductByID handles GET /products/:id
+// GetProductByID handles GET /products/:id; include_deleted=true requires admin credentials
 func (h *Handler) GetProductByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid product ID",
-			Message: "Product ID must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Product ID must be a valid number")
 		return
 	}
 
-	product, err := h.queryHandler.HandleGetProduct(query.GetProductQuery{ID: id})
+	includeDeleted, err := parseBoolQuery(c, "include_deleted")
 	if err != nil {
-		HandleError(c, err)
+		respond.Error(c, http.StatusBadRequest, "include_deleted must be true or false")
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		Category:    product.Category,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+	product, err := h.queryHandler.HandleGetProduct(c.Request.Context(), query.GetProductQuery{
+		ID:             id,
+		IncludeDeleted: includeDeleted,
 	})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	response := dto.ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Stock:             product.Stock,
+		Category:          product.Category,
+		SKU:               product.SKU,
+		LowStockThreshold: product.LowStockThreshold,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
+	}
+	if product.IsDeleted() {
+		deletedAt := product.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+
+	respond.OK(c, http.StatusOK, response)
 }
 
 // CreateProduct handles POST /products
 func (h *Handler) CreateProduct(c *gin.Context) {
 	var cmd command.CreateProductCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		HandleBindError(c, err)
 		return
 	}
 
-	product, err := h.commandHandler.HandleCreateProduct(cmd)
+	product, err := h.commandHandler.HandleCreateProduct(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		Category:    product.Category,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+	respond.OK(c, http.StatusCreated, dto.ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Stock:             product.Stock,
+		Category:          product.Category,
+		SKU:               product.SKU,
+		LowStockThreshold: product.LowStockThreshold,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
 	})
 }
 
@@ -117,67 +176,257 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 func (h *Handler) UpdateProduct(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid product ID",
-			Message: "Product ID must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Product ID must be a valid number")
 		return
 	}
 
 	var cmd command.UpdateProductCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+		HandleBindError(c, err)
 		return
 	}
 
 	cmd.ID = id
+	if cmd.ChangedBy == "" {
+		cmd.ChangedBy = c.GetHeader("X-User-ID")
+	}
+
+	product, err := h.commandHandler.HandleUpdateProduct(c.Request.Context(), cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, dto.ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Stock:             product.Stock,
+		Category:          product.Category,
+		SKU:               product.SKU,
+		LowStockThreshold: product.LowStockThreshold,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
+	})
+}
+
+// UpsertProductBySKU handles PUT /products/sku/:sku
+// It responds 201 when the product was created and 200 when an existing product was updated.
+func (h *Handler) UpsertProductBySKU(c *gin.Context) {
+	var cmd command.UpsertProductBySKUCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		HandleBindError(c, err)
+		return
+	}
+
+	cmd.SKU = c.Param("sku")
+	if cmd.ChangedBy == "" {
+		cmd.ChangedBy = c.GetHeader("X-User-ID")
+	}
 
-	product, err := h.commandHandler.HandleUpdateProduct(cmd)
+	product, created, err := h.commandHandler.HandleUpsertProductBySKU(c.Request.Context(), cmd)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		Category:    product.Category,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	respond.OK(c, status, dto.ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Stock:             product.Stock,
+		Category:          product.Category,
+		SKU:               product.SKU,
+		LowStockThreshold: product.LowStockThreshold,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
 	})
 }
 
-// DeleteProduct handles DELETE /products/:id
+// DeleteProduct handles DELETE /products/:id; hard=true requires admin credentials
 func (h *Handler) DeleteProduct(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid product ID",
-			Message: "Product ID must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Product ID must be a valid number")
 		return
 	}
 
-	err = h.commandHandler.HandleDeleteProduct(command.DeleteProductCommand{ID: id})
+	hard, err := parseBoolQuery(c, "hard")
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, "hard must be true or false")
+		return
+	}
+
+	err = h.commandHandler.HandleDeleteProduct(c.Request.Context(), command.DeleteProductCommand{ID: id, Hard: hard})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Product deleted successfully",
+	message := "Product deleted successfully"
+	if hard {
+		message = "Product permanently deleted"
+	}
+
+	respond.OK(c, http.StatusOK, dto.SuccessResponse{
+		Message: message,
+	})
+}
+
+// RestoreProduct handles POST /products/:id/restore
+func (h *Handler) RestoreProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, "Product ID must be a valid number")
+		return
+	}
+
+	product, err := h.commandHandler.HandleRestoreProduct(c.Request.Context(), command.RestoreProductCommand{ID: id})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, dto.ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Stock:             product.Stock,
+		Category:          product.Category,
+		SKU:               product.SKU,
+		LowStockThreshold: product.LowStockThreshold,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
+	})
+}
+
+// parseBoolQuery parses an optional boolean query parameter, defaulting to false
+func parseBoolQuery(c *gin.Context, key string) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// adminAuthWhenQuery requires admin credentials when the boolean query parameter key is true.
+// Other requests, including malformed values the handler rejects itself, pass through.
+func adminAuthWhenQuery(adminToken, key string) gin.HandlerFunc {
+	adminAuth := middleware.AdminAuth(adminToken)
+	return func(c *gin.Context) {
+		if enabled, err := parseBoolQuery(c, key); err == nil && enabled {
+			adminAuth(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestsNoCache reports whether the request's Cache-Control header carries the no-cache directive
+func requestsNoCache(c *gin.Context) bool {
+	for _, directive := range strings.Split(c.GetHeader("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFloatQuery parses an optional numeric query parameter, returning nil when it is absent
+func parseFloatQuery(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// GetPriceHistory handles GET /products/:id/price-history
+func (h *Handler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, "Product ID must be a valid number")
+		return
+	}
+
+	limit := defaultPriceHistoryLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxPriceHistoryLimit {
+			respond.Error(c, http.StatusBadRequest, "Limit must be a number between 1 and "+strconv.Itoa(maxPriceHistoryLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	history, err := h.queryHandler.HandleGetPriceHistory(c.Request.Context(), query.GetPriceHistoryQuery{
+		ProductID: id,
+		Limit:     limit,
 	})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	response := dto.PriceHistoriesResponse{
+		ProductID: id,
+		History:   make([]dto.PriceHistoryResponse, len(history)),
+		Count:     len(history),
+	}
+	for i, entry := range history {
+		response.History[i] = dto.PriceHistoryResponse{
+			OldPrice:  entry.OldPrice,
+			NewPrice:  entry.NewPrice,
+			ChangedAt: entry.ChangedAt,
+			ChangedBy: entry.ChangedBy,
+		}
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// GetTopMostExpensive handles GET /products/top?limit=N&category=X
+func (h *Handler) GetTopMostExpensive(c *gin.Context) {
+	limit := defaultTopProductsLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxTopProductsLimit {
+			respond.Error(c, http.StatusBadRequest, "Limit must be a number between 1 and "+strconv.Itoa(maxTopProductsLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	h.respondTopMostExpensive(c, limit, c.Query("category"))
 }
 
 // GetTop5MostExpensive handles GET /products/top-5
 func (h *Handler) GetTop5MostExpensive(c *gin.Context) {
-	products, err := h.queryHandler.HandleGetTopMostExpensive(query.GetTopMostExpensiveQuery{Limit: 5})
+	h.respondTopMostExpensive(c, 5, "")
+}
+
+// GetTop10MostExpensive handles GET /products/top-10
+func (h *Handler) GetTop10MostExpensive(c *gin.Context) {
+	h.respondTopMostExpensive(c, 10, "")
+}
+
+// respondTopMostExpensive writes the top most expensive products for the given limit and category
+func (h *Handler) respondTopMostExpensive(c *gin.Context, limit int, category string) {
+	products, err := h.queryHandler.HandleGetTopMostExpensive(c.Request.Context(), query.GetTopMostExpensiveQuery{
+		Limit:    limit,
+		Category: category,
+	})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -201,12 +450,13 @@ func (h *Handler) GetTop5MostExpensive(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
-// GetTop10MostExpensive handles GET /products/top-10
-func (h *Handler) GetTop10MostExpensive(c *gin.Context) {
-	products, err := h.queryHandler.HandleGetTopMostExpensive(query.GetTopMostExpensiveQuery{Limit: 10})
+// GetProductsAtLowStock handles GET /products/low-stock.
+// Each product is compared against its own low stock threshold, or the global one when it has none.
+func (h *Handler) GetProductsAtLowStock(c *gin.Context) {
+	products, err := h.queryHandler.HandleGetProductsAtLowStock(c.Request.Context(), query.GetProductsAtLowStockQuery{})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -219,23 +469,25 @@ func (h *Handler) GetTop10MostExpensive(c *gin.Context) {
 
 	for i, product := range products {
 		response.Products[i] = dto.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
-			Category:    product.Category,
-			CreatedAt:   product.CreatedAt,
-			UpdatedAt:   product.UpdatedAt,
+			ID:                product.ID,
+			Name:              product.Name,
+			Description:       product.Description,
+			Price:             product.Price,
+			Stock:             product.Stock,
+			Category:          product.Category,
+			SKU:               product.SKU,
+			LowStockThreshold: product.LowStockThreshold,
+			CreatedAt:         product.CreatedAt,
+			UpdatedAt:         product.UpdatedAt,
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetLowStockProducts1 handles GET /products/low-stock-1
 func (h *Handler) GetLowStockProducts1(c *gin.Context) {
-	products, err := h.queryHandler.HandleGetLowStockProducts(query.GetLowStockProductsQuery{MaxStock: 1})
+	products, err := h.queryHandler.HandleGetLowStockProducts(c.Request.Context(), query.GetLowStockProductsQuery{MaxStock: 1})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -259,12 +511,12 @@ func (h *Handler) GetLowStockProducts1(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetLowStockProducts10 handles GET /products/low-stock-10
 func (h *Handler) GetLowStockProducts10(c *gin.Context) {
-	products, err := h.queryHandler.HandleGetLowStockProducts(query.GetLowStockProductsQuery{MaxStock: 10})
+	products, err := h.queryHandler.HandleGetLowStockProducts(c.Request.Context(), query.GetLowStockProductsQuery{MaxStock: 10})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -288,21 +540,18 @@ func (h *Handler) GetLowStockProducts10(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetProductsByCategory handles GET /products/category/:category
 func (h *Handler) GetProductsByCategory(c *gin.Context) {
 	category := c.Param("category")
 	if category == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid category",
-			Message: "Category parameter is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Category parameter is required")
 		return
 	}
 
-	products, err := h.queryHandler.HandleGetProductsByCategory(query.GetProductsByCategoryQuery{Category: category})
+	products, err := h.queryHandler.HandleGetProductsByCategory(c.Request.Context(), query.GetProductsByCategoryQuery{Category: category})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -326,30 +575,24 @@ func (h *Handler) GetProductsByCategory(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetProductsByPriceRange handles GET /products/price/:min/:max
 func (h *Handler) GetProductsByPriceRange(c *gin.Context) {
 	minPrice, err := strconv.ParseFloat(c.Param("min"), 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid min price",
-			Message: "Min price must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Min price must be a valid number")
 		return
 	}
 
 	maxPrice, err := strconv.ParseFloat(c.Param("max"), 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid max price",
-			Message: "Max price must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Max price must be a valid number")
 		return
 	}
 
-	products, err := h.queryHandler.HandleGetProductsByPriceRange(query.GetProductsByPriceRangeQuery{
+	products, err := h.queryHandler.HandleGetProductsByPriceRange(c.Request.Context(), query.GetProductsByPriceRangeQuery{
 		MinPrice: minPrice,
 		MaxPrice: maxPrice,
 	})
@@ -376,21 +619,18 @@ func (h *Handler) GetProductsByPriceRange(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetProductsByName handles GET /products/search/:name
 func (h *Handler) GetProductsByName(c *gin.Context) {
 	name := c.Param("name")
 	if name == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid name",
-			Message: "Name parameter is required",
-		})
+		respond.Error(c, http.StatusBadRequest, "Name parameter is required")
 		return
 	}
 
-	products, err := h.queryHandler.HandleGetProductsByName(query.GetProductsByNameQuery{Name: name})
+	products, err := h.queryHandler.HandleGetProductsByName(c.Request.Context(), query.GetProductsByNameQuery{Name: name})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -414,18 +654,88 @@ func (h *Handler) GetProductsByName(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
+}
+
+// SearchProducts handles GET /products/search, combining the optional q (full-text search
+// over name and description), category, min_price, max_price and in_stock filters with sort,
+// limit and offset. Text searches are ranked by relevance unless sort is given.
+func (h *Handler) SearchProducts(c *gin.Context) {
+	q := query.SearchProductsQuery{
+		Text:     strings.TrimSpace(c.Query("q")),
+		Category: c.Query("category"),
+		Sort:     c.Query("sort"),
+	}
+
+	var err error
+	if q.MinPrice, err = parseFloatQuery(c, "min_price"); err != nil {
+		respond.Error(c, http.StatusBadRequest, "min_price must be a valid number")
+		return
+	}
+	if q.MaxPrice, err = parseFloatQuery(c, "max_price"); err != nil {
+		respond.Error(c, http.StatusBadRequest, "max_price must be a valid number")
+		return
+	}
+	if q.InStockOnly, err = parseBoolQuery(c, "in_stock"); err != nil {
+		respond.Error(c, http.StatusBadRequest, "in_stock must be true or false")
+		return
+	}
+
+	q.Limit, q.Offset = pagination.Parse(c.Request.URL.Query(), defaultProductsPageSize, h.maxPageSize)
+
+	products, total, err := h.queryHandler.HandleSearchProducts(c.Request.Context(), q)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	response := dto.ProductsResponse{
+		Products:   make([]dto.ProductResponse, len(products)),
+		Count:      len(products),
+		Pagination: pagination.NewPage(c.Request.URL, total, q.Limit, q.Offset),
+	}
+
+	for i, product := range products {
+		response.Products[i] = dto.ProductResponse{
+			ID:          product.ID,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Stock:       product.Stock,
+			Category:    product.Category,
+			CreatedAt:   product.CreatedAt,
+			UpdatedAt:   product.UpdatedAt,
+		}
+		if q.Text != "" {
+			score := product.Score
+			response.Products[i].Score = &score
+		}
+	}
+
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetProductStats handles GET /products/stats
 func (h *Handler) GetProductStats(c *gin.Context) {
-	stats, err := h.queryHandler.HandleGetProductStats(query.GetProductStatsQuery{})
+	fresh, err := parseBoolQuery(c, "fresh")
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, "fresh must be true or false")
+		return
+	}
+
+	// Cache-Control: no-cache forces a recompute just like ?fresh=true
+	fresh = fresh || requestsNoCache(c)
+
+	stats, refreshedAt, err := h.queryHandler.HandleGetProductStats(c.Request.Context(), query.GetProductStatsQuery{Fresh: fresh})
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ProductStatsResponse{
+	// Tell clients how old the snapshot is
+	c.Header("X-Stats-Age", strconv.Itoa(int(time.Since(refreshedAt).Seconds())))
+
+	respond.OK(c, http.StatusOK, dto.ProductStatsResponse{
 		TotalProducts:     stats.TotalProducts,
 		TotalCategories:   stats.TotalCategories,
 		AveragePrice:      stats.AveragePrice,
@@ -437,7 +747,7 @@ func (h *Handler) GetProductStats(c *gin.Context) {
 
 // GetCategories handles GET /products/categories
 func (h *Handler) GetCategories(c *gin.Context) {
-	categories, err := h.queryHandler.HandleGetCategories(query.GetCategoriesQuery{})
+	categories, err := h.queryHandler.HandleGetCategories(c.Request.Context(), query.GetCategoriesQuery{})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -450,27 +760,125 @@ func (h *Handler) GetCategories(c *gin.Context) {
 
 	for i, category := range categories {
 		response.Categories[i] = dto.CategoryResponse{
-			Name:        category.Name,
+			ID:           category.ID,
+			Name:         category.Name,
+			Description:  category.Description,
 			ProductCount: category.ProductCount,
 			AveragePrice: category.AveragePrice,
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
+}
+
+// GetCategory handles GET /products/categories/:name
+func (h *Handler) GetCategory(c *gin.Context) {
+	category, err := h.queryHandler.HandleGetCategory(c.Request.Context(), query.GetCategoryQuery{Name: c.Param("name")})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, dto.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+	})
+}
+
+// CreateCategory handles POST /products/categories
+func (h *Handler) CreateCategory(c *gin.Context) {
+	var cmd command.CreateCategoryCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		HandleBindError(c, err)
+		return
+	}
+
+	category, err := h.commandHandler.HandleCreateCategory(c.Request.Context(), cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusCreated, dto.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+	})
+}
+
+// UpdateCategory handles PUT /products/categories/:name
+func (h *Handler) UpdateCategory(c *gin.Context) {
+	var cmd command.UpdateCategoryCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		HandleBindError(c, err)
+		return
+	}
+
+	cmd.Name = c.Param("name")
+
+	category, err := h.commandHandler.HandleUpdateCategory(c.Request.Context(), cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, dto.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+	})
+}
+
+// RenameCategory handles POST /products/categories/:name/rename
+func (h *Handler) RenameCategory(c *gin.Context) {
+	var cmd command.RenameCategoryCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		HandleBindError(c, err)
+		return
+	}
+
+	cmd.OldName = c.Param("name")
+
+	category, err := h.commandHandler.HandleRenameCategory(c.Request.Context(), cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, dto.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+	})
+}
+
+// DeleteCategory handles DELETE /products/categories/:name?reassign_to=
+func (h *Handler) DeleteCategory(c *gin.Context) {
+	cmd := command.DeleteCategoryCommand{
+		Name:       c.Param("name"),
+		ReassignTo: c.Query("reassign_to"),
+	}
+
+	if err := h.commandHandler.HandleDeleteCategory(c.Request.Context(), cmd); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, dto.SuccessResponse{
+		Message: "Category deleted successfully",
+	})
 }
 
 // GetProductsByStock handles GET /products/stock/:stock
 func (h *Handler) GetProductsByStock(c *gin.Context) {
 	stock, err := strconv.Atoi(c.Param("stock"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid stock",
-			Message: "Stock must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Stock must be a valid number")
 		return
 	}
 
-	products, err := h.queryHandler.HandleGetProductsByStock(query.GetProductsByStockQuery{Stock: stock})
+	products, err := h.queryHandler.HandleGetProductsByStock(c.Request.Context(), query.GetProductsByStockQuery{Stock: stock})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -494,29 +902,23 @@ func (h *Handler) GetProductsByStock(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetRandomProducts handles GET /products/random/:count
 func (h *Handler) GetRandomProducts(c *gin.Context) {
 	count, err := strconv.Atoi(c.Param("count"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid count",
-			Message: "Count must be a valid number",
-		})
+		respond.Error(c, http.StatusBadRequest, "Count must be a valid number")
 		return
 	}
 
 	if count <= 0 || count > 50 {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid count",
-			Message: "Count must be between 1 and 50",
-		})
+		respond.Error(c, http.StatusBadRequest, "Count must be between 1 and 50")
 		return
 	}
 
-	products, err := h.queryHandler.HandleGetRandomProducts(query.GetRandomProductsQuery{Count: count})
+	products, err := h.queryHandler.HandleGetRandomProducts(c.Request.Context(), query.GetRandomProductsQuery{Count: count})
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -540,25 +942,21 @@ func (h *Handler) GetRandomProducts(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
 // GetProductsByDateRange handles GET /products/created/:start/:end
+// Each bound is an RFC3339 timestamp or a YYYY-MM-DD date; an end date includes the whole day.
 func (h *Handler) GetProductsByDateRange(c *gin.Context) {
-	startDate := c.Param("start")
-	endDate := c.Param("end")
-
-	if startDate == "" || endDate == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Invalid date range",
-			Message: "Start and end dates are required",
-		})
+	dateRange, err := daterange.Parse(c.Param("start"), c.Param("end"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	products, err := h.queryHandler.HandleGetProductsByDateRange(query.GetProductsByDateRangeQuery{
-		StartDate: startDate,
-		EndDate:   endDate,
+	products, err := h.queryHandler.HandleGetProductsByDateRange(c.Request.Context(), query.GetProductsByDateRangeQuery{
+		StartDate: dateRange.Start,
+		EndDate:   dateRange.End,
 	})
 	if err != nil {
 		HandleError(c, err)
@@ -583,44 +981,80 @@ func (h *Handler) GetProductsByDateRange(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond.OK(c, http.StatusOK, response)
 }
 
-// HealthCheck handles GET /health
+// HealthCheck handles GET /health, reporting the database status
 func (h *Handler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, dto.HealthResponse{
-		Service:   "product-service",
-		Status:    "healthy",
-		Timestamp: time.Now().Format(time.RFC3339),
-		Version:   "1.0.0",
-	})
+	h.health.ServeHealth(c)
+}
+
+// ReadinessCheck handles GET /ready, reporting whether the database is reachable
+func (h *Handler) ReadinessCheck(c *gin.Context) {
+	h.health.ServeReady(c)
+}
+
+// LivenessCheck handles GET /live, reporting only that the process is running
+func (h *Handler) LivenessCheck(c *gin.Context) {
+	h.health.ServeLive(c)
 }
 
-// SetupRoutes sets up all routes
-func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler) {
-	handler := NewHandler(commandHandler, queryHandler)
+// SetupRoutes sets up all routes. The API is mounted under apiPrefix, such as /v1, and
+// served unversioned as a deprecated alias; health checks stay at the root.
+func SetupRoutes(r *gin.Engine, commandHandler *handler.CommandHandler, queryHandler *handler.QueryHandler, checker *health.Checker, maxPageSize int, adminToken string, apiPrefix string) {
+	handler := NewHandler(commandHandler, queryHandler, checker, maxPageSize)
 
+	registerProductRoutes(r.Group(apiPrefix), handler, adminToken)
+	if apiPrefix != "" {
+		// Unversioned aliases, kept working during the deprecation window
+		registerProductRoutes(r.Group("", middleware.DeprecatedAlias("", apiPrefix)), handler, adminToken)
+	}
+
+	// Health checks
+	r.GET("/health", handler.HealthCheck)
+	r.GET("/ready", handler.ReadinessCheck)
+	r.GET("/live", handler.LivenessCheck)
+}
+
+// registerProductRoutes registers the product API on routes; admin routes require adminToken
+func registerProductRoutes(routes gin.IRoutes, handler *Handler, adminToken string) {
 	// Product routes
-	r.GET("/products", handler.GetAllProducts)
-	r.GET("/products/:id", handler.GetProductByID)
-	r.POST("/products", handler.CreateProduct)
-	r.PUT("/products/:id", handler.UpdateProduct)
-	r.DELETE("/products/:id", handler.DeleteProduct)
+	routes.GET("/products", handler.GetAllProducts)
+	routes.GET("/products/:id", adminAuthWhenQuery(adminToken, "include_deleted"), handler.GetProductByID)
+	routes.POST("/products", handler.CreateProduct)
+	routes.PUT("/products/:id", handler.UpdateProduct)
+	routes.PUT("/products/sku/:sku", handler.UpsertProductBySKU)
+	routes.DELETE("/products/:id", adminAuthWhenQuery(adminToken, "hard"), handler.DeleteProduct)
+	routes.POST("/products/:id/restore", middleware.AdminAuth(adminToken), handler.RestoreProduct)
+	routes.GET("/products/:id/price-history", handler.GetPriceHistory)
 
 	// Query routes
-	r.GET("/products/top-5", handler.GetTop5MostExpensive)
-	r.GET("/products/top-10", handler.GetTop10MostExpensive)
-	r.GET("/products/low-stock-1", handler.GetLowStockProducts1)
-	r.GET("/products/low-stock-10", handler.GetLowStockProducts10)
-	r.GET("/products/category/:category", handler.GetProductsByCategory)
-	r.GET("/products/price/:min/:max", handler.GetProductsByPriceRange)
-	r.GET("/products/search/:name", handler.GetProductsByName)
-	r.GET("/products/stats", handler.GetProductStats)
-	r.GET("/products/categories", handler.GetCategories)
-	r.GET("/products/stock/:stock", handler.GetProductsByStock)
-	r.GET("/products/random/:count", handler.GetRandomProducts)
-	r.GET("/products/created/:start/:end", handler.GetProductsByDateRange)
-
-	// Health check
-	r.GET("/health", handler.HealthCheck)
-}
\ No newline at end of file
+	routes.GET("/products/top", handler.GetTopMostExpensive)
+	routes.GET("/products/top-5", handler.GetTop5MostExpensive)
+	routes.GET("/products/top-10", handler.GetTop10MostExpensive)
+	routes.GET("/products/low-stock", handler.GetProductsAtLowStock)
+	routes.GET("/products/low-stock-1", handler.GetLowStockProducts1)
+	routes.GET("/products/low-stock-10", handler.GetLowStockProducts10)
+	routes.GET("/products/category/:category", handler.GetProductsByCategory)
+	routes.GET("/products/price/:min/:max", handler.GetProductsByPriceRange)
+	routes.GET("/products/search", handler.SearchProducts)
+	routes.GET("/products/export", handler.ExportProducts)
+	routes.GET("/products/search/:name", handler.GetProductsByName)
+	routes.GET("/products/stats", handler.GetProductStats)
+	routes.GET("/products/categories", handler.GetCategories)
+	routes.GET("/products/categories/:name", handler.GetCategory)
+	routes.GET("/products/stock/:stock", handler.GetProductsByStock)
+	routes.GET("/products/random/:count", handler.GetRandomProducts)
+	routes.GET("/products/created/:start/:end", handler.GetProductsByDateRange)
+
+	// Category routes
+	routes.POST("/products/categories", handler.CreateCategory)
+	routes.PUT("/products/categories/:name", handler.UpdateCategory)
+	routes.POST("/products/categories/:name/rename", handler.RenameCategory)
+	routes.DELETE("/products/categories/:name", handler.DeleteCategory)
+
+	// Promotion routes
+	routes.GET("/products/promotions", handler.GetPromotions)
+	routes.GET("/products/promotions/:id", handler.GetPromotion)
+	routes.POST("/products/promotions", middleware.AdminAuth(adminToken), handler.CreatePromotion)
+}
@@ -74,8 +74,8 @@ func GetCorrelationIDFromContext(c *gin.Context) string {
 	return ""
 }
 
-// HTTPLoggingMiddleware logs HTTP requests and responses
-func HTTPLoggingMiddleware() gin.HandlerFunc {
+// HTTPLoggingMiddleware logs HTTP requests and responses and records them in metrics
+func HTTPLoggingMiddleware(metrics *external.Metrics) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get logger with request ID
 		logger := GetLoggerFromContext(c)
@@ -124,7 +124,7 @@ func HTTPLoggingMiddleware() gin.HandlerFunc {
 		}
 		
 		// Record Prometheus metrics
-		external.RecordHTTPRequest(
+		metrics.RecordHTTPRequest(
 			c.Request.Method,
 			c.Request.URL.Path,
 			c.Writer.Status(),
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"obs-tools-usage/internal/product/application/command"
+	"obs-tools-usage/internal/product/application/dto"
+	"obs-tools-usage/internal/product/application/query"
+	"obs-tools-usage/internal/product/domain/entity"
+	"obs-tools-usage/pkg/pagination"
+	"obs-tools-usage/pkg/respond"
+)
+
+// Promotion statuses reported by promotion responses
+const (
+	promotionStatusScheduled = "scheduled"
+	promotionStatusActive    = "active"
+	promotionStatusEnded     = "ended"
+)
+
+// CreatePromotion handles POST /products/promotions.
+// A promotion starting in the future is announced when it starts, one already running right away.
+func (h *Handler) CreatePromotion(c *gin.Context) {
+	var cmd command.CreatePromotionCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		HandleBindError(c, err)
+		return
+	}
+
+	promotion, err := h.commandHandler.HandleCreatePromotion(c.Request.Context(), cmd)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusCreated, toPromotionResponse(*promotion, time.Now()))
+}
+
+// GetPromotion handles GET /products/promotions/:id
+func (h *Handler) GetPromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, "Promotion ID must be a valid number")
+		return
+	}
+
+	promotion, err := h.queryHandler.HandleGetPromotion(c.Request.Context(), query.GetPromotionQuery{ID: id})
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	respond.OK(c, http.StatusOK, toPromotionResponse(*promotion, time.Now()))
+}
+
+// GetPromotions handles GET /products/promotions?limit=&offset=, latest start first
+func (h *Handler) GetPromotions(c *gin.Context) {
+	var q query.GetPromotionsQuery
+	q.Limit, q.Offset = pagination.Parse(c.Request.URL.Query(), defaultProductsPageSize, h.maxPageSize)
+
+	promotions, total, err := h.queryHandler.HandleGetPromotions(c.Request.Context(), q)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	now := time.Now()
+	response := dto.PromotionsResponse{
+		Promotions: make([]dto.PromotionResponse, len(promotions)),
+		Count:      len(promotions),
+		Pagination: pagination.NewPage(c.Request.URL, total, q.Limit, q.Offset),
+	}
+	for i, promotion := range promotions {
+		response.Promotions[i] = toPromotionResponse(promotion, now)
+	}
+
+	respond.OK(c, http.StatusOK, response)
+}
+
+// toPromotionResponse converts a promotion to its response, with its status at now
+func toPromotionResponse(promotion entity.Promotion, now time.Time) dto.PromotionResponse {
+	status := promotionStatusEnded
+	switch {
+	case promotion.IsActive(now):
+		status = promotionStatusActive
+	case now.Before(promotion.StartDate):
+		status = promotionStatusScheduled
+	}
+
+	return dto.PromotionResponse{
+		ID:          promotion.ID,
+		Title:       promotion.Title,
+		Description: promotion.Description,
+		Discount:    promotion.Discount,
+		StartDate:   promotion.StartDate,
+		EndDate:     promotion.EndDate,
+		Status:      status,
+		PublishedAt: promotion.PublishedAt,
+		CreatedAt:   promotion.CreatedAt,
+	}
+}
@@ -2,22 +2,37 @@ package consumer
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"obs-tools-usage/kafka/events"
 )
 
+// CoPurchaseRecorder records which products were bought together in an order
+type CoPurchaseRecorder interface {
+	RecordCoPurchase(orderID string, productIDs []int) error
+}
+
+// CheckoutItemRemover takes checked out products out of a user's basket
+type CheckoutItemRemover interface {
+	RemoveCheckedOutItems(userID, basketID string, productIDs []int) error
+}
+
 // BasketServiceEventHandler handles events for the basket service
 type BasketServiceEventHandler struct {
-	logger *logrus.Logger
-	// In a real implementation, you would inject the basket repository
-	// basketRepo repository.BasketRepository
+	logger      *logrus.Logger
+	coPurchases CoPurchaseRecorder
+	baskets     CheckoutItemRemover
 }
 
-// NewBasketServiceEventHandler creates a new basket service event handler
-func NewBasketServiceEventHandler(logger *logrus.Logger) *BasketServiceEventHandler {
+// NewBasketServiceEventHandler creates a new basket service event handler.
+// coPurchases may be nil, in which case co-purchase data is not recorded.
+// baskets may be nil, in which case basket cleared events only get logged.
+func NewBasketServiceEventHandler(logger *logrus.Logger, coPurchases CoPurchaseRecorder, baskets CheckoutItemRemover) *BasketServiceEventHandler {
 	return &BasketServiceEventHandler{
-		logger: logger,
+		logger:      logger,
+		coPurchases: coPurchases,
+		baskets:     baskets,
 	}
 }
 
@@ -28,32 +43,21 @@ func (h *BasketServiceEventHandler) HandlePaymentCompleted(ctx context.Context,
 		"payment_id": event.PaymentID,
 		"user_id":    event.UserID,
 		"basket_id":  event.BasketID,
-	}).Info("Payment completed event received - clearing basket")
-
-	// Clear the basket after successful payment
-	// In a real implementation, you would:
-	// 1. Get the basket from Redis
-	// 2. Clear all items from the basket
-	// 3. Update the basket in Redis
-	// 4. Log the operation
-
-	// Example implementation:
-	// basket, err := h.basketRepo.GetBasket(event.UserID)
-	// if err != nil {
-	//     return fmt.Errorf("failed to get basket: %w", err)
-	// }
-	// 
-	// basket.ClearItems()
-	// err = h.basketRepo.SaveBasket(basket)
-	// if err != nil {
-	//     return fmt.Errorf("failed to clear basket: %w", err)
-	// }
-
-	h.logger.WithFields(logrus.Fields{
-		"user_id":   event.UserID,
-		"basket_id": event.BasketID,
-	}).Info("Basket cleared after successful payment")
+	}).Info("Payment completed event received")
+
+	// Record which products were bought together for recommendations
+	if h.coPurchases != nil && len(event.Items) > 0 {
+		productIDs := make([]int, 0, len(event.Items))
+		for _, item := range event.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		if err := h.coPurchases.RecordCoPurchase(event.PaymentID, productIDs); err != nil {
+			return fmt.Errorf("failed to record co-purchase: %w", err)
+		}
+	}
 
+	// The paid items are taken out of the basket by the basket cleared event
+	// the payment service publishes alongside this one
 	return nil
 }
 
@@ -130,29 +134,25 @@ func (h *BasketServiceEventHandler) HandleStockUpdate(ctx context.Context, event
 // HandleBasketCleared handles basket cleared events
 func (h *BasketServiceEventHandler) HandleBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error {
 	h.logger.WithFields(logrus.Fields{
-		"event_id":  event.EventID,
-		"user_id":   event.UserID,
-		"basket_id": event.BasketID,
-		"reason":    event.Reason,
+		"event_id":    event.EventID,
+		"user_id":     event.UserID,
+		"basket_id":   event.BasketID,
+		"reason":      event.Reason,
+		"product_ids": event.ProductIDs,
 	}).Info("Basket cleared event received")
 
-	// In a real implementation, you would:
-	// 1. Verify the basket exists
-	// 2. Clear all items from the basket
-	// 3. Update the basket in Redis
-	// 4. Log the operation
-
-	// Example implementation:
-	// basket, err := h.basketRepo.GetBasket(event.UserID)
-	// if err != nil {
-	//     return fmt.Errorf("failed to get basket: %w", err)
-	// }
-	// 
-	// basket.ClearItems()
-	// err = h.basketRepo.SaveBasket(basket)
-	// if err != nil {
-	//     return fmt.Errorf("failed to clear basket: %w", err)
-	// }
+	// The basket service publishes cleared events for its own clear and delete operations,
+	// which are already applied
+	if source, _ := event.Metadata["source"].(string); source == events.BasketServiceSource {
+		return nil
+	}
+	if h.baskets == nil {
+		return nil
+	}
 
+	// Partial checkouts list the paid products; the unpaid ones stay in the basket
+	if err := h.baskets.RemoveCheckedOutItems(event.UserID, event.BasketID, event.ProductIDs); err != nil {
+		return fmt.Errorf("failed to remove checked out items: %w", err)
+	}
 	return nil
 }
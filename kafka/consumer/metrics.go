@@ -0,0 +1,48 @@
+package consumer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var consumerMessagesConsumed = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "consumer_messages_consumed_total",
+		Help: "Total number of Kafka messages consumed",
+	},
+	[]string{"consumer", "topic"},
+)
+
+var consumerLag = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "consumer_lag",
+		Help: "Number of messages between the last consumed offset and the partition high watermark",
+	},
+	[]string{"consumer", "topic", "partition"},
+)
+
+var consumerProcessingDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "consumer_processing_duration_seconds",
+		Help:    "Time taken to handle a Kafka message, including retries",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"consumer", "event_type"},
+)
+
+// recordConsumedMessage updates the throughput, lag and processing time metrics of a handled message
+func recordConsumedMessage(consumer string, claim sarama.ConsumerGroupClaim, message *sarama.ConsumerMessage, duration time.Duration) {
+	consumerMessagesConsumed.WithLabelValues(consumer, message.Topic).Inc()
+	consumerProcessingDuration.WithLabelValues(consumer, messageEventType(message)).Observe(duration.Seconds())
+
+	// The high watermark is the offset the next produced message will get
+	lag := claim.HighWaterMarkOffset() - message.Offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+	consumerLag.WithLabelValues(consumer, message.Topic, strconv.Itoa(int(message.Partition))).Set(float64(lag))
+}
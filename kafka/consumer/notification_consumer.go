@@ -18,21 +18,33 @@ type NotificationEventHandler interface {
 	HandlePaymentRefunded(ctx context.Context, event *events.PaymentRefundedEvent) error
 	HandleStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error
 	HandleBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error
+	HandleBasketItemAdded(ctx context.Context, event *events.BasketItemAddedEvent) error
+	HandlePromotionCreated(ctx context.Context, event *events.PromotionCreatedEvent) error
 }
 
+var _ NotificationEventHandler = (*NotificationServiceEventHandler)(nil)
+
 // NotificationConsumer handles consuming notification events from Kafka
 type NotificationConsumer struct {
 	consumerGroup sarama.ConsumerGroup
+	brokers       []string
 	handler       NotificationEventHandler
+	retryPolicy   RetryPolicy
+	deadLetter    *DeadLetterPublisher
+	processed     ProcessedEventStore
 	logger        *logrus.Logger
 	topics        []string
 }
 
-// NewNotificationConsumer creates a new notification consumer
+// NewNotificationConsumer creates a new notification consumer.
+// Failing messages are retried according to retryPolicy before their offset is committed.
+// When processed is set, events it already records are skipped, including during replays.
 func NewNotificationConsumer(
 	brokers []string,
 	groupID string,
 	handler NotificationEventHandler,
+	retryPolicy RetryPolicy,
+	processed ProcessedEventStore,
 	logger *logrus.Logger,
 ) (*NotificationConsumer, error) {
 	config := sarama.NewConfig()
@@ -46,14 +58,28 @@ func NewNotificationConsumer(
 		return nil, fmt.Errorf("failed to create consumer group: %w", err)
 	}
 
+	var deadLetter *DeadLetterPublisher
+	if retryPolicy.DeadLetterTopic != "" {
+		deadLetter, err = NewDeadLetterPublisher(brokers, retryPolicy.DeadLetterTopic)
+		if err != nil {
+			consumerGroup.Close()
+			return nil, err
+		}
+	}
+
 	return &NotificationConsumer{
 		consumerGroup: consumerGroup,
+		brokers:       brokers,
 		handler:       handler,
+		retryPolicy:   retryPolicy,
+		deadLetter:    deadLetter,
+		processed:     processed,
 		logger:        logger,
 		topics: []string{
 			events.PaymentEventsTopic,
 			events.StockEventsTopic,
 			events.BasketEventsTopic,
+			events.PromotionEventsTopic,
 		},
 	}, nil
 }
@@ -80,6 +106,11 @@ func (c *NotificationConsumer) Start(ctx context.Context) error {
 // Stop stops the consumer
 func (c *NotificationConsumer) Stop() error {
 	c.logger.Info("Stopping notification consumer...")
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			c.logger.WithError(err).Warn("Failed to close dead letter producer")
+		}
+	}
 	return c.consumerGroup.Close()
 }
 
@@ -110,12 +141,18 @@ func (c *NotificationConsumer) ConsumeClaim(session sarama.ConsumerGroupSession,
 				"offset":    message.Offset,
 			}).Debug("Processing message")
 
-			if err := c.processMessage(context.Background(), message); err != nil {
-				c.logger.WithError(err).Error("Failed to process message")
-				// In production, you might want to implement retry logic or dead letter queue
+			start := time.Now()
+			err := processWithRetry(session.Context(), "notification", c.retryPolicy, message, c.logger, c.processMessageOnce)
+			if err != nil && session.Context().Err() != nil {
+				// Leave the offset uncommitted so the message is redelivered after rebalance
+				return nil
+			}
+			if err != nil {
+				handleFailedMessage("notification", c.deadLetter, message, err, c.logger)
 			}
 
 			session.MarkMessage(message, "")
+			recordConsumedMessage("notification", claim, message, time.Since(start))
 
 		case <-session.Context().Done():
 			return nil
@@ -123,6 +160,12 @@ func (c *NotificationConsumer) ConsumeClaim(session sarama.ConsumerGroupSession,
 	}
 }
 
+// processMessageOnce processes a message unless its event was already applied
+func (c *NotificationConsumer) processMessageOnce(ctx context.Context, message *sarama.ConsumerMessage) error {
+	_, err := processOnce(ctx, "notification", c.processed, message, c.logger, c.processMessage)
+	return err
+}
+
 // processMessage processes a single message
 func (c *NotificationConsumer) processMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
 	// Get event type from headers
@@ -135,45 +178,61 @@ func (c *NotificationConsumer) processMessage(ctx context.Context, message *sara
 	}
 
 	if eventType == "" {
-		return fmt.Errorf("event type not found in message headers")
+		return fmt.Errorf("%w: event type not found in message headers", errMalformedMessage)
 	}
 
 	switch eventType {
 	case events.PaymentCompletedEventType:
 		var event events.PaymentCompletedEvent
 		if err := json.Unmarshal(message.Value, &event); err != nil {
-			return fmt.Errorf("failed to unmarshal payment completed event: %w", err)
+			return fmt.Errorf("%w: failed to unmarshal payment completed event: %w", errMalformedMessage, err)
 		}
+		// Older schemas are migrated so handlers only deal with the current one
+		event.Upgrade()
 		return c.handler.HandlePaymentCompleted(ctx, &event)
 
 	case events.PaymentFailedEventType:
 		var event events.PaymentFailedEvent
 		if err := json.Unmarshal(message.Value, &event); err != nil {
-			return fmt.Errorf("failed to unmarshal payment failed event: %w", err)
+			return fmt.Errorf("%w: failed to unmarshal payment failed event: %w", errMalformedMessage, err)
 		}
 		return c.handler.HandlePaymentFailed(ctx, &event)
 
 	case events.PaymentRefundedEventType:
 		var event events.PaymentRefundedEvent
 		if err := json.Unmarshal(message.Value, &event); err != nil {
-			return fmt.Errorf("failed to unmarshal payment refunded event: %w", err)
+			return fmt.Errorf("%w: failed to unmarshal payment refunded event: %w", errMalformedMessage, err)
 		}
 		return c.handler.HandlePaymentRefunded(ctx, &event)
 
 	case events.StockUpdateEventType:
 		var event events.StockUpdateEvent
 		if err := json.Unmarshal(message.Value, &event); err != nil {
-			return fmt.Errorf("failed to unmarshal stock update event: %w", err)
+			return fmt.Errorf("%w: failed to unmarshal stock update event: %w", errMalformedMessage, err)
 		}
 		return c.handler.HandleStockUpdate(ctx, &event)
 
 	case events.BasketClearedEventType:
 		var event events.BasketClearedEvent
 		if err := json.Unmarshal(message.Value, &event); err != nil {
-			return fmt.Errorf("failed to unmarshal basket cleared event: %w", err)
+			return fmt.Errorf("%w: failed to unmarshal basket cleared event: %w", errMalformedMessage, err)
 		}
 		return c.handler.HandleBasketCleared(ctx, &event)
 
+	case events.BasketItemAddedEventType:
+		var event events.BasketItemAddedEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal basket item added event: %w", errMalformedMessage, err)
+		}
+		return c.handler.HandleBasketItemAdded(ctx, &event)
+
+	case events.PromotionCreatedEventType:
+		var event events.PromotionCreatedEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal promotion created event: %w", errMalformedMessage, err)
+		}
+		return c.handler.HandlePromotionCreated(ctx, &event)
+
 	default:
 		c.logger.WithField("event_type", eventType).Warn("Unknown event type")
 		return nil
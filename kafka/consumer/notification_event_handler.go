@@ -8,23 +8,50 @@ import (
 	"obs-tools-usage/kafka/events"
 )
 
-// NotificationEventHandler handles events for the notification service
-type NotificationEventHandler struct {
-	logger *logrus.Logger
-	// In a real implementation, you would inject the notification repository
-	// notificationRepo repository.NotificationRepository
-	// notificationUseCase *usecase.NotificationUseCase
+// NotificationServiceEventHandler handles events for the notification service
+type NotificationServiceEventHandler struct {
+	logger        *logrus.Logger
+	notifications NotificationCreator
 }
 
-// NewNotificationEventHandler creates a new notification service event handler
-func NewNotificationEventHandler(logger *logrus.Logger) *NotificationEventHandler {
-	return &NotificationEventHandler{
-		logger: logger,
+// EventNotification is a notification to create for a consumed event
+type EventNotification struct {
+	SourceEventID string // ID of the event, so a redelivered event is notified once
+	UserID        string
+	Title         string
+	Message       string
+	Type          string
+	Priority      string
+	Channel       string
+	Data          map[string]interface{}
+}
+
+// NotificationCreator creates the notifications for consumed events
+type NotificationCreator interface {
+	CreateEventNotification(ctx context.Context, notification EventNotification) error
+}
+
+// NewNotificationServiceEventHandler creates a new notification service event handler
+func NewNotificationServiceEventHandler(logger *logrus.Logger, notifications NotificationCreator) *NotificationServiceEventHandler {
+	return &NotificationServiceEventHandler{
+		logger:        logger,
+		notifications: notifications,
 	}
 }
 
+// create creates the notification for an event; without a creator it is only logged
+func (h *NotificationServiceEventHandler) create(ctx context.Context, notification EventNotification) error {
+	if h.notifications == nil {
+		return nil
+	}
+	if err := h.notifications.CreateEventNotification(ctx, notification); err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
 // HandlePaymentCompleted handles payment completed events
-func (h *NotificationEventHandler) HandlePaymentCompleted(ctx context.Context, event *events.PaymentCompletedEvent) error {
+func (h *NotificationServiceEventHandler) HandlePaymentCompleted(ctx context.Context, event *events.PaymentCompletedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":   event.EventID,
 		"payment_id": event.PaymentID,
@@ -34,25 +61,25 @@ func (h *NotificationEventHandler) HandlePaymentCompleted(ctx context.Context, e
 	}).Info("Payment completed event received - sending notification")
 
 	// Create success notification for payment completion
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Payment Successful",
-		"message":  "Your payment has been processed successfully",
-		"type":     "payment",
-		"priority": "high",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Payment Successful",
+		Message:       "Your payment has been processed successfully",
+		Type:          "payment",
+		Priority:      "high",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"payment_id": event.PaymentID,
 			"amount":     event.Amount,
 			"currency":   event.Currency,
 		},
 	}
 
-	// In a real implementation, you would:
-	// 1. Create notification in database
-	// 2. Send via email/SMS/push notification
-	// 3. Update user preferences
-	
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Payment success notification created")
@@ -61,7 +88,7 @@ func (h *NotificationEventHandler) HandlePaymentCompleted(ctx context.Context, e
 }
 
 // HandlePaymentFailed handles payment failed events
-func (h *NotificationEventHandler) HandlePaymentFailed(ctx context.Context, event *events.PaymentFailedEvent) error {
+func (h *NotificationServiceEventHandler) HandlePaymentFailed(ctx context.Context, event *events.PaymentFailedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":   event.EventID,
 		"payment_id": event.PaymentID,
@@ -72,14 +99,15 @@ func (h *NotificationEventHandler) HandlePaymentFailed(ctx context.Context, even
 	}).Info("Payment failed event received - sending notification")
 
 	// Create error notification for payment failure
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Payment Failed",
-		"message":  "Your payment could not be processed. Please try again.",
-		"type":     "payment",
-		"priority": "high",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Payment Failed",
+		Message:       "Your payment could not be processed. Please try again.",
+		Type:          "payment",
+		Priority:      "high",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"payment_id": event.PaymentID,
 			"amount":     event.Amount,
 			"reason":     event.Reason,
@@ -87,11 +115,10 @@ func (h *NotificationEventHandler) HandlePaymentFailed(ctx context.Context, even
 		},
 	}
 
-	// In a real implementation, you would:
-	// 1. Create notification in database
-	// 2. Send via email/SMS/push notification
-	// 3. Provide retry options
-	
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Payment failure notification created")
@@ -100,7 +127,7 @@ func (h *NotificationEventHandler) HandlePaymentFailed(ctx context.Context, even
 }
 
 // HandlePaymentRefunded handles payment refunded events
-func (h *NotificationEventHandler) HandlePaymentRefunded(ctx context.Context, event *events.PaymentRefundedEvent) error {
+func (h *NotificationServiceEventHandler) HandlePaymentRefunded(ctx context.Context, event *events.PaymentRefundedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":   event.EventID,
 		"payment_id": event.PaymentID,
@@ -110,25 +137,25 @@ func (h *NotificationEventHandler) HandlePaymentRefunded(ctx context.Context, ev
 	}).Info("Payment refunded event received - sending notification")
 
 	// Create info notification for payment refund
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Payment Refunded",
-		"message":  "Your payment has been refunded successfully",
-		"type":     "payment",
-		"priority": "normal",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Payment Refunded",
+		Message:       "Your payment has been refunded successfully",
+		Type:          "payment",
+		Priority:      "normal",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"payment_id": event.PaymentID,
 			"amount":     event.Amount,
 			"reason":     event.Reason,
 		},
 	}
 
-	// In a real implementation, you would:
-	// 1. Create notification in database
-	// 2. Send via email/SMS/push notification
-	// 3. Update user account balance
-	
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Payment refund notification created")
@@ -137,7 +164,7 @@ func (h *NotificationEventHandler) HandlePaymentRefunded(ctx context.Context, ev
 }
 
 // HandleStockUpdate handles stock update events
-func (h *NotificationEventHandler) HandleStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error {
+func (h *NotificationServiceEventHandler) HandleStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":   event.EventID,
 		"product_id": event.ProductID,
@@ -147,14 +174,15 @@ func (h *NotificationEventHandler) HandleStockUpdate(ctx context.Context, event
 	}).Info("Stock update event received - sending notification")
 
 	// Create system notification for stock updates
-	notification := map[string]interface{}{
-		"user_id":  "system", // System notification
-		"title":    "Stock Updated",
-		"message":  "Product stock has been updated",
-		"type":     "system",
-		"priority": "normal",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        "system", // System notification
+		Title:         "Stock Updated",
+		Message:       "Product stock has been updated",
+		Type:          "system",
+		Priority:      "normal",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"product_id": event.ProductID,
 			"quantity":   event.Quantity,
 			"operation":  event.Operation,
@@ -162,11 +190,10 @@ func (h *NotificationEventHandler) HandleStockUpdate(ctx context.Context, event
 		},
 	}
 
-	// In a real implementation, you would:
-	// 1. Create notification in database
-	// 2. Send to admin users
-	// 3. Update inventory alerts
-	
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Stock update notification created")
@@ -175,7 +202,7 @@ func (h *NotificationEventHandler) HandleStockUpdate(ctx context.Context, event
 }
 
 // HandleBasketCleared handles basket cleared events
-func (h *NotificationEventHandler) HandleBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error {
+func (h *NotificationServiceEventHandler) HandleBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":  event.EventID,
 		"user_id":   event.UserID,
@@ -184,24 +211,24 @@ func (h *NotificationEventHandler) HandleBasketCleared(ctx context.Context, even
 	}).Info("Basket cleared event received - sending notification")
 
 	// Create info notification for basket cleared
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Basket Cleared",
-		"message":  "Your basket has been cleared",
-		"type":     "info",
-		"priority": "low",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Basket Cleared",
+		Message:       "Your basket has been cleared",
+		Type:          "info",
+		Priority:      "low",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"basket_id": event.BasketID,
 			"reason":    event.Reason,
 		},
 	}
 
-	// In a real implementation, you would:
-	// 1. Create notification in database
-	// 2. Send via email/SMS/push notification
-	// 3. Provide re-add items option
-	
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Basket cleared notification created")
@@ -210,7 +237,7 @@ func (h *NotificationEventHandler) HandleBasketCleared(ctx context.Context, even
 }
 
 // HandleUserRegistered handles user registration events
-func (h *NotificationEventHandler) HandleUserRegistered(ctx context.Context, event *events.UserRegisteredEvent) error {
+func (h *NotificationServiceEventHandler) HandleUserRegistered(ctx context.Context, event *events.UserRegisteredEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id": event.EventID,
 		"user_id":  event.UserID,
@@ -218,19 +245,24 @@ func (h *NotificationEventHandler) HandleUserRegistered(ctx context.Context, eve
 	}).Info("User registered event received - sending welcome notification")
 
 	// Create welcome notification
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Welcome!",
-		"message":  "Welcome to our platform! Get started by exploring our products.",
-		"type":     "success",
-		"priority": "normal",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Welcome!",
+		Message:       "Welcome to our platform! Get started by exploring our products.",
+		Type:          "success",
+		Priority:      "normal",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"email":      event.Email,
 			"first_name": event.FirstName,
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Welcome notification created")
@@ -239,7 +271,7 @@ func (h *NotificationEventHandler) HandleUserRegistered(ctx context.Context, eve
 }
 
 // HandleProductViewed handles product view events
-func (h *NotificationEventHandler) HandleProductViewed(ctx context.Context, event *events.ProductViewedEvent) error {
+func (h *NotificationServiceEventHandler) HandleProductViewed(ctx context.Context, event *events.ProductViewedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":   event.EventID,
 		"product_id": event.ProductID,
@@ -258,7 +290,7 @@ func (h *NotificationEventHandler) HandleProductViewed(ctx context.Context, even
 }
 
 // HandleBasketItemAdded handles basket item addition events
-func (h *NotificationEventHandler) HandleBasketItemAdded(ctx context.Context, event *events.BasketItemAddedEvent) error {
+func (h *NotificationServiceEventHandler) HandleBasketItemAdded(ctx context.Context, event *events.BasketItemAddedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":     event.EventID,
 		"user_id":      event.UserID,
@@ -268,14 +300,15 @@ func (h *NotificationEventHandler) HandleBasketItemAdded(ctx context.Context, ev
 	}).Info("Basket item added event received - sending confirmation")
 
 	// Create confirmation notification
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Item Added to Basket",
-		"message":  fmt.Sprintf("Added %d x %s to your basket", event.Quantity, event.ProductName),
-		"type":     "info",
-		"priority": "low",
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Item Added to Basket",
+		Message:       fmt.Sprintf("Added %d x %s to your basket", event.Quantity, event.ProductName),
+		Type:          "info",
+		Priority:      "low",
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"product_id":   event.ProductID,
 			"product_name": event.ProductName,
 			"quantity":     event.Quantity,
@@ -283,6 +316,10 @@ func (h *NotificationEventHandler) HandleBasketItemAdded(ctx context.Context, ev
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Basket item added notification created")
@@ -291,7 +328,7 @@ func (h *NotificationEventHandler) HandleBasketItemAdded(ctx context.Context, ev
 }
 
 // HandleBasketAbandoned handles basket abandonment events
-func (h *NotificationEventHandler) HandleBasketAbandoned(ctx context.Context, event *events.BasketAbandonedEvent) error {
+func (h *NotificationServiceEventHandler) HandleBasketAbandoned(ctx context.Context, event *events.BasketAbandonedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":     event.EventID,
 		"user_id":      event.UserID,
@@ -301,14 +338,15 @@ func (h *NotificationEventHandler) HandleBasketAbandoned(ctx context.Context, ev
 	}).Info("Basket abandoned event received - sending recovery notification")
 
 	// Create recovery notification
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Don't Forget Your Items!",
-		"message":  "You have items in your basket. Complete your purchase now!",
-		"type":     "warning",
-		"priority": "normal",
-		"channel":  "email",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Don't Forget Your Items!",
+		Message:       "You have items in your basket. Complete your purchase now!",
+		Type:          "warning",
+		Priority:      "normal",
+		Channel:       "email",
+		Data: map[string]interface{}{
 			"basket_id":    event.BasketID,
 			"item_count":   event.ItemCount,
 			"total_value":  event.TotalValue,
@@ -316,6 +354,10 @@ func (h *NotificationEventHandler) HandleBasketAbandoned(ctx context.Context, ev
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Basket abandoned recovery notification created")
@@ -324,7 +366,7 @@ func (h *NotificationEventHandler) HandleBasketAbandoned(ctx context.Context, ev
 }
 
 // HandleOrderCreated handles order creation events
-func (h *NotificationEventHandler) HandleOrderCreated(ctx context.Context, event *events.OrderCreatedEvent) error {
+func (h *NotificationServiceEventHandler) HandleOrderCreated(ctx context.Context, event *events.OrderCreatedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":     event.EventID,
 		"order_id":     event.OrderID,
@@ -334,14 +376,15 @@ func (h *NotificationEventHandler) HandleOrderCreated(ctx context.Context, event
 	}).Info("Order created event received - sending confirmation")
 
 	// Create order confirmation notification
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Order Confirmed",
-		"message":  fmt.Sprintf("Your order #%s has been confirmed. Total: %s %.2f", event.OrderID, event.Currency, event.TotalAmount),
-		"type":     "success",
-		"priority": "high",
-		"channel":  "email",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Order Confirmed",
+		Message:       fmt.Sprintf("Your order #%s has been confirmed. Total: %s %.2f", event.OrderID, event.Currency, event.TotalAmount),
+		Type:          "success",
+		Priority:      "high",
+		Channel:       "email",
+		Data: map[string]interface{}{
 			"order_id":     event.OrderID,
 			"total_amount": event.TotalAmount,
 			"currency":     event.Currency,
@@ -349,6 +392,10 @@ func (h *NotificationEventHandler) HandleOrderCreated(ctx context.Context, event
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Order confirmation notification created")
@@ -357,7 +404,7 @@ func (h *NotificationEventHandler) HandleOrderCreated(ctx context.Context, event
 }
 
 // HandleOrderShipped handles order shipment events
-func (h *NotificationEventHandler) HandleOrderShipped(ctx context.Context, event *events.OrderShippedEvent) error {
+func (h *NotificationServiceEventHandler) HandleOrderShipped(ctx context.Context, event *events.OrderShippedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":        event.EventID,
 		"order_id":        event.OrderID,
@@ -367,14 +414,15 @@ func (h *NotificationEventHandler) HandleOrderShipped(ctx context.Context, event
 	}).Info("Order shipped event received - sending tracking notification")
 
 	// Create shipping notification
-	notification := map[string]interface{}{
-		"user_id":  event.UserID,
-		"title":    "Order Shipped!",
-		"message":  fmt.Sprintf("Your order #%s has been shipped. Tracking: %s", event.OrderID, event.TrackingNumber),
-		"type":     "info",
-		"priority": "high",
-		"channel":  "email",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        event.UserID,
+		Title:         "Order Shipped!",
+		Message:       fmt.Sprintf("Your order #%s has been shipped. Tracking: %s", event.OrderID, event.TrackingNumber),
+		Type:          "info",
+		Priority:      "high",
+		Channel:       "email",
+		Data: map[string]interface{}{
 			"order_id":         event.OrderID,
 			"tracking_number":  event.TrackingNumber,
 			"carrier":          event.Carrier,
@@ -382,6 +430,10 @@ func (h *NotificationEventHandler) HandleOrderShipped(ctx context.Context, event
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Order shipped notification created")
@@ -390,7 +442,7 @@ func (h *NotificationEventHandler) HandleOrderShipped(ctx context.Context, event
 }
 
 // HandleStockLow handles low stock events
-func (h *NotificationEventHandler) HandleStockLow(ctx context.Context, event *events.StockLowEvent) error {
+func (h *NotificationServiceEventHandler) HandleStockLow(ctx context.Context, event *events.StockLowEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":      event.EventID,
 		"product_id":    event.ProductID,
@@ -400,14 +452,15 @@ func (h *NotificationEventHandler) HandleStockLow(ctx context.Context, event *ev
 	}).Info("Stock low event received - sending alert")
 
 	// Create stock alert notification
-	notification := map[string]interface{}{
-		"user_id":  "admin", // Admin notification
-		"title":    "Low Stock Alert",
-		"message":  fmt.Sprintf("Product '%s' is running low on stock. Current: %d, Threshold: %d", event.ProductName, event.CurrentStock, event.Threshold),
-		"type":     "warning",
-		"priority": "high",
-		"channel":  "email",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        "admin", // Admin notification
+		Title:         "Low Stock Alert",
+		Message:       fmt.Sprintf("Product '%s' is running low on stock. Current: %d, Threshold: %d", event.ProductName, event.CurrentStock, event.Threshold),
+		Type:          "warning",
+		Priority:      "high",
+		Channel:       "email",
+		Data: map[string]interface{}{
 			"product_id":    event.ProductID,
 			"product_name":  event.ProductName,
 			"current_stock": event.CurrentStock,
@@ -415,6 +468,10 @@ func (h *NotificationEventHandler) HandleStockLow(ctx context.Context, event *ev
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Stock low alert notification created")
@@ -423,7 +480,7 @@ func (h *NotificationEventHandler) HandleStockLow(ctx context.Context, event *ev
 }
 
 // HandleStockOut handles stock out events
-func (h *NotificationEventHandler) HandleStockOut(ctx context.Context, event *events.StockOutEvent) error {
+func (h *NotificationServiceEventHandler) HandleStockOut(ctx context.Context, event *events.StockOutEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":     event.EventID,
 		"product_id":   event.ProductID,
@@ -431,19 +488,24 @@ func (h *NotificationEventHandler) HandleStockOut(ctx context.Context, event *ev
 	}).Info("Stock out event received - sending urgent alert")
 
 	// Create urgent stock out alert
-	notification := map[string]interface{}{
-		"user_id":  "admin", // Admin notification
-		"title":    "URGENT: Stock Out",
-		"message":  fmt.Sprintf("Product '%s' is out of stock!", event.ProductName),
-		"type":     "error",
-		"priority": "urgent",
-		"channel":  "email",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        "admin", // Admin notification
+		Title:         "URGENT: Stock Out",
+		Message:       fmt.Sprintf("Product '%s' is out of stock!", event.ProductName),
+		Type:          "error",
+		Priority:      "urgent",
+		Channel:       "email",
+		Data: map[string]interface{}{
 			"product_id":   event.ProductID,
 			"product_name": event.ProductName,
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Stock out alert notification created")
@@ -452,7 +514,7 @@ func (h *NotificationEventHandler) HandleStockOut(ctx context.Context, event *ev
 }
 
 // HandleSystemMaintenance handles system maintenance events
-func (h *NotificationEventHandler) HandleSystemMaintenance(ctx context.Context, event *events.SystemMaintenanceEvent) error {
+func (h *NotificationServiceEventHandler) HandleSystemMaintenance(ctx context.Context, event *events.SystemMaintenanceEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":   event.EventID,
 		"title":      event.Title,
@@ -462,20 +524,25 @@ func (h *NotificationEventHandler) HandleSystemMaintenance(ctx context.Context,
 	}).Info("System maintenance event received - sending notification")
 
 	// Create system maintenance notification
-	notification := map[string]interface{}{
-		"user_id":  "all", // Broadcast to all users
-		"title":    event.Title,
-		"message":  event.Description,
-		"type":     "system",
-		"priority": event.Severity,
-		"channel":  "in_app",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        "all", // Broadcast to all users
+		Title:         event.Title,
+		Message:       event.Description,
+		Type:          "system",
+		Priority:      event.Severity,
+		Channel:       "in_app",
+		Data: map[string]interface{}{
 			"start_time": event.StartTime,
 			"end_time":   event.EndTime,
 			"severity":   event.Severity,
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("System maintenance notification created")
@@ -484,7 +551,7 @@ func (h *NotificationEventHandler) HandleSystemMaintenance(ctx context.Context,
 }
 
 // HandlePromotionCreated handles promotion creation events
-func (h *NotificationEventHandler) HandlePromotionCreated(ctx context.Context, event *events.PromotionCreatedEvent) error {
+func (h *NotificationServiceEventHandler) HandlePromotionCreated(ctx context.Context, event *events.PromotionCreatedEvent) error {
 	h.logger.WithFields(logrus.Fields{
 		"event_id":     event.EventID,
 		"promotion_id": event.PromotionID,
@@ -493,14 +560,15 @@ func (h *NotificationEventHandler) HandlePromotionCreated(ctx context.Context, e
 	}).Info("Promotion created event received - sending marketing notification")
 
 	// Create promotion notification
-	notification := map[string]interface{}{
-		"user_id":  "all", // Broadcast to all users
-		"title":    "New Promotion Available!",
-		"message":  fmt.Sprintf("%s - %.0f%% off!", event.Title, event.Discount),
-		"type":     "marketing",
-		"priority": "normal",
-		"channel":  "email",
-		"data": map[string]string{
+	notification := EventNotification{
+		SourceEventID: event.EventID,
+		UserID:        "all", // Broadcast to all users
+		Title:         "New Promotion Available!",
+		Message:       fmt.Sprintf("%s - %.0f%% off!", event.Title, event.Discount),
+		Type:          "marketing",
+		Priority:      "normal",
+		Channel:       "email",
+		Data: map[string]interface{}{
 			"promotion_id": event.PromotionID,
 			"title":        event.Title,
 			"description":  event.Description,
@@ -510,6 +578,10 @@ func (h *NotificationEventHandler) HandlePromotionCreated(ctx context.Context, e
 		},
 	}
 
+	if err := h.create(ctx, notification); err != nil {
+		return err
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"notification": notification,
 	}).Info("Promotion notification created")
@@ -147,6 +147,8 @@ func (c *PaymentConsumer) processMessage(ctx context.Context, message *sarama.Co
 		if err := json.Unmarshal(message.Value, &event); err != nil {
 			return fmt.Errorf("failed to unmarshal payment completed event: %w", err)
 		}
+		// Older schemas are migrated so handlers only deal with the current one
+		event.Upgrade()
 		return c.handler.HandlePaymentCompleted(ctx, &event)
 
 	case events.PaymentFailedEventType:
@@ -0,0 +1,118 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedEventStore records which events a consumer has already applied,
+// so redelivered or replayed events are not handled twice
+type ProcessedEventStore interface {
+	IsProcessed(ctx context.Context, consumerName, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, consumerName, eventID string) error
+}
+
+// ProcessedEvent is a row of the processed events table
+type ProcessedEvent struct {
+	Consumer    string    `gorm:"primaryKey;size:64"`
+	EventID     string    `gorm:"primaryKey;size:64"`
+	ProcessedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the processed events table name
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
+
+// GormProcessedEventStore keeps processed event IDs in the service's database
+type GormProcessedEventStore struct {
+	db *gorm.DB
+}
+
+// NewGormProcessedEventStore creates a database-backed processed event store, creating its table if needed
+func NewGormProcessedEventStore(db *gorm.DB) (*GormProcessedEventStore, error) {
+	if err := db.AutoMigrate(&ProcessedEvent{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate processed events table: %w", err)
+	}
+	return &GormProcessedEventStore{db: db}, nil
+}
+
+// IsProcessed reports whether the consumer has already applied the event
+func (s *GormProcessedEventStore) IsProcessed(ctx context.Context, consumerName, eventID string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&ProcessedEvent{}).
+		Where("consumer = ? AND event_id = ?", consumerName, eventID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to look up processed event: %w", err)
+	}
+	return count > 0, nil
+}
+
+// MarkProcessed records that the consumer has applied the event
+func (s *GormProcessedEventStore) MarkProcessed(ctx context.Context, consumerName, eventID string) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ProcessedEvent{Consumer: consumerName, EventID: eventID, ProcessedAt: time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark event as processed: %w", err)
+	}
+	return nil
+}
+
+// messageEventID reads the event_id field every event payload carries
+func messageEventID(message *sarama.ConsumerMessage) string {
+	var envelope struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal(message.Value, &envelope); err != nil {
+		return ""
+	}
+	return envelope.EventID
+}
+
+// processOnce runs process for a message unless the store shows its event was already applied,
+// and records the event once process succeeds. Messages without an event ID are always processed.
+// It reports whether the message was skipped as a duplicate.
+func processOnce(
+	ctx context.Context,
+	consumerName string,
+	store ProcessedEventStore,
+	message *sarama.ConsumerMessage,
+	logger *logrus.Logger,
+	process func(context.Context, *sarama.ConsumerMessage) error,
+) (bool, error) {
+	eventID := messageEventID(message)
+	if store == nil || eventID == "" {
+		return false, process(ctx, message)
+	}
+
+	processed, err := store.IsProcessed(ctx, consumerName, eventID)
+	if err != nil {
+		return false, err
+	}
+	if processed {
+		return true, nil
+	}
+
+	if err := process(ctx, message); err != nil {
+		return false, err
+	}
+
+	// The effect is applied, so a failure here must not trigger a retry
+	if err := store.MarkProcessed(ctx, consumerName, eventID); err != nil {
+		logger.WithError(err).WithFields(logrus.Fields{
+			"consumer": consumerName,
+			"event_id": eventID,
+		}).Warn("Failed to record processed event, it may be handled again")
+	}
+	return false, nil
+}
@@ -0,0 +1,159 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+)
+
+// replayProgressInterval is how many messages are replayed between progress logs
+const replayProgressInterval = 100
+
+// ReplayResult summarises a replay run
+type ReplayResult struct {
+	Topic      string `json:"topic"`
+	FromOffset int64  `json:"from_offset"`
+	ToOffset   int64  `json:"to_offset"`
+	Partitions int    `json:"partitions"`
+	Read       int    `json:"read"`
+	Replayed   int    `json:"replayed"`
+	Skipped    int    `json:"skipped"` // Already applied according to the processed event store
+	Failed     int    `json:"failed"`
+}
+
+// ReplayEvents re-dispatches the messages of topic between fromOffset and toOffset (inclusive)
+// on every partition through the handler. Events recorded in the processed event store are
+// skipped, so only events whose effects were never applied are handled again. Failing messages
+// are retried like live ones and then counted as failed; they are not dead-lettered again.
+func (c *NotificationConsumer) ReplayEvents(ctx context.Context, topic string, fromOffset, toOffset int64) (*ReplayResult, error) {
+	if fromOffset < 0 || toOffset < fromOffset {
+		return nil, fmt.Errorf("invalid offset range: %d-%d", fromOffset, toOffset)
+	}
+
+	consumer, err := sarama.NewConsumer(c.brokers, sarama.NewConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	partitions, err := consumer.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %s: %w", topic, err)
+	}
+
+	result := &ReplayResult{
+		Topic:      topic,
+		FromOffset: fromOffset,
+		ToOffset:   toOffset,
+		Partitions: len(partitions),
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":       topic,
+		"from_offset": fromOffset,
+		"to_offset":   toOffset,
+		"partitions":  len(partitions),
+	}).Info("Starting event replay")
+
+	for _, partition := range partitions {
+		if err := c.replayPartition(ctx, consumer, topic, partition, fromOffset, toOffset, result); err != nil {
+			return result, err
+		}
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"topic":    topic,
+		"read":     result.Read,
+		"replayed": result.Replayed,
+		"skipped":  result.Skipped,
+		"failed":   result.Failed,
+	}).Info("Event replay completed")
+
+	return result, nil
+}
+
+// replayPartition replays the requested offset range of a single partition
+func (c *NotificationConsumer) replayPartition(
+	ctx context.Context,
+	consumer sarama.Consumer,
+	topic string,
+	partition int32,
+	fromOffset, toOffset int64,
+	result *ReplayResult,
+) error {
+	logger := c.logger.WithFields(logrus.Fields{
+		"topic":     topic,
+		"partition": partition,
+	})
+
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, fromOffset)
+	if errors.Is(err, sarama.ErrOffsetOutOfRange) {
+		logger.WithField("from_offset", fromOffset).Warn("Replay start offset is not available on partition, skipping")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to consume partition %d of %s: %w", partition, topic, err)
+	}
+	defer partitionConsumer.Close()
+
+	// Stop at the requested end or at the last message currently in the partition
+	lastOffset := partitionConsumer.HighWaterMarkOffset() - 1
+	if toOffset < lastOffset {
+		lastOffset = toOffset
+	}
+	if lastOffset < fromOffset {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case consumerErr := <-partitionConsumer.Errors():
+			return fmt.Errorf("failed to read partition %d of %s: %w", partition, topic, consumerErr)
+
+		case message := <-partitionConsumer.Messages():
+			result.Read++
+
+			skipped := false
+			err := processWithRetry(ctx, "notification-replay", c.retryPolicy, message, c.logger,
+				func(ctx context.Context, message *sarama.ConsumerMessage) error {
+					var err error
+					skipped, err = processOnce(ctx, "notification", c.processed, message, c.logger, c.processMessage)
+					return err
+				})
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return ctx.Err()
+			case err != nil:
+				result.Failed++
+				logger.WithError(err).WithFields(logrus.Fields{
+					"offset":     message.Offset,
+					"event_type": messageEventType(message),
+				}).Error("Failed to replay message")
+			case skipped:
+				result.Skipped++
+			default:
+				result.Replayed++
+			}
+
+			if result.Read%replayProgressInterval == 0 {
+				logger.WithFields(logrus.Fields{
+					"offset":   message.Offset,
+					"read":     result.Read,
+					"replayed": result.Replayed,
+					"skipped":  result.Skipped,
+					"failed":   result.Failed,
+				}).Info("Event replay progress")
+			}
+
+			if message.Offset >= lastOffset {
+				return nil
+			}
+		}
+	}
+}
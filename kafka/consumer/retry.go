@@ -0,0 +1,197 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// errMalformedMessage marks messages that can never be processed, so they are not retried
+var errMalformedMessage = errors.New("malformed message")
+
+var consumerProcessingErrors = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "consumer_processing_errors_total",
+		Help: "Total number of failed Kafka message handling attempts",
+	},
+	[]string{"consumer", "event_type"},
+)
+
+var consumerDeadLettered = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "consumer_dead_lettered_total",
+		Help: "Total number of Kafka messages sent to the dead letter topic",
+	},
+	[]string{"consumer", "event_type"},
+)
+
+// RetryPolicy controls how often a failing message is retried before it is committed.
+// When DeadLetterTopic is set, messages that still fail are copied there first.
+type RetryPolicy struct {
+	MaxRetries      int
+	InitialBackoff  time.Duration
+	MaxBackoff      time.Duration
+	DeadLetterTopic string
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// backoff returns the delay before the given retry (1-based), doubling up to MaxBackoff
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// processWithRetry runs process for a message, retrying failures with backoff.
+// It returns the last error once retries are exhausted, or ctx.Err() if the
+// session ends while waiting, in which case the message must not be marked.
+func processWithRetry(
+	ctx context.Context,
+	consumerName string,
+	policy RetryPolicy,
+	message *sarama.ConsumerMessage,
+	logger *logrus.Logger,
+	process func(context.Context, *sarama.ConsumerMessage) error,
+) error {
+	eventType := messageEventType(message)
+
+	for attempt := 0; ; attempt++ {
+		err := process(ctx, message)
+		if err == nil {
+			return nil
+		}
+
+		consumerProcessingErrors.WithLabelValues(consumerName, eventType).Inc()
+
+		if errors.Is(err, errMalformedMessage) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		delay := policy.backoff(attempt + 1)
+		logger.WithError(err).WithFields(logrus.Fields{
+			"consumer":   consumerName,
+			"event_type": eventType,
+			"topic":      message.Topic,
+			"partition":  message.Partition,
+			"offset":     message.Offset,
+			"attempt":    attempt + 1,
+			"backoff":    delay.String(),
+		}).Warn("Message processing failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// handleFailedMessage logs a message that exhausted its retries and dead-letters it when deadLetter is set
+func handleFailedMessage(consumerName string, deadLetter *DeadLetterPublisher, message *sarama.ConsumerMessage, err error, logger *logrus.Logger) {
+	fields := logrus.Fields{
+		"consumer":   consumerName,
+		"topic":      message.Topic,
+		"partition":  message.Partition,
+		"offset":     message.Offset,
+		"event_type": messageEventType(message),
+	}
+
+	if deadLetter == nil {
+		logger.WithError(err).WithFields(fields).Error("Failed to process message, skipping")
+		return
+	}
+
+	if dlqErr := deadLetter.Publish(message, err); dlqErr != nil {
+		logger.WithError(dlqErr).WithFields(fields).Error("Failed to dead-letter message, skipping")
+		return
+	}
+
+	consumerDeadLettered.WithLabelValues(consumerName, messageEventType(message)).Inc()
+	logger.WithError(err).WithFields(fields).Warn("Failed to process message, sent to dead letter topic")
+}
+
+// messageEventType reads the event_type header of a message
+func messageEventType(message *sarama.ConsumerMessage) string {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == "event_type" {
+			return string(header.Value)
+		}
+	}
+	return "unknown"
+}
+
+// DeadLetterPublisher copies messages that could not be processed to a dead letter topic
+type DeadLetterPublisher struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewDeadLetterPublisher creates a dead letter publisher for the given topic
+func NewDeadLetterPublisher(brokers []string, topic string) (*DeadLetterPublisher, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter producer: %w", err)
+	}
+
+	return &DeadLetterPublisher{
+		producer: producer,
+		topic:    topic,
+	}, nil
+}
+
+// Publish sends a copy of the message with its origin and failure reason in the headers
+func (d *DeadLetterPublisher) Publish(message *sarama.ConsumerMessage, cause error) error {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+4)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte("dlq_original_topic"), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte("dlq_original_partition"), Value: []byte(strconv.FormatInt(int64(message.Partition), 10))},
+		sarama.RecordHeader{Key: []byte("dlq_original_offset"), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte("dlq_error"), Value: []byte(cause.Error())},
+	)
+
+	_, _, err := d.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   d.topic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to dead letter topic %s: %w", d.topic, err)
+	}
+	return nil
+}
+
+// Close closes the dead letter producer
+func (d *DeadLetterPublisher) Close() error {
+	return d.producer.Close()
+}
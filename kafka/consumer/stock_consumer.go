@@ -0,0 +1,165 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/kafka/events"
+)
+
+// StockEventHandler applies stock events to a product catalog.
+// Handlers apply each event at most once themselves, recording its ID
+// in the same transaction as the stock change.
+type StockEventHandler interface {
+	HandleStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error
+}
+
+// StockConsumer handles consuming the stock updates published by the payment service
+type StockConsumer struct {
+	consumerGroup sarama.ConsumerGroup
+	handler       StockEventHandler
+	retryPolicy   RetryPolicy
+	deadLetter    *DeadLetterPublisher
+	logger        *logrus.Logger
+}
+
+// NewStockConsumer creates a new stock consumer.
+// Failing messages are retried according to retryPolicy before their offset is committed.
+func NewStockConsumer(
+	brokers []string,
+	groupID string,
+	handler StockEventHandler,
+	retryPolicy RetryPolicy,
+	logger *logrus.Logger,
+) (*StockConsumer, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	config.Consumer.Group.Session.Timeout = 10 * time.Second
+	config.Consumer.Group.Heartbeat.Interval = 3 * time.Second
+
+	consumerGroup, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	var deadLetter *DeadLetterPublisher
+	if retryPolicy.DeadLetterTopic != "" {
+		deadLetter, err = NewDeadLetterPublisher(brokers, retryPolicy.DeadLetterTopic)
+		if err != nil {
+			consumerGroup.Close()
+			return nil, err
+		}
+	}
+
+	return &StockConsumer{
+		consumerGroup: consumerGroup,
+		handler:       handler,
+		retryPolicy:   retryPolicy,
+		deadLetter:    deadLetter,
+		logger:        logger,
+	}, nil
+}
+
+// Start consumes stock events until the context is cancelled
+func (c *StockConsumer) Start(ctx context.Context) {
+	c.logger.WithField("topic", events.StockEventsTopic).Info("Stock consumer started")
+
+	for {
+		if err := c.consumerGroup.Consume(ctx, []string{events.StockEventsTopic}, c); err != nil {
+			c.logger.WithError(err).Error("Error consuming stock events")
+		}
+
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Stock consumer stopped")
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Stop stops the consumer
+func (c *StockConsumer) Stop() error {
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			c.logger.WithError(err).Warn("Failed to close dead letter producer")
+		}
+	}
+	return c.consumerGroup.Close()
+}
+
+// Setup is run at the beginning of a new session, before ConsumeClaim
+func (c *StockConsumer) Setup(sarama.ConsumerGroupSession) error {
+	c.logger.Debug("Stock consumer session started")
+	return nil
+}
+
+// Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited
+func (c *StockConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	c.logger.Debug("Stock consumer session ended")
+	return nil
+}
+
+// ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages()
+func (c *StockConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case message := <-claim.Messages():
+			if message == nil {
+				return nil
+			}
+
+			start := time.Now()
+			err := processWithRetry(session.Context(), "stock", c.retryPolicy, message, c.logger, c.processMessage)
+			if err != nil && session.Context().Err() != nil {
+				// Leave the offset uncommitted so the message is redelivered after rebalance
+				return nil
+			}
+			if err != nil {
+				handleFailedMessage("stock", c.deadLetter, message, err, c.logger)
+			}
+
+			session.MarkMessage(message, "")
+			recordConsumedMessage("stock", claim, message, time.Since(start))
+
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// processMessage dispatches a single message to the handler
+func (c *StockConsumer) processMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
+	var eventType string
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == "event_type" {
+			eventType = string(header.Value)
+			break
+		}
+	}
+
+	switch eventType {
+	case events.StockUpdateEventType:
+		var event events.StockUpdateEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			return fmt.Errorf("%w: failed to unmarshal stock update event: %w", errMalformedMessage, err)
+		}
+		return c.handler.HandleStockUpdate(ctx, &event)
+
+	case events.StockLowEventType, events.StockOutEventType:
+		// Stock alerts are published by the product service for other consumers
+		return nil
+
+	case "":
+		return fmt.Errorf("%w: event type not found in message headers", errMalformedMessage)
+
+	default:
+		c.logger.WithField("event_type", eventType).Warn("Unknown stock event type")
+		return nil
+	}
+}
@@ -95,27 +95,32 @@ type ProductViewedEvent struct {
 	Timestamp string `json:"timestamp"`
 }
 
-// BasketItemAddedEvent represents a basket item addition event
+// BasketItemAddedEvent represents a basket item addition event.
+// OperationID is the basket operation that added the item, when the client sent one.
 type BasketItemAddedEvent struct {
-	EventID     string `json:"event_id"`
-	UserID      string `json:"user_id"`
-	BasketID    string `json:"basket_id"`
-	ProductID   int    `json:"product_id"`
-	ProductName string `json:"product_name"`
-	Quantity    int    `json:"quantity"`
-	Price       float64 `json:"price"`
-	Timestamp   string `json:"timestamp"`
+	EventID       string  `json:"event_id"`
+	SchemaVersion int     `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	OperationID   string  `json:"operation_id,omitempty"`
+	UserID        string  `json:"user_id"`
+	BasketID      string  `json:"basket_id"`
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	Quantity      int     `json:"quantity"`
+	Price         float64 `json:"price"`
+	Timestamp     string  `json:"timestamp"`
 }
 
 // BasketAbandonedEvent represents a basket abandonment event
+
 type BasketAbandonedEvent struct {
-	EventID     string `json:"event_id"`
-	UserID      string `json:"user_id"`
-	BasketID    string `json:"basket_id"`
-	ItemCount   int    `json:"item_count"`
-	TotalValue  float64 `json:"total_value"`
-	AbandonedAt string `json:"abandoned_at"`
-	Timestamp   string `json:"timestamp"`
+	EventID       string  `json:"event_id"`
+	SchemaVersion int     `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	UserID        string  `json:"user_id"`
+	BasketID      string  `json:"basket_id"`
+	ItemCount     int     `json:"item_count"`
+	TotalValue    float64 `json:"total_value"`
+	AbandonedAt   string  `json:"abandoned_at"`
+	Timestamp     string  `json:"timestamp"`
 }
 
 // OrderCreatedEvent represents an order creation event
@@ -141,21 +146,25 @@ type OrderShippedEvent struct {
 }
 
 // StockLowEvent represents a low stock event
+
 type StockLowEvent struct {
-	EventID     string `json:"event_id"`
-	ProductID   int    `json:"product_id"`
-	ProductName string `json:"product_name"`
-	CurrentStock int   `json:"current_stock"`
-	Threshold   int    `json:"threshold"`
-	Timestamp   string `json:"timestamp"`
+	EventID       string `json:"event_id"`
+	SchemaVersion int    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	ProductID     int    `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	CurrentStock  int    `json:"current_stock"`
+	Threshold     int    `json:"threshold"`
+	Timestamp     string `json:"timestamp"`
 }
 
 // StockOutEvent represents a stock out event
+
 type StockOutEvent struct {
-	EventID     string `json:"event_id"`
-	ProductID   int    `json:"product_id"`
-	ProductName string `json:"product_name"`
-	Timestamp   string `json:"timestamp"`
+	EventID       string `json:"event_id"`
+	SchemaVersion int    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	ProductID     int    `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Timestamp     string `json:"timestamp"`
 }
 
 // SystemMaintenanceEvent represents a system maintenance event
@@ -170,13 +179,15 @@ type SystemMaintenanceEvent struct {
 }
 
 // PromotionCreatedEvent represents a promotion creation event
+
 type PromotionCreatedEvent struct {
-	EventID     string  `json:"event_id"`
-	PromotionID string  `json:"promotion_id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Discount    float64 `json:"discount"`
-	StartDate   string  `json:"start_date"`
-	EndDate     string  `json:"end_date"`
-	Timestamp   string  `json:"timestamp"`
+	EventID       string  `json:"event_id"`
+	SchemaVersion int     `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	PromotionID   string  `json:"promotion_id"`
+	Title         string  `json:"title"`
+	Description   string  `json:"description"`
+	Discount      float64 `json:"discount"`
+	StartDate     string  `json:"start_date"`
+	EndDate       string  `json:"end_date"`
+	Timestamp     string  `json:"timestamp"`
 }
@@ -5,17 +5,21 @@ import (
 )
 
 // PaymentCompletedEvent represents a payment completion event
+
 type PaymentCompletedEvent struct {
-	EventID     string                 `json:"event_id"`
-	EventType   string                 `json:"event_type"`
-	Timestamp   time.Time              `json:"timestamp"`
-	PaymentID   string                 `json:"payment_id"`
-	UserID      string                 `json:"user_id"`
-	BasketID    string                 `json:"basket_id"`
-	Amount      float64                `json:"amount"`
-	Currency    string                 `json:"currency"`
-	Items       []PaymentItemEvent     `json:"items"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	Timestamp     time.Time              `json:"timestamp"`
+	PaymentID     string                 `json:"payment_id"`
+	UserID        string                 `json:"user_id"`
+	BasketID      string                 `json:"basket_id"`
+	Amount        float64                `json:"amount"`
+	FeeAmount     float64                `json:"fee_amount"` // Since v2
+	NetAmount     float64                `json:"net_amount"` // Since v2, Amount minus FeeAmount
+	Currency      string                 `json:"currency"`
+	Items         []PaymentItemEvent     `json:"items"`
+	Metadata      map[string]interface{} `json:"metadata"`
 }
 
 // PaymentItemEvent represents a payment item in the event
@@ -29,55 +33,81 @@ type PaymentItemEvent struct {
 }
 
 // PaymentFailedEvent represents a payment failure event
+
 type PaymentFailedEvent struct {
-	EventID     string                 `json:"event_id"`
-	EventType   string                 `json:"event_type"`
-	Timestamp   time.Time              `json:"timestamp"`
-	PaymentID   string                 `json:"payment_id"`
-	UserID      string                 `json:"user_id"`
-	BasketID    string                 `json:"basket_id"`
-	Amount      float64                `json:"amount"`
-	Currency    string                 `json:"currency"`
-	Reason      string                 `json:"reason"`
-	ErrorCode   string                 `json:"error_code"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	Timestamp     time.Time              `json:"timestamp"`
+	PaymentID     string                 `json:"payment_id"`
+	UserID        string                 `json:"user_id"`
+	BasketID      string                 `json:"basket_id"`
+	Amount        float64                `json:"amount"`
+	Currency      string                 `json:"currency"`
+	Reason        string                 `json:"reason"`
+	ErrorCode     string                 `json:"error_code"`
+	Metadata      map[string]interface{} `json:"metadata"`
 }
 
 // PaymentRefundedEvent represents a payment refund event
+
 type PaymentRefundedEvent struct {
-	EventID     string                 `json:"event_id"`
-	EventType   string                 `json:"event_type"`
-	Timestamp   time.Time              `json:"timestamp"`
-	PaymentID   string                 `json:"payment_id"`
-	UserID      string                 `json:"user_id"`
-	Amount      float64                `json:"amount"`
-	Currency    string                 `json:"currency"`
-	Reason      string                 `json:"reason"`
-	RefundID    string                 `json:"refund_id"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	Timestamp     time.Time              `json:"timestamp"`
+	PaymentID     string                 `json:"payment_id"`
+	UserID        string                 `json:"user_id"`
+	Amount        float64                `json:"amount"`
+	Currency      string                 `json:"currency"`
+	Reason        string                 `json:"reason"`
+	RefundID      string                 `json:"refund_id"`
+	Metadata      map[string]interface{} `json:"metadata"`
+}
+
+// PaymentCancelledEvent represents a payment cancellation event
+
+type PaymentCancelledEvent struct {
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	Timestamp     time.Time              `json:"timestamp"`
+	PaymentID     string                 `json:"payment_id"`
+	UserID        string                 `json:"user_id"`
+	BasketID      string                 `json:"basket_id"`
+	Amount        float64                `json:"amount"`
+	Currency      string                 `json:"currency"`
+	Reason        string                 `json:"reason"`
+	Metadata      map[string]interface{} `json:"metadata"`
 }
 
 // StockUpdateEvent represents a stock update event
+
 type StockUpdateEvent struct {
-	EventID     string                 `json:"event_id"`
-	EventType   string                 `json:"event_type"`
-	Timestamp   time.Time              `json:"timestamp"`
-	ProductID   int                    `json:"product_id"`
-	Quantity    int                    `json:"quantity"`
-	Operation   string                 `json:"operation"` // "decrease" or "increase"
-	Reason      string                 `json:"reason"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	Timestamp     time.Time              `json:"timestamp"`
+	ProductID     int                    `json:"product_id"`
+	Quantity      int                    `json:"quantity"`
+	Operation     string                 `json:"operation"` // "decrease" or "increase"
+	Reason        string                 `json:"reason"`
+	Metadata      map[string]interface{} `json:"metadata"`
 }
 
-// BasketClearedEvent represents a basket clearing event
+// BasketClearedEvent represents a basket clearing event.
+// When ProductIDs is set only those products are removed from the basket, otherwise it is emptied.
+
 type BasketClearedEvent struct {
-	EventID     string                 `json:"event_id"`
-	EventType   string                 `json:"event_type"`
-	Timestamp   time.Time              `json:"timestamp"`
-	UserID      string                 `json:"user_id"`
-	BasketID    string                 `json:"basket_id"`
-	Reason      string                 `json:"reason"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version,omitempty"` // 0 means v1, see SchemaVersionOf
+	Timestamp     time.Time              `json:"timestamp"`
+	UserID        string                 `json:"user_id"`
+	BasketID      string                 `json:"basket_id"`
+	Reason        string                 `json:"reason"`
+	ProductIDs    []int                  `json:"product_ids,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata"`
 }
 
 // Event types
@@ -85,13 +115,19 @@ const (
 	PaymentCompletedEventType = "payment.completed"
 	PaymentFailedEventType    = "payment.failed"
 	PaymentRefundedEventType  = "payment.refunded"
+	PaymentCancelledEventType = "payment.cancelled"
 	StockUpdateEventType      = "stock.updated"
 	BasketClearedEventType    = "basket.cleared"
 )
 
+// BasketServiceSource is the "source" metadata of the basket cleared events the basket service
+// publishes about its own operations, so its consumer does not apply them a second time
+const BasketServiceSource = "basket-service"
+
 // Kafka topics
 const (
-	PaymentEventsTopic = "payment-events"
-	StockEventsTopic   = "stock-events"
-	BasketEventsTopic  = "basket-events"
+	PaymentEventsTopic   = "payment-events"
+	StockEventsTopic     = "stock-events"
+	BasketEventsTopic    = "basket-events"
+	PromotionEventsTopic = "promotion-events"
 )
@@ -0,0 +1,196 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/kafka/events"
+)
+
+// BasketEventPublisher publishes events raised by the basket service.
+// BasketPublisher sends them to Kafka; NoopPublisher discards them.
+type BasketEventPublisher interface {
+	PublishBasketAbandoned(ctx context.Context, event *events.BasketAbandonedEvent) error
+	PublishBasketItemAdded(ctx context.Context, event *events.BasketItemAddedEvent) error
+	PublishBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error
+	Close() error
+}
+
+var (
+	_ BasketEventPublisher = (*BasketPublisher)(nil)
+	_ BasketEventPublisher = (*NoopPublisher)(nil)
+)
+
+// BasketPublisher handles publishing basket events to Kafka
+type BasketPublisher struct {
+	producer sarama.SyncProducer
+	logger   *logrus.Logger
+}
+
+// NewBasketPublisher creates a new basket publisher
+func NewBasketPublisher(brokers []string, logger *logrus.Logger) (*BasketPublisher, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+	config.Producer.Compression = sarama.CompressionSnappy
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	return &BasketPublisher{
+		producer: producer,
+		logger:   logger,
+	}, nil
+}
+
+// PublishBasketAbandoned publishes a basket abandoned event
+func (p *BasketPublisher) PublishBasketAbandoned(ctx context.Context, event *events.BasketAbandonedEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal basket abandoned event: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: events.BasketEventsTopic,
+		Key:   sarama.StringEncoder(event.UserID),
+		Value: sarama.ByteEncoder(message),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_type"), Value: []byte(events.BasketAbandonedEventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
+			{Key: []byte("user_id"), Value: []byte(event.UserID)},
+			{Key: []byte("basket_id"), Value: []byte(event.BasketID)},
+		},
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send basket abandoned event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":    event.EventID,
+		"user_id":     event.UserID,
+		"basket_id":   event.BasketID,
+		"item_count":  event.ItemCount,
+		"total_value": event.TotalValue,
+		"topic":       events.BasketEventsTopic,
+		"partition":   partition,
+		"offset":      offset,
+	}).Info("Basket abandoned event published")
+
+	return nil
+}
+
+// PublishBasketItemAdded publishes a basket item added event
+func (p *BasketPublisher) PublishBasketItemAdded(ctx context.Context, event *events.BasketItemAddedEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal basket item added event: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: events.BasketEventsTopic,
+		Key:   sarama.StringEncoder(event.UserID),
+		Value: sarama.ByteEncoder(message),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_type"), Value: []byte(events.BasketItemAddedEventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
+			{Key: []byte("user_id"), Value: []byte(event.UserID)},
+			{Key: []byte("basket_id"), Value: []byte(event.BasketID)},
+		},
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send basket item added event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":     event.EventID,
+		"operation_id": event.OperationID,
+		"user_id":      event.UserID,
+		"basket_id":    event.BasketID,
+		"product_id":   event.ProductID,
+		"quantity":     event.Quantity,
+		"topic":        events.BasketEventsTopic,
+		"partition":    partition,
+		"offset":       offset,
+	}).Info("Basket item added event published")
+
+	return nil
+}
+
+// PublishBasketCleared publishes a basket cleared event
+func (p *BasketPublisher) PublishBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	event.EventType = events.BasketClearedEventType
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal basket cleared event: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: events.BasketEventsTopic,
+		Key:   sarama.StringEncoder(event.UserID),
+		Value: sarama.ByteEncoder(message),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
+			{Key: []byte("user_id"), Value: []byte(event.UserID)},
+			{Key: []byte("basket_id"), Value: []byte(event.BasketID)},
+		},
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send basket cleared event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":  event.EventID,
+		"user_id":   event.UserID,
+		"basket_id": event.BasketID,
+		"reason":    event.Reason,
+		"topic":     events.BasketEventsTopic,
+		"partition": partition,
+		"offset":    offset,
+	}).Info("Basket cleared event published")
+
+	return nil
+}
+
+// Close closes the publisher
+func (p *BasketPublisher) Close() error {
+	return p.producer.Close()
+}
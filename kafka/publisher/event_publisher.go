@@ -0,0 +1,24 @@
+package publisher
+
+import (
+	"context"
+
+	"obs-tools-usage/kafka/events"
+)
+
+// EventPublisher publishes payment-related events.
+// PaymentPublisher sends them to Kafka; NoopPublisher discards them.
+type EventPublisher interface {
+	PublishPaymentCompleted(ctx context.Context, event *events.PaymentCompletedEvent) error
+	PublishPaymentFailed(ctx context.Context, event *events.PaymentFailedEvent) error
+	PublishPaymentRefunded(ctx context.Context, event *events.PaymentRefundedEvent) error
+	PublishPaymentCancelled(ctx context.Context, event *events.PaymentCancelledEvent) error
+	PublishStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error
+	PublishBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error
+	Close() error
+}
+
+var (
+	_ EventPublisher = (*PaymentPublisher)(nil)
+	_ EventPublisher = (*NoopPublisher)(nil)
+)
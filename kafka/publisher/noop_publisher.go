@@ -0,0 +1,101 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/kafka/events"
+)
+
+// NoopPublisher discards every event. It is used when Kafka is disabled so
+// deployments without a broker keep working; each dropped event is logged at debug level.
+type NoopPublisher struct {
+	logger *logrus.Logger
+}
+
+// NewNoopPublisher creates a publisher that drops all events
+func NewNoopPublisher(logger *logrus.Logger) *NoopPublisher {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &NoopPublisher{logger: logger}
+}
+
+// PublishPaymentCompleted discards a payment completed event
+func (p *NoopPublisher) PublishPaymentCompleted(ctx context.Context, event *events.PaymentCompletedEvent) error {
+	p.discard(event.EventType, event.EventID)
+	return nil
+}
+
+// PublishPaymentFailed discards a payment failed event
+func (p *NoopPublisher) PublishPaymentFailed(ctx context.Context, event *events.PaymentFailedEvent) error {
+	p.discard(event.EventType, event.EventID)
+	return nil
+}
+
+// PublishPaymentRefunded discards a payment refunded event
+func (p *NoopPublisher) PublishPaymentRefunded(ctx context.Context, event *events.PaymentRefundedEvent) error {
+	p.discard(event.EventType, event.EventID)
+	return nil
+}
+
+// PublishPaymentCancelled discards a payment cancelled event
+func (p *NoopPublisher) PublishPaymentCancelled(ctx context.Context, event *events.PaymentCancelledEvent) error {
+	p.discard(event.EventType, event.EventID)
+	return nil
+}
+
+// PublishStockUpdate discards a stock update event
+func (p *NoopPublisher) PublishStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error {
+	p.discard(event.EventType, event.EventID)
+	return nil
+}
+
+// PublishBasketCleared discards a basket cleared event
+func (p *NoopPublisher) PublishBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error {
+	p.discard(event.EventType, event.EventID)
+	return nil
+}
+
+// PublishBasketAbandoned discards a basket abandoned event
+func (p *NoopPublisher) PublishBasketAbandoned(ctx context.Context, event *events.BasketAbandonedEvent) error {
+	p.discard(events.BasketAbandonedEventType, event.EventID)
+	return nil
+}
+
+// PublishBasketItemAdded discards a basket item added event
+func (p *NoopPublisher) PublishBasketItemAdded(ctx context.Context, event *events.BasketItemAddedEvent) error {
+	p.discard(events.BasketItemAddedEventType, event.EventID)
+	return nil
+}
+
+// PublishStockLow discards a stock low event
+func (p *NoopPublisher) PublishStockLow(ctx context.Context, event *events.StockLowEvent) error {
+	p.discard(events.StockLowEventType, event.EventID)
+	return nil
+}
+
+// PublishStockOut discards a stock out event
+func (p *NoopPublisher) PublishStockOut(ctx context.Context, event *events.StockOutEvent) error {
+	p.discard(events.StockOutEventType, event.EventID)
+	return nil
+}
+
+// PublishPromotionCreated discards a promotion created event
+func (p *NoopPublisher) PublishPromotionCreated(ctx context.Context, event *events.PromotionCreatedEvent) error {
+	p.discard(events.PromotionCreatedEventType, event.EventID)
+	return nil
+}
+
+// Close is a no-op
+func (p *NoopPublisher) Close() error {
+	return nil
+}
+
+// discard logs that an event was dropped
+func (p *NoopPublisher) discard(eventType, eventID string) {
+	p.logger.WithFields(logrus.Fields{
+		"event_type": eventType,
+		"event_id":   eventID,
+	}).Debug("Kafka publishing disabled, event discarded")
+}
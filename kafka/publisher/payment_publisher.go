@@ -12,7 +12,9 @@ import (
 	"obs-tools-usage/kafka/events"
 )
 
-// PaymentPublisher handles publishing payment events to Kafka
+// PaymentPublisher handles publishing payment events to Kafka.
+// Events that already carry an ID and timestamp (e.g. relayed from an outbox) keep them,
+// so consumers can deduplicate retried deliveries.
 type PaymentPublisher struct {
 	producer sarama.SyncProducer
 	logger   *logrus.Logger
@@ -39,9 +41,14 @@ func NewPaymentPublisher(brokers []string, logger *logrus.Logger) (*PaymentPubli
 
 // PublishPaymentCompleted publishes a payment completed event
 func (p *PaymentPublisher) PublishPaymentCompleted(ctx context.Context, event *events.PaymentCompletedEvent) error {
-	event.EventID = uuid.New().String()
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
 	event.EventType = events.PaymentCompletedEventType
-	event.Timestamp = time.Now()
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 
 	message, err := json.Marshal(event)
 	if err != nil {
@@ -54,6 +61,7 @@ func (p *PaymentPublisher) PublishPaymentCompleted(ctx context.Context, event *e
 		Value: sarama.ByteEncoder(message),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
 			{Key: []byte("payment_id"), Value: []byte(event.PaymentID)},
 			{Key: []byte("user_id"), Value: []byte(event.UserID)},
 		},
@@ -78,9 +86,14 @@ func (p *PaymentPublisher) PublishPaymentCompleted(ctx context.Context, event *e
 
 // PublishPaymentFailed publishes a payment failed event
 func (p *PaymentPublisher) PublishPaymentFailed(ctx context.Context, event *events.PaymentFailedEvent) error {
-	event.EventID = uuid.New().String()
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
 	event.EventType = events.PaymentFailedEventType
-	event.Timestamp = time.Now()
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 
 	message, err := json.Marshal(event)
 	if err != nil {
@@ -93,6 +106,7 @@ func (p *PaymentPublisher) PublishPaymentFailed(ctx context.Context, event *even
 		Value: sarama.ByteEncoder(message),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
 			{Key: []byte("payment_id"), Value: []byte(event.PaymentID)},
 			{Key: []byte("user_id"), Value: []byte(event.UserID)},
 		},
@@ -117,9 +131,14 @@ func (p *PaymentPublisher) PublishPaymentFailed(ctx context.Context, event *even
 
 // PublishPaymentRefunded publishes a payment refunded event
 func (p *PaymentPublisher) PublishPaymentRefunded(ctx context.Context, event *events.PaymentRefundedEvent) error {
-	event.EventID = uuid.New().String()
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
 	event.EventType = events.PaymentRefundedEventType
-	event.Timestamp = time.Now()
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 
 	message, err := json.Marshal(event)
 	if err != nil {
@@ -132,6 +151,7 @@ func (p *PaymentPublisher) PublishPaymentRefunded(ctx context.Context, event *ev
 		Value: sarama.ByteEncoder(message),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
 			{Key: []byte("payment_id"), Value: []byte(event.PaymentID)},
 			{Key: []byte("user_id"), Value: []byte(event.UserID)},
 		},
@@ -154,11 +174,61 @@ func (p *PaymentPublisher) PublishPaymentRefunded(ctx context.Context, event *ev
 	return nil
 }
 
+// PublishPaymentCancelled publishes a payment cancelled event
+func (p *PaymentPublisher) PublishPaymentCancelled(ctx context.Context, event *events.PaymentCancelledEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	event.EventType = events.PaymentCancelledEventType
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment cancelled event: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: events.PaymentEventsTopic,
+		Key:   sarama.StringEncoder(event.PaymentID),
+		Value: sarama.ByteEncoder(message),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
+			{Key: []byte("payment_id"), Value: []byte(event.PaymentID)},
+			{Key: []byte("user_id"), Value: []byte(event.UserID)},
+		},
+	}
+
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send payment cancelled event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":   event.EventID,
+		"payment_id": event.PaymentID,
+		"user_id":    event.UserID,
+		"topic":      events.PaymentEventsTopic,
+		"partition":  partition,
+		"offset":     offset,
+	}).Info("Payment cancelled event published")
+
+	return nil
+}
+
 // PublishStockUpdate publishes a stock update event
 func (p *PaymentPublisher) PublishStockUpdate(ctx context.Context, event *events.StockUpdateEvent) error {
-	event.EventID = uuid.New().String()
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
 	event.EventType = events.StockUpdateEventType
-	event.Timestamp = time.Now()
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 
 	message, err := json.Marshal(event)
 	if err != nil {
@@ -171,6 +241,7 @@ func (p *PaymentPublisher) PublishStockUpdate(ctx context.Context, event *events
 		Value: sarama.ByteEncoder(message),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
 			{Key: []byte("product_id"), Value: []byte(fmt.Sprintf("%d", event.ProductID))},
 		},
 	}
@@ -195,9 +266,14 @@ func (p *PaymentPublisher) PublishStockUpdate(ctx context.Context, event *events
 
 // PublishBasketCleared publishes a basket cleared event
 func (p *PaymentPublisher) PublishBasketCleared(ctx context.Context, event *events.BasketClearedEvent) error {
-	event.EventID = uuid.New().String()
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
 	event.EventType = events.BasketClearedEventType
-	event.Timestamp = time.Now()
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
 
 	message, err := json.Marshal(event)
 	if err != nil {
@@ -210,6 +286,7 @@ func (p *PaymentPublisher) PublishBasketCleared(ctx context.Context, event *even
 		Value: sarama.ByteEncoder(message),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte("event_type"), Value: []byte(event.EventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
 			{Key: []byte("user_id"), Value: []byte(event.UserID)},
 			{Key: []byte("basket_id"), Value: []byte(event.BasketID)},
 		},
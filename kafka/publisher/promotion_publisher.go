@@ -0,0 +1,96 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/kafka/events"
+)
+
+// PromotionEventPublisher publishes promotions announced by the product service.
+// PromotionPublisher sends them to Kafka; NoopPublisher discards them.
+type PromotionEventPublisher interface {
+	PublishPromotionCreated(ctx context.Context, event *events.PromotionCreatedEvent) error
+	Close() error
+}
+
+var (
+	_ PromotionEventPublisher = (*PromotionPublisher)(nil)
+	_ PromotionEventPublisher = (*NoopPublisher)(nil)
+)
+
+// PromotionPublisher handles publishing promotion events to Kafka
+type PromotionPublisher struct {
+	producer sarama.SyncProducer
+	logger   *logrus.Logger
+}
+
+// NewPromotionPublisher creates a new promotion publisher
+func NewPromotionPublisher(brokers []string, logger *logrus.Logger) (*PromotionPublisher, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+	config.Producer.Compression = sarama.CompressionSnappy
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	return &PromotionPublisher{
+		producer: producer,
+		logger:   logger,
+	}, nil
+}
+
+// PublishPromotionCreated publishes a promotion created event, keyed by promotion
+func (p *PromotionPublisher) PublishPromotionCreated(ctx context.Context, event *events.PromotionCreatedEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal promotion created event: %w", err)
+	}
+
+	partition, offset, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: events.PromotionEventsTopic,
+		Key:   sarama.StringEncoder(event.PromotionID),
+		Value: sarama.ByteEncoder(message),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_type"), Value: []byte(events.PromotionCreatedEventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(event.SchemaVersion)},
+			{Key: []byte("promotion_id"), Value: []byte(event.PromotionID)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send promotion created event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":     event.EventID,
+		"promotion_id": event.PromotionID,
+		"start_date":   event.StartDate,
+		"topic":        events.PromotionEventsTopic,
+		"partition":    partition,
+		"offset":       offset,
+	}).Info("Promotion created event published")
+
+	return nil
+}
+
+// Close closes the publisher
+func (p *PromotionPublisher) Close() error {
+	return p.producer.Close()
+}
@@ -0,0 +1,136 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"obs-tools-usage/kafka/events"
+)
+
+// StockEventPublisher publishes stock level alerts raised by the product service.
+// StockPublisher sends them to Kafka; NoopPublisher discards them.
+type StockEventPublisher interface {
+	PublishStockLow(ctx context.Context, event *events.StockLowEvent) error
+	PublishStockOut(ctx context.Context, event *events.StockOutEvent) error
+	Close() error
+}
+
+var (
+	_ StockEventPublisher = (*StockPublisher)(nil)
+	_ StockEventPublisher = (*NoopPublisher)(nil)
+)
+
+// StockPublisher handles publishing stock events to Kafka
+type StockPublisher struct {
+	producer sarama.SyncProducer
+	logger   *logrus.Logger
+}
+
+// NewStockPublisher creates a new stock publisher
+func NewStockPublisher(brokers []string, logger *logrus.Logger) (*StockPublisher, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+	config.Producer.Compression = sarama.CompressionSnappy
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	return &StockPublisher{
+		producer: producer,
+		logger:   logger,
+	}, nil
+}
+
+// PublishStockLow publishes a stock low event
+func (p *StockPublisher) PublishStockLow(ctx context.Context, event *events.StockLowEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stock low event: %w", err)
+	}
+
+	partition, offset, err := p.send(events.StockLowEventType, event.ProductID, event.SchemaVersion, message)
+	if err != nil {
+		return fmt.Errorf("failed to send stock low event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":      event.EventID,
+		"product_id":    event.ProductID,
+		"current_stock": event.CurrentStock,
+		"threshold":     event.Threshold,
+		"topic":         events.StockEventsTopic,
+		"partition":     partition,
+		"offset":        offset,
+	}).Info("Stock low event published")
+
+	return nil
+}
+
+// PublishStockOut publishes a stock out event
+func (p *StockPublisher) PublishStockOut(ctx context.Context, event *events.StockOutEvent) error {
+	if event.EventID == "" {
+		event.EventID = uuid.New().String()
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	event.SchemaVersion = events.SchemaVersionOf(event.SchemaVersion)
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stock out event: %w", err)
+	}
+
+	partition, offset, err := p.send(events.StockOutEventType, event.ProductID, event.SchemaVersion, message)
+	if err != nil {
+		return fmt.Errorf("failed to send stock out event: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"event_id":   event.EventID,
+		"product_id": event.ProductID,
+		"topic":      events.StockEventsTopic,
+		"partition":  partition,
+		"offset":     offset,
+	}).Info("Stock out event published")
+
+	return nil
+}
+
+// send writes an encoded stock event to the stock topic, keyed by product so a product's events stay ordered
+func (p *StockPublisher) send(eventType string, productID, schemaVersion int, message []byte) (int32, int64, error) {
+	productKey := strconv.Itoa(productID)
+	return p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: events.StockEventsTopic,
+		Key:   sarama.StringEncoder(productKey),
+		Value: sarama.ByteEncoder(message),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event_type"), Value: []byte(eventType)},
+			{Key: []byte(events.SchemaVersionHeader), Value: events.SchemaVersionHeaderValue(schemaVersion)},
+			{Key: []byte("product_id"), Value: []byte(productKey)},
+		},
+	})
+}
+
+// Close closes the publisher
+func (p *StockPublisher) Close() error {
+	return p.producer.Close()
+}
@@ -0,0 +1,50 @@
+package daterange
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateLayout is the bare date form accepted besides full RFC 3339 timestamps
+const dateLayout = "2006-01-02"
+
+// Range is an inclusive time range
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Parse parses the bounds of an inclusive range given as RFC 3339 timestamps or YYYY-MM-DD dates.
+// Dates are taken in UTC: a bare start date begins at midnight and a bare end date covers the
+// whole day, down to the microsecond resolution of the databases. It fails with an "invalid"
+// error when a bound cannot be parsed or the start is after the end.
+func Parse(start, end string) (Range, error) {
+	startTime, _, err := parseBound(start)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid start date %q: expected RFC3339 or YYYY-MM-DD", start)
+	}
+	endTime, dateOnly, err := parseBound(end)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid end date %q: expected RFC3339 or YYYY-MM-DD", end)
+	}
+	if dateOnly {
+		endTime = endTime.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	if startTime.After(endTime) {
+		return Range{}, fmt.Errorf("invalid date range: start %q is after end %q", start, end)
+	}
+	return Range{Start: startTime, End: endTime}, nil
+}
+
+// parseBound parses a single bound, reporting whether it was a bare date
+func parseBound(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
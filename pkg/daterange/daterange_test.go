@@ -0,0 +1,106 @@
+package daterange
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "bare dates cover whole days",
+			start:     "2024-03-01",
+			end:       "2024-03-31",
+			wantStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 31, 23, 59, 59, 999999000, time.UTC),
+		},
+		{
+			name:      "same bare date",
+			start:     "2024-03-01",
+			end:       "2024-03-01",
+			wantStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 1, 23, 59, 59, 999999000, time.UTC),
+		},
+		{
+			name:      "RFC 3339 timestamps are kept as given",
+			start:     "2024-03-01T08:30:00Z",
+			end:       "2024-03-01T17:45:00Z",
+			wantStart: time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 1, 17, 45, 0, 0, time.UTC),
+		},
+		{
+			name:      "timestamps with offsets and fractions",
+			start:     "2024-03-01T10:00:00.5+02:00",
+			end:       "2024-03-01T10:00:00-05:00",
+			wantStart: time.Date(2024, 3, 1, 8, 0, 0, 500000000, time.UTC),
+			wantEnd:   time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "bare start date with timestamp end",
+			start:     "2024-03-01",
+			end:       "2024-03-02T12:00:00Z",
+			wantStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "timestamp start on the bare end date",
+			start:     "2024-03-02T23:00:00Z",
+			end:       "2024-03-02",
+			wantStart: time.Date(2024, 3, 2, 23, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 2, 23, 59, 59, 999999000, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !r.Start.Equal(tt.wantStart) {
+				t.Errorf("Start = %v, want %v", r.Start, tt.wantStart)
+			}
+			if !r.End.Equal(tt.wantEnd) {
+				t.Errorf("End = %v, want %v", r.End, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		message string
+	}{
+		{name: "inverted bare dates", start: "2024-03-31", end: "2024-03-01", message: "invalid date range"},
+		{name: "inverted timestamps", start: "2024-03-01T12:00:00Z", end: "2024-03-01T11:59:59Z", message: "invalid date range"},
+		{name: "start after the bare end date", start: "2024-03-02T00:00:00Z", end: "2024-03-01", message: "invalid date range"},
+		{name: "malformed start", start: "foo", end: "2024-03-01", message: `invalid start date "foo"`},
+		{name: "malformed end", start: "2024-03-01", end: "bar", message: `invalid end date "bar"`},
+		{name: "empty start", start: "", end: "2024-03-01", message: "invalid start date"},
+		{name: "impossible date", start: "2024-02-30", end: "2024-03-01", message: "invalid start date"},
+		{name: "wrong date order", start: "01-03-2024", end: "2024-03-01", message: "invalid start date"},
+		{name: "timestamp without zone", start: "2024-03-01T10:00:00", end: "2024-03-02", message: "invalid start date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.start, tt.end)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not contain %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package grpclimits
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Config holds the limits a gRPC server enforces on its clients, so a misbehaving client cannot
+// open unlimited streams, send oversized messages or keep dead connections around.
+// Zero values keep gRPC's defaults.
+type Config struct {
+	MaxConcurrentStreams uint32        // Streams a single connection may have open at once
+	MaxRecvMsgSize       int           // Largest message in bytes the server accepts
+	KeepaliveTime        time.Duration // Idle time after which the server pings the client
+	KeepaliveTimeout     time.Duration // How long the server waits for the ping ack before closing the connection
+	MaxConnectionIdle    time.Duration // Idle time after which the server closes a connection without streams
+	MinPingInterval      time.Duration // Clients pinging more often than this are disconnected
+	PermitWithoutStream  bool          // Allow client pings on connections without active streams
+}
+
+// ServerOptions returns the gRPC server options enforcing the limits
+func (c Config) ServerOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	if c.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+
+	return append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:              c.KeepaliveTime,
+			Timeout:           c.KeepaliveTimeout,
+			MaxConnectionIdle: c.MaxConnectionIdle,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.MinPingInterval,
+			PermitWithoutStream: c.PermitWithoutStream,
+		}),
+	)
+}
+
+// LogFields returns the limits as log fields, for logging them when the server starts
+func (c Config) LogFields() logrus.Fields {
+	return logrus.Fields{
+		"max_concurrent_streams": c.MaxConcurrentStreams,
+		"max_recv_msg_size":      c.MaxRecvMsgSize,
+		"keepalive_time":         c.KeepaliveTime.String(),
+		"keepalive_timeout":      c.KeepaliveTimeout.String(),
+		"max_connection_idle":    c.MaxConnectionIdle.String(),
+		"min_ping_interval":      c.MinPingInterval.String(),
+		"permit_without_stream":  c.PermitWithoutStream,
+	}
+}
@@ -0,0 +1,94 @@
+package grpctls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Config holds the certificates services use to authenticate each other over mutual TLS.
+// When Enabled is false connections are insecure, which is only meant for development.
+type Config struct {
+	Enabled    bool
+	CertFile   string // PEM certificate presented to the peer
+	KeyFile    string // PEM private key of CertFile
+	CAFile     string // PEM bundle of the CAs that sign peer certificates
+	ServerName string // Name expected in the server certificate; empty uses the dialled host
+}
+
+// ServerCredentials returns the transport credentials of a gRPC server, which requires
+// clients to present a certificate signed by the configured CA
+func (c Config) ServerCredentials() (credentials.TransportCredentials, error) {
+	if !c.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	certificate, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// ClientCredentials returns the transport credentials of a gRPC client, which presents its
+// certificate and only trusts servers whose certificate is signed by the configured CA
+func (c Config) ClientCredentials() (credentials.TransportCredentials, error) {
+	if !c.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	certificate, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		ServerName:   c.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// Validate reports an error when TLS is enabled without the cert, key and CA files, so
+// services refuse to start rather than fall back to insecure connections
+func (c Config) Validate() error {
+	if c.Enabled && (c.CertFile == "" || c.KeyFile == "" || c.CAFile == "") {
+		return errors.New("gRPC TLS is enabled but the cert, key and CA files are not all set")
+	}
+	return nil
+}
+
+// load reads the key pair and the CA bundle, failing when any of them is not configured
+func (c Config) load() (tls.Certificate, *x509.CertPool, error) {
+	if err := c.Validate(); err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to load gRPC TLS key pair: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to read gRPC TLS CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return tls.Certificate{}, nil, fmt.Errorf("gRPC TLS CA file %s contains no PEM certificates", c.CAFile)
+	}
+
+	return certificate, pool, nil
+}
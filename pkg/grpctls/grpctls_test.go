@@ -0,0 +1,36 @@
+package grpctls
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "disabled without files", config: Config{}},
+		{name: "enabled with every file", config: Config{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem", CAFile: "ca.pem"}},
+		{name: "enabled without files", config: Config{Enabled: true}, wantErr: true},
+		{name: "enabled without a CA", config: Config{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}, wantErr: true},
+		{name: "enabled without a key", config: Config{Enabled: true, CertFile: "cert.pem", CAFile: "ca.pem"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCredentialsRefuseEnabledTLSWithoutFiles(t *testing.T) {
+	config := Config{Enabled: true}
+	if _, err := config.ServerCredentials(); err == nil {
+		t.Error("ServerCredentials returned insecure credentials for enabled TLS without files")
+	}
+	if _, err := config.ClientCredentials(); err == nil {
+		t.Error("ClientCredentials returned insecure credentials for enabled TLS without files")
+	}
+}
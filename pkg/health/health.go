@@ -0,0 +1,208 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status values reported for the service and for each component
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+)
+
+// DefaultTimeout bounds how long a single check may take
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc probes one dependency and returns an error when it is unavailable
+type CheckFunc func(ctx context.Context) error
+
+// InfoFunc returns a snapshot of diagnostic details, such as connection pool stats, reported by /health
+type InfoFunc func() interface{}
+
+// ComponentStatus is the result of a single dependency check
+type ComponentStatus struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the aggregated result returned by the health and readiness endpoints
+type Report struct {
+	Service   string                     `json:"service"`
+	Status    string                     `json:"status"`
+	Timestamp string                     `json:"timestamp"`
+	Version   string                     `json:"version"`
+	Checks    map[string]ComponentStatus `json:"checks,omitempty"`
+	Failing   []string                   `json:"failing,omitempty"`
+	Info      map[string]interface{}     `json:"info,omitempty"`
+}
+
+type namedCheck struct {
+	name       string
+	check      CheckFunc
+	dependency bool
+}
+
+// Checker aggregates dependency checks for a service.
+// Readiness checks cover the service's own datastore and run on /ready and /health;
+// dependency checks cover upstream services and only run on /health, so an outage
+// upstream does not take this service out of rotation.
+type Checker struct {
+	service string
+	version string
+	timeout time.Duration
+	checks  []namedCheck
+	infos   map[string]InfoFunc
+}
+
+// NewChecker creates a health checker for the named service
+func NewChecker(service, version string) *Checker {
+	return &Checker{
+		service: service,
+		version: version,
+		timeout: DefaultTimeout,
+	}
+}
+
+// WithTimeout sets the per-check timeout
+func (c *Checker) WithTimeout(timeout time.Duration) *Checker {
+	if timeout > 0 {
+		c.timeout = timeout
+	}
+	return c
+}
+
+// AddReadinessCheck registers a check for a dependency the service cannot serve without
+func (c *Checker) AddReadinessCheck(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// AddDependencyCheck registers a check for an upstream service reported only by /health
+func (c *Checker) AddDependencyCheck(name string, check CheckFunc) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check, dependency: true})
+}
+
+// AddInfo registers diagnostic details included in the /health report
+func (c *Checker) AddInfo(name string, info InfoFunc) {
+	if c.infos == nil {
+		c.infos = make(map[string]InfoFunc)
+	}
+	c.infos[name] = info
+}
+
+// Run executes the registered checks concurrently. Dependency checks are skipped
+// unless includeDependencies is set.
+func (c *Checker) Run(ctx context.Context, includeDependencies bool) Report {
+	report := Report{
+		Service:   c.service,
+		Status:    StatusHealthy,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Version:   c.version,
+		Checks:    make(map[string]ComponentStatus),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		if nc.dependency && !includeDependencies {
+			continue
+		}
+
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			status := c.runCheck(ctx, nc.check)
+
+			mu.Lock()
+			report.Checks[nc.name] = status
+			if status.Status != StatusUp {
+				report.Failing = append(report.Failing, nc.name)
+			}
+			mu.Unlock()
+		}(nc)
+	}
+	wg.Wait()
+
+	if len(report.Failing) > 0 {
+		sort.Strings(report.Failing)
+		report.Status = StatusUnhealthy
+	}
+	return report
+}
+
+// runCheck executes a single check, giving up once the configured timeout elapses
+// even if the check itself does not honour its context
+func (c *Checker) runCheck(ctx context.Context, check CheckFunc) ComponentStatus {
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- check(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-checkCtx.Done():
+		err = fmt.Errorf("check timed out after %s", c.timeout)
+	}
+
+	status := ComponentStatus{
+		Status:    StatusUp,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// ServeHealth writes the composite health report, including upstream dependencies.
+// It responds 200 when every check passes and 503 listing the failing components otherwise.
+func (c *Checker) ServeHealth(ctx *gin.Context) {
+	report := c.Run(ctx.Request.Context(), true)
+	if len(c.infos) > 0 {
+		report.Info = make(map[string]interface{}, len(c.infos))
+		for name, info := range c.infos {
+			report.Info[name] = info()
+		}
+	}
+	c.respond(ctx, report)
+}
+
+// ServeReady writes the readiness report, covering only the service's own datastore
+func (c *Checker) ServeReady(ctx *gin.Context) {
+	c.respond(ctx, c.Run(ctx.Request.Context(), false))
+}
+
+// ServeLive reports that the process is up without touching any dependency
+func (c *Checker) ServeLive(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Report{
+		Service:   c.service,
+		Status:    StatusHealthy,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Version:   c.version,
+	})
+}
+
+// respond writes the report with the status code matching its outcome
+func (c *Checker) respond(ctx *gin.Context, report Report) {
+	statusCode := http.StatusOK
+	if report.Status != StatusHealthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+	ctx.JSON(statusCode, report)
+}
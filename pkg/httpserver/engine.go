@@ -0,0 +1,36 @@
+package httpserver
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EngineConfig controls how a service's gin engine runs
+type EngineConfig struct {
+	Mode           string   // gin mode: debug, release or test
+	TrustedProxies []string // IPs or CIDRs whose X-Forwarded-For headers are trusted; empty trusts none
+}
+
+// NewEngine sets the gin mode and returns a bare engine whose ClientIP only honours
+// forwarding headers sent by the configured proxies, such as the API gateway
+func NewEngine(cfg EngineConfig) (*gin.Engine, error) {
+	switch cfg.Mode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+		gin.SetMode(cfg.Mode)
+	default:
+		return nil, fmt.Errorf("invalid gin mode %q, expected debug, release or test", cfg.Mode)
+	}
+
+	r := gin.New()
+
+	var proxies []string
+	if len(cfg.TrustedProxies) > 0 {
+		proxies = cfg.TrustedProxies
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	return r, nil
+}
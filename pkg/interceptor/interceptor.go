@@ -0,0 +1,91 @@
+// Package interceptor provides gRPC server interceptors shared by the services.
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var grpcRequestDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "grpc_server_request_duration_seconds",
+		Help:    "Duration of unary gRPC requests handled by the server",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"method", "code"},
+)
+
+// UnaryServerInterceptors returns the standard unary chain: metrics, logging and panic recovery.
+// Recovery runs innermost so recovered panics are logged and measured as codes.Internal.
+func UnaryServerInterceptors(logger *logrus.Logger) []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		UnaryMetricsInterceptor(),
+		UnaryLoggingInterceptor(logger),
+		UnaryRecoveryInterceptor(logger),
+	}
+}
+
+// UnaryRecoveryInterceptor converts panics in handlers into codes.Internal errors
+func UnaryRecoveryInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.WithFields(logrus.Fields{
+					"grpc_method": info.FullMethod,
+					"panic":       r,
+					"stack":       string(debug.Stack()),
+				}).Error("Recovered from panic in gRPC handler")
+				resp = nil
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// UnaryLoggingInterceptor logs every unary call with its status code and duration
+func UnaryLoggingInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		duration := time.Since(start)
+
+		code := status.Code(err)
+		entry := logger.WithFields(logrus.Fields{
+			"grpc_method": info.FullMethod,
+			"grpc_code":   code.String(),
+			"duration_ms": duration.Milliseconds(),
+		})
+
+		switch code {
+		case codes.OK:
+			entry.Info("gRPC request completed")
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			entry.WithError(err).Error("gRPC request failed")
+		default:
+			entry.WithError(err).Warn("gRPC request failed")
+		}
+
+		return resp, err
+	}
+}
+
+// UnaryMetricsInterceptor records the duration of every unary call by method and status code
+func UnaryMetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		grpcRequestDuration.WithLabelValues(info.FullMethod, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
@@ -0,0 +1,65 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lease is a row of the job lease table
+type Lease struct {
+	Name      string    `gorm:"primaryKey;size:128"`
+	Owner     string    `gorm:"size:128;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name used by Lease
+func (Lease) TableName() string {
+	return "job_leases"
+}
+
+// GormBackend keeps leases as rows of a table, for services that have a database but no Redis
+type GormBackend struct {
+	db *gorm.DB
+}
+
+// NewGormBackend creates a lease backend, creating the lease table if needed
+func NewGormBackend(db *gorm.DB) (*GormBackend, error) {
+	if err := db.AutoMigrate(&Lease{}); err != nil {
+		return nil, err
+	}
+	return &GormBackend{db: db}, nil
+}
+
+// Acquire claims the lease row when it is free, expired or already held by owner.
+// Expiry is judged by the replicas' clocks, so the TTL should dwarf their skew.
+func (b *GormBackend) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	db := b.db.WithContext(ctx)
+
+	// Make sure the row exists; an expired placeholder is claimed by the update below
+	placeholder := Lease{Name: name, ExpiresAt: now}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&placeholder).Error; err != nil {
+		return false, err
+	}
+
+	result := db.Model(&Lease{}).
+		Where("name = ? AND (owner = ? OR expires_at <= ?)", name, owner, now).
+		Updates(map[string]interface{}{
+			"owner":      owner,
+			"expires_at": now.Add(ttl),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release expires the lease if owner still holds it
+func (b *GormBackend) Release(ctx context.Context, name, owner string) error {
+	return b.db.WithContext(ctx).Model(&Lease{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("expires_at", time.Now()).Error
+}
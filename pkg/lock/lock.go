@@ -0,0 +1,140 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// releaseTimeout bounds how long releasing a lease may take once its job has stopped
+	releaseTimeout = 5 * time.Second
+	// DefaultTTL is the lease TTL used when the configured one is too short to renew
+	DefaultTTL = 30 * time.Second
+)
+
+// Backend stores named leases shared by every replica of a service
+type Backend interface {
+	// Acquire takes the lease for name on behalf of owner, or extends it when owner already holds it.
+	// It reports whether owner holds the lease for another ttl afterwards.
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Release gives up the lease for name if owner still holds it
+	Release(ctx context.Context, name, owner string) error
+}
+
+// Config controls how long a lease lasts without being renewed
+type Config struct {
+	Enabled bool
+	TTL     time.Duration
+}
+
+// Locker runs periodic jobs on only one replica at a time
+type Locker struct {
+	backend Backend
+	owner   string
+	config  Config
+	logger  *logrus.Logger
+}
+
+// NewLocker creates a locker whose leases are held under a name unique to this process.
+// A TTL too short to renew every third of it falls back to DefaultTTL.
+func NewLocker(backend Backend, config Config, logger *logrus.Logger) *Locker {
+	if config.TTL/3 <= 0 {
+		logger.WithField("ttl", config.TTL).Warnf("Job lease TTL must be positive, using %s", DefaultTTL)
+		config.TTL = DefaultTTL
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &Locker{
+		backend: backend,
+		owner:   fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Run calls job while this replica holds the lease for name, until ctx is cancelled.
+// Replicas without the lease retry every third of the TTL; the holder renews it at the same pace.
+// When a renewal fails the job's context is cancelled, since another replica may take over once
+// the lease expires, and Run goes back to waiting for it. The lease is released on shutdown.
+// With locking disabled the job simply runs on every replica.
+func (l *Locker) Run(ctx context.Context, name string, job func(ctx context.Context)) {
+	if !l.config.Enabled {
+		job(ctx)
+		return
+	}
+
+	ticker := time.NewTicker(l.config.TTL / 3)
+	defer ticker.Stop()
+
+	for {
+		held, err := l.backend.Acquire(ctx, name, l.owner, l.config.TTL)
+		if err != nil && ctx.Err() == nil {
+			l.logger.WithError(err).WithField("lock", name).Warn("Failed to acquire job lease")
+		}
+		if held {
+			l.hold(ctx, name, job, ticker)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// hold runs job while renewing the lease for name, and releases the lease once job returns
+func (l *Locker) hold(ctx context.Context, name string, job func(ctx context.Context), ticker *time.Ticker) {
+	logger := l.logger.WithFields(logrus.Fields{
+		"lock":  name,
+		"owner": l.owner,
+	})
+	logger.Info("Acquired job lease, running job on this replica")
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job(jobCtx)
+	}()
+
+renew:
+	for {
+		select {
+		case <-done:
+			break renew
+		case <-ticker.C:
+			held, err := l.backend.Acquire(jobCtx, name, l.owner, l.config.TTL)
+			if err != nil || !held {
+				if jobCtx.Err() != nil {
+					continue
+				}
+				entry := logger
+				if err != nil {
+					entry = logger.WithError(err)
+				}
+				entry.Warn("Lost job lease, stopping job on this replica")
+				cancel()
+				<-done
+				break renew
+			}
+		}
+	}
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancelRelease()
+	if err := l.backend.Release(releaseCtx, name, l.owner); err != nil {
+		logger.WithError(err).Warn("Failed to release job lease, it will expire on its own")
+		return
+	}
+	logger.Info("Released job lease")
+}
@@ -0,0 +1,34 @@
+package lock
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNewLockerFallsBackToDefaultTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{name: "zero", ttl: 0, want: DefaultTTL},
+		{name: "negative", ttl: -time.Second, want: DefaultTTL},
+		{name: "too short to renew", ttl: 2 * time.Nanosecond, want: DefaultTTL},
+		{name: "shortest renewable", ttl: 3 * time.Nanosecond, want: 3 * time.Nanosecond},
+		{name: "configured", ttl: 10 * time.Second, want: 10 * time.Second},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLocker(nil, Config{Enabled: true, TTL: tt.ttl}, logger)
+			if l.config.TTL != tt.want {
+				t.Errorf("TTL = %s, want %s", l.config.TTL, tt.want)
+			}
+		})
+	}
+}
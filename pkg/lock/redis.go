@@ -0,0 +1,58 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// renewScript extends a lease only while it is still held by the caller
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes a lease only while it is still held by the caller
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisBackend keeps leases as Redis keys set with SET NX and a TTL
+type RedisBackend struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisBackend creates a lease backend storing leases under keyPrefix
+func NewRedisBackend(client *redis.Client, keyPrefix string) *RedisBackend {
+	return &RedisBackend{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Acquire takes the lease with SET NX, or extends it when owner already holds it
+func (b *RedisBackend) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	key := b.keyPrefix + name
+	acquired, err := b.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || acquired {
+		return acquired, err
+	}
+
+	renewed, err := renewScript.Run(ctx, b.client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+// Release deletes the lease if owner still holds it
+func (b *RedisBackend) Release(ctx context.Context, name, owner string) error {
+	return releaseScript.Run(ctx, b.client, []string{b.keyPrefix + name}, owner).Err()
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSampledMessages are the high-volume success messages sampled when no list is configured
+var DefaultSampledMessages = []string{
+	"Database operation completed",
+}
+
+// SamplingConfig controls how routine Info logs are reduced.
+// Warn and Error entries, and Info entries with other messages, are never sampled.
+type SamplingConfig struct {
+	Rate     int      // Keep 1 in Rate matching entries; 0 or 1 keeps every entry
+	Demote   bool     // Log matching entries at Debug instead of sampling them
+	Messages []string // Messages to sample; defaults to DefaultSampledMessages
+}
+
+// Enabled reports whether the configuration changes any log output
+func (c SamplingConfig) Enabled() bool {
+	return c.Demote || c.Rate > 1
+}
+
+// SamplingFormatter wraps a formatter and drops or demotes routine Info entries.
+// Logrus hooks cannot discard entries, so sampling is applied at formatting time.
+type SamplingFormatter struct {
+	next     logrus.Formatter
+	rate     uint64
+	demote   bool
+	messages map[string]struct{}
+	counters sync.Map // message -> *atomic.Uint64
+}
+
+// NewSamplingFormatter creates a formatter that samples entries before passing them to next
+func NewSamplingFormatter(next logrus.Formatter, cfg SamplingConfig) *SamplingFormatter {
+	messages := cfg.Messages
+	if len(messages) == 0 {
+		messages = DefaultSampledMessages
+	}
+
+	f := &SamplingFormatter{
+		next:     next,
+		demote:   cfg.Demote,
+		messages: make(map[string]struct{}, len(messages)),
+	}
+	if cfg.Rate > 1 {
+		f.rate = uint64(cfg.Rate)
+	}
+	for _, message := range messages {
+		f.messages[message] = struct{}{}
+	}
+	return f
+}
+
+// Format formats the entry, returning no output for entries that are sampled out
+func (f *SamplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level != logrus.InfoLevel {
+		return f.next.Format(entry)
+	}
+	if _, ok := f.messages[entry.Message]; !ok {
+		return f.next.Format(entry)
+	}
+
+	if f.demote {
+		if entry.Logger == nil || !entry.Logger.IsLevelEnabled(logrus.DebugLevel) {
+			return nil, nil
+		}
+		entry.Level = logrus.DebugLevel
+		return f.next.Format(entry)
+	}
+
+	if f.rate == 0 {
+		return f.next.Format(entry)
+	}
+
+	counter, _ := f.counters.LoadOrStore(entry.Message, new(atomic.Uint64))
+	if (counter.(*atomic.Uint64).Add(1)-1)%f.rate != 0 {
+		return nil, nil
+	}
+
+	// Record the rate so log consumers can scale counts back up
+	sampled := entry.WithField("sample_rate", f.rate)
+	sampled.Level = entry.Level
+	sampled.Message = entry.Message
+	sampled.Time = entry.Time
+	sampled.Caller = entry.Caller
+	return f.next.Format(sampled)
+}
+
+// ApplySampling wraps the logger's current formatter with sampling.
+// It must be called after the formatter has been configured.
+func ApplySampling(logger *logrus.Logger, cfg SamplingConfig) {
+	if !cfg.Enabled() {
+		return
+	}
+	logger.SetFormatter(NewSamplingFormatter(logger.Formatter, cfg))
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RequestIDHeader carries the request ID, set by the gateway or generated here
+	RequestIDHeader = "X-Request-ID"
+	// UserIDHeader carries the user ID forwarded by the gateway
+	UserIDHeader = "X-User-ID"
+	// RequestIDKey is the gin context key holding the request ID
+	RequestIDKey = "request_id"
+)
+
+// HTTPObserver records a finished request, e.g. in the service's request duration histogram.
+// route is the matched route pattern rather than the raw path, to keep label cardinality bounded.
+type HTTPObserver func(method, route string, status int, latency time.Duration, bytesIn, bytesOut int)
+
+// AccessLog logs every request as one structured entry through logger, so it follows the
+// service's configured log format, and passes it to observe when set. Requests to skipPaths
+// are observed but not logged. 5xx responses are logged at Error and 4xx at Warn.
+func AccessLog(logger *logrus.Logger, observe HTTPObserver, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+
+		latency := time.Since(start)
+		status := c.Writer.Status()
+		bytesIn := int(c.Request.ContentLength)
+		if bytesIn < 0 {
+			bytesIn = 0
+		}
+		bytesOut := c.Writer.Size()
+		if bytesOut < 0 {
+			bytesOut = 0
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		if observe != nil {
+			observe(c.Request.Method, route, status, latency, bytesIn, bytesOut)
+		}
+
+		if skip[c.Request.URL.Path] {
+			return
+		}
+
+		userID := c.GetHeader(UserIDHeader)
+		if userID == "" {
+			userID = c.Param("user_id")
+		}
+
+		entry := logger.WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"route":      route,
+			"status":     status,
+			"latency_ms": float64(latency.Microseconds()) / 1000,
+			"request_id": requestID,
+			"user_id":    userID,
+			"bytes_in":   bytesIn,
+			"bytes_out":  bytesOut,
+			"client_ip":  c.ClientIP(),
+		})
+		if traceID := c.GetString(TraceIDKey); traceID != "" {
+			entry = entry.WithField(TraceIDKey, traceID)
+		}
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			entry.Error("HTTP request completed with error")
+		case status >= 400:
+			entry.Warn("HTTP request completed with client error")
+		default:
+			entry.Info("HTTP request completed")
+		}
+	}
+}
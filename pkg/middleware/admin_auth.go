@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"obs-tools-usage/pkg/respond"
+)
+
+// AdminAuth guards admin and ops routes with a shared bearer token.
+// Requests without credentials get 401, requests with a wrong token get 403.
+// An empty token rejects every request, so admin routes are closed until one is configured.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorization := c.GetHeader("Authorization")
+		if authorization == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			respond.Abort(c, http.StatusUnauthorized, "Admin credentials required")
+			return
+		}
+
+		provided, found := strings.CutPrefix(authorization, "Bearer ")
+		if !found || token == "" || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(provided)), []byte(token)) != 1 {
+			respond.Abort(c, http.StatusForbidden, "Invalid admin credentials")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"obs-tools-usage/pkg/respond"
+)
+
+// DefaultMaxBodyBytes is the request body limit used when none is configured
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1MB
+
+// BodySizeLimit rejects request bodies larger than maxBytes with 413 Request Entity Too Large.
+// Requests that declare an oversized Content-Length are rejected up front; for the rest the
+// body is wrapped in http.MaxBytesReader so reading past the limit fails during binding.
+func BodySizeLimit(maxBytes int64) gin.HandlerFunc {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			respond.Abort(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", maxBytes))
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge reports whether err was caused by a body exceeding the BodySizeLimit
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// BindErrorStatus returns the HTTP status for a request binding error:
+// 413 when the body exceeded the size limit, 400 when it was malformed or invalid
+func BindErrorStatus(err error) int {
+	if IsBodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecatedAlias marks routes mounted under aliasPrefix as deprecated copies of the routes under
+// successorPrefix. Responses carry a Deprecation header and a Link to the successor path, so
+// clients can migrate before the alias is removed.
+func DeprecatedAlias(aliasPrefix, successorPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		successor := successorPrefix + strings.TrimPrefix(c.Request.URL.Path, aliasPrefix)
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
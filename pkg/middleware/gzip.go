@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultGzipMinSize is the smallest response body compressed when no threshold is configured
+const DefaultGzipMinSize = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// Gzip compresses response bodies for clients that send Accept-Encoding: gzip.
+// Bodies smaller than minSize are sent uncompressed. Requests to excludedPaths,
+// websocket upgrades, event streams and responses that flush early are never compressed.
+func Gzip(minSize int, excludedPaths ...string) gin.HandlerFunc {
+	if minSize <= 0 {
+		minSize = DefaultGzipMinSize
+	}
+
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, path := range excludedPaths {
+		excluded[path] = true
+	}
+
+	return func(c *gin.Context) {
+		req := c.Request
+		if excluded[req.URL.Path] ||
+			req.Method == http.MethodHead ||
+			req.Header.Get("Upgrade") != "" ||
+			strings.Contains(req.Header.Get("Accept"), "text/event-stream") ||
+			!acceptsGzip(req.Header.Get("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		for _, param := range fields[1:] {
+			value, found := strings.CutPrefix(strings.TrimSpace(param), "q=")
+			if !found {
+				continue
+			}
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the start of a response until it knows whether the body
+// reaches the size threshold, then either compresses it or passes it through unchanged
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+
+	status    int
+	buffer    []byte
+	committed bool
+	gz        *gzip.Writer
+}
+
+// WriteHeader records the status until the response is committed
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.committed {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+// WriteHeaderNow defers sending headers until the response is committed
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if w.committed {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Status returns the response status, including one not yet sent
+func (w *gzipResponseWriter) Status() int {
+	if !w.committed && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Written reports whether anything was written to the response
+func (w *gzipResponseWriter) Written() bool {
+	return len(w.buffer) > 0 || w.ResponseWriter.Written()
+}
+
+// Write buffers data below the threshold and compresses everything once it is reached
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.committed {
+		if w.gz != nil {
+			return w.gz.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) >= w.minSize {
+		if err := w.commit(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// WriteString writes a string body
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends buffered data. A handler flushing before the threshold is streaming,
+// so its response is passed through uncompressed.
+func (w *gzipResponseWriter) Flush() {
+	if !w.committed {
+		w.commit(false)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// commit sends the headers and buffered data, compressing them if requested and allowed
+func (w *gzipResponseWriter) commit(compress bool) error {
+	w.committed = true
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		compress = false
+	}
+
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(w.ResponseWriter)
+		w.gz = gz
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buffered := w.buffer
+	w.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(buffered)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buffered)
+	return err
+}
+
+// finish sends a response that never reached the threshold and closes the compressor
+func (w *gzipResponseWriter) finish() {
+	if !w.committed {
+		w.commit(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriterPool.Put(w.gz)
+		w.gz = nil
+	}
+}
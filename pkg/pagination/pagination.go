@@ -0,0 +1,82 @@
+package pagination
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// DefaultMaxLimit is the largest page size accepted when a service configures none
+const DefaultMaxLimit = 100
+
+// Parse reads the limit and offset parameters of a list request.
+// A missing, invalid or non-positive limit falls back to defaultLimit and a limit above maxLimit
+// is clamped to it, so clients cannot force unbounded queries. A missing, invalid or negative
+// offset is treated as 0. A non-positive maxLimit means DefaultMaxLimit.
+func Parse(query url.Values, defaultLimit, maxLimit int) (limit, offset int) {
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxLimit
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	offset, err = strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// Page is the pagination envelope shared by list responses across services.
+// Next and Prev are relative links to the neighbouring pages and are nil at the edges.
+type Page struct {
+	Total  int64   `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+	Next   *string `json:"next"`
+	Prev   *string `json:"prev"`
+}
+
+// NewPage builds the pagination envelope for a page of a list served at requestURL.
+// The links keep every query parameter of the request and only replace limit and offset.
+func NewPage(requestURL *url.URL, total int64, limit, offset int) *Page {
+	page := &Page{
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	if limit <= 0 {
+		return page
+	}
+
+	if int64(offset+limit) < total {
+		next := pageLink(requestURL, limit, offset+limit)
+		page.Next = &next
+	}
+
+	if offset > 0 {
+		prevOffset := offset - limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev := pageLink(requestURL, limit, prevOffset)
+		page.Prev = &prev
+	}
+
+	return page
+}
+
+// pageLink returns the request path and query with limit and offset replaced
+func pageLink(requestURL *url.URL, limit, offset int) string {
+	params := requestURL.Query()
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("offset", strconv.Itoa(offset))
+
+	link := url.URL{Path: requestURL.Path, RawQuery: params.Encode()}
+	return link.String()
+}
@@ -0,0 +1,105 @@
+package respond
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code is a stable, machine-readable error code clients can branch on
+type Code string
+
+// Error codes shared by every service. Codes are part of the API contract: add new ones,
+// never rename or remove them.
+const (
+	CodeValidation         Code = "VALIDATION_ERROR"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeConflict           Code = "CONFLICT"
+	CodeGone               Code = "GONE"
+	CodePayloadTooLarge    Code = "PAYLOAD_TOO_LARGE"
+	CodeUnprocessable      Code = "UNPROCESSABLE"
+	CodeRateLimited        Code = "RATE_LIMITED"
+	CodeInternal           Code = "INTERNAL_ERROR"
+	CodeBadGateway         Code = "BAD_GATEWAY"
+	CodeServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+	CodeTimeout            Code = "TIMEOUT"
+)
+
+// Envelope is the body of every API response: data on success, error otherwise
+type Envelope struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   *ErrorBody  `json:"error,omitempty"`
+}
+
+// ErrorBody describes why a request failed
+type ErrorBody struct {
+	Code    Code        `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"` // Values behind the error, e.g. the invalid fields
+}
+
+// CodeForStatus returns the error code matching an HTTP status
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeValidation
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	default:
+		return CodeInternal
+	}
+}
+
+// OK writes data in a successful envelope
+func OK(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, Envelope{Success: true, Data: data})
+}
+
+// Error writes a failed envelope whose code is derived from the status
+func Error(c *gin.Context, status int, message string) {
+	ErrorWithDetails(c, status, CodeForStatus(status), message, nil)
+}
+
+// ErrorWithDetails writes a failed envelope with an explicit code and optional details
+func ErrorWithDetails(c *gin.Context, status int, code Code, message string, details interface{}) {
+	c.JSON(status, failure(code, message, details))
+}
+
+// Abort stops the handler chain and writes a failed envelope, for use in middleware
+func Abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, failure(CodeForStatus(status), message, nil))
+}
+
+func failure(code Code, message string, details interface{}) Envelope {
+	return Envelope{
+		Success: false,
+		Error: &ErrorBody{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package seed
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"obs-tools-usage/pkg/respond"
+)
+
+// Result counts the sample rows a seeding run inserted and the ones it skipped because they already existed
+type Result struct {
+	Inserted int `json:"inserted"`
+	Skipped  int `json:"skipped"`
+}
+
+// Func inserts a service's sample data. Seeding must be idempotent so it can be re-run on demand.
+type Func func() (Result, error)
+
+// Handler returns a handler that re-runs seeding and reports what it inserted.
+// It is meant for development only; services register it behind admin auth, in development,
+// when SEED_ENDPOINT_ENABLED is set.
+func Handler(seed Func) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := seed()
+		if err != nil {
+			respond.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respond.OK(c, http.StatusOK, result)
+	}
+}